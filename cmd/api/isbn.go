@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/infrastructure/config"
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/infrastructure/db"
+)
+
+const isbnUsage = `uso: api isbn normalize [--dry-run]
+
+  normalize  reescribe en forma canónica (ISBN-13 sin guiones) los ISBN
+             guardados antes de validar el dígito de control; informa los
+             inválidos y los que chocan con otro libro (duplicados)`
+
+// runISBN implementa el subcomando "isbn".
+func runISBN(cfg config.Config, args []string) error {
+	if len(args) == 0 || args[0] != "normalize" {
+		return fmt.Errorf("%s", isbnUsage)
+	}
+	dryRun := len(args) > 1 && args[1] == "--dry-run"
+
+	if cfg.DBDriver == "memory" {
+		return fmt.Errorf("DB_DRIVER=memory has no stored books")
+	}
+	database, err := db.Open(cfg.DBDriver, cfg.DSN())
+	if err != nil {
+		return fmt.Errorf("db open: %w", err)
+	}
+	defer database.SQL.Close()
+	books := database.Repos().Books
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	all, err := books.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	var fixed, invalid, conflicts int
+	planned := map[string]uint64{} // canónico -> libro que lo tomaría
+	for _, b := range all {
+		canonical, err := domain.ParseISBN(b.ISBN())
+		switch {
+		case err != nil:
+			invalid++
+			fmt.Fprintf(os.Stderr, "invalid   book %d: %q (%v)\n", b.ID(), b.ISBN(), err)
+			continue
+		case canonical == b.ISBN():
+			continue // ya está normalizado
+		}
+
+		other, _ := books.GetByISBN(ctx, canonical)
+		if other == nil && dryRun && planned[canonical] != 0 {
+			other, _ = books.GetByID(ctx, planned[canonical]) // en --dry-run aún no se guardó
+		}
+		if other != nil && other.ID() != b.ID() {
+			conflicts++
+			fmt.Fprintf(os.Stderr, "duplicate book %d: %s is also book %d\n", b.ID(), domain.FormatISBN(canonical), other.ID())
+			continue
+		}
+		fmt.Printf("book %d: %q -> %s\n", b.ID(), b.ISBN(), canonical)
+		if dryRun {
+			planned[canonical] = b.ID()
+			fixed++
+			continue
+		}
+		if err := b.SetISBN(canonical); err != nil {
+			return err
+		}
+		if err := books.Update(ctx, b); err != nil {
+			if errors.Is(err, domain.ErrDuplicate) {
+				// Otro libro guardó el mismo ISBN con otro formato y ya se normalizó
+				conflicts++
+				fmt.Fprintf(os.Stderr, "duplicate book %d: %s already taken\n", b.ID(), domain.FormatISBN(canonical))
+				continue
+			}
+			return fmt.Errorf("book %d: %w", b.ID(), err)
+		}
+		fixed++
+	}
+	fmt.Printf("books: %d, normalized: %d, invalid: %d, duplicates: %d\n", len(all), fixed, invalid, conflicts)
+	return nil
+}
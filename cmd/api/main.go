@@ -1,14 +1,21 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
+	"os"
 	"time"
 
 	apphttp "github.com/jfmg0509/sistema_libros_funcional_go/internal/transport/http"
 
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/infrastructure/blob"
 	"github.com/jfmg0509/sistema_libros_funcional_go/internal/infrastructure/config"
 	"github.com/jfmg0509/sistema_libros_funcional_go/internal/infrastructure/db"
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/infrastructure/epub"
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/infrastructure/imaging"
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/infrastructure/memory"
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/infrastructure/search"
 	"github.com/jfmg0509/sistema_libros_funcional_go/internal/usecase"
 )
 
@@ -19,17 +26,103 @@ func main() {
 		log.Fatalf("config: %v", err)
 	}
 
-	// 2) DB
-	database, err := db.Open(cfg.DSN())
-	if err != nil {
-		log.Fatalf("db open: %v", err)
+	// Subcomando: api migrate <up|down|status>
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(cfg, os.Args[2:]); err != nil {
+			log.Fatalf("migrate: %v", err)
+		}
+		return
+	}
+
+	// Subcomando: api isbn normalize [--dry-run]
+	if len(os.Args) > 1 && os.Args[1] == "isbn" {
+		if err := runISBN(cfg, os.Args[2:]); err != nil {
+			log.Fatalf("isbn: %v", err)
+		}
+		return
+	}
+
+	// 2) DB + 3) Repos (del motor elegido en DB_DRIVER)
+	var (
+		userRepo       usecase.UserRepo
+		bookRepo       usecase.BookRepo
+		accessRepo     usecase.AccessRepo
+		sessionRepo    usecase.SessionRepo
+		tokenRepo      usecase.APITokenRepo
+		authorRepo     usecase.AuthorRepo
+		taxonomyRepo   usecase.TaxonomyRepo
+		circRepo       usecase.CirculationRepo
+		relationRepo   usecase.RelationRepo
+		fileRepo       usecase.FileRepo
+		coverRepo      usecase.CoverRepo
+		progressRepo   usecase.ProgressRepo
+		annotationRepo usecase.AnnotationRepo
+		reviewRepo     usecase.ReviewRepo
+	)
+	if cfg.DBDriver == "memory" {
+		// Sin persistencia: útil para demos y pruebas manuales
+		store := memory.NewStore()
+		userRepo = memory.NewUserRepo(store)
+		bookRepo = memory.NewBookRepo(store)
+		accessRepo = memory.NewAccessRepo(store)
+		sessionRepo = memory.NewSessionRepo(store)
+		tokenRepo = memory.NewAPITokenRepo(store)
+		authorRepo = memory.NewAuthorRepo(store)
+		taxonomyRepo = memory.NewTaxonomyRepo(store)
+		circRepo = memory.NewCirculationRepo(store)
+		relationRepo = memory.NewRelationRepo(store)
+		fileRepo = memory.NewFileRepo(store)
+		coverRepo = memory.NewCoverRepo(store)
+		progressRepo = memory.NewProgressRepo(store)
+		annotationRepo = memory.NewAnnotationRepo(store)
+		reviewRepo = memory.NewReviewRepo(store)
+		log.Printf("DB_DRIVER=memory: data will be lost on restart")
+	} else {
+		// Se niega a arrancar si faltan migraciones
+		database, err := db.Open(cfg.DBDriver, cfg.DSN())
+		if err != nil {
+			log.Fatalf("db open: %v", err)
+		}
+		defer database.SQL.Close()
+
+		repos := database.Repos()
+		userRepo = repos.Users
+		bookRepo = repos.Books
+		accessRepo = repos.Access
+		sessionRepo = repos.Sessions
+		tokenRepo = repos.Tokens
+		authorRepo = repos.Authors
+		taxonomyRepo = repos.Taxonomy
+		circRepo = repos.Circulation
+		relationRepo = repos.Relations
+		fileRepo = repos.Files
+		coverRepo = repos.Covers
+		progressRepo = repos.Progress
+		annotationRepo = repos.Annotations
+		reviewRepo = repos.Reviews
 	}
-	defer database.SQL.Close()
 
-	// 3) Repos
-	userRepo := db.NewMySQLUserRepo(database.SQL)
-	bookRepo := db.NewMySQLBookRepo(database.SQL)
-	accessRepo := db.NewMySQLAccessRepo(database.SQL)
+	// Almacén de los adjuntos (carpeta local o bucket S3)
+	var blobs usecase.BlobStore
+	if cfg.BlobDriver == "s3" {
+		blobs, err = blob.NewS3Store(blob.S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Bucket:    cfg.S3Bucket,
+			Region:    cfg.S3Region,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+		}, nil)
+	} else {
+		blobs, err = blob.NewLocalStore(cfg.BlobDir)
+	}
+	if err != nil {
+		log.Fatalf("blob store: %v", err)
+	}
+	// Caché de miniaturas de portada (siempre en disco local)
+	thumbs, err := blob.NewLocalStore(cfg.CoverCacheDir)
+	if err != nil {
+		log.Fatalf("cover cache: %v", err)
+	}
 
 	// 4) Access Queue
 	queue := usecase.NewAccessQueue(accessRepo, cfg.AccessQueueSize, cfg.AccessWorkers)
@@ -37,7 +130,64 @@ func main() {
 
 	// 5) Services
 	userService := usecase.NewUserService(userRepo)
+	userService.SetCirculationRepo(circRepo)
 	bookService := usecase.NewBookService(bookRepo, userRepo, accessRepo, queue)
+	bookService.SetAuthorRepo(authorRepo)
+	bookService.SetRelationRepo(relationRepo)
+	bookService.SetFileStore(fileRepo, blobs)
+	fileService := usecase.NewFileService(fileRepo, bookRepo, blobs, cfg.UploadMaxSize)
+	fileService.SetCirculationRepo(circRepo)
+	bookService.SetCoverStore(coverRepo, blobs, thumbs)
+	coverService := usecase.NewCoverService(coverRepo, bookRepo, blobs, thumbs, imaging.NewResizer())
+	authorService := usecase.NewAuthorService(authorRepo, bookRepo)
+	taxonomyService := usecase.NewTaxonomyService(taxonomyRepo, bookRepo)
+	circService := usecase.NewCirculationService(circRepo, bookRepo, userRepo)
+	progressService := usecase.NewProgressService(progressRepo, bookRepo)
+	annotationService := usecase.NewAnnotationService(annotationRepo, bookRepo, userRepo)
+	reviewService := usecase.NewReviewService(reviewRepo, bookRepo)
+	// Lector integrado: las sesiones de lectura se registran solas
+	sessions := usecase.NewReadingSessions(accessRepo, cfg.ReaderIdle)
+	defer sessions.Close()
+	readerService := usecase.NewReaderService(fileRepo, bookRepo, blobs, epub.NewReader(), progressService, sessions, cfg.ReaderCacheSize)
+	readerService.SetCirculationRepo(circRepo)
+	authService := usecase.NewAuthService(userRepo, sessionRepo, cfg.SessionTTL)
+
+	// Índice de texto completo en memoria: se carga con el catálogo actual
+	// (y el promedio de estrellas de cada libro) y BookService lo mantiene al
+	// día en cada alta/edición/baja; ReviewService, al moderar reseñas.
+	all, err := bookRepo.List(context.Background())
+	if err != nil {
+		log.Fatalf("search index: %v", err)
+	}
+	index := search.NewIndex()
+	index.Rebuild(all)
+	ids := make([]uint64, len(all))
+	for i, b := range all {
+		ids[i] = b.ID()
+	}
+	ratings, err := reviewRepo.Summaries(context.Background(), ids)
+	if err != nil {
+		log.Fatalf("search index: %v", err)
+	}
+	for id, s := range ratings {
+		index.SetRating(id, s.Average())
+	}
+	bookService.SetSearcher(index)
+	reviewService.SetIndex(index)
+	authorService.SetSearcher(index)
+	taxonomyService.SetSearcher(index)
+	tokenService := usecase.NewTokenService(tokenRepo, userRepo)
+
+	// Administrador inicial (solo si se configuró y aún no existe)
+	if cfg.AdminEmail != "" && cfg.AdminPassword != "" {
+		created, err := userService.EnsureAdmin(context.Background(), cfg.AdminName, cfg.AdminEmail, cfg.AdminPassword)
+		if err != nil {
+			log.Fatalf("bootstrap admin: %v", err)
+		}
+		if created {
+			log.Printf("admin user %s created", cfg.AdminEmail)
+		}
+	}
 
 	// 6) Renderer (usa tu render.go)
 	renderer, err := apphttp.NewRenderer("web/templates")
@@ -46,7 +196,21 @@ func main() {
 	}
 
 	// 7) Handler único (UI + API)
-	h := apphttp.NewHandler(userService, bookService, renderer)
+	h := apphttp.NewHandler(apphttp.Services{
+		Users:       userService,
+		Books:       bookService,
+		Auth:        authService,
+		Tokens:      tokenService,
+		Authors:     authorService,
+		Taxonomy:    taxonomyService,
+		Circulation: circService,
+		Files:       fileService,
+		Covers:      coverService,
+		Progress:    progressService,
+		Reader:      readerService,
+		Annotations: annotationService,
+		Reviews:     reviewService,
+	}, renderer, cfg.SecureCookies)
 
 	// 8) Router
 	router := apphttp.NewRouter(h)
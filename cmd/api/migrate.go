@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/infrastructure/config"
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/infrastructure/db"
+)
+
+const migrateUsage = `uso: api migrate <up | down [N] | status>
+
+  up        aplica todas las migraciones pendientes
+  down [N]  revierte las últimas N migraciones (por defecto 1)
+  status    lista migraciones aplicadas y pendientes`
+
+// runMigrate implementa el subcomando "migrate".
+func runMigrate(cfg config.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%s", migrateUsage)
+	}
+
+	if cfg.DBDriver == "memory" {
+		return fmt.Errorf("DB_DRIVER=memory has no schema to migrate")
+	}
+
+	database, err := db.OpenUnchecked(cfg.DBDriver, cfg.DSN())
+	if err != nil {
+		return fmt.Errorf("db open: %w", err)
+	}
+	defer database.SQL.Close()
+
+	m, err := db.NewMigrator(database.SQL, database.Driver)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	switch args[0] {
+	case "up":
+		done, err := m.Up(ctx)
+		for _, mg := range done {
+			fmt.Printf("applied  %04d_%s\n", mg.Version, mg.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(done) == 0 {
+			fmt.Println("schema is up to date")
+		}
+		return nil
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("down: N must be a positive integer")
+			}
+			steps = n
+		}
+		done, err := m.Down(ctx, steps)
+		for _, mg := range done {
+			fmt.Printf("reverted %04d_%s\n", mg.Version, mg.Name)
+		}
+		return err
+
+	case "status":
+		list, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, st := range list {
+			state := "pending"
+			if st.Applied {
+				state = "applied " + st.AppliedAt.Format(time.RFC3339)
+			}
+			if st.Modified {
+				state += " (MODIFIED: checksum mismatch)"
+			}
+			fmt.Printf("%04d_%-30s %s\n", st.Version, st.Name, state)
+		}
+		return nil
+	}
+
+	fmt.Fprintln(os.Stderr, migrateUsage)
+	return fmt.Errorf("unknown migrate command %q", args[0])
+}
@@ -1,43 +0,0 @@
-CREATE DATABASE IF NOT EXISTS libros_poo CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci;
-USE libros_poo;
-
-CREATE TABLE IF NOT EXISTS users (
-  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
-  name VARCHAR(120) NOT NULL,
-  email VARCHAR(180) NOT NULL,
-  role VARCHAR(20) NOT NULL,
-  active TINYINT(1) NOT NULL DEFAULT 1,
-  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-  updated_at TIMESTAMP NULL DEFAULT NULL ON UPDATE CURRENT_TIMESTAMP,
-  PRIMARY KEY (id),
-  UNIQUE KEY uq_users_email (email)
-) ENGINE=InnoDB;
-
-CREATE TABLE IF NOT EXISTS books (
-  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
-  title VARCHAR(200) NOT NULL,
-  author VARCHAR(160) NOT NULL,
-  year INT NOT NULL,
-  isbn VARCHAR(50) NOT NULL,
-  category VARCHAR(80) NOT NULL,
-  tags VARCHAR(255) NOT NULL DEFAULT '',
-  description TEXT NULL,
-  active TINYINT(1) NOT NULL DEFAULT 1,
-  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-  updated_at TIMESTAMP NULL DEFAULT NULL ON UPDATE CURRENT_TIMESTAMP,
-  PRIMARY KEY (id),
-  UNIQUE KEY uq_books_isbn (isbn)
-) ENGINE=InnoDB;
-
-CREATE TABLE IF NOT EXISTS access_events (
-  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
-  user_id BIGINT UNSIGNED NOT NULL,
-  book_id BIGINT UNSIGNED NOT NULL,
-  access_type VARCHAR(20) NOT NULL,
-  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-  PRIMARY KEY (id),
-  KEY idx_access_events_book (book_id),
-  KEY idx_access_events_user (user_id),
-  CONSTRAINT fk_access_user FOREIGN KEY (user_id) REFERENCES users(id),
-  CONSTRAINT fk_access_book FOREIGN KEY (book_id) REFERENCES books(id)
-) ENGINE=InnoDB;
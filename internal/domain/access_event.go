@@ -9,11 +9,22 @@ import (
 // AccessEvent representa un evento de acceso de un usuario a un libro.
 // Es una entidad del dominio basada en eventos (event-driven).
 type AccessEvent struct {
-	id         uint64     // ID único del evento (asignado por BD)
-	userID     uint64     // ID del usuario que accede
-	bookID     uint64     // ID del libro accedido
-	accessType AccessType // Tipo de acceso (VIEW, DOWNLOAD, etc.)
-	createdAt  time.Time  // Fecha y hora del evento
+	id         uint64        // ID único del evento (asignado por BD)
+	userID     uint64        // ID del usuario que accede
+	bookID     uint64        // ID del libro accedido
+	accessType AccessType    // Tipo de acceso (VIEW, DOWNLOAD, etc.)
+	duration   time.Duration // Tiempo leído (solo sesiones de LECTURA)
+	createdAt  time.Time     // Fecha y hora del evento
+}
+
+// MaxReadingSession acota la duración de una sesión de lectura: más que eso
+// es una pestaña olvidada, no lectura.
+const MaxReadingSession = 8 * time.Hour
+
+// ReadingTime resume las sesiones de lectura registradas para un libro.
+type ReadingTime struct {
+	Sessions int           // Cantidad de sesiones
+	Total    time.Duration // Tiempo total leído
 }
 
 // NewAccessEvent es el constructor del evento.
@@ -42,11 +53,29 @@ func NewAccessEvent(userID, bookID uint64, accessType AccessType) (*AccessEvent,
 	}, nil
 }
 
+// NewReadingSession crea el evento LECTURA de una sesión del lector
+// integrado con el tiempo que duró (se redondea a segundos).
+func NewReadingSession(userID, bookID uint64, d time.Duration) (*AccessEvent, error) {
+
+	// Valida la duración antes de crear el evento
+	if d < 0 || d > MaxReadingSession {
+		return nil, fmt.Errorf("%w: reading session must last between 0 and %s", ErrValidation, MaxReadingSession)
+	}
+
+	e, err := NewAccessEvent(userID, bookID, AccessLectura)
+	if err != nil {
+		return nil, err
+	}
+	e.duration = d.Round(time.Second)
+	return e, nil
+}
+
 // HydrateAccessEvent reconstruye un evento desde la base de datos.
 // Se usa cuando se leen registros ya persistidos.
 func HydrateAccessEvent(
 	id, userID, bookID uint64,
 	accessType AccessType,
+	duration time.Duration,
 	createdAt time.Time,
 ) (*AccessEvent, error) {
 
@@ -58,6 +87,7 @@ func HydrateAccessEvent(
 
 	// Sobrescribe datos provenientes de BD
 	e.id = id
+	e.duration = duration
 	e.createdAt = createdAt
 
 	return e, nil
@@ -77,5 +107,8 @@ func (e *AccessEvent) BookID() uint64 { return e.bookID }
 // AccessType devuelve el tipo de acceso
 func (e *AccessEvent) AccessType() AccessType { return e.accessType }
 
+// Duration devuelve cuánto duró la sesión de lectura (0 en otros tipos)
+func (e *AccessEvent) Duration() time.Duration { return e.duration }
+
 // CreatedAt devuelve la fecha del evento
 func (e *AccessEvent) CreatedAt() time.Time { return e.createdAt }
@@ -0,0 +1,248 @@
+package domain // Dominio: subrayados, marcadores y notas de un usuario sobre un libro
+
+import (
+	"fmt"          // Errores con contexto
+	"strings"      // Normalización de textos y valores
+	"time"         // Fechas de alta y edición
+	"unicode/utf8" // Largos en caracteres
+)
+
+// Largos máximos (en caracteres) del texto subrayado y de la nota.
+const (
+	MaxAnnotationQuoteLen = 2000
+	MaxAnnotationNoteLen  = 5000
+)
+
+// -------------------- AnnotationVisibility --------------------
+
+// AnnotationVisibility indica quién además del autor puede ver la anotación.
+type AnnotationVisibility string
+
+const (
+	AnnotationPrivate AnnotationVisibility = "private" // Solo el autor
+	AnnotationClass   AnnotationVisibility = "class"   // El autor y los docentes (permiso de estadísticas)
+	AnnotationPublic  AnnotationVisibility = "public"  // Cualquier lector del libro
+)
+
+// AnnotationVisibilities son las visibilidades aceptadas, de la más cerrada a la más abierta.
+var AnnotationVisibilities = []AnnotationVisibility{AnnotationPrivate, AnnotationClass, AnnotationPublic}
+
+// ParseAnnotationVisibility normaliza la visibilidad ("" => private).
+func ParseAnnotationVisibility(s string) (AnnotationVisibility, error) {
+	v := AnnotationVisibility(strings.ToLower(strings.TrimSpace(s)))
+	if v == "" {
+		return AnnotationPrivate, nil
+	}
+	for _, ok := range AnnotationVisibilities {
+		if v == ok {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("%w: visibility must be private, class or public", ErrValidation)
+}
+
+// -------------------- AnnotationColor --------------------
+
+// AnnotationColor es el color del resaltado.
+type AnnotationColor string
+
+const (
+	ColorYellow AnnotationColor = "yellow"
+	ColorGreen  AnnotationColor = "green"
+	ColorBlue   AnnotationColor = "blue"
+	ColorPink   AnnotationColor = "pink"
+	ColorPurple AnnotationColor = "purple"
+)
+
+// AnnotationColors son los colores aceptados, en el orden en que se muestran.
+var AnnotationColors = []AnnotationColor{ColorYellow, ColorGreen, ColorBlue, ColorPink, ColorPurple}
+
+// ParseAnnotationColor normaliza el color ("" => yellow).
+func ParseAnnotationColor(s string) (AnnotationColor, error) {
+	c := AnnotationColor(strings.ToLower(strings.TrimSpace(s)))
+	if c == "" {
+		return ColorYellow, nil
+	}
+	for _, ok := range AnnotationColors {
+		if c == ok {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("%w: unknown color %q", ErrValidation, s)
+}
+
+// -------------------- AnnotationContent --------------------
+
+// AnnotationContent es lo que escribe el usuario: dónde (ancla), qué texto
+// marcó y qué nota dejó. Sin texto ni nota es un marcador.
+type AnnotationContent struct {
+	Location   string               // Ancla, p. ej. "epubcfi(/6/4!/4/2/1:0)" o "página 12"
+	Quote      string               // Texto subrayado (opcional)
+	Note       string               // Nota del usuario (opcional)
+	Color      AnnotationColor      // "" => yellow
+	Visibility AnnotationVisibility // "" => private
+}
+
+// Normalize recorta los textos, completa los valores por defecto y valida
+// el contenido. Retorna ErrValidation si no es válido.
+func (c AnnotationContent) Normalize() (AnnotationContent, error) {
+	c.Location = strings.TrimSpace(c.Location)
+	c.Quote = strings.TrimSpace(c.Quote)
+	c.Note = strings.TrimSpace(c.Note)
+	switch {
+	case c.Location == "":
+		return c, fmt.Errorf("%w: location is required", ErrValidation)
+	case utf8.RuneCountInString(c.Location) > MaxReadingLocationLen:
+		return c, fmt.Errorf("%w: location too long (max %d characters)", ErrValidation, MaxReadingLocationLen)
+	case strings.HasPrefix(c.Location, "epubcfi(") && !strings.HasSuffix(c.Location, ")"):
+		return c, fmt.Errorf("%w: malformed EPUB CFI", ErrValidation)
+	case utf8.RuneCountInString(c.Quote) > MaxAnnotationQuoteLen:
+		return c, fmt.Errorf("%w: quote too long (max %d characters)", ErrValidation, MaxAnnotationQuoteLen)
+	case utf8.RuneCountInString(c.Note) > MaxAnnotationNoteLen:
+		return c, fmt.Errorf("%w: note too long (max %d characters)", ErrValidation, MaxAnnotationNoteLen)
+	}
+	var err error
+	if c.Color, err = ParseAnnotationColor(string(c.Color)); err != nil {
+		return c, err
+	}
+	if c.Visibility, err = ParseAnnotationVisibility(string(c.Visibility)); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+// -------------------- Annotation --------------------
+
+// Tipos de anotación (se deducen del contenido).
+const (
+	AnnotationBookmark  = "bookmark"  // Solo ancla
+	AnnotationHighlight = "highlight" // Texto subrayado sin nota
+	AnnotationNote      = "note"      // Con nota (con o sin texto subrayado)
+)
+
+// Annotation es un subrayado, marcador o nota de un usuario en un libro.
+type Annotation struct {
+	id        uint64            // ID único (asignado por BD)
+	userID    uint64            // Autor
+	bookID    uint64            // Libro
+	content   AnnotationContent // Ancla, texto, nota, color y visibilidad
+	createdAt time.Time         // Fecha de alta
+	updatedAt time.Time         // Última edición
+}
+
+// NewAnnotation valida una anotación nueva del usuario en el libro.
+func NewAnnotation(userID, bookID uint64, c AnnotationContent) (*Annotation, error) {
+	if userID == 0 || bookID == 0 {
+		return nil, fmt.Errorf("%w: user_id and book_id are required", ErrValidation)
+	}
+	c, err := c.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return &Annotation{userID: userID, bookID: bookID, content: c, createdAt: now, updatedAt: now}, nil
+}
+
+// HydrateAnnotation reconstruye una anotación desde la persistencia (sin validar).
+func HydrateAnnotation(id, userID, bookID uint64, c AnnotationContent, createdAt, updatedAt time.Time) *Annotation {
+	return &Annotation{id: id, userID: userID, bookID: bookID, content: c, createdAt: createdAt, updatedAt: updatedAt}
+}
+
+// Edit reemplaza el contenido (validándolo) y marca la fecha de edición.
+func (a *Annotation) Edit(c AnnotationContent) error {
+	c, err := c.Normalize()
+	if err != nil {
+		return err
+	}
+	a.content = c
+	a.updatedAt = time.Now()
+	return nil
+}
+
+// ID devuelve el ID de la anotación
+func (a *Annotation) ID() uint64 { return a.id }
+
+// UserID devuelve el autor
+func (a *Annotation) UserID() uint64 { return a.userID }
+
+// BookID devuelve el libro
+func (a *Annotation) BookID() uint64 { return a.bookID }
+
+// Content devuelve el contenido completo
+func (a *Annotation) Content() AnnotationContent { return a.content }
+
+// Location devuelve el ancla dentro del libro
+func (a *Annotation) Location() string { return a.content.Location }
+
+// Quote devuelve el texto subrayado ("" si no hay)
+func (a *Annotation) Quote() string { return a.content.Quote }
+
+// Note devuelve la nota ("" si no hay)
+func (a *Annotation) Note() string { return a.content.Note }
+
+// Color devuelve el color del resaltado
+func (a *Annotation) Color() AnnotationColor { return a.content.Color }
+
+// Visibility devuelve quién puede verla
+func (a *Annotation) Visibility() AnnotationVisibility { return a.content.Visibility }
+
+// CreatedAt devuelve la fecha de alta
+func (a *Annotation) CreatedAt() time.Time { return a.createdAt }
+
+// UpdatedAt devuelve la última edición
+func (a *Annotation) UpdatedAt() time.Time { return a.updatedAt }
+
+// Kind deduce el tipo: nota si tiene nota, subrayado si solo tiene texto,
+// marcador si no tiene ninguno.
+func (a *Annotation) Kind() string {
+	switch {
+	case a.content.Note != "":
+		return AnnotationNote
+	case a.content.Quote != "":
+		return AnnotationHighlight
+	default:
+		return AnnotationBookmark
+	}
+}
+
+// AnnotationDetail es una anotación con los datos que muestran los listados.
+type AnnotationDetail struct {
+	Annotation *Annotation
+	BookTitle  string // Título del libro
+	UserName   string // Nombre del autor
+}
+
+// -------------------- AnnotationFilter --------------------
+
+// AnnotationFilter encapsula filtros para listar anotaciones.
+type AnnotationFilter struct {
+	UserID       uint64                 // Del autor (0 = todos)
+	BookID       uint64                 // Del libro (0 = todos)
+	Visibilities []AnnotationVisibility // Alguna de estas (vacío = cualquiera)
+	Color        AnnotationColor        // "" = cualquiera
+
+	PageRequest // Página, tamaño y orden (created_at por defecto, updated_at, location)
+}
+
+// AnnotationSortFields son los campos por los que se puede ordenar anotaciones.
+var AnnotationSortFields = []string{SortCreatedAt, "updated_at", "location"}
+
+// Paging normaliza la paginación (por defecto lo más reciente primero) y
+// valida el filtro.
+func (f AnnotationFilter) Paging() (PageRequest, error) {
+	for _, v := range f.Visibilities {
+		if got, err := ParseAnnotationVisibility(string(v)); err != nil || got != v {
+			return PageRequest{}, fmt.Errorf("%w: visibility must be private, class or public", ErrValidation)
+		}
+	}
+	if f.Color != "" {
+		if _, err := ParseAnnotationColor(string(f.Color)); err != nil {
+			return PageRequest{}, err
+		}
+	}
+	p := f.PageRequest
+	if p.Sort == "updated_at" && p.Dir == "" {
+		p.Dir = SortDesc
+	}
+	return p.Normalize(AnnotationSortFields)
+}
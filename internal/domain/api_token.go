@@ -0,0 +1,173 @@
+package domain // Dominio: tokens personales de API
+
+import (
+	"fmt"     // Para construir errores con contexto
+	"strings" // Para normalizar nombre y scopes
+	"time"    // Para expiración y último uso
+)
+
+// APIToken representa un token personal para acceder a /api sin navegador.
+// Igual que las sesiones, solo se guarda el hash del token.
+type APIToken struct {
+	id         uint64       // ID único (asignado por BD)
+	userID     uint64       // Dueño del token
+	name       string       // Nombre descriptivo ("carga catálogo")
+	tokenHash  string       // SHA-256 (hex) del token
+	prefix     string       // Primeros caracteres, para reconocerlo en listados
+	scopes     []Permission // Permisos que el token puede ejercer
+	expiresAt  time.Time    // Cero => no expira
+	lastUsedAt time.Time    // Cero => nunca usado
+	revokedAt  time.Time    // Cero => vigente
+	createdAt  time.Time    // Fecha de creación
+}
+
+// NewAPIToken crea un token válido.
+// Los scopes deben pertenecer al catálogo y no pueden repetirse.
+func NewAPIToken(userID uint64, name, tokenHash, prefix string, scopes []Permission, expiresAt time.Time) (*APIToken, error) {
+
+	name = strings.TrimSpace(name)
+	if len(name) < 2 {
+		return nil, fmt.Errorf("%w: token name must have at least 2 characters", ErrValidation)
+	}
+	if userID == 0 || tokenHash == "" {
+		return nil, fmt.Errorf("%w: user_id and token are required", ErrValidation)
+	}
+	if !expiresAt.IsZero() && !expiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("%w: token expiration must be in the future", ErrValidation)
+	}
+
+	cleaned, err := normalizeScopes(scopes)
+	if err != nil {
+		return nil, err
+	}
+	if len(cleaned) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", ErrValidation)
+	}
+
+	return &APIToken{
+		userID:    userID,
+		name:      name,
+		tokenHash: tokenHash,
+		prefix:    prefix,
+		scopes:    cleaned,
+		expiresAt: expiresAt,
+		createdAt: time.Now(),
+	}, nil
+}
+
+// HydrateAPIToken reconstruye un token desde la base de datos.
+func HydrateAPIToken(
+	id, userID uint64,
+	name, tokenHash, prefix, scopesCSV string,
+	expiresAt, lastUsedAt, revokedAt, createdAt time.Time,
+) *APIToken {
+	scopes := make([]Permission, 0)
+	for _, s := range strings.Split(scopesCSV, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			scopes = append(scopes, Permission(s))
+		}
+	}
+	return &APIToken{
+		id:         id,
+		userID:     userID,
+		name:       name,
+		tokenHash:  tokenHash,
+		prefix:     prefix,
+		scopes:     scopes,
+		expiresAt:  expiresAt,
+		lastUsedAt: lastUsedAt,
+		revokedAt:  revokedAt,
+		createdAt:  createdAt,
+	}
+}
+
+// -------------------- Getters --------------------
+
+// ID devuelve el ID del token
+func (t *APIToken) ID() uint64 { return t.id }
+
+// UserID devuelve el dueño
+func (t *APIToken) UserID() uint64 { return t.userID }
+
+// Name devuelve el nombre descriptivo
+func (t *APIToken) Name() string { return t.name }
+
+// TokenHash devuelve el hash del token
+func (t *APIToken) TokenHash() string { return t.tokenHash }
+
+// Prefix devuelve el prefijo visible
+func (t *APIToken) Prefix() string { return t.prefix }
+
+// Scopes devuelve una COPIA de los scopes
+func (t *APIToken) Scopes() []Permission { return append([]Permission{}, t.scopes...) }
+
+// ScopesCSV devuelve los scopes como CSV (útil para guardar en BD)
+func (t *APIToken) ScopesCSV() string {
+	parts := make([]string, 0, len(t.scopes))
+	for _, s := range t.scopes {
+		parts = append(parts, string(s))
+	}
+	return strings.Join(parts, ",")
+}
+
+// ExpiresAt devuelve la expiración (cero => no expira)
+func (t *APIToken) ExpiresAt() time.Time { return t.expiresAt }
+
+// LastUsedAt devuelve el último uso (cero => nunca)
+func (t *APIToken) LastUsedAt() time.Time { return t.lastUsedAt }
+
+// RevokedAt devuelve la fecha de revocación (cero => vigente)
+func (t *APIToken) RevokedAt() time.Time { return t.revokedAt }
+
+// CreatedAt devuelve la fecha de creación
+func (t *APIToken) CreatedAt() time.Time { return t.createdAt }
+
+// -------------------- Comportamiento --------------------
+
+// HasScope indica si el token puede ejercer el permiso indicado
+func (t *APIToken) HasScope(p Permission) bool {
+	for _, s := range t.scopes {
+		if s == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Revoked indica si el token fue revocado
+func (t *APIToken) Revoked() bool { return !t.revokedAt.IsZero() }
+
+// Expired indica si el token ya venció en el instante now
+func (t *APIToken) Expired(now time.Time) bool {
+	return !t.expiresAt.IsZero() && !now.Before(t.expiresAt)
+}
+
+// Usable indica si el token puede autenticar en el instante now
+func (t *APIToken) Usable(now time.Time) bool { return !t.Revoked() && !t.Expired(now) }
+
+// Revoke marca el token como revocado (idempotente)
+func (t *APIToken) Revoke(now time.Time) {
+	if t.revokedAt.IsZero() {
+		t.revokedAt = now
+	}
+}
+
+// -------------------- Helpers --------------------
+
+// normalizeScopes valida scopes contra el catálogo y elimina repetidos
+func normalizeScopes(scopes []Permission) ([]Permission, error) {
+	seen := map[Permission]bool{}
+	out := make([]Permission, 0, len(scopes))
+	for _, s := range scopes {
+		s = Permission(strings.ToLower(strings.TrimSpace(string(s))))
+		if s == "" || seen[s] {
+			continue
+		}
+		if !s.IsValid() {
+			return nil, fmt.Errorf("%w: unknown scope %q", ErrValidation, s)
+		}
+		seen[s] = true
+		out = append(out, s)
+	}
+	return out, nil
+}
@@ -0,0 +1,202 @@
+package domain // Dominio: autores y créditos de un libro (autor, editor, traductor, ilustrador)
+
+import (
+	"fmt"          // Errores con contexto
+	"strings"      // Limpieza de nombres y armado del byline
+	"time"         // Fechas de creación/actualización
+	"unicode/utf8" // Largo de nombres en caracteres (no bytes)
+)
+
+// MaxAuthorNameLen es el largo máximo de un nombre (columna authors.name y
+// books.author, donde se guarda el byline).
+const MaxAuthorNameLen = 180
+
+// Author representa a una persona acreditada en uno o más libros.
+// Todos los campos son privados → encapsulación real.
+type Author struct {
+	id        uint64    // ID único (asignado por BD)
+	name      string    // Nombre para mostrar ("Gabriel García Márquez")
+	bio       string    // Nota biográfica (opcional)
+	createdAt time.Time // Fecha de creación
+	updatedAt time.Time // Fecha de última actualización
+}
+
+// NewAuthor crea un autor válido.
+func NewAuthor(name, bio string) (*Author, error) {
+	a := &Author{}
+	if err := a.SetName(name); err != nil {
+		return nil, err
+	}
+	a.SetBio(bio)
+	a.createdAt = time.Now()
+	return a, nil
+}
+
+// HydrateAuthor reconstruye un Author desde la persistencia (sin validar).
+func HydrateAuthor(id uint64, name, bio string, createdAt, updatedAt time.Time) *Author {
+	return &Author{id: id, name: name, bio: bio, createdAt: createdAt, updatedAt: updatedAt}
+}
+
+// -------------------- Getters --------------------
+
+// ID devuelve el ID del autor
+func (a *Author) ID() uint64 { return a.id }
+
+// Name devuelve el nombre
+func (a *Author) Name() string { return a.name }
+
+// Bio devuelve la nota biográfica
+func (a *Author) Bio() string { return a.bio }
+
+// CreatedAt devuelve fecha creación
+func (a *Author) CreatedAt() time.Time { return a.createdAt }
+
+// UpdatedAt devuelve fecha actualización
+func (a *Author) UpdatedAt() time.Time { return a.updatedAt }
+
+// -------------------- Setters --------------------
+
+// SetName valida y asigna el nombre (mismas reglas que Book.SetAuthor)
+func (a *Author) SetName(name string) error {
+	name = strings.Join(strings.Fields(name), " ")
+	if len(name) < 2 {
+		return fmt.Errorf("%w: author name must have at least 2 characters", ErrValidation)
+	}
+	if utf8.RuneCountInString(name) > MaxAuthorNameLen {
+		return fmt.Errorf("%w: author name too long (max %d)", ErrValidation, MaxAuthorNameLen)
+	}
+	a.name = name
+	a.updatedAt = time.Now()
+	return nil
+}
+
+// SetBio asigna la nota biográfica
+func (a *Author) SetBio(bio string) {
+	a.bio = strings.TrimSpace(bio)
+	a.updatedAt = time.Now()
+}
+
+// -------------------- Créditos --------------------
+
+// CreditRole es la función de un autor en un libro.
+type CreditRole string
+
+const (
+	CreditAuthor      CreditRole = "author"      // Autor / coautor
+	CreditEditor      CreditRole = "editor"      // Editor o compilador del volumen
+	CreditTranslator  CreditRole = "translator"  // Traductor
+	CreditIllustrator CreditRole = "illustrator" // Ilustrador
+)
+
+// CreditRoles son los roles aceptados, en el orden en que se muestran.
+var CreditRoles = []CreditRole{CreditAuthor, CreditEditor, CreditTranslator, CreditIllustrator}
+
+// ParseCreditRole normaliza el rol ("Editor" -> editor); vacío => author.
+func ParseCreditRole(s string) (CreditRole, error) {
+	r := CreditRole(strings.ToLower(strings.TrimSpace(s)))
+	if r == "" {
+		return CreditAuthor, nil
+	}
+	for _, ok := range CreditRoles {
+		if r == ok {
+			return r, nil
+		}
+	}
+	return "", fmt.Errorf("%w: unknown credit role %q", ErrValidation, s)
+}
+
+// BookCredit es la participación de un autor en un libro. Position ordena
+// los créditos (0 = primero); el repositorio la asigna según el orden de la lista.
+type BookCredit struct {
+	AuthorID uint64
+	Name     string // Nombre del autor (lo completa el repositorio al leer)
+	Role     CreditRole
+	Position int
+}
+
+// AuthorBook es un libro de un autor junto con su rol en él.
+type AuthorBook struct {
+	Book *Book
+	Role CreditRole
+}
+
+// ValidateCredits verifica una lista de créditos antes de guardarla: al menos
+// un crédito, roles válidos y sin repetir el mismo autor con el mismo rol.
+func ValidateCredits(credits []BookCredit) error {
+	if len(credits) == 0 {
+		return fmt.Errorf("%w: a book needs at least one credit", ErrValidation)
+	}
+	seen := map[BookCredit]bool{}
+	for _, c := range credits {
+		if c.AuthorID == 0 {
+			return fmt.Errorf("%w: credit without author", ErrValidation)
+		}
+		if _, err := ParseCreditRole(string(c.Role)); err != nil || c.Role == "" {
+			return fmt.Errorf("%w: unknown credit role %q", ErrValidation, c.Role)
+		}
+		k := BookCredit{AuthorID: c.AuthorID, Role: c.Role}
+		if seen[k] {
+			return fmt.Errorf("%w: author %d is credited twice as %s", ErrValidation, c.AuthorID, c.Role)
+		}
+		seen[k] = true
+	}
+	return nil
+}
+
+// Byline arma el texto de autoría que se guarda en Book.Author (listados,
+// búsqueda, facetas y orden por autor):
+//   - los autores en orden ("Ana, Beto");
+//   - sin autores, los editores con "(ed.)" / "(eds.)";
+//   - si no, el primer crédito.
+//
+// Si no cabe en MaxAuthorNameLen se abrevia con "et al.".
+func Byline(credits []BookCredit) string {
+	var authors, editors []string
+	for _, c := range credits {
+		switch c.Role {
+		case CreditAuthor:
+			authors = append(authors, c.Name)
+		case CreditEditor:
+			editors = append(editors, c.Name)
+		}
+	}
+
+	var names []string
+	suffix := ""
+	switch {
+	case len(authors) > 0:
+		names = authors
+	case len(editors) == 1:
+		names, suffix = editors, " (ed.)"
+	case len(editors) > 1:
+		names, suffix = editors, " (eds.)"
+	case len(credits) > 0:
+		names = []string{credits[0].Name}
+	default:
+		return ""
+	}
+
+	out := strings.Join(names, ", ") + suffix
+	if utf8.RuneCountInString(out) > MaxAuthorNameLen {
+		out = names[0] + " et al." + suffix
+	}
+	if utf8.RuneCountInString(out) > MaxAuthorNameLen {
+		out = names[0]
+	}
+	return out
+}
+
+// -------------------- AuthorFilter --------------------
+
+// AuthorFilter encapsula filtros para listar autores.
+type AuthorFilter struct {
+	Q string // Subcadena del nombre (sin distinguir mayúsculas)
+
+	PageRequest // Página, tamaño y orden (name, created_at)
+}
+
+// AuthorSortFields son los campos por los que se puede ordenar autores.
+var AuthorSortFields = []string{SortCreatedAt, "name"}
+
+// Paging normaliza la paginación del filtro de autores.
+func (f AuthorFilter) Paging() (PageRequest, error) { return f.PageRequest.Normalize(AuthorSortFields) }
@@ -14,8 +14,8 @@ type Book struct {
 	author      string    // Autor
 	year        int       // Año de publicación
 	isbn        string    // ISBN (regla: único)
-	category    string    // Categoría
-	tags        []string  // Etiquetas (slice)
+	category    string    // Categoría (nombre canónico en el árbol de categorías)
+	tags        []string  // Etiquetas normalizadas (ver NormalizeTag), sin repetir
 	description string    // Descripción
 	active      bool      // Estado lógico
 	createdAt   time.Time // Fecha de creación
@@ -31,6 +31,19 @@ func NewBook(
 	tags []string,
 	description string,
 ) (*Book, error) {
+	return newBook(title, author, year, isbn, category, tags, description, (*Book).SetISBN)
+}
+
+// newBook aplica las validaciones comunes; setISBN permite que HydrateBook
+// acepte ISBN heredados sin dígito de control válido.
+func newBook(
+	title, author string,
+	year int,
+	isbn, category string,
+	tags []string,
+	description string,
+	setISBN func(b *Book, isbn string) error,
+) (*Book, error) {
 
 	// Libro activo por defecto
 	b := &Book{active: true}
@@ -45,15 +58,17 @@ func NewBook(
 	if err := b.SetYear(year); err != nil {
 		return nil, err
 	}
-	if err := b.SetISBN(isbn); err != nil {
+	if err := setISBN(b, isbn); err != nil {
 		return nil, err
 	}
 	if err := b.SetCategory(category); err != nil {
 		return nil, err
 	}
+	if err := b.SetTags(tags); err != nil {
+		return nil, err
+	}
 
-	// Tags y descripción no retornan error
-	b.SetTags(tags)
+	// La descripción no retorna error
 	b.SetDescription(description)
 
 	// Fecha de creación
@@ -62,22 +77,21 @@ func NewBook(
 	return b, nil
 }
 
-// HydrateBook reconstruye un Book desde la base de datos.
-// Se usa al leer desde MySQL.
+// HydrateBook reconstruye un Book desde la persistencia
+// (las etiquetas se leen de la tabla book_tags).
 func HydrateBook(
 	id uint64,
 	title, author string,
 	year int,
-	isbn, category, tagsCSV, description string,
+	isbn, category string,
+	tags []string,
+	description string,
 	active bool,
 	createdAt, updatedAt time.Time,
 ) (*Book, error) {
 
-	// Convierte CSV a slice
-	tags := splitTags(tagsCSV)
-
-	// Reutiliza constructor para validar datos
-	b, err := NewBook(title, author, year, isbn, category, tags, description)
+	// Reutiliza las validaciones del constructor (ISBN tolerante con datos previos)
+	b, err := newBook(title, author, year, isbn, category, tags, description, (*Book).setLegacyISBN)
 	if err != nil {
 		return nil, err
 	}
@@ -105,9 +119,13 @@ func (b *Book) Author() string { return b.author }
 // Year devuelve el año
 func (b *Book) Year() int { return b.year }
 
-// ISBN devuelve el ISBN
+// ISBN devuelve el ISBN en forma canónica (ISBN-13 sin guiones; los datos
+// heredados sin normalizar se devuelven como se guardaron)
 func (b *Book) ISBN() string { return b.isbn }
 
+// ISBNDisplay devuelve el ISBN con guiones para mostrar ("978-84-376-0494-7")
+func (b *Book) ISBNDisplay() string { return FormatISBN(NormalizeISBN(b.isbn)) }
+
 // Category devuelve la categoría
 func (b *Book) Category() string { return b.category }
 
@@ -162,39 +180,62 @@ func (b *Book) SetYear(year int) error {
 	return nil
 }
 
-// SetISBN valida y asigna ISBN
+// SetISBN valida el ISBN-10/13 (dígito de control incluido) y lo guarda
+// en forma canónica ISBN-13 (ver ParseISBN)
 func (b *Book) SetISBN(isbn string) error {
+	canonical, err := ParseISBN(isbn)
+	if err != nil {
+		return err
+	}
+	b.isbn = canonical
+	b.updatedAt = time.Now()
+	return nil
+}
+
+// setLegacyISBN acepta tal cual los ISBN guardados antes de validar el dígito
+// de control (regla anterior); "api isbn normalize" los pasa a forma canónica.
+func (b *Book) setLegacyISBN(isbn string) error {
 	isbn = strings.TrimSpace(isbn)
 	if len(isbn) < 5 {
 		return fmt.Errorf("%w: isbn too short", ErrValidation)
 	}
 	b.isbn = isbn
-	b.updatedAt = time.Now()
 	return nil
 }
 
-// SetCategory valida y asigna categoría
+// SetCategory valida y asigna categoría (el repositorio la resuelve por slug
+// a una categoría existente o crea una nueva en la raíz)
 func (b *Book) SetCategory(category string) error {
-	category = strings.TrimSpace(category)
-	if len(category) < 2 {
-		return fmt.Errorf("%w: category too short", ErrValidation)
+	category, err := cleanCategory(category)
+	if err != nil {
+		return err
 	}
 	b.category = category
 	b.updatedAt = time.Now()
 	return nil
 }
 
-// SetTags limpia y asigna etiquetas (slice)
-func (b *Book) SetTags(tags []string) {
+// SetTags normaliza y asigna etiquetas (slice): se descartan las vacías y
+// las repetidas (mismo slug, p. ej. "Ciencia Ficción" y "ciencia-ficcion")
+func (b *Book) SetTags(tags []string) error {
 	cleaned := make([]string, 0, len(tags))
+	seen := map[string]bool{}
 	for _, t := range tags {
-		t = strings.TrimSpace(t)
-		if t != "" {
+		if strings.TrimSpace(t) == "" {
+			continue
+		}
+		t, err := cleanTag(t)
+		if err != nil {
+			return err
+		}
+		if slug := Slugify(t); !seen[slug] {
+			seen[slug] = true
 			cleaned = append(cleaned, t)
 		}
 	}
 	b.tags = cleaned
 	b.updatedAt = time.Now()
+	return nil
 }
 
 // SetDescription asigna descripción
@@ -214,37 +255,3 @@ func (b *Book) Activate() {
 	b.active = true
 	b.updatedAt = time.Now()
 }
-
-// -------------------- Helpers --------------------
-
-// splitTags convierte un CSV en slice
-func splitTags(csv string) []string {
-	csv = strings.TrimSpace(csv)
-	if csv == "" {
-		return nil
-	}
-	parts := strings.Split(csv, ",")
-	out := make([]string, 0, len(parts))
-	for _, p := range parts {
-		p = strings.TrimSpace(p)
-		if p != "" {
-			out = append(out, p)
-		}
-	}
-	return out
-}
-
-// JoinTags convierte un slice en CSV (útil para guardar en BD)
-func JoinTags(tags []string) string {
-	if len(tags) == 0 {
-		return ""
-	}
-	cleaned := make([]string, 0, len(tags))
-	for _, t := range tags {
-		t = strings.TrimSpace(t)
-		if t != "" {
-			cleaned = append(cleaned, t)
-		}
-	}
-	return strings.Join(cleaned, ",")
-}
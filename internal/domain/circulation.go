@@ -0,0 +1,363 @@
+package domain // Dominio: circulación (ejemplares físicos, puestos digitales y préstamos)
+
+import (
+	"fmt"          // Errores con contexto
+	"strings"      // Normalización de códigos de barras
+	"time"         // Fechas de préstamo y vencimiento
+	"unicode"      // Caracteres permitidos en el código de barras
+	"unicode/utf8" // Largo en caracteres
+)
+
+// -------------------- CopyStatus --------------------
+
+// CopyStatus es el estado de un ejemplar físico.
+type CopyStatus string
+
+const (
+	CopyAvailable   CopyStatus = "available"   // En estantería, se puede prestar
+	CopyOnLoan      CopyStatus = "on_loan"     // Prestado (solo lo asignan préstamo y devolución)
+	CopyOnHold      CopyStatus = "on_hold"     // Apartado para una reserva (solo lo asignan las reservas)
+	CopyMaintenance CopyStatus = "maintenance" // En reparación o catalogación
+	CopyLost        CopyStatus = "lost"        // Extraviado
+	CopyWithdrawn   CopyStatus = "withdrawn"   // Dado de baja (se conserva el historial)
+)
+
+// AllowedCopyStatuses es el catálogo de estados de ejemplar.
+var AllowedCopyStatuses = [6]CopyStatus{CopyAvailable, CopyOnLoan, CopyOnHold, CopyMaintenance, CopyLost, CopyWithdrawn}
+
+// ParseCopyStatus normaliza y valida un estado ("Available" -> available).
+func ParseCopyStatus(s string) (CopyStatus, error) {
+	st := CopyStatus(strings.ToLower(strings.TrimSpace(s)))
+	for _, allowed := range AllowedCopyStatuses {
+		if st == allowed {
+			return st, nil
+		}
+	}
+	return "", fmt.Errorf("%w: invalid copy status %q", ErrValidation, s)
+}
+
+// -------------------- CopyKind --------------------
+
+// CopyKind distingue los ejemplares físicos de los puestos digitales.
+type CopyKind string
+
+const (
+	CopyPhysical CopyKind = "physical" // Ejemplar en estantería
+	CopyDigital  CopyKind = "digital"  // Puesto de la licencia de préstamo digital
+)
+
+// -------------------- Copy --------------------
+
+const (
+	MaxBarcodeLen  = 40  // Largo máximo del código de barras (columna copies.barcode)
+	MaxLocationLen = 120 // Largo máximo de la ubicación (estante, sala)
+	MaxSeats       = 500 // Puestos digitales por libro
+)
+
+// Copy es un ejemplar de un libro, identificado por su código de barras. Un
+// ejemplar digital es un puesto de la licencia: un préstamo simultáneo del
+// archivo. Se presta, devuelve, renueva y aparta para las reservas igual que
+// uno físico, pero no tiene ubicación y lo administra SetSeats.
+type Copy struct {
+	id        uint64     // ID único (asignado por BD)
+	bookID    uint64     // Libro al que pertenece
+	kind      CopyKind   // Físico o puesto digital
+	barcode   string     // Código de barras (único, en mayúsculas)
+	status    CopyStatus // Estado actual
+	location  string     // Ubicación física (opcional)
+	createdAt time.Time  // Fecha de alta
+	updatedAt time.Time  // Fecha de última actualización
+}
+
+// NewCopy crea un ejemplar disponible del libro bookID.
+func NewCopy(bookID uint64, barcode, location string) (*Copy, error) {
+	if bookID == 0 {
+		return nil, fmt.Errorf("%w: book is required", ErrValidation)
+	}
+	c := &Copy{bookID: bookID, kind: CopyPhysical, status: CopyAvailable, createdAt: time.Now()}
+	if err := c.SetBarcode(barcode); err != nil {
+		return nil, err
+	}
+	if err := c.SetLocation(location); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// NewSeat crea el puesto digital n (desde 1) del libro bookID, con el código
+// SEAT-<libro>-<n>.
+func NewSeat(bookID uint64, n int) (*Copy, error) {
+	if bookID == 0 {
+		return nil, fmt.Errorf("%w: book is required", ErrValidation)
+	}
+	c := &Copy{bookID: bookID, kind: CopyDigital, status: CopyAvailable, createdAt: time.Now()}
+	if err := c.SetBarcode(fmt.Sprintf("SEAT-%d-%d", bookID, n)); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// HydrateCopy reconstruye un ejemplar desde la persistencia (sin validar).
+func HydrateCopy(id, bookID uint64, kind CopyKind, barcode string, status CopyStatus, location string, createdAt, updatedAt time.Time) *Copy {
+	return &Copy{id: id, bookID: bookID, kind: kind, barcode: barcode, status: status, location: location, createdAt: createdAt, updatedAt: updatedAt}
+}
+
+// ID devuelve el ID del ejemplar
+func (c *Copy) ID() uint64 { return c.id }
+
+// BookID devuelve el libro del ejemplar
+func (c *Copy) BookID() uint64 { return c.bookID }
+
+// Kind devuelve si es un ejemplar físico o un puesto digital
+func (c *Copy) Kind() CopyKind { return c.kind }
+
+// Digital indica si es un puesto de la licencia digital.
+func (c *Copy) Digital() bool { return c.kind == CopyDigital }
+
+// Barcode devuelve el código de barras normalizado
+func (c *Copy) Barcode() string { return c.barcode }
+
+// Status devuelve el estado actual
+func (c *Copy) Status() CopyStatus { return c.status }
+
+// Location devuelve la ubicación física
+func (c *Copy) Location() string { return c.location }
+
+// CreatedAt devuelve fecha de alta
+func (c *Copy) CreatedAt() time.Time { return c.createdAt }
+
+// UpdatedAt devuelve fecha actualización
+func (c *Copy) UpdatedAt() time.Time { return c.updatedAt }
+
+// Available indica si el ejemplar se puede prestar.
+func (c *Copy) Available() bool { return c.status == CopyAvailable }
+
+// SetBarcode normaliza (mayúsculas, sin espacios) y valida el código de barras.
+func (c *Copy) SetBarcode(barcode string) error {
+	barcode, err := NormalizeBarcode(barcode)
+	if err != nil {
+		return err
+	}
+	c.barcode = barcode
+	c.updatedAt = time.Now()
+	return nil
+}
+
+// SetLocation asigna la ubicación física (opcional).
+func (c *Copy) SetLocation(location string) error {
+	location = strings.Join(strings.Fields(location), " ")
+	if c.Digital() && location != "" {
+		return fmt.Errorf("%w: digital seats have no location", ErrValidation)
+	}
+	if utf8.RuneCountInString(location) > MaxLocationLen {
+		return fmt.Errorf("%w: location too long (max %d)", ErrValidation, MaxLocationLen)
+	}
+	c.location = location
+	c.updatedAt = time.Now()
+	return nil
+}
+
+// SetStatus cambia el estado a mano (mantenimiento, extraviado, baja...).
+// "on_loan" lo asignan solo los préstamos y "on_hold" solo las reservas: un
+// ejemplar prestado primero debe devolverse y uno apartado, retirarse o
+// liberarse cancelando la reserva.
+func (c *Copy) SetStatus(st CopyStatus) error {
+	if st == c.status {
+		return nil
+	}
+	if st == CopyOnLoan || c.status == CopyOnLoan {
+		return fmt.Errorf("%w: loans change the on_loan status (check the copy out or in)", ErrValidation)
+	}
+	if st == CopyOnHold || c.status == CopyOnHold {
+		return fmt.Errorf("%w: holds change the on_hold status (check the copy out or cancel the hold)", ErrValidation)
+	}
+	if _, err := ParseCopyStatus(string(st)); err != nil {
+		return err
+	}
+	c.status = st
+	c.updatedAt = time.Now()
+	return nil
+}
+
+// NormalizeBarcode deja el código en mayúsculas y sin espacios y valida que
+// solo tenga letras, dígitos o guiones ("  lib-0001 " -> "LIB-0001").
+func NormalizeBarcode(s string) (string, error) {
+	s = strings.ToUpper(strings.Join(strings.Fields(s), ""))
+	if len(s) < 3 || len(s) > MaxBarcodeLen {
+		return "", fmt.Errorf("%w: barcode must have 3 to %d characters", ErrValidation, MaxBarcodeLen)
+	}
+	for _, r := range s {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-') {
+			return "", fmt.Errorf("%w: barcode %q may only contain letters, digits and '-'", ErrValidation, s)
+		}
+	}
+	return s, nil
+}
+
+// -------------------- LoanPolicy --------------------
+
+// LoanPolicy son las reglas de préstamo de un rol.
+type LoanPolicy struct {
+	MaxLoans    int // Préstamos activos simultáneos
+	LoanDays    int // Días de préstamo (y de cada renovación)
+	MaxRenewals int // Renovaciones permitidas por préstamo
+	MaxHolds    int // Reservas activas simultáneas
+}
+
+// loanPolicies es la matriz rol -> reglas de préstamo (como rolePermissions).
+var loanPolicies = map[Role]LoanPolicy{
+	RoleAdmin:     {MaxLoans: 10, LoanDays: 30, MaxRenewals: 3, MaxHolds: 10},
+	RoleReader:    {MaxLoans: 3, LoanDays: 14, MaxRenewals: 2, MaxHolds: 3},
+	RoleConsultor: {MaxLoans: 5, LoanDays: 21, MaxRenewals: 1, MaxHolds: 5},
+}
+
+// LoanPolicy devuelve las reglas de préstamo del rol (sin préstamos si el rol
+// no está en la matriz).
+func (r Role) LoanPolicy() LoanPolicy { return loanPolicies[r] }
+
+// -------------------- Loan --------------------
+
+// Loan es el préstamo de un ejemplar a un usuario. Está activo hasta que se
+// registra la devolución.
+type Loan struct {
+	id         uint64    // ID único (asignado por BD)
+	copyID     uint64    // Ejemplar prestado
+	bookID     uint64    // Libro del ejemplar (para listar por libro)
+	userID     uint64    // Usuario que lo lleva
+	loanedAt   time.Time // Fecha de salida
+	dueAt      time.Time // Vencimiento
+	returnedAt time.Time // Devolución (cero = activo)
+	renewals   int       // Renovaciones realizadas
+}
+
+// NewLoan presta el ejemplar c al usuario u desde now, con el plazo de su rol.
+// El límite de préstamos simultáneos lo verifica el repositorio al guardar.
+// Un ejemplar apartado para una reserva se presta con NewHoldLoan.
+func NewLoan(c *Copy, u *User, now time.Time) (*Loan, error) {
+	if !c.Available() {
+		return nil, fmt.Errorf("%w: copy %s is %s", ErrUnavailable, c.Barcode(), c.Status())
+	}
+	return newLoan(c, u, now)
+}
+
+// newLoan arma el préstamo una vez verificado el ejemplar.
+func newLoan(c *Copy, u *User, now time.Time) (*Loan, error) {
+	if !u.Active() {
+		return nil, fmt.Errorf("%w: user %d", ErrInactiveEntity, u.ID())
+	}
+	p := u.Role().LoanPolicy()
+	if p.MaxLoans == 0 {
+		return nil, fmt.Errorf("%w: role %s cannot borrow", ErrLimitReached, u.Role())
+	}
+	now = now.UTC().Truncate(time.Second)
+	return &Loan{
+		copyID:   c.ID(),
+		bookID:   c.BookID(),
+		userID:   u.ID(),
+		loanedAt: now,
+		dueAt:    now.AddDate(0, 0, p.LoanDays),
+	}, nil
+}
+
+// HydrateLoan reconstruye un préstamo desde la persistencia (sin validar).
+func HydrateLoan(id, copyID, bookID, userID uint64, loanedAt, dueAt, returnedAt time.Time, renewals int) *Loan {
+	return &Loan{id: id, copyID: copyID, bookID: bookID, userID: userID, loanedAt: loanedAt, dueAt: dueAt, returnedAt: returnedAt, renewals: renewals}
+}
+
+// ID devuelve el ID del préstamo
+func (l *Loan) ID() uint64 { return l.id }
+
+// CopyID devuelve el ejemplar prestado
+func (l *Loan) CopyID() uint64 { return l.copyID }
+
+// BookID devuelve el libro del ejemplar
+func (l *Loan) BookID() uint64 { return l.bookID }
+
+// UserID devuelve el usuario que lo lleva
+func (l *Loan) UserID() uint64 { return l.userID }
+
+// LoanedAt devuelve la fecha de salida
+func (l *Loan) LoanedAt() time.Time { return l.loanedAt }
+
+// DueAt devuelve el vencimiento
+func (l *Loan) DueAt() time.Time { return l.dueAt }
+
+// ReturnedAt devuelve la fecha de devolución (cero si sigue activo)
+func (l *Loan) ReturnedAt() time.Time { return l.returnedAt }
+
+// Renewals devuelve las renovaciones realizadas
+func (l *Loan) Renewals() int { return l.renewals }
+
+// Active indica si el ejemplar todavía no se devolvió.
+func (l *Loan) Active() bool { return l.returnedAt.IsZero() }
+
+// Overdue indica si el préstamo sigue activo después del vencimiento.
+func (l *Loan) Overdue(now time.Time) bool { return l.Active() && now.After(l.dueAt) }
+
+// Renew extiende el vencimiento LoanDays días (desde el vencimiento actual).
+// No se renueva un préstamo devuelto, vencido o sin renovaciones disponibles.
+func (l *Loan) Renew(p LoanPolicy, now time.Time) error {
+	switch {
+	case !l.Active():
+		return fmt.Errorf("%w: loan %d was already returned", ErrValidation, l.id)
+	case l.Overdue(now):
+		return fmt.Errorf("%w: loan %d is overdue; return the copy first", ErrLimitReached, l.id)
+	case l.renewals >= p.MaxRenewals:
+		return fmt.Errorf("%w: loan %d reached %d renewal(s)", ErrLimitReached, l.id, p.MaxRenewals)
+	}
+	l.dueAt = l.dueAt.AddDate(0, 0, p.LoanDays)
+	l.renewals++
+	return nil
+}
+
+// Return registra la devolución en now.
+func (l *Loan) Return(now time.Time) error {
+	if !l.Active() {
+		return fmt.Errorf("%w: loan %d was already returned", ErrValidation, l.id)
+	}
+	l.returnedAt = now.UTC().Truncate(time.Second)
+	return nil
+}
+
+// LoanDetail es un préstamo con los datos que muestra el mostrador.
+type LoanDetail struct {
+	Loan      *Loan
+	Barcode   string // Código del ejemplar
+	Digital   bool   // El ejemplar es un puesto digital
+	BookTitle string // Título del libro
+	UserName  string // Nombre del usuario
+}
+
+// -------------------- LoanFilter --------------------
+
+// Estados de préstamo para filtrar.
+const (
+	LoanStatusActive   = "active"   // Sin devolver
+	LoanStatusOverdue  = "overdue"  // Sin devolver y vencidos
+	LoanStatusReturned = "returned" // Devueltos
+)
+
+// LoanFilter encapsula filtros para listar préstamos.
+type LoanFilter struct {
+	UserID  uint64    // Préstamos del usuario (0 = todos)
+	BookID  uint64    // Préstamos de ejemplares del libro (0 = todos)
+	CopyID  uint64    // Préstamos del ejemplar (0 = todos)
+	Digital bool      // Solo préstamos de puestos digitales
+	Status  string    // "", active, overdue o returned
+	Now     time.Time // Referencia para "overdue" (la fija el servicio)
+
+	PageRequest // Página, tamaño y orden (created_at = salida, due_at)
+}
+
+// LoanSortFields son los campos por los que se puede ordenar préstamos.
+var LoanSortFields = []string{SortCreatedAt, "due_at"}
+
+// Paging normaliza la paginación y valida el estado del filtro.
+func (f LoanFilter) Paging() (PageRequest, error) {
+	switch f.Status {
+	case "", LoanStatusActive, LoanStatusOverdue, LoanStatusReturned:
+	default:
+		return PageRequest{}, fmt.Errorf("%w: status must be active, overdue or returned", ErrValidation)
+	}
+	return f.PageRequest.Normalize(LoanSortFields)
+}
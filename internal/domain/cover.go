@@ -0,0 +1,160 @@
+package domain // Dominio: portada de un libro y sus miniaturas
+
+import (
+	"bytes"        // Comparación de firmas (magic numbers)
+	"encoding/hex" // Validación del hash
+	"fmt"          // Errores con contexto
+	"strings"      // Normalización del hash
+	"time"         // Fecha de la portada
+)
+
+// -------------------- Límites --------------------
+
+const (
+	MaxCoverBytes int64 = 5 << 20 // Tamaño máximo de la imagen subida (5 MB)
+	MaxCoverSide        = 4000    // Ancho/alto máximo en píxeles (evita bombas de descompresión)
+)
+
+// -------------------- CoverSize --------------------
+
+// CoverSize es un tamaño de miniatura de portada.
+type CoverSize string
+
+const (
+	CoverSmall  CoverSize = "small"  // Listados
+	CoverMedium CoverSize = "medium" // Ficha del libro
+	CoverLarge  CoverSize = "large"  // Vista ampliada
+)
+
+// CoverSizes lista los tamaños en orden creciente.
+var CoverSizes = []CoverSize{CoverSmall, CoverMedium, CoverLarge}
+
+// ParseCoverSize valida un tamaño recibido por URL.
+func ParseCoverSize(s string) (CoverSize, error) {
+	switch CoverSize(s) {
+	case CoverSmall, CoverMedium, CoverLarge:
+		return CoverSize(s), nil
+	}
+	return "", fmt.Errorf("%w: invalid cover size %q (allowed: small, medium, large)", ErrValidation, s)
+}
+
+// Width devuelve el ancho en píxeles de la miniatura (el alto sale de la
+// proporción de la imagen original).
+func (c CoverSize) Width() int {
+	switch c {
+	case CoverSmall:
+		return 96
+	case CoverMedium:
+		return 240
+	default:
+		return 480
+	}
+}
+
+// SniffCoverType detecta el tipo de imagen por su firma. Solo se aceptan
+// JPEG, PNG y WebP; cualquier otro contenido es ErrValidation.
+func SniffCoverType(head []byte) (string, error) {
+	switch {
+	case bytes.HasPrefix(head, []byte("\xFF\xD8\xFF")):
+		return "image/jpeg", nil
+	case bytes.HasPrefix(head, []byte("\x89PNG\r\n\x1a\n")):
+		return "image/png", nil
+	case len(head) >= 12 && string(head[0:4]) == "RIFF" && string(head[8:12]) == "WEBP":
+		return "image/webp", nil
+	}
+	return "", fmt.Errorf("%w: unsupported image type (allowed: JPEG, PNG, WebP)", ErrValidation)
+}
+
+// CoverBlobKey es la clave de la imagen original en el almacén de blobs
+// (separada de los adjuntos para no mezclar sus conteos de uso).
+func CoverBlobKey(sha256 string) string { return "covers/" + BlobKey(sha256) }
+
+// ThumbnailKey es la clave de una miniatura en la caché. Depende solo del
+// contenido: cambiar la portada genera claves nuevas y nunca hay que
+// invalidar miniaturas viejas, solo borrarlas cuando nadie las usa.
+func ThumbnailKey(sha256 string, size CoverSize) string {
+	return BlobKey(sha256) + "-" + string(size) + ".jpg"
+}
+
+// ImageInfo es lo que se lee de la cabecera de una imagen (sin decodificar
+// los píxeles).
+type ImageInfo struct {
+	Format        string // "jpeg", "png" o "webp"
+	Width, Height int
+}
+
+// -------------------- Cover --------------------
+
+// Cover es la portada de un libro (a lo sumo una por libro). La imagen
+// original vive en el almacén de blobs bajo CoverBlobKey(SHA256()).
+type Cover struct {
+	bookID      uint64    // Libro (también es la clave)
+	sha256      string    // Hash del contenido (hex, minúsculas)
+	contentType string    // image/jpeg, image/png o image/webp
+	width       int       // Ancho original en píxeles
+	height      int       // Alto original en píxeles
+	size        int64     // Tamaño en bytes
+	updatedAt   time.Time // Última vez que se cambió
+}
+
+// NewCover valida los metadatos de una imagen ya leída e inspeccionada.
+func NewCover(bookID uint64, sha256, contentType string, width, height int, size int64) (*Cover, error) {
+	if bookID == 0 {
+		return nil, fmt.Errorf("%w: book_id is required", ErrValidation)
+	}
+	if _, err := hex.DecodeString(sha256); err != nil || len(sha256) != 64 {
+		return nil, fmt.Errorf("%w: invalid sha256", ErrValidation)
+	}
+	if size <= 0 {
+		return nil, fmt.Errorf("%w: image is empty", ErrValidation)
+	}
+	if size > MaxCoverBytes {
+		return nil, fmt.Errorf("%w: image exceeds the %d MB limit", ErrTooLarge, MaxCoverBytes>>20)
+	}
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("%w: invalid image dimensions", ErrValidation)
+	}
+	if width > MaxCoverSide || height > MaxCoverSide {
+		return nil, fmt.Errorf("%w: image is %dx%d px, max %d px per side", ErrValidation, width, height, MaxCoverSide)
+	}
+	return &Cover{
+		bookID: bookID, sha256: strings.ToLower(sha256), contentType: contentType,
+		width: width, height: height, size: size, updatedAt: time.Now(),
+	}, nil
+}
+
+// HydrateCover reconstruye una portada desde la persistencia (sin validar).
+func HydrateCover(bookID uint64, sha256, contentType string, width, height int, size int64, updatedAt time.Time) *Cover {
+	return &Cover{
+		bookID: bookID, sha256: sha256, contentType: contentType,
+		width: width, height: height, size: size, updatedAt: updatedAt,
+	}
+}
+
+// BookID devuelve el libro de la portada
+func (c *Cover) BookID() uint64 { return c.bookID }
+
+// SHA256 devuelve el hash de la imagen
+func (c *Cover) SHA256() string { return c.sha256 }
+
+// ContentType devuelve el tipo MIME de la imagen original
+func (c *Cover) ContentType() string { return c.contentType }
+
+// Width devuelve el ancho original
+func (c *Cover) Width() int { return c.width }
+
+// Height devuelve el alto original
+func (c *Cover) Height() int { return c.height }
+
+// Size devuelve el tamaño en bytes
+func (c *Cover) Size() int64 { return c.size }
+
+// UpdatedAt devuelve la fecha del último cambio
+func (c *Cover) UpdatedAt() time.Time { return c.updatedAt }
+
+// Version identifica el contenido en las URLs (?v=...) para que el
+// navegador pueda guardar la miniatura sin volver a preguntar.
+func (c *Cover) Version() string { return c.sha256[:12] }
+
+// BlobKey devuelve la clave de la imagen original
+func (c *Cover) BlobKey() string { return CoverBlobKey(c.sha256) }
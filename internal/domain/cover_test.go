@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSniffCoverType(t *testing.T) {
+	valid := map[string]string{
+		"\xFF\xD8\xFF\xE0\x00\x10JFIF": "image/jpeg",
+		"\x89PNG\r\n\x1a\n\x00\x00":    "image/png",
+		"RIFF\x24\x08\x00\x00WEBPVP8 ": "image/webp",
+	}
+	for head, want := range valid {
+		if got, err := SniffCoverType([]byte(head)); err != nil || got != want {
+			t.Errorf("SniffCoverType(%q) = %q, %v; want %q", head, got, err, want)
+		}
+	}
+	// GIF, SVG y un WAV (también RIFF) no son portadas válidas
+	for _, head := range []string{"", "GIF89a", "<svg xmlns=", "RIFF\x24\x08\x00\x00WAVEfmt "} {
+		if _, err := SniffCoverType([]byte(head)); !errors.Is(err, ErrValidation) {
+			t.Errorf("SniffCoverType(%q): expected validation error, got %v", head, err)
+		}
+	}
+
+	if _, err := NewCover(1, "ab", "image/png", 10, 10, 10); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected invalid sha, got %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// EbookChapter es un documento del spine de un EPUB; el lector integrado
+// muestra uno por página.
+type EbookChapter struct {
+	Href  string // ruta del XHTML dentro del EPUB
+	Title string // título según la tabla de contenidos ("" si no figura)
+	Spine int    // posición en el spine (contando los no lineales), para el CFI
+}
+
+// EbookOutline es la estructura de un EPUB: metadatos y capítulos en orden
+// de lectura (solo los lineales del spine).
+type EbookOutline struct {
+	Title    string
+	Language string
+	Chapters []EbookChapter
+}
+
+// ChapterCFI devuelve el EPUB CFI que apunta al inicio del capítulo i
+// (desde 0), el que se guarda como ubicación del progreso.
+func (o EbookOutline) ChapterCFI(i int) string {
+	if i < 0 || i >= len(o.Chapters) {
+		return ""
+	}
+	// /6 es el spine dentro del paquete; sus itemref son los pasos pares
+	return fmt.Sprintf("epubcfi(/6/%d!)", 2*(o.Chapters[i].Spine+1))
+}
+
+// ChapterAt busca el capítulo al que apunta un EPUB CFI guardado (-1 si no
+// es un CFI o no corresponde a ningún capítulo del libro).
+func (o EbookOutline) ChapterAt(cfi string) int {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(cfi), "epubcfi(/6/")
+	if !ok {
+		return -1
+	}
+	end := strings.IndexFunc(rest, func(r rune) bool { return r < '0' || r > '9' })
+	if end < 0 {
+		end = len(rest)
+	}
+	step, err := strconv.Atoi(rest[:end])
+	if err != nil || step < 2 || step%2 != 0 {
+		return -1
+	}
+	for i, ch := range o.Chapters {
+		if ch.Spine == step/2-1 {
+			return i
+		}
+	}
+	return -1
+}
+
+// EbookLinks arma las URLs con que el lector reemplaza los enlaces internos
+// del EPUB: a otro capítulo (con su fragmento, que puede ser "") y a un
+// recurso (imagen) por su ruta dentro del EPUB.
+type EbookLinks struct {
+	Chapter  func(index int, fragment string) string
+	Resource func(path string) string
+}
@@ -0,0 +1,29 @@
+package domain
+
+import "testing"
+
+func TestEbookOutlineCFI(t *testing.T) {
+	// El primer itemref del spine es una portada no lineal: no es capítulo
+	o := EbookOutline{Chapters: []EbookChapter{{Href: "uno.xhtml", Spine: 1}, {Href: "dos.xhtml", Spine: 2}}}
+
+	if got := o.ChapterCFI(0); got != "epubcfi(/6/4!)" {
+		t.Errorf("ChapterCFI(0) = %q", got)
+	}
+	if got := o.ChapterCFI(2); got != "" {
+		t.Errorf("ChapterCFI(2) = %q, want empty", got)
+	}
+
+	for cfi, want := range map[string]int{
+		"epubcfi(/6/4!)":               0,
+		"epubcfi(/6/6[cap2]!/4/2/1:0)": 1,
+		" epubcfi(/6/6!/4) ":           1,
+		"epubcfi(/6/2!)":               -1, // la portada
+		"epubcfi(/6/5!)":               -1, // paso impar
+		"epubcfi(/4/4!)":               -1,
+		"página 4":                     -1,
+	} {
+		if got := o.ChapterAt(cfi); got != want {
+			t.Errorf("ChapterAt(%q) = %d, want %d", cfi, got, want)
+		}
+	}
+}
@@ -34,4 +34,24 @@ var (
 	// ErrInactiveEntity se usa cuando una entidad existe pero está inactiva
 	// Ejemplo: usuario o libro desactivado
 	ErrInactiveEntity = errors.New("entity is inactive")
+
+	// ErrUnauthorized se usa cuando no hay una identidad válida
+	// Ejemplo: credenciales incorrectas, sesión expirada o ausente
+	ErrUnauthorized = errors.New("unauthorized")
+
+	// ErrForbidden se usa cuando hay identidad pero no permiso suficiente
+	// Ejemplo: un READER intentando eliminar un libro
+	ErrForbidden = errors.New("forbidden")
+
+	// ErrUnavailable se usa cuando un recurso existe pero no se puede usar ahora
+	// Ejemplo: prestar un ejemplar que ya está prestado o extraviado
+	ErrUnavailable = errors.New("not available")
+
+	// ErrLimitReached se usa cuando se supera un límite de una regla del negocio
+	// Ejemplo: más préstamos simultáneos o renovaciones que las del rol
+	ErrLimitReached = errors.New("limit reached")
+
+	// ErrTooLarge se usa cuando un contenido supera el tamaño máximo permitido
+	// Ejemplo: subir un adjunto más grande que UPLOAD_MAX_MB
+	ErrTooLarge = errors.New("too large")
 )
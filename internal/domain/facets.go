@@ -0,0 +1,77 @@
+package domain // Dominio: agregaciones (facetas) de la búsqueda de libros
+
+import (
+	"fmt"
+	"sort"
+)
+
+// MaxFacetValues es la cantidad de valores que se devuelven por faceta.
+const MaxFacetValues = 10
+
+// FacetCount es un valor de faceta y cuántos resultados lo tienen.
+type FacetCount struct {
+	Value string // Valor tal como se muestra (p. ej. "Novela", "1990" para una década)
+	Count int    // Libros del resultado con ese valor
+}
+
+// BookFacets resume el resultado completo (todas las páginas) por dimensión.
+type BookFacets struct {
+	Categories []FacetCount // Por categoría (más frecuentes primero)
+	Authors    []FacetCount // Por autor (más frecuentes primero)
+	Tags       []FacetCount // Por etiqueta (más frecuentes primero)
+	Decades    []FacetCount // Por década ("1990" = 1990-1999), más reciente primero
+	Years      []FacetCount // Por año; solo si el filtro ya acota a una década o menos
+}
+
+// BookResults es la respuesta de una búsqueda: la página pedida y,
+// si BookFilter.WithFacets, las facetas del resultado completo.
+type BookResults struct {
+	Hits   Page[BookHit]
+	Facets *BookFacets // nil si no se pidieron
+
+	// Approximate indica que no hubo coincidencias exactas y los resultados
+	// vienen de la búsqueda tolerante a errores de tipeo (BookFilter.Fuzzy).
+	Approximate bool
+}
+
+// Decade devuelve el primer año de la década de year (1987 -> 1980).
+func Decade(year int) int { return year - year%10 }
+
+// Validate revisa los filtros que no dependen de la paginación.
+func (f BookFilter) Validate() error {
+	if f.YearFrom < 0 || f.YearTo < 0 {
+		return fmt.Errorf("%w: year range cannot be negative", ErrValidation)
+	}
+	if f.YearFrom > 0 && f.YearTo > 0 && f.YearFrom > f.YearTo {
+		return fmt.Errorf("%w: year_from must be <= year_to", ErrValidation)
+	}
+	if f.MinRating < 0 || f.MinRating > MaxReviewRating {
+		return fmt.Errorf("%w: min_rating must be between 0 and %d", ErrValidation, MaxReviewRating)
+	}
+	if _, err := f.Query(); err != nil {
+		return err
+	}
+	_, err := f.Paging()
+	return err
+}
+
+// NarrowYears indica si el rango de años pedido abarca una década o menos
+// (entonces tiene sentido mostrar la faceta por año).
+func (f BookFilter) NarrowYears() bool {
+	return f.YearFrom > 0 && f.YearTo > 0 && f.YearTo-f.YearFrom < 10
+}
+
+// TopFacets ordena los conteos (más frecuentes primero, luego por valor)
+// y recorta a MaxFacetValues.
+func TopFacets(counts []FacetCount) []FacetCount {
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Value < counts[j].Value
+	})
+	if len(counts) > MaxFacetValues {
+		counts = counts[:MaxFacetValues]
+	}
+	return counts
+}
@@ -0,0 +1,161 @@
+package domain // Dominio: archivos adjuntos de un libro (PDF, EPUB, audio)
+
+import (
+	"bytes"         // Comparación de firmas (magic numbers)
+	"encoding/hex"  // Validación del hash
+	"fmt"           // Errores con contexto
+	"path/filepath" // Nombre base del archivo subido
+	"strings"       // Normalización del nombre
+	"time"          // Fecha de subida
+	"unicode/utf8"  // Largo del nombre en caracteres
+)
+
+// -------------------- FileKind --------------------
+
+// FileKind es el tipo de contenido de un adjunto.
+type FileKind string
+
+const (
+	FileKindPDF   FileKind = "pdf"   // Documento PDF
+	FileKindEPUB  FileKind = "epub"  // Libro electrónico EPUB
+	FileKindAudio FileKind = "audio" // Audiolibro (MP3, M4A/M4B, OGG, FLAC, WAV)
+)
+
+// SniffLen es cuántos bytes del inicio necesita SniffFileType.
+const SniffLen = 64
+
+// DefaultMaxFileSize es el tamaño máximo de un adjunto si no se configura otro.
+const DefaultMaxFileSize int64 = 100 << 20 // 100 MB
+
+// SniffFileType detecta el tipo real del archivo por su firma (no confía en
+// la extensión ni en el Content-Type que manda el cliente). Retorna
+// ErrValidation si no es un PDF, un EPUB o un audio reconocido.
+func SniffFileType(head []byte) (string, FileKind, error) {
+	switch {
+	case bytes.HasPrefix(head, []byte("%PDF-")):
+		return "application/pdf", FileKindPDF, nil
+
+	// EPUB: un ZIP cuya primera entrada es "mimetype" sin comprimir
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")) && len(head) >= 58 &&
+		string(head[30:58]) == "mimetypeapplication/epub+zip":
+		return "application/epub+zip", FileKindEPUB, nil
+
+	case bytes.HasPrefix(head, []byte("ID3")),
+		len(head) >= 2 && head[0] == 0xFF && head[1]&0xE0 == 0xE0 && head[1]&0x06 != 0: // frame MPEG con capa válida
+		return "audio/mpeg", FileKindAudio, nil
+
+	case len(head) >= 12 && string(head[4:8]) == "ftyp" &&
+		(string(head[8:11]) == "M4A" || string(head[8:11]) == "M4B"):
+		return "audio/mp4", FileKindAudio, nil
+
+	case bytes.HasPrefix(head, []byte("OggS")):
+		return "audio/ogg", FileKindAudio, nil
+
+	case bytes.HasPrefix(head, []byte("fLaC")):
+		return "audio/flac", FileKindAudio, nil
+
+	case len(head) >= 12 && string(head[0:4]) == "RIFF" && string(head[8:12]) == "WAVE":
+		return "audio/wav", FileKindAudio, nil
+	}
+	return "", "", fmt.Errorf("%w: unsupported file type (allowed: PDF, EPUB, audio)", ErrValidation)
+}
+
+// BlobKey es la clave del contenido en el almacén: el SHA-256 repartido en
+// dos niveles de carpetas ("ab/cd/abcd..."). Dos subidas iguales comparten
+// la misma clave (deduplicación).
+func BlobKey(sha256 string) string {
+	return sha256[0:2] + "/" + sha256[2:4] + "/" + sha256
+}
+
+// -------------------- BookFile --------------------
+
+// BookFile es un archivo adjunto a un libro. El contenido vive en el
+// almacén de blobs bajo BlobKey(SHA256()); la BD guarda solo los metadatos.
+type BookFile struct {
+	id          uint64    // ID único (asignado por BD)
+	bookID      uint64    // Libro al que pertenece
+	name        string    // Nombre original (sin ruta)
+	contentType string    // Tipo MIME detectado por contenido
+	kind        FileKind  // pdf, epub o audio
+	size        int64     // Tamaño en bytes
+	sha256      string    // Hash del contenido (hex, minúsculas)
+	uploadedBy  uint64    // Usuario que lo subió (0 si se eliminó)
+	createdAt   time.Time // Fecha de subida
+}
+
+// NewBookFile valida los metadatos de un adjunto ya leído y hasheado.
+func NewBookFile(bookID uint64, name, contentType string, kind FileKind, size int64, sha256 string, uploadedBy uint64) (*BookFile, error) {
+	if bookID == 0 {
+		return nil, fmt.Errorf("%w: book_id is required", ErrValidation)
+	}
+	name, err := cleanFileName(name)
+	if err != nil {
+		return nil, err
+	}
+	if size <= 0 {
+		return nil, fmt.Errorf("%w: file is empty", ErrValidation)
+	}
+	if _, err := hex.DecodeString(sha256); err != nil || len(sha256) != 64 {
+		return nil, fmt.Errorf("%w: invalid sha256", ErrValidation)
+	}
+	switch kind {
+	case FileKindPDF, FileKindEPUB, FileKindAudio:
+	default:
+		return nil, fmt.Errorf("%w: invalid file kind %q", ErrValidation, kind)
+	}
+	return &BookFile{
+		bookID: bookID, name: name, contentType: contentType, kind: kind,
+		size: size, sha256: strings.ToLower(sha256), uploadedBy: uploadedBy, createdAt: time.Now(),
+	}, nil
+}
+
+// HydrateBookFile reconstruye un adjunto desde la persistencia (sin validar).
+func HydrateBookFile(id, bookID uint64, name, contentType string, kind FileKind, size int64, sha256 string, uploadedBy uint64, createdAt time.Time) *BookFile {
+	return &BookFile{
+		id: id, bookID: bookID, name: name, contentType: contentType, kind: kind,
+		size: size, sha256: sha256, uploadedBy: uploadedBy, createdAt: createdAt,
+	}
+}
+
+// cleanFileName deja solo el nombre base (los navegadores viejos mandan la
+// ruta completa) y limita su largo.
+func cleanFileName(name string) (string, error) {
+	name = strings.TrimSpace(filepath.Base(strings.ReplaceAll(name, `\`, "/")))
+	if name == "" || name == "." || name == "/" {
+		return "", fmt.Errorf("%w: file name is required", ErrValidation)
+	}
+	if utf8.RuneCountInString(name) > 255 {
+		return "", fmt.Errorf("%w: file name too long", ErrValidation)
+	}
+	return name, nil
+}
+
+// ID devuelve el ID del adjunto
+func (f *BookFile) ID() uint64 { return f.id }
+
+// BookID devuelve el libro al que pertenece
+func (f *BookFile) BookID() uint64 { return f.bookID }
+
+// Name devuelve el nombre original
+func (f *BookFile) Name() string { return f.name }
+
+// ContentType devuelve el tipo MIME detectado
+func (f *BookFile) ContentType() string { return f.contentType }
+
+// Kind devuelve el tipo de adjunto
+func (f *BookFile) Kind() FileKind { return f.kind }
+
+// Size devuelve el tamaño en bytes
+func (f *BookFile) Size() int64 { return f.size }
+
+// SHA256 devuelve el hash del contenido
+func (f *BookFile) SHA256() string { return f.sha256 }
+
+// UploadedBy devuelve el usuario que lo subió (0 si ya no existe)
+func (f *BookFile) UploadedBy() uint64 { return f.uploadedBy }
+
+// CreatedAt devuelve la fecha de subida
+func (f *BookFile) CreatedAt() time.Time { return f.createdAt }
+
+// BlobKey devuelve la clave del contenido en el almacén
+func (f *BookFile) BlobKey() string { return BlobKey(f.sha256) }
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSniffFileType(t *testing.T) {
+	valid := map[string]string{
+		"%PDF-1.7\n%âãÏÓ": "application/pdf",
+		"PK\x03\x04\x14\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x08\x00\x00\x00" +
+			"mimetypeapplication/epub+zip": "application/epub+zip",
+		"ID3\x04\x00\x00":                  "audio/mpeg",
+		"\xFF\xFB\x90\x64":                 "audio/mpeg", // frame MPEG-1 capa III sin ID3
+		"\x00\x00\x00\x20ftypM4B \x00\x00": "audio/mp4",
+		"OggS\x00\x02":                     "audio/ogg",
+		"fLaC\x00\x00\x00\x22":             "audio/flac",
+		"RIFF\x24\x08\x00\x00WAVEfmt ":     "audio/wav",
+	}
+	for head, want := range valid {
+		got, _, err := SniffFileType([]byte(head))
+		if err != nil || got != want {
+			t.Errorf("SniffFileType(%q) = %q, %v; want %q", head, got, err, want)
+		}
+	}
+
+	// Un ZIP cualquiera no es un EPUB; texto e imágenes tampoco se aceptan
+	for _, head := range []string{"", "hola", "PK\x03\x04\x14\x00", "\x89PNG\r\n\x1a\n", "\x00\x00\x00\x20ftypisom", "\xFF\xE0\x00"} {
+		if _, _, err := SniffFileType([]byte(head)); !errors.Is(err, ErrValidation) {
+			t.Errorf("SniffFileType(%q): expected validation error, got %v", head, err)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package domain // Dominio: reservas (cola de espera por libro) y disponibilidad
+
+import (
+	"fmt"  // Errores con contexto
+	"time" // Fechas de reserva y vencimiento del retiro
+)
+
+// -------------------- HoldStatus --------------------
+
+// HoldStatus es el estado de una reserva.
+type HoldStatus string
+
+const (
+	HoldWaiting   HoldStatus = "waiting"   // En la cola, sin ejemplar asignado
+	HoldReady     HoldStatus = "ready"     // Ejemplar apartado, esperando el retiro
+	HoldFulfilled HoldStatus = "fulfilled" // Se retiró (se convirtió en préstamo)
+	HoldCancelled HoldStatus = "cancelled" // Cancelada por el usuario o el mostrador
+	HoldExpired   HoldStatus = "expired"   // No se retiró a tiempo
+)
+
+// HoldPickupDays es el plazo para retirar un ejemplar apartado; después la
+// reserva vence y el ejemplar pasa a la siguiente de la cola.
+const HoldPickupDays = 3
+
+// -------------------- Hold --------------------
+
+// Hold es la reserva de un libro por un usuario. Las reservas de un libro se
+// atienden en orden de llegada (FIFO) a medida que se liberan ejemplares.
+type Hold struct {
+	id        uint64     // ID único (asignado por BD; define el orden de la cola)
+	bookID    uint64     // Libro reservado
+	userID    uint64     // Usuario que espera
+	copyID    uint64     // Ejemplar apartado (0 mientras espera)
+	status    HoldStatus // Estado actual
+	createdAt time.Time  // Fecha de la reserva
+	readyAt   time.Time  // Cuándo se apartó un ejemplar (cero si espera)
+	expiresAt time.Time  // Límite para retirarlo (cero si espera)
+	closedAt  time.Time  // Retiro, cancelación o vencimiento (cero si activa)
+}
+
+// NewHold crea una reserva en espera del libro b para el usuario u.
+// El máximo de reservas del rol lo verifica el repositorio al guardar.
+func NewHold(b *Book, u *User, now time.Time) (*Hold, error) {
+	if !b.Active() {
+		return nil, fmt.Errorf("%w: book %d", ErrInactiveEntity, b.ID())
+	}
+	if !u.Active() {
+		return nil, fmt.Errorf("%w: user %d", ErrInactiveEntity, u.ID())
+	}
+	if u.Role().LoanPolicy().MaxHolds == 0 {
+		return nil, fmt.Errorf("%w: role %s cannot place holds", ErrLimitReached, u.Role())
+	}
+	return &Hold{bookID: b.ID(), userID: u.ID(), status: HoldWaiting, createdAt: now.UTC().Truncate(time.Second)}, nil
+}
+
+// HydrateHold reconstruye una reserva desde la persistencia (sin validar).
+func HydrateHold(id, bookID, userID, copyID uint64, status HoldStatus, createdAt, readyAt, expiresAt, closedAt time.Time) *Hold {
+	return &Hold{
+		id: id, bookID: bookID, userID: userID, copyID: copyID, status: status,
+		createdAt: createdAt, readyAt: readyAt, expiresAt: expiresAt, closedAt: closedAt,
+	}
+}
+
+// ID devuelve el ID de la reserva
+func (h *Hold) ID() uint64 { return h.id }
+
+// BookID devuelve el libro reservado
+func (h *Hold) BookID() uint64 { return h.bookID }
+
+// UserID devuelve el usuario que espera
+func (h *Hold) UserID() uint64 { return h.userID }
+
+// CopyID devuelve el ejemplar apartado (0 si espera)
+func (h *Hold) CopyID() uint64 { return h.copyID }
+
+// Status devuelve el estado actual
+func (h *Hold) Status() HoldStatus { return h.status }
+
+// CreatedAt devuelve la fecha de la reserva
+func (h *Hold) CreatedAt() time.Time { return h.createdAt }
+
+// ReadyAt devuelve cuándo se apartó el ejemplar (cero si espera)
+func (h *Hold) ReadyAt() time.Time { return h.readyAt }
+
+// ExpiresAt devuelve el límite de retiro (cero si espera)
+func (h *Hold) ExpiresAt() time.Time { return h.expiresAt }
+
+// ClosedAt devuelve la fecha de cierre (cero si sigue activa)
+func (h *Hold) ClosedAt() time.Time { return h.closedAt }
+
+// Active indica si la reserva sigue en la cola o esperando el retiro.
+func (h *Hold) Active() bool { return h.status == HoldWaiting || h.status == HoldReady }
+
+// MarkReady aparta el ejemplar copyID para la reserva desde now.
+func (h *Hold) MarkReady(copyID uint64, now time.Time) error {
+	if h.status != HoldWaiting {
+		return fmt.Errorf("%w: hold %d is %s", ErrValidation, h.id, h.status)
+	}
+	now = now.UTC().Truncate(time.Second)
+	h.copyID, h.status = copyID, HoldReady
+	h.readyAt, h.expiresAt = now, now.AddDate(0, 0, HoldPickupDays)
+	return nil
+}
+
+// Cancel cierra una reserva activa (si tenía ejemplar apartado, el repositorio
+// lo pasa a la siguiente de la cola).
+func (h *Hold) Cancel(now time.Time) error {
+	if !h.Active() {
+		return fmt.Errorf("%w: hold %d is %s", ErrValidation, h.id, h.status)
+	}
+	h.status, h.closedAt = HoldCancelled, now.UTC().Truncate(time.Second)
+	return nil
+}
+
+// NewHoldLoan presta el ejemplar apartado c al titular de la reserva h.
+// Un ejemplar apartado no se presta a otro usuario (ErrUnavailable).
+func NewHoldLoan(c *Copy, h *Hold, u *User, now time.Time) (*Loan, error) {
+	if c.Status() != CopyOnHold || h.Status() != HoldReady || h.CopyID() != c.ID() || h.UserID() != u.ID() {
+		return nil, fmt.Errorf("%w: copy %s is reserved for another hold", ErrUnavailable, c.Barcode())
+	}
+	return newLoan(c, u, now)
+}
+
+// HoldDetail es una reserva con los datos que muestran las pantallas.
+type HoldDetail struct {
+	Hold      *Hold
+	Position  int    // Lugar en la cola (1 = la próxima); 0 si no espera
+	BookTitle string // Título del libro
+	UserName  string // Nombre del usuario
+	Barcode   string // Código del ejemplar apartado ("" si espera)
+	Digital   bool   // Lo apartado es un puesto digital (se retira con Borrow)
+}
+
+// -------------------- HoldFilter --------------------
+
+// HoldStatusActive filtra reservas en espera o listas para retirar.
+const HoldStatusActive = "active"
+
+// HoldFilter encapsula filtros para listar reservas.
+type HoldFilter struct {
+	UserID uint64 // Reservas del usuario (0 = todas)
+	BookID uint64 // Reservas del libro (0 = todas)
+	Status string // "", active o un HoldStatus
+
+	PageRequest // Página y orden (created_at = orden de la cola)
+}
+
+// HoldSortFields son los campos por los que se puede ordenar reservas.
+var HoldSortFields = []string{SortCreatedAt}
+
+// Paging normaliza la paginación y valida el estado del filtro.
+func (f HoldFilter) Paging() (PageRequest, error) {
+	switch HoldStatus(f.Status) {
+	case "", HoldStatusActive, HoldWaiting, HoldReady, HoldFulfilled, HoldCancelled, HoldExpired:
+	default:
+		return PageRequest{}, fmt.Errorf("%w: invalid hold status %q", ErrValidation, f.Status)
+	}
+	return f.PageRequest.Normalize(HoldSortFields)
+}
+
+// -------------------- Availability --------------------
+
+// Availability resume los ejemplares y puestos digitales de un libro y su
+// cola de reservas. Available, OnLoan y OnHold cuentan ambos: la cola se
+// atiende con el primero que se libere.
+type Availability struct {
+	Copies    int // Ejemplares físicos en circulación (sin extraviados ni dados de baja)
+	Seats     int // Puestos digitales de la licencia (sin dados de baja)
+	Available int // En estantería o puesto libre, se pueden prestar ya
+	OnLoan    int // Prestados
+	OnHold    int // Apartados para una reserva lista
+	Waiting   int // Reservas en la cola
+}
+
+// AddCopies suma n ejemplares del tipo kind con el estado st.
+func (a *Availability) AddCopies(kind CopyKind, st CopyStatus, n int) {
+	switch st {
+	case CopyLost, CopyWithdrawn:
+		return
+	case CopyAvailable:
+		a.Available += n
+	case CopyOnLoan:
+		a.OnLoan += n
+	case CopyOnHold:
+		a.OnHold += n
+	}
+	if kind == CopyDigital {
+		a.Seats += n
+	} else {
+		a.Copies += n
+	}
+}
+
+// InCirculation indica si el libro tiene ejemplares o puestos que prestar
+// (y, por lo tanto, con qué atender una reserva).
+func (a Availability) InCirculation() bool { return a.Copies+a.Seats > 0 }
@@ -0,0 +1,154 @@
+package domain // Dominio: ISBN (validación, forma canónica y formato con guiones)
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Forma canónica: ISBN-13 sin guiones ni espacios ("9788437604947").
+// Así "978-84-376-0494-7", "84-376-0494-X" y "9788437604947" son el mismo
+// libro para la clave única uq_books_isbn.
+
+// ParseISBN valida un ISBN-10 o ISBN-13 (con o sin guiones/espacios y con o
+// sin el rótulo "ISBN") y devuelve su forma canónica ISBN-13.
+func ParseISBN(s string) (string, error) {
+	raw := strings.ToUpper(strings.TrimSpace(s))
+	raw = strings.TrimPrefix(raw, "ISBN")
+	raw = strings.TrimPrefix(raw, "-13")
+	raw = strings.TrimPrefix(raw, "-10")
+	raw = strings.TrimLeft(raw, ": ")
+
+	var digits []byte
+	for i := 0; i < len(raw); i++ {
+		switch c := raw[i]; {
+		case c >= '0' && c <= '9', c == 'X':
+			digits = append(digits, c)
+		case c == '-' || c == ' ':
+			// separadores permitidos
+		default:
+			return "", fmt.Errorf("%w: isbn has invalid character %q", ErrValidation, c)
+		}
+	}
+
+	switch len(digits) {
+	case 10:
+		if strings.IndexByte(string(digits[:9]), 'X') >= 0 {
+			return "", fmt.Errorf("%w: isbn-10 allows X only as check digit", ErrValidation)
+		}
+		if isbn10Check(digits[:9]) != digits[9] {
+			return "", fmt.Errorf("%w: isbn-10 checksum mismatch", ErrValidation)
+		}
+		body := "978" + string(digits[:9])
+		return body + string(isbn13Check([]byte(body))), nil
+	case 13:
+		if strings.IndexByte(string(digits), 'X') >= 0 {
+			return "", fmt.Errorf("%w: isbn-13 must be all digits", ErrValidation)
+		}
+		if p := string(digits[:3]); p != "978" && p != "979" {
+			return "", fmt.Errorf("%w: isbn-13 must start with 978 or 979", ErrValidation)
+		}
+		if isbn13Check(digits[:12]) != digits[12] {
+			return "", fmt.Errorf("%w: isbn-13 checksum mismatch", ErrValidation)
+		}
+		return string(digits), nil
+	default:
+		return "", fmt.Errorf("%w: isbn must have 10 or 13 digits", ErrValidation)
+	}
+}
+
+// NormalizeISBN devuelve la forma canónica si s es un ISBN válido; si no,
+// s sin espacios alrededor (datos heredados o búsquedas por ISBN inválido).
+func NormalizeISBN(s string) string {
+	if c, err := ParseISBN(s); err == nil {
+		return c
+	}
+	return strings.TrimSpace(s)
+}
+
+// ISBN10 devuelve la forma ISBN-10 de un ISBN-13 canónico con prefijo 978
+// ("" si no existe, p. ej. prefijo 979 o valor no canónico).
+func ISBN10(canonical string) string {
+	if len(canonical) != 13 || !strings.HasPrefix(canonical, "978") {
+		return ""
+	}
+	body := []byte(canonical[3:12])
+	return string(body) + string(isbn10Check(body))
+}
+
+// isbn10Check calcula el dígito de control ISBN-10 (módulo 11, 10 = X).
+func isbn10Check(d []byte) byte {
+	sum := 0
+	for i, c := range d {
+		sum += (10 - i) * int(c-'0')
+	}
+	switch r := (11 - sum%11) % 11; r {
+	case 10:
+		return 'X'
+	default:
+		return byte('0' + r)
+	}
+}
+
+// isbn13Check calcula el dígito de control ISBN-13 (pesos 1 y 3, módulo 10).
+func isbn13Check(d []byte) byte {
+	sum := 0
+	for i, c := range d {
+		w := 1
+		if i%2 == 1 {
+			w = 3
+		}
+		sum += w * int(c-'0')
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// -------------------- Formato con guiones --------------------
+
+// isbnRange es un rango de editoriales de un grupo: los prefijos de largo
+// len(from) entre from y to (inclusive) identifican al editor.
+type isbnRange struct{ from, to string }
+
+// isbnGroups son los rangos de editoriales de los grupos más comunes en la
+// biblioteca (Agencia Internacional del ISBN). Para otros grupos FormatISBN
+// separa solo el prefijo y el dígito de control.
+var isbnGroups = map[string][]isbnRange{
+	"978-0":  {{"00", "19"}, {"200", "699"}, {"7000", "8499"}, {"85000", "89999"}, {"900000", "949999"}, {"9500000", "9999999"}},
+	"978-1":  {{"00", "09"}, {"100", "399"}, {"4000", "5499"}, {"55000", "86979"}, {"869800", "998999"}, {"9990000", "9999999"}},
+	"978-2":  {{"00", "19"}, {"200", "349"}, {"35000", "39999"}, {"400", "699"}, {"7000", "8399"}, {"84000", "89999"}, {"900000", "949999"}, {"9500000", "9999999"}},
+	"978-3":  {{"00", "02"}, {"030", "033"}, {"0340", "0369"}, {"03700", "03999"}, {"04", "19"}, {"200", "699"}, {"7000", "8499"}, {"85000", "89999"}, {"900000", "949999"}, {"9500000", "9539999"}, {"95400", "96999"}, {"9700000", "9849999"}, {"98500", "99999"}},
+	"978-84": {{"00", "13"}, {"140", "149"}, {"15000", "19999"}, {"200", "699"}, {"7000", "8499"}, {"85000", "89999"}, {"9000", "9199"}, {"920000", "923999"}, {"92400", "92999"}, {"930000", "949999"}, {"95000", "96999"}, {"9700", "9999"}},
+	"979-10": {{"00", "19"}, {"200", "699"}, {"7000", "8999"}, {"90000", "97599"}, {"976000", "999999"}},
+}
+
+// FormatISBN da formato con guiones a un ISBN canónico
+// ("9788437604947" -> "978-84-376-0494-7"). Los valores no canónicos
+// (datos heredados) se devuelven tal cual.
+func FormatISBN(canonical string) string {
+	if len(canonical) != 13 {
+		return canonical
+	}
+	for _, c := range canonical {
+		if c < '0' || c > '9' {
+			return canonical
+		}
+	}
+	prefix, rest, check := canonical[:3], canonical[3:12], canonical[12:]
+
+	for group := 1; group <= 5; group++ {
+		ranges, ok := isbnGroups[prefix+"-"+rest[:group]]
+		if !ok {
+			continue
+		}
+		tail := rest[group:]
+		for _, r := range ranges {
+			n := len(r.from)
+			if n >= len(tail) {
+				continue
+			}
+			if p := tail[:n]; p >= r.from && p <= r.to {
+				return strings.Join([]string{prefix, rest[:group], tail[:n], tail[n:], check}, "-")
+			}
+		}
+	}
+	return prefix + "-" + rest + "-" + check
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseISBN(t *testing.T) {
+	valid := map[string]string{
+		"9788437604947":          "9788437604947",
+		"978-84-376-0494-7":      "9788437604947",
+		"978 84 376 0494 7":      "9788437604947",
+		"84-376-0494-X":          "9788437604947", // ISBN-10 -> ISBN-13
+		"843760494x":             "9788437604947",
+		"ISBN 978-0-306-40615-7": "9780306406157",
+		"ISBN-10: 0-306-40615-2": "9780306406157",
+		"979-10-90636-07-1":      "9791090636071",
+	}
+	for in, want := range valid {
+		got, err := ParseISBN(in)
+		if err != nil || got != want {
+			t.Errorf("ParseISBN(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+
+	for _, in := range []string{"", "ISBN-1", "978-84-376-0494-8", "84-376-0494-2", "977-84-376-0494-7", "97884376049X7", "12345", "978_84_376_0494_7"} {
+		if _, err := ParseISBN(in); !errors.Is(err, ErrValidation) {
+			t.Errorf("ParseISBN(%q): expected validation error, got %v", in, err)
+		}
+	}
+}
+
+func TestFormatISBN(t *testing.T) {
+	cases := map[string]string{
+		"9788437604947": "978-84-376-0494-7",
+		"9780306406157": "978-0-306-40615-7",
+		"9781491950357": "978-1-4919-5035-7",
+		"9791090636071": "979-10-90636-07-1",
+		"9789500000009": "978-950000000-9", // grupo sin rangos conocidos
+		"ISBN-1":        "ISBN-1",          // dato heredado
+	}
+	for in, want := range cases {
+		if got := FormatISBN(in); got != want {
+			t.Errorf("FormatISBN(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if got := ISBN10("9788437604947"); got != "843760494X" {
+		t.Errorf("ISBN10: got %q", got)
+	}
+}
+
+func TestHydrateBookKeepsLegacyISBN(t *testing.T) {
+	if _, err := NewBook("Libro", "Autor", 2000, "ISBN-1", "General", nil, ""); !errors.Is(err, ErrValidation) {
+		t.Fatalf("NewBook must reject invalid isbn, got %v", err)
+	}
+	b, err := HydrateBook(1, "Libro", "Autor", 2000, "ISBN-1", "General", nil, "", true, time.Time{}, time.Time{})
+	if err != nil || b.ISBN() != "ISBN-1" {
+		t.Fatalf("HydrateBook must accept legacy isbn: %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+package domain // Dominio: paginación y ordenamiento de listados
+
+import (
+	"fmt"  // Mensajes de validación con contexto
+	"math" // Tope de página (sin desbordar el offset)
+)
+
+// -------------------- Ordenamiento --------------------
+
+// SortDir es la dirección de ordenamiento (asc / desc).
+type SortDir string
+
+const (
+	SortAsc  SortDir = "asc"  // Ascendente (A→Z, antiguo→nuevo)
+	SortDesc SortDir = "desc" // Descendente (Z→A, nuevo→antiguo)
+)
+
+const (
+	// Campo de orden por defecto: fecha de creación (equivale a "más nuevos primero").
+	SortCreatedAt = "created_at"
+
+	// SortRelevance ordena por puntuación de la búsqueda de texto completo.
+	// Sin texto libre (o en repositorios sin ranking) equivale a created_at.
+	SortRelevance = "relevance"
+
+	// SortRating ordena por promedio de estrellas de las reseñas aprobadas
+	// (los libros sin reseñas cuentan como 0).
+	SortRating = "rating"
+)
+
+// BookSortFields son los campos por los que se puede ordenar libros.
+var BookSortFields = []string{SortCreatedAt, SortRelevance, "title", "author", "year", SortRating}
+
+// UserSortFields son los campos por los que se puede ordenar usuarios.
+var UserSortFields = []string{SortCreatedAt, "name", "email", "role"}
+
+// -------------------- PageRequest --------------------
+
+const (
+	DefaultPageSize = 20  // Tamaño de página si el cliente no indica uno
+	MaxPageSize     = 100 // Tope para evitar respuestas gigantes
+)
+
+// PageRequest describe qué página pedir y cómo ordenarla.
+// Valores cero significan "por defecto" (página 1, 20 ítems, created_at desc).
+type PageRequest struct {
+	Page     int     // Página 1-based
+	PageSize int     // Ítems por página (se recorta a MaxPageSize)
+	Sort     string  // Campo de orden (ver BookSortFields / UserSortFields)
+	Dir      SortDir // Dirección; vacía => desc para created_at/relevance/rating, asc para el resto
+}
+
+// Normalize aplica valores por defecto y valida el campo/dirección de orden
+// contra los permitidos. Retorna ErrValidation si no son válidos o si la
+// página es tan grande que su offset no cabe en un int.
+func (p PageRequest) Normalize(allowed []string) (PageRequest, error) {
+	if p.Page < 1 {
+		p.Page = 1
+	}
+	if p.PageSize < 1 {
+		p.PageSize = DefaultPageSize
+	}
+	if p.PageSize > MaxPageSize {
+		p.PageSize = MaxPageSize
+	}
+	if p.Page > math.MaxInt/p.PageSize {
+		return p, fmt.Errorf("%w: page is too large", ErrValidation)
+	}
+
+	if p.Sort == "" {
+		p.Sort = SortCreatedAt
+	}
+	if !contains(allowed, p.Sort) {
+		return p, fmt.Errorf("%w: cannot sort by %q", ErrValidation, p.Sort)
+	}
+
+	switch p.Dir {
+	case SortAsc, SortDesc:
+	case "":
+		// Lo natural: fechas, relevancia y valoración de mayor a menor; textos/años de menor a mayor
+		p.Dir = SortAsc
+		if p.Sort == SortCreatedAt || p.Sort == SortRelevance || p.Sort == SortRating {
+			p.Dir = SortDesc
+		}
+	default:
+		return p, fmt.Errorf("%w: sort direction must be asc or desc", ErrValidation)
+	}
+	return p, nil
+}
+
+// Offset es la cantidad de filas a saltar (para LIMIT/OFFSET).
+func (p PageRequest) Offset() int {
+	if p.Page < 1 {
+		return 0
+	}
+	return (p.Page - 1) * p.PageSize
+}
+
+// Paging normaliza la paginación del filtro de libros.
+func (f BookFilter) Paging() (PageRequest, error) { return f.PageRequest.Normalize(BookSortFields) }
+
+// Paging normaliza la paginación del filtro de usuarios.
+func (f UserFilter) Paging() (PageRequest, error) { return f.PageRequest.Normalize(UserSortFields) }
+
+// -------------------- Page --------------------
+
+// Page es una página de resultados junto con el total de coincidencias.
+type Page[T any] struct {
+	Items    []T // Ítems de esta página
+	Total    int // Total de ítems que cumplen el filtro (todas las páginas)
+	Page     int // Página actual (1-based)
+	PageSize int // Tamaño de página aplicado
+}
+
+// TotalPages calcula cuántas páginas hay (mínimo 1, aunque no haya ítems).
+func (p Page[T]) TotalPages() int {
+	if p.PageSize < 1 || p.Total == 0 {
+		return 1
+	}
+	return (p.Total + p.PageSize - 1) / p.PageSize
+}
+
+// HasPrev indica si existe una página anterior.
+func (p Page[T]) HasPrev() bool { return p.Page > 1 }
+
+// HasNext indica si existe una página siguiente.
+func (p Page[T]) HasNext() bool { return p.Page < p.TotalPages() }
+
+// MapPage convierte los ítems de una página conservando los metadatos.
+func MapPage[A, B any](p Page[A], fn func([]A) []B) Page[B] {
+	return Page[B]{Items: fn(p.Items), Total: p.Total, Page: p.Page, PageSize: p.PageSize}
+}
+
+// contains indica si s está en list.
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
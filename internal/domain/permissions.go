@@ -0,0 +1,60 @@
+package domain // Dominio: permisos por rol (autorización)
+
+// Permission representa una acción protegida del sistema.
+// El formato "recurso:acción" se reutiliza como scope de tokens de API.
+type Permission string
+
+// Catálogo de permisos.
+const (
+	PermBooksRead       Permission = "books:read"       // Listar, buscar y ver libros
+	PermBooksWrite      Permission = "books:write"      // Crear, editar y eliminar libros
+	PermBooksDownload   Permission = "books:download"   // Descargar libros
+	PermUsersRead       Permission = "users:read"       // Ver usuarios
+	PermUsersWrite      Permission = "users:write"      // Crear, editar y eliminar usuarios
+	PermStatsRead       Permission = "stats:read"       // Ver estadísticas de acceso
+	PermLoansWrite      Permission = "loans:write"      // Mostrador: ejemplares, préstamos y devoluciones de cualquier usuario
+	PermReviewsModerate Permission = "reviews:moderate" // Aprobar, ocultar y borrar reseñas de cualquier usuario
+)
+
+// AllPermissions es el catálogo completo (útil para validar entradas).
+var AllPermissions = []Permission{
+	PermBooksRead, PermBooksWrite, PermBooksDownload,
+	PermUsersRead, PermUsersWrite, PermStatsRead, PermLoansWrite,
+	PermReviewsModerate,
+}
+
+// rolePermissions es la matriz rol -> permisos.
+// Se usa un MAP de sets para consultas O(1).
+var rolePermissions = map[Role]map[Permission]bool{
+	RoleAdmin: {
+		PermBooksRead: true, PermBooksWrite: true, PermBooksDownload: true,
+		PermUsersRead: true, PermUsersWrite: true, PermStatsRead: true,
+		PermLoansWrite: true, PermReviewsModerate: true,
+	},
+	RoleReader: {
+		PermBooksRead: true, PermBooksDownload: true,
+	},
+	RoleConsultor: {
+		PermBooksRead: true, PermStatsRead: true,
+	},
+}
+
+// IsValid indica si el permiso pertenece al catálogo.
+func (p Permission) IsValid() bool {
+	for _, known := range AllPermissions {
+		if p == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Can indica si el rol tiene el permiso indicado.
+func (r Role) Can(p Permission) bool {
+	return rolePermissions[r][p]
+}
+
+// Can indica si el usuario (activo) tiene el permiso indicado por su rol.
+func (u *User) Can(p Permission) bool {
+	return u != nil && u.active && u.role.Can(p)
+}
@@ -0,0 +1,213 @@
+package domain // Dominio: progreso de lectura de un usuario en un libro
+
+import (
+	"fmt"          // Errores con contexto
+	"math"         // Redondeo del porcentaje
+	"strings"      // Normalización de la ubicación
+	"time"         // Fechas de lectura
+	"unicode/utf8" // Largo de la ubicación en caracteres
+)
+
+// MaxReadingLocationLen es el largo máximo de la ubicación de lectura
+// (EPUB CFI u otra ancla).
+const MaxReadingLocationLen = 500
+
+// -------------------- ReadingPosition --------------------
+
+// ReadingPosition es por dónde va el lector, tal como la informa el
+// cliente. Puede venir cualquier combinación: un lector de PDF manda
+// páginas, uno de EPUB una CFI y un porcentaje.
+type ReadingPosition struct {
+	Page       int     // Página actual (1 = primera; 0 si no aplica)
+	TotalPages int     // Páginas del libro (0 si no se conocen)
+	Percent    float64 // Avance 0-100 (si falta y hay páginas, se calcula)
+	Location   string  // Ancla del formato, p. ej. "epubcfi(/6/14!/4/2/1:0)"
+}
+
+// Normalize valida la posición y completa el porcentaje a partir de las
+// páginas cuando el cliente no lo manda. Retorna ErrValidation si no es
+// coherente.
+func (p ReadingPosition) Normalize() (ReadingPosition, error) {
+	p.Location = strings.TrimSpace(p.Location)
+	switch {
+	case p.Page < 0 || p.TotalPages < 0:
+		return p, fmt.Errorf("%w: page and total_pages cannot be negative", ErrValidation)
+	case p.TotalPages > 0 && p.Page > p.TotalPages:
+		return p, fmt.Errorf("%w: page %d is past the last page (%d)", ErrValidation, p.Page, p.TotalPages)
+	case math.IsNaN(p.Percent) || p.Percent < 0 || p.Percent > 100:
+		return p, fmt.Errorf("%w: percent must be between 0 and 100", ErrValidation)
+	case utf8.RuneCountInString(p.Location) > MaxReadingLocationLen:
+		return p, fmt.Errorf("%w: location too long (max %d characters)", ErrValidation, MaxReadingLocationLen)
+	case strings.HasPrefix(p.Location, "epubcfi(") && !strings.HasSuffix(p.Location, ")"):
+		return p, fmt.Errorf("%w: malformed EPUB CFI", ErrValidation)
+	}
+	if p.Percent == 0 && p.TotalPages > 0 {
+		p.Percent = float64(p.Page) / float64(p.TotalPages) * 100
+	}
+	p.Percent = math.Round(p.Percent*10) / 10 // un decimal alcanza para mostrar
+	return p, nil
+}
+
+// -------------------- ReadingProgress --------------------
+
+// ReadingProgress es el avance de un usuario en un libro (a lo sumo uno por
+// par usuario-libro; se actualiza en cada guardado).
+type ReadingProgress struct {
+	id         uint64          // ID único (asignado por BD)
+	userID     uint64          // Lector
+	bookID     uint64          // Libro
+	position   ReadingPosition // Última posición informada
+	startedAt  time.Time       // Primera vez que se guardó
+	lastReadAt time.Time       // Último guardado
+	finishedAt time.Time       // Cuándo lo terminó (cero si no)
+}
+
+// NewReadingProgress valida la posición de un lector en un libro.
+func NewReadingProgress(userID, bookID uint64, pos ReadingPosition) (*ReadingProgress, error) {
+	if userID == 0 || bookID == 0 {
+		return nil, fmt.Errorf("%w: user_id and book_id are required", ErrValidation)
+	}
+	pos, err := pos.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return &ReadingProgress{userID: userID, bookID: bookID, position: pos, startedAt: now, lastReadAt: now}, nil
+}
+
+// HydrateReadingProgress reconstruye un progreso desde la persistencia (sin validar).
+func HydrateReadingProgress(id, userID, bookID uint64, pos ReadingPosition, startedAt, lastReadAt, finishedAt time.Time) *ReadingProgress {
+	return &ReadingProgress{
+		id: id, userID: userID, bookID: bookID, position: pos,
+		startedAt: startedAt, lastReadAt: lastReadAt, finishedAt: finishedAt,
+	}
+}
+
+// MarkFinished marca el libro como terminado en at. La posición no cambia:
+// quien relee un libro terminado sigue contando como que lo terminó.
+func (p *ReadingProgress) MarkFinished(at time.Time) { p.finishedAt = at }
+
+// ID devuelve el ID del progreso
+func (p *ReadingProgress) ID() uint64 { return p.id }
+
+// UserID devuelve el lector
+func (p *ReadingProgress) UserID() uint64 { return p.userID }
+
+// BookID devuelve el libro
+func (p *ReadingProgress) BookID() uint64 { return p.bookID }
+
+// Position devuelve la última posición informada
+func (p *ReadingProgress) Position() ReadingPosition { return p.position }
+
+// Page devuelve la página actual (0 si no aplica)
+func (p *ReadingProgress) Page() int { return p.position.Page }
+
+// TotalPages devuelve las páginas del libro (0 si no se conocen)
+func (p *ReadingProgress) TotalPages() int { return p.position.TotalPages }
+
+// Percent devuelve el avance (0-100)
+func (p *ReadingProgress) Percent() float64 { return p.position.Percent }
+
+// Location devuelve el ancla de la posición (EPUB CFI, etc.)
+func (p *ReadingProgress) Location() string { return p.position.Location }
+
+// StartedAt devuelve cuándo empezó a leer
+func (p *ReadingProgress) StartedAt() time.Time { return p.startedAt }
+
+// LastReadAt devuelve la última vez que guardó su avance
+func (p *ReadingProgress) LastReadAt() time.Time { return p.lastReadAt }
+
+// FinishedAt devuelve cuándo lo terminó (cero si no lo terminó)
+func (p *ReadingProgress) FinishedAt() time.Time { return p.finishedAt }
+
+// Finished indica si el lector terminó el libro
+func (p *ReadingProgress) Finished() bool { return !p.finishedAt.IsZero() }
+
+// ProgressDetail es un progreso con los datos que muestran los listados.
+type ProgressDetail struct {
+	Progress   *ReadingProgress
+	BookTitle  string // Título del libro
+	BookAuthor string // Autor del libro
+	UserName   string // Nombre del lector
+}
+
+// -------------------- ProgressFilter --------------------
+
+// Estados de lectura para filtrar.
+const (
+	ProgressStatusReading  = "reading"  // Empezados y sin terminar
+	ProgressStatusFinished = "finished" // Terminados
+)
+
+// ProgressFilter encapsula filtros para listar progresos.
+type ProgressFilter struct {
+	UserID uint64 // Progresos del lector (0 = todos)
+	BookID uint64 // Progresos del libro (0 = todos)
+	Status string // "", reading o finished
+
+	PageRequest // Página, tamaño y orden (last_read_at por defecto, percent)
+}
+
+// ProgressSortFields son los campos por los que se puede ordenar progresos.
+var ProgressSortFields = []string{"last_read_at", "percent", SortCreatedAt}
+
+// Paging normaliza la paginación (por defecto lo leído más reciente
+// primero) y valida el estado del filtro.
+func (f ProgressFilter) Paging() (PageRequest, error) {
+	switch f.Status {
+	case "", ProgressStatusReading, ProgressStatusFinished:
+	default:
+		return PageRequest{}, fmt.Errorf("%w: status must be reading or finished", ErrValidation)
+	}
+	p := f.PageRequest
+	if p.Sort == "" {
+		p.Sort = "last_read_at"
+		if p.Dir == "" {
+			p.Dir = SortDesc
+		}
+	}
+	return p.Normalize(ProgressSortFields)
+}
+
+// -------------------- BookCompletion --------------------
+
+// BookCompletion resume cuántos lectores empezaron y terminaron un libro
+// (para que el docente vea las tasas de finalización).
+type BookCompletion struct {
+	BookID     uint64
+	Title      string
+	Author     string
+	Readers    int     // Lectores con progreso guardado
+	Finished   int     // De ellos, cuántos lo terminaron
+	AvgPercent float64 // Avance promedio (0-100)
+}
+
+// Rate devuelve el porcentaje de lectores que terminaron el libro (0 sin lectores).
+func (c BookCompletion) Rate() float64 {
+	if c.Readers == 0 {
+		return 0
+	}
+	return math.Round(float64(c.Finished)/float64(c.Readers)*1000) / 10
+}
+
+// CompletionFilter encapsula filtros para el resumen por libro.
+type CompletionFilter struct {
+	BookID uint64 // Un libro (0 = todos los que tengan lectores)
+
+	PageRequest // Página, tamaño y orden (readers por defecto, rate, avg_percent, title)
+}
+
+// CompletionSortFields son los campos por los que se puede ordenar el resumen.
+var CompletionSortFields = []string{"readers", "rate", "avg_percent", "title"}
+
+// Paging normaliza la paginación (por defecto los libros con más lectores primero).
+func (f CompletionFilter) Paging() (PageRequest, error) {
+	p := f.PageRequest
+	if p.Sort == "" {
+		p.Sort = "readers"
+		if p.Dir == "" {
+			p.Dir = SortDesc
+		}
+	}
+	return p.Normalize(CompletionSortFields)
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestReadingPositionNormalize(t *testing.T) {
+	valid := map[ReadingPosition]ReadingPosition{
+		{Page: 50, TotalPages: 200}:                          {Page: 50, TotalPages: 200, Percent: 25},
+		{Page: 1, TotalPages: 3}:                             {Page: 1, TotalPages: 3, Percent: 33.3},
+		{Page: 10, TotalPages: 200, Percent: 7.25}:           {Page: 10, TotalPages: 200, Percent: 7.3}, // manda el del cliente
+		{Location: " epubcfi(/6/14!/4/2/1:0) ", Percent: 42}: {Location: "epubcfi(/6/14!/4/2/1:0)", Percent: 42},
+		{}: {},
+	}
+	for in, want := range valid {
+		got, err := in.Normalize()
+		if err != nil || got != want {
+			t.Errorf("Normalize(%+v) = %+v, %v; want %+v", in, got, err, want)
+		}
+	}
+
+	for _, in := range []ReadingPosition{
+		{Page: -1},
+		{Page: 201, TotalPages: 200},
+		{Percent: 100.5},
+		{Location: "epubcfi(/6/14!/4/2"},
+		{Location: strings.Repeat("x", MaxReadingLocationLen+1)},
+	} {
+		if _, err := in.Normalize(); !errors.Is(err, ErrValidation) {
+			t.Errorf("Normalize(%+v): expected validation error, got %v", in, err)
+		}
+	}
+}
@@ -0,0 +1,439 @@
+package domain // Dominio: lenguaje de consulta del parámetro q (AST + parser)
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Lenguaje de consulta de libros (BookFilter.Q):
+//
+//	cien años                 palabras: todas deben aparecer (AND implícito)
+//	"cien años"               frase exacta
+//	sol*                      prefijo
+//	author:"García Márquez"   campo (author, title, category: contiene; tag, isbn: exacto)
+//	year:1967  year:1960..1980  year:1990..  year:..1950
+//	-tag:infantil  NOT x      negación
+//	novela OR cuento          alternativa (menor precedencia que AND)
+//	(a OR b) c                agrupación
+//
+// ParseQuery produce un AST tipado que cada backend (SQL, memoria, índice)
+// ejecuta a su manera; los errores son ErrValidation con la posición.
+
+// MaxQueryTerms limita el tamaño de la consulta (evita SQL desmedido).
+const MaxQueryTerms = 32
+
+// QueryExpr es un nodo del AST de consulta.
+type QueryExpr interface{ queryExpr() }
+
+// AndExpr exige que se cumplan todos los términos.
+type AndExpr struct{ Terms []QueryExpr }
+
+// OrExpr exige que se cumpla al menos uno.
+type OrExpr struct{ Terms []QueryExpr }
+
+// NotExpr niega el término.
+type NotExpr struct{ Term QueryExpr }
+
+// TextTerm es texto libre sobre título, autor, etiquetas y descripción.
+type TextTerm struct {
+	Text   string // Texto tal como se escribió (sin comillas ni *)
+	Phrase bool   // Venía entre comillas
+	Prefix bool   // Terminaba en * (prefijo)
+}
+
+// QueryField es un campo consultable con campo:valor.
+type QueryField string
+
+const (
+	FieldAuthor   QueryField = "author"   // contiene
+	FieldTitle    QueryField = "title"    // contiene
+	FieldCategory QueryField = "category" // contiene
+	FieldTag      QueryField = "tag"      // etiqueta exacta
+	FieldISBN     QueryField = "isbn"     // ISBN exacto
+	fieldYear     QueryField = "year"     // se convierte en YearRange
+)
+
+// FieldTerm filtra por un campo del libro (sin distinguir mayúsculas).
+type FieldTerm struct {
+	Field QueryField
+	Value string
+}
+
+// YearRange filtra por año de publicación; 0 = sin límite.
+type YearRange struct{ From, To int }
+
+func (AndExpr) queryExpr()   {}
+func (OrExpr) queryExpr()    {}
+func (NotExpr) queryExpr()   {}
+func (TextTerm) queryExpr()  {}
+func (FieldTerm) queryExpr() {}
+func (YearRange) queryExpr() {}
+
+// queryFields son los campos válidos antes de ":".
+var queryFields = map[string]QueryField{
+	"author": FieldAuthor, "title": FieldTitle, "category": FieldCategory,
+	"tag": FieldTag, "isbn": FieldISBN, "year": fieldYear,
+}
+
+// ParseQuery convierte q en un AST. Una consulta vacía devuelve nil (sin filtro).
+func ParseQuery(q string) (QueryExpr, error) {
+	toks, err := lexQuery(q)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{toks: toks}
+	if len(toks) == 0 {
+		return nil, nil
+	}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t != nil {
+		// Solo puede quedar un ")" sin abrir
+		return nil, queryErr(t.pos, "unexpected %q", t.text)
+	}
+	if p.terms > MaxQueryTerms {
+		return nil, fmt.Errorf("%w: query has too many terms (max %d)", ErrValidation, MaxQueryTerms)
+	}
+	return e, nil
+}
+
+// Query interpreta f.Q (nil si está vacío). Los repositorios la ejecutan
+// junto con los demás filtros (AND).
+func (f BookFilter) Query() (QueryExpr, error) {
+	return ParseQuery(f.Q)
+}
+
+// EvalQuery evalúa e con leaf decidiendo cada hoja (TextTerm, FieldTerm, YearRange).
+// Un e nil (consulta vacía) siempre se cumple.
+func EvalQuery(e QueryExpr, leaf func(QueryExpr) bool) bool {
+	switch n := e.(type) {
+	case nil:
+		return true
+	case AndExpr:
+		for _, t := range n.Terms {
+			if !EvalQuery(t, leaf) {
+				return false
+			}
+		}
+		return true
+	case OrExpr:
+		for _, t := range n.Terms {
+			if EvalQuery(t, leaf) {
+				return true
+			}
+		}
+		return false
+	case NotExpr:
+		return !EvalQuery(n.Term, leaf)
+	default:
+		return leaf(n)
+	}
+}
+
+// PositiveTextTerms lista los TextTerm que no están negados (los que aportan
+// relevancia y se resaltan).
+func PositiveTextTerms(e QueryExpr) []TextTerm {
+	var out []TextTerm
+	var walk func(e QueryExpr, negated bool)
+	walk = func(e QueryExpr, negated bool) {
+		switch n := e.(type) {
+		case AndExpr:
+			for _, t := range n.Terms {
+				walk(t, negated)
+			}
+		case OrExpr:
+			for _, t := range n.Terms {
+				walk(t, negated)
+			}
+		case NotExpr:
+			walk(n.Term, !negated)
+		case TextTerm:
+			if !negated {
+				out = append(out, n)
+			}
+		}
+	}
+	walk(e, false)
+	return out
+}
+
+// -------------------- Lexer --------------------
+
+type qtokKind int
+
+const (
+	qtWord   qtokKind = iota // palabra suelta (puede terminar en *)
+	qtPhrase                 // "..."
+	qtField                  // campo: (el valor es el token siguiente)
+	qtLParen
+	qtRParen
+	qtNot // - pegado o NOT
+	qtOr  // OR
+)
+
+type qtok struct {
+	kind qtokKind
+	text string
+	pos  int // posición (1-based, en caracteres) para los mensajes
+}
+
+func lexQuery(q string) ([]qtok, error) {
+	var out []qtok
+	runes := []rune(q)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		pos := i + 1
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			out = append(out, qtok{kind: qtLParen, text: "(", pos: pos})
+			i++
+		case r == ')':
+			out = append(out, qtok{kind: qtRParen, text: ")", pos: pos})
+			i++
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, queryErr(pos, "unterminated quote")
+			}
+			out = append(out, qtok{kind: qtPhrase, text: string(runes[i+1 : end]), pos: pos})
+			i = end + 1
+		case r == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) && runes[i+1] != ')':
+			out = append(out, qtok{kind: qtNot, text: "-", pos: pos})
+			i++
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune(`()"`, runes[end]) {
+				end++
+			}
+			word := string(runes[i:end])
+			i = end
+
+			// campo:valor (el valor puede ser una frase: author:"García Márquez")
+			if name, value, ok := strings.Cut(word, ":"); ok && isFieldName(name) {
+				out = append(out, qtok{kind: qtField, text: strings.ToLower(name), pos: pos})
+				if value != "" {
+					out = append(out, qtok{kind: qtWord, text: value, pos: pos + utf8.RuneCountInString(name) + 1})
+				}
+				continue
+			}
+			switch word {
+			case "OR":
+				out = append(out, qtok{kind: qtOr, text: word, pos: pos})
+			case "NOT":
+				out = append(out, qtok{kind: qtNot, text: word, pos: pos})
+			case "AND":
+				// AND es implícito; se acepta por comodidad
+			default:
+				out = append(out, qtok{kind: qtWord, text: word, pos: pos})
+			}
+		}
+	}
+	return out, nil
+}
+
+// isFieldName indica si s es uno de queryFields. Cualquier otra cosa antes
+// de ":" sigue siendo texto libre: "10:30", "c++:" o el título
+// "Cosmos: un viaje personal".
+func isFieldName(s string) bool {
+	_, ok := queryFields[strings.ToLower(s)]
+	return ok
+}
+
+// -------------------- Parser --------------------
+
+// queryParser es un parser descendente recursivo:
+//
+//	or    := and ("OR" and)*
+//	and   := unary unary*
+//	unary := ("-" | "NOT") unary | primary
+//	primary := "(" or ")" | field ":" value | "frase" | palabra
+type queryParser struct {
+	toks  []qtok
+	i     int
+	terms int // hojas creadas (para MaxQueryTerms)
+}
+
+func (p *queryParser) peek() *qtok {
+	if p.i < len(p.toks) {
+		return &p.toks[p.i]
+	}
+	return nil
+}
+
+func (p *queryParser) next() *qtok {
+	t := p.peek()
+	if t != nil {
+		p.i++
+	}
+	return t
+}
+
+func (p *queryParser) parseOr() (QueryExpr, error) {
+	var terms []QueryExpr
+	for {
+		e, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if e != nil {
+			terms = append(terms, e)
+		}
+		t := p.peek()
+		if t == nil || t.kind != qtOr {
+			break
+		}
+		p.next()
+		if n := p.peek(); n == nil || n.kind == qtOr || n.kind == qtRParen {
+			return nil, queryErr(t.pos, "OR needs a term on each side")
+		}
+	}
+	switch len(terms) {
+	case 0:
+		return nil, nil // solo términos vacíos ("" o *): sin filtro
+	case 1:
+		return terms[0], nil
+	}
+	return OrExpr{Terms: terms}, nil
+}
+
+// parseAnd devuelve nil si no hubo términos que filtren.
+func (p *queryParser) parseAnd() (QueryExpr, error) {
+	var terms []QueryExpr
+	if t := p.peek(); t != nil && t.kind == qtOr {
+		return nil, queryErr(t.pos, "OR needs a term on each side")
+	}
+	for t := p.peek(); t != nil && t.kind != qtOr && t.kind != qtRParen; t = p.peek() {
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if e != nil {
+			terms = append(terms, e)
+		}
+	}
+	switch len(terms) {
+	case 0:
+		return nil, nil
+	case 1:
+		return terms[0], nil
+	}
+	return AndExpr{Terms: terms}, nil
+}
+
+func (p *queryParser) parseUnary() (QueryExpr, error) {
+	t := p.peek()
+	if t.kind != qtNot {
+		return p.parsePrimary()
+	}
+	p.next()
+	if n := p.peek(); n == nil || n.kind == qtOr || n.kind == qtRParen {
+		return nil, queryErr(t.pos, "nothing to negate after %q", t.text)
+	}
+	e, err := p.parseUnary()
+	if err != nil || e == nil {
+		return nil, err
+	}
+	return NotExpr{Term: e}, nil
+}
+
+func (p *queryParser) parsePrimary() (QueryExpr, error) {
+	t := p.next()
+	switch t.kind {
+	case qtLParen:
+		if n := p.peek(); n != nil && n.kind == qtRParen {
+			return nil, queryErr(t.pos, "empty parentheses")
+		}
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if n := p.next(); n == nil || n.kind != qtRParen {
+			return nil, queryErr(t.pos, "unbalanced parentheses")
+		}
+		return e, nil
+	case qtRParen:
+		return nil, queryErr(t.pos, "unexpected %q", t.text)
+	case qtPhrase:
+		if strings.TrimSpace(t.text) == "" {
+			return nil, nil // "" no filtra nada
+		}
+		p.terms++
+		return TextTerm{Text: t.text, Phrase: true}, nil
+	case qtField:
+		return p.parseField(t)
+	default: // palabra
+		text := strings.TrimRight(t.text, "*")
+		if text == "" {
+			return nil, nil // "*" suelto no filtra
+		}
+		p.terms++
+		return TextTerm{Text: text, Prefix: text != t.text}, nil
+	}
+}
+
+func (p *queryParser) parseField(t *qtok) (QueryExpr, error) {
+	field := queryFields[t.text] // el lexer solo emite campos conocidos
+	v := p.peek()
+	if v == nil || (v.kind != qtWord && v.kind != qtPhrase) || strings.TrimSpace(v.text) == "" {
+		return nil, queryErr(t.pos, "missing value for %s:", t.text)
+	}
+	p.next()
+	p.terms++
+
+	if field == fieldYear {
+		return parseYearRange(v)
+	}
+	value := strings.TrimSpace(v.text)
+	if field == FieldISBN {
+		value = NormalizeISBN(value) // con o sin guiones, ISBN-10 o ISBN-13
+	}
+	return FieldTerm{Field: field, Value: value}, nil
+}
+
+// parseYearRange acepta 1967, 1960..1980, 1990.. y ..1950.
+func parseYearRange(t *qtok) (QueryExpr, error) {
+	from, to, isRange := strings.Cut(t.text, "..")
+	if !isRange {
+		to = from
+	}
+	year := func(s string) (int, error) {
+		if s == "" && isRange {
+			return 0, nil // extremo abierto
+		}
+		y, err := strconv.Atoi(s)
+		if err != nil || y < 1 || y > 9999 {
+			return 0, queryErr(t.pos, "invalid year %q", s)
+		}
+		return y, nil
+	}
+	f, err := year(from)
+	if err != nil {
+		return nil, err
+	}
+	l, err := year(to)
+	if err != nil {
+		return nil, err
+	}
+	if f == 0 && l == 0 {
+		return nil, queryErr(t.pos, "year range needs at least one end")
+	}
+	if f > 0 && l > 0 && f > l {
+		return nil, queryErr(t.pos, "invalid year range %s (start after end)", t.text)
+	}
+	return YearRange{From: f, To: l}, nil
+}
+
+// queryErr arma un ErrValidation con la posición (1-based) dentro de q.
+func queryErr(pos int, format string, args ...any) error {
+	return fmt.Errorf("%w: query: %s at position %d", ErrValidation, fmt.Sprintf(format, args...), pos)
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	cases := map[string]QueryExpr{
+		"":       nil,
+		`  "" *`: nil,
+		"cien años": AndExpr{Terms: []QueryExpr{
+			TextTerm{Text: "cien"}, TextTerm{Text: "años"},
+		}},
+		`"cien años" sol*`: AndExpr{Terms: []QueryExpr{
+			TextTerm{Text: "cien años", Phrase: true}, TextTerm{Text: "sol", Prefix: true},
+		}},
+		`Author:"García Márquez" -tag:infantil`: AndExpr{Terms: []QueryExpr{
+			FieldTerm{Field: FieldAuthor, Value: "García Márquez"},
+			NotExpr{Term: FieldTerm{Field: FieldTag, Value: "infantil"}},
+		}},
+		"isbn:84-376-0494-X": FieldTerm{Field: FieldISBN, Value: "9788437604947"},
+		"year:1967 year:1960..1980 year:1990.. year:..1950": AndExpr{Terms: []QueryExpr{
+			YearRange{From: 1967, To: 1967}, YearRange{From: 1960, To: 1980},
+			YearRange{From: 1990}, YearRange{To: 1950},
+		}},
+		"(novela OR cuento) NOT terror": AndExpr{Terms: []QueryExpr{
+			OrExpr{Terms: []QueryExpr{TextTerm{Text: "novela"}, TextTerm{Text: "cuento"}}},
+			NotExpr{Term: TextTerm{Text: "terror"}},
+		}},
+		"a b OR c AND d": OrExpr{Terms: []QueryExpr{
+			AndExpr{Terms: []QueryExpr{TextTerm{Text: "a"}, TextTerm{Text: "b"}}},
+			AndExpr{Terms: []QueryExpr{TextTerm{Text: "c"}, TextTerm{Text: "d"}}},
+		}},
+		// Solo los campos conocidos: lo demás antes de ":" es texto libre
+		"Cosmos: un viaje personal": AndExpr{Terms: []QueryExpr{
+			TextTerm{Text: "Cosmos:"}, TextTerm{Text: "un"}, TextTerm{Text: "viaje"}, TextTerm{Text: "personal"},
+		}},
+		"genre:novela 10:30": AndExpr{Terms: []QueryExpr{
+			TextTerm{Text: "genre:novela"}, TextTerm{Text: "10:30"},
+		}},
+	}
+	for q, want := range cases {
+		got, err := ParseQuery(q)
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("ParseQuery(%q) = %#v, %v; want %#v", q, got, err, want)
+		}
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	cases := map[string]string{
+		`"cien años`:                          "unterminated quote at position 1",
+		"author:":                             "missing value for author: at position 1",
+		"title: -x":                           "missing value for title: at position 1",
+		"year:sesenta":                        `invalid year "sesenta" at position 6`,
+		"year:1980..1960":                     "start after end",
+		"year:..":                             "needs at least one end",
+		"(novela":                             "unbalanced parentheses at position 1",
+		"novela)":                             `unexpected ")" at position 7`,
+		"()":                                  "empty parentheses",
+		"OR novela":                           "OR needs a term on each side",
+		"novela OR":                           "OR needs a term on each side",
+		"NOT":                                 "nothing to negate",
+		strings.Repeat("a ", MaxQueryTerms+1): "too many terms",
+	}
+	for q, want := range cases {
+		if _, err := ParseQuery(q); !errors.Is(err, ErrValidation) || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseQuery(%q): expected validation error with %q, got %v", q, want, err)
+		}
+	}
+}
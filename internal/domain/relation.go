@@ -0,0 +1,143 @@
+package domain // Dominio: relaciones tipadas entre libros (continuación, cuaderno, guía, adaptación)
+
+import (
+	"fmt"     // Errores con contexto
+	"strings" // Normalización del tipo
+	"time"    // Fecha de alta
+)
+
+// -------------------- RelationType --------------------
+
+// RelationType es el tipo de una relación "from -> to" entre dos libros.
+// Se lee como "from es <tipo> de to".
+type RelationType string
+
+const (
+	RelationSequel       RelationType = "sequel"        // from es la continuación de to
+	RelationWorkbook     RelationType = "workbook"      // from es el cuaderno de trabajo de to
+	RelationTeacherGuide RelationType = "teacher_guide" // from es la guía docente de to
+	RelationAdaptation   RelationType = "adaptation"    // from es una adaptación de to
+	RelationCompanion    RelationType = "companion"     // from y to se complementan (simétrica)
+)
+
+// RelationTypes son los tipos aceptados, en el orden en que se muestran.
+var RelationTypes = []RelationType{RelationSequel, RelationWorkbook, RelationTeacherGuide, RelationAdaptation, RelationCompanion}
+
+// ParseRelationType normaliza el tipo ("Teacher_Guide" -> teacher_guide).
+func ParseRelationType(s string) (RelationType, error) {
+	t := RelationType(strings.ToLower(strings.TrimSpace(s)))
+	for _, ok := range RelationTypes {
+		if t == ok {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("%w: unknown relation type %q", ErrValidation, s)
+}
+
+// Acyclic indica si el tipo es una jerarquía: un libro no puede ser, por
+// ejemplo, continuación de su propia continuación. Las relaciones simétricas
+// (companion) no tienen dirección y no forman ciclos.
+func (t RelationType) Acyclic() bool { return t != RelationCompanion }
+
+// -------------------- BookRelation --------------------
+
+// BookRelation relaciona dos libros. Si es bidireccional también se muestra
+// en el libro destino (con el sentido inverso: "continúa en", "tiene
+// cuaderno"...); si no, solo en el libro origen.
+type BookRelation struct {
+	id            uint64       // ID único (asignado por BD)
+	fromID        uint64       // Libro origen
+	toID          uint64       // Libro destino
+	relType       RelationType // Tipo de la relación
+	bidirectional bool         // Visible desde ambos libros
+	createdAt     time.Time    // Fecha de alta
+}
+
+// NewBookRelation crea la relación "fromID es <t> de toID". Los ciclos y los
+// duplicados se validan con CheckBookRelation, que conoce las demás relaciones.
+func NewBookRelation(fromID, toID uint64, t RelationType, bidirectional bool) (*BookRelation, error) {
+	t, err := ParseRelationType(string(t))
+	if err != nil {
+		return nil, err
+	}
+	if fromID == 0 || toID == 0 {
+		return nil, fmt.Errorf("%w: relation needs two books", ErrValidation)
+	}
+	if fromID == toID {
+		return nil, fmt.Errorf("%w: a book cannot be related to itself", ErrValidation)
+	}
+	return &BookRelation{fromID: fromID, toID: toID, relType: t, bidirectional: bidirectional, createdAt: time.Now()}, nil
+}
+
+// HydrateBookRelation reconstruye una relación desde la persistencia (sin validar).
+func HydrateBookRelation(id, fromID, toID uint64, t RelationType, bidirectional bool, createdAt time.Time) *BookRelation {
+	return &BookRelation{id: id, fromID: fromID, toID: toID, relType: t, bidirectional: bidirectional, createdAt: createdAt}
+}
+
+// ID devuelve el ID de la relación
+func (r *BookRelation) ID() uint64 { return r.id }
+
+// FromID devuelve el libro origen
+func (r *BookRelation) FromID() uint64 { return r.fromID }
+
+// ToID devuelve el libro destino
+func (r *BookRelation) ToID() uint64 { return r.toID }
+
+// Type devuelve el tipo de la relación
+func (r *BookRelation) Type() RelationType { return r.relType }
+
+// Bidirectional indica si la relación se muestra también en el libro destino
+func (r *BookRelation) Bidirectional() bool { return r.bidirectional }
+
+// CreatedAt devuelve la fecha de alta
+func (r *BookRelation) CreatedAt() time.Time { return r.createdAt }
+
+// CheckBookRelation valida la relación nueva contra las existentes del mismo
+// tipo (same): no se repite (en una simétrica, tampoco al revés) y en los
+// tipos jerárquicos no cierra un ciclo (to no puede llegar ya a from).
+func CheckBookRelation(same []*BookRelation, r *BookRelation) error {
+	next := map[uint64][]uint64{}
+	for _, e := range same {
+		if e.Type() != r.Type() {
+			continue
+		}
+		if e.FromID() == r.FromID() && e.ToID() == r.ToID() ||
+			!r.Type().Acyclic() && e.FromID() == r.ToID() && e.ToID() == r.FromID() {
+			return fmt.Errorf("%w: books %d and %d are already related as %s", ErrDuplicate, r.FromID(), r.ToID(), r.Type())
+		}
+		next[e.FromID()] = append(next[e.FromID()], e.ToID())
+	}
+	if !r.Type().Acyclic() {
+		return nil
+	}
+
+	// Recorre desde to siguiendo la dirección de las relaciones; si llega a
+	// from, la nueva arista cerraría un ciclo
+	seen := map[uint64]bool{}
+	stack := []uint64{r.ToID()}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if id == r.FromID() {
+			return fmt.Errorf("%w: %s relation between books %d and %d would create a cycle", ErrValidation, r.Type(), r.FromID(), r.ToID())
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		stack = append(stack, next[id]...)
+	}
+	return nil
+}
+
+// RelatedBook es una relación vista desde uno de sus libros: el otro libro
+// (con los datos que muestra la pantalla) y si se ve en sentido inverso
+// (el libro actual es el destino).
+type RelatedBook struct {
+	Relation *BookRelation
+	Inverse  bool   // true si el libro actual es el destino de la relación
+	BookID   uint64 // El otro libro
+	Title    string
+	Author   string
+	Year     int
+}
@@ -1,6 +1,9 @@
 package domain // Dominio: contratos (interfaces) y tipos de búsqueda
 
-import "context" // Context permite cancelación/timeout desde HTTP hacia DB
+import (
+	"context" // Context permite cancelación/timeout desde HTTP hacia DB
+	"time"    // Referencias de vencimiento (reservas)
+)
 
 // -------------------- UserRepository --------------------
 
@@ -26,6 +29,10 @@ type UserRepository interface {
 	// List retorna todos los usuarios.
 	List(ctx context.Context) ([]*User, error)
 
+	// Search retorna una página de usuarios que cumplen el filtro (rol, estado, email)
+	// junto con el total de coincidencias.
+	Search(ctx context.Context, f UserFilter) (Page[*User], error)
+
 	// Update actualiza los datos del usuario.
 	Update(ctx context.Context, u *User) error
 
@@ -34,14 +41,36 @@ type UserRepository interface {
 	Delete(ctx context.Context, id uint64) error
 }
 
+// -------------------- UserFilter --------------------
+
+// UserFilter encapsula filtros para listar usuarios.
+// Campos vacíos (o nil) significan "sin filtro".
+type UserFilter struct {
+	Role   Role   // Rol exacto (ADMIN, READER, CONSULTOR)
+	Active *bool  // Estado: nil => todos
+	Email  string // Subcadena del email (sin distinguir mayúsculas)
+
+	PageRequest // Página, tamaño y orden (name, email, role, created_at)
+}
+
 // -------------------- BookFilter --------------------
 
 // BookFilter encapsula filtros de búsqueda de libros.
 // Se mantiene simple para construir consultas dinámicas en repositorio.
 type BookFilter struct {
-	Q        string // Texto libre (título/ISBN/descripción, etc.)
+	Q        string // Consulta (texto libre y campo:valor, ver ParseQuery)
 	Author   string // Filtro por autor
 	Category string // Filtro por categoría
+	Tag      string // Etiqueta (mismo slug: sin distinguir mayúsculas ni acentos)
+	YearFrom int    // Año mínimo inclusive (0 = sin límite)
+	YearTo   int    // Año máximo inclusive (0 = sin límite)
+
+	MinRating float64 // Promedio mínimo de estrellas (0 = sin filtro; excluye los libros sin reseñas)
+
+	WithFacets bool // Pedir facetas del resultado (requiere índice de búsqueda)
+	Fuzzy      bool // Tolerar errores de tipeo en el texto libre (requiere índice)
+
+	PageRequest // Página, tamaño y orden (title, author, year, created_at, rating)
 }
 
 // -------------------- BookRepository --------------------
@@ -61,8 +90,9 @@ type BookRepository interface {
 	// List retorna todos los libros.
 	List(ctx context.Context) ([]*Book, error)
 
-	// Search busca libros por filtros (Q, author, category).
-	Search(ctx context.Context, f BookFilter) ([]*Book, error)
+	// Search busca libros por filtros (Q, author, category) y retorna
+	// la página pedida junto con el total de coincidencias.
+	Search(ctx context.Context, f BookFilter) (Page[*Book], error)
 
 	// Update actualiza datos del libro.
 	Update(ctx context.Context, b *Book) error
@@ -82,4 +112,320 @@ type AccessLogRepository interface {
 	// StatsByBook retorna estadísticas agrupadas por tipo de acceso.
 	// Ejemplo retorno: map[AccessType]int{"LECTURA": 10, "DESCARGA": 3}
 	StatsByBook(ctx context.Context, bookID uint64) (map[AccessType]int, error)
+
+	// ReadingTime suma las sesiones de lectura registradas para el libro.
+	ReadingTime(ctx context.Context, bookID uint64) (ReadingTime, error)
+}
+
+// -------------------- AuthorRepository --------------------
+
+// AuthorRepository define el contrato para persistir autores y los créditos
+// (relación muchos a muchos, ordenada y con rol) entre libros y autores.
+type AuthorRepository interface {
+
+	// Create guarda un autor y retorna el ID generado.
+	Create(ctx context.Context, a *Author) (uint64, error)
+
+	// GetByID obtiene un autor por ID (ErrNotFound si no existe).
+	GetByID(ctx context.Context, id uint64) (*Author, error)
+
+	// GetByName busca por nombre exacto sin distinguir mayúsculas
+	// (el de menor ID si hay varios).
+	GetByName(ctx context.Context, name string) (*Author, error)
+
+	// Search retorna una página de autores que cumplen el filtro.
+	Search(ctx context.Context, f AuthorFilter) (Page[*Author], error)
+
+	// Update actualiza nombre y biografía.
+	Update(ctx context.Context, a *Author) error
+
+	// Delete elimina el autor y sus créditos.
+	Delete(ctx context.Context, id uint64) error
+
+	// SetBookCredits reemplaza los créditos del libro; Position sigue el orden de la lista.
+	SetBookCredits(ctx context.Context, bookID uint64, credits []BookCredit) error
+
+	// BookCredits retorna los créditos del libro ordenados por Position (con Name).
+	BookCredits(ctx context.Context, bookID uint64) ([]BookCredit, error)
+
+	// AuthorBooks retorna los libros del autor con su rol (por año y título).
+	AuthorBooks(ctx context.Context, authorID uint64) ([]AuthorBook, error)
+
+	// Merge pasa los créditos de dupID a keepID y elimina dupID, en una sola
+	// operación. Retorna los IDs de los libros afectados.
+	Merge(ctx context.Context, keepID, dupID uint64) ([]uint64, error)
+}
+
+// -------------------- TaxonomyRepository --------------------
+
+// TaxonomyRepository define el contrato para etiquetas y el árbol de
+// categorías. Los libros guardan el nombre canónico de su categoría y de sus
+// etiquetas; renombrar, fusionar o borrar los actualiza y retorna los IDs
+// de los libros afectados.
+type TaxonomyRepository interface {
+
+	// ListTags retorna una página de etiquetas con la cantidad de libros de cada una.
+	ListTags(ctx context.Context, f TagFilter) (Page[TagUsage], error)
+
+	// GetTag obtiene una etiqueta por ID (ErrNotFound si no existe).
+	GetTag(ctx context.Context, id uint64) (*Tag, error)
+
+	// RenameTag guarda nombre y slug (ErrDuplicate si el slug es de otra etiqueta).
+	RenameTag(ctx context.Context, t *Tag) ([]uint64, error)
+
+	// MergeTags pasa los libros de dupID a keepID y elimina dupID.
+	MergeTags(ctx context.Context, keepID, dupID uint64) ([]uint64, error)
+
+	// DeleteTag elimina la etiqueta y la quita de sus libros.
+	DeleteTag(ctx context.Context, id uint64) ([]uint64, error)
+
+	// Categories retorna todas las categorías con sus libros directos.
+	Categories(ctx context.Context) ([]CategoryUsage, error)
+
+	// GetCategory obtiene una categoría por ID (ErrNotFound si no existe).
+	GetCategory(ctx context.Context, id uint64) (*Category, error)
+
+	// CreateCategory guarda una categoría (ErrDuplicate si el slug ya existe).
+	CreateCategory(ctx context.Context, c *Category) (uint64, error)
+
+	// UpdateCategory guarda nombre y padre y renombra la categoría en sus libros.
+	UpdateCategory(ctx context.Context, c *Category) ([]uint64, error)
+
+	// MergeCategories pasa libros y subcategorías de dupID a keepID y elimina dupID.
+	MergeCategories(ctx context.Context, keepID, dupID uint64) ([]uint64, error)
+
+	// DeleteCategory elimina una categoría sin libros ni subcategorías.
+	DeleteCategory(ctx context.Context, id uint64) error
+}
+
+// -------------------- CirculationRepository --------------------
+
+// CirculationRepository define el contrato para ejemplares físicos, préstamos
+// y reservas. Cada vez que un ejemplar queda disponible (alta, cambio de
+// estado, devolución, reserva cancelada o vencida) se aparta para la reserva
+// más antigua en espera de su libro.
+type CirculationRepository interface {
+
+	// CreateCopy guarda un ejemplar (ErrDuplicate si el código de barras existe).
+	CreateCopy(ctx context.Context, c *Copy) (uint64, error)
+
+	// GetCopy obtiene un ejemplar por ID (ErrNotFound si no existe).
+	GetCopy(ctx context.Context, id uint64) (*Copy, error)
+
+	// GetCopyByBarcode obtiene un ejemplar por código de barras normalizado.
+	GetCopyByBarcode(ctx context.Context, barcode string) (*Copy, error)
+
+	// CopiesByBook retorna los ejemplares del libro por código de barras.
+	CopiesByBook(ctx context.Context, bookID uint64) ([]*Copy, error)
+
+	// UpdateCopy guarda código, ubicación y estado si el estado del ejemplar
+	// sigue siendo from, el que se leyó (ErrUnavailable si un préstamo, una
+	// devolución o una reserva lo cambió entretanto).
+	UpdateCopy(ctx context.Context, c *Copy, from CopyStatus) error
+
+	// DeleteCopy elimina el ejemplar (y su historial por ON DELETE CASCADE).
+	DeleteCopy(ctx context.Context, id uint64) error
+
+	// Checkout marca el ejemplar como prestado y guarda el préstamo, salvo que
+	// el ejemplar no esté disponible (ErrUnavailable) o el usuario ya tenga
+	// maxActive préstamos activos (ErrLimitReached). Un ejemplar apartado solo
+	// se presta al titular de la reserva, que queda cumplida.
+	Checkout(ctx context.Context, l *Loan, maxActive int) (uint64, error)
+
+	// GetLoan obtiene un préstamo por ID (ErrNotFound si no existe).
+	GetLoan(ctx context.Context, id uint64) (*Loan, error)
+
+	// ActiveLoan obtiene el préstamo activo del ejemplar (ErrNotFound si no hay).
+	ActiveLoan(ctx context.Context, copyID uint64) (*Loan, error)
+
+	// RenewLoan guarda vencimiento y renovaciones de un préstamo activo
+	// (ErrLimitReached si hay reservas esperando el libro).
+	RenewLoan(ctx context.Context, l *Loan) error
+
+	// ReturnLoan registra la devolución y deja el ejemplar disponible (o
+	// apartado para la próxima reserva).
+	ReturnLoan(ctx context.Context, l *Loan) error
+
+	// ListLoans retorna una página de préstamos con ejemplar, libro y usuario.
+	ListLoans(ctx context.Context, f LoanFilter) (Page[LoanDetail], error)
+
+	// PlaceHold guarda la reserva al final de la cola del libro, salvo que el
+	// usuario ya tenga una activa del libro (ErrDuplicate) o maxActive en total
+	// (ErrLimitReached). Si hay un ejemplar disponible queda lista enseguida.
+	PlaceHold(ctx context.Context, h *Hold, maxActive int) (uint64, error)
+
+	// GetHold obtiene una reserva por ID (ErrNotFound si no existe).
+	GetHold(ctx context.Context, id uint64) (*Hold, error)
+
+	// ReadyHold obtiene la reserva lista que aparta el ejemplar (ErrNotFound si no hay).
+	ReadyHold(ctx context.Context, copyID uint64) (*Hold, error)
+
+	// CancelHold cierra una reserva activa y libera su ejemplar apartado.
+	CancelHold(ctx context.Context, h *Hold) error
+
+	// ExpireHolds vence las reservas listas no retiradas antes de now, libera
+	// sus ejemplares y retorna cuántas venció.
+	ExpireHolds(ctx context.Context, now time.Time) (int, error)
+
+	// ListHolds retorna una página de reservas con su lugar en la cola.
+	ListHolds(ctx context.Context, f HoldFilter) (Page[HoldDetail], error)
+
+	// Availability resume ejemplares y cola de un libro (ErrNotFound si no existe).
+	Availability(ctx context.Context, bookID uint64) (Availability, error)
+}
+
+// -------------------- RelationRepository --------------------
+
+// RelationRepository define el contrato para las relaciones tipadas entre
+// libros. Borrar un libro borra sus relaciones.
+type RelationRepository interface {
+
+	// Create guarda la relación (ErrNotFound si falta un libro, ErrDuplicate
+	// si ya existe con el mismo origen, destino y tipo).
+	Create(ctx context.Context, r *BookRelation) (uint64, error)
+
+	// GetByID obtiene una relación por ID (ErrNotFound si no existe).
+	GetByID(ctx context.Context, id uint64) (*BookRelation, error)
+
+	// Delete elimina la relación.
+	Delete(ctx context.Context, id uint64) error
+
+	// ByType retorna todas las relaciones del tipo (para validar ciclos).
+	ByType(ctx context.Context, t RelationType) ([]*BookRelation, error)
+
+	// ByBook retorna las relaciones que se ven desde el libro: las que salen
+	// de él y las bidireccionales que llegan a él (por tipo y título).
+	ByBook(ctx context.Context, bookID uint64) ([]RelatedBook, error)
+}
+
+// -------------------- FileRepository --------------------
+
+// FileRepository define el contrato para los metadatos de los adjuntos de un
+// libro. El contenido se guarda aparte (BlobStore), indexado por su SHA-256;
+// borrar un libro borra sus adjuntos.
+type FileRepository interface {
+
+	// Create guarda el adjunto (ErrNotFound si falta el libro, ErrDuplicate
+	// si el libro ya tiene un archivo con el mismo contenido).
+	Create(ctx context.Context, f *BookFile) (uint64, error)
+
+	// GetByID obtiene un adjunto por ID (ErrNotFound si no existe).
+	GetByID(ctx context.Context, id uint64) (*BookFile, error)
+
+	// ByBook retorna los adjuntos del libro (por tipo y nombre).
+	ByBook(ctx context.Context, bookID uint64) ([]*BookFile, error)
+
+	// CountBySHA cuenta los adjuntos (de cualquier libro) con ese contenido.
+	CountBySHA(ctx context.Context, sha256 string) (int, error)
+
+	// Delete elimina el adjunto (el blob lo borra quien lo llame si ya
+	// nadie lo usa).
+	Delete(ctx context.Context, id uint64) error
+}
+
+// -------------------- CoverRepository --------------------
+
+// CoverRepository define el contrato para las portadas (una por libro). La
+// imagen se guarda aparte (BlobStore); borrar un libro borra su portada.
+type CoverRepository interface {
+
+	// Get obtiene la portada del libro (ErrNotFound si no tiene).
+	Get(ctx context.Context, bookID uint64) (*Cover, error)
+
+	// ByBooks retorna las portadas de los libros dados que tengan una
+	// (para pintar listados con una sola consulta).
+	ByBooks(ctx context.Context, bookIDs []uint64) (map[uint64]*Cover, error)
+
+	// Put crea o reemplaza la portada (ErrNotFound si falta el libro).
+	Put(ctx context.Context, c *Cover) error
+
+	// CountBySHA cuenta las portadas (de cualquier libro) con esa imagen.
+	CountBySHA(ctx context.Context, sha256 string) (int, error)
+
+	// Delete quita la portada del libro (ErrNotFound si no tenía).
+	Delete(ctx context.Context, bookID uint64) error
+}
+
+// -------------------- ProgressRepository --------------------
+
+// ProgressRepository define el contrato para el progreso de lectura (uno
+// por usuario y libro). Borrar el libro o el usuario borra sus progresos.
+type ProgressRepository interface {
+
+	// Save crea o actualiza el progreso del usuario en el libro: conserva
+	// la fecha de inicio, fija last_read_at y guarda finished_at tal como
+	// viene (ErrNotFound si falta el usuario o el libro).
+	Save(ctx context.Context, p *ReadingProgress) error
+
+	// Get obtiene el progreso del usuario en el libro (ErrNotFound si no hay).
+	Get(ctx context.Context, userID, bookID uint64) (*ReadingProgress, error)
+
+	// Delete borra el progreso del usuario en el libro (ErrNotFound si no hay).
+	Delete(ctx context.Context, userID, bookID uint64) error
+
+	// List retorna progresos paginados con título del libro y nombre del lector.
+	List(ctx context.Context, f ProgressFilter) (Page[ProgressDetail], error)
+
+	// Completion resume lectores, terminados y avance promedio por libro
+	// (solo libros con al menos un lector).
+	Completion(ctx context.Context, f CompletionFilter) (Page[BookCompletion], error)
+}
+
+// -------------------- AnnotationRepository --------------------
+
+// AnnotationRepository define el contrato para las anotaciones (subrayados,
+// marcadores y notas). Borrar el libro o el usuario borra sus anotaciones.
+type AnnotationRepository interface {
+
+	// Create guarda la anotación y retorna su ID (ErrNotFound si falta el
+	// usuario o el libro).
+	Create(ctx context.Context, a *Annotation) (uint64, error)
+
+	// GetByID obtiene la anotación (ErrNotFound si no existe).
+	GetByID(ctx context.Context, id uint64) (*Annotation, error)
+
+	// Update guarda el contenido editado y la fecha de edición (ErrNotFound
+	// si no existe).
+	Update(ctx context.Context, a *Annotation) error
+
+	// Delete elimina la anotación (ErrNotFound si no existe).
+	Delete(ctx context.Context, id uint64) error
+
+	// List retorna anotaciones paginadas con título del libro y nombre del autor.
+	List(ctx context.Context, f AnnotationFilter) (Page[AnnotationDetail], error)
+}
+
+// -------------------- ReviewRepository --------------------
+
+// ReviewRepository define el contrato para las reseñas y su historial.
+// Borrar el libro o el usuario borra sus reseñas.
+type ReviewRepository interface {
+
+	// Create guarda la reseña y retorna su ID (ErrNotFound si falta el
+	// usuario o el libro, ErrDuplicate si el usuario ya reseñó el libro).
+	Create(ctx context.Context, r *Review) (uint64, error)
+
+	// GetByID obtiene la reseña (ErrNotFound si no existe).
+	GetByID(ctx context.Context, id uint64) (*Review, error)
+
+	// GetByUserBook obtiene la reseña del usuario sobre el libro (ErrNotFound si no hay).
+	GetByUserBook(ctx context.Context, userID, bookID uint64) (*Review, error)
+
+	// Update guarda estrellas, texto y estado; si prev no es nil lo agrega
+	// al historial en la misma transacción (ErrNotFound si no existe).
+	Update(ctx context.Context, r *Review, prev *ReviewRevision) error
+
+	// Delete elimina la reseña y su historial (ErrNotFound si no existe).
+	Delete(ctx context.Context, id uint64) error
+
+	// List retorna reseñas paginadas con título del libro y nombre del autor.
+	List(ctx context.Context, f ReviewFilter) (Page[ReviewDetail], error)
+
+	// History retorna las versiones anteriores de la reseña, de la más
+	// antigua a la más reciente.
+	History(ctx context.Context, reviewID uint64) ([]ReviewRevision, error)
+
+	// Summaries resume las reseñas aprobadas de cada libro pedido (los
+	// libros sin reseñas aprobadas no aparecen en el mapa).
+	Summaries(ctx context.Context, bookIDs []uint64) (map[uint64]RatingSummary, error)
 }
@@ -0,0 +1,226 @@
+package domain // Dominio: reseñas de los lectores y valoración de los libros
+
+import (
+	"fmt"          // Errores con contexto
+	"strings"      // Normalización de textos y valores
+	"time"         // Fechas de alta, edición y moderación
+	"unicode/utf8" // Largos en caracteres
+)
+
+// Rango de estrellas y largo máximo (en caracteres) del texto de una reseña.
+const (
+	MinReviewRating  = 1
+	MaxReviewRating  = 5
+	MaxReviewTextLen = 5000
+)
+
+// -------------------- ReviewStatus --------------------
+
+// ReviewStatus es el estado de moderación de una reseña.
+type ReviewStatus string
+
+const (
+	ReviewPending  ReviewStatus = "pending"  // En la cola de moderación (recién escrita o editada)
+	ReviewApproved ReviewStatus = "approved" // Visible para todos y cuenta en el promedio
+	ReviewHidden   ReviewStatus = "hidden"   // Oculta por un moderador (solo la ve su autor)
+)
+
+// ReviewStatuses son los estados aceptados, en el orden del flujo de moderación.
+var ReviewStatuses = []ReviewStatus{ReviewPending, ReviewApproved, ReviewHidden}
+
+// ParseReviewStatus normaliza el estado. Retorna ErrValidation si no es uno conocido.
+func ParseReviewStatus(s string) (ReviewStatus, error) {
+	st := ReviewStatus(strings.ToLower(strings.TrimSpace(s)))
+	for _, ok := range ReviewStatuses {
+		if st == ok {
+			return st, nil
+		}
+	}
+	return "", fmt.Errorf("%w: status must be pending, approved or hidden", ErrValidation)
+}
+
+// -------------------- ReviewRevision --------------------
+
+// ReviewRevision es una versión anterior de una reseña (historial de ediciones).
+type ReviewRevision struct {
+	Rating    int       // Estrellas de esa versión
+	Text      string    // Texto de esa versión
+	WrittenAt time.Time // Cuándo se escribió esa versión
+}
+
+// -------------------- Review --------------------
+
+// Review es la reseña de un usuario sobre un libro: estrellas, texto y estado
+// de moderación. Hay a lo sumo una por usuario y libro.
+type Review struct {
+	id          uint64       // ID único (asignado por BD)
+	userID      uint64       // Autor
+	bookID      uint64       // Libro reseñado
+	rating      int          // 1 a 5 estrellas
+	text        string       // Texto (opcional)
+	status      ReviewStatus // Estado de moderación
+	createdAt   time.Time    // Fecha de alta
+	updatedAt   time.Time    // Última edición del autor
+	moderatedAt time.Time    // Última decisión de un moderador (cero = nunca moderada)
+}
+
+// NewReview valida una reseña nueva; queda pendiente de moderación.
+func NewReview(userID, bookID uint64, rating int, text string) (*Review, error) {
+	if userID == 0 || bookID == 0 {
+		return nil, fmt.Errorf("%w: user_id and book_id are required", ErrValidation)
+	}
+	text, err := normalizeReview(rating, text)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return &Review{userID: userID, bookID: bookID, rating: rating, text: text,
+		status: ReviewPending, createdAt: now, updatedAt: now}, nil
+}
+
+// HydrateReview reconstruye una reseña desde la persistencia (sin validar).
+func HydrateReview(id, userID, bookID uint64, rating int, text string, status ReviewStatus,
+	createdAt, updatedAt, moderatedAt time.Time) *Review {
+	return &Review{id: id, userID: userID, bookID: bookID, rating: rating, text: text, status: status,
+		createdAt: createdAt, updatedAt: updatedAt, moderatedAt: moderatedAt}
+}
+
+// normalizeReview valida las estrellas y recorta el texto.
+func normalizeReview(rating int, text string) (string, error) {
+	if rating < MinReviewRating || rating > MaxReviewRating {
+		return "", fmt.Errorf("%w: rating must be between %d and %d", ErrValidation, MinReviewRating, MaxReviewRating)
+	}
+	text = strings.TrimSpace(text)
+	if utf8.RuneCountInString(text) > MaxReviewTextLen {
+		return "", fmt.Errorf("%w: review text too long (max %d characters)", ErrValidation, MaxReviewTextLen)
+	}
+	return text, nil
+}
+
+// Edit reemplaza estrellas y texto. La versión anterior se devuelve para el
+// historial (nil si no cambió nada) y la reseña vuelve a la cola de
+// moderación: lo aprobado no se puede cambiar sin que un moderador lo vea.
+func (r *Review) Edit(rating int, text string) (*ReviewRevision, error) {
+	text, err := normalizeReview(rating, text)
+	if err != nil {
+		return nil, err
+	}
+	if rating == r.rating && text == r.text {
+		return nil, nil
+	}
+	prev := &ReviewRevision{Rating: r.rating, Text: r.text, WrittenAt: r.updatedAt}
+	r.rating, r.text = rating, text
+	r.status = ReviewPending
+	r.updatedAt = time.Now()
+	return prev, nil
+}
+
+// Moderate aprueba u oculta la reseña.
+func (r *Review) Moderate(status ReviewStatus) error {
+	if status != ReviewApproved && status != ReviewHidden {
+		return fmt.Errorf("%w: a review can only be approved or hidden", ErrValidation)
+	}
+	r.status = status
+	r.moderatedAt = time.Now()
+	return nil
+}
+
+// ID devuelve el ID de la reseña
+func (r *Review) ID() uint64 { return r.id }
+
+// UserID devuelve el autor
+func (r *Review) UserID() uint64 { return r.userID }
+
+// BookID devuelve el libro reseñado
+func (r *Review) BookID() uint64 { return r.bookID }
+
+// Rating devuelve las estrellas (1 a 5)
+func (r *Review) Rating() int { return r.rating }
+
+// Text devuelve el texto ("" si solo dejó estrellas)
+func (r *Review) Text() string { return r.text }
+
+// Status devuelve el estado de moderación
+func (r *Review) Status() ReviewStatus { return r.status }
+
+// Approved indica si la reseña está publicada
+func (r *Review) Approved() bool { return r.status == ReviewApproved }
+
+// CreatedAt devuelve la fecha de alta
+func (r *Review) CreatedAt() time.Time { return r.createdAt }
+
+// UpdatedAt devuelve la última edición del autor
+func (r *Review) UpdatedAt() time.Time { return r.updatedAt }
+
+// ModeratedAt devuelve la última decisión de moderación (cero si no hubo)
+func (r *Review) ModeratedAt() time.Time { return r.moderatedAt }
+
+// ReviewDetail es una reseña con los datos que muestran los listados.
+type ReviewDetail struct {
+	Review    *Review
+	BookTitle string // Título del libro
+	UserName  string // Nombre del autor
+}
+
+// -------------------- RatingSummary --------------------
+
+// RatingSummary resume las reseñas aprobadas de un libro.
+type RatingSummary struct {
+	BookID       uint64
+	Count        int                  // Reseñas aprobadas
+	Distribution [MaxReviewRating]int // Distribution[i] = reseñas de i+1 estrellas
+}
+
+// Add suma n reseñas de rating estrellas (fuera de rango se ignoran).
+func (s *RatingSummary) Add(rating, n int) {
+	if rating < MinReviewRating || rating > MaxReviewRating || n <= 0 {
+		return
+	}
+	s.Distribution[rating-1] += n
+	s.Count += n
+}
+
+// Average devuelve el promedio de estrellas (0 sin reseñas).
+func (s RatingSummary) Average() float64 {
+	if s.Count == 0 {
+		return 0
+	}
+	sum := 0
+	for i, n := range s.Distribution {
+		sum += (i + 1) * n
+	}
+	return float64(sum) / float64(s.Count)
+}
+
+// -------------------- ReviewFilter --------------------
+
+// ReviewFilter encapsula filtros para listar reseñas.
+type ReviewFilter struct {
+	UserID   uint64         // Del autor (0 = todos)
+	BookID   uint64         // Del libro (0 = todos)
+	Statuses []ReviewStatus // Alguno de estos estados (vacío = cualquiera)
+	Rating   int            // Con exactamente estas estrellas (0 = cualquiera)
+
+	PageRequest // Página, tamaño y orden (created_at por defecto, updated_at, rating)
+}
+
+// ReviewSortFields son los campos por los que se puede ordenar reseñas.
+var ReviewSortFields = []string{SortCreatedAt, "updated_at", SortRating}
+
+// Paging normaliza la paginación (por defecto lo más reciente primero) y
+// valida el filtro.
+func (f ReviewFilter) Paging() (PageRequest, error) {
+	for _, st := range f.Statuses {
+		if got, err := ParseReviewStatus(string(st)); err != nil || got != st {
+			return PageRequest{}, fmt.Errorf("%w: status must be pending, approved or hidden", ErrValidation)
+		}
+	}
+	if f.Rating != 0 && (f.Rating < MinReviewRating || f.Rating > MaxReviewRating) {
+		return PageRequest{}, fmt.Errorf("%w: rating must be between %d and %d", ErrValidation, MinReviewRating, MaxReviewRating)
+	}
+	p := f.PageRequest
+	if p.Sort == "updated_at" && p.Dir == "" {
+		p.Dir = SortDesc
+	}
+	return p.Normalize(ReviewSortFields)
+}
@@ -0,0 +1,50 @@
+package domain // Dominio: resultados de búsqueda de texto completo
+
+// BookHit es un libro encontrado por la búsqueda de texto completo,
+// con su puntuación de relevancia y los fragmentos resaltados.
+type BookHit struct {
+	Book    *Book      // Libro encontrado
+	Score   float64    // Relevancia (mayor = más relevante; 0 si no hubo texto)
+	Title   []Fragment // Título con los términos coincidentes marcados
+	Snippet []Fragment // Extracto de la descripción alrededor de las coincidencias
+}
+
+// Fragment es un trozo de texto original; Match indica si coincide con la consulta.
+// Se entrega en trozos (y no como HTML) para que cada capa lo escape a su manera.
+type Fragment struct {
+	Text  string
+	Match bool
+}
+
+// BookHits envuelve libros sin ranking (p. ej. listados sin texto libre).
+func BookHits(list []*Book) []BookHit {
+	out := make([]BookHit, 0, len(list))
+	for _, b := range list {
+		out = append(out, BookHit{Book: b})
+	}
+	return out
+}
+
+// -------------------- Autocompletado --------------------
+
+// SuggestionKind indica qué se está completando.
+type SuggestionKind string
+
+const (
+	SuggestTitle  SuggestionKind = "title"
+	SuggestAuthor SuggestionKind = "author"
+)
+
+const (
+	DefaultSuggestions = 10  // Sugerencias por defecto
+	MaxSuggestions     = 20  // Tope de sugerencias por pedido
+	MaxSuggestPrefix   = 100 // Largo máximo del prefijo (en caracteres)
+)
+
+// Suggestion es una compleción de título o autor para un prefijo.
+type Suggestion struct {
+	Text   string         // Título o autor tal como está guardado
+	Kind   SuggestionKind // title | author
+	BookID uint64         // Libro más reciente con ese título (0 para autores)
+	Count  int            // Libros con ese título / de ese autor
+}
@@ -0,0 +1,64 @@
+package domain // Dominio: sesiones de usuario autenticado
+
+import (
+	"fmt"  // Para construir errores con contexto
+	"time" // Para expiración de la sesión
+)
+
+// Session representa una sesión iniciada por un usuario (login).
+// El token real solo lo conoce el navegador (cookie); aquí se guarda su hash.
+type Session struct {
+	tokenHash string    // SHA-256 (hex) del token de la cookie
+	userID    uint64    // Usuario dueño de la sesión
+	expiresAt time.Time // Momento en que deja de ser válida
+	createdAt time.Time // Momento del login
+}
+
+// NewSession crea una sesión válida para un usuario.
+func NewSession(tokenHash string, userID uint64, expiresAt time.Time) (*Session, error) {
+
+	// El hash y el usuario son obligatorios
+	if tokenHash == "" || userID == 0 {
+		return nil, fmt.Errorf("%w: token and user_id are required", ErrValidation)
+	}
+
+	// Una sesión que nace expirada no tiene sentido
+	if !expiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("%w: session expiration must be in the future", ErrValidation)
+	}
+
+	return &Session{
+		tokenHash: tokenHash,
+		userID:    userID,
+		expiresAt: expiresAt,
+		createdAt: time.Now(),
+	}, nil
+}
+
+// HydrateSession reconstruye una sesión desde la base de datos.
+// No valida la expiración: una sesión leída puede estar vencida.
+func HydrateSession(tokenHash string, userID uint64, expiresAt, createdAt time.Time) *Session {
+	return &Session{
+		tokenHash: tokenHash,
+		userID:    userID,
+		expiresAt: expiresAt,
+		createdAt: createdAt,
+	}
+}
+
+// -------------------- Getters --------------------
+
+// TokenHash devuelve el hash del token
+func (s *Session) TokenHash() string { return s.tokenHash }
+
+// UserID devuelve el usuario dueño
+func (s *Session) UserID() uint64 { return s.userID }
+
+// ExpiresAt devuelve la fecha de expiración
+func (s *Session) ExpiresAt() time.Time { return s.expiresAt }
+
+// CreatedAt devuelve la fecha del login
+func (s *Session) CreatedAt() time.Time { return s.createdAt }
+
+// Expired indica si la sesión ya venció en el instante now
+func (s *Session) Expired(now time.Time) bool { return !now.Before(s.expiresAt) }
@@ -0,0 +1,294 @@
+package domain // Dominio: taxonomía del catálogo (etiquetas y árbol de categorías)
+
+import (
+	"cmp"          // Orden de hijos en el árbol
+	"fmt"          // Errores con contexto
+	"slices"       // Orden del árbol
+	"strings"      // Normalización de nombres
+	"time"         // Fechas de creación/actualización
+	"unicode"      // Clasificación de caracteres del slug
+	"unicode/utf8" // Largo de nombres en caracteres (no bytes)
+
+	"golang.org/x/text/runes"        // Quitar diacríticos
+	"golang.org/x/text/transform"    // Cadena de transformaciones
+	"golang.org/x/text/unicode/norm" // Descomposición NFD
+)
+
+const (
+	MaxTagLen      = 60  // Largo máximo de una etiqueta (columna tags.name)
+	MaxCategoryLen = 120 // Largo máximo de una categoría (categories.name y books.category)
+)
+
+// -------------------- Slugs --------------------
+
+// Slugify arma la clave normalizada de una etiqueta o categoría: minúsculas,
+// sin acentos y con guiones entre palabras ("Ciencia Ficción" -> "ciencia-ficcion").
+// "+" y "#" se conservan para no confundir "C++" y "C#" con "c".
+// Dos nombres con el mismo slug son la misma etiqueta/categoría.
+func Slugify(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	if folded, _, err := transform.String(t, s); err == nil {
+		s = folded
+	}
+
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '+' || r == '#':
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteRune(r)
+		default:
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+// NormalizeTag limpia una etiqueta: espacios colapsados y minúsculas
+// ("  Ciencia   Ficción " -> "ciencia ficción").
+func NormalizeTag(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// -------------------- Tag --------------------
+
+// Tag es una etiqueta del catálogo (única por slug).
+type Tag struct {
+	id        uint64    // ID único (asignado por BD)
+	name      string    // Nombre normalizado ("ciencia ficción")
+	slug      string    // Clave única ("ciencia-ficcion")
+	createdAt time.Time // Fecha de creación
+}
+
+// NewTag crea una etiqueta válida.
+func NewTag(name string) (*Tag, error) {
+	t := &Tag{createdAt: time.Now()}
+	if err := t.SetName(name); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// HydrateTag reconstruye una etiqueta desde la persistencia (sin validar).
+func HydrateTag(id uint64, name, slug string, createdAt time.Time) *Tag {
+	return &Tag{id: id, name: name, slug: slug, createdAt: createdAt}
+}
+
+// ID devuelve el ID de la etiqueta
+func (t *Tag) ID() uint64 { return t.id }
+
+// Name devuelve el nombre normalizado
+func (t *Tag) Name() string { return t.name }
+
+// Slug devuelve la clave única
+func (t *Tag) Slug() string { return t.slug }
+
+// CreatedAt devuelve fecha creación
+func (t *Tag) CreatedAt() time.Time { return t.createdAt }
+
+// SetName normaliza, valida y asigna el nombre (recalcula el slug)
+func (t *Tag) SetName(name string) error {
+	name, err := cleanTag(name)
+	if err != nil {
+		return err
+	}
+	t.name, t.slug = name, Slugify(name)
+	return nil
+}
+
+// cleanTag normaliza una etiqueta y valida largo y slug.
+func cleanTag(name string) (string, error) {
+	name = NormalizeTag(name)
+	if Slugify(name) == "" {
+		return "", fmt.Errorf("%w: tag %q must contain letters or digits", ErrValidation, name)
+	}
+	if utf8.RuneCountInString(name) > MaxTagLen {
+		return "", fmt.Errorf("%w: tag %q too long (max %d)", ErrValidation, name, MaxTagLen)
+	}
+	return name, nil
+}
+
+// -------------------- Category --------------------
+
+// Category es un nodo del árbol de categorías (única por slug en todo el árbol,
+// así un nombre siempre identifica a una sola categoría).
+type Category struct {
+	id        uint64    // ID único (asignado por BD)
+	name      string    // Nombre para mostrar ("Ciencia ficción")
+	slug      string    // Clave única ("ciencia-ficcion")
+	parentID  uint64    // Categoría padre (0 = raíz)
+	createdAt time.Time // Fecha de creación
+	updatedAt time.Time // Fecha de última actualización
+}
+
+// NewCategory crea una categoría válida bajo parentID (0 = raíz).
+func NewCategory(name string, parentID uint64) (*Category, error) {
+	c := &Category{parentID: parentID, createdAt: time.Now()}
+	if err := c.SetName(name); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// HydrateCategory reconstruye una categoría desde la persistencia (sin validar).
+func HydrateCategory(id uint64, name, slug string, parentID uint64, createdAt, updatedAt time.Time) *Category {
+	return &Category{id: id, name: name, slug: slug, parentID: parentID, createdAt: createdAt, updatedAt: updatedAt}
+}
+
+// ID devuelve el ID de la categoría
+func (c *Category) ID() uint64 { return c.id }
+
+// Name devuelve el nombre
+func (c *Category) Name() string { return c.name }
+
+// Slug devuelve la clave única
+func (c *Category) Slug() string { return c.slug }
+
+// ParentID devuelve la categoría padre (0 = raíz)
+func (c *Category) ParentID() uint64 { return c.parentID }
+
+// CreatedAt devuelve fecha creación
+func (c *Category) CreatedAt() time.Time { return c.createdAt }
+
+// UpdatedAt devuelve fecha actualización
+func (c *Category) UpdatedAt() time.Time { return c.updatedAt }
+
+// SetName valida y asigna el nombre (recalcula el slug)
+func (c *Category) SetName(name string) error {
+	name, err := cleanCategory(name)
+	if err != nil {
+		return err
+	}
+	c.name, c.slug = name, Slugify(name)
+	c.updatedAt = time.Now()
+	return nil
+}
+
+// SetParent mueve la categoría bajo parentID (0 = raíz). Los ciclos más
+// profundos se validan con CheckCategoryParent, que conoce el árbol completo.
+func (c *Category) SetParent(parentID uint64) error {
+	if parentID != 0 && parentID == c.id {
+		return fmt.Errorf("%w: a category cannot be its own parent", ErrValidation)
+	}
+	c.parentID = parentID
+	c.updatedAt = time.Now()
+	return nil
+}
+
+// cleanCategory colapsa espacios y valida largo y slug (regla de Book.SetCategory).
+func cleanCategory(name string) (string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if len(name) < 2 || Slugify(name) == "" {
+		return "", fmt.Errorf("%w: category too short", ErrValidation)
+	}
+	if utf8.RuneCountInString(name) > MaxCategoryLen {
+		return "", fmt.Errorf("%w: category too long (max %d)", ErrValidation, MaxCategoryLen)
+	}
+	return name, nil
+}
+
+// CheckCategoryParent valida mover la categoría id bajo parentID: el padre debe
+// existir y no puede ser la propia categoría ni uno de sus descendientes.
+func CheckCategoryParent(all []*Category, id, parentID uint64) error {
+	if parentID == 0 {
+		return nil
+	}
+	parent := map[uint64]uint64{}
+	for _, c := range all {
+		parent[c.ID()] = c.ParentID()
+	}
+	if _, ok := parent[parentID]; !ok {
+		return fmt.Errorf("%w: parent category %d", ErrNotFound, parentID)
+	}
+	// Sube desde el nuevo padre hasta la raíz; si pasa por id habría un ciclo
+	for p, steps := parentID, 0; p != 0 && steps <= len(all); p, steps = parent[p], steps+1 {
+		if p == id {
+			return fmt.Errorf("%w: a category cannot be moved under itself or its subcategories", ErrValidation)
+		}
+	}
+	return nil
+}
+
+// -------------------- Uso y árbol --------------------
+
+// TagUsage es una etiqueta con la cantidad de libros que la usan.
+type TagUsage struct {
+	Tag   *Tag
+	Books int
+}
+
+// CategoryUsage es una categoría con la cantidad de libros asignados a ella
+// directamente (sin contar subcategorías).
+type CategoryUsage struct {
+	Category *Category
+	Books    int
+}
+
+// CategoryNode es un nodo del árbol de categorías con sus conteos.
+type CategoryNode struct {
+	Category   *Category
+	Books      int             // Libros asignados a esta categoría
+	TotalBooks int             // Libros de esta categoría y todas sus subcategorías
+	Children   []*CategoryNode // Subcategorías por nombre
+}
+
+// BuildCategoryTree arma el árbol (raíces y subcategorías ordenadas por nombre)
+// y suma TotalBooks hacia arriba. Una categoría cuyo padre no está en la
+// lista se trata como raíz.
+func BuildCategoryTree(list []CategoryUsage) []*CategoryNode {
+	nodes := make(map[uint64]*CategoryNode, len(list))
+	for _, u := range list {
+		nodes[u.Category.ID()] = &CategoryNode{Category: u.Category, Books: u.Books}
+	}
+
+	var roots []*CategoryNode
+	for _, u := range list {
+		n := nodes[u.Category.ID()]
+		if p, ok := nodes[u.Category.ParentID()]; ok && p != n {
+			p.Children = append(p.Children, n)
+		} else {
+			roots = append(roots, n)
+		}
+	}
+
+	var walk func(ns []*CategoryNode) int
+	walk = func(ns []*CategoryNode) int {
+		slices.SortFunc(ns, func(a, b *CategoryNode) int {
+			return cmp.Or(
+				cmp.Compare(strings.ToLower(a.Category.Name()), strings.ToLower(b.Category.Name())),
+				cmp.Compare(a.Category.ID(), b.Category.ID()),
+			)
+		})
+		sum := 0
+		for _, n := range ns {
+			n.TotalBooks = n.Books + walk(n.Children)
+			sum += n.TotalBooks
+		}
+		return sum
+	}
+	walk(roots)
+	if roots == nil {
+		roots = []*CategoryNode{}
+	}
+	return roots
+}
+
+// -------------------- TagFilter --------------------
+
+// TagFilter encapsula filtros para listar etiquetas.
+type TagFilter struct {
+	Q string // Subcadena del nombre (sin distinguir mayúsculas)
+
+	PageRequest // Página, tamaño y orden (name, books, created_at)
+}
+
+// TagSortFields son los campos por los que se puede ordenar etiquetas
+// ("books" = cantidad de libros que la usan).
+var TagSortFields = []string{SortCreatedAt, "name", "books"}
+
+// Paging normaliza la paginación del filtro de etiquetas.
+func (f TagFilter) Paging() (PageRequest, error) { return f.PageRequest.Normalize(TagSortFields) }
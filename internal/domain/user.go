@@ -4,8 +4,13 @@ import (
 	"fmt"     // Para construir errores con contexto (fmt.Errorf)
 	"strings" // Para limpiar y normalizar textos (TrimSpace, ToLower, etc.)
 	"time"    // Para timestamps (createdAt/updatedAt)
+
+	"golang.org/x/crypto/bcrypt" // Hash seguro de contraseñas
 )
 
+// MinPasswordLength es la longitud mínima aceptada para una contraseña.
+const MinPasswordLength = 8
+
 // User representa la entidad Usuario en el dominio.
 // POO + Encapsulación: los campos son privados (minúscula) y solo se accede por métodos.
 type User struct {
@@ -16,6 +21,8 @@ type User struct {
 	active    bool      // Estado lógico (activo/inactivo)
 	createdAt time.Time // Fecha creación
 	updatedAt time.Time // Fecha actualización
+
+	passwordHash string // Hash bcrypt de la contraseña (nunca el texto plano)
 }
 
 // NewUser es el constructor del dominio.
@@ -52,6 +59,7 @@ func HydrateUser(
 	name, email string,
 	role Role,
 	active bool,
+	passwordHash string,
 	createdAt, updatedAt time.Time,
 ) (*User, error) {
 
@@ -64,6 +72,7 @@ func HydrateUser(
 	// Sobrescribe datos que vienen desde BD (persistencia).
 	u.id = id
 	u.active = active
+	u.passwordHash = passwordHash
 	u.createdAt = createdAt
 	u.updatedAt = updatedAt
 
@@ -93,6 +102,12 @@ func (u *User) CreatedAt() time.Time { return u.createdAt }
 // UpdatedAt devuelve fecha actualización.
 func (u *User) UpdatedAt() time.Time { return u.updatedAt }
 
+// PasswordHash devuelve el hash almacenado (solo para persistencia).
+func (u *User) PasswordHash() string { return u.passwordHash }
+
+// HasPassword indica si el usuario tiene credenciales configuradas.
+func (u *User) HasPassword() bool { return u.passwordHash != "" }
+
 // -------------------- Setters (encapsulación + validación) --------------------
 
 // SetName valida y asigna el nombre.
@@ -168,3 +183,35 @@ func (u *User) Activate() {
 	u.active = true          // Cambia estado
 	u.updatedAt = time.Now() // Marca actualización
 }
+
+// -------------------- Credenciales --------------------
+
+// SetPassword valida la contraseña y guarda SOLO su hash bcrypt.
+// Regla: mínimo MinPasswordLength caracteres.
+func (u *User) SetPassword(plain string) error {
+
+	// Validación mínima (no se recorta: los espacios son parte de la clave)
+	if len(plain) < MinPasswordLength {
+		return fmt.Errorf("%w: password must have at least %d characters", ErrValidation, MinPasswordLength)
+	}
+
+	// bcrypt incluye sal aleatoria y costo dentro del hash
+	hash, err := bcrypt.GenerateFromPassword([]byte(plain), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrValidation, err)
+	}
+
+	u.passwordHash = string(hash)
+	u.updatedAt = time.Now()
+
+	return nil
+}
+
+// CheckPassword compara una contraseña en texto plano contra el hash guardado.
+// Un usuario sin contraseña nunca puede autenticarse.
+func (u *User) CheckPassword(plain string) bool {
+	if u.passwordHash == "" {
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(u.passwordHash), []byte(plain)) == nil
+}
@@ -0,0 +1,103 @@
+// Package blob guarda el contenido de los adjuntos (usecase.BlobStore):
+// en una carpeta local o en un bucket compatible con S3 (AWS, MinIO, ...).
+package blob
+
+import (
+	"context"       // Firma del contrato
+	"errors"        // errors.Is sobre errores del sistema de archivos
+	"fmt"           // Errores con contexto
+	"io"            // Lectura/escritura por streaming
+	"io/fs"         // fs.ErrNotExist
+	"os"            // Archivos
+	"path/filepath" // Rutas por sistema operativo
+	"strings"       // Validación de claves
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain" // Errores del dominio
+)
+
+// LocalStore guarda cada blob como un archivo bajo dir (la clave es la ruta
+// relativa, por ejemplo "ab/cd/abcd...").
+type LocalStore struct {
+	dir string
+}
+
+// NewLocalStore crea la carpeta si no existe.
+func NewLocalStore(dir string) (*LocalStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &LocalStore{dir: dir}, nil
+}
+
+// Put escribe el contenido en un temporal de la misma carpeta y lo renombra:
+// un lector nunca ve un blob a medio escribir. Si r trae otra cantidad de
+// bytes que size, no guarda nada.
+func (s *LocalStore) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op si ya se renombró
+
+	n, err := io.Copy(tmp, r)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if n != size {
+		return fmt.Errorf("blob %s: wrote %d bytes, expected %d", key, n, size)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Get abre el blob posicionado en offset (ErrNotFound si no existe).
+func (s *LocalStore) Get(ctx context.Context, key string, offset int64) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if offset > 0 {
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// Delete borra el blob (no falla si ya no existe).
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path traduce la clave a una ruta dentro de dir; rechaza claves que
+// intenten salir de la carpeta.
+func (s *LocalStore) path(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "..") || strings.Contains(key, `\`) {
+		return "", fmt.Errorf("%w: invalid blob key %q", domain.ErrValidation, key)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
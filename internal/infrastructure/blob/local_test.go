@@ -0,0 +1,69 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+func TestLocalStore(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewLocalStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	key := domain.BlobKey(strings.Repeat("ab", 32))
+
+	if err := s.Put(ctx, key, strings.NewReader("hola mundo"), 10); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "ab", "ab", strings.Repeat("ab", 32))); err != nil {
+		t.Fatalf("expected blob under its key path: %v", err)
+	}
+
+	// Lectura completa y desde un offset (rangos HTTP)
+	for offset, want := range map[int64]string{0: "hola mundo", 5: "mundo"} {
+		rc, err := s.Get(ctx, key, offset)
+		if err != nil {
+			t.Fatalf("get at %d: %v", offset, err)
+		}
+		got, _ := io.ReadAll(rc)
+		rc.Close()
+		if string(got) != want {
+			t.Fatalf("get at %d = %q, want %q", offset, got, want)
+		}
+	}
+
+	// Un tamaño que no coincide no deja nada a medias
+	other := domain.BlobKey(strings.Repeat("cd", 32))
+	if err := s.Put(ctx, other, strings.NewReader("corto"), 99); err == nil {
+		t.Fatal("expected size mismatch error")
+	}
+	if _, err := s.Get(ctx, other, 0); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after failed put, got %v", err)
+	}
+	if leftovers, _ := filepath.Glob(filepath.Join(dir, "cd", "cd", ".upload-*")); len(leftovers) != 0 {
+		t.Fatalf("temporary files left behind: %v", leftovers)
+	}
+
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := s.Get(ctx, key, 0); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatalf("delete twice: %v", err)
+	}
+
+	if err := s.Put(ctx, "../fuera", strings.NewReader("x"), 1); !errors.Is(err, domain.ErrValidation) {
+		t.Fatalf("expected ErrValidation for a key outside the dir, got %v", err)
+	}
+}
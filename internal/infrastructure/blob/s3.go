@@ -0,0 +1,210 @@
+package blob
+
+import (
+	"context"       // Cancelación de las peticiones
+	"crypto/hmac"   // Firma SigV4
+	"crypto/sha256" // Hash de la petición canónica
+	"encoding/hex"  // Hashes y firmas en hex
+	"errors"        // errors.Is
+	"fmt"           // Errores con contexto
+	"io"            // Streaming del contenido
+	"net/http"      // Cliente HTTP
+	"net/url"       // Endpoint del servicio
+	"sort"          // Orden de las cabeceras firmadas
+	"strconv"       // Cabecera Range
+	"strings"       // Armado de la petición canónica
+	"time"          // Fecha de la firma
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain" // Errores del dominio
+)
+
+// S3Config son los datos de conexión a un servicio compatible con S3.
+type S3Config struct {
+	Endpoint  string // URL base, por ejemplo https://s3.us-east-1.amazonaws.com o http://localhost:9000 (MinIO)
+	Bucket    string // Bucket (debe existir)
+	Region    string // Región de la firma (MinIO acepta us-east-1)
+	AccessKey string
+	SecretKey string
+}
+
+// S3Store guarda los blobs como objetos de un bucket S3. Usa URLs de estilo
+// ruta (endpoint/bucket/clave), que aceptan AWS, MinIO y demás compatibles,
+// y firma cada petición con AWS Signature V4 sin depender del SDK.
+type S3Store struct {
+	cfg      S3Config
+	endpoint *url.URL
+	client   *http.Client
+}
+
+// NewS3Store valida la configuración. client puede ser nil (http.DefaultClient).
+func NewS3Store(cfg S3Config, client *http.Client) (*S3Store, error) {
+	u, err := url.Parse(strings.TrimRight(cfg.Endpoint, "/"))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", cfg.Endpoint)
+	}
+	if cfg.Bucket == "" || cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, fmt.Errorf("S3 bucket and credentials are required")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &S3Store{cfg: cfg, endpoint: u, client: client}, nil
+}
+
+// Put sube el objeto por streaming (el cuerpo no se firma: UNSIGNED-PAYLOAD,
+// así no hay que leerlo dos veces).
+func (s *S3Store) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	req, err := s.request(ctx, http.MethodPut, key, r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	res, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
+// Get descarga el objeto desde offset (con Range si offset > 0).
+func (s *S3Store) Get(ctx context.Context, key string, offset int64) (io.ReadCloser, error) {
+	req, err := s.request(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+	}
+	res, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+// Delete borra el objeto (S3 responde 204 aunque no exista).
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	req, err := s.request(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	res, err := s.do(req)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return err
+	}
+	if res != nil {
+		res.Body.Close()
+	}
+	return nil
+}
+
+// request arma la petición al objeto key del bucket.
+func (s *S3Store) request(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "..") {
+		return nil, fmt.Errorf("%w: invalid blob key %q", domain.ErrValidation, key)
+	}
+	u := *s.endpoint
+	u.Path = strings.TrimRight(u.Path, "/") + "/" + s.cfg.Bucket + "/" + key
+	u.RawPath = ""
+	return http.NewRequestWithContext(ctx, method, u.String(), body)
+}
+
+// do firma y envía la petición; traduce 404 a ErrNotFound y cualquier otro
+// estado fuera de 2xx a un error con el código que devolvió el servicio.
+func (s *S3Store) do(req *http.Request) (*http.Response, error) {
+	signV4(req, s.cfg, "UNSIGNED-PAYLOAD", time.Now())
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return res, nil
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return nil, domain.ErrNotFound
+	}
+	msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+	return nil, fmt.Errorf("s3 %s %s: %s %s", req.Method, req.URL.Path, res.Status, strings.TrimSpace(string(msg)))
+}
+
+// signV4 agrega las cabeceras x-amz-* y Authorization (AWS Signature V4).
+// Se firman host, range (si está) y las cabeceras x-amz-*.
+func signV4(req *http.Request, cfg S3Config, payloadHash string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	// Cabeceras canónicas: nombre en minúsculas, ordenadas, valor sin espacios extremos
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "range" || strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonHeaders strings.Builder
+	for _, name := range names {
+		canonHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signed := strings.Join(names, ";")
+
+	canonical := strings.Join([]string{
+		req.Method,
+		awsEscapePath(req.URL.Path),
+		req.URL.Query().Encode(), // sin parámetros en este cliente
+		canonHeaders.String(),
+		signed,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + cfg.Region + "/s3/aws4_request"
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hexSHA256(canonical)
+
+	key := hmacSHA256([]byte("AWS4"+cfg.SecretKey), day)
+	key = hmacSHA256(key, cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+cfg.AccessKey+"/"+scope+
+		", SignedHeaders="+signed+", Signature="+signature)
+}
+
+// awsEscapePath codifica cada segmento como exige SigV4 (RFC 3986: solo
+// letras, dígitos y -._~ quedan sin codificar; "/" separa segmentos).
+func awsEscapePath(path string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if c == '/' || c == '-' || c == '_' || c == '.' || c == '~' ||
+			'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	m := hmac.New(sha256.New, key)
+	m.Write([]byte(data))
+	return m.Sum(nil)
+}
+
+func hexSHA256(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
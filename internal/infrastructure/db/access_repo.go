@@ -0,0 +1,87 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+// SQLAccessRepo implementa usecase.AccessRepo sobre cualquier motor soportado.
+type SQLAccessRepo struct {
+	db conn // conexión + dialecto del motor
+}
+
+func NewMySQLAccessRepo(db *sql.DB) *SQLAccessRepo { return &SQLAccessRepo{db: conn{db, mysqlDialect}} }
+func NewSQLiteAccessRepo(db *sql.DB) *SQLAccessRepo {
+	return &SQLAccessRepo{db: conn{db, sqliteDialect}}
+}
+
+func (r *SQLAccessRepo) Create(ctx context.Context, e *domain.AccessEvent) (uint64, error) {
+	return r.db.insert(
+		ctx,
+		`INSERT INTO access_events (user_id, book_id, access_type, duration_seconds) VALUES (?,?,?,?)`,
+		e.UserID(), e.BookID(), string(e.AccessType()), int64(e.Duration()/time.Second),
+	)
+}
+
+func (r *SQLAccessRepo) StatsByBook(ctx context.Context, bookID uint64) (map[domain.AccessType]int, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT access_type, COUNT(*)
+         FROM access_events
+         WHERE book_id=?
+         GROUP BY access_type`,
+		bookID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Siempre devolvemos las 3 llaves, aunque no existan filas aún
+	stats := map[domain.AccessType]int{
+		domain.AccessApertura: 0,
+		domain.AccessLectura:  0,
+		domain.AccessDescarga: 0,
+	}
+
+	for rows.Next() {
+		var t string
+		var c int
+		if err := rows.Scan(&t, &c); err != nil {
+			return nil, err
+		}
+
+		// Normaliza: solo contamos los tipos válidos
+		switch domain.AccessType(t) {
+		case domain.AccessApertura, domain.AccessLectura, domain.AccessDescarga:
+			stats[domain.AccessType(t)] = c
+		default:
+			// si en BD hay un tipo raro, lo ignoramos para no romper la UI
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// ReadingTime suma las sesiones LECTURA del libro y sus segundos.
+func (r *SQLAccessRepo) ReadingTime(ctx context.Context, bookID uint64) (domain.ReadingTime, error) {
+	var sessions, seconds int64
+	err := r.db.QueryRowContext(
+		ctx,
+		`SELECT COUNT(*), COALESCE(SUM(duration_seconds), 0)
+         FROM access_events
+         WHERE book_id=? AND access_type=?`,
+		bookID, string(domain.AccessLectura),
+	).Scan(&sessions, &seconds)
+	if err != nil {
+		return domain.ReadingTime{}, err
+	}
+	return domain.ReadingTime{Sessions: int(sessions), Total: time.Duration(seconds) * time.Second}, nil
+}
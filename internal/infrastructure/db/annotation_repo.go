@@ -0,0 +1,161 @@
+package db // Infraestructura DB: anotaciones de los usuarios sobre los libros
+
+import (
+	"context"      // Para timeouts/cancelación
+	"database/sql" // Driver SQL estándar
+	"errors"       // Para comparar errores (errors.Is)
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain" // Dominio (Annotation + errores)
+)
+
+// SQLAnnotationRepo persiste subrayados, marcadores y notas (tabla annotations).
+type SQLAnnotationRepo struct {
+	db conn // conexión + dialecto del motor
+}
+
+// NewMySQLAnnotationRepo inyecta la conexión (MySQL).
+func NewMySQLAnnotationRepo(db *sql.DB) *SQLAnnotationRepo {
+	return &SQLAnnotationRepo{db: conn{db, mysqlDialect}}
+}
+
+// NewSQLiteAnnotationRepo inyecta la conexión (SQLite).
+func NewSQLiteAnnotationRepo(db *sql.DB) *SQLAnnotationRepo {
+	return &SQLAnnotationRepo{db: conn{db, sqliteDialect}}
+}
+
+const annotationColumns = `a.id,a.user_id,a.book_id,a.location,a.quote,a.note,a.color,a.visibility,a.created_at,a.updated_at`
+
+// annotationSortColumns traduce los campos de orden públicos a expresiones SQL.
+var annotationSortColumns = map[string]string{
+	domain.SortCreatedAt: "id",
+	"updated_at":         "updated_at",
+	"location":           "location",
+}
+
+// Create verifica que existan el usuario y el libro e inserta la anotación.
+func (r *SQLAnnotationRepo) Create(ctx context.Context, a *domain.Annotation) (uint64, error) {
+	var id uint64
+	err := r.db.inTx(ctx, func(tx txConn) error {
+		if err := tx.mustExist(ctx, "users", a.UserID()); err != nil {
+			return err
+		}
+		if err := tx.mustExist(ctx, "books", a.BookID()); err != nil {
+			return err
+		}
+		var err error
+		id, err = tx.insert(ctx,
+			`INSERT INTO annotations (user_id,book_id,location,quote,note,color,visibility,created_at,updated_at)
+			 VALUES (?,?,?,?,?,?,?,?,?)`,
+			a.UserID(), a.BookID(), a.Location(), a.Quote(), a.Note(), string(a.Color()), string(a.Visibility()),
+			a.CreatedAt().UTC(), a.UpdatedAt().UTC())
+		return err
+	})
+	return id, err
+}
+
+// GetByID busca una anotación por ID.
+func (r *SQLAnnotationRepo) GetByID(ctx context.Context, id uint64) (*domain.Annotation, error) {
+	return scanAnnotation(r.db.QueryRowContext(ctx, `SELECT `+annotationColumns+` FROM annotations a WHERE a.id=?`, id))
+}
+
+// Update guarda el contenido y la fecha de edición.
+func (r *SQLAnnotationRepo) Update(ctx context.Context, a *domain.Annotation) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE annotations SET location=?,quote=?,note=?,color=?,visibility=?,updated_at=? WHERE id=?`,
+		a.Location(), a.Quote(), a.Note(), string(a.Color()), string(a.Visibility()), a.UpdatedAt().UTC(), a.ID())
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// Delete elimina la anotación.
+func (r *SQLAnnotationRepo) Delete(ctx context.Context, id uint64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM annotations WHERE id=?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// List retorna anotaciones paginadas con título del libro y nombre del autor.
+func (r *SQLAnnotationRepo) List(ctx context.Context, f domain.AnnotationFilter) (domain.Page[domain.AnnotationDetail], error) {
+	pg, err := f.Paging()
+	if err != nil {
+		return domain.Page[domain.AnnotationDetail]{}, err
+	}
+
+	cond := "1=1"
+	args := []any{}
+	if f.UserID != 0 {
+		cond += " AND a.user_id=?"
+		args = append(args, f.UserID)
+	}
+	if f.BookID != 0 {
+		cond += " AND a.book_id=?"
+		args = append(args, f.BookID)
+	}
+	if len(f.Visibilities) > 0 {
+		cond += " AND a.visibility IN (" + placeholders(len(f.Visibilities)) + ")"
+		for _, v := range f.Visibilities {
+			args = append(args, string(v))
+		}
+	}
+	if f.Color != "" {
+		cond += " AND a.color=?"
+		args = append(args, string(f.Color))
+	}
+
+	page := domain.Page[domain.AnnotationDetail]{Page: pg.Page, PageSize: pg.PageSize}
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM annotations a WHERE `+cond, args...).Scan(&page.Total); err != nil {
+		return page, err
+	}
+
+	// La tabla derivada evita que el "id" de desempate de orderBy sea ambiguo
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+annotationColumns+`,a.title,a.user_name FROM (
+		   SELECT a.*,b.title,u.name AS user_name
+		   FROM annotations a JOIN books b ON b.id=a.book_id JOIN users u ON u.id=a.user_id
+		 ) a WHERE `+cond+` ORDER BY `+orderBy(annotationSortColumns, pg)+` LIMIT ? OFFSET ?`,
+		append(args, pg.PageSize, pg.Offset())...)
+	if err != nil {
+		return page, err
+	}
+	defer rows.Close()
+
+	page.Items = []domain.AnnotationDetail{}
+	for rows.Next() {
+		var d domain.AnnotationDetail
+		a, err := scanAnnotation(withExtra(rows, &d.BookTitle, &d.UserName))
+		if err != nil {
+			return page, err
+		}
+		d.Annotation = a
+		page.Items = append(page.Items, d)
+	}
+	return page, rows.Err()
+}
+
+// scanAnnotation convierte una fila (annotationColumns) en entidad.
+func scanAnnotation(row interface{ Scan(dest ...any) error }) (*domain.Annotation, error) {
+	var (
+		id, userID, bookID   uint64
+		c                    domain.AnnotationContent
+		color, visibility    string
+		createdAt, updatedAt dbTime
+	)
+	if err := row.Scan(&id, &userID, &bookID, &c.Location, &c.Quote, &c.Note, &color, &visibility, &createdAt, &updatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	c.Color, c.Visibility = domain.AnnotationColor(color), domain.AnnotationVisibility(visibility)
+	return domain.HydrateAnnotation(id, userID, bookID, c, createdAt.Time, updatedAt.Time), nil
+}
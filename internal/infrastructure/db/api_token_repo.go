@@ -0,0 +1,106 @@
+package db // Infraestructura DB: tokens personales de API
+
+import (
+	"context"      // Para timeouts/cancelación
+	"database/sql" // Driver SQL estándar
+	"errors"       // Para comparar errores (errors.Is)
+	"time"         // Para expiración / último uso
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain" // Dominio (APIToken + errores)
+)
+
+// SQLAPITokenRepo persiste tokens en la tabla api_tokens.
+type SQLAPITokenRepo struct {
+	db conn // conexión + dialecto del motor
+}
+
+// NewMySQLAPITokenRepo inyecta la conexión (MySQL).
+func NewMySQLAPITokenRepo(db *sql.DB) *SQLAPITokenRepo {
+	return &SQLAPITokenRepo{db: conn{db, mysqlDialect}}
+}
+
+// NewSQLiteAPITokenRepo inyecta la conexión (SQLite).
+func NewSQLiteAPITokenRepo(db *sql.DB) *SQLAPITokenRepo {
+	return &SQLAPITokenRepo{db: conn{db, sqliteDialect}}
+}
+
+const apiTokenColumns = `id,user_id,name,token_hash,prefix,scopes,expires_at,last_used_at,revoked_at,created_at`
+
+// Create inserta un token y retorna su ID.
+func (r *SQLAPITokenRepo) Create(ctx context.Context, t *domain.APIToken) (uint64, error) {
+	return r.db.insert(
+		ctx,
+		`INSERT INTO api_tokens (user_id,name,token_hash,prefix,scopes,expires_at) VALUES (?,?,?,?,?,?)`,
+		t.UserID(),
+		t.Name(),
+		t.TokenHash(),
+		t.Prefix(),
+		t.ScopesCSV(),
+		nullableTime(t.ExpiresAt()), // NULL => no expira
+	)
+}
+
+// GetByID busca un token por ID.
+func (r *SQLAPITokenRepo) GetByID(ctx context.Context, id uint64) (*domain.APIToken, error) {
+	return scanAPIToken(r.db.QueryRowContext(ctx, `SELECT `+apiTokenColumns+` FROM api_tokens WHERE id=?`, id))
+}
+
+// GetByHash busca un token por el hash del valor secreto.
+func (r *SQLAPITokenRepo) GetByHash(ctx context.Context, tokenHash string) (*domain.APIToken, error) {
+	return scanAPIToken(r.db.QueryRowContext(ctx, `SELECT `+apiTokenColumns+` FROM api_tokens WHERE token_hash=?`, tokenHash))
+}
+
+// ListByUser lista los tokens de un usuario (más recientes primero).
+func (r *SQLAPITokenRepo) ListByUser(ctx context.Context, userID uint64) ([]*domain.APIToken, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+apiTokenColumns+` FROM api_tokens WHERE user_id=? ORDER BY id DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []*domain.APIToken{}
+	for rows.Next() {
+		t, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// Revoke marca el token como revocado.
+func (r *SQLAPITokenRepo) Revoke(ctx context.Context, id uint64, at time.Time) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE api_tokens SET revoked_at=? WHERE id=? AND revoked_at IS NULL`, at.UTC(), id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// TouchLastUsed registra el último uso del token.
+func (r *SQLAPITokenRepo) TouchLastUsed(ctx context.Context, id uint64, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE api_tokens SET last_used_at=? WHERE id=?`, at.UTC(), id)
+	return err
+}
+
+// scanAPIToken convierte una fila en entidad (sirve para *sql.Row y *sql.Rows).
+func scanAPIToken(row interface{ Scan(dest ...any) error }) (*domain.APIToken, error) {
+	var (
+		id, userID                       uint64
+		name, hash, prefix, scopes       string
+		expiresAt, lastUsedAt, revokedAt dbTime // NULL => tiempo cero
+		createdAt                        dbTime
+	)
+	if err := row.Scan(&id, &userID, &name, &hash, &prefix, &scopes, &expiresAt, &lastUsedAt, &revokedAt, &createdAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return domain.HydrateAPIToken(id, userID, name, hash, prefix, scopes,
+		expiresAt.Time, lastUsedAt.Time, revokedAt.Time, createdAt.Time), nil
+}
@@ -0,0 +1,291 @@
+package db // Infraestructura DB: autores y créditos libro-autor
+
+import (
+	"context"      // Para timeouts/cancelación
+	"database/sql" // Driver SQL estándar
+	"errors"       // Para comparar errores (errors.Is)
+	"strings"      // Filtro por nombre
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain" // Dominio (Author, BookCredit + errores)
+)
+
+// SQLAuthorRepo persiste autores (tabla authors) y créditos (tabla book_authors).
+type SQLAuthorRepo struct {
+	db conn // conexión + dialecto del motor
+}
+
+// NewMySQLAuthorRepo inyecta la conexión (MySQL).
+func NewMySQLAuthorRepo(db *sql.DB) *SQLAuthorRepo {
+	return &SQLAuthorRepo{db: conn{db, mysqlDialect}}
+}
+
+// NewSQLiteAuthorRepo inyecta la conexión (SQLite).
+func NewSQLiteAuthorRepo(db *sql.DB) *SQLAuthorRepo {
+	return &SQLAuthorRepo{db: conn{db, sqliteDialect}}
+}
+
+const authorColumns = `id,name,COALESCE(bio,''),created_at,COALESCE(updated_at,created_at)`
+
+// authorSortColumns traduce los campos de orden públicos a expresiones SQL.
+var authorSortColumns = map[string]string{
+	domain.SortCreatedAt: "id",
+	"name":               "LOWER(name)",
+}
+
+// Create inserta un autor y retorna su ID.
+func (r *SQLAuthorRepo) Create(ctx context.Context, a *domain.Author) (uint64, error) {
+	return r.db.insert(ctx, `INSERT INTO authors (name,bio) VALUES (?,?)`, a.Name(), a.Bio())
+}
+
+// GetByID busca un autor por ID.
+func (r *SQLAuthorRepo) GetByID(ctx context.Context, id uint64) (*domain.Author, error) {
+	return scanAuthor(r.db.QueryRowContext(ctx, `SELECT `+authorColumns+` FROM authors WHERE id=?`, id))
+}
+
+// GetByName busca por nombre exacto sin distinguir mayúsculas (el más antiguo).
+func (r *SQLAuthorRepo) GetByName(ctx context.Context, name string) (*domain.Author, error) {
+	name = strings.ToLower(strings.Join(strings.Fields(name), " "))
+	return scanAuthor(r.db.QueryRowContext(ctx,
+		`SELECT `+authorColumns+` FROM authors WHERE LOWER(name)=? ORDER BY id LIMIT 1`, name))
+}
+
+// Search retorna la página de autores cuyo nombre contiene f.Q.
+func (r *SQLAuthorRepo) Search(ctx context.Context, f domain.AuthorFilter) (domain.Page[*domain.Author], error) {
+	p, err := f.Paging()
+	if err != nil {
+		return domain.Page[*domain.Author]{}, err
+	}
+
+	cond := "1=1"
+	args := []any{}
+	if q := strings.TrimSpace(f.Q); q != "" {
+		cond = r.db.d.like("name")
+		args = append(args, "%"+strings.ToLower(q)+"%")
+	}
+
+	page := domain.Page[*domain.Author]{Page: p.Page, PageSize: p.PageSize}
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM authors WHERE `+cond, args...).Scan(&page.Total); err != nil {
+		return page, err
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+authorColumns+` FROM authors WHERE `+cond+` ORDER BY `+orderBy(authorSortColumns, p)+` LIMIT ? OFFSET ?`,
+		append(args, p.PageSize, p.Offset())...)
+	if err != nil {
+		return page, err
+	}
+	defer rows.Close()
+
+	page.Items = []*domain.Author{}
+	for rows.Next() {
+		a, err := scanAuthor(rows)
+		if err != nil {
+			return page, err
+		}
+		page.Items = append(page.Items, a)
+	}
+	return page, rows.Err()
+}
+
+// Update actualiza nombre y biografía.
+func (r *SQLAuthorRepo) Update(ctx context.Context, a *domain.Author) error {
+	// updated_at explícito: SQLite y PostgreSQL no tienen ON UPDATE CURRENT_TIMESTAMP
+	res, err := r.db.exec(ctx, `UPDATE authors SET name=?,bio=?,updated_at=CURRENT_TIMESTAMP WHERE id=?`, a.Name(), a.Bio(), a.ID())
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// Delete elimina el autor; sus créditos caen por ON DELETE CASCADE.
+func (r *SQLAuthorRepo) Delete(ctx context.Context, id uint64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM authors WHERE id=?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// SetBookCredits reemplaza los créditos del libro en una transacción.
+func (r *SQLAuthorRepo) SetBookCredits(ctx context.Context, bookID uint64, credits []domain.BookCredit) error {
+	return r.db.inTx(ctx, func(tx txConn) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM book_authors WHERE book_id=?`, bookID); err != nil {
+			return err
+		}
+		for i, c := range credits {
+			if _, err := tx.exec(ctx,
+				`INSERT INTO book_authors (book_id,author_id,role,position) VALUES (?,?,?,?)`,
+				bookID, c.AuthorID, string(c.Role), i,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// BookCredits retorna los créditos del libro en orden, con el nombre del autor.
+func (r *SQLAuthorRepo) BookCredits(ctx context.Context, bookID uint64) ([]domain.BookCredit, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT ba.author_id,a.name,ba.role,ba.position
+		 FROM book_authors ba JOIN authors a ON a.id=ba.author_id
+		 WHERE ba.book_id=? ORDER BY ba.position, ba.author_id`, bookID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []domain.BookCredit{}
+	for rows.Next() {
+		var (
+			c    domain.BookCredit
+			role string
+		)
+		if err := rows.Scan(&c.AuthorID, &c.Name, &role, &c.Position); err != nil {
+			return nil, err
+		}
+		c.Role = domain.CreditRole(role)
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// AuthorBooks retorna los libros del autor con su rol, por año y título.
+func (r *SQLAuthorRepo) AuthorBooks(ctx context.Context, authorID uint64) ([]domain.AuthorBook, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+bookColumns+`,role FROM books JOIN book_authors ON book_authors.book_id=books.id
+		 WHERE book_authors.author_id=? ORDER BY year, LOWER(title), id, position`, authorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var (
+		list  []bookRow
+		roles []string
+	)
+	for rows.Next() {
+		var role string
+		br, err := scanBookRow(withExtra(rows, &role))
+		if err != nil {
+			return nil, err
+		}
+		list, roles = append(list, br), append(roles, role)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close() // libera la conexión antes de leer las etiquetas
+
+	books, err := r.db.hydrateBooks(ctx, list)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]domain.AuthorBook, 0, len(books))
+	for i, b := range books {
+		out = append(out, domain.AuthorBook{Book: b, Role: domain.CreditRole(roles[i])})
+	}
+	return out, nil
+}
+
+// Merge pasa los créditos de dupID a keepID y elimina dupID en una transacción.
+// Si keepID ya tenía el mismo rol en un libro, el crédito duplicado se descarta.
+func (r *SQLAuthorRepo) Merge(ctx context.Context, keepID, dupID uint64) ([]uint64, error) {
+	var affected []uint64
+	err := r.db.inTx(ctx, func(tx txConn) error {
+		for _, id := range []uint64{keepID, dupID} {
+			var one int
+			if err := tx.QueryRowContext(ctx, `SELECT 1 FROM authors WHERE id=?`, id).Scan(&one); err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return domain.ErrNotFound
+				}
+				return err
+			}
+		}
+
+		type credit struct {
+			bookID uint64
+			role   string
+		}
+		rows, err := tx.QueryContext(ctx, `SELECT book_id,role FROM book_authors WHERE author_id=? ORDER BY book_id`, dupID)
+		if err != nil {
+			return err
+		}
+		var moved []credit
+		for rows.Next() {
+			var c credit
+			if err := rows.Scan(&c.bookID, &c.role); err != nil {
+				rows.Close()
+				return err
+			}
+			moved = append(moved, c)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, c := range moved {
+			if len(affected) == 0 || affected[len(affected)-1] != c.bookID {
+				affected = append(affected, c.bookID)
+			}
+			var n int
+			if err := tx.QueryRowContext(ctx,
+				`SELECT COUNT(*) FROM book_authors WHERE book_id=? AND author_id=? AND role=?`,
+				c.bookID, keepID, c.role).Scan(&n); err != nil {
+				return err
+			}
+			q := `UPDATE book_authors SET author_id=? WHERE book_id=? AND author_id=? AND role=?`
+			args := []any{keepID, c.bookID, dupID, c.role}
+			if n > 0 {
+				q = `DELETE FROM book_authors WHERE book_id=? AND author_id=? AND role=?`
+				args = args[1:]
+			}
+			if _, err := tx.exec(ctx, q, args...); err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM authors WHERE id=?`, dupID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return affected, nil
+}
+
+// scanAuthor convierte una fila (authorColumns) en entidad.
+func scanAuthor(row interface{ Scan(dest ...any) error }) (*domain.Author, error) {
+	var (
+		id                   uint64
+		name, bio            string
+		createdAt, updatedAt dbTime
+	)
+	if err := row.Scan(&id, &name, &bio, &createdAt, &updatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return domain.HydrateAuthor(id, name, bio, createdAt.Time, updatedAt.Time), nil
+}
+
+// extraScanner agrega columnas al final de una fila que lee otro scan*.
+type extraScanner struct {
+	row   interface{ Scan(dest ...any) error }
+	extra []any
+}
+
+func (s extraScanner) Scan(dest ...any) error { return s.row.Scan(append(dest, s.extra...)...) }
+
+// withExtra permite reutilizar scanBookRow en consultas con columnas adicionales.
+func withExtra(row interface{ Scan(dest ...any) error }, extra ...any) extraScanner {
+	return extraScanner{row: row, extra: extra}
+}
@@ -0,0 +1,174 @@
+package db // Infraestructura DB: línea base para BD creadas antes de las migraciones
+
+import (
+	"context" // Timeouts/cancelación
+	"fmt"     // Errores con contexto
+	"regexp"  // Tabla de cada sentencia de 0001
+	"strconv" // Largo de VARCHAR en SQLite
+	"strings" // Normalización de tipos
+)
+
+// baselineColumn es una columna de las tablas de 0001 que la línea base
+// verifica en una BD ya existente. Las de texto (size > 0) deben ser VARCHAR
+// de al menos ese largo; del resto solo se exige que existan.
+type baselineColumn struct {
+	table, name string
+	size        int    // largo de VARCHAR en 0001 (0: otro tipo)
+	mysql       string // definición completa en MySQL, para ajustarla
+}
+
+// baselineTables son las tablas que crea 0001, en orden de creación.
+var baselineTables = []string{"users", "books", "access_events"}
+
+// baselineColumns son las columnas de 0001 (las que agregan las migraciones
+// siguientes las crean ellas mismas).
+var baselineColumns = []baselineColumn{
+	{table: "users", name: "id"},
+	{table: "users", name: "name", size: 120, mysql: "VARCHAR(120) NOT NULL"},
+	{table: "users", name: "email", size: 180, mysql: "VARCHAR(180) NOT NULL"},
+	{table: "users", name: "role", size: 20, mysql: "VARCHAR(20) NOT NULL"},
+	{table: "users", name: "active"},
+	{table: "users", name: "created_at"},
+	{table: "users", name: "updated_at"},
+	{table: "books", name: "id"},
+	{table: "books", name: "title", size: 220, mysql: "VARCHAR(220) NOT NULL"},
+	{table: "books", name: "author", size: 180, mysql: "VARCHAR(180) NOT NULL"},
+	{table: "books", name: "year"},
+	{table: "books", name: "isbn", size: 80, mysql: "VARCHAR(80) NOT NULL"},
+	{table: "books", name: "category", size: 120, mysql: "VARCHAR(120) NOT NULL"},
+	{table: "books", name: "tags", size: 600, mysql: "VARCHAR(600) NOT NULL DEFAULT ''"},
+	{table: "books", name: "description"},
+	{table: "books", name: "active"},
+	{table: "books", name: "created_at"},
+	{table: "books", name: "updated_at"},
+	{table: "access_events", name: "id"},
+	{table: "access_events", name: "user_id"},
+	{table: "access_events", name: "book_id"},
+	{table: "access_events", name: "access_type", size: 20, mysql: "VARCHAR(20) NOT NULL"},
+	{table: "access_events", name: "created_at"},
+}
+
+// columnType es el tipo de una columna tal como lo informa el motor.
+type columnType struct {
+	kind string // varchar, enum, int, ... (en minúsculas)
+	size int    // largo de VARCHAR/ENUM (0 si no aplica)
+}
+
+// createTarget reconoce la tabla de un CREATE TABLE / CREATE [UNIQUE] INDEX.
+var createTarget = regexp.MustCompile(`(?is)^CREATE\s+(?:TABLE\s+|(?:UNIQUE\s+)?INDEX\s+\w+\s+ON\s+)(\w+)`)
+
+// baseline es el paso previo a 0001. En una BD vacía no hace nada y 0001 se
+// aplica tal cual. Si ya hay tablas de 0001 (BD creada con los antiguos
+// scripts/schema.sql o docs/schema.sql, o un 0001 que falló a medias en
+// MySQL) verifica sus columnas y devuelve el script sin las sentencias de
+// esas tablas, para crear solo las que faltan. En MySQL ajusta las
+// diferencias conocidas de los scripts viejos (VARCHAR más cortos, role y
+// access_type como ENUM); cualquier otra diferencia es ErrSchemaMismatch.
+func baseline(ctx context.Context, tx txConn, script string) (string, error) {
+	existing := map[string]map[string]columnType{}
+	for _, table := range baselineTables {
+		cols, err := columnTypes(ctx, tx, table)
+		if err != nil {
+			return "", err
+		}
+		if len(cols) > 0 {
+			existing[table] = cols
+		}
+	}
+	if len(existing) == 0 {
+		return script, nil
+	}
+
+	var problems, fixes []string
+	for _, c := range baselineColumns {
+		cols, ok := existing[c.table]
+		if !ok {
+			continue
+		}
+		got, ok := cols[c.name]
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("%s.%s is missing", c.table, c.name))
+		case c.size == 0 || tx.d.name == DriverSQLite: // SQLite no aplica el largo de VARCHAR
+		case got.kind == "varchar" && got.size >= c.size:
+		case tx.d.name == DriverMySQL && (got.kind == "varchar" || got.kind == "enum"):
+			fixes = append(fixes, fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s %s", c.table, c.name, c.mysql))
+		default:
+			problems = append(problems, fmt.Sprintf("%s.%s is %s, expected VARCHAR(%d)", c.table, c.name, got, c.size))
+		}
+	}
+	if len(problems) > 0 {
+		return "", fmt.Errorf("%w: %s", ErrSchemaMismatch, strings.Join(problems, "; "))
+	}
+	for _, stmt := range fixes {
+		if _, err := tx.Tx.ExecContext(ctx, stmt); err != nil {
+			return "", err
+		}
+	}
+
+	var rest []string
+	for _, stmt := range splitStatements(script) {
+		if m := createTarget.FindStringSubmatch(stmt); m != nil && existing[strings.ToLower(m[1])] != nil {
+			continue
+		}
+		rest = append(rest, stmt+";")
+	}
+	return strings.Join(rest, "\n"), nil
+}
+
+// columnTypes lee las columnas de table (vacío si la tabla no existe).
+func columnTypes(ctx context.Context, tx txConn, table string) (map[string]columnType, error) {
+	var query string
+	switch tx.d.name {
+	case DriverMySQL:
+		query = `SELECT column_name, data_type, COALESCE(character_maximum_length,0) FROM information_schema.columns
+		 WHERE table_schema = DATABASE() AND table_name = ?`
+	case DriverPostgres:
+		query = `SELECT column_name, data_type, COALESCE(character_maximum_length,0) FROM information_schema.columns
+		 WHERE table_schema = current_schema() AND table_name = ?`
+	default:
+		query = `SELECT name, type, 0 FROM pragma_table_info(?)`
+	}
+	rows, err := tx.QueryContext(ctx, query, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := map[string]columnType{}
+	for rows.Next() {
+		var (
+			name, kind string
+			size       int64
+		)
+		if err := rows.Scan(&name, &kind, &size); err != nil {
+			return nil, err
+		}
+		out[strings.ToLower(name)] = parseColumnType(kind, int(size))
+	}
+	return out, rows.Err()
+}
+
+// parseColumnType normaliza el tipo informado por el motor: "character
+// varying" (PostgreSQL) y "VARCHAR(220)" (SQLite, que solo da el tipo
+// declarado) quedan como varchar con su largo.
+func parseColumnType(kind string, size int) columnType {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	if name, rest, ok := strings.Cut(kind, "("); ok {
+		kind = strings.TrimSpace(name)
+		if n, err := strconv.Atoi(strings.TrimSpace(strings.TrimSuffix(rest, ")"))); err == nil {
+			size = n
+		}
+	}
+	if kind == "character varying" {
+		kind = "varchar"
+	}
+	return columnType{kind: kind, size: size}
+}
+
+func (c columnType) String() string {
+	if c.size > 0 {
+		return fmt.Sprintf("%s(%d)", strings.ToUpper(c.kind), c.size)
+	}
+	return strings.ToUpper(c.kind)
+}
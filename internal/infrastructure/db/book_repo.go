@@ -0,0 +1,306 @@
+package db
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "strings"
+
+    "github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+// SQLBookRepo implementa usecase.BookRepo sobre cualquier motor soportado.
+type SQLBookRepo struct {
+    db conn // conexión + dialecto del motor
+}
+
+func NewMySQLBookRepo(db *sql.DB) *SQLBookRepo  { return &SQLBookRepo{db: conn{db, mysqlDialect}} }
+func NewSQLiteBookRepo(db *sql.DB) *SQLBookRepo { return &SQLBookRepo{db: conn{db, sqliteDialect}} }
+func NewPostgresBookRepo(db *sql.DB) *SQLBookRepo { return &SQLBookRepo{db: conn{db, postgresDialect}} }
+
+// Las etiquetas no están en books: se leen de book_tags (ver hydrateBooks).
+const bookColumns = `id,title,author,year,isbn,category,COALESCE(description,''),active,created_at,COALESCE(updated_at,created_at)`
+
+// Create inserta el libro en una transacción: resuelve la categoría por slug
+// (creándola si no existe) y guarda las etiquetas en book_tags.
+func (r *SQLBookRepo) Create(ctx context.Context, b *domain.Book) (uint64, error) {
+    var id uint64
+    err := r.db.inTx(ctx, func(tx txConn) error {
+        catID, category, err := tx.ensureCategory(ctx, b.Category())
+        if err != nil { return err }
+        id, err = tx.insert(ctx,
+            `INSERT INTO books (title,author,year,isbn,category,category_id,description,active) VALUES (?,?,?,?,?,?,?,?)`,
+            b.Title(), b.Author(), b.Year(), b.ISBN(), category, catID, b.Description(), b.Active(),
+        )
+        if err != nil { return err }
+        return tx.setBookTags(ctx, id, b.Tags())
+    })
+    if err != nil { return 0, err }
+    return id, nil
+}
+
+func (r *SQLBookRepo) GetByID(ctx context.Context, id uint64) (*domain.Book, error) {
+    return r.one(ctx, `SELECT `+bookColumns+` FROM books WHERE id=?`, id)
+}
+
+func (r *SQLBookRepo) GetByISBN(ctx context.Context, isbn string) (*domain.Book, error) {
+    isbn = domain.NormalizeISBN(isbn) // se guarda en forma canónica
+    return r.one(ctx, `SELECT `+bookColumns+` FROM books WHERE isbn=?`, isbn)
+}
+
+func (r *SQLBookRepo) List(ctx context.Context) ([]*domain.Book, error) {
+    return r.query(ctx, `SELECT `+bookColumns+` FROM books ORDER BY id DESC`)
+}
+
+func (r *SQLBookRepo) Search(ctx context.Context, f domain.BookFilter) (domain.Page[*domain.Book], error) {
+    p, err := f.Paging()
+    if err != nil { return domain.Page[*domain.Book]{}, err }
+
+    // Construcción simple con filtros (slices para params)
+    d := r.db.d
+    where := []string{"1=1"}
+    args := []any{}
+
+    // Consulta estructurada (texto libre, campo:valor, -negación, OR)
+    expr, err := f.Query()
+    if err != nil { return domain.Page[*domain.Book]{}, err }
+    if expr != nil {
+        clause, qargs := compileQuery(d, expr)
+        where = append(where, clause)
+        args = append(args, qargs...)
+    }
+    if a := strings.TrimSpace(f.Author); a != "" {
+        where = append(where, d.like("author"))
+        args = append(args, "%"+strings.ToLower(a)+"%")
+    }
+    if c := strings.TrimSpace(f.Category); c != "" {
+        where = append(where, d.like("category"))
+        args = append(args, "%"+strings.ToLower(c)+"%")
+    }
+    if tag := strings.TrimSpace(f.Tag); tag != "" {
+        clause, targs := tagClause(tag)
+        where = append(where, clause)
+        args = append(args, targs...)
+    }
+    if f.YearFrom > 0 {
+        where = append(where, "year>=?")
+        args = append(args, f.YearFrom)
+    }
+    if f.YearTo > 0 {
+        where = append(where, "year<=?")
+        args = append(args, f.YearTo)
+    }
+    if f.MinRating > 0 {
+        where = append(where, ratingExpr+">=?")
+        args = append(args, f.MinRating)
+    }
+
+    cond := strings.Join(where, " AND ")
+    page := domain.Page[*domain.Book]{Page: p.Page, PageSize: p.PageSize}
+
+    // Total de coincidencias (para "página X de Y")
+    if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM books WHERE `+cond, args...).Scan(&page.Total); err != nil {
+        return page, err
+    }
+
+    page.Items, err = r.query(ctx, `SELECT `+bookColumns+`
+              FROM books WHERE `+cond+` ORDER BY `+orderBy(bookSortColumns, p)+` LIMIT ? OFFSET ?`,
+        append(args, p.PageSize, p.Offset())...)
+    return page, err
+}
+
+// bookSortColumns traduce los campos de orden públicos a expresiones SQL.
+// created_at se ordena por id: mismo orden de inserción y sin empates por segundo.
+var bookSortColumns = map[string]string{
+    domain.SortCreatedAt: "id",
+    "title":              "LOWER(title)",
+    "author":             "LOWER(author)",
+    "year":               "year",
+    domain.SortRating:    ratingExpr, // promedio de las reseñas aprobadas (ver review_repo.go)
+}
+
+// Update actualiza el libro, su categoría y sus etiquetas en una transacción.
+func (r *SQLBookRepo) Update(ctx context.Context, b *domain.Book) error {
+    return r.db.inTx(ctx, func(tx txConn) error {
+        catID, category, err := tx.ensureCategory(ctx, b.Category())
+        if err != nil { return err }
+        // updated_at explícito: SQLite y PostgreSQL no tienen ON UPDATE CURRENT_TIMESTAMP
+        if _, err := tx.exec(ctx,
+            `UPDATE books SET title=?,author=?,year=?,isbn=?,category=?,category_id=?,description=?,active=?,updated_at=CURRENT_TIMESTAMP WHERE id=?`,
+            b.Title(), b.Author(), b.Year(), b.ISBN(), category, catID, b.Description(), b.Active(), b.ID(),
+        ); err != nil {
+            return err
+        }
+        return tx.setBookTags(ctx, b.ID(), b.Tags())
+    })
+}
+
+func (r *SQLBookRepo) Delete(ctx context.Context, id uint64) error {
+    res, err := r.db.ExecContext(ctx, `DELETE FROM books WHERE id=?`, id)
+    if err != nil { return err }
+    n, _ := res.RowsAffected()
+    if n == 0 { return domain.ErrNotFound }
+    return nil
+}
+
+// query ejecuta un SELECT de libros y reconstruye las entidades.
+func (r *SQLBookRepo) query(ctx context.Context, query string, args ...any) ([]*domain.Book, error) {
+    rows, err := r.db.QueryContext(ctx, query, args...)
+    if err != nil { return nil, err }
+    defer rows.Close()
+
+    list := []bookRow{}
+    for rows.Next() {
+        br, err := scanBookRow(rows)
+        if err != nil { return nil, err }
+        list = append(list, br)
+    }
+    if err := rows.Err(); err != nil { return nil, err }
+    rows.Close() // libera la conexión antes de leer las etiquetas (SQLite usa una sola)
+    return r.db.hydrateBooks(ctx, list)
+}
+
+// one ejecuta un SELECT de un solo libro (domain.ErrNotFound si no hay filas).
+func (r *SQLBookRepo) one(ctx context.Context, query string, args ...any) (*domain.Book, error) {
+    list, err := r.query(ctx, query, args...)
+    if err != nil { return nil, err }
+    if len(list) == 0 { return nil, domain.ErrNotFound }
+    return list[0], nil
+}
+
+// bookRow es una fila de bookColumns antes de leer sus etiquetas.
+type bookRow struct {
+    id uint64
+    title, author, isbn, category, desc string
+    year int
+    active bool
+    createdAt, updatedAt dbTime
+}
+
+// scanBookRow lee una fila (bookColumns).
+func scanBookRow(row interface{ Scan(dest ...any) error }) (bookRow, error) {
+    var br bookRow
+    err := row.Scan(&br.id, &br.title, &br.author, &br.year, &br.isbn, &br.category, &br.desc, &br.active, &br.createdAt, &br.updatedAt)
+    if errors.Is(err, sql.ErrNoRows) { return br, domain.ErrNotFound }
+    return br, err
+}
+
+// tagBatch es la cantidad de libros por consulta al leer etiquetas (IN (...)).
+const tagBatch = 500
+
+// hydrateBooks lee las etiquetas de las filas (en orden, por lotes) y
+// reconstruye las entidades.
+func (c conn) hydrateBooks(ctx context.Context, list []bookRow) ([]*domain.Book, error) {
+    tags := map[uint64][]string{}
+    for from := 0; from < len(list); from += tagBatch {
+        batch := list[from:min(from+tagBatch, len(list))]
+        ids := make([]any, len(batch))
+        for i, br := range batch { ids[i] = br.id }
+
+        rows, err := c.QueryContext(ctx,
+            `SELECT bt.book_id,t.name FROM book_tags bt JOIN tags t ON t.id=bt.tag_id
+             WHERE bt.book_id IN (`+placeholders(len(ids))+`) ORDER BY bt.book_id, bt.position`, ids...)
+        if err != nil { return nil, err }
+        for rows.Next() {
+            var (
+                id uint64
+                name string
+            )
+            if err := rows.Scan(&id, &name); err != nil {
+                rows.Close()
+                return nil, err
+            }
+            tags[id] = append(tags[id], name)
+        }
+        rows.Close()
+        if err := rows.Err(); err != nil { return nil, err }
+    }
+
+    out := make([]*domain.Book, 0, len(list))
+    for _, br := range list {
+        b, err := domain.HydrateBook(br.id, br.title, br.author, br.year, br.isbn, br.category, tags[br.id],
+            br.desc, br.active, br.createdAt.Time, br.updatedAt.Time)
+        if err != nil { return nil, err }
+        out = append(out, b)
+    }
+    return out, nil
+}
+
+// placeholders arma "?,?,?" para una lista IN de n valores (n > 0).
+func placeholders(n int) string { return strings.Repeat("?,", n-1) + "?" }
+
+// tagJoin es la condición "el libro tiene una etiqueta que cumple ..." (book_tags).
+const tagJoin = `EXISTS (SELECT 1 FROM book_tags bt JOIN tags t ON t.id=bt.tag_id WHERE bt.book_id=books.id AND `
+
+// tagClause filtra por etiqueta: mismo slug (sin distinguir mayúsculas,
+// acentos ni separadores).
+func tagClause(tag string) (string, []any) {
+    return tagJoin + "t.slug=?)", []any{domain.Slugify(tag)}
+}
+
+// compileQuery traduce el AST de domain.ParseQuery a una condición SQL con sus
+// argumentos (cada nodo va entre paréntesis para respetar NOT/OR/AND).
+func compileQuery(d dialect, e domain.QueryExpr) (string, []any) {
+    join := func(terms []domain.QueryExpr, op string) (string, []any) {
+        parts := make([]string, 0, len(terms))
+        var args []any
+        for _, t := range terms {
+            c, a := compileQuery(d, t)
+            parts = append(parts, c)
+            args = append(args, a...)
+        }
+        return "(" + strings.Join(parts, op) + ")", args
+    }
+
+    switch n := e.(type) {
+    case domain.AndExpr:
+        return join(n.Terms, " AND ")
+    case domain.OrExpr:
+        return join(n.Terms, " OR ")
+    case domain.NotExpr:
+        c, a := compileQuery(d, n.Term)
+        return "NOT " + c, a
+
+    case domain.TextTerm:
+        like := "%" + strings.ToLower(n.Text) + "%"
+        clause := d.like("title") + " OR " + d.like("author") + " OR " + tagJoin + d.like("t.name") + ") OR " + d.like("COALESCE(description,'')")
+        args := []any{like, like, like, like}
+        if d.fullText && !n.Prefix {
+            // PostgreSQL: además de "contiene", la palabra en cualquier forma (tsvector)
+            fn := "plainto_tsquery"
+            if n.Phrase {
+                fn = "phraseto_tsquery"
+            }
+            clause = "search_vector @@ " + fn + "('spanish', ?) OR " + clause
+            args = append([]any{n.Text}, args...)
+        }
+        return "(" + clause + ")", args
+
+    case domain.FieldTerm:
+        v := strings.ToLower(n.Value)
+        switch n.Field {
+        case domain.FieldAuthor:
+            return d.like("author"), []any{"%" + v + "%"}
+        case domain.FieldTitle:
+            return d.like("title"), []any{"%" + v + "%"}
+        case domain.FieldCategory:
+            return d.like("category"), []any{"%" + v + "%"}
+        case domain.FieldTag:
+            return tagClause(v)
+        case domain.FieldISBN:
+            return "LOWER(isbn)=?", []any{v}
+        }
+
+    case domain.YearRange:
+        switch {
+        case n.From > 0 && n.To > 0:
+            return "(year>=? AND year<=?)", []any{n.From, n.To}
+        case n.From > 0:
+            return "year>=?", []any{n.From}
+        default:
+            return "year<=?", []any{n.To}
+        }
+    }
+    // Nodo desconocido: no coincide con nada (nunca debería pasar)
+    return "1=0", nil
+}
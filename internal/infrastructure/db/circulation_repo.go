@@ -0,0 +1,647 @@
+package db // Infraestructura DB: ejemplares físicos, puestos digitales y préstamos
+
+import (
+	"context"      // Para timeouts/cancelación
+	"database/sql" // Driver SQL estándar
+	"errors"       // Para comparar errores (errors.Is)
+	"fmt"          // Errores con contexto
+	"time"         // Referencia de vencimiento
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain" // Dominio (Copy, Loan + errores)
+)
+
+// SQLCirculationRepo persiste ejemplares (tabla copies) y préstamos (tabla loans).
+type SQLCirculationRepo struct {
+	db conn // conexión + dialecto del motor
+}
+
+// NewMySQLCirculationRepo inyecta la conexión (MySQL).
+func NewMySQLCirculationRepo(db *sql.DB) *SQLCirculationRepo {
+	return &SQLCirculationRepo{db: conn{db, mysqlDialect}}
+}
+
+// NewSQLiteCirculationRepo inyecta la conexión (SQLite).
+func NewSQLiteCirculationRepo(db *sql.DB) *SQLCirculationRepo {
+	return &SQLCirculationRepo{db: conn{db, sqliteDialect}}
+}
+
+const (
+	copyColumns = `id,book_id,kind,barcode,status,location,created_at,COALESCE(updated_at,created_at)`
+	loanColumns = `l.id,l.copy_id,l.book_id,l.user_id,l.loaned_at,l.due_at,l.returned_at,l.renewals`
+	holdColumns = `h.id,h.book_id,h.user_id,COALESCE(h.copy_id,0),h.status,h.created_at,h.ready_at,h.expires_at,h.closed_at`
+)
+
+// loanSortColumns traduce los campos de orden públicos a expresiones SQL.
+var loanSortColumns = map[string]string{
+	domain.SortCreatedAt: "id",
+	"due_at":             "due_at",
+}
+
+// holdSortColumns: la cola se ordena por id (orden de llegada).
+var holdSortColumns = map[string]string{
+	domain.SortCreatedAt: "id",
+}
+
+// -------------------- Ejemplares --------------------
+
+// CreateCopy inserta un ejemplar y retorna su ID. Si hay reservas en espera
+// del libro, el ejemplar nuevo queda apartado para la más antigua.
+func (r *SQLCirculationRepo) CreateCopy(ctx context.Context, c *domain.Copy) (uint64, error) {
+	var id uint64
+	err := r.db.inTx(ctx, func(tx txConn) error {
+		var err error
+		id, err = tx.insert(ctx,
+			`INSERT INTO copies (book_id,kind,barcode,status,location) VALUES (?,?,?,?,?)`,
+			c.BookID(), string(c.Kind()), c.Barcode(), string(c.Status()), c.Location())
+		if err != nil {
+			return err
+		}
+		return tx.serveHolds(ctx, c.BookID(), time.Now())
+	})
+	return id, err
+}
+
+// GetCopy busca un ejemplar por ID.
+func (r *SQLCirculationRepo) GetCopy(ctx context.Context, id uint64) (*domain.Copy, error) {
+	return scanCopy(r.db.QueryRowContext(ctx, `SELECT `+copyColumns+` FROM copies WHERE id=?`, id))
+}
+
+// GetCopyByBarcode busca un ejemplar por código de barras (ya normalizado).
+func (r *SQLCirculationRepo) GetCopyByBarcode(ctx context.Context, barcode string) (*domain.Copy, error) {
+	return scanCopy(r.db.QueryRowContext(ctx, `SELECT `+copyColumns+` FROM copies WHERE barcode=?`, barcode))
+}
+
+// CopiesByBook retorna los ejemplares del libro ordenados por código.
+func (r *SQLCirculationRepo) CopiesByBook(ctx context.Context, bookID uint64) ([]*domain.Copy, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+copyColumns+` FROM copies WHERE book_id=? ORDER BY barcode, id`, bookID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []*domain.Copy{}
+	for rows.Next() {
+		c, err := scanCopy(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// UpdateCopy guarda código, ubicación y estado solo si el estado sigue siendo
+// from (concurrencia optimista): un préstamo, una devolución o una reserva
+// ocurridos entre la lectura y la escritura no se pisan (ErrUnavailable).
+// Un ejemplar que vuelve a estar disponible atiende la cola del libro.
+func (r *SQLCirculationRepo) UpdateCopy(ctx context.Context, c *domain.Copy, from domain.CopyStatus) error {
+	return r.db.inTx(ctx, func(tx txConn) error {
+		// updated_at explícito: SQLite y PostgreSQL no tienen ON UPDATE CURRENT_TIMESTAMP
+		res, err := tx.exec(ctx,
+			`UPDATE copies SET barcode=?,status=?,location=?,updated_at=CURRENT_TIMESTAMP WHERE id=? AND status=?`,
+			c.Barcode(), string(c.Status()), c.Location(), c.ID(), string(from))
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			if err := tx.mustExist(ctx, "copies", c.ID()); err != nil {
+				return err
+			}
+			return domain.ErrUnavailable
+		}
+		if !c.Available() {
+			return nil
+		}
+		return tx.serveHolds(ctx, c.BookID(), time.Now())
+	})
+}
+
+// DeleteCopy elimina el ejemplar; su historial cae por ON DELETE CASCADE.
+func (r *SQLCirculationRepo) DeleteCopy(ctx context.Context, id uint64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM copies WHERE id=?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// -------------------- Préstamos --------------------
+
+// Checkout marca el ejemplar como prestado (si estaba disponible, o apartado
+// para una reserva lista del mismo usuario, que queda cumplida), verifica el
+// límite de préstamos activos del usuario y guarda el préstamo, todo en una
+// transacción.
+func (r *SQLCirculationRepo) Checkout(ctx context.Context, l *domain.Loan, maxActive int) (uint64, error) {
+	var id uint64
+	err := r.db.inTx(ctx, func(tx txConn) error {
+		res, err := tx.ExecContext(ctx,
+			`UPDATE copies SET status='on_loan',updated_at=CURRENT_TIMESTAMP WHERE id=? AND status='available'`, l.CopyID())
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			if err := tx.claimHold(ctx, l); err != nil {
+				return err
+			}
+		}
+		// Las reservas en espera del mismo libro ya no hacen falta
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE holds SET status='fulfilled',closed_at=? WHERE book_id=? AND user_id=? AND status='waiting'`,
+			l.LoanedAt().UTC(), l.BookID(), l.UserID()); err != nil {
+			return err
+		}
+
+		var active int
+		if err := tx.QueryRowContext(ctx,
+			`SELECT COUNT(*) FROM loans WHERE user_id=? AND returned_at IS NULL`, l.UserID()).Scan(&active); err != nil {
+			return err
+		}
+		if active >= maxActive {
+			return domain.ErrLimitReached
+		}
+
+		id, err = tx.insert(ctx,
+			`INSERT INTO loans (copy_id,book_id,user_id,loaned_at,due_at,renewals) VALUES (?,?,?,?,?,?)`,
+			l.CopyID(), l.BookID(), l.UserID(), l.LoanedAt().UTC(), l.DueAt().UTC(), l.Renewals())
+		return err
+	})
+	return id, err
+}
+
+// GetLoan busca un préstamo por ID.
+func (r *SQLCirculationRepo) GetLoan(ctx context.Context, id uint64) (*domain.Loan, error) {
+	return scanLoan(r.db.QueryRowContext(ctx, `SELECT `+loanColumns+` FROM loans l WHERE l.id=?`, id))
+}
+
+// ActiveLoan busca el préstamo sin devolver del ejemplar.
+func (r *SQLCirculationRepo) ActiveLoan(ctx context.Context, copyID uint64) (*domain.Loan, error) {
+	return scanLoan(r.db.QueryRowContext(ctx,
+		`SELECT `+loanColumns+` FROM loans l WHERE l.copy_id=? AND l.returned_at IS NULL ORDER BY l.id DESC LIMIT 1`, copyID))
+}
+
+// RenewLoan guarda el nuevo vencimiento de un préstamo activo.
+func (r *SQLCirculationRepo) RenewLoan(ctx context.Context, l *domain.Loan) error {
+	// La cola se verifica en el mismo UPDATE: una reserva que llega entre la
+	// lectura y la escritura también impide la renovación.
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE loans SET due_at=?,renewals=? WHERE id=? AND returned_at IS NULL
+		 AND NOT EXISTS (SELECT 1 FROM holds WHERE holds.book_id=loans.book_id AND holds.status='waiting')`,
+		l.DueAt().UTC(), l.Renewals(), l.ID())
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		old, err := r.GetLoan(ctx, l.ID())
+		if err != nil {
+			return err
+		}
+		if !old.Active() {
+			return domain.ErrValidation
+		}
+		return fmt.Errorf("%w: other users are waiting for this book", domain.ErrLimitReached)
+	}
+	return nil
+}
+
+// ReturnLoan cierra el préstamo y deja el ejemplar disponible.
+func (r *SQLCirculationRepo) ReturnLoan(ctx context.Context, l *domain.Loan) error {
+	err := r.db.inTx(ctx, func(tx txConn) error {
+		res, err := tx.ExecContext(ctx,
+			`UPDATE loans SET returned_at=? WHERE id=? AND returned_at IS NULL`, l.ReturnedAt().UTC(), l.ID())
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return errClosedLoan
+		}
+		res, err = tx.ExecContext(ctx,
+			`UPDATE copies SET status='available',updated_at=CURRENT_TIMESTAMP WHERE id=? AND status='on_loan'`, l.CopyID())
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return nil
+		}
+		return tx.serveHolds(ctx, l.BookID(), l.ReturnedAt())
+	})
+	if errors.Is(err, errClosedLoan) {
+		return r.closedLoan(ctx, l.ID())
+	}
+	return err
+}
+
+// ListLoans retorna la página de préstamos (con código, título y usuario).
+func (r *SQLCirculationRepo) ListLoans(ctx context.Context, f domain.LoanFilter) (domain.Page[domain.LoanDetail], error) {
+	p, err := f.Paging()
+	if err != nil {
+		return domain.Page[domain.LoanDetail]{}, err
+	}
+
+	cond := "1=1"
+	args := []any{}
+	for _, by := range []struct {
+		col string
+		id  uint64
+	}{{"l.user_id", f.UserID}, {"l.book_id", f.BookID}, {"l.copy_id", f.CopyID}} {
+		if by.id != 0 {
+			cond += " AND " + by.col + "=?"
+			args = append(args, by.id)
+		}
+	}
+	if f.Digital {
+		cond += " AND l.copy_id IN (SELECT id FROM copies WHERE kind='digital')"
+	}
+	switch f.Status {
+	case domain.LoanStatusActive:
+		cond += " AND l.returned_at IS NULL"
+	case domain.LoanStatusOverdue:
+		cond += " AND l.returned_at IS NULL AND l.due_at<?"
+		args = append(args, nowOr(f.Now).UTC())
+	case domain.LoanStatusReturned:
+		cond += " AND l.returned_at IS NOT NULL"
+	}
+
+	page := domain.Page[domain.LoanDetail]{Page: p.Page, PageSize: p.PageSize}
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM loans l WHERE `+cond, args...).Scan(&page.Total); err != nil {
+		return page, err
+	}
+
+	// La tabla derivada evita que el "id" de desempate de orderBy sea ambiguo
+	// entre loans, copies, books y users.
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+loanColumns+`,l.barcode,l.kind,l.title,l.user_name FROM (
+		   SELECT l.*,c.barcode,c.kind,b.title,u.name AS user_name
+		   FROM loans l JOIN copies c ON c.id=l.copy_id JOIN books b ON b.id=l.book_id JOIN users u ON u.id=l.user_id
+		 ) l WHERE `+cond+` ORDER BY `+orderBy(loanSortColumns, p)+` LIMIT ? OFFSET ?`,
+		append(args, p.PageSize, p.Offset())...)
+	if err != nil {
+		return page, err
+	}
+	defer rows.Close()
+
+	page.Items = []domain.LoanDetail{}
+	for rows.Next() {
+		var (
+			d    domain.LoanDetail
+			kind string
+		)
+		l, err := scanLoan(withExtra(rows, &d.Barcode, &kind, &d.BookTitle, &d.UserName))
+		if err != nil {
+			return page, err
+		}
+		d.Loan, d.Digital = l, domain.CopyKind(kind) == domain.CopyDigital
+		page.Items = append(page.Items, d)
+	}
+	return page, rows.Err()
+}
+
+// errClosedLoan corta la transacción de ReturnLoan si el préstamo ya estaba cerrado.
+var errClosedLoan = errors.New("loan is not active")
+
+// closedLoan explica por qué no se actualizó un préstamo: no existe o ya se devolvió.
+func (r *SQLCirculationRepo) closedLoan(ctx context.Context, id uint64) error {
+	if _, err := r.GetLoan(ctx, id); err != nil {
+		return err
+	}
+	return domain.ErrValidation
+}
+
+// nowOr usa la referencia del filtro o el reloj si no se fijó.
+func nowOr(t time.Time) time.Time {
+	if t.IsZero() {
+		return time.Now()
+	}
+	return t
+}
+
+// -------------------- Reservas --------------------
+
+// PlaceHold guarda la reserva al final de la cola (tras verificar duplicado y
+// límite del usuario) y la atiende enseguida si hay un ejemplar disponible.
+func (r *SQLCirculationRepo) PlaceHold(ctx context.Context, h *domain.Hold, maxActive int) (uint64, error) {
+	var id uint64
+	err := r.db.inTx(ctx, func(tx txConn) error {
+		var same, active int
+		if err := tx.QueryRowContext(ctx,
+			`SELECT COALESCE(SUM(CASE WHEN book_id=? THEN 1 ELSE 0 END),0),COUNT(*)
+			 FROM holds WHERE user_id=? AND status IN ('waiting','ready')`,
+			h.BookID(), h.UserID()).Scan(&same, &active); err != nil {
+			return err
+		}
+		if same > 0 {
+			return domain.ErrDuplicate
+		}
+		if active >= maxActive {
+			return domain.ErrLimitReached
+		}
+
+		var err error
+		id, err = tx.insert(ctx,
+			`INSERT INTO holds (book_id,user_id,status,created_at) VALUES (?,?,?,?)`,
+			h.BookID(), h.UserID(), string(h.Status()), h.CreatedAt().UTC())
+		if err != nil {
+			return err
+		}
+		return tx.serveHolds(ctx, h.BookID(), h.CreatedAt())
+	})
+	return id, err
+}
+
+// GetHold busca una reserva por ID.
+func (r *SQLCirculationRepo) GetHold(ctx context.Context, id uint64) (*domain.Hold, error) {
+	return scanHold(r.db.QueryRowContext(ctx, `SELECT `+holdColumns+` FROM holds h WHERE h.id=?`, id))
+}
+
+// ReadyHold busca la reserva lista que aparta el ejemplar.
+func (r *SQLCirculationRepo) ReadyHold(ctx context.Context, copyID uint64) (*domain.Hold, error) {
+	return scanHold(r.db.QueryRowContext(ctx,
+		`SELECT `+holdColumns+` FROM holds h WHERE h.copy_id=? AND h.status='ready' ORDER BY h.id LIMIT 1`, copyID))
+}
+
+// CancelHold cierra la reserva (si sigue activa) y pasa su ejemplar apartado
+// a la siguiente de la cola.
+func (r *SQLCirculationRepo) CancelHold(ctx context.Context, h *domain.Hold) error {
+	return r.db.inTx(ctx, func(tx txConn) error {
+		cur, err := scanHold(tx.QueryRowContext(ctx, `SELECT `+holdColumns+` FROM holds h WHERE h.id=?`, h.ID()))
+		if err != nil {
+			return err
+		}
+		if !cur.Active() {
+			return domain.ErrValidation
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE holds SET status=?,closed_at=? WHERE id=?`, string(h.Status()), h.ClosedAt().UTC(), h.ID()); err != nil {
+			return err
+		}
+		if cur.Status() != domain.HoldReady {
+			return nil
+		}
+		return tx.releaseCopy(ctx, cur.CopyID(), cur.BookID(), h.ClosedAt())
+	})
+}
+
+// ExpireHolds vence las reservas listas cuyo plazo de retiro pasó y atiende
+// la cola con los ejemplares liberados.
+func (r *SQLCirculationRepo) ExpireHolds(ctx context.Context, now time.Time) (int, error) {
+	var n int
+	err := r.db.inTx(ctx, func(tx txConn) error {
+		rows, err := tx.QueryContext(ctx,
+			`SELECT `+holdColumns+` FROM holds h WHERE h.status='ready' AND h.expires_at<? ORDER BY h.id`, now.UTC())
+		if err != nil {
+			return err
+		}
+		var expired []*domain.Hold
+		for rows.Next() {
+			h, err := scanHold(rows)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			expired = append(expired, h)
+		}
+		rows.Close() // SQLite: una sola conexión, cerrar antes de seguir
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, h := range expired {
+			if _, err := tx.ExecContext(ctx,
+				`UPDATE holds SET status='expired',closed_at=? WHERE id=?`, now.UTC(), h.ID()); err != nil {
+				return err
+			}
+			if err := tx.releaseCopy(ctx, h.CopyID(), h.BookID(), now); err != nil {
+				return err
+			}
+		}
+		n = len(expired)
+		return nil
+	})
+	return n, err
+}
+
+// ListHolds retorna la página de reservas con su lugar en la cola.
+func (r *SQLCirculationRepo) ListHolds(ctx context.Context, f domain.HoldFilter) (domain.Page[domain.HoldDetail], error) {
+	p, err := f.Paging()
+	if err != nil {
+		return domain.Page[domain.HoldDetail]{}, err
+	}
+
+	cond := "1=1"
+	args := []any{}
+	for _, by := range []struct {
+		col string
+		id  uint64
+	}{{"h.user_id", f.UserID}, {"h.book_id", f.BookID}} {
+		if by.id != 0 {
+			cond += " AND " + by.col + "=?"
+			args = append(args, by.id)
+		}
+	}
+	switch f.Status {
+	case "":
+	case domain.HoldStatusActive:
+		cond += " AND h.status IN ('waiting','ready')"
+	default:
+		cond += " AND h.status=?"
+		args = append(args, f.Status)
+	}
+
+	page := domain.Page[domain.HoldDetail]{Page: p.Page, PageSize: p.PageSize}
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM holds h WHERE `+cond, args...).Scan(&page.Total); err != nil {
+		return page, err
+	}
+
+	// Tabla derivada como en ListLoans; queue_pos cuenta las reservas en
+	// espera del mismo libro hasta esta (inclusive).
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+holdColumns+`,h.queue_pos,h.title,h.user_name,h.barcode,h.kind FROM (
+		   SELECT h.*,b.title,u.name AS user_name,COALESCE(c.barcode,'') AS barcode,COALESCE(c.kind,'') AS kind,
+		     CASE WHEN h.status='waiting' THEN
+		       (SELECT COUNT(*) FROM holds w WHERE w.book_id=h.book_id AND w.status='waiting' AND w.id<=h.id)
+		     ELSE 0 END AS queue_pos
+		   FROM holds h JOIN books b ON b.id=h.book_id JOIN users u ON u.id=h.user_id
+		   LEFT JOIN copies c ON c.id=h.copy_id
+		 ) h WHERE `+cond+` ORDER BY `+orderBy(holdSortColumns, p)+` LIMIT ? OFFSET ?`,
+		append(args, p.PageSize, p.Offset())...)
+	if err != nil {
+		return page, err
+	}
+	defer rows.Close()
+
+	page.Items = []domain.HoldDetail{}
+	for rows.Next() {
+		var (
+			d    domain.HoldDetail
+			kind string
+		)
+		h, err := scanHold(withExtra(rows, &d.Position, &d.BookTitle, &d.UserName, &d.Barcode, &kind))
+		if err != nil {
+			return page, err
+		}
+		d.Hold, d.Digital = h, domain.CopyKind(kind) == domain.CopyDigital
+		page.Items = append(page.Items, d)
+	}
+	return page, rows.Err()
+}
+
+// Availability cuenta los ejemplares y puestos del libro por estado y su cola.
+func (r *SQLCirculationRepo) Availability(ctx context.Context, bookID uint64) (domain.Availability, error) {
+	var a domain.Availability
+	if err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM holds WHERE book_id=? AND status='waiting'`, bookID).Scan(&a.Waiting); err != nil {
+		return a, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT kind,status,COUNT(*) FROM copies WHERE book_id=? GROUP BY kind,status`, bookID)
+	if err != nil {
+		return a, err
+	}
+	for rows.Next() {
+		var (
+			kind, status string
+			n            int
+		)
+		if err := rows.Scan(&kind, &status, &n); err != nil {
+			rows.Close()
+			return a, err
+		}
+		a.AddCopies(domain.CopyKind(kind), domain.CopyStatus(status), n)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return a, err
+	}
+
+	if !a.InCirculation() && a.Waiting == 0 {
+		var exists int
+		if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM books WHERE id=?`, bookID).Scan(&exists); err != nil {
+			return a, err
+		}
+		if exists == 0 {
+			return a, domain.ErrNotFound
+		}
+	}
+	return a, nil
+}
+
+// claimHold presta un ejemplar apartado: solo si la reserva lista es del
+// mismo usuario, que queda cumplida (ErrUnavailable si no).
+func (t txConn) claimHold(ctx context.Context, l *domain.Loan) error {
+	res, err := t.ExecContext(ctx,
+		`UPDATE holds SET status='fulfilled',closed_at=? WHERE copy_id=? AND user_id=? AND status='ready'`,
+		l.LoanedAt().UTC(), l.CopyID(), l.UserID())
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		if err := t.mustExist(ctx, "copies", l.CopyID()); err != nil {
+			return err
+		}
+		return domain.ErrUnavailable
+	}
+	_, err = t.ExecContext(ctx,
+		`UPDATE copies SET status='on_loan',updated_at=CURRENT_TIMESTAMP WHERE id=? AND status='on_hold'`, l.CopyID())
+	return err
+}
+
+// releaseCopy devuelve a la estantería un ejemplar apartado y atiende la cola.
+func (t txConn) releaseCopy(ctx context.Context, copyID, bookID uint64, now time.Time) error {
+	if copyID == 0 {
+		return nil // el ejemplar se eliminó (copy_id NULL)
+	}
+	if _, err := t.ExecContext(ctx,
+		`UPDATE copies SET status='available',updated_at=CURRENT_TIMESTAMP WHERE id=? AND status='on_hold'`, copyID); err != nil {
+		return err
+	}
+	return t.serveHolds(ctx, bookID, now)
+}
+
+// serveHolds aparta ejemplares disponibles del libro para las reservas en
+// espera, de la más antigua a la más nueva (FIFO).
+func (t txConn) serveHolds(ctx context.Context, bookID uint64, now time.Time) error {
+	for {
+		var copyID uint64
+		err := t.QueryRowContext(ctx,
+			`SELECT id FROM copies WHERE book_id=? AND status='available' ORDER BY id LIMIT 1`, bookID).Scan(&copyID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		h, err := scanHold(t.QueryRowContext(ctx,
+			`SELECT `+holdColumns+` FROM holds h WHERE h.book_id=? AND h.status='waiting' ORDER BY h.id LIMIT 1`, bookID))
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := h.MarkReady(copyID, now); err != nil {
+			return err
+		}
+		if _, err := t.ExecContext(ctx,
+			`UPDATE copies SET status='on_hold',updated_at=CURRENT_TIMESTAMP WHERE id=?`, copyID); err != nil {
+			return err
+		}
+		if _, err := t.ExecContext(ctx,
+			`UPDATE holds SET status=?,copy_id=?,ready_at=?,expires_at=? WHERE id=?`,
+			string(h.Status()), copyID, h.ReadyAt(), h.ExpiresAt(), h.ID()); err != nil {
+			return err
+		}
+	}
+}
+
+// -------------------- Scan --------------------
+
+// scanCopy convierte una fila (copyColumns) en entidad.
+func scanCopy(row interface{ Scan(dest ...any) error }) (*domain.Copy, error) {
+	var (
+		id, bookID                      uint64
+		kind, barcode, status, location string
+		createdAt, updatedAt            dbTime
+	)
+	if err := row.Scan(&id, &bookID, &kind, &barcode, &status, &location, &createdAt, &updatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return domain.HydrateCopy(id, bookID, domain.CopyKind(kind), barcode, domain.CopyStatus(status), location, createdAt.Time, updatedAt.Time), nil
+}
+
+// scanLoan convierte una fila (loanColumns) en entidad.
+func scanLoan(row interface{ Scan(dest ...any) error }) (*domain.Loan, error) {
+	var (
+		id, copyID, bookID, userID  uint64
+		loanedAt, dueAt, returnedAt dbTime // returned_at NULL => tiempo cero
+		renewals                    int
+	)
+	if err := row.Scan(&id, &copyID, &bookID, &userID, &loanedAt, &dueAt, &returnedAt, &renewals); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return domain.HydrateLoan(id, copyID, bookID, userID, loanedAt.Time, dueAt.Time, returnedAt.Time, renewals), nil
+}
+
+// scanHold convierte una fila (holdColumns) en entidad.
+func scanHold(row interface{ Scan(dest ...any) error }) (*domain.Hold, error) {
+	var (
+		id, bookID, userID, copyID              uint64
+		status                                  string
+		createdAt, readyAt, expiresAt, closedAt dbTime // NULL => tiempo cero
+	)
+	if err := row.Scan(&id, &bookID, &userID, &copyID, &status, &createdAt, &readyAt, &expiresAt, &closedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return domain.HydrateHold(id, bookID, userID, copyID, domain.HoldStatus(status), createdAt.Time, readyAt.Time, expiresAt.Time, closedAt.Time), nil
+}
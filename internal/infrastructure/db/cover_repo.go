@@ -0,0 +1,118 @@
+package db // Infraestructura DB: portadas de los libros
+
+import (
+	"context"      // Para timeouts/cancelación
+	"database/sql" // Driver SQL estándar
+	"errors"       // Para comparar errores (errors.Is)
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain" // Dominio (Cover + errores)
+)
+
+// SQLCoverRepo persiste los metadatos de las portadas (tabla book_covers).
+// La imagen la guarda el BlobStore.
+type SQLCoverRepo struct {
+	db conn // conexión + dialecto del motor
+}
+
+// NewMySQLCoverRepo inyecta la conexión (MySQL).
+func NewMySQLCoverRepo(db *sql.DB) *SQLCoverRepo {
+	return &SQLCoverRepo{db: conn{db, mysqlDialect}}
+}
+
+// NewSQLiteCoverRepo inyecta la conexión (SQLite).
+func NewSQLiteCoverRepo(db *sql.DB) *SQLCoverRepo {
+	return &SQLCoverRepo{db: conn{db, sqliteDialect}}
+}
+
+const coverColumns = `book_id,sha256,content_type,width,height,size,updated_at`
+
+// Get obtiene la portada del libro.
+func (r *SQLCoverRepo) Get(ctx context.Context, bookID uint64) (*domain.Cover, error) {
+	return scanCover(r.db.QueryRowContext(ctx, `SELECT `+coverColumns+` FROM book_covers WHERE book_id=?`, bookID))
+}
+
+// ByBooks retorna las portadas de los libros dados (por lotes).
+func (r *SQLCoverRepo) ByBooks(ctx context.Context, bookIDs []uint64) (map[uint64]*domain.Cover, error) {
+	out := map[uint64]*domain.Cover{}
+	for from := 0; from < len(bookIDs); from += tagBatch {
+		batch := bookIDs[from:min(from+tagBatch, len(bookIDs))]
+		ids := make([]any, len(batch))
+		for i, id := range batch {
+			ids[i] = id
+		}
+
+		rows, err := r.db.QueryContext(ctx,
+			`SELECT `+coverColumns+` FROM book_covers WHERE book_id IN (`+placeholders(len(ids))+`)`, ids...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			c, err := scanCover(rows)
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+			out[c.BookID()] = c
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// Put reemplaza la portada del libro. Borrar e insertar en una transacción
+// evita depender del UPSERT de cada motor.
+func (r *SQLCoverRepo) Put(ctx context.Context, c *domain.Cover) error {
+	return r.db.inTx(ctx, func(tx txConn) error {
+		if err := tx.mustExist(ctx, "books", c.BookID()); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM book_covers WHERE book_id=?`, c.BookID()); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO book_covers (book_id,sha256,content_type,width,height,size) VALUES (?,?,?,?,?,?)`,
+			c.BookID(), c.SHA256(), c.ContentType(), c.Width(), c.Height(), c.Size())
+		return err
+	})
+}
+
+// CountBySHA cuenta las portadas que apuntan a la misma imagen.
+func (r *SQLCoverRepo) CountBySHA(ctx context.Context, sha256 string) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM book_covers WHERE sha256=?`, sha256).Scan(&n)
+	return n, err
+}
+
+// Delete quita la portada del libro.
+func (r *SQLCoverRepo) Delete(ctx context.Context, bookID uint64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM book_covers WHERE book_id=?`, bookID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// scanCover convierte una fila (coverColumns) en entidad.
+func scanCover(row interface{ Scan(dest ...any) error }) (*domain.Cover, error) {
+	var (
+		bookID           uint64
+		sha, contentType string
+		width, height    int
+		size             int64
+		updatedAt        dbTime
+	)
+	if err := row.Scan(&bookID, &sha, &contentType, &width, &height, &size, &updatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return domain.HydrateCover(bookID, sha, contentType, width, height, size, updatedAt.Time), nil
+}
@@ -0,0 +1,162 @@
+package db // Capa de infraestructura: acceso a base de datos (MySQL / SQLite / PostgreSQL)
+
+import (
+	"context"      // Contextos para timeout y cancelación
+	"database/sql" // API estándar de SQL en Go
+	"strings"      // Armado del DSN de SQLite
+	"time"         // Manejo de tiempos
+
+	_ "github.com/go-sql-driver/mysql" // Driver MySQL (import anónimo)
+	_ "github.com/jackc/pgx/v5/stdlib" // Driver PostgreSQL (registra "pgx")
+	_ "modernc.org/sqlite"             // Driver SQLite en Go puro (registra "sqlite")
+)
+
+// DB es un wrapper alrededor de *sql.DB.
+// Permite centralizar la conexión y extenderla en el futuro.
+type DB struct {
+	SQL    *sql.DB // Conexión principal a la base de datos
+	Driver string  // Motor elegido (DriverMySQL / DriverSQLite / DriverPostgres)
+
+	d dialect // diferencias del motor (placeholders, duplicados, ...)
+}
+
+// Open abre una conexión al motor indicado usando el DSN recibido.
+// Además:
+// - Configura el pool de conexiones
+// - Verifica conectividad con Ping
+// - Verifica que el esquema tenga todas las migraciones (y sin cambios)
+func Open(driver, dsn string) (*DB, error) {
+
+	d, err := OpenUnchecked(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// ---------------- ESQUEMA ----------------
+
+	// La app no arranca contra un esquema desactualizado o alterado
+	m, err := NewMigrator(d.SQL, d.Driver)
+	if err != nil {
+		_ = d.SQL.Close()
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := m.Verify(ctx); err != nil {
+		_ = d.SQL.Close()
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// OpenUnchecked abre la conexión SIN verificar migraciones.
+// Solo debe usarse desde el subcomando "migrate".
+func OpenUnchecked(driver, dsn string) (*DB, error) {
+
+	d, err := dialectFor(driver)
+	if err != nil {
+		return nil, err
+	}
+	if d.name == DriverSQLite {
+		dsn = sqliteDSN(dsn)
+	}
+
+	// Inicializa la conexión con el driver del motor
+	// NOTA: sql.Open NO abre realmente la conexión todavía
+	sqlDB, err := sql.Open(d.driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// ---------------- POOL DE CONEXIONES ----------------
+
+	// Tiempo máximo que una conexión puede vivir antes de reciclarse
+	sqlDB.SetConnMaxLifetime(5 * time.Minute)
+
+	if d.name == DriverSQLite {
+		// SQLite admite un solo escritor: una conexión evita "database is locked"
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetMaxIdleConns(1)
+	} else {
+		// Máximo de conexiones abiertas simultáneamente
+		sqlDB.SetMaxOpenConns(25)
+
+		// Máximo de conexiones inactivas en el pool
+		sqlDB.SetMaxIdleConns(10)
+	}
+
+	// ---------------- HEALTH CHECK ----------------
+
+	// Contexto con timeout para evitar bloqueos
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// Ping fuerza una conexión real a la base
+	// Si falla aquí, la app no debe continuar
+	if err := sqlDB.PingContext(ctx); err != nil {
+		_ = sqlDB.Close() // cierre defensivo
+		return nil, err
+	}
+
+	// Si todo salió bien, retornamos nuestro wrapper DB
+	return &DB{SQL: sqlDB, Driver: d.name, d: d}, nil
+}
+
+// sqliteDSN agrega los pragmas que la app necesita a la ruta del archivo:
+// - foreign_keys: SQLite no aplica ON DELETE CASCADE si no se activa
+// - busy_timeout: espera en vez de fallar ante un bloqueo breve
+// - journal_mode(WAL): lecturas concurrentes con una escritura
+func sqliteDSN(path string) string {
+	if strings.Contains(path, "_pragma=") {
+		return path // el usuario ya definió sus pragmas
+	}
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	if !strings.HasPrefix(path, "file:") {
+		path = "file:" + path
+	}
+	return path + sep + "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+}
+
+// Repos agrupa los repositorios del motor abierto.
+type Repos struct {
+	Users       *SQLUserRepo
+	Books       *SQLBookRepo
+	Access      *SQLAccessRepo
+	Sessions    *SQLSessionRepo
+	Tokens      *SQLAPITokenRepo
+	Authors     *SQLAuthorRepo
+	Taxonomy    *SQLTaxonomyRepo
+	Circulation *SQLCirculationRepo
+	Relations   *SQLRelationRepo
+	Files       *SQLFileRepo
+	Covers      *SQLCoverRepo
+	Progress    *SQLProgressRepo
+	Annotations *SQLAnnotationRepo
+	Reviews     *SQLReviewRepo
+}
+
+// Repos construye los repositorios con el dialecto de la conexión.
+func (db *DB) Repos() Repos {
+	return Repos{
+		Users:       &SQLUserRepo{db: conn{db.SQL, db.d}},
+		Books:       &SQLBookRepo{db: conn{db.SQL, db.d}},
+		Access:      &SQLAccessRepo{db: conn{db.SQL, db.d}},
+		Sessions:    &SQLSessionRepo{db: conn{db.SQL, db.d}},
+		Tokens:      &SQLAPITokenRepo{db: conn{db.SQL, db.d}},
+		Authors:     &SQLAuthorRepo{db: conn{db.SQL, db.d}},
+		Taxonomy:    &SQLTaxonomyRepo{db: conn{db.SQL, db.d}},
+		Circulation: &SQLCirculationRepo{db: conn{db.SQL, db.d}},
+		Relations:   &SQLRelationRepo{db: conn{db.SQL, db.d}},
+		Files:       &SQLFileRepo{db: conn{db.SQL, db.d}},
+		Covers:      &SQLCoverRepo{db: conn{db.SQL, db.d}},
+		Progress:    &SQLProgressRepo{db: conn{db.SQL, db.d}},
+		Annotations: &SQLAnnotationRepo{db: conn{db.SQL, db.d}},
+		Reviews:     &SQLReviewRepo{db: conn{db.SQL, db.d}},
+	}
+}
@@ -0,0 +1,282 @@
+package db // Infraestructura DB: diferencias entre motores SQL
+
+import (
+	"context"      // Para timeouts/cancelación
+	"database/sql" // API estándar de SQL
+	"fmt"          // Errores con contexto
+	"strconv"      // Numeración de placeholders ($1, $2...)
+	"strings"      // Reescritura de placeholders y parseo de fechas
+	"time"         // Lectura de fechas
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain" // Errores del dominio
+)
+
+// Nombres de motor aceptados en DB_DRIVER.
+const (
+	DriverMySQL    = "mysql"
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// dialect encapsula lo que cambia entre motores; los repositorios SQL
+// escriben las consultas con "?" y delegan aquí el resto.
+type dialect struct {
+	name       string // nombre lógico (directorio de migraciones)
+	driverName string // nombre registrado en database/sql
+
+	// dollarParams: el motor usa $1, $2... en vez de "?" (PostgreSQL).
+	dollarParams bool
+
+	// returningID: el motor no soporta LastInsertId; se usa RETURNING id.
+	returningID bool
+
+	// ilike: el motor compara sin distinguir mayúsculas con ILIKE.
+	ilike bool
+
+	// fullText: books tiene la columna search_vector (tsvector) indexada.
+	fullText bool
+
+	// timestampType es el tipo de columna para fechas en schema_migrations.
+	timestampType string
+
+	// isDuplicate detecta violaciones de clave única del motor.
+	isDuplicate func(err error) bool
+}
+
+var (
+	mysqlDialect = dialect{
+		name: DriverMySQL, driverName: "mysql",
+		timestampType: "DATETIME", isDuplicate: isMySQLDuplicate,
+	}
+	sqliteDialect = dialect{
+		name: DriverSQLite, driverName: "sqlite",
+		timestampType: "DATETIME", isDuplicate: isSQLiteDuplicate,
+	}
+	postgresDialect = dialect{
+		name: DriverPostgres, driverName: "pgx",
+		dollarParams: true, returningID: true, ilike: true, fullText: true,
+		timestampType: "TIMESTAMPTZ", isDuplicate: isPostgresDuplicate,
+	}
+)
+
+// dialectFor resuelve el dialecto a partir del nombre del driver.
+func dialectFor(driver string) (dialect, error) {
+	switch strings.ToLower(strings.TrimSpace(driver)) {
+	case DriverMySQL:
+		return mysqlDialect, nil
+	case DriverSQLite, "sqlite3":
+		return sqliteDialect, nil
+	case DriverPostgres, "postgresql", "pgx":
+		return postgresDialect, nil
+	}
+	return dialect{}, fmt.Errorf("unsupported DB_DRIVER %q", driver)
+}
+
+// rebind reescribe los "?" como $1, $2... cuando el motor lo requiere.
+// Respeta los literales entre comillas simples.
+func (d dialect) rebind(query string) string {
+	if !d.dollarParams || !strings.Contains(query, "?") {
+		return query
+	}
+	var (
+		b       strings.Builder
+		n       int
+		inQuote bool
+	)
+	b.Grow(len(query) + 8)
+	for _, r := range query {
+		switch {
+		case r == '\'':
+			inQuote = !inQuote
+		case r == '?' && !inQuote:
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// like arma una comparación "contiene" sin distinguir mayúsculas.
+// El patrón (argumento) debe venir ya en minúsculas.
+func (d dialect) like(column string) string {
+	if d.ilike {
+		return column + " ILIKE ?"
+	}
+	return "LOWER(" + column + ") LIKE ?"
+}
+
+// insertIgnore arma un INSERT que no falla si choca con una clave única
+// (la fila existente queda igual). into es "tabla (columnas)".
+func (d dialect) insertIgnore(into, values string) string {
+	switch d.name {
+	case DriverMySQL:
+		return "INSERT IGNORE INTO " + into + " VALUES " + values
+	case DriverSQLite:
+		return "INSERT OR IGNORE INTO " + into + " VALUES " + values
+	}
+	return "INSERT INTO " + into + " VALUES " + values + " ON CONFLICT DO NOTHING"
+}
+
+// translate convierte errores del motor en errores del dominio.
+func (d dialect) translate(err error) error {
+	if d.isDuplicate(err) {
+		return domain.ErrDuplicate
+	}
+	return err
+}
+
+// conn envuelve *sql.DB: reescribe placeholders según el dialecto, de modo
+// que los repositorios escriben una sola versión de cada consulta.
+type conn struct {
+	*sql.DB
+	d dialect
+}
+
+func (c conn) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return c.DB.ExecContext(ctx, c.d.rebind(query), args...)
+}
+
+func (c conn) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return c.DB.QueryContext(ctx, c.d.rebind(query), args...)
+}
+
+func (c conn) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return c.DB.QueryRowContext(ctx, c.d.rebind(query), args...)
+}
+
+// insert ejecuta un INSERT y retorna el ID autoincremental generado.
+// Una clave duplicada se traduce a domain.ErrDuplicate.
+func (c conn) insert(ctx context.Context, query string, args ...any) (uint64, error) {
+	if c.d.returningID {
+		var id uint64
+		if err := c.QueryRowContext(ctx, query+" RETURNING id", args...).Scan(&id); err != nil {
+			return 0, c.d.translate(err)
+		}
+		return id, nil
+	}
+
+	res, err := c.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, c.d.translate(err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return uint64(id), nil
+}
+
+// exec ejecuta una sentencia sin resultado traduciendo la clave duplicada.
+func (c conn) exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	res, err := c.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, c.d.translate(err)
+	}
+	return res, nil
+}
+
+// txConn es conn dentro de una transacción (misma reescritura de placeholders).
+type txConn struct {
+	*sql.Tx
+	d dialect
+}
+
+func (t txConn) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return t.Tx.ExecContext(ctx, t.d.rebind(query), args...)
+}
+
+func (t txConn) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return t.Tx.QueryContext(ctx, t.d.rebind(query), args...)
+}
+
+func (t txConn) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return t.Tx.QueryRowContext(ctx, t.d.rebind(query), args...)
+}
+
+// insert ejecuta un INSERT y retorna el ID generado (ver conn.insert).
+func (t txConn) insert(ctx context.Context, query string, args ...any) (uint64, error) {
+	if t.d.returningID {
+		var id uint64
+		if err := t.QueryRowContext(ctx, query+" RETURNING id", args...).Scan(&id); err != nil {
+			return 0, t.d.translate(err)
+		}
+		return id, nil
+	}
+
+	res, err := t.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, t.d.translate(err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return uint64(id), nil
+}
+
+// exec ejecuta una sentencia sin resultado traduciendo la clave duplicada.
+func (t txConn) exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	res, err := t.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, t.d.translate(err)
+	}
+	return res, nil
+}
+
+// inTx ejecuta fn en una transacción: commit si fn no falla, rollback si falla.
+func (c conn) inTx(ctx context.Context, fn func(tx txConn) error) error {
+	tx, err := c.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(txConn{tx, c.d}); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// dbTime lee fechas de cualquier motor: MySQL (parseTime=true) y PostgreSQL
+// entregan time.Time, mientras SQLite puede entregar texto (p. ej. en COALESCE).
+type dbTime struct{ time.Time }
+
+// Formatos de texto que producen SQLite (CURRENT_TIMESTAMP) y el driver.
+var dbTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02",
+}
+
+// Scan implementa sql.Scanner.
+func (t *dbTime) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		t.Time = time.Time{}
+		return nil
+	case time.Time:
+		t.Time = v
+		return nil
+	case []byte:
+		return t.parse(string(v))
+	case string:
+		return t.parse(v)
+	}
+	return fmt.Errorf("cannot scan %T into time", src)
+}
+
+func (t *dbTime) parse(s string) error {
+	s = strings.TrimSpace(s)
+	for _, layout := range dbTimeLayouts {
+		if v, err := time.Parse(layout, s); err == nil {
+			t.Time = v
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot parse time %q", s)
+}
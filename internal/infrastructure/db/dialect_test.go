@@ -0,0 +1,48 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+func TestRebind(t *testing.T) {
+	q := `SELECT id FROM books WHERE COALESCE(description,'?')=? AND id=?`
+
+	if got := mysqlDialect.rebind(q); got != q {
+		t.Fatalf("mysql must keep ?, got %q", got)
+	}
+	want := `SELECT id FROM books WHERE COALESCE(description,'?')=$1 AND id=$2`
+	if got := postgresDialect.rebind(q); got != want {
+		t.Fatalf("postgres rebind:\n got %q\nwant %q", got, want)
+	}
+}
+
+// pgErr imita el error del driver de PostgreSQL (método SQLState).
+type pgErr struct{ code string }
+
+func (e pgErr) Error() string    { return "pg error " + e.code }
+func (e pgErr) SQLState() string { return e.code }
+
+func TestDuplicateTranslation(t *testing.T) {
+	cases := []struct {
+		d   dialect
+		err error
+		dup bool
+	}{
+		{mysqlDialect, errors.New("Error 1062 (23000): Duplicate entry 'a' for key 'uq_users_email'"), true},
+		{mysqlDialect, errors.New("Error 1146: Table doesn't exist"), false},
+		{sqliteDialect, errors.New("constraint failed: UNIQUE constraint failed: users.email (2067)"), true},
+		{sqliteDialect, errors.New("no such table: users"), false},
+		{postgresDialect, fmt.Errorf("insert: %w", pgErr{"23505"}), true},
+		{postgresDialect, pgErr{"23503"}, false},
+	}
+	for _, c := range cases {
+		got := errors.Is(c.d.translate(c.err), domain.ErrDuplicate)
+		if got != c.dup {
+			t.Errorf("%s: %q duplicate=%v, want %v", c.d.name, c.err, got, c.dup)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package db // Infraestructura DB: metadatos de los adjuntos de un libro
+
+import (
+	"context"      // Para timeouts/cancelación
+	"database/sql" // Driver SQL estándar
+	"errors"       // Para comparar errores (errors.Is)
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain" // Dominio (BookFile + errores)
+)
+
+// SQLFileRepo persiste los metadatos de los adjuntos (tabla book_files).
+// El contenido lo guarda el BlobStore.
+type SQLFileRepo struct {
+	db conn // conexión + dialecto del motor
+}
+
+// NewMySQLFileRepo inyecta la conexión (MySQL).
+func NewMySQLFileRepo(db *sql.DB) *SQLFileRepo {
+	return &SQLFileRepo{db: conn{db, mysqlDialect}}
+}
+
+// NewSQLiteFileRepo inyecta la conexión (SQLite).
+func NewSQLiteFileRepo(db *sql.DB) *SQLFileRepo {
+	return &SQLFileRepo{db: conn{db, sqliteDialect}}
+}
+
+const fileColumns = `f.id,f.book_id,f.name,f.content_type,f.kind,f.size,f.sha256,COALESCE(f.uploaded_by,0),f.created_at`
+
+// Create verifica que exista el libro e inserta el adjunto (la restricción
+// única libro+hash traduce los duplicados a ErrDuplicate).
+func (r *SQLFileRepo) Create(ctx context.Context, f *domain.BookFile) (uint64, error) {
+	var id uint64
+	err := r.db.inTx(ctx, func(tx txConn) error {
+		if err := tx.mustExist(ctx, "books", f.BookID()); err != nil {
+			return err
+		}
+		var err error
+		id, err = tx.insert(ctx,
+			`INSERT INTO book_files (book_id,name,content_type,kind,size,sha256,uploaded_by) VALUES (?,?,?,?,?,?,?)`,
+			f.BookID(), f.Name(), f.ContentType(), string(f.Kind()), f.Size(), f.SHA256(), nullableID(f.UploadedBy()))
+		return err
+	})
+	return id, err
+}
+
+// GetByID busca un adjunto por ID.
+func (r *SQLFileRepo) GetByID(ctx context.Context, id uint64) (*domain.BookFile, error) {
+	return scanFile(r.db.QueryRowContext(ctx, `SELECT `+fileColumns+` FROM book_files f WHERE f.id=?`, id))
+}
+
+// ByBook retorna los adjuntos del libro por tipo y nombre.
+func (r *SQLFileRepo) ByBook(ctx context.Context, bookID uint64) ([]*domain.BookFile, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+fileColumns+` FROM book_files f WHERE f.book_id=? ORDER BY f.kind, LOWER(f.name), f.id`, bookID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []*domain.BookFile{}
+	for rows.Next() {
+		f, err := scanFile(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, f)
+	}
+	return out, rows.Err()
+}
+
+// CountBySHA cuenta los adjuntos que apuntan al mismo contenido.
+func (r *SQLFileRepo) CountBySHA(ctx context.Context, sha256 string) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM book_files WHERE sha256=?`, sha256).Scan(&n)
+	return n, err
+}
+
+// Delete elimina el adjunto.
+func (r *SQLFileRepo) Delete(ctx context.Context, id uint64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM book_files WHERE id=?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// scanFile convierte una fila (fileColumns) en entidad.
+func scanFile(row interface{ Scan(dest ...any) error }) (*domain.BookFile, error) {
+	var (
+		id, bookID, uploadedBy  uint64
+		name, contentType, kind string
+		size                    int64
+		sha                     string
+		createdAt               dbTime
+	)
+	if err := row.Scan(&id, &bookID, &name, &contentType, &kind, &size, &sha, &uploadedBy, &createdAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return domain.HydrateBookFile(id, bookID, name, contentType, domain.FileKind(kind), size, sha, uploadedBy, createdAt.Time), nil
+}
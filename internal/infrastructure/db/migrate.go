@@ -0,0 +1,382 @@
+package db // Infraestructura DB: migraciones versionadas del esquema
+
+import (
+	"context"       // Timeouts/cancelación
+	"crypto/sha256" // Checksum de cada archivo .up.sql
+	"database/sql"  // API estándar de SQL
+	"embed"         // Migraciones embebidas en el binario
+	"encoding/hex"  // Checksum en hexadecimal
+	"errors"        // Errores centinela
+	"fmt"           // Errores con contexto
+	"io/fs"         // Recorrido del FS embebido
+	"path"          // Rutas dentro del FS embebido
+	"sort"          // Orden por versión
+	"strconv"       // Versión numérica desde el nombre
+	"strings"       // Parseo de nombres y sentencias
+	"time"          // Fecha de aplicación
+)
+
+// migrationsFS contiene los archivos NNNN_nombre.up.sql / .down.sql por motor.
+//
+//go:embed migrations
+var migrationsFS embed.FS
+
+// Errores del subsistema de migraciones.
+var (
+	// ErrSchemaOutdated indica que hay migraciones pendientes de aplicar.
+	ErrSchemaOutdated = errors.New("database schema is out of date; run: migrate up")
+
+	// ErrChecksumMismatch indica que una migración aplicada fue modificada después.
+	ErrChecksumMismatch = errors.New("applied migration checksum mismatch")
+
+	// ErrUnknownMigration indica que la BD tiene versiones que el binario no conoce.
+	ErrUnknownMigration = errors.New("database has migrations unknown to this binary")
+
+	// ErrSchemaMismatch indica que las tablas que ya existían antes de 0001
+	// no coinciden con su esquema (ver baseline).
+	ErrSchemaMismatch = errors.New("existing tables do not match the base schema")
+)
+
+// Migration es un paso versionado del esquema.
+type Migration struct {
+	Version  int    // Número creciente (prefijo del archivo)
+	Name     string // Nombre descriptivo
+	Up       string // SQL para aplicar
+	Down     string // SQL para revertir
+	Checksum string // SHA-256 del SQL "up"
+
+	// data copia datos que el SQL no puede transformar solo (p. ej. slugs sin
+	// acentos); corre después de Up, en la misma transacción. Ver dataSteps.
+	data func(ctx context.Context, tx txConn) error
+}
+
+// dataSteps son los pasos en Go de algunas versiones, por número de versión.
+// En MySQL el DDL previo ya hizo commit, así que deben poder repetirse.
+var dataSteps = map[int]func(ctx context.Context, tx txConn) error{
+	5: backfillTaxonomy,
+}
+
+// MigrationStatus describe el estado de una migración en una BD concreta.
+type MigrationStatus struct {
+	Migration
+	Applied   bool      // Ya aplicada
+	AppliedAt time.Time // Cuándo se aplicó (si Applied)
+	Modified  bool      // El checksum guardado no coincide con el archivo
+}
+
+// Migrator aplica y revierte migraciones sobre una conexión.
+type Migrator struct {
+	db         conn
+	migrations []Migration
+}
+
+// NewMigrator carga las migraciones embebidas del motor indicado ("mysql", "sqlite", "postgres").
+func NewMigrator(db *sql.DB, driver string) (*Migrator, error) {
+	d, err := dialectFor(driver)
+	if err != nil {
+		return nil, err
+	}
+	list, err := loadMigrations(migrationsFS, path.Join("migrations", d.name))
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		list[i].data = dataSteps[list[i].Version]
+	}
+	return &Migrator{db: conn{db, d}, migrations: list}, nil
+}
+
+// Migrations devuelve las migraciones conocidas (ordenadas por versión).
+func (m *Migrator) Migrations() []Migration { return append([]Migration{}, m.migrations...) }
+
+// Status combina las migraciones conocidas con lo registrado en schema_migrations.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]MigrationStatus, 0, len(m.migrations))
+	for _, mg := range m.migrations {
+		st := MigrationStatus{Migration: mg}
+		if a, ok := applied[mg.Version]; ok {
+			st.Applied = true
+			st.AppliedAt = a.appliedAt
+			st.Modified = a.checksum != mg.Checksum
+		}
+		out = append(out, st)
+	}
+	return out, nil
+}
+
+// Verify confirma que la BD tiene exactamente las migraciones del binario.
+// Es lo que usa Open para negarse a arrancar contra un esquema desactualizado.
+func (m *Migrator) Verify(ctx context.Context) error {
+	if err := m.ensureTable(ctx); err != nil {
+		return err
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+
+	known := map[int]bool{}
+	pending := 0
+	for _, mg := range m.migrations {
+		known[mg.Version] = true
+		a, ok := applied[mg.Version]
+		if !ok {
+			pending++
+			continue
+		}
+		if a.checksum != mg.Checksum {
+			return fmt.Errorf("%w: %04d_%s", ErrChecksumMismatch, mg.Version, mg.Name)
+		}
+	}
+	for v := range applied {
+		if !known[v] {
+			return fmt.Errorf("%w: version %d", ErrUnknownMigration, v)
+		}
+	}
+	if pending > 0 {
+		return fmt.Errorf("%w (%d pending)", ErrSchemaOutdated, pending)
+	}
+	return nil
+}
+
+// Up aplica en orden todas las migraciones pendientes.
+// Retorna las migraciones aplicadas en esta llamada.
+//
+// Cada migración (script, paso de datos y registro en schema_migrations) va
+// en su propia transacción: en SQLite y PostgreSQL el DDL es transaccional y
+// una migración que falla no deja nada aplicado. En MySQL cada sentencia DDL
+// hace commit implícito y la transacción solo asegura una misma conexión (la
+// de las variables @ddl); por eso sus scripts se escriben para poder
+// repetirse (CREATE TABLE IF NOT EXISTS, ALTER con guarda) y el siguiente Up
+// retoma una migración que falló a medias. Antes de 0001 corre la línea
+// base (ver baseline) por si la BD ya tenía tablas.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	done := []Migration{}
+	for _, mg := range m.migrations {
+		if a, ok := applied[mg.Version]; ok {
+			if a.checksum != mg.Checksum {
+				return done, fmt.Errorf("%w: %04d_%s", ErrChecksumMismatch, mg.Version, mg.Name)
+			}
+			continue
+		}
+		err := m.db.inTx(ctx, func(tx txConn) error {
+			script := mg.Up
+			if mg.Version == 1 {
+				var err error
+				if script, err = baseline(ctx, tx, script); err != nil {
+					return fmt.Errorf("migration %04d_%s baseline: %w", mg.Version, mg.Name, err)
+				}
+			}
+			if err := exec(ctx, tx, script); err != nil {
+				return fmt.Errorf("migration %04d_%s up: %w", mg.Version, mg.Name, err)
+			}
+			if mg.data != nil {
+				if err := mg.data(ctx, tx); err != nil {
+					return fmt.Errorf("migration %04d_%s data: %w", mg.Version, mg.Name, err)
+				}
+			}
+			_, err := tx.ExecContext(ctx,
+				`INSERT INTO schema_migrations (version,name,checksum,applied_at) VALUES (?,?,?,?)`,
+				mg.Version, mg.Name, mg.Checksum, time.Now().UTC(),
+			)
+			return err
+		})
+		if err != nil {
+			return done, err
+		}
+		done = append(done, mg)
+	}
+	return done, nil
+}
+
+// Down revierte las últimas `steps` migraciones aplicadas (en orden inverso),
+// cada una en su transacción como en Up.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		return nil, fmt.Errorf("steps must be positive")
+	}
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	done := []Migration{}
+	for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		mg := m.migrations[i]
+		if _, ok := applied[mg.Version]; !ok {
+			continue
+		}
+		if strings.TrimSpace(mg.Down) == "" {
+			return done, fmt.Errorf("migration %04d_%s has no down script", mg.Version, mg.Name)
+		}
+		err := m.db.inTx(ctx, func(tx txConn) error {
+			if err := exec(ctx, tx, mg.Down); err != nil {
+				return fmt.Errorf("migration %04d_%s down: %w", mg.Version, mg.Name, err)
+			}
+			_, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version=?`, mg.Version)
+			return err
+		})
+		if err != nil {
+			return done, err
+		}
+		done = append(done, mg)
+	}
+	return done, nil
+}
+
+// -------------------- internos --------------------
+
+type appliedRow struct {
+	checksum  string
+	appliedAt time.Time
+}
+
+// ensureTable crea la tabla de control si no existe.
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	_, err := m.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+  version BIGINT NOT NULL,
+  name VARCHAR(200) NOT NULL,
+  checksum CHAR(64) NOT NULL,
+  applied_at `+m.db.d.timestampType+` NOT NULL,
+  PRIMARY KEY (version)
+)`)
+	return err
+}
+
+// applied lee las versiones registradas.
+func (m *Migrator) applied(ctx context.Context) (map[int]appliedRow, error) {
+	rows, err := m.db.QueryContext(ctx, `SELECT version,checksum,applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := map[int]appliedRow{}
+	for rows.Next() {
+		var (
+			v int
+			a appliedRow
+		)
+		var at dbTime
+		if err := rows.Scan(&v, &a.checksum, &at); err != nil {
+			return nil, err
+		}
+		a.appliedAt = at.Time
+		out[v] = a
+	}
+	return out, rows.Err()
+}
+
+// exec ejecuta un script sentencia por sentencia en la transacción.
+func exec(ctx context.Context, tx txConn, script string) error {
+	for _, stmt := range splitStatements(script) {
+		// DDL literal: se usa el *sql.Tx directo, sin reescribir placeholders
+		if _, err := tx.Tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitStatements separa un script por ";" al final de línea, ignorando
+// comentarios "--". Suficiente para DDL simple (sin procedimientos).
+func splitStatements(script string) []string {
+	var (
+		out []string
+		cur strings.Builder
+	)
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		cur.WriteString(line)
+		cur.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			stmt := strings.TrimSuffix(strings.TrimSpace(cur.String()), ";")
+			if stmt != "" {
+				out = append(out, stmt)
+			}
+			cur.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(cur.String()); rest != "" {
+		out = append(out, rest)
+	}
+	return out
+}
+
+// loadMigrations lee y valida los archivos de un directorio embebido.
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("migrations for %q: %w", path.Base(dir), err)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".sql") {
+			continue
+		}
+
+		// Formato: NNNN_nombre.up.sql | NNNN_nombre.down.sql
+		base := strings.TrimSuffix(e.Name(), ".sql")
+		direction := path.Ext(base) // ".up" / ".down"
+		base = strings.TrimSuffix(base, direction)
+		num, name, ok := strings.Cut(base, "_")
+		version, convErr := strconv.Atoi(num)
+		if !ok || convErr != nil || (direction != ".up" && direction != ".down") {
+			return nil, fmt.Errorf("invalid migration file name %q", e.Name())
+		}
+
+		body, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		mg := byVersion[version]
+		if mg == nil {
+			mg = &Migration{Version: version, Name: name}
+			byVersion[version] = mg
+		}
+		if mg.Name != name {
+			return nil, fmt.Errorf("migration %d has inconsistent names %q / %q", version, mg.Name, name)
+		}
+		if direction == ".up" {
+			sum := sha256.Sum256(body)
+			mg.Up = string(body)
+			mg.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			mg.Down = string(body)
+		}
+	}
+
+	out := make([]Migration, 0, len(byVersion))
+	for _, mg := range byVersion {
+		if mg.Up == "" {
+			return nil, fmt.Errorf("migration %04d_%s has no up script", mg.Version, mg.Name)
+		}
+		out = append(out, *mg)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out, nil
+}
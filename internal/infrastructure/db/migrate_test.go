@@ -0,0 +1,234 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestEmbeddedMigrationsLoad(t *testing.T) {
+	var want int
+	for _, driver := range []string{DriverMySQL, DriverSQLite, DriverPostgres} {
+		list, err := loadMigrations(migrationsFS, "migrations/"+driver)
+		if err != nil {
+			t.Fatalf("load %s: %v", driver, err)
+		}
+		if len(list) == 0 {
+			t.Fatalf("expected embedded migrations for %s", driver)
+		}
+		// Todos los motores deben tener las mismas versiones
+		if want == 0 {
+			want = len(list)
+		} else if len(list) != want {
+			t.Fatalf("%s has %d migrations, expected %d", driver, len(list), want)
+		}
+		checkMigrations(t, list)
+	}
+}
+
+func checkMigrations(t *testing.T, list []Migration) {
+	t.Helper()
+	for i, mg := range list {
+		if mg.Version != i+1 {
+			t.Fatalf("versions must be consecutive from 1, got %d at position %d", mg.Version, i)
+		}
+		if mg.Down == "" {
+			t.Fatalf("migration %04d_%s has no down script", mg.Version, mg.Name)
+		}
+		if len(mg.Checksum) != 64 {
+			t.Fatalf("unexpected checksum %q", mg.Checksum)
+		}
+	}
+}
+
+func TestLoadMigrationsRejectsBadNames(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/0001_init.up.sql": {Data: []byte("CREATE TABLE a (id INT);")},
+		"m/init.up.sql":      {Data: []byte("CREATE TABLE b (id INT);")},
+	}
+	if _, err := loadMigrations(fsys, "m"); err == nil {
+		t.Fatalf("expected error for file without version")
+	}
+}
+
+func TestLoadMigrationsRequiresUp(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/0001_init.down.sql": {Data: []byte("DROP TABLE a;")},
+	}
+	if _, err := loadMigrations(fsys, "m"); err == nil {
+		t.Fatalf("expected error for migration without up script")
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := `-- comentario
+CREATE TABLE a (
+  id INT
+);
+
+ALTER TABLE a ADD COLUMN b INT;
+`
+	got := splitStatements(script)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 statements, got %d: %q", len(got), got)
+	}
+	if got[1] != "ALTER TABLE a ADD COLUMN b INT" {
+		t.Fatalf("unexpected statement %q", got[1])
+	}
+}
+
+func TestUpIsAtomicPerMigration(t *testing.T) {
+	ctx := context.Background()
+	d, err := OpenUnchecked(DriverSQLite, filepath.Join(t.TempDir(), "atomic.db"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer d.SQL.Close()
+
+	fsys := fstest.MapFS{
+		"m/0001_a.up.sql":   {Data: []byte("CREATE TABLE a (id INT);")},
+		"m/0001_a.down.sql": {Data: []byte("DROP TABLE a;")},
+		"m/0002_b.up.sql":   {Data: []byte("CREATE TABLE b (id INT);\nALTER TABLE a ADD COLUMN x INT;\nALTER TABLE a ADD COLUMN x INT;")},
+		"m/0002_b.down.sql": {Data: []byte("DROP TABLE b;")},
+	}
+	list, err := loadMigrations(fsys, "m")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	m := &Migrator{db: conn{d.SQL, d.d}, migrations: list}
+
+	// 0002 falla en su última sentencia: no queda nada de ella, ni registrada
+	done, err := m.Up(ctx)
+	if err == nil || len(done) != 1 {
+		t.Fatalf("expected 0002 to fail after applying 0001, got %v, %v", done, err)
+	}
+	var n int
+	if err := d.SQL.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name='b'`).Scan(&n); err != nil || n != 0 {
+		t.Fatalf("expected table b rolled back, got %d, %v", n, err)
+	}
+	if _, err := d.SQL.Exec(`SELECT x FROM a`); err == nil {
+		t.Fatalf("expected column a.x rolled back")
+	}
+	status, err := m.Status(ctx)
+	if err != nil || !status[0].Applied || status[1].Applied {
+		t.Fatalf("unexpected status %+v, %v", status, err)
+	}
+
+	// Corregido el script, el siguiente Up la aplica entera
+	m.migrations[1].Up = "CREATE TABLE b (id INT);\nALTER TABLE a ADD COLUMN x INT;"
+	if done, err := m.Up(ctx); err != nil || len(done) != 1 {
+		t.Fatalf("retry: %v, %v", done, err)
+	}
+}
+
+func TestBaselineAdoptsExistingTables(t *testing.T) {
+	ctx := context.Background()
+	open := func(t *testing.T, legacy string) (*DB, *Migrator) {
+		t.Helper()
+		d, err := OpenUnchecked(DriverSQLite, filepath.Join(t.TempDir(), "legacy.db"))
+		if err != nil {
+			t.Fatalf("open: %v", err)
+		}
+		t.Cleanup(func() { _ = d.SQL.Close() })
+		for _, stmt := range splitStatements(legacy) {
+			if _, err := d.SQL.Exec(stmt); err != nil {
+				t.Fatalf("legacy schema: %v", err)
+			}
+		}
+		m, err := NewMigrator(d.SQL, d.Driver)
+		if err != nil {
+			t.Fatalf("migrator: %v", err)
+		}
+		return d, m
+	}
+
+	// Tablas de un esquema viejo: se conservan con sus datos y se crea el resto
+	d, m := open(t, `CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, name VARCHAR(120) NOT NULL,
+  email VARCHAR(180) NOT NULL, role VARCHAR(20) NOT NULL, active INTEGER NOT NULL DEFAULT 1,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, updated_at TIMESTAMP NULL);
+CREATE TABLE books (id INTEGER PRIMARY KEY AUTOINCREMENT, title VARCHAR(200) NOT NULL, author VARCHAR(160) NOT NULL,
+  year INTEGER NOT NULL, isbn VARCHAR(50) NOT NULL, category VARCHAR(80) NOT NULL, tags VARCHAR(255) NOT NULL DEFAULT '',
+  description TEXT NULL, active INTEGER NOT NULL DEFAULT 1,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, updated_at TIMESTAMP NULL);
+INSERT INTO books (title,author,year,isbn,category,tags) VALUES ('Rayuela','Julio Cortázar',1963,'9788437604572','Novela','clásico');`)
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("up over legacy tables: %v", err)
+	}
+	var title, category string
+	if err := d.SQL.QueryRow(`SELECT b.title, c.name FROM books b JOIN categories c ON c.id=b.category_id`).Scan(&title, &category); err != nil || title != "Rayuela" || category != "Novela" {
+		t.Fatalf("legacy book lost: %q %q %v", title, category, err)
+	}
+	var tag string
+	if err := d.SQL.QueryRow(`SELECT t.name FROM book_tags bt JOIN tags t ON t.id=bt.tag_id`).Scan(&tag); err != nil || tag != "clásico" {
+		t.Fatalf("legacy tags not copied: %q %v", tag, err)
+	}
+	if _, err := d.SQL.Exec(`SELECT duration_seconds FROM access_events`); err != nil {
+		t.Fatalf("expected access_events created: %v", err)
+	}
+
+	// Una columna de 0001 que falta: no se aplica nada
+	_, m = open(t, `CREATE TABLE books (id INTEGER PRIMARY KEY AUTOINCREMENT, title VARCHAR(220) NOT NULL);`)
+	if _, err := m.Up(ctx); !errors.Is(err, ErrSchemaMismatch) {
+		t.Fatalf("expected schema mismatch, got %v", err)
+	}
+	if status, err := m.Status(ctx); err != nil || status[0].Applied {
+		t.Fatalf("expected nothing applied, got %+v, %v", status, err)
+	}
+}
+
+func TestParseColumnType(t *testing.T) {
+	cases := []struct {
+		kind string
+		size int
+		want columnType
+	}{
+		{"varchar", 200, columnType{"varchar", 200}},           // MySQL
+		{"enum", 9, columnType{"enum", 9}},                     // MySQL
+		{"character varying", 220, columnType{"varchar", 220}}, // PostgreSQL
+		{"VARCHAR(120)", 0, columnType{"varchar", 120}},        // SQLite
+		{"INTEGER", 0, columnType{"integer", 0}},
+	}
+	for _, c := range cases {
+		if got := parseColumnType(c.kind, c.size); got != c.want {
+			t.Errorf("parseColumnType(%q, %d) = %+v, want %+v", c.kind, c.size, got, c.want)
+		}
+	}
+}
+
+// Las reglas de 0005 están congeladas: este test fija lo que producen para
+// que un cambio en el dominio no altere una migración ya publicada.
+func TestTaxonomyBackfillRules(t *testing.T) {
+	slugs := map[string]string{
+		"Ciencia Ficción": "ciencia-ficcion",
+		"  C++ / C# ":     "c++-c#",
+		"Año 2000":        "ano-2000",
+		"¡¿…?!":           "",
+	}
+	for in, want := range slugs {
+		if got := legacySlug(in); got != want {
+			t.Errorf("legacySlug(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	categories := map[string]string{
+		"  Ciencia   ficción ":   "Ciencia ficción",
+		"X":                      legacyCategory,
+		"--":                     legacyCategory,
+		strings.Repeat("a", 121): legacyCategory,
+	}
+	for in, want := range categories {
+		if got := legacyCategoryName(in); got != want {
+			t.Errorf("legacyCategoryName(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	long := strings.Repeat("b", 59) + " c"
+	got := legacyTags(" Ciencia  Ficción ,ciencia-ficcion,,--, " + long)
+	if want := []string{"ciencia ficción", strings.Repeat("b", 59)}; !reflect.DeepEqual(got, want) {
+		t.Errorf("legacyTags = %q, want %q", got, want)
+	}
+}
@@ -1,19 +1,21 @@
-CREATE DATABASE IF NOT EXISTS libros_poo CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci;
-USE libros_poo;
+-- Esquema base: usuarios, libros y eventos de acceso.
+-- Unifica los antiguos scripts/schema.sql y docs/schema.sql. Si las tablas ya
+-- existen (BD creada con esos scripts) no se crean de nuevo: la línea base
+-- (baseline.go) verifica y ajusta sus columnas antes de registrar 0001.
 
-CREATE TABLE IF NOT EXISTS users (
+CREATE TABLE users (
   id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
   name VARCHAR(120) NOT NULL,
   email VARCHAR(180) NOT NULL,
-  role ENUM('ADMIN','READER','CONSULTOR') NOT NULL,
+  role VARCHAR(20) NOT NULL,
   active TINYINT(1) NOT NULL DEFAULT 1,
   created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
   updated_at TIMESTAMP NULL DEFAULT NULL ON UPDATE CURRENT_TIMESTAMP,
   PRIMARY KEY (id),
   UNIQUE KEY uq_users_email (email)
-) ENGINE=InnoDB;
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
 
-CREATE TABLE IF NOT EXISTS books (
+CREATE TABLE books (
   id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
   title VARCHAR(220) NOT NULL,
   author VARCHAR(180) NOT NULL,
@@ -21,7 +23,7 @@ CREATE TABLE IF NOT EXISTS books (
   isbn VARCHAR(80) NOT NULL,
   category VARCHAR(120) NOT NULL,
   tags VARCHAR(600) NOT NULL DEFAULT '',
-  description TEXT,
+  description TEXT NULL,
   active TINYINT(1) NOT NULL DEFAULT 1,
   created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
   updated_at TIMESTAMP NULL DEFAULT NULL ON UPDATE CURRENT_TIMESTAMP,
@@ -29,17 +31,17 @@ CREATE TABLE IF NOT EXISTS books (
   UNIQUE KEY uq_books_isbn (isbn),
   KEY idx_books_author (author),
   KEY idx_books_category (category)
-) ENGINE=InnoDB;
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
 
-CREATE TABLE IF NOT EXISTS access_events (
+CREATE TABLE access_events (
   id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
   user_id BIGINT UNSIGNED NOT NULL,
   book_id BIGINT UNSIGNED NOT NULL,
-  access_type ENUM('APERTURA','LECTURA','DESCARGA') NOT NULL,
+  access_type VARCHAR(20) NOT NULL,
   created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
   PRIMARY KEY (id),
   KEY idx_access_book (book_id),
   KEY idx_access_user (user_id),
   CONSTRAINT fk_access_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
   CONSTRAINT fk_access_book FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE
-) ENGINE=InnoDB;
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS sessions;
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.columns
+  WHERE table_schema = DATABASE() AND table_name = 'users' AND column_name = 'password_hash') > 0,
+  'ALTER TABLE users DROP COLUMN password_hash', 'DO 0');
+PREPARE ddl FROM @ddl;
+EXECUTE ddl;
+DEALLOCATE PREPARE ddl;
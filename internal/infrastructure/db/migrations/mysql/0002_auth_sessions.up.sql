@@ -0,0 +1,21 @@
+-- Credenciales (hash bcrypt) y sesiones de login.
+
+-- MySQL no tiene ADD/DROP COLUMN IF [NOT] EXISTS: el ALTER se arma solo si
+-- hace falta, para que la migración pueda repetirse si falló a medias.
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.columns
+  WHERE table_schema = DATABASE() AND table_name = 'users' AND column_name = 'password_hash') = 0,
+  'ALTER TABLE users ADD COLUMN password_hash VARCHAR(255) NOT NULL DEFAULT '''' AFTER active', 'DO 0');
+PREPARE ddl FROM @ddl;
+EXECUTE ddl;
+DEALLOCATE PREPARE ddl;
+
+CREATE TABLE IF NOT EXISTS sessions (
+  token_hash CHAR(64) NOT NULL,
+  user_id BIGINT UNSIGNED NOT NULL,
+  expires_at DATETIME NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (token_hash),
+  KEY idx_sessions_user (user_id),
+  KEY idx_sessions_expires (expires_at),
+  CONSTRAINT fk_sessions_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB;
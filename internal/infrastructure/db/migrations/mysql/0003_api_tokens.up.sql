@@ -0,0 +1,18 @@
+-- Tokens personales de API (solo se guarda el hash).
+
+CREATE TABLE IF NOT EXISTS api_tokens (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  user_id BIGINT UNSIGNED NOT NULL,
+  name VARCHAR(120) NOT NULL,
+  token_hash CHAR(64) NOT NULL,
+  prefix VARCHAR(20) NOT NULL,
+  scopes VARCHAR(255) NOT NULL,
+  expires_at DATETIME NULL DEFAULT NULL,
+  last_used_at DATETIME NULL DEFAULT NULL,
+  revoked_at DATETIME NULL DEFAULT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  UNIQUE KEY uq_api_tokens_hash (token_hash),
+  KEY idx_api_tokens_user (user_id),
+  CONSTRAINT fk_api_tokens_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB;
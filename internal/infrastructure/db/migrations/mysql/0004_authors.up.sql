@@ -0,0 +1,32 @@
+-- Autores como entidad propia y créditos libro-autor (ordenados y con rol).
+-- books.author se conserva como byline (texto de autoría para mostrar y buscar).
+
+CREATE TABLE IF NOT EXISTS authors (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  name VARCHAR(180) NOT NULL,
+  bio TEXT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NULL DEFAULT NULL ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  KEY idx_authors_name (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS book_authors (
+  book_id BIGINT UNSIGNED NOT NULL,
+  author_id BIGINT UNSIGNED NOT NULL,
+  role VARCHAR(20) NOT NULL,
+  position INT NOT NULL DEFAULT 0,
+  PRIMARY KEY (book_id, author_id, role),
+  KEY idx_book_authors_author (author_id),
+  CONSTRAINT fk_book_authors_book FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE,
+  CONSTRAINT fk_book_authors_author FOREIGN KEY (author_id) REFERENCES authors(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Un autor por cada texto de autoría existente, acreditado como "author"
+-- (sin repetir lo ya copiado si la migración falló a medias)
+INSERT INTO authors (name) SELECT DISTINCT b.author FROM books b
+WHERE NOT EXISTS (SELECT 1 FROM authors a WHERE a.name = b.author);
+
+INSERT INTO book_authors (book_id, author_id, role, position)
+SELECT b.id, a.id, 'author', 0 FROM books b JOIN authors a ON a.name = b.author
+WHERE NOT EXISTS (SELECT 1 FROM book_authors ba WHERE ba.book_id = b.id AND ba.author_id = a.id AND ba.role = 'author');
@@ -0,0 +1,15 @@
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.table_constraints
+  WHERE table_schema = DATABASE() AND table_name = 'books' AND constraint_name = 'fk_books_category') > 0,
+  'ALTER TABLE books DROP FOREIGN KEY fk_books_category', 'DO 0');
+PREPARE ddl FROM @ddl;
+EXECUTE ddl;
+DEALLOCATE PREPARE ddl;
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.columns
+  WHERE table_schema = DATABASE() AND table_name = 'books' AND column_name = 'category_id') > 0,
+  'ALTER TABLE books DROP COLUMN category_id', 'DO 0');
+PREPARE ddl FROM @ddl;
+EXECUTE ddl;
+DEALLOCATE PREPARE ddl;
+DROP TABLE IF EXISTS book_tags;
+DROP TABLE IF EXISTS tags;
+DROP TABLE IF EXISTS categories;
@@ -0,0 +1,47 @@
+-- Etiquetas y categorías como entidades (únicas por slug) y árbol de categorías.
+-- books.category se conserva como nombre canónico (para mostrar, filtrar y
+-- facetas) y books.category_id apunta a la categoría. Los datos existentes
+-- (categoría de texto libre y etiquetas CSV) los copia el paso en Go de esta
+-- versión (backfillTaxonomy), que calcula los slugs sin acentos.
+
+CREATE TABLE IF NOT EXISTS categories (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  name VARCHAR(120) NOT NULL,
+  slug VARCHAR(120) NOT NULL,
+  parent_id BIGINT UNSIGNED NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NULL DEFAULT NULL ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  UNIQUE KEY uq_categories_slug (slug),
+  KEY idx_categories_parent (parent_id),
+  CONSTRAINT fk_categories_parent FOREIGN KEY (parent_id) REFERENCES categories(id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS tags (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  name VARCHAR(60) NOT NULL,
+  slug VARCHAR(60) NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  UNIQUE KEY uq_tags_slug (slug)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS book_tags (
+  book_id BIGINT UNSIGNED NOT NULL,
+  tag_id BIGINT UNSIGNED NOT NULL,
+  position INT NOT NULL DEFAULT 0,
+  PRIMARY KEY (book_id, tag_id),
+  KEY idx_book_tags_tag (tag_id),
+  CONSTRAINT fk_book_tags_book FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE,
+  CONSTRAINT fk_book_tags_tag FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- MySQL no tiene ADD/DROP COLUMN IF [NOT] EXISTS: el ALTER se arma solo si
+-- hace falta, para que la migración pueda repetirse si falló a medias. Es un
+-- solo ALTER (atómico): si la columna ya está, también el índice y la FK.
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.columns
+  WHERE table_schema = DATABASE() AND table_name = 'books' AND column_name = 'category_id') = 0,
+  'ALTER TABLE books ADD COLUMN category_id BIGINT UNSIGNED NULL AFTER category, ADD KEY idx_books_category_id (category_id), ADD CONSTRAINT fk_books_category FOREIGN KEY (category_id) REFERENCES categories(id)', 'DO 0');
+PREPARE ddl FROM @ddl;
+EXECUTE ddl;
+DEALLOCATE PREPARE ddl;
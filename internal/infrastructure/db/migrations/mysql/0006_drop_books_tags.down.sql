@@ -0,0 +1,11 @@
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.columns
+  WHERE table_schema = DATABASE() AND table_name = 'books' AND column_name = 'tags') = 0,
+  'ALTER TABLE books ADD COLUMN tags VARCHAR(600) NOT NULL DEFAULT '''' AFTER category', 'DO 0');
+PREPARE ddl FROM @ddl;
+EXECUTE ddl;
+DEALLOCATE PREPARE ddl;
+
+UPDATE books SET tags = COALESCE(LEFT((
+  SELECT GROUP_CONCAT(t.name ORDER BY bt.position SEPARATOR ',')
+  FROM book_tags bt JOIN tags t ON t.id = bt.tag_id
+  WHERE bt.book_id = books.id), 600), '');
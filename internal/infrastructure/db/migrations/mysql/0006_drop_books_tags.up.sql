@@ -0,0 +1,10 @@
+-- Las etiquetas viven en book_tags (0005): se elimina la columna CSV.
+
+-- MySQL no tiene ADD/DROP COLUMN IF [NOT] EXISTS: el ALTER se arma solo si
+-- hace falta, para que la migración pueda repetirse si falló a medias.
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.columns
+  WHERE table_schema = DATABASE() AND table_name = 'books' AND column_name = 'tags') > 0,
+  'ALTER TABLE books DROP COLUMN tags', 'DO 0');
+PREPARE ddl FROM @ddl;
+EXECUTE ddl;
+DEALLOCATE PREPARE ddl;
@@ -0,0 +1,35 @@
+-- Circulación: ejemplares físicos (con código de barras y estado) y préstamos.
+-- copies.status = 'on_loan' mientras haya un préstamo activo (returned_at NULL).
+
+CREATE TABLE IF NOT EXISTS copies (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  book_id BIGINT UNSIGNED NOT NULL,
+  barcode VARCHAR(40) NOT NULL,
+  status VARCHAR(20) NOT NULL DEFAULT 'available',
+  location VARCHAR(120) NOT NULL DEFAULT '',
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NULL DEFAULT NULL ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  UNIQUE KEY uq_copies_barcode (barcode),
+  KEY idx_copies_book (book_id),
+  CONSTRAINT fk_copies_book FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS loans (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  copy_id BIGINT UNSIGNED NOT NULL,
+  book_id BIGINT UNSIGNED NOT NULL,
+  user_id BIGINT UNSIGNED NOT NULL,
+  loaned_at DATETIME NOT NULL,
+  due_at DATETIME NOT NULL,
+  returned_at DATETIME NULL DEFAULT NULL,
+  renewals INT NOT NULL DEFAULT 0,
+  PRIMARY KEY (id),
+  KEY idx_loans_copy (copy_id, returned_at),
+  KEY idx_loans_user (user_id, returned_at),
+  KEY idx_loans_book (book_id),
+  KEY idx_loans_due (due_at),
+  CONSTRAINT fk_loans_copy FOREIGN KEY (copy_id) REFERENCES copies(id) ON DELETE CASCADE,
+  CONSTRAINT fk_loans_book FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE,
+  CONSTRAINT fk_loans_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB;
@@ -0,0 +1,22 @@
+-- Reservas: cola FIFO por libro (el orden es el id). Una reserva "ready"
+-- aparta un ejemplar (copies.status = 'on_hold') hasta expires_at.
+
+CREATE TABLE IF NOT EXISTS holds (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  book_id BIGINT UNSIGNED NOT NULL,
+  user_id BIGINT UNSIGNED NOT NULL,
+  copy_id BIGINT UNSIGNED NULL DEFAULT NULL,
+  status VARCHAR(20) NOT NULL DEFAULT 'waiting',
+  created_at DATETIME NOT NULL,
+  ready_at DATETIME NULL DEFAULT NULL,
+  expires_at DATETIME NULL DEFAULT NULL,
+  closed_at DATETIME NULL DEFAULT NULL,
+  PRIMARY KEY (id),
+  KEY idx_holds_book (book_id, status),
+  KEY idx_holds_user (user_id, status),
+  KEY idx_holds_copy (copy_id, status),
+  KEY idx_holds_expires (status, expires_at),
+  CONSTRAINT fk_holds_book FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE,
+  CONSTRAINT fk_holds_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+  CONSTRAINT fk_holds_copy FOREIGN KEY (copy_id) REFERENCES copies(id) ON DELETE SET NULL
+) ENGINE=InnoDB;
@@ -0,0 +1,18 @@
+-- Relaciones tipadas entre libros: "from_book_id es <type> de to_book_id"
+-- (sequel, workbook, teacher_guide, adaptation, companion). bidirectional
+-- indica si la relación también se muestra en el libro destino.
+
+CREATE TABLE IF NOT EXISTS book_relations (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  from_book_id BIGINT UNSIGNED NOT NULL,
+  to_book_id BIGINT UNSIGNED NOT NULL,
+  type VARCHAR(20) NOT NULL,
+  bidirectional TINYINT(1) NOT NULL DEFAULT 0,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  UNIQUE KEY uq_book_relations (from_book_id, to_book_id, type),
+  KEY idx_book_relations_to (to_book_id),
+  KEY idx_book_relations_type (type),
+  CONSTRAINT fk_book_relations_from FOREIGN KEY (from_book_id) REFERENCES books(id) ON DELETE CASCADE,
+  CONSTRAINT fk_book_relations_to FOREIGN KEY (to_book_id) REFERENCES books(id) ON DELETE CASCADE
+) ENGINE=InnoDB;
@@ -0,0 +1,20 @@
+-- Adjuntos de un libro (PDF, EPUB, audio). El contenido vive en el almacén
+-- de blobs bajo su SHA-256; aquí solo se guardan los metadatos. Un libro no
+-- repite contenido, pero dos libros pueden compartir el mismo blob.
+
+CREATE TABLE IF NOT EXISTS book_files (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  book_id BIGINT UNSIGNED NOT NULL,
+  name VARCHAR(255) NOT NULL,
+  content_type VARCHAR(100) NOT NULL,
+  kind VARCHAR(10) NOT NULL,
+  size BIGINT NOT NULL,
+  sha256 CHAR(64) NOT NULL,
+  uploaded_by BIGINT UNSIGNED NULL DEFAULT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  UNIQUE KEY uq_book_files (book_id, sha256),
+  KEY idx_book_files_sha (sha256),
+  CONSTRAINT fk_book_files_book FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE,
+  CONSTRAINT fk_book_files_user FOREIGN KEY (uploaded_by) REFERENCES users(id) ON DELETE SET NULL
+) ENGINE=InnoDB;
@@ -0,0 +1,16 @@
+-- Portada de un libro (a lo sumo una). La imagen original vive en el
+-- almacén de blobs bajo su SHA-256 y las miniaturas en la caché de disco;
+-- aquí solo se guardan los metadatos.
+
+CREATE TABLE IF NOT EXISTS book_covers (
+  book_id BIGINT UNSIGNED NOT NULL,
+  sha256 CHAR(64) NOT NULL,
+  content_type VARCHAR(30) NOT NULL,
+  width INT NOT NULL,
+  height INT NOT NULL,
+  size BIGINT NOT NULL,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (book_id),
+  KEY idx_book_covers_sha (sha256),
+  CONSTRAINT fk_book_covers_book FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE
+) ENGINE=InnoDB;
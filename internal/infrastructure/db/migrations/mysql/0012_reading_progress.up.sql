@@ -0,0 +1,22 @@
+-- Progreso de lectura: por dónde va cada usuario en cada libro (página,
+-- porcentaje y/o ubicación EPUB CFI). Una fila por usuario y libro que se
+-- actualiza en cada guardado; finished_at queda NULL hasta terminarlo.
+
+CREATE TABLE IF NOT EXISTS reading_progress (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  user_id BIGINT UNSIGNED NOT NULL,
+  book_id BIGINT UNSIGNED NOT NULL,
+  page INT NOT NULL DEFAULT 0,
+  total_pages INT NOT NULL DEFAULT 0,
+  percent DOUBLE NOT NULL DEFAULT 0,
+  location VARCHAR(500) NOT NULL DEFAULT '',
+  started_at DATETIME NOT NULL,
+  last_read_at DATETIME NOT NULL,
+  finished_at DATETIME NULL DEFAULT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY uq_reading_progress (user_id, book_id),
+  KEY idx_reading_progress_book (book_id),
+  KEY idx_reading_progress_recent (user_id, last_read_at),
+  CONSTRAINT fk_reading_progress_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+  CONSTRAINT fk_reading_progress_book FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE
+) ENGINE=InnoDB;
@@ -0,0 +1,11 @@
+-- Duración de las sesiones de lectura: el lector integrado registra un
+-- evento LECTURA por sesión con los segundos leídos (0 en los demás tipos).
+
+-- MySQL no tiene ADD/DROP COLUMN IF [NOT] EXISTS: el ALTER se arma solo si
+-- hace falta, para que la migración pueda repetirse si falló a medias.
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.columns
+  WHERE table_schema = DATABASE() AND table_name = 'access_events' AND column_name = 'duration_seconds') = 0,
+  'ALTER TABLE access_events ADD COLUMN duration_seconds INT NOT NULL DEFAULT 0 AFTER access_type', 'DO 0');
+PREPARE ddl FROM @ddl;
+EXECUTE ddl;
+DEALLOCATE PREPARE ddl;
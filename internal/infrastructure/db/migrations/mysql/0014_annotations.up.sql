@@ -0,0 +1,21 @@
+-- Anotaciones: subrayados, marcadores y notas de un usuario en un libro.
+-- location es el ancla dentro del libro (EPUB CFI, página...); visibility
+-- decide quién más la ve (private, class = docentes, public).
+
+CREATE TABLE IF NOT EXISTS annotations (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  user_id BIGINT UNSIGNED NOT NULL,
+  book_id BIGINT UNSIGNED NOT NULL,
+  location VARCHAR(500) NOT NULL,
+  quote TEXT NOT NULL,
+  note TEXT NOT NULL,
+  color VARCHAR(10) NOT NULL DEFAULT 'yellow',
+  visibility VARCHAR(10) NOT NULL DEFAULT 'private',
+  created_at DATETIME NOT NULL,
+  updated_at DATETIME NOT NULL,
+  PRIMARY KEY (id),
+  KEY idx_annotations_user (user_id, created_at),
+  KEY idx_annotations_book (book_id, visibility),
+  CONSTRAINT fk_annotations_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+  CONSTRAINT fk_annotations_book FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE
+) ENGINE=InnoDB;
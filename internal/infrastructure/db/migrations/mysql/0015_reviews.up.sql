@@ -0,0 +1,33 @@
+-- Reseñas: una por usuario y libro (1 a 5 estrellas y un texto). Entran en
+-- la cola de moderación (pending) y solo las aprobadas se muestran y cuentan
+-- en el promedio del libro. review_revisions guarda cada versión anterior
+-- cuando el autor la edita.
+
+CREATE TABLE IF NOT EXISTS reviews (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  user_id BIGINT UNSIGNED NOT NULL,
+  book_id BIGINT UNSIGNED NOT NULL,
+  rating TINYINT NOT NULL,
+  text TEXT NOT NULL,
+  status VARCHAR(10) NOT NULL DEFAULT 'pending',
+  created_at DATETIME NOT NULL,
+  updated_at DATETIME NOT NULL,
+  moderated_at DATETIME NULL DEFAULT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY uq_reviews (user_id, book_id),
+  KEY idx_reviews_book (book_id, status),
+  KEY idx_reviews_status (status, updated_at),
+  CONSTRAINT fk_reviews_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+  CONSTRAINT fk_reviews_book FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+CREATE TABLE IF NOT EXISTS review_revisions (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  review_id BIGINT UNSIGNED NOT NULL,
+  rating TINYINT NOT NULL,
+  text TEXT NOT NULL,
+  written_at DATETIME NOT NULL,
+  PRIMARY KEY (id),
+  KEY idx_review_revisions (review_id, id),
+  CONSTRAINT fk_review_revisions FOREIGN KEY (review_id) REFERENCES reviews(id) ON DELETE CASCADE
+) ENGINE=InnoDB;
@@ -0,0 +1,12 @@
+-- Puestos digitales: un título con licencia de préstamo digital tiene tantos
+-- ejemplares kind='digital' (sin ubicación) como puestos simultáneos. Se
+-- prestan, devuelven y apartan para la cola de reservas como los físicos.
+
+-- MySQL no tiene ADD/DROP COLUMN IF [NOT] EXISTS: el ALTER se arma solo si
+-- hace falta, para que la migración pueda repetirse si falló a medias.
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.columns
+  WHERE table_schema = DATABASE() AND table_name = 'copies' AND column_name = 'kind') = 0,
+  'ALTER TABLE copies ADD COLUMN kind VARCHAR(10) NOT NULL DEFAULT ''physical'' AFTER book_id', 'DO 0');
+PREPARE ddl FROM @ddl;
+EXECUTE ddl;
+DEALLOCATE PREPARE ddl;
@@ -0,0 +1,49 @@
+-- Esquema base: usuarios, libros y eventos de acceso (equivalente a mysql/0001).
+-- La unicidad de email/isbn es sin distinguir mayúsculas, como la collation *_ci de MySQL.
+
+CREATE TABLE users (
+  id BIGSERIAL PRIMARY KEY,
+  name VARCHAR(120) NOT NULL,
+  email VARCHAR(180) NOT NULL,
+  role VARCHAR(20) NOT NULL,
+  active BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NULL DEFAULT NULL
+);
+
+CREATE UNIQUE INDEX uq_users_email ON users (LOWER(email));
+
+CREATE TABLE books (
+  id BIGSERIAL PRIMARY KEY,
+  title VARCHAR(220) NOT NULL,
+  author VARCHAR(180) NOT NULL,
+  year INTEGER NOT NULL,
+  isbn VARCHAR(80) NOT NULL,
+  category VARCHAR(120) NOT NULL,
+  tags VARCHAR(600) NOT NULL DEFAULT '',
+  description TEXT NULL,
+  active BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NULL DEFAULT NULL,
+  -- Búsqueda de texto completo (título, autor, etiquetas, descripción)
+  search_vector TSVECTOR GENERATED ALWAYS AS (
+    to_tsvector('spanish',
+      title || ' ' || author || ' ' || tags || ' ' || COALESCE(description, ''))
+  ) STORED
+);
+
+CREATE UNIQUE INDEX uq_books_isbn ON books (LOWER(isbn));
+CREATE INDEX idx_books_author ON books (author);
+CREATE INDEX idx_books_category ON books (category);
+CREATE INDEX idx_books_search ON books USING GIN (search_vector);
+
+CREATE TABLE access_events (
+  id BIGSERIAL PRIMARY KEY,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  book_id BIGINT NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+  access_type VARCHAR(20) NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_access_book ON access_events (book_id);
+CREATE INDEX idx_access_user ON access_events (user_id);
@@ -0,0 +1,17 @@
+-- Tokens personales de API (solo se guarda el hash).
+
+CREATE TABLE api_tokens (
+  id BIGSERIAL PRIMARY KEY,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name VARCHAR(120) NOT NULL,
+  token_hash CHAR(64) NOT NULL,
+  prefix VARCHAR(20) NOT NULL,
+  scopes VARCHAR(255) NOT NULL,
+  expires_at TIMESTAMPTZ NULL DEFAULT NULL,
+  last_used_at TIMESTAMPTZ NULL DEFAULT NULL,
+  revoked_at TIMESTAMPTZ NULL DEFAULT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT uq_api_tokens_hash UNIQUE (token_hash)
+);
+
+CREATE INDEX idx_api_tokens_user ON api_tokens (user_id);
@@ -0,0 +1,29 @@
+-- Autores como entidad propia y créditos libro-autor (ordenados y con rol).
+-- books.author se conserva como byline (texto de autoría para mostrar y buscar).
+
+CREATE TABLE authors (
+  id BIGSERIAL PRIMARY KEY,
+  name VARCHAR(180) NOT NULL,
+  bio TEXT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NULL DEFAULT NULL
+);
+
+CREATE INDEX idx_authors_name ON authors (LOWER(name));
+
+CREATE TABLE book_authors (
+  book_id BIGINT NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+  author_id BIGINT NOT NULL REFERENCES authors(id) ON DELETE CASCADE,
+  role VARCHAR(20) NOT NULL,
+  position INTEGER NOT NULL DEFAULT 0,
+  PRIMARY KEY (book_id, author_id, role)
+);
+
+CREATE INDEX idx_book_authors_author ON book_authors (author_id);
+
+-- Un autor por cada texto de autoría existente (sin distinguir mayúsculas,
+-- como la collation *_ci de MySQL), acreditado como "author"
+INSERT INTO authors (name) SELECT MIN(author) FROM books GROUP BY LOWER(author);
+
+INSERT INTO book_authors (book_id, author_id, role, position)
+SELECT b.id, a.id, 'author', 0 FROM books b JOIN authors a ON LOWER(a.name) = LOWER(b.author);
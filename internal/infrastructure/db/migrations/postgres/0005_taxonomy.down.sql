@@ -0,0 +1,4 @@
+ALTER TABLE books DROP COLUMN IF EXISTS category_id;
+DROP TABLE IF EXISTS book_tags;
+DROP TABLE IF EXISTS tags;
+DROP TABLE IF EXISTS categories;
@@ -0,0 +1,13 @@
+ALTER TABLE books DROP COLUMN search_vector;
+ALTER TABLE books ADD COLUMN tags VARCHAR(600) NOT NULL DEFAULT '';
+
+UPDATE books SET tags = COALESCE(LEFT((
+  SELECT string_agg(t.name, ',' ORDER BY bt.position)
+  FROM book_tags bt JOIN tags t ON t.id = bt.tag_id
+  WHERE bt.book_id = books.id), 600), '');
+
+ALTER TABLE books ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+  to_tsvector('spanish', title || ' ' || author || ' ' || tags || ' ' || COALESCE(description, ''))
+) STORED;
+
+CREATE INDEX idx_books_search ON books USING GIN (search_vector);
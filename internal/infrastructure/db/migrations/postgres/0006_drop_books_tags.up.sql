@@ -0,0 +1,12 @@
+-- Las etiquetas viven en book_tags (0005): se elimina la columna CSV.
+-- search_vector dependía de ella, así que se recrea sin etiquetas (la
+-- búsqueda por texto libre sigue encontrando etiquetas vía book_tags).
+
+ALTER TABLE books DROP COLUMN search_vector;
+ALTER TABLE books DROP COLUMN tags;
+
+ALTER TABLE books ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+  to_tsvector('spanish', title || ' ' || author || ' ' || COALESCE(description, ''))
+) STORED;
+
+CREATE INDEX idx_books_search ON books USING GIN (search_vector);
@@ -0,0 +1,34 @@
+-- Circulación: ejemplares físicos (con código de barras y estado) y préstamos.
+-- copies.status = 'on_loan' mientras haya un préstamo activo (returned_at NULL).
+
+CREATE TABLE copies (
+  id BIGSERIAL PRIMARY KEY,
+  book_id BIGINT NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+  barcode VARCHAR(40) NOT NULL,
+  status VARCHAR(20) NOT NULL DEFAULT 'available',
+  location VARCHAR(120) NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NULL DEFAULT NULL,
+  CONSTRAINT uq_copies_barcode UNIQUE (barcode)
+);
+
+CREATE INDEX idx_copies_book ON copies (book_id);
+
+CREATE TABLE loans (
+  id BIGSERIAL PRIMARY KEY,
+  copy_id BIGINT NOT NULL REFERENCES copies(id) ON DELETE CASCADE,
+  book_id BIGINT NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  loaned_at TIMESTAMPTZ NOT NULL,
+  due_at TIMESTAMPTZ NOT NULL,
+  returned_at TIMESTAMPTZ NULL DEFAULT NULL,
+  renewals INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_loans_copy ON loans (copy_id, returned_at);
+CREATE INDEX idx_loans_user ON loans (user_id, returned_at);
+CREATE INDEX idx_loans_book ON loans (book_id);
+CREATE INDEX idx_loans_due ON loans (due_at);
+
+-- Un solo préstamo activo por ejemplar
+CREATE UNIQUE INDEX uq_loans_active_copy ON loans (copy_id) WHERE returned_at IS NULL;
@@ -0,0 +1,4 @@
+-- Los ejemplares apartados vuelven a estar disponibles.
+UPDATE copies SET status = 'available' WHERE status = 'on_hold';
+
+DROP TABLE IF EXISTS holds;
@@ -0,0 +1,19 @@
+-- Reservas: cola FIFO por libro (el orden es el id). Una reserva "ready"
+-- aparta un ejemplar (copies.status = 'on_hold') hasta expires_at.
+
+CREATE TABLE holds (
+  id BIGSERIAL PRIMARY KEY,
+  book_id BIGINT NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  copy_id BIGINT NULL DEFAULT NULL REFERENCES copies(id) ON DELETE SET NULL,
+  status VARCHAR(20) NOT NULL DEFAULT 'waiting',
+  created_at TIMESTAMPTZ NOT NULL,
+  ready_at TIMESTAMPTZ NULL DEFAULT NULL,
+  expires_at TIMESTAMPTZ NULL DEFAULT NULL,
+  closed_at TIMESTAMPTZ NULL DEFAULT NULL
+);
+
+CREATE INDEX idx_holds_book ON holds (book_id, status);
+CREATE INDEX idx_holds_user ON holds (user_id, status);
+CREATE INDEX idx_holds_copy ON holds (copy_id, status);
+CREATE INDEX idx_holds_expires ON holds (status, expires_at);
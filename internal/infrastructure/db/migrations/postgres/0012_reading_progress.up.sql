@@ -0,0 +1,20 @@
+-- Progreso de lectura: por dónde va cada usuario en cada libro (página,
+-- porcentaje y/o ubicación EPUB CFI). Una fila por usuario y libro que se
+-- actualiza en cada guardado; finished_at queda NULL hasta terminarlo.
+
+CREATE TABLE reading_progress (
+  id BIGSERIAL PRIMARY KEY,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  book_id BIGINT NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+  page INT NOT NULL DEFAULT 0,
+  total_pages INT NOT NULL DEFAULT 0,
+  percent DOUBLE PRECISION NOT NULL DEFAULT 0,
+  location VARCHAR(500) NOT NULL DEFAULT '',
+  started_at TIMESTAMPTZ NOT NULL,
+  last_read_at TIMESTAMPTZ NOT NULL,
+  finished_at TIMESTAMPTZ NULL DEFAULT NULL,
+  CONSTRAINT uq_reading_progress UNIQUE (user_id, book_id)
+);
+
+CREATE INDEX idx_reading_progress_book ON reading_progress (book_id);
+CREATE INDEX idx_reading_progress_recent ON reading_progress (user_id, last_read_at);
@@ -0,0 +1,30 @@
+-- Reseñas: una por usuario y libro (1 a 5 estrellas y un texto). Entran en
+-- la cola de moderación (pending) y solo las aprobadas se muestran y cuentan
+-- en el promedio del libro. review_revisions guarda cada versión anterior
+-- cuando el autor la edita.
+
+CREATE TABLE reviews (
+  id BIGSERIAL PRIMARY KEY,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  book_id BIGINT NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+  rating SMALLINT NOT NULL,
+  text TEXT NOT NULL DEFAULT '',
+  status VARCHAR(10) NOT NULL DEFAULT 'pending',
+  created_at TIMESTAMPTZ NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL,
+  moderated_at TIMESTAMPTZ NULL DEFAULT NULL,
+  CONSTRAINT uq_reviews UNIQUE (user_id, book_id)
+);
+
+CREATE INDEX idx_reviews_book ON reviews (book_id, status);
+CREATE INDEX idx_reviews_status ON reviews (status, updated_at);
+
+CREATE TABLE review_revisions (
+  id BIGSERIAL PRIMARY KEY,
+  review_id BIGINT NOT NULL REFERENCES reviews(id) ON DELETE CASCADE,
+  rating SMALLINT NOT NULL,
+  text TEXT NOT NULL DEFAULT '',
+  written_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_review_revisions ON review_revisions (review_id, id);
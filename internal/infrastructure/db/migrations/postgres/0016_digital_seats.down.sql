@@ -0,0 +1 @@
+ALTER TABLE copies DROP COLUMN kind;
@@ -0,0 +1,5 @@
+-- Puestos digitales: un título con licencia de préstamo digital tiene tantos
+-- ejemplares kind='digital' (sin ubicación) como puestos simultáneos. Se
+-- prestan, devuelven y apartan para la cola de reservas como los físicos.
+
+ALTER TABLE copies ADD COLUMN kind VARCHAR(10) NOT NULL DEFAULT 'physical';
@@ -0,0 +1,42 @@
+-- Esquema base: usuarios, libros y eventos de acceso (equivalente a mysql/0001).
+-- NOCASE replica la collation *_ci de MySQL en las claves únicas de texto.
+
+CREATE TABLE users (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name VARCHAR(120) NOT NULL,
+  email VARCHAR(180) NOT NULL COLLATE NOCASE,
+  role VARCHAR(20) NOT NULL,
+  active INTEGER NOT NULL DEFAULT 1,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NULL DEFAULT NULL,
+  CONSTRAINT uq_users_email UNIQUE (email)
+);
+
+CREATE TABLE books (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  title VARCHAR(220) NOT NULL,
+  author VARCHAR(180) NOT NULL,
+  year INTEGER NOT NULL,
+  isbn VARCHAR(80) NOT NULL COLLATE NOCASE,
+  category VARCHAR(120) NOT NULL,
+  tags VARCHAR(600) NOT NULL DEFAULT '',
+  description TEXT NULL,
+  active INTEGER NOT NULL DEFAULT 1,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NULL DEFAULT NULL,
+  CONSTRAINT uq_books_isbn UNIQUE (isbn)
+);
+
+CREATE INDEX idx_books_author ON books (author);
+CREATE INDEX idx_books_category ON books (category);
+
+CREATE TABLE access_events (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  book_id INTEGER NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+  access_type VARCHAR(20) NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_access_book ON access_events (book_id);
+CREATE INDEX idx_access_user ON access_events (user_id);
@@ -0,0 +1,17 @@
+-- Tokens personales de API (solo se guarda el hash).
+
+CREATE TABLE api_tokens (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name VARCHAR(120) NOT NULL,
+  token_hash CHAR(64) NOT NULL,
+  prefix VARCHAR(20) NOT NULL,
+  scopes VARCHAR(255) NOT NULL,
+  expires_at DATETIME NULL DEFAULT NULL,
+  last_used_at DATETIME NULL DEFAULT NULL,
+  revoked_at DATETIME NULL DEFAULT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT uq_api_tokens_hash UNIQUE (token_hash)
+);
+
+CREATE INDEX idx_api_tokens_user ON api_tokens (user_id);
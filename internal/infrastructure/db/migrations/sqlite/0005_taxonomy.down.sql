@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_books_category_id;
+ALTER TABLE books DROP COLUMN category_id;
+DROP TABLE IF EXISTS book_tags;
+DROP TABLE IF EXISTS tags;
+DROP TABLE IF EXISTS categories;
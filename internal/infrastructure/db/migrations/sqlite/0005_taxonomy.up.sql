@@ -0,0 +1,39 @@
+-- Etiquetas y categorías como entidades (únicas por slug) y árbol de categorías
+-- (equivalente a mysql/0005). books.category se conserva como nombre canónico
+-- y books.category_id apunta a la categoría; los datos existentes los copia
+-- el paso en Go de esta versión (backfillTaxonomy).
+-- category_id va sin REFERENCES: SQLite no puede borrar (down) una columna
+-- con clave foránea; la app valida la categoría antes de asignarla.
+
+CREATE TABLE categories (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name VARCHAR(120) NOT NULL,
+  slug VARCHAR(120) NOT NULL,
+  parent_id INTEGER NULL REFERENCES categories(id),
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NULL DEFAULT NULL,
+  CONSTRAINT uq_categories_slug UNIQUE (slug)
+);
+
+CREATE INDEX idx_categories_parent ON categories (parent_id);
+
+CREATE TABLE tags (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name VARCHAR(60) NOT NULL,
+  slug VARCHAR(60) NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT uq_tags_slug UNIQUE (slug)
+);
+
+CREATE TABLE book_tags (
+  book_id INTEGER NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+  tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+  position INTEGER NOT NULL DEFAULT 0,
+  PRIMARY KEY (book_id, tag_id)
+);
+
+CREATE INDEX idx_book_tags_tag ON book_tags (tag_id);
+
+ALTER TABLE books ADD COLUMN category_id INTEGER NULL;
+
+CREATE INDEX idx_books_category_id ON books (category_id);
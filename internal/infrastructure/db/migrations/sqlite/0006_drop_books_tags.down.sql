@@ -0,0 +1,6 @@
+ALTER TABLE books ADD COLUMN tags VARCHAR(600) NOT NULL DEFAULT '';
+
+UPDATE books SET tags = COALESCE(SUBSTR((
+  SELECT group_concat(t.name, ',')
+  FROM book_tags bt JOIN tags t ON t.id = bt.tag_id
+  WHERE bt.book_id = books.id), 1, 600), '');
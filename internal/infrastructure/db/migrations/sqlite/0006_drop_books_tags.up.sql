@@ -0,0 +1,3 @@
+-- Las etiquetas viven en book_tags (0005): se elimina la columna CSV.
+
+ALTER TABLE books DROP COLUMN tags;
@@ -0,0 +1,16 @@
+-- Relaciones tipadas entre libros: "from_book_id es <type> de to_book_id"
+-- (sequel, workbook, teacher_guide, adaptation, companion). bidirectional
+-- indica si la relación también se muestra en el libro destino.
+
+CREATE TABLE book_relations (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  from_book_id INTEGER NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+  to_book_id INTEGER NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+  type VARCHAR(20) NOT NULL,
+  bidirectional INTEGER NOT NULL DEFAULT 0,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT uq_book_relations UNIQUE (from_book_id, to_book_id, type)
+);
+
+CREATE INDEX idx_book_relations_to ON book_relations (to_book_id);
+CREATE INDEX idx_book_relations_type ON book_relations (type);
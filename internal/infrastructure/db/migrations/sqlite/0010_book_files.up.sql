@@ -0,0 +1,18 @@
+-- Adjuntos de un libro (PDF, EPUB, audio). El contenido vive en el almacén
+-- de blobs bajo su SHA-256; aquí solo se guardan los metadatos. Un libro no
+-- repite contenido, pero dos libros pueden compartir el mismo blob.
+
+CREATE TABLE book_files (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  book_id INTEGER NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+  name VARCHAR(255) NOT NULL,
+  content_type VARCHAR(100) NOT NULL,
+  kind VARCHAR(10) NOT NULL,
+  size BIGINT NOT NULL,
+  sha256 CHAR(64) NOT NULL,
+  uploaded_by INTEGER NULL DEFAULT NULL REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT uq_book_files UNIQUE (book_id, sha256)
+);
+
+CREATE INDEX idx_book_files_sha ON book_files (sha256);
@@ -0,0 +1,15 @@
+-- Portada de un libro (a lo sumo una). La imagen original vive en el
+-- almacén de blobs bajo su SHA-256 y las miniaturas en la caché de disco;
+-- aquí solo se guardan los metadatos.
+
+CREATE TABLE book_covers (
+  book_id INTEGER PRIMARY KEY REFERENCES books(id) ON DELETE CASCADE,
+  sha256 CHAR(64) NOT NULL,
+  content_type VARCHAR(30) NOT NULL,
+  width INT NOT NULL,
+  height INT NOT NULL,
+  size BIGINT NOT NULL,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_book_covers_sha ON book_covers (sha256);
@@ -0,0 +1,20 @@
+-- Progreso de lectura: por dónde va cada usuario en cada libro (página,
+-- porcentaje y/o ubicación EPUB CFI). Una fila por usuario y libro que se
+-- actualiza en cada guardado; finished_at queda NULL hasta terminarlo.
+
+CREATE TABLE reading_progress (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  book_id INTEGER NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+  page INT NOT NULL DEFAULT 0,
+  total_pages INT NOT NULL DEFAULT 0,
+  percent REAL NOT NULL DEFAULT 0,
+  location VARCHAR(500) NOT NULL DEFAULT '',
+  started_at DATETIME NOT NULL,
+  last_read_at DATETIME NOT NULL,
+  finished_at DATETIME NULL DEFAULT NULL,
+  CONSTRAINT uq_reading_progress UNIQUE (user_id, book_id)
+);
+
+CREATE INDEX idx_reading_progress_book ON reading_progress (book_id);
+CREATE INDEX idx_reading_progress_recent ON reading_progress (user_id, last_read_at);
@@ -0,0 +1 @@
+ALTER TABLE access_events DROP COLUMN duration_seconds;
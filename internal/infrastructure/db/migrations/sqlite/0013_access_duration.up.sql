@@ -0,0 +1,4 @@
+-- Duración de las sesiones de lectura: el lector integrado registra un
+-- evento LECTURA por sesión con los segundos leídos (0 en los demás tipos).
+
+ALTER TABLE access_events ADD COLUMN duration_seconds INT NOT NULL DEFAULT 0;
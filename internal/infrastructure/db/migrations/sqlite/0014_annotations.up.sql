@@ -0,0 +1,19 @@
+-- Anotaciones: subrayados, marcadores y notas de un usuario en un libro.
+-- location es el ancla dentro del libro (EPUB CFI, página...); visibility
+-- decide quién más la ve (private, class = docentes, public).
+
+CREATE TABLE annotations (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  book_id INTEGER NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+  location VARCHAR(500) NOT NULL,
+  quote TEXT NOT NULL DEFAULT '',
+  note TEXT NOT NULL DEFAULT '',
+  color VARCHAR(10) NOT NULL DEFAULT 'yellow',
+  visibility VARCHAR(10) NOT NULL DEFAULT 'private',
+  created_at DATETIME NOT NULL,
+  updated_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_annotations_user ON annotations (user_id, created_at);
+CREATE INDEX idx_annotations_book ON annotations (book_id, visibility);
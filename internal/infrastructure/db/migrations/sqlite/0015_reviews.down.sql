@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS review_revisions;
+DROP TABLE IF EXISTS reviews;
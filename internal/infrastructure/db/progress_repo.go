@@ -0,0 +1,206 @@
+package db // Infraestructura DB: progreso de lectura
+
+import (
+	"context"      // Para timeouts/cancelación
+	"database/sql" // Driver SQL estándar
+	"errors"       // Para comparar errores (errors.Is)
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain" // Dominio (ReadingProgress + errores)
+)
+
+// SQLProgressRepo persiste el progreso de lectura (tabla reading_progress).
+type SQLProgressRepo struct {
+	db conn // conexión + dialecto del motor
+}
+
+// NewMySQLProgressRepo inyecta la conexión (MySQL).
+func NewMySQLProgressRepo(db *sql.DB) *SQLProgressRepo {
+	return &SQLProgressRepo{db: conn{db, mysqlDialect}}
+}
+
+// NewSQLiteProgressRepo inyecta la conexión (SQLite).
+func NewSQLiteProgressRepo(db *sql.DB) *SQLProgressRepo {
+	return &SQLProgressRepo{db: conn{db, sqliteDialect}}
+}
+
+const progressColumns = `p.id,p.user_id,p.book_id,p.page,p.total_pages,p.percent,p.location,p.started_at,p.last_read_at,p.finished_at`
+
+// progressSortColumns traduce los campos de orden públicos a expresiones SQL.
+var progressSortColumns = map[string]string{
+	domain.SortCreatedAt: "id",
+	"last_read_at":       "last_read_at",
+	"percent":            "percent",
+}
+
+// completionSortColumns: columnas de la tabla derivada de Completion.
+var completionSortColumns = map[string]string{
+	"readers":     "readers",
+	"rate":        "rate",
+	"avg_percent": "avg_percent",
+	"title":       "LOWER(title)",
+}
+
+// Save actualiza la fila del usuario y libro (conservando started_at) o la
+// crea si es el primer guardado.
+func (r *SQLProgressRepo) Save(ctx context.Context, p *domain.ReadingProgress) error {
+	return r.db.inTx(ctx, func(tx txConn) error {
+		if err := tx.mustExist(ctx, "users", p.UserID()); err != nil {
+			return err
+		}
+		if err := tx.mustExist(ctx, "books", p.BookID()); err != nil {
+			return err
+		}
+		res, err := tx.ExecContext(ctx,
+			`UPDATE reading_progress SET page=?,total_pages=?,percent=?,location=?,last_read_at=?,finished_at=?
+			 WHERE user_id=? AND book_id=?`,
+			p.Page(), p.TotalPages(), p.Percent(), p.Location(), p.LastReadAt().UTC(), nullableTime(p.FinishedAt()),
+			p.UserID(), p.BookID())
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			return nil
+		}
+		_, err = tx.insert(ctx,
+			`INSERT INTO reading_progress (user_id,book_id,page,total_pages,percent,location,started_at,last_read_at,finished_at)
+			 VALUES (?,?,?,?,?,?,?,?,?)`,
+			p.UserID(), p.BookID(), p.Page(), p.TotalPages(), p.Percent(), p.Location(),
+			p.StartedAt().UTC(), p.LastReadAt().UTC(), nullableTime(p.FinishedAt()))
+		return err
+	})
+}
+
+// Get obtiene el progreso del usuario en el libro.
+func (r *SQLProgressRepo) Get(ctx context.Context, userID, bookID uint64) (*domain.ReadingProgress, error) {
+	return scanProgress(r.db.QueryRowContext(ctx,
+		`SELECT `+progressColumns+` FROM reading_progress p WHERE p.user_id=? AND p.book_id=?`, userID, bookID))
+}
+
+// Delete borra el progreso del usuario en el libro.
+func (r *SQLProgressRepo) Delete(ctx context.Context, userID, bookID uint64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM reading_progress WHERE user_id=? AND book_id=?`, userID, bookID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// List retorna progresos paginados con título, autor y nombre del lector.
+func (r *SQLProgressRepo) List(ctx context.Context, f domain.ProgressFilter) (domain.Page[domain.ProgressDetail], error) {
+	pg, err := f.Paging()
+	if err != nil {
+		return domain.Page[domain.ProgressDetail]{}, err
+	}
+
+	cond := "1=1"
+	args := []any{}
+	if f.UserID != 0 {
+		cond += " AND p.user_id=?"
+		args = append(args, f.UserID)
+	}
+	if f.BookID != 0 {
+		cond += " AND p.book_id=?"
+		args = append(args, f.BookID)
+	}
+	switch f.Status {
+	case domain.ProgressStatusReading:
+		cond += " AND p.finished_at IS NULL"
+	case domain.ProgressStatusFinished:
+		cond += " AND p.finished_at IS NOT NULL"
+	}
+
+	page := domain.Page[domain.ProgressDetail]{Page: pg.Page, PageSize: pg.PageSize}
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM reading_progress p WHERE `+cond, args...).Scan(&page.Total); err != nil {
+		return page, err
+	}
+
+	// La tabla derivada evita que el "id" de desempate de orderBy sea ambiguo
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+progressColumns+`,p.title,p.author,p.user_name FROM (
+		   SELECT p.*,b.title,b.author,u.name AS user_name
+		   FROM reading_progress p JOIN books b ON b.id=p.book_id JOIN users u ON u.id=p.user_id
+		 ) p WHERE `+cond+` ORDER BY `+orderBy(progressSortColumns, pg)+` LIMIT ? OFFSET ?`,
+		append(args, pg.PageSize, pg.Offset())...)
+	if err != nil {
+		return page, err
+	}
+	defer rows.Close()
+
+	page.Items = []domain.ProgressDetail{}
+	for rows.Next() {
+		var d domain.ProgressDetail
+		p, err := scanProgress(withExtra(rows, &d.BookTitle, &d.BookAuthor, &d.UserName))
+		if err != nil {
+			return page, err
+		}
+		d.Progress = p
+		page.Items = append(page.Items, d)
+	}
+	return page, rows.Err()
+}
+
+// Completion agrupa los progresos por libro.
+func (r *SQLProgressRepo) Completion(ctx context.Context, f domain.CompletionFilter) (domain.Page[domain.BookCompletion], error) {
+	pg, err := f.Paging()
+	if err != nil {
+		return domain.Page[domain.BookCompletion]{}, err
+	}
+
+	cond := "1=1"
+	args := []any{}
+	if f.BookID != 0 {
+		cond += " AND book_id=?"
+		args = append(args, f.BookID)
+	}
+
+	page := domain.Page[domain.BookCompletion]{Page: pg.Page, PageSize: pg.PageSize}
+	if err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(DISTINCT book_id) FROM reading_progress WHERE `+cond, args...).Scan(&page.Total); err != nil {
+		return page, err
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id,title,author,readers,finished,avg_percent FROM (
+		   SELECT b.id,b.title,b.author,COUNT(*) AS readers,
+		          SUM(CASE WHEN p.finished_at IS NULL THEN 0 ELSE 1 END) AS finished,
+		          AVG(p.percent) AS avg_percent,
+		          SUM(CASE WHEN p.finished_at IS NULL THEN 0 ELSE 1 END)*1.0/COUNT(*) AS rate
+		   FROM reading_progress p JOIN books b ON b.id=p.book_id
+		   WHERE `+cond+`
+		   GROUP BY b.id,b.title,b.author
+		 ) c ORDER BY `+orderBy(completionSortColumns, pg)+` LIMIT ? OFFSET ?`,
+		append(args, pg.PageSize, pg.Offset())...)
+	if err != nil {
+		return page, err
+	}
+	defer rows.Close()
+
+	page.Items = []domain.BookCompletion{}
+	for rows.Next() {
+		var c domain.BookCompletion
+		if err := rows.Scan(&c.BookID, &c.Title, &c.Author, &c.Readers, &c.Finished, &c.AvgPercent); err != nil {
+			return page, err
+		}
+		page.Items = append(page.Items, c)
+	}
+	return page, rows.Err()
+}
+
+// scanProgress convierte una fila (progressColumns) en entidad.
+func scanProgress(row interface{ Scan(dest ...any) error }) (*domain.ReadingProgress, error) {
+	var (
+		id, userID, bookID                uint64
+		pos                               domain.ReadingPosition
+		startedAt, lastReadAt, finishedAt dbTime // NULL => tiempo cero
+	)
+	if err := row.Scan(&id, &userID, &bookID, &pos.Page, &pos.TotalPages, &pos.Percent, &pos.Location, &startedAt, &lastReadAt, &finishedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return domain.HydrateReadingProgress(id, userID, bookID, pos, startedAt.Time, lastReadAt.Time, finishedAt.Time), nil
+}
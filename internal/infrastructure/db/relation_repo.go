@@ -0,0 +1,126 @@
+package db // Infraestructura DB: relaciones tipadas entre libros
+
+import (
+	"context"      // Para timeouts/cancelación
+	"database/sql" // Driver SQL estándar
+	"errors"       // Para comparar errores (errors.Is)
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain" // Dominio (BookRelation + errores)
+)
+
+// SQLRelationRepo persiste las relaciones entre libros (tabla book_relations).
+type SQLRelationRepo struct {
+	db conn // conexión + dialecto del motor
+}
+
+// NewMySQLRelationRepo inyecta la conexión (MySQL).
+func NewMySQLRelationRepo(db *sql.DB) *SQLRelationRepo {
+	return &SQLRelationRepo{db: conn{db, mysqlDialect}}
+}
+
+// NewSQLiteRelationRepo inyecta la conexión (SQLite).
+func NewSQLiteRelationRepo(db *sql.DB) *SQLRelationRepo {
+	return &SQLRelationRepo{db: conn{db, sqliteDialect}}
+}
+
+const relationColumns = `r.id,r.from_book_id,r.to_book_id,r.type,r.bidirectional,r.created_at`
+
+// Create verifica que existan ambos libros e inserta la relación
+// (la restricción única traduce los duplicados a ErrDuplicate).
+func (r *SQLRelationRepo) Create(ctx context.Context, rel *domain.BookRelation) (uint64, error) {
+	var id uint64
+	err := r.db.inTx(ctx, func(tx txConn) error {
+		for _, bookID := range []uint64{rel.FromID(), rel.ToID()} {
+			if err := tx.mustExist(ctx, "books", bookID); err != nil {
+				return err
+			}
+		}
+		var err error
+		id, err = tx.insert(ctx,
+			`INSERT INTO book_relations (from_book_id,to_book_id,type,bidirectional) VALUES (?,?,?,?)`,
+			rel.FromID(), rel.ToID(), string(rel.Type()), rel.Bidirectional())
+		return err
+	})
+	return id, err
+}
+
+// GetByID busca una relación por ID.
+func (r *SQLRelationRepo) GetByID(ctx context.Context, id uint64) (*domain.BookRelation, error) {
+	return scanRelation(r.db.QueryRowContext(ctx, `SELECT `+relationColumns+` FROM book_relations r WHERE r.id=?`, id))
+}
+
+// Delete elimina la relación.
+func (r *SQLRelationRepo) Delete(ctx context.Context, id uint64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM book_relations WHERE id=?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// ByType retorna todas las relaciones del tipo (por id).
+func (r *SQLRelationRepo) ByType(ctx context.Context, t domain.RelationType) ([]*domain.BookRelation, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+relationColumns+` FROM book_relations r WHERE r.type=? ORDER BY r.id`, string(t))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []*domain.BookRelation{}
+	for rows.Next() {
+		rel, err := scanRelation(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, rel)
+	}
+	return out, rows.Err()
+}
+
+// ByBook retorna las relaciones que salen del libro y las bidireccionales
+// que llegan a él, con los datos del otro libro (por tipo y título).
+func (r *SQLRelationRepo) ByBook(ctx context.Context, bookID uint64) ([]domain.RelatedBook, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+relationColumns+`,b.id,b.title,b.author,b.year FROM book_relations r
+		 JOIN books b ON b.id = CASE WHEN r.from_book_id=? THEN r.to_book_id ELSE r.from_book_id END
+		 WHERE r.from_book_id=? OR (r.to_book_id=? AND r.bidirectional=?)
+		 ORDER BY r.type, LOWER(b.title), r.id`,
+		bookID, bookID, bookID, true)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []domain.RelatedBook{}
+	for rows.Next() {
+		var rb domain.RelatedBook
+		rel, err := scanRelation(withExtra(rows, &rb.BookID, &rb.Title, &rb.Author, &rb.Year))
+		if err != nil {
+			return nil, err
+		}
+		rb.Relation, rb.Inverse = rel, rel.FromID() != bookID
+		out = append(out, rb)
+	}
+	return out, rows.Err()
+}
+
+// scanRelation convierte una fila (relationColumns) en entidad.
+func scanRelation(row interface{ Scan(dest ...any) error }) (*domain.BookRelation, error) {
+	var (
+		id, fromID, toID uint64
+		relType          string
+		bidirectional    bool
+		createdAt        dbTime
+	)
+	if err := row.Scan(&id, &fromID, &toID, &relType, &bidirectional, &createdAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return domain.HydrateBookRelation(id, fromID, toID, domain.RelationType(relType), bidirectional, createdAt.Time), nil
+}
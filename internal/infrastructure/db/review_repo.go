@@ -0,0 +1,246 @@
+package db // Infraestructura DB: reseñas de los libros y su historial
+
+import (
+	"context"      // Para timeouts/cancelación
+	"database/sql" // Driver SQL estándar
+	"errors"       // Para comparar errores (errors.Is)
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain" // Dominio (Review + errores)
+)
+
+// SQLReviewRepo persiste las reseñas (tabla reviews) y sus versiones
+// anteriores (tabla review_revisions).
+type SQLReviewRepo struct {
+	db conn // conexión + dialecto del motor
+}
+
+// NewMySQLReviewRepo inyecta la conexión (MySQL).
+func NewMySQLReviewRepo(db *sql.DB) *SQLReviewRepo {
+	return &SQLReviewRepo{db: conn{db, mysqlDialect}}
+}
+
+// NewSQLiteReviewRepo inyecta la conexión (SQLite).
+func NewSQLiteReviewRepo(db *sql.DB) *SQLReviewRepo {
+	return &SQLReviewRepo{db: conn{db, sqliteDialect}}
+}
+
+const reviewColumns = `r.id,r.user_id,r.book_id,r.rating,r.text,r.status,r.created_at,r.updated_at,r.moderated_at`
+
+// reviewSortColumns traduce los campos de orden públicos a expresiones SQL.
+var reviewSortColumns = map[string]string{
+	domain.SortCreatedAt: "id",
+	"updated_at":         "updated_at",
+	domain.SortRating:    "rating",
+}
+
+// ratingExpr es el promedio de estrellas de las reseñas aprobadas de
+// books.id (0 sin reseñas). Lo usan la búsqueda de libros y su orden.
+const ratingExpr = `COALESCE((SELECT AVG(rv.rating) FROM reviews rv WHERE rv.book_id=books.id AND rv.status='approved'),0)`
+
+// Create verifica que existan el usuario y el libro e inserta la reseña
+// (la clave única usuario+libro da ErrDuplicate).
+func (r *SQLReviewRepo) Create(ctx context.Context, rv *domain.Review) (uint64, error) {
+	var id uint64
+	err := r.db.inTx(ctx, func(tx txConn) error {
+		if err := tx.mustExist(ctx, "users", rv.UserID()); err != nil {
+			return err
+		}
+		if err := tx.mustExist(ctx, "books", rv.BookID()); err != nil {
+			return err
+		}
+		var err error
+		id, err = tx.insert(ctx,
+			`INSERT INTO reviews (user_id,book_id,rating,text,status,created_at,updated_at,moderated_at)
+			 VALUES (?,?,?,?,?,?,?,?)`,
+			rv.UserID(), rv.BookID(), rv.Rating(), rv.Text(), string(rv.Status()),
+			rv.CreatedAt().UTC(), rv.UpdatedAt().UTC(), nullableTime(rv.ModeratedAt()))
+		return err
+	})
+	return id, err
+}
+
+// GetByID busca una reseña por ID.
+func (r *SQLReviewRepo) GetByID(ctx context.Context, id uint64) (*domain.Review, error) {
+	return scanReview(r.db.QueryRowContext(ctx, `SELECT `+reviewColumns+` FROM reviews r WHERE r.id=?`, id))
+}
+
+// GetByUserBook busca la reseña del usuario sobre el libro.
+func (r *SQLReviewRepo) GetByUserBook(ctx context.Context, userID, bookID uint64) (*domain.Review, error) {
+	return scanReview(r.db.QueryRowContext(ctx,
+		`SELECT `+reviewColumns+` FROM reviews r WHERE r.user_id=? AND r.book_id=?`, userID, bookID))
+}
+
+// Update guarda estrellas, texto y estado; prev (si no es nil) pasa al
+// historial en la misma transacción.
+func (r *SQLReviewRepo) Update(ctx context.Context, rv *domain.Review, prev *domain.ReviewRevision) error {
+	return r.db.inTx(ctx, func(tx txConn) error {
+		res, err := tx.ExecContext(ctx,
+			`UPDATE reviews SET rating=?,text=?,status=?,updated_at=?,moderated_at=? WHERE id=?`,
+			rv.Rating(), rv.Text(), string(rv.Status()), rv.UpdatedAt().UTC(), nullableTime(rv.ModeratedAt()), rv.ID())
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return domain.ErrNotFound
+		}
+		if prev == nil {
+			return nil
+		}
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO review_revisions (review_id,rating,text,written_at) VALUES (?,?,?,?)`,
+			rv.ID(), prev.Rating, prev.Text, prev.WrittenAt.UTC())
+		return err
+	})
+}
+
+// Delete elimina la reseña (su historial cae en cascada).
+func (r *SQLReviewRepo) Delete(ctx context.Context, id uint64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM reviews WHERE id=?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// List retorna reseñas paginadas con título del libro y nombre del autor.
+func (r *SQLReviewRepo) List(ctx context.Context, f domain.ReviewFilter) (domain.Page[domain.ReviewDetail], error) {
+	pg, err := f.Paging()
+	if err != nil {
+		return domain.Page[domain.ReviewDetail]{}, err
+	}
+
+	cond := "1=1"
+	args := []any{}
+	if f.UserID != 0 {
+		cond += " AND r.user_id=?"
+		args = append(args, f.UserID)
+	}
+	if f.BookID != 0 {
+		cond += " AND r.book_id=?"
+		args = append(args, f.BookID)
+	}
+	if len(f.Statuses) > 0 {
+		cond += " AND r.status IN (" + placeholders(len(f.Statuses)) + ")"
+		for _, st := range f.Statuses {
+			args = append(args, string(st))
+		}
+	}
+	if f.Rating != 0 {
+		cond += " AND r.rating=?"
+		args = append(args, f.Rating)
+	}
+
+	page := domain.Page[domain.ReviewDetail]{Page: pg.Page, PageSize: pg.PageSize}
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM reviews r WHERE `+cond, args...).Scan(&page.Total); err != nil {
+		return page, err
+	}
+
+	// La tabla derivada evita que el "id" de desempate de orderBy sea ambiguo
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+reviewColumns+`,r.title,r.user_name FROM (
+		   SELECT r.*,b.title,u.name AS user_name
+		   FROM reviews r JOIN books b ON b.id=r.book_id JOIN users u ON u.id=r.user_id
+		 ) r WHERE `+cond+` ORDER BY `+orderBy(reviewSortColumns, pg)+` LIMIT ? OFFSET ?`,
+		append(args, pg.PageSize, pg.Offset())...)
+	if err != nil {
+		return page, err
+	}
+	defer rows.Close()
+
+	page.Items = []domain.ReviewDetail{}
+	for rows.Next() {
+		var d domain.ReviewDetail
+		rv, err := scanReview(withExtra(rows, &d.BookTitle, &d.UserName))
+		if err != nil {
+			return page, err
+		}
+		d.Review = rv
+		page.Items = append(page.Items, d)
+	}
+	return page, rows.Err()
+}
+
+// History retorna las versiones anteriores de la reseña en orden de edición.
+func (r *SQLReviewRepo) History(ctx context.Context, reviewID uint64) ([]domain.ReviewRevision, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT rating,text,written_at FROM review_revisions WHERE review_id=? ORDER BY id`, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []domain.ReviewRevision{}
+	for rows.Next() {
+		var (
+			rev       domain.ReviewRevision
+			writtenAt dbTime
+		)
+		if err := rows.Scan(&rev.Rating, &rev.Text, &writtenAt); err != nil {
+			return nil, err
+		}
+		rev.WrittenAt = writtenAt.Time
+		out = append(out, rev)
+	}
+	return out, rows.Err()
+}
+
+// Summaries cuenta las reseñas aprobadas por libro y estrellas (por lotes,
+// como las etiquetas de los libros).
+func (r *SQLReviewRepo) Summaries(ctx context.Context, bookIDs []uint64) (map[uint64]domain.RatingSummary, error) {
+	out := map[uint64]domain.RatingSummary{}
+	for from := 0; from < len(bookIDs); from += tagBatch {
+		batch := bookIDs[from:min(from+tagBatch, len(bookIDs))]
+		ids := make([]any, len(batch))
+		for i, id := range batch {
+			ids[i] = id
+		}
+
+		rows, err := r.db.QueryContext(ctx,
+			`SELECT book_id,rating,COUNT(*) FROM reviews
+			 WHERE status='approved' AND book_id IN (`+placeholders(len(ids))+`)
+			 GROUP BY book_id,rating`, ids...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var (
+				bookID        uint64
+				rating, count int
+			)
+			if err := rows.Scan(&bookID, &rating, &count); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			s := out[bookID]
+			s.BookID = bookID
+			s.Add(rating, count)
+			out[bookID] = s
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// scanReview convierte una fila (reviewColumns) en entidad.
+func scanReview(row interface{ Scan(dest ...any) error }) (*domain.Review, error) {
+	var (
+		id, userID, bookID                uint64
+		rating                            int
+		text, status                      string
+		createdAt, updatedAt, moderatedAt dbTime // NULL => tiempo cero
+	)
+	if err := row.Scan(&id, &userID, &bookID, &rating, &text, &status, &createdAt, &updatedAt, &moderatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return domain.HydrateReview(id, userID, bookID, rating, text, domain.ReviewStatus(status),
+		createdAt.Time, updatedAt.Time, moderatedAt.Time), nil
+}
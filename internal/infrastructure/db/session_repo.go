@@ -0,0 +1,80 @@
+package db // Infraestructura DB: sesiones de login
+
+import (
+	"context"      // Para timeouts/cancelación
+	"database/sql" // Driver SQL estándar
+	"errors"       // Para comparar errores (errors.Is)
+	"time"         // Para expiración
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain" // Dominio (Session + errores)
+)
+
+// SQLSessionRepo guarda las sesiones activas en la tabla sessions.
+// Solo se persiste el hash del token, nunca el token de la cookie.
+type SQLSessionRepo struct {
+	db conn // conexión + dialecto del motor
+}
+
+// NewMySQLSessionRepo inyecta la conexión (MySQL).
+func NewMySQLSessionRepo(db *sql.DB) *SQLSessionRepo {
+	return &SQLSessionRepo{db: conn{db, mysqlDialect}}
+}
+
+// NewSQLiteSessionRepo inyecta la conexión (SQLite).
+func NewSQLiteSessionRepo(db *sql.DB) *SQLSessionRepo {
+	return &SQLSessionRepo{db: conn{db, sqliteDialect}}
+}
+
+// Create inserta una sesión nueva.
+func (r *SQLSessionRepo) Create(ctx context.Context, s *domain.Session) error {
+	_, err := r.db.exec(
+		ctx,
+		`INSERT INTO sessions (token_hash,user_id,expires_at) VALUES (?,?,?)`,
+		s.TokenHash(),
+		s.UserID(),
+		s.ExpiresAt().UTC(),
+	)
+	return err
+}
+
+// GetByTokenHash busca la sesión por hash del token.
+// Si no existe, retorna domain.ErrNotFound.
+func (r *SQLSessionRepo) GetByTokenHash(ctx context.Context, tokenHash string) (*domain.Session, error) {
+	row := r.db.QueryRowContext(
+		ctx,
+		`SELECT token_hash,user_id,expires_at,created_at FROM sessions WHERE token_hash=?`,
+		tokenHash,
+	)
+
+	var (
+		hash                 string
+		userID               uint64
+		expiresAt, createdAt dbTime
+	)
+	if err := row.Scan(&hash, &userID, &expiresAt, &createdAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return domain.HydrateSession(hash, userID, expiresAt.Time, createdAt.Time), nil
+}
+
+// Delete elimina una sesión (logout).
+func (r *SQLSessionRepo) Delete(ctx context.Context, tokenHash string) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM sessions WHERE token_hash=?`, tokenHash)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteExpired purga sesiones vencidas.
+func (r *SQLSessionRepo) DeleteExpired(ctx context.Context, now time.Time) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM sessions WHERE expires_at<=?`, now.UTC())
+	return err
+}
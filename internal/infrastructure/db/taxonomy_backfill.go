@@ -0,0 +1,158 @@
+package db // Infraestructura DB: paso en Go de la migración 0005 (taxonomía)
+
+import (
+	"context"      // Timeouts/cancelación
+	"database/sql" // sql.ErrNoRows
+	"errors"       // Para comparar errores (errors.Is)
+	"strings"      // CSV heredado y normalización
+	"unicode"      // Clasificación de caracteres del slug
+	"unicode/utf8" // Largo de nombres en caracteres
+
+	"golang.org/x/text/runes"        // Quitar diacríticos
+	"golang.org/x/text/transform"    // Cadena de transformaciones
+	"golang.org/x/text/unicode/norm" // Descomposición NFD
+)
+
+// Este paso está congelado: ni su SQL ni sus reglas usan el repositorio o
+// el dominio, que siguen al esquema y a las reglas actuales. Los helpers de
+// abajo son copias de domain.Slugify, domain.NormalizeTag y de la
+// validación de domain.NewCategory tal como eran al escribir 0005; cambiar
+// esas funciones no debe cambiar lo que produce una migración vieja.
+
+const (
+	legacyCategory    = "Sin categoría" // reemplaza categorías heredadas sin letras ni dígitos
+	backfillTagLen    = 60              // tags.name en 0005
+	backfillCatLen    = 120             // categories.name en 0005
+	backfillCatMinLen = 2               // largo mínimo de una categoría (en bytes)
+)
+
+// backfillTaxonomy es el paso en Go de la migración 0005: crea categorías y
+// etiquetas (por slug) desde books.category y el CSV books.tags, y enlaza cada
+// libro. Corre antes de que 0006 elimine books.tags, en la transacción de la
+// migración; puede repetirse (busca por slug y reemplaza las etiquetas).
+func backfillTaxonomy(ctx context.Context, tx txConn) error {
+	type legacyBook struct {
+		id             uint64
+		category, tags string
+	}
+	rows, err := tx.QueryContext(ctx, `SELECT id,category,tags FROM books ORDER BY id`)
+	if err != nil {
+		return err
+	}
+	var list []legacyBook
+	for rows.Next() {
+		var b legacyBook
+		if err := rows.Scan(&b.id, &b.category, &b.tags); err != nil {
+			rows.Close()
+			return err
+		}
+		list = append(list, b)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, b := range list {
+		catID, canonical, err := backfillSlug(ctx, tx, "categories", legacyCategoryName(b.category))
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE books SET category=?,category_id=?,updated_at=updated_at WHERE id=?`, canonical, catID, b.id,
+		); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM book_tags WHERE book_id=?`, b.id); err != nil {
+			return err
+		}
+		for i, tag := range legacyTags(b.tags) {
+			tagID, _, err := backfillSlug(ctx, tx, "tags", tag)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx,
+				`INSERT INTO book_tags (book_id,tag_id,position) VALUES (?,?,?)`, b.id, tagID, i,
+			); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// backfillSlug devuelve el ID y el nombre de la fila de table (categories o
+// tags) con el slug de name, creándola si falta. Es parte de la migración
+// 0005: nadie más escribe mientras corre.
+func backfillSlug(ctx context.Context, tx txConn, table, name string) (uint64, string, error) {
+	var (
+		id        uint64
+		canonical string
+	)
+	slug := legacySlug(name)
+	query := `SELECT id,name FROM ` + table + ` WHERE slug=?`
+	err := tx.QueryRowContext(ctx, query, slug).Scan(&id, &canonical)
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, err = tx.ExecContext(ctx, `INSERT INTO `+table+` (name,slug) VALUES (?,?)`, name, slug); err == nil {
+			err = tx.QueryRowContext(ctx, query, slug).Scan(&id, &canonical)
+		}
+	}
+	return id, canonical, err
+}
+
+// legacyCategoryName limpia books.category con los espacios colapsados; si
+// no es una categoría válida (menos de 2 bytes, sin letras ni dígitos o de
+// más de 120 caracteres) queda legacyCategory.
+func legacyCategoryName(raw string) string {
+	name := strings.Join(strings.Fields(raw), " ")
+	if len(name) < backfillCatMinLen || legacySlug(name) == "" || utf8.RuneCountInString(name) > backfillCatLen {
+		return legacyCategory
+	}
+	return name
+}
+
+// legacyTags separa el CSV de books.tags y normaliza cada etiqueta
+// (espacios colapsados y minúsculas): recorta las de más de 60 caracteres y
+// descarta vacías y repetidas.
+func legacyTags(csv string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, t := range strings.Split(csv, ",") {
+		t = strings.ToLower(strings.Join(strings.Fields(t), " "))
+		if r := []rune(t); len(r) > backfillTagLen {
+			t = strings.TrimSpace(string(r[:backfillTagLen]))
+		}
+		slug := legacySlug(t)
+		if slug == "" || seen[slug] {
+			continue
+		}
+		seen[slug] = true
+		out = append(out, t)
+	}
+	return out
+}
+
+// legacySlug es el slug de 0005: minúsculas, sin acentos y con guiones entre
+// palabras ("Ciencia Ficción" -> "ciencia-ficcion"); "+" y "#" se conservan.
+func legacySlug(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	if folded, _, err := transform.String(t, s); err == nil {
+		s = folded
+	}
+
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '+' || r == '#':
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteRune(r)
+		default:
+			dash = true
+		}
+	}
+	return b.String()
+}
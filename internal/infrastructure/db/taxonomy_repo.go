@@ -0,0 +1,449 @@
+package db // Infraestructura DB: etiquetas, árbol de categorías y su relación con libros
+
+import (
+	"context"      // Para timeouts/cancelación
+	"database/sql" // Driver SQL estándar
+	"errors"       // Para comparar errores (errors.Is)
+	"strings"      // Filtro por nombre y CSV heredado
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain" // Dominio (Tag, Category + errores)
+)
+
+// SQLTaxonomyRepo persiste etiquetas (tags, book_tags) y categorías
+// (categories, books.category_id).
+type SQLTaxonomyRepo struct {
+	db conn // conexión + dialecto del motor
+}
+
+// NewMySQLTaxonomyRepo inyecta la conexión (MySQL).
+func NewMySQLTaxonomyRepo(db *sql.DB) *SQLTaxonomyRepo {
+	return &SQLTaxonomyRepo{db: conn{db, mysqlDialect}}
+}
+
+// NewSQLiteTaxonomyRepo inyecta la conexión (SQLite).
+func NewSQLiteTaxonomyRepo(db *sql.DB) *SQLTaxonomyRepo {
+	return &SQLTaxonomyRepo{db: conn{db, sqliteDialect}}
+}
+
+const (
+	tagColumns      = `id,name,slug,created_at`
+	categoryColumns = `id,name,slug,COALESCE(parent_id,0),created_at,COALESCE(updated_at,created_at)`
+)
+
+// tagSortColumns traduce los campos de orden públicos a expresiones SQL
+// (book_count es la columna calculada de ListTags).
+var tagSortColumns = map[string]string{
+	domain.SortCreatedAt: "id",
+	"name":               "name",
+	"books":              "book_count",
+}
+
+// -------------------- Etiquetas --------------------
+
+// ListTags retorna la página de etiquetas (con cantidad de libros) cuyo
+// nombre o slug contiene f.Q.
+func (r *SQLTaxonomyRepo) ListTags(ctx context.Context, f domain.TagFilter) (domain.Page[domain.TagUsage], error) {
+	p, err := f.Paging()
+	if err != nil {
+		return domain.Page[domain.TagUsage]{}, err
+	}
+
+	cond := "1=1"
+	args := []any{}
+	if q := strings.TrimSpace(f.Q); q != "" {
+		cond = r.db.d.like("name")
+		args = append(args, "%"+strings.ToLower(q)+"%")
+		if slug := domain.Slugify(q); slug != "" {
+			cond = "(" + cond + " OR slug LIKE ?)"
+			args = append(args, "%"+slug+"%")
+		}
+	}
+
+	page := domain.Page[domain.TagUsage]{Page: p.Page, PageSize: p.PageSize}
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM tags WHERE `+cond, args...).Scan(&page.Total); err != nil {
+		return page, err
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+tagColumns+`,(SELECT COUNT(*) FROM book_tags bt WHERE bt.tag_id=tags.id) AS book_count
+		 FROM tags WHERE `+cond+` ORDER BY `+orderBy(tagSortColumns, p)+` LIMIT ? OFFSET ?`,
+		append(args, p.PageSize, p.Offset())...)
+	if err != nil {
+		return page, err
+	}
+	defer rows.Close()
+
+	page.Items = []domain.TagUsage{}
+	for rows.Next() {
+		var n int
+		t, err := scanTag(withExtra(rows, &n))
+		if err != nil {
+			return page, err
+		}
+		page.Items = append(page.Items, domain.TagUsage{Tag: t, Books: n})
+	}
+	return page, rows.Err()
+}
+
+// GetTag busca una etiqueta por ID.
+func (r *SQLTaxonomyRepo) GetTag(ctx context.Context, id uint64) (*domain.Tag, error) {
+	return scanTag(r.db.QueryRowContext(ctx, `SELECT `+tagColumns+` FROM tags WHERE id=?`, id))
+}
+
+// RenameTag guarda el nuevo nombre/slug (ErrDuplicate si el slug ya es de
+// otra etiqueta). Retorna los libros que la usan.
+func (r *SQLTaxonomyRepo) RenameTag(ctx context.Context, t *domain.Tag) ([]uint64, error) {
+	var affected []uint64
+	err := r.db.inTx(ctx, func(tx txConn) error {
+		if err := tx.mustExist(ctx, "tags", t.ID()); err != nil {
+			return err
+		}
+		if _, err := tx.exec(ctx, `UPDATE tags SET name=?,slug=? WHERE id=?`, t.Name(), t.Slug(), t.ID()); err != nil {
+			return err
+		}
+		var err error
+		affected, err = tx.ids(ctx, `SELECT book_id FROM book_tags WHERE tag_id=? ORDER BY book_id`, t.ID())
+		return err
+	})
+	return affected, err
+}
+
+// MergeTags pasa los libros de dupID a keepID (sin repetir la etiqueta en un
+// libro que ya tenía ambas) y elimina dupID. Retorna los libros afectados.
+func (r *SQLTaxonomyRepo) MergeTags(ctx context.Context, keepID, dupID uint64) ([]uint64, error) {
+	var affected []uint64
+	err := r.db.inTx(ctx, func(tx txConn) error {
+		for _, id := range []uint64{keepID, dupID} {
+			if err := tx.mustExist(ctx, "tags", id); err != nil {
+				return err
+			}
+		}
+		var err error
+		affected, err = tx.ids(ctx, `SELECT book_id FROM book_tags WHERE tag_id=? ORDER BY book_id`, dupID)
+		if err != nil {
+			return err
+		}
+
+		for _, bookID := range affected {
+			var n int
+			if err := tx.QueryRowContext(ctx,
+				`SELECT COUNT(*) FROM book_tags WHERE book_id=? AND tag_id=?`, bookID, keepID).Scan(&n); err != nil {
+				return err
+			}
+			q := `UPDATE book_tags SET tag_id=? WHERE book_id=? AND tag_id=?`
+			args := []any{keepID, bookID, dupID}
+			if n > 0 {
+				q = `DELETE FROM book_tags WHERE book_id=? AND tag_id=?`
+				args = args[1:]
+			}
+			if _, err := tx.exec(ctx, q, args...); err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM tags WHERE id=?`, dupID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return affected, nil
+}
+
+// DeleteTag elimina la etiqueta y la quita de todos los libros.
+// Retorna los libros afectados.
+func (r *SQLTaxonomyRepo) DeleteTag(ctx context.Context, id uint64) ([]uint64, error) {
+	var affected []uint64
+	err := r.db.inTx(ctx, func(tx txConn) error {
+		if err := tx.mustExist(ctx, "tags", id); err != nil {
+			return err
+		}
+		var err error
+		if affected, err = tx.ids(ctx, `SELECT book_id FROM book_tags WHERE tag_id=? ORDER BY book_id`, id); err != nil {
+			return err
+		}
+		// Explícito: no depender de que SQLite tenga activado foreign_keys
+		if _, err := tx.ExecContext(ctx, `DELETE FROM book_tags WHERE tag_id=?`, id); err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `DELETE FROM tags WHERE id=?`, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return affected, nil
+}
+
+// -------------------- Categorías --------------------
+
+// Categories retorna todas las categorías con la cantidad de libros asignados
+// directamente a cada una (el árbol lo arma domain.BuildCategoryTree).
+func (r *SQLTaxonomyRepo) Categories(ctx context.Context) ([]domain.CategoryUsage, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+categoryColumns+`,(SELECT COUNT(*) FROM books WHERE books.category_id=categories.id)
+		 FROM categories ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []domain.CategoryUsage{}
+	for rows.Next() {
+		var n int
+		c, err := scanCategory(withExtra(rows, &n))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, domain.CategoryUsage{Category: c, Books: n})
+	}
+	return out, rows.Err()
+}
+
+// GetCategory busca una categoría por ID.
+func (r *SQLTaxonomyRepo) GetCategory(ctx context.Context, id uint64) (*domain.Category, error) {
+	return scanCategory(r.db.QueryRowContext(ctx, `SELECT `+categoryColumns+` FROM categories WHERE id=?`, id))
+}
+
+// CreateCategory inserta una categoría (ErrDuplicate si el slug ya existe).
+func (r *SQLTaxonomyRepo) CreateCategory(ctx context.Context, c *domain.Category) (uint64, error) {
+	return r.db.insert(ctx, `INSERT INTO categories (name,slug,parent_id) VALUES (?,?,?)`,
+		c.Name(), c.Slug(), nullableID(c.ParentID()))
+}
+
+// UpdateCategory guarda nombre y padre, y actualiza el nombre en sus libros
+// (books.category). Retorna los libros de la categoría.
+func (r *SQLTaxonomyRepo) UpdateCategory(ctx context.Context, c *domain.Category) ([]uint64, error) {
+	var affected []uint64
+	err := r.db.inTx(ctx, func(tx txConn) error {
+		if err := tx.mustExist(ctx, "categories", c.ID()); err != nil {
+			return err
+		}
+		// updated_at explícito: SQLite y PostgreSQL no tienen ON UPDATE CURRENT_TIMESTAMP
+		if _, err := tx.exec(ctx,
+			`UPDATE categories SET name=?,slug=?,parent_id=?,updated_at=CURRENT_TIMESTAMP WHERE id=?`,
+			c.Name(), c.Slug(), nullableID(c.ParentID()), c.ID(),
+		); err != nil {
+			return err
+		}
+		var err error
+		if affected, err = tx.ids(ctx, `SELECT id FROM books WHERE category_id=? ORDER BY id`, c.ID()); err != nil {
+			return err
+		}
+		return tx.renameBooksCategory(ctx, c.ID(), c.ID(), c.Name())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return affected, nil
+}
+
+// MergeCategories pasa libros y subcategorías de dupID a keepID y elimina
+// dupID. Si keepID estaba dentro de dupID, antes sube al lugar de dupID.
+// Retorna los libros que cambiaron de categoría.
+func (r *SQLTaxonomyRepo) MergeCategories(ctx context.Context, keepID, dupID uint64) ([]uint64, error) {
+	var affected []uint64
+	err := r.db.inTx(ctx, func(tx txConn) error {
+		parent := map[uint64]uint64{}
+		rows, err := tx.QueryContext(ctx, `SELECT id,COALESCE(parent_id,0) FROM categories`)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var id, p uint64
+			if err := rows.Scan(&id, &p); err != nil {
+				rows.Close()
+				return err
+			}
+			parent[id] = p
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		for _, id := range []uint64{keepID, dupID} {
+			if _, ok := parent[id]; !ok {
+				return domain.ErrNotFound
+			}
+		}
+
+		if isDescendant(parent, keepID, dupID) {
+			if _, err := tx.ExecContext(ctx, `UPDATE categories SET parent_id=? WHERE id=?`,
+				nullableID(parent[dupID]), keepID); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE categories SET parent_id=? WHERE parent_id=?`, keepID, dupID); err != nil {
+			return err
+		}
+
+		if affected, err = tx.ids(ctx, `SELECT id FROM books WHERE category_id=? ORDER BY id`, dupID); err != nil {
+			return err
+		}
+		var name string
+		if err := tx.QueryRowContext(ctx, `SELECT name FROM categories WHERE id=?`, keepID).Scan(&name); err != nil {
+			return err
+		}
+		if err := tx.renameBooksCategory(ctx, dupID, keepID, name); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM categories WHERE id=?`, dupID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return affected, nil
+}
+
+// DeleteCategory elimina una categoría (el servicio verifica antes que no
+// tenga libros ni subcategorías).
+func (r *SQLTaxonomyRepo) DeleteCategory(ctx context.Context, id uint64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM categories WHERE id=?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// isDescendant indica si id está dentro del subárbol de ancestor.
+func isDescendant(parent map[uint64]uint64, id, ancestor uint64) bool {
+	for p, steps := parent[id], 0; p != 0 && steps <= len(parent); p, steps = parent[p], steps+1 {
+		if p == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// -------------------- Helpers de escritura (transacción) --------------------
+
+// ensureCategory devuelve el ID y el nombre canónico de la categoría con el
+// slug de name, creándola en la raíz si no existe.
+func (t txConn) ensureCategory(ctx context.Context, name string) (uint64, string, error) {
+	return t.ensure(ctx, "categories", name)
+}
+
+// ensureTag devuelve el ID y el nombre canónico de la etiqueta con el slug
+// de name, creándola si no existe.
+func (t txConn) ensureTag(ctx context.Context, name string) (uint64, string, error) {
+	return t.ensure(ctx, "tags", name)
+}
+
+// ensure busca por slug en table (categories o tags) y crea la fila si falta.
+// El INSERT ignora la clave duplicada por si otra transacción la creó a la vez.
+func (t txConn) ensure(ctx context.Context, table, name string) (uint64, string, error) {
+	slug := domain.Slugify(name)
+	for attempt := 0; ; attempt++ {
+		var (
+			id        uint64
+			canonical string
+		)
+		err := t.QueryRowContext(ctx, `SELECT id,name FROM `+table+` WHERE slug=?`, slug).Scan(&id, &canonical)
+		if err == nil {
+			return id, canonical, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) || attempt > 0 {
+			return 0, "", err
+		}
+		if _, err := t.ExecContext(ctx, t.d.insertIgnore(table+" (name,slug)", "(?,?)"), name, slug); err != nil {
+			return 0, "", err
+		}
+	}
+}
+
+// setBookTags reemplaza las etiquetas del libro (en orden), creando las que
+// no existan. tags ya viene normalizado y sin repetidos (Book.SetTags).
+func (t txConn) setBookTags(ctx context.Context, bookID uint64, tags []string) error {
+	if _, err := t.ExecContext(ctx, `DELETE FROM book_tags WHERE book_id=?`, bookID); err != nil {
+		return err
+	}
+	for i, name := range tags {
+		tagID, _, err := t.ensureTag(ctx, name)
+		if err != nil {
+			return err
+		}
+		if _, err := t.exec(ctx,
+			`INSERT INTO book_tags (book_id,tag_id,position) VALUES (?,?,?)`, bookID, tagID, i,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renameBooksCategory asigna la categoría toID (y su nombre) a los libros de
+// fromID. updated_at=updated_at evita que MySQL lo cambie: es solo una
+// sincronización del nombre desnormalizado.
+func (t txConn) renameBooksCategory(ctx context.Context, fromID, toID uint64, name string) error {
+	_, err := t.ExecContext(ctx,
+		`UPDATE books SET category=?,category_id=?,updated_at=updated_at WHERE category_id=?`, name, toID, fromID)
+	return err
+}
+
+// mustExist retorna domain.ErrNotFound si table no tiene la fila id.
+func (t txConn) mustExist(ctx context.Context, table string, id uint64) error {
+	var one int
+	err := t.QueryRowContext(ctx, `SELECT 1 FROM `+table+` WHERE id=?`, id).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrNotFound
+	}
+	return err
+}
+
+// ids ejecuta una consulta de una columna de IDs.
+func (t txConn) ids(ctx context.Context, query string, args ...any) ([]uint64, error) {
+	rows, err := t.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []uint64
+	for rows.Next() {
+		var id uint64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	return out, rows.Err()
+}
+
+// -------------------- Scan --------------------
+
+// scanTag convierte una fila (tagColumns) en entidad.
+func scanTag(row interface{ Scan(dest ...any) error }) (*domain.Tag, error) {
+	var (
+		id         uint64
+		name, slug string
+		createdAt  dbTime
+	)
+	if err := row.Scan(&id, &name, &slug, &createdAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return domain.HydrateTag(id, name, slug, createdAt.Time), nil
+}
+
+// scanCategory convierte una fila (categoryColumns) en entidad.
+func scanCategory(row interface{ Scan(dest ...any) error }) (*domain.Category, error) {
+	var (
+		id, parentID         uint64
+		name, slug           string
+		createdAt, updatedAt dbTime
+	)
+	if err := row.Scan(&id, &name, &slug, &parentID, &createdAt, &updatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return domain.HydrateCategory(id, name, slug, parentID, createdAt.Time, updatedAt.Time), nil
+}
@@ -0,0 +1,267 @@
+package db // Infraestructura DB: repositorio de usuarios (MySQL / SQLite / PostgreSQL)
+
+import (
+	"context"      // Para timeouts/cancelación desde handlers -> DB
+	"database/sql" // Driver SQL estándar
+	"errors"       // Para comparar errores (errors.Is)
+	"strings"      // Para normalizar email
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain" // Dominio (entidad + errores)
+)
+
+// SQLUserRepo es la implementación concreta de domain.UserRepository en SQL.
+// Guarda internamente un *sql.DB para ejecutar consultas y el dialecto del motor.
+type SQLUserRepo struct {
+	db conn // conexión + dialecto del motor
+}
+
+// NewMySQLUserRepo es el "constructor" del repositorio para MySQL.
+// Inyecta la dependencia db.
+func NewMySQLUserRepo(db *sql.DB) *SQLUserRepo { return &SQLUserRepo{db: conn{db, mysqlDialect}} }
+
+// NewSQLiteUserRepo construye el repositorio para SQLite.
+func NewSQLiteUserRepo(db *sql.DB) *SQLUserRepo { return &SQLUserRepo{db: conn{db, sqliteDialect}} }
+
+// NewPostgresUserRepo construye el repositorio para PostgreSQL.
+func NewPostgresUserRepo(db *sql.DB) *SQLUserRepo { return &SQLUserRepo{db: conn{db, postgresDialect}} }
+
+// Create inserta un usuario en la tabla users.
+// Retorna el ID generado por el motor.
+func (r *SQLUserRepo) Create(ctx context.Context, u *domain.User) (uint64, error) {
+
+	// Inserta usuario. Nota: usamos getters (encapsulación).
+	// El dialecto traduce la clave duplicada (email) a domain.ErrDuplicate.
+	return r.db.insert(
+		ctx,
+		`INSERT INTO users (name,email,role,active,password_hash) VALUES (?,?,?,?,?)`,
+		u.Name(),         // nombre validado por dominio
+		u.Email(),        // email validado por dominio
+		string(u.Role()), // rol como string
+		u.Active(),       // el driver convierte bool al tipo del motor
+		u.PasswordHash(), // hash bcrypt (nunca texto plano)
+	)
+}
+
+// GetByID trae un usuario por ID.
+// Si no existe, retorna domain.ErrNotFound.
+func (r *SQLUserRepo) GetByID(ctx context.Context, id uint64) (*domain.User, error) {
+
+	// QueryRowContext retorna 1 fila máximo (o error)
+	row := r.db.QueryRowContext(
+		ctx,
+		`SELECT id,name,email,role,active,password_hash,created_at,COALESCE(updated_at,created_at)
+		 FROM users WHERE id=?`,
+		id,
+	)
+
+	// Variables donde se escanean columnas
+	var (
+		rid                  uint64
+		name, email, role    string
+		passwordHash         string
+		active               bool
+		createdAt, updatedAt dbTime
+	)
+
+	// Scan copia los valores del row a las variables
+	if err := row.Scan(&rid, &name, &email, &role, &active, &passwordHash, &createdAt, &updatedAt); err != nil {
+
+		// Si no hay filas, se traduce a ErrNotFound
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+
+		// Cualquier otro error se retorna tal cual
+		return nil, err
+	}
+
+	// HydrateUser reconstruye la entidad respetando validaciones y encapsulación
+	return domain.HydrateUser(
+		rid,
+		name,
+		email,
+		domain.Role(role),
+		active,
+		passwordHash,
+		createdAt.Time,
+		updatedAt.Time,
+	)
+}
+
+// GetByEmail trae usuario por email.
+func (r *SQLUserRepo) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+
+	// Normaliza email para evitar diferencias por mayúsculas/espacios
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	row := r.db.QueryRowContext(
+		ctx,
+		`SELECT id,name,email,role,active,password_hash,created_at,COALESCE(updated_at,created_at)
+		 FROM users WHERE email=?`,
+		email,
+	)
+
+	var (
+		rid                  uint64
+		name, em, role       string
+		passwordHash         string
+		active               bool
+		createdAt, updatedAt dbTime
+	)
+
+	if err := row.Scan(&rid, &name, &em, &role, &active, &passwordHash, &createdAt, &updatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return domain.HydrateUser(
+		rid,
+		name,
+		em,
+		domain.Role(role),
+		active,
+		passwordHash,
+		createdAt.Time,
+		updatedAt.Time,
+	)
+}
+
+// List retorna todos los usuarios ordenados descendentemente por ID (sin paginar).
+func (r *SQLUserRepo) List(ctx context.Context) ([]*domain.User, error) {
+	return r.query(ctx, `SELECT `+userColumns+` FROM users ORDER BY id DESC`)
+}
+
+// userSortColumns traduce los campos de orden públicos a expresiones SQL.
+// created_at se ordena por id (mismo orden de inserción, sin empates).
+var userSortColumns = map[string]string{
+	domain.SortCreatedAt: "id",
+	"name":               "LOWER(name)",
+	"email":              "email",
+	"role":               "role",
+}
+
+// Search retorna una página de usuarios que cumplen el filtro y el total de coincidencias.
+// Construye el WHERE dinámicamente (slices para condiciones y parámetros).
+func (r *SQLUserRepo) Search(ctx context.Context, f domain.UserFilter) (domain.Page[*domain.User], error) {
+
+	// Valida orden/dirección y aplica valores por defecto (página 1, 20 ítems)
+	p, err := f.Paging()
+	if err != nil {
+		return domain.Page[*domain.User]{}, err
+	}
+
+	where := []string{"1=1"}
+	args := []any{}
+
+	if f.Role != "" {
+		where = append(where, "role=?")
+		args = append(args, strings.ToUpper(string(f.Role)))
+	}
+	if f.Active != nil {
+		where = append(where, "active=?")
+		args = append(args, *f.Active)
+	}
+	if e := strings.TrimSpace(f.Email); e != "" {
+		where = append(where, r.db.d.like("email"))
+		args = append(args, "%"+strings.ToLower(e)+"%")
+	}
+	cond := strings.Join(where, " AND ")
+
+	page := domain.Page[*domain.User]{Page: p.Page, PageSize: p.PageSize}
+
+	// Primero el total (todas las páginas), luego solo la página pedida
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users WHERE `+cond, args...).Scan(&page.Total); err != nil {
+		return page, err
+	}
+
+	page.Items, err = r.query(
+		ctx,
+		`SELECT `+userColumns+` FROM users WHERE `+cond+` ORDER BY `+orderBy(userSortColumns, p)+` LIMIT ? OFFSET ?`,
+		append(args, p.PageSize, p.Offset())...,
+	)
+	return page, err
+}
+
+// userColumns son las columnas que espera query (en este orden).
+const userColumns = `id,name,email,role,active,password_hash,created_at,COALESCE(updated_at,created_at)`
+
+// query ejecuta un SELECT de usuarios (userColumns) y reconstruye las entidades.
+func (r *SQLUserRepo) query(ctx context.Context, query string, args ...any) ([]*domain.User, error) {
+
+	// QueryContext devuelve múltiples filas
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() // cierre de recursos
+
+	// Slice de salida (lista de usuarios)
+	out := []*domain.User{}
+
+	// Itera fila por fila
+	for rows.Next() {
+		var (
+			rid                  uint64
+			name, em, role       string
+			passwordHash         string
+			active               bool
+			createdAt, updatedAt dbTime
+		)
+
+		// Scan por cada fila
+		if err := rows.Scan(&rid, &name, &em, &role, &active, &passwordHash, &createdAt, &updatedAt); err != nil {
+			return nil, err
+		}
+
+		// Reconstruye entidad dominio
+		u, err := domain.HydrateUser(rid, name, em, domain.Role(role), active, passwordHash, createdAt.Time, updatedAt.Time)
+		if err != nil {
+			return nil, err
+		}
+
+		// Agrega al slice
+		out = append(out, u)
+	}
+
+	return out, rows.Err()
+}
+
+// Update actualiza un usuario existente.
+func (r *SQLUserRepo) Update(ctx context.Context, u *domain.User) error {
+
+	// updated_at explícito: SQLite y PostgreSQL no tienen ON UPDATE CURRENT_TIMESTAMP.
+	// Si falla por duplicado (email unique), el dialecto traduce a ErrDuplicate.
+	_, err := r.db.exec(
+		ctx,
+		`UPDATE users SET name=?, email=?, role=?, active=?, password_hash=?, updated_at=CURRENT_TIMESTAMP WHERE id=?`,
+		u.Name(),
+		u.Email(),
+		string(u.Role()),
+		u.Active(),
+		u.PasswordHash(),
+		u.ID(),
+	)
+	return err
+}
+
+// Delete elimina un usuario por ID.
+// Si no afectó filas, significa que no existía.
+func (r *SQLUserRepo) Delete(ctx context.Context, id uint64) error {
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM users WHERE id=?`, id)
+	if err != nil {
+		return err
+	}
+
+	// RowsAffected dice cuántas filas fueron eliminadas
+	n, _ := res.RowsAffected()
+
+	// Si no eliminó nada, no existía
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
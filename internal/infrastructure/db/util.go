@@ -1,17 +1,12 @@
-package db // Infraestructura DB: funciones utilitarias específicas de MySQL
+package db // Infraestructura DB: funciones utilitarias por motor
 
-import "strings"
+import (
+	"errors"
+	"strings"
+	"time"
 
-// boolToTiny convierte un bool de Go a un entero compatible con MySQL.
-// MySQL suele representar booleanos como TINYINT(1):
-// - true  -> 1
-// - false -> 0
-func boolToTiny(b bool) int {
-	if b {
-		return 1
-	}
-	return 0
-}
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
 
 // isMySQLDuplicate detecta errores de clave duplicada en MySQL.
 //
@@ -39,3 +34,60 @@ func isMySQLDuplicate(err error) bool {
 	// Detecta error de clave duplicada
 	return strings.Contains(msg, "duplicate") || strings.Contains(msg, "1062")
 }
+
+// isSQLiteDuplicate detecta violaciones UNIQUE/PRIMARY KEY en SQLite.
+// Igual que en MySQL, se evita depender del tipo de error del driver:
+// - "unique constraint failed"
+// - códigos extendidos 2067 (UNIQUE) y 1555 (PRIMARY KEY)
+func isSQLiteDuplicate(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "unique constraint failed") ||
+		strings.Contains(msg, "(2067)") || strings.Contains(msg, "(1555)")
+}
+
+// isPostgresDuplicate detecta unique_violation (SQLSTATE 23505) en PostgreSQL.
+// En vez de importar pgconn.PgError se usa la interfaz SQLState() que
+// expone el error del driver, manteniendo el bajo acoplamiento.
+func isPostgresDuplicate(err error) bool {
+	var st interface{ SQLState() string }
+	if errors.As(err, &st) {
+		return st.SQLState() == "23505"
+	}
+	return err != nil && strings.Contains(err.Error(), "SQLSTATE 23505")
+}
+
+// nullableTime convierte un time.Time cero en NULL (columnas opcionales).
+// Los valores no nulos se guardan en UTC.
+func nullableTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC()
+}
+
+// nullableID convierte un ID cero en NULL (claves foráneas opcionales).
+func nullableID(id uint64) any {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+// orderBy arma la cláusula ORDER BY (sin la palabra clave) para la página
+// ya normalizada. columns traduce el campo público a una expresión SQL fija,
+// así nunca se interpola texto del cliente. El id desempata para que el orden
+// sea estable entre páginas.
+func orderBy(columns map[string]string, p domain.PageRequest) string {
+	dir := "ASC"
+	if p.Dir == domain.SortDesc {
+		dir = "DESC"
+	}
+	col := columns[p.Sort]
+	if col == "" || col == "id" {
+		return "id " + dir
+	}
+	return col + " " + dir + ", id " + dir
+}
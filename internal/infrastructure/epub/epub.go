@@ -0,0 +1,401 @@
+// Package epub implementa usecase.EbookReader con la biblioteca estándar:
+// lee la estructura del EPUB (container.xml, paquete OPF, spine y tabla de
+// contenidos NCX o nav) y convierte cada capítulo XHTML en HTML seguro para
+// incrustarlo en la página del lector.
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"path"
+	"strings"
+
+	"golang.org/x/text/encoding/htmlindex"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+// maxEntrySize acota lo que se descomprime de cada archivo del EPUB, para
+// no reservar memoria sin límite ante un ZIP malicioso.
+const maxEntrySize = 16 << 20
+
+// imageTypes son los recursos que el lector sirve (SVG queda afuera: puede
+// traer scripts).
+var imageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// Reader lee EPUB 2 y 3.
+type Reader struct{}
+
+// NewReader construye el lector de EPUB.
+func NewReader() *Reader { return &Reader{} }
+
+// Outline lee los metadatos y los capítulos en orden de lectura. Los
+// títulos salen de la tabla de contenidos (nav en EPUB 3, NCX en EPUB 2).
+func (Reader) Outline(r io.ReaderAt, size int64) (domain.EbookOutline, error) {
+	b, err := open(r, size)
+	if err != nil {
+		return domain.EbookOutline{}, err
+	}
+	titles := b.tocTitles()
+	o := domain.EbookOutline{Title: b.title, Language: b.language}
+	for _, ch := range b.chapters {
+		ch.Title = titles[ch.Href]
+		o.Chapters = append(o.Chapters, ch)
+	}
+	return o, nil
+}
+
+// Chapter devuelve el capítulo index (desde 0) como HTML saneado: sin
+// scripts, estilos ni atributos de eventos, y con los enlaces internos y
+// las imágenes reescritos con links.
+func (Reader) Chapter(r io.ReaderAt, size int64, index int, links domain.EbookLinks) (string, error) {
+	b, err := open(r, size)
+	if err != nil {
+		return "", err
+	}
+	if index < 0 || index >= len(b.chapters) {
+		return "", fmt.Errorf("%w: chapter %d", domain.ErrNotFound, index+1)
+	}
+	href := b.chapters[index].Href
+	raw, err := b.read(href)
+	if err != nil {
+		return "", err
+	}
+
+	s := &sanitizer{base: href, book: b, links: links, chapters: map[string]int{}}
+	for i, ch := range b.chapters {
+		s.chapters[ch.Href] = i
+	}
+	if err := s.run(raw); err != nil {
+		return "", fmt.Errorf("%w: unreadable chapter %s (%v)", domain.ErrValidation, href, err)
+	}
+	return s.out.String(), nil
+}
+
+// Resource devuelve una imagen del EPUB y su tipo MIME. Solo se sirven las
+// imágenes declaradas en el manifiesto; el resto es ErrNotFound.
+func (Reader) Resource(r io.ReaderAt, size int64, name string) ([]byte, string, error) {
+	b, err := open(r, size)
+	if err != nil {
+		return nil, "", err
+	}
+	mediaType, ok := b.images[name]
+	if !ok {
+		return nil, "", fmt.Errorf("%w: resource %s", domain.ErrNotFound, name)
+	}
+	data, err := b.read(name)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, mediaType, nil
+}
+
+// -------------------- Paquete OPF --------------------
+
+// book es lo que se lee del paquete OPF. Las rutas quedan resueltas
+// respecto de la raíz del ZIP.
+type book struct {
+	zip      *zip.Reader
+	title    string
+	language string
+	chapters []domain.EbookChapter
+	images   map[string]string // ruta → tipo MIME
+	nav      string            // documento nav de EPUB 3 ("" si no hay)
+	ncx      string            // tabla NCX de EPUB 2 ("" si no hay)
+}
+
+type containerXML struct {
+	Rootfiles []struct {
+		FullPath  string `xml:"full-path,attr"`
+		MediaType string `xml:"media-type,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+type packageXML struct {
+	Metadata struct {
+		Title    []string `xml:"title"`
+		Language []string `xml:"language"`
+	} `xml:"metadata"`
+	Manifest []struct {
+		ID         string `xml:"id,attr"`
+		Href       string `xml:"href,attr"`
+		MediaType  string `xml:"media-type,attr"`
+		Properties string `xml:"properties,attr"`
+	} `xml:"manifest>item"`
+	Spine struct {
+		Toc   string `xml:"toc,attr"`
+		Items []struct {
+			IDRef  string `xml:"idref,attr"`
+			Linear string `xml:"linear,attr"`
+		} `xml:"itemref"`
+	} `xml:"spine"`
+}
+
+// open abre el ZIP y lee el paquete OPF que indica container.xml.
+func open(r io.ReaderAt, size int64) (*book, error) {
+	z, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, invalid(err)
+	}
+	b := &book{zip: z, images: map[string]string{}}
+
+	var c containerXML
+	if err := b.decode("META-INF/container.xml", &c); err != nil {
+		return nil, err
+	}
+	opf := ""
+	for _, rf := range c.Rootfiles {
+		if rf.MediaType == "" || rf.MediaType == "application/oebps-package+xml" {
+			opf = rf.FullPath
+			break
+		}
+	}
+	if opf == "" {
+		return nil, invalid(errors.New("no package document in container.xml"))
+	}
+
+	var p packageXML
+	if err := b.decode(opf, &p); err != nil {
+		return nil, err
+	}
+	if len(p.Metadata.Title) > 0 {
+		b.title = strings.TrimSpace(p.Metadata.Title[0])
+	}
+	if len(p.Metadata.Language) > 0 {
+		b.language = strings.TrimSpace(p.Metadata.Language[0])
+	}
+
+	type item struct{ href, mediaType string }
+	items := map[string]item{}
+	for _, it := range p.Manifest {
+		href, _, ok := resolve(opf, it.Href)
+		if !ok {
+			continue
+		}
+		mt := strings.ToLower(strings.TrimSpace(it.MediaType))
+		items[it.ID] = item{href: href, mediaType: mt}
+		if imageTypes[mt] {
+			b.images[href] = mt
+		}
+		if hasToken(it.Properties, "nav") {
+			b.nav = href
+		}
+	}
+	if it, ok := items[p.Spine.Toc]; ok {
+		b.ncx = it.href
+	}
+
+	for i, ref := range p.Spine.Items {
+		it, ok := items[ref.IDRef]
+		if !ok || ref.Linear == "no" {
+			continue
+		}
+		if it.mediaType != "application/xhtml+xml" && it.mediaType != "text/html" {
+			continue
+		}
+		b.chapters = append(b.chapters, domain.EbookChapter{Href: it.href, Spine: i})
+	}
+	if len(b.chapters) == 0 {
+		return nil, invalid(errors.New("no readable chapters in the spine"))
+	}
+	return b, nil
+}
+
+// read descomprime un archivo del EPUB (ErrNotFound si no existe).
+func (b *book) read(name string) ([]byte, error) {
+	f, err := b.zip.Open(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrInvalid) {
+			return nil, fmt.Errorf("%w: %s is not in the EPUB", domain.ErrNotFound, name)
+		}
+		return nil, invalid(err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxEntrySize+1))
+	if err != nil {
+		return nil, invalid(err)
+	}
+	if len(data) > maxEntrySize {
+		return nil, fmt.Errorf("%w: %s exceeds %d bytes", domain.ErrTooLarge, name, maxEntrySize)
+	}
+	return data, nil
+}
+
+// decode lee un archivo XML del EPUB en v.
+func (b *book) decode(name string, v any) error {
+	data, err := b.read(name)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return invalid(err)
+		}
+		return err
+	}
+	if err := newDecoder(data).Decode(v); err != nil {
+		return invalid(fmt.Errorf("%s: %v", name, err))
+	}
+	return nil
+}
+
+// -------------------- Tabla de contenidos --------------------
+
+type navPoint struct {
+	Label   string `xml:"navLabel>text"`
+	Content struct {
+		Src string `xml:"src,attr"`
+	} `xml:"content"`
+	Children []navPoint `xml:"navPoint"`
+}
+
+// tocTitles devuelve el primer título que la tabla de contenidos da a cada
+// capítulo. Una tabla ausente o ilegible no impide leer el libro: los
+// capítulos quedan sin título.
+func (b *book) tocTitles() map[string]string {
+	titles := map[string]string{}
+	add := func(base, href, label string) {
+		p, _, ok := resolve(base, href)
+		label = strings.Join(strings.Fields(label), " ")
+		if _, seen := titles[p]; ok && !seen && label != "" {
+			titles[p] = label
+		}
+	}
+
+	if b.nav != "" {
+		if data, err := b.read(b.nav); err == nil {
+			navTitles(data, func(href, label string) { add(b.nav, href, label) })
+		}
+	}
+	if len(titles) == 0 && b.ncx != "" {
+		var ncx struct {
+			Points []navPoint `xml:"navMap>navPoint"`
+		}
+		if err := b.decode(b.ncx, &ncx); err == nil {
+			var walk func([]navPoint)
+			walk = func(points []navPoint) {
+				for _, np := range points {
+					add(b.ncx, np.Content.Src, np.Label)
+					walk(np.Children)
+				}
+			}
+			walk(ncx.Points)
+		}
+	}
+	return titles
+}
+
+// navTitles recorre el <nav epub:type="toc"> de un documento nav y llama a
+// fn por cada enlace con su texto.
+func navTitles(data []byte, fn func(href, label string)) {
+	d := newDecoder(data)
+	navDepth := 0 // > 0 dentro del nav de la tabla de contenidos
+	href, inLink := "", false
+	var label strings.Builder
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch {
+			case navDepth > 0:
+				navDepth++
+				if t.Name.Local == "a" {
+					href, inLink = attr(t, "href"), true
+					label.Reset()
+				}
+			case t.Name.Local == "nav" && hasToken(attr(t, "type"), "toc"):
+				navDepth = 1
+			}
+		case xml.EndElement:
+			if navDepth == 0 {
+				continue
+			}
+			navDepth--
+			if t.Name.Local == "a" && inLink {
+				fn(href, label.String())
+				inLink = false
+			}
+			if navDepth == 0 {
+				return
+			}
+		case xml.CharData:
+			if inLink {
+				label.Write(t)
+			}
+		}
+	}
+}
+
+// -------------------- Utilidades --------------------
+
+// resolve resuelve una referencia relativa al documento base y la separa
+// de su fragmento. ok es false para URLs externas o inválidas.
+func resolve(base, ref string) (p, fragment string, ok bool) {
+	u, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Opaque != "" {
+		return "", "", false
+	}
+	switch {
+	case u.Path == "":
+		p = base
+	case strings.HasPrefix(u.Path, "/"):
+		p = strings.TrimPrefix(path.Clean(u.Path), "/")
+	default:
+		p = path.Join(path.Dir(base), u.Path)
+	}
+	return p, u.Fragment, true
+}
+
+// newDecoder arma un decodificador tolerante: muchos EPUB traen entidades
+// HTML (&nbsp;) o etiquetas sin cerrar pese a ser XHTML.
+func newDecoder(data []byte) *xml.Decoder {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	d.Strict = false
+	d.AutoClose = xml.HTMLAutoClose
+	d.Entity = xml.HTMLEntity
+	d.CharsetReader = func(label string, in io.Reader) (io.Reader, error) {
+		enc, err := htmlindex.Get(label)
+		if err != nil {
+			return nil, err
+		}
+		return enc.NewDecoder().Reader(in), nil
+	}
+	return d
+}
+
+// attr devuelve el valor del atributo por nombre local ("" si no está).
+func attr(t xml.StartElement, local string) string {
+	for _, a := range t.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// hasToken indica si la lista separada por espacios contiene tok.
+func hasToken(list, tok string) bool {
+	for _, f := range strings.Fields(list) {
+		if f == tok {
+			return true
+		}
+	}
+	return false
+}
+
+// invalid traduce un error de lectura del EPUB a ErrValidation.
+func invalid(err error) error {
+	return fmt.Errorf("%w: unreadable EPUB (%v)", domain.ErrValidation, err)
+}
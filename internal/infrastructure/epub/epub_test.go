@@ -0,0 +1,186 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+const container = `<?xml version="1.0"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles><rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/></rootfiles>
+</container>`
+
+const opf = `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title> El lector </dc:title><dc:language>es</dc:language>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>
+    <item id="cover" href="cover.xhtml" media-type="application/xhtml+xml"/>
+    <item id="c1" href="text/uno.xhtml" media-type="application/xhtml+xml"/>
+    <item id="c2" href="text/dos%20b.xhtml" media-type="application/xhtml+xml"/>
+    <item id="img" href="img/pic.png" media-type="image/png"/>
+    <item id="logo" href="img/logo.svg" media-type="image/svg+xml"/>
+  </manifest>
+  <spine toc="ncx">
+    <itemref idref="cover" linear="no"/>
+    <itemref idref="c1"/>
+    <itemref idref="c2"/>
+  </spine>
+</package>`
+
+const nav = `<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops"><body>
+  <nav epub:type="landmarks"><ol><li><a href="cover.xhtml">Portada</a></li></ol></nav>
+  <nav epub:type="toc"><ol>
+    <li><a href="text/uno.xhtml">Capítulo   <em>uno</em></a></li>
+    <li><a href="text/dos%20b.xhtml#s1">Dos</a></li>
+  </ol></nav>
+</body></html>`
+
+const ncx = `<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/"><navMap>
+  <navPoint><navLabel><text>Primero</text></navLabel><content src="text/uno.xhtml"/>
+    <navPoint><navLabel><text>Segundo</text></navLabel><content src="text/dos%20b.xhtml"/></navPoint>
+  </navPoint>
+</navMap></ncx>`
+
+const chapter = `<?xml version="1.0" encoding="UTF-8"?>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops">
+<head><title>Uno</title><style>p { color: red }</style><script>alert(1)</script></head>
+<body onload="x()">
+  <h1 id="top" class="t" epub:type="title">Uno&nbsp;&amp; más</h1>
+  <p style="color:red" onclick="x()">Ver <a href="dos%20b.xhtml#s1">el dos</a>, <a href="#top">arriba</a>,
+  <a href="https://example.com/x?a=1&amp;b=2">afuera</a> y <a href="javascript:alert(1)">nada</a>.<br></p>
+  <img src="../img/pic.png" alt="Foto"/><img src="../img/logo.svg" alt="Logo"/>
+  <svg xmlns="http://www.w3.org/2000/svg"><text>oculto</text></svg>
+  <custom>texto <b>suelto</b></custom>
+</body></html>`
+
+func newEPUB(t *testing.T, files map[string]string) (*bytes.Reader, int64) {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprint(f, content)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes()), int64(buf.Len())
+}
+
+func sample(t *testing.T, skip ...string) (*bytes.Reader, int64) {
+	t.Helper()
+	files := map[string]string{
+		"mimetype":               "application/epub+zip",
+		"META-INF/container.xml": container,
+		"OEBPS/content.opf":      opf,
+		"OEBPS/nav.xhtml":        nav,
+		"OEBPS/toc.ncx":          ncx,
+		"OEBPS/cover.xhtml":      `<html><body><p>Portada</p></body></html>`,
+		"OEBPS/text/uno.xhtml":   chapter,
+		"OEBPS/text/dos b.xhtml": `<html><body><h2 id="s1">Dos</h2></body></html>`,
+		"OEBPS/img/pic.png":      "png",
+		"OEBPS/img/logo.svg":     "<svg/>",
+	}
+	for _, name := range skip {
+		delete(files, name)
+	}
+	return newEPUB(t, files)
+}
+
+var links = domain.EbookLinks{
+	Chapter:  func(i int, frag string) string { return fmt.Sprintf("?ch=%d#%s", i+1, frag) },
+	Resource: func(p string) string { return "res?path=" + p },
+}
+
+func TestOutline(t *testing.T) {
+	r, size := sample(t)
+	o, err := NewReader().Outline(r, size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []domain.EbookChapter{
+		{Href: "OEBPS/text/uno.xhtml", Title: "Capítulo uno", Spine: 1},
+		{Href: "OEBPS/text/dos b.xhtml", Title: "Dos", Spine: 2},
+	}
+	if o.Title != "El lector" || o.Language != "es" || len(o.Chapters) != 2 || o.Chapters[0] != want[0] || o.Chapters[1] != want[1] {
+		t.Fatalf("unexpected outline %+v", o)
+	}
+
+	// Sin documento nav los títulos salen del NCX
+	r, size = sample(t, "OEBPS/nav.xhtml")
+	o, err = NewReader().Outline(r, size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Chapters[0].Title != "Primero" || o.Chapters[1].Title != "Segundo" {
+		t.Fatalf("expected NCX titles, got %+v", o.Chapters)
+	}
+
+	// Sin container.xml no es un EPUB
+	r, size = sample(t, "META-INF/container.xml")
+	if _, err := NewReader().Outline(r, size); !errors.Is(err, domain.ErrValidation) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	if _, err := NewReader().Outline(strings.NewReader("no es zip"), 9); !errors.Is(err, domain.ErrValidation) {
+		t.Fatalf("expected validation error for non-zip, got %v", err)
+	}
+}
+
+func TestChapterSanitizes(t *testing.T) {
+	r, size := sample(t)
+	got, err := NewReader().Chapter(r, size, 0, links)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"<h1 id=\"top\">Uno\u00a0&amp; más</h1>", // &nbsp; llega como carácter
+		`<p>Ver <a href="?ch=2#s1">el dos</a>`,
+		`<a href="#top">arriba</a>`,
+		`<a href="https://example.com/x?a=1&amp;b=2" rel="noopener noreferrer" target="_blank">afuera</a>`,
+		`<a>nada</a>.<br /></p>`,
+		`<img src="res?path=OEBPS/img/pic.png" alt="Foto" />`,
+		`<img alt="Logo" />`,
+		`texto <b>suelto</b>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in chapter:\n%s", want, got)
+		}
+	}
+	for _, bad := range []string{"script", "alert", "style", "onclick", "onload", "class", "epub:", "oculto", "<custom", "<body", "<title"} {
+		if strings.Contains(got, bad) {
+			t.Errorf("unexpected %q in chapter:\n%s", bad, got)
+		}
+	}
+
+	if _, err := NewReader().Chapter(r, size, 2, links); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a missing chapter, got %v", err)
+	}
+}
+
+func TestResource(t *testing.T) {
+	r, size := sample(t)
+	data, mediaType, err := NewReader().Resource(r, size, "OEBPS/img/pic.png")
+	if err != nil || string(data) != "png" || mediaType != "image/png" {
+		t.Fatalf("Resource = %q, %q, %v", data, mediaType, err)
+	}
+	// Solo imágenes rasterizadas del manifiesto
+	for _, name := range []string{"OEBPS/img/logo.svg", "OEBPS/text/uno.xhtml", "OEBPS/img/otra.png", "../etc/passwd"} {
+		if _, _, err := NewReader().Resource(r, size, name); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("Resource(%q): expected ErrNotFound, got %v", name, err)
+		}
+	}
+}
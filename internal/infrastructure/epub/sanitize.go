@@ -0,0 +1,175 @@
+package epub
+
+import (
+	"encoding/xml"
+	"html"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+// allowedTags son los elementos que se copian al HTML del lector. Los
+// demás se descartan conservando su contenido (body, section desconocidas,
+// epub:switch...), salvo los de dropTags, que se descartan enteros.
+var allowedTags = map[string]bool{
+	"a": true, "abbr": true, "article": true, "aside": true, "b": true, "blockquote": true,
+	"br": true, "caption": true, "cite": true, "code": true, "dd": true, "del": true,
+	"div": true, "dl": true, "dt": true, "em": true, "figcaption": true, "figure": true,
+	"footer": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"header": true, "hr": true, "i": true, "img": true, "ins": true, "li": true, "mark": true,
+	"ol": true, "p": true, "pre": true, "q": true, "rp": true, "rt": true, "ruby": true,
+	"s": true, "section": true, "small": true, "span": true, "strong": true, "sub": true,
+	"sup": true, "table": true, "tbody": true, "td": true, "tfoot": true, "th": true,
+	"thead": true, "tr": true, "u": true, "ul": true,
+}
+
+var dropTags = map[string]bool{
+	"audio": true, "button": true, "canvas": true, "embed": true, "form": true, "head": true,
+	"iframe": true, "input": true, "link": true, "math": true, "meta": true, "noscript": true,
+	"object": true, "script": true, "select": true, "style": true, "svg": true,
+	"template": true, "textarea": true, "title": true, "video": true,
+}
+
+var voidTags = map[string]bool{"br": true, "hr": true, "img": true}
+
+// allowedAttrs son los atributos que se copian; href y src se tratan
+// aparte porque hay que reescribirlos.
+var allowedAttrs = map[string]bool{
+	"alt": true, "colspan": true, "dir": true, "height": true, "id": true, "lang": true,
+	"rowspan": true, "scope": true, "start": true, "title": true, "width": true,
+}
+
+// sanitizer copia el <body> de un capítulo XHTML dejando solo etiquetas y
+// atributos de la lista blanca.
+type sanitizer struct {
+	base     string         // ruta del capítulo, para resolver las relativas
+	book     *book          // manifiesto (imágenes)
+	chapters map[string]int // ruta → índice del capítulo
+	links    domain.EbookLinks
+
+	out   strings.Builder
+	open  []string // elementos abiertos; "" los que no se copiaron
+	inner int      // > 0 dentro de un elemento de dropTags
+}
+
+func (s *sanitizer) run(data []byte) error {
+	d := newDecoder(data)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if s.inner > 0 {
+				s.inner++
+				continue
+			}
+			name := strings.ToLower(t.Name.Local)
+			switch {
+			case dropTags[name]:
+				s.inner = 1
+			case allowedTags[name]:
+				s.start(name, t.Attr)
+				s.open = append(s.open, name)
+			default:
+				s.open = append(s.open, "")
+			}
+		case xml.EndElement:
+			if s.inner > 0 {
+				s.inner--
+				continue
+			}
+			if len(s.open) == 0 {
+				continue
+			}
+			name := s.open[len(s.open)-1]
+			s.open = s.open[:len(s.open)-1]
+			if name != "" && !voidTags[name] {
+				s.out.WriteString("</" + name + ">")
+			}
+		case xml.CharData:
+			if s.inner == 0 {
+				s.out.WriteString(html.EscapeString(string(t)))
+			}
+		}
+	}
+}
+
+// start escribe la etiqueta de apertura con los atributos permitidos.
+func (s *sanitizer) start(name string, attrs []xml.Attr) {
+	s.out.WriteString("<" + name)
+	external := false
+	for _, a := range attrs {
+		local := strings.ToLower(a.Name.Local)
+		if a.Name.Space != "" && a.Name.Space != "xml" && a.Name.Space != "http://www.w3.org/XML/1998/namespace" {
+			continue // epub:type, xlink:href, etc.
+		}
+		value := a.Value
+		switch {
+		case local == "href" && name == "a":
+			value, external = s.href(value)
+		case local == "src" && name == "img":
+			value = s.src(value)
+		case !allowedAttrs[local]:
+			value = ""
+		}
+		if value != "" {
+			s.out.WriteString(" " + local + `="` + html.EscapeString(value) + `"`)
+		}
+	}
+	if external {
+		s.out.WriteString(` rel="noopener noreferrer" target="_blank"`)
+	}
+	if voidTags[name] {
+		s.out.WriteString(" />")
+		return
+	}
+	s.out.WriteString(">")
+}
+
+// href reescribe un enlace: a otro capítulo pasa por links.Chapter, a una
+// ancla del mismo capítulo queda como "#ancla" y los externos http(s) o
+// mailto se conservan (external = true). Lo demás se descarta ("").
+func (s *sanitizer) href(ref string) (value string, external bool) {
+	if u, err := url.Parse(strings.TrimSpace(ref)); err == nil && u.Scheme != "" {
+		switch strings.ToLower(u.Scheme) {
+		case "http", "https", "mailto":
+			return u.String(), true
+		}
+		return "", false
+	}
+	p, frag, ok := resolve(s.base, ref)
+	if !ok {
+		return "", false
+	}
+	if p == s.base {
+		if frag == "" {
+			return "", false
+		}
+		return "#" + frag, false
+	}
+	if i, ok := s.chapters[p]; ok && s.links.Chapter != nil {
+		return s.links.Chapter(i, frag), false
+	}
+	return "", false
+}
+
+// src reescribe la fuente de una imagen del manifiesto con links.Resource;
+// las externas o no declaradas se descartan.
+func (s *sanitizer) src(ref string) string {
+	p, _, ok := resolve(s.base, ref)
+	if !ok || s.links.Resource == nil {
+		return ""
+	}
+	if _, ok := s.book.images[p]; !ok {
+		return ""
+	}
+	return s.links.Resource(p)
+}
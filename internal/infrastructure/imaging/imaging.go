@@ -0,0 +1,70 @@
+// Package imaging implementa usecase.ImageResizer con la biblioteca
+// estándar y golang.org/x/image (WebP y escalado de calidad).
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png" // registra el decodificador PNG
+	"io"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // registra el decodificador WebP
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+// thumbQuality es la calidad JPEG de las miniaturas.
+const thumbQuality = 85
+
+// Resizer decodifica JPEG, PNG y WebP y genera miniaturas JPEG.
+type Resizer struct{}
+
+// NewResizer construye el redimensionador.
+func NewResizer() *Resizer { return &Resizer{} }
+
+// Inspect lee solo la cabecera de la imagen (formato y dimensiones).
+func (Resizer) Inspect(r io.Reader) (domain.ImageInfo, error) {
+	cfg, format, err := image.DecodeConfig(r)
+	if err != nil {
+		return domain.ImageInfo{}, invalid(err)
+	}
+	return domain.ImageInfo{Format: format, Width: cfg.Width, Height: cfg.Height}, nil
+}
+
+// Thumbnail escala la imagen al ancho pedido manteniendo la proporción
+// (si ya es más angosta se deja de su tamaño) y la codifica en JPEG. Las
+// transparencias se aplanan sobre blanco, porque JPEG no tiene canal alfa.
+func (Resizer) Thumbnail(r io.Reader, width int) ([]byte, error) {
+	src, _, err := image.Decode(r)
+	if err != nil {
+		return nil, invalid(err)
+	}
+	b := src.Bounds()
+	if b.Dx() <= 0 || b.Dy() <= 0 {
+		return nil, fmt.Errorf("%w: empty image", domain.ErrValidation)
+	}
+	w, h := b.Dx(), b.Dy()
+	if w > width {
+		w, h = width, max(1, h*width/w)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: thumbQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// invalid traduce un error de decodificación (formato desconocido o imagen
+// corrupta) a ErrValidation.
+func invalid(err error) error {
+	return fmt.Errorf("%w: unreadable image (%v)", domain.ErrValidation, err)
+}
@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"context" // Firma del contrato
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain" // Entidad
+)
+
+// AccessRepo implementa usecase.AccessRepo sobre un Store.
+type AccessRepo struct{ s *Store }
+
+// NewAccessRepo construye el repositorio de eventos de acceso.
+func NewAccessRepo(s *Store) *AccessRepo { return &AccessRepo{s: s} }
+
+// Create registra el evento y retorna el ID asignado.
+func (r *AccessRepo) Create(ctx context.Context, e *domain.AccessEvent) (uint64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.nextAccess++
+	id := r.s.nextAccess
+	stored, err := domain.HydrateAccessEvent(id, e.UserID(), e.BookID(), e.AccessType(), e.Duration(), r.s.now())
+	if err != nil {
+		return 0, err
+	}
+	r.s.access[id] = stored
+	return id, nil
+}
+
+// StatsByBook cuenta accesos por tipo; siempre devuelve las 3 llaves.
+func (r *AccessRepo) StatsByBook(ctx context.Context, bookID uint64) (map[domain.AccessType]int, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	stats := map[domain.AccessType]int{
+		domain.AccessApertura: 0,
+		domain.AccessLectura:  0,
+		domain.AccessDescarga: 0,
+	}
+	for _, e := range r.s.access {
+		if e.BookID() == bookID {
+			stats[e.AccessType()]++
+		}
+	}
+	return stats, nil
+}
+
+// ReadingTime suma las sesiones LECTURA del libro y su duración.
+func (r *AccessRepo) ReadingTime(ctx context.Context, bookID uint64) (domain.ReadingTime, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var rt domain.ReadingTime
+	for _, e := range r.s.access {
+		if e.BookID() == bookID && e.AccessType() == domain.AccessLectura {
+			rt.Sessions++
+			rt.Total += e.Duration()
+		}
+	}
+	return rt, nil
+}
@@ -0,0 +1,116 @@
+package memory
+
+import (
+	"context" // Firma del contrato
+	"slices"  // Filtro por visibilidad
+	"strings" // Orden por ubicación
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain" // Entidades + errores
+)
+
+// AnnotationRepo implementa usecase.AnnotationRepo sobre un Store.
+type AnnotationRepo struct{ s *Store }
+
+// NewAnnotationRepo construye el repositorio de anotaciones.
+func NewAnnotationRepo(s *Store) *AnnotationRepo { return &AnnotationRepo{s: s} }
+
+// Create guarda la anotación. ErrNotFound si falta el usuario o el libro.
+func (r *AnnotationRepo) Create(ctx context.Context, a *domain.Annotation) (uint64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.users[a.UserID()]; !ok {
+		return 0, domain.ErrNotFound // clave foránea
+	}
+	if _, ok := r.s.books[a.BookID()]; !ok {
+		return 0, domain.ErrNotFound
+	}
+	r.s.nextAnnotation++
+	id := r.s.nextAnnotation
+	r.s.annotations[id] = domain.HydrateAnnotation(id, a.UserID(), a.BookID(), a.Content(), a.CreatedAt().UTC(), a.UpdatedAt().UTC())
+	return id, nil
+}
+
+// GetByID retorna una copia de la anotación o domain.ErrNotFound.
+func (r *AnnotationRepo) GetByID(ctx context.Context, id uint64) (*domain.Annotation, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	a, ok := r.s.annotations[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneAnnotation(a), nil
+}
+
+// Update guarda el contenido y la fecha de edición.
+func (r *AnnotationRepo) Update(ctx context.Context, a *domain.Annotation) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	prev, ok := r.s.annotations[a.ID()]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	r.s.annotations[a.ID()] = domain.HydrateAnnotation(a.ID(), prev.UserID(), prev.BookID(), a.Content(), prev.CreatedAt(), a.UpdatedAt().UTC())
+	return nil
+}
+
+// Delete elimina la anotación.
+func (r *AnnotationRepo) Delete(ctx context.Context, id uint64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.annotations[id]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.s.annotations, id)
+	return nil
+}
+
+// List filtra, ordena y pagina como el repositorio SQL.
+func (r *AnnotationRepo) List(ctx context.Context, f domain.AnnotationFilter) (domain.Page[domain.AnnotationDetail], error) {
+	p, err := f.Paging()
+	if err != nil {
+		return domain.Page[domain.AnnotationDetail]{}, err
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	out := []domain.AnnotationDetail{}
+	for _, a := range r.s.annotations {
+		switch {
+		case f.UserID != 0 && a.UserID() != f.UserID,
+			f.BookID != 0 && a.BookID() != f.BookID,
+			len(f.Visibilities) > 0 && !slices.Contains(f.Visibilities, a.Visibility()),
+			f.Color != "" && a.Color() != f.Color:
+			continue
+		}
+		d := domain.AnnotationDetail{Annotation: cloneAnnotation(a)}
+		if b, ok := r.s.books[a.BookID()]; ok {
+			d.BookTitle = b.Title()
+		}
+		if u, ok := r.s.users[a.UserID()]; ok {
+			d.UserName = u.Name()
+		}
+		out = append(out, d)
+	}
+	sortPaged(out, p, annotationSortFields[p.Sort], func(d domain.AnnotationDetail) uint64 { return d.Annotation.ID() })
+	return paginate(out, p), nil
+}
+
+// annotationSortFields replica annotationSortColumns del repositorio SQL.
+var annotationSortFields = map[string]func(a, b domain.AnnotationDetail) int{
+	domain.SortCreatedAt: nil, // orden de alta (id)
+	"updated_at": func(a, b domain.AnnotationDetail) int {
+		return a.Annotation.UpdatedAt().Compare(b.Annotation.UpdatedAt())
+	},
+	"location": func(a, b domain.AnnotationDetail) int {
+		return strings.Compare(a.Annotation.Location(), b.Annotation.Location())
+	},
+}
+
+func cloneAnnotation(a *domain.Annotation) *domain.Annotation {
+	return domain.HydrateAnnotation(a.ID(), a.UserID(), a.BookID(), a.Content(), a.CreatedAt(), a.UpdatedAt())
+}
@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"context" // Firma del contrato
+	"sort"    // Orden por ID descendente
+	"time"    // Revocación / último uso
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain" // Entidad + errores
+)
+
+// APITokenRepo implementa usecase.APITokenRepo sobre un Store.
+type APITokenRepo struct{ s *Store }
+
+// NewAPITokenRepo construye el repositorio de tokens de API.
+func NewAPITokenRepo(s *Store) *APITokenRepo { return &APITokenRepo{s: s} }
+
+// Create guarda el token y retorna el ID asignado.
+func (r *APITokenRepo) Create(ctx context.Context, t *domain.APIToken) (uint64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, other := range r.s.tokens {
+		if other.TokenHash() == t.TokenHash() {
+			return 0, domain.ErrDuplicate
+		}
+	}
+
+	r.s.nextToken++
+	id := r.s.nextToken
+	r.s.tokens[id] = domain.HydrateAPIToken(id, t.UserID(), t.Name(), t.TokenHash(), t.Prefix(), t.ScopesCSV(),
+		t.ExpiresAt(), time.Time{}, time.Time{}, r.s.now())
+	return id, nil
+}
+
+// GetByID busca un token por ID.
+func (r *APITokenRepo) GetByID(ctx context.Context, id uint64) (*domain.APIToken, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	t, ok := r.s.tokens[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneToken(t), nil
+}
+
+// GetByHash busca un token por el hash del secreto.
+func (r *APITokenRepo) GetByHash(ctx context.Context, tokenHash string) (*domain.APIToken, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, t := range r.s.tokens {
+		if t.TokenHash() == tokenHash {
+			return cloneToken(t), nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+// ListByUser lista los tokens del usuario (más recientes primero).
+func (r *APITokenRepo) ListByUser(ctx context.Context, userID uint64) ([]*domain.APIToken, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	out := []*domain.APIToken{}
+	for _, t := range r.s.tokens {
+		if t.UserID() == userID {
+			out = append(out, cloneToken(t))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID() > out[j].ID() })
+	return out, nil
+}
+
+// Revoke marca el token como revocado; ErrNotFound si no existe o ya lo estaba.
+func (r *APITokenRepo) Revoke(ctx context.Context, id uint64, at time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	t, ok := r.s.tokens[id]
+	if !ok || t.Revoked() {
+		return domain.ErrNotFound
+	}
+	c := cloneToken(t)
+	c.Revoke(at.UTC())
+	r.s.tokens[id] = c
+	return nil
+}
+
+// TouchLastUsed registra el último uso del token.
+func (r *APITokenRepo) TouchLastUsed(ctx context.Context, id uint64, at time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	t, ok := r.s.tokens[id]
+	if !ok {
+		return nil // igual que UPDATE sin filas: no es error
+	}
+	r.s.tokens[id] = domain.HydrateAPIToken(t.ID(), t.UserID(), t.Name(), t.TokenHash(), t.Prefix(), t.ScopesCSV(),
+		t.ExpiresAt(), at.UTC(), t.RevokedAt(), t.CreatedAt())
+	return nil
+}
+
+// cloneToken evita compartir el puntero guardado.
+func cloneToken(t *domain.APIToken) *domain.APIToken {
+	return domain.HydrateAPIToken(t.ID(), t.UserID(), t.Name(), t.TokenHash(), t.Prefix(), t.ScopesCSV(),
+		t.ExpiresAt(), t.LastUsedAt(), t.RevokedAt(), t.CreatedAt())
+}
@@ -0,0 +1,262 @@
+package memory
+
+import (
+	"cmp"     // Comparación de campos de orden
+	"context" // Firma del contrato
+	"sort"    // Orden de créditos y libros
+	"strings" // Normalización del nombre
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain" // Entidades + errores
+)
+
+// AuthorRepo implementa usecase.AuthorRepo sobre un Store.
+type AuthorRepo struct{ s *Store }
+
+// NewAuthorRepo construye el repositorio de autores y créditos.
+func NewAuthorRepo(s *Store) *AuthorRepo { return &AuthorRepo{s: s} }
+
+// Create guarda el autor y retorna el ID asignado.
+func (r *AuthorRepo) Create(ctx context.Context, a *domain.Author) (uint64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.nextAuthor++
+	id := r.s.nextAuthor
+	now := r.s.now()
+	r.s.authors[id] = domain.HydrateAuthor(id, a.Name(), a.Bio(), now, now)
+	return id, nil
+}
+
+// GetByID retorna una copia del autor o domain.ErrNotFound.
+func (r *AuthorRepo) GetByID(ctx context.Context, id uint64) (*domain.Author, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	a, ok := r.s.authors[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneAuthor(a), nil
+}
+
+// GetByName busca por nombre exacto sin distinguir mayúsculas (el de menor ID).
+func (r *AuthorRepo) GetByName(ctx context.Context, name string) (*domain.Author, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	name = key(strings.Join(strings.Fields(name), " "))
+	var found *domain.Author
+	for _, a := range r.s.authors {
+		if key(a.Name()) == name && (found == nil || a.ID() < found.ID()) {
+			found = a
+		}
+	}
+	if found == nil {
+		return nil, domain.ErrNotFound
+	}
+	return cloneAuthor(found), nil
+}
+
+// Search replica el filtro SQL (nombre "contiene") y retorna la página pedida.
+func (r *AuthorRepo) Search(ctx context.Context, f domain.AuthorFilter) (domain.Page[*domain.Author], error) {
+	p, err := f.Paging()
+	if err != nil {
+		return domain.Page[*domain.Author]{}, err
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	q := key(f.Q)
+	out := []*domain.Author{}
+	for _, a := range r.s.authors {
+		if q != "" && !containsFold(a.Name(), q) {
+			continue
+		}
+		out = append(out, cloneAuthor(a))
+	}
+	sortPaged(out, p, authorSortFields[p.Sort], (*domain.Author).ID)
+	return paginate(out, p), nil
+}
+
+// authorSortFields replica authorSortColumns del repositorio SQL.
+var authorSortFields = map[string]func(a, b *domain.Author) int{
+	domain.SortCreatedAt: nil, // orden de inserción (id)
+	"name": func(a, b *domain.Author) int {
+		return cmp.Compare(strings.ToLower(a.Name()), strings.ToLower(b.Name()))
+	},
+}
+
+// Update reemplaza nombre y biografía.
+func (r *AuthorRepo) Update(ctx context.Context, a *domain.Author) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	old, ok := r.s.authors[a.ID()]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	r.s.authors[a.ID()] = domain.HydrateAuthor(a.ID(), a.Name(), a.Bio(), old.CreatedAt(), r.s.now())
+	return nil
+}
+
+// Delete elimina el autor y sus créditos (como ON DELETE CASCADE).
+func (r *AuthorRepo) Delete(ctx context.Context, id uint64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.authors[id]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.s.authors, id)
+
+	for bookID, list := range r.s.credits {
+		kept := list[:0:0]
+		for _, c := range list {
+			if c.AuthorID != id {
+				kept = append(kept, c)
+			}
+		}
+		r.s.setCredits(bookID, kept)
+	}
+	return nil
+}
+
+// SetBookCredits reemplaza los créditos del libro (libro y autores deben existir).
+func (r *AuthorRepo) SetBookCredits(ctx context.Context, bookID uint64, credits []domain.BookCredit) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.books[bookID]; !ok {
+		return domain.ErrNotFound
+	}
+	seen := map[domain.BookCredit]bool{}
+	list := make([]domain.BookCredit, 0, len(credits))
+	for _, c := range credits {
+		if _, ok := r.s.authors[c.AuthorID]; !ok {
+			return domain.ErrNotFound
+		}
+		k := domain.BookCredit{AuthorID: c.AuthorID, Role: c.Role}
+		if seen[k] {
+			return domain.ErrDuplicate // clave primaria (book_id, author_id, role)
+		}
+		seen[k] = true
+		list = append(list, k)
+	}
+	r.s.setCredits(bookID, list)
+	return nil
+}
+
+// BookCredits retorna los créditos del libro en orden, con el nombre del autor.
+func (r *AuthorRepo) BookCredits(ctx context.Context, bookID uint64) ([]domain.BookCredit, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	out := []domain.BookCredit{}
+	for _, c := range r.s.credits[bookID] {
+		c.Name = r.s.authors[c.AuthorID].Name()
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// AuthorBooks retorna los libros del autor con su rol, por año y título.
+func (r *AuthorRepo) AuthorBooks(ctx context.Context, authorID uint64) ([]domain.AuthorBook, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	type row struct {
+		ab  domain.AuthorBook
+		pos int
+	}
+	rows := []row{}
+	for bookID, list := range r.s.credits {
+		for _, c := range list {
+			if c.AuthorID == authorID {
+				rows = append(rows, row{domain.AuthorBook{Book: cloneBook(r.s.books[bookID]), Role: c.Role}, c.Position})
+			}
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i].ab.Book, rows[j].ab.Book
+		if c := cmp.Or(
+			cmp.Compare(a.Year(), b.Year()),
+			cmp.Compare(strings.ToLower(a.Title()), strings.ToLower(b.Title())),
+			cmp.Compare(a.ID(), b.ID()),
+		); c != 0 {
+			return c < 0
+		}
+		return rows[i].pos < rows[j].pos
+	})
+
+	out := make([]domain.AuthorBook, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, r.ab)
+	}
+	return out, nil
+}
+
+// Merge pasa los créditos de dupID a keepID (descartando los repetidos) y
+// elimina dupID. Retorna los libros afectados.
+func (r *AuthorRepo) Merge(ctx context.Context, keepID, dupID uint64) ([]uint64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.authors[keepID]; !ok {
+		return nil, domain.ErrNotFound
+	}
+	if _, ok := r.s.authors[dupID]; !ok {
+		return nil, domain.ErrNotFound
+	}
+
+	var affected []uint64
+	for bookID, list := range r.s.credits {
+		has := map[domain.CreditRole]bool{}
+		touched := false
+		for _, c := range list {
+			if c.AuthorID == keepID {
+				has[c.Role] = true
+			}
+			touched = touched || c.AuthorID == dupID
+		}
+		if !touched {
+			continue
+		}
+		affected = append(affected, bookID)
+
+		kept := make([]domain.BookCredit, 0, len(list))
+		for _, c := range list {
+			if c.AuthorID == dupID {
+				if has[c.Role] {
+					continue
+				}
+				c.AuthorID = keepID
+			}
+			kept = append(kept, c)
+		}
+		r.s.setCredits(bookID, kept)
+	}
+	delete(r.s.authors, dupID)
+
+	sort.Slice(affected, func(i, j int) bool { return affected[i] < affected[j] })
+	return affected, nil
+}
+
+// setCredits guarda la lista renumerando Position (0..n-1) y sin nombres.
+// Requiere el candado de escritura tomado.
+func (s *Store) setCredits(bookID uint64, list []domain.BookCredit) {
+	if len(list) == 0 {
+		delete(s.credits, bookID)
+		return
+	}
+	out := make([]domain.BookCredit, 0, len(list))
+	for i, c := range list {
+		out = append(out, domain.BookCredit{AuthorID: c.AuthorID, Role: c.Role, Position: i})
+	}
+	s.credits[bookID] = out
+}
+
+// cloneAuthor evita compartir el puntero guardado.
+func cloneAuthor(a *domain.Author) *domain.Author {
+	return domain.HydrateAuthor(a.ID(), a.Name(), a.Bio(), a.CreatedAt(), a.UpdatedAt())
+}
@@ -0,0 +1,300 @@
+package memory
+
+import (
+	"cmp"     // Comparación de campos de orden
+	"context" // Firma del contrato
+	"sort"    // Orden por ID descendente
+	"strings" // Filtros
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain" // Entidad + errores
+)
+
+// BookRepo implementa usecase.BookRepo sobre un Store.
+type BookRepo struct{ s *Store }
+
+// NewBookRepo construye el repositorio de libros.
+func NewBookRepo(s *Store) *BookRepo { return &BookRepo{s: s} }
+
+// Create guarda una copia del libro y retorna el ID asignado. La categoría y
+// las etiquetas se resuelven por slug (creándolas si no existen), como en SQL.
+func (r *BookRepo) Create(ctx context.Context, b *domain.Book) (uint64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.bookByISBN[key(b.ISBN())]; ok {
+		return 0, domain.ErrDuplicate
+	}
+
+	r.s.nextBook++
+	id := r.s.nextBook
+	now := r.s.now()
+	stored, err := domain.HydrateBook(id, b.Title(), b.Author(), b.Year(), b.ISBN(),
+		r.s.ensureCategory(b.Category()).Name(), r.s.ensureTags(b.Tags()), b.Description(), b.Active(), now, now)
+	if err != nil {
+		return 0, err
+	}
+
+	r.s.books[id] = stored
+	r.s.bookByISBN[key(b.ISBN())] = id
+	return id, nil
+}
+
+// GetByID retorna una copia del libro o domain.ErrNotFound.
+func (r *BookRepo) GetByID(ctx context.Context, id uint64) (*domain.Book, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	b, ok := r.s.books[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneBook(b), nil
+}
+
+// GetByISBN busca por ISBN (en forma canónica si es válido) sin distinguir mayúsculas.
+func (r *BookRepo) GetByISBN(ctx context.Context, isbn string) (*domain.Book, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	id, ok := r.s.bookByISBN[key(domain.NormalizeISBN(isbn))]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneBook(r.s.books[id]), nil
+}
+
+// List retorna todos los libros ordenados por ID descendente.
+func (r *BookRepo) List(ctx context.Context) ([]*domain.Book, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	out := make([]*domain.Book, 0, len(r.s.books))
+	for _, b := range r.s.books {
+		out = append(out, cloneBook(b))
+	}
+	sortBooks(out)
+	return out, nil
+}
+
+// Search replica el filtro SQL: la consulta Q (ver matchesQuery), autor y
+// categoría como "contiene", sin distinguir mayúsculas, y el promedio mínimo
+// de estrellas.
+// Retorna la página pedida con el total de coincidencias.
+func (r *BookRepo) Search(ctx context.Context, f domain.BookFilter) (domain.Page[*domain.Book], error) {
+	p, err := f.Paging()
+	if err != nil {
+		return domain.Page[*domain.Book]{}, err
+	}
+	expr, err := f.Query()
+	if err != nil {
+		return domain.Page[*domain.Book]{}, err
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	a := strings.ToLower(strings.TrimSpace(f.Author))
+	c := strings.ToLower(strings.TrimSpace(f.Category))
+
+	// Promedios de las reseñas aprobadas (como la subconsulta ratingExpr del SQL)
+	var ratings map[uint64]domain.RatingSummary
+	if f.MinRating > 0 || p.Sort == domain.SortRating {
+		ratings = r.s.ratingSummaries()
+	}
+
+	out := []*domain.Book{}
+	for _, b := range r.s.books {
+		if !matchesQuery(b, expr) {
+			continue
+		}
+		if a != "" && !containsFold(b.Author(), a) {
+			continue
+		}
+		if c != "" && !containsFold(b.Category(), c) {
+			continue
+		}
+		if !matchesTagAndYear(b, f) {
+			continue
+		}
+		if f.MinRating > 0 && ratings[b.ID()].Average() < f.MinRating {
+			continue
+		}
+		out = append(out, cloneBook(b))
+	}
+	byField := bookSortFields[p.Sort]
+	if p.Sort == domain.SortRating {
+		byField = func(a, b *domain.Book) int {
+			return cmp.Compare(ratings[a.ID()].Average(), ratings[b.ID()].Average())
+		}
+	}
+	sortPaged(out, p, byField, (*domain.Book).ID)
+	return paginate(out, p), nil
+}
+
+// bookSortFields replica bookSortColumns del repositorio SQL.
+var bookSortFields = map[string]func(a, b *domain.Book) int{
+	domain.SortCreatedAt: nil, // orden de inserción (id)
+	"title": func(a, b *domain.Book) int {
+		return cmp.Compare(strings.ToLower(a.Title()), strings.ToLower(b.Title()))
+	},
+	"author": func(a, b *domain.Book) int {
+		return cmp.Compare(strings.ToLower(a.Author()), strings.ToLower(b.Author()))
+	},
+	"year": func(a, b *domain.Book) int { return cmp.Compare(a.Year(), b.Year()) },
+	// rating depende de las reseñas: se resuelve en Search
+}
+
+// Update reemplaza los datos del libro manteniendo la unicidad del ISBN.
+func (r *BookRepo) Update(ctx context.Context, b *domain.Book) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	old, ok := r.s.books[b.ID()]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if id, ok := r.s.bookByISBN[key(b.ISBN())]; ok && id != b.ID() {
+		return domain.ErrDuplicate
+	}
+
+	stored, err := domain.HydrateBook(b.ID(), b.Title(), b.Author(), b.Year(), b.ISBN(),
+		r.s.ensureCategory(b.Category()).Name(), r.s.ensureTags(b.Tags()), b.Description(), b.Active(), old.CreatedAt(), r.s.now())
+	if err != nil {
+		return err
+	}
+
+	delete(r.s.bookByISBN, key(old.ISBN()))
+	r.s.bookByISBN[key(b.ISBN())] = b.ID()
+	r.s.books[b.ID()] = stored
+	return nil
+}
+
+// Delete elimina el libro y, en cascada, sus eventos de acceso, ejemplares,
+// reservas, relaciones y adjuntos.
+func (r *BookRepo) Delete(ctx context.Context, id uint64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	b, ok := r.s.books[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.s.bookByISBN, key(b.ISBN()))
+	delete(r.s.books, id)
+	delete(r.s.credits, id)
+	r.s.deleteCopies(func(c *domain.Copy) bool { return c.BookID() == id })
+
+	for eid, e := range r.s.access {
+		if e.BookID() == id {
+			delete(r.s.access, eid)
+		}
+	}
+	for hid, h := range r.s.holds {
+		if h.BookID() == id {
+			delete(r.s.holds, hid)
+		}
+	}
+	for rid, rel := range r.s.relations {
+		if rel.FromID() == id || rel.ToID() == id {
+			delete(r.s.relations, rid)
+		}
+	}
+	for fid, f := range r.s.files {
+		if f.BookID() == id {
+			delete(r.s.files, fid)
+		}
+	}
+	delete(r.s.covers, id)
+	for pid, p := range r.s.progress {
+		if p.BookID() == id {
+			delete(r.s.progress, pid)
+		}
+	}
+	for aid, a := range r.s.annotations {
+		if a.BookID() == id {
+			delete(r.s.annotations, aid)
+		}
+	}
+	for rid, rv := range r.s.reviews {
+		if rv.BookID() == id {
+			delete(r.s.reviews, rid)
+			delete(r.s.reviewHistory, rid)
+		}
+	}
+	return nil
+}
+
+// matchesTagAndYear aplica el filtro de etiqueta exacta y rango de años.
+func matchesTagAndYear(b *domain.Book, f domain.BookFilter) bool {
+	if f.YearFrom > 0 && b.Year() < f.YearFrom {
+		return false
+	}
+	if f.YearTo > 0 && b.Year() > f.YearTo {
+		return false
+	}
+	return f.Tag == "" || hasTag(b, f.Tag)
+}
+
+// hasTag compara etiquetas por slug, como el join con tags del SQL.
+func hasTag(b *domain.Book, tag string) bool {
+	slug := domain.Slugify(tag)
+	for _, t := range b.Tags() {
+		if domain.Slugify(t) == slug {
+			return true
+		}
+	}
+	return false
+}
+
+// anyTagContains indica si alguna etiqueta contiene q (en minúsculas).
+func anyTagContains(b *domain.Book, q string) bool {
+	for _, t := range b.Tags() {
+		if containsFold(t, q) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesQuery ejecuta el AST de la consulta como lo hace el SQL: el texto
+// libre "contiene" en título/autor/etiquetas/descripción, author/title/category
+// "contiene", tag e isbn exactos.
+func matchesQuery(b *domain.Book, expr domain.QueryExpr) bool {
+	return domain.EvalQuery(expr, func(e domain.QueryExpr) bool {
+		switch t := e.(type) {
+		case domain.TextTerm:
+			q := strings.ToLower(t.Text)
+			return containsFold(b.Title(), q) || containsFold(b.Author(), q) ||
+				anyTagContains(b, q) || containsFold(b.Description(), q)
+		case domain.FieldTerm:
+			v := strings.ToLower(t.Value)
+			switch t.Field {
+			case domain.FieldAuthor:
+				return containsFold(b.Author(), v)
+			case domain.FieldTitle:
+				return containsFold(b.Title(), v)
+			case domain.FieldCategory:
+				return containsFold(b.Category(), v)
+			case domain.FieldTag:
+				return hasTag(b, v)
+			case domain.FieldISBN:
+				return key(b.ISBN()) == key(v)
+			}
+		case domain.YearRange:
+			return (t.From == 0 || b.Year() >= t.From) && (t.To == 0 || b.Year() <= t.To)
+		}
+		return false
+	})
+}
+
+// sortBooks ordena por ID descendente (como ORDER BY id DESC).
+func sortBooks(list []*domain.Book) {
+	sort.Slice(list, func(i, j int) bool { return list[i].ID() > list[j].ID() })
+}
+
+// cloneBook evita que el llamador modifique el estado guardado sin Update.
+func cloneBook(b *domain.Book) *domain.Book {
+	c, _ := domain.HydrateBook(b.ID(), b.Title(), b.Author(), b.Year(), b.ISBN(), b.Category(),
+		b.Tags(), b.Description(), b.Active(), b.CreatedAt(), b.UpdatedAt())
+	return c
+}
@@ -0,0 +1,529 @@
+package memory
+
+import (
+	"cmp"     // Comparación de campos de orden
+	"context" // Firma del contrato
+	"fmt"     // Errores con contexto
+	"sort"    // Orden de ejemplares
+	"time"    // Referencia de vencimiento
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain" // Entidades + errores
+)
+
+// CirculationRepo implementa usecase.CirculationRepo sobre un Store.
+type CirculationRepo struct{ s *Store }
+
+// NewCirculationRepo construye el repositorio de ejemplares y préstamos.
+func NewCirculationRepo(s *Store) *CirculationRepo { return &CirculationRepo{s: s} }
+
+// -------------------- Ejemplares --------------------
+
+// CreateCopy guarda el ejemplar (ErrDuplicate si el código ya existe).
+func (r *CirculationRepo) CreateCopy(ctx context.Context, c *domain.Copy) (uint64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.books[c.BookID()]; !ok {
+		return 0, domain.ErrNotFound // clave foránea book_id
+	}
+	if _, ok := r.s.copyByBarcode[key(c.Barcode())]; ok {
+		return 0, domain.ErrDuplicate
+	}
+
+	r.s.nextCopy++
+	id := r.s.nextCopy
+	now := r.s.now()
+	r.s.copies[id] = domain.HydrateCopy(id, c.BookID(), c.Kind(), c.Barcode(), c.Status(), c.Location(), now, now)
+	r.s.copyByBarcode[key(c.Barcode())] = id
+	r.s.serveHolds(c.BookID(), now)
+	return id, nil
+}
+
+// GetCopy retorna una copia del ejemplar o domain.ErrNotFound.
+func (r *CirculationRepo) GetCopy(ctx context.Context, id uint64) (*domain.Copy, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	c, ok := r.s.copies[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneCopy(c), nil
+}
+
+// GetCopyByBarcode busca por código de barras (sin distinguir mayúsculas).
+func (r *CirculationRepo) GetCopyByBarcode(ctx context.Context, barcode string) (*domain.Copy, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	id, ok := r.s.copyByBarcode[key(barcode)]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneCopy(r.s.copies[id]), nil
+}
+
+// CopiesByBook retorna los ejemplares del libro ordenados por código.
+func (r *CirculationRepo) CopiesByBook(ctx context.Context, bookID uint64) ([]*domain.Copy, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	out := []*domain.Copy{}
+	for _, c := range r.s.copies {
+		if c.BookID() == bookID {
+			out = append(out, cloneCopy(c))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return cmp.Or(cmp.Compare(out[i].Barcode(), out[j].Barcode()), cmp.Compare(out[i].ID(), out[j].ID())) < 0
+	})
+	return out, nil
+}
+
+// UpdateCopy guarda código, ubicación y estado si el estado sigue siendo from
+// (como el WHERE status=? de SQL).
+func (r *CirculationRepo) UpdateCopy(ctx context.Context, c *domain.Copy, from domain.CopyStatus) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	old, ok := r.s.copies[c.ID()]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if old.Status() != from {
+		return domain.ErrUnavailable
+	}
+	if id, ok := r.s.copyByBarcode[key(c.Barcode())]; ok && id != c.ID() {
+		return domain.ErrDuplicate
+	}
+
+	delete(r.s.copyByBarcode, key(old.Barcode()))
+	r.s.copyByBarcode[key(c.Barcode())] = c.ID()
+	r.s.copies[c.ID()] = domain.HydrateCopy(c.ID(), old.BookID(), old.Kind(), c.Barcode(), c.Status(), c.Location(), old.CreatedAt(), r.s.now())
+	if c.Status() == domain.CopyAvailable {
+		r.s.serveHolds(old.BookID(), r.s.now())
+	}
+	return nil
+}
+
+// DeleteCopy elimina el ejemplar y su historial (como ON DELETE CASCADE).
+func (r *CirculationRepo) DeleteCopy(ctx context.Context, id uint64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.copies[id]; !ok {
+		return domain.ErrNotFound
+	}
+	r.s.deleteCopies(func(c *domain.Copy) bool { return c.ID() == id })
+	return nil
+}
+
+// -------------------- Préstamos --------------------
+
+// Checkout replica la transacción SQL: ejemplar disponible (o apartado para
+// este usuario), límite del usuario y alta del préstamo bajo el mismo candado.
+func (r *CirculationRepo) Checkout(ctx context.Context, l *domain.Loan, maxActive int) (uint64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	c, ok := r.s.copies[l.CopyID()]
+	if !ok {
+		return 0, domain.ErrNotFound
+	}
+	var claimed *domain.Hold
+	switch c.Status() {
+	case domain.CopyAvailable:
+	case domain.CopyOnHold:
+		for _, h := range r.s.holds {
+			if h.CopyID() == c.ID() && h.UserID() == l.UserID() && h.Status() == domain.HoldReady {
+				claimed = h
+			}
+		}
+		if claimed == nil {
+			return 0, domain.ErrUnavailable
+		}
+	default:
+		return 0, domain.ErrUnavailable
+	}
+	active := 0
+	for _, other := range r.s.loans {
+		if other.UserID() == l.UserID() && other.Active() {
+			active++
+		}
+	}
+	if active >= maxActive {
+		return 0, domain.ErrLimitReached
+	}
+
+	// La reserva retirada y las que el usuario tenía en espera del libro quedan cumplidas
+	for _, h := range r.s.holds {
+		if h == claimed || (h.BookID() == l.BookID() && h.UserID() == l.UserID() && h.Status() == domain.HoldWaiting) {
+			r.s.setHoldStatus(h, domain.HoldFulfilled, l.LoanedAt())
+		}
+	}
+	r.s.setCopyStatus(c, domain.CopyOnLoan)
+	r.s.nextLoan++
+	id := r.s.nextLoan
+	r.s.loans[id] = domain.HydrateLoan(id, l.CopyID(), l.BookID(), l.UserID(), l.LoanedAt().UTC(), l.DueAt().UTC(), time.Time{}, l.Renewals())
+	return id, nil
+}
+
+// GetLoan retorna una copia del préstamo o domain.ErrNotFound.
+func (r *CirculationRepo) GetLoan(ctx context.Context, id uint64) (*domain.Loan, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	l, ok := r.s.loans[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneLoan(l), nil
+}
+
+// ActiveLoan retorna el préstamo sin devolver del ejemplar.
+func (r *CirculationRepo) ActiveLoan(ctx context.Context, copyID uint64) (*domain.Loan, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, l := range r.s.loans {
+		if l.CopyID() == copyID && l.Active() {
+			return cloneLoan(l), nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+// RenewLoan guarda vencimiento y renovaciones de un préstamo activo.
+func (r *CirculationRepo) RenewLoan(ctx context.Context, l *domain.Loan) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	old, ok := r.s.loans[l.ID()]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if !old.Active() {
+		return domain.ErrValidation
+	}
+	for _, h := range r.s.holds {
+		if h.BookID() == old.BookID() && h.Status() == domain.HoldWaiting {
+			return fmt.Errorf("%w: other users are waiting for this book", domain.ErrLimitReached)
+		}
+	}
+	r.s.loans[l.ID()] = domain.HydrateLoan(old.ID(), old.CopyID(), old.BookID(), old.UserID(), old.LoanedAt(), l.DueAt().UTC(), time.Time{}, l.Renewals())
+	return nil
+}
+
+// ReturnLoan cierra el préstamo y deja el ejemplar disponible (o apartado
+// para la primera reserva de la cola).
+func (r *CirculationRepo) ReturnLoan(ctx context.Context, l *domain.Loan) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	old, ok := r.s.loans[l.ID()]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if !old.Active() {
+		return domain.ErrValidation
+	}
+	r.s.loans[l.ID()] = domain.HydrateLoan(old.ID(), old.CopyID(), old.BookID(), old.UserID(), old.LoanedAt(), old.DueAt(), l.ReturnedAt().UTC(), old.Renewals())
+	if c, ok := r.s.copies[old.CopyID()]; ok && c.Status() == domain.CopyOnLoan {
+		r.s.setCopyStatus(c, domain.CopyAvailable)
+		r.s.serveHolds(old.BookID(), l.ReturnedAt())
+	}
+	return nil
+}
+
+// ListLoans replica los filtros SQL y completa código, título y usuario.
+func (r *CirculationRepo) ListLoans(ctx context.Context, f domain.LoanFilter) (domain.Page[domain.LoanDetail], error) {
+	p, err := f.Paging()
+	if err != nil {
+		return domain.Page[domain.LoanDetail]{}, err
+	}
+	now := f.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	out := []domain.LoanDetail{}
+	for _, l := range r.s.loans {
+		switch {
+		case f.UserID != 0 && l.UserID() != f.UserID,
+			f.BookID != 0 && l.BookID() != f.BookID,
+			f.CopyID != 0 && l.CopyID() != f.CopyID,
+			f.Digital && !r.s.copies[l.CopyID()].Digital(),
+			f.Status == domain.LoanStatusActive && !l.Active(),
+			f.Status == domain.LoanStatusOverdue && !(l.Active() && l.DueAt().Before(now)),
+			f.Status == domain.LoanStatusReturned && l.Active():
+			continue
+		}
+		d := domain.LoanDetail{Loan: cloneLoan(l)}
+		if c, ok := r.s.copies[l.CopyID()]; ok {
+			d.Barcode, d.Digital = c.Barcode(), c.Digital()
+		}
+		if b, ok := r.s.books[l.BookID()]; ok {
+			d.BookTitle = b.Title()
+		}
+		if u, ok := r.s.users[l.UserID()]; ok {
+			d.UserName = u.Name()
+		}
+		out = append(out, d)
+	}
+	sortPaged(out, p, loanSortFields[p.Sort], func(d domain.LoanDetail) uint64 { return d.Loan.ID() })
+	return paginate(out, p), nil
+}
+
+// loanSortFields replica loanSortColumns del repositorio SQL.
+var loanSortFields = map[string]func(a, b domain.LoanDetail) int{
+	domain.SortCreatedAt: nil, // orden de alta (id)
+	"due_at":             func(a, b domain.LoanDetail) int { return a.Loan.DueAt().Compare(b.Loan.DueAt()) },
+}
+
+// -------------------- Reservas --------------------
+
+// PlaceHold replica la transacción SQL: sin duplicado, dentro del límite y
+// atendida enseguida si hay un ejemplar disponible.
+func (r *CirculationRepo) PlaceHold(ctx context.Context, h *domain.Hold, maxActive int) (uint64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.books[h.BookID()]; !ok {
+		return 0, domain.ErrNotFound
+	}
+	if _, ok := r.s.users[h.UserID()]; !ok {
+		return 0, domain.ErrNotFound
+	}
+	active := 0
+	for _, other := range r.s.holds {
+		if other.UserID() != h.UserID() || !other.Active() {
+			continue
+		}
+		if other.BookID() == h.BookID() {
+			return 0, domain.ErrDuplicate
+		}
+		active++
+	}
+	if active >= maxActive {
+		return 0, domain.ErrLimitReached
+	}
+
+	r.s.nextHold++
+	id := r.s.nextHold
+	r.s.holds[id] = domain.HydrateHold(id, h.BookID(), h.UserID(), 0, domain.HoldWaiting, h.CreatedAt().UTC(), time.Time{}, time.Time{}, time.Time{})
+	r.s.serveHolds(h.BookID(), h.CreatedAt())
+	return id, nil
+}
+
+// GetHold retorna una copia de la reserva o domain.ErrNotFound.
+func (r *CirculationRepo) GetHold(ctx context.Context, id uint64) (*domain.Hold, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	h, ok := r.s.holds[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneHold(h), nil
+}
+
+// ReadyHold retorna la reserva lista que aparta el ejemplar.
+func (r *CirculationRepo) ReadyHold(ctx context.Context, copyID uint64) (*domain.Hold, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, h := range r.s.holds {
+		if h.CopyID() == copyID && h.Status() == domain.HoldReady {
+			return cloneHold(h), nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+// CancelHold cierra la reserva activa y libera su ejemplar apartado.
+func (r *CirculationRepo) CancelHold(ctx context.Context, h *domain.Hold) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	old, ok := r.s.holds[h.ID()]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if !old.Active() {
+		return domain.ErrValidation
+	}
+	r.s.setHoldStatus(old, h.Status(), h.ClosedAt())
+	if old.Status() == domain.HoldReady {
+		r.s.releaseCopy(old.CopyID(), old.BookID(), h.ClosedAt())
+	}
+	return nil
+}
+
+// ExpireHolds vence las reservas listas cuyo plazo de retiro pasó.
+func (r *CirculationRepo) ExpireHolds(ctx context.Context, now time.Time) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	expired := []*domain.Hold{}
+	for _, h := range r.s.holds {
+		if h.Status() == domain.HoldReady && h.ExpiresAt().Before(now) {
+			expired = append(expired, h)
+		}
+	}
+	// Mismo orden que el repositorio SQL (por id)
+	sort.Slice(expired, func(i, j int) bool { return expired[i].ID() < expired[j].ID() })
+	for _, h := range expired {
+		r.s.setHoldStatus(h, domain.HoldExpired, now)
+		r.s.releaseCopy(h.CopyID(), h.BookID(), now)
+	}
+	return len(expired), nil
+}
+
+// ListHolds replica los filtros SQL y calcula el lugar en la cola.
+func (r *CirculationRepo) ListHolds(ctx context.Context, f domain.HoldFilter) (domain.Page[domain.HoldDetail], error) {
+	p, err := f.Paging()
+	if err != nil {
+		return domain.Page[domain.HoldDetail]{}, err
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	out := []domain.HoldDetail{}
+	for _, h := range r.s.holds {
+		switch {
+		case f.UserID != 0 && h.UserID() != f.UserID,
+			f.BookID != 0 && h.BookID() != f.BookID,
+			f.Status == domain.HoldStatusActive && !h.Active(),
+			f.Status != "" && f.Status != domain.HoldStatusActive && string(h.Status()) != f.Status:
+			continue
+		}
+		d := domain.HoldDetail{Hold: cloneHold(h)}
+		if h.Status() == domain.HoldWaiting {
+			for _, w := range r.s.holds {
+				if w.BookID() == h.BookID() && w.Status() == domain.HoldWaiting && w.ID() <= h.ID() {
+					d.Position++
+				}
+			}
+		}
+		if c, ok := r.s.copies[h.CopyID()]; ok {
+			d.Barcode, d.Digital = c.Barcode(), c.Digital()
+		}
+		if b, ok := r.s.books[h.BookID()]; ok {
+			d.BookTitle = b.Title()
+		}
+		if u, ok := r.s.users[h.UserID()]; ok {
+			d.UserName = u.Name()
+		}
+		out = append(out, d)
+	}
+	sortPaged(out, p, nil, func(d domain.HoldDetail) uint64 { return d.Hold.ID() })
+	return paginate(out, p), nil
+}
+
+// Availability cuenta los ejemplares del libro por estado y su cola.
+func (r *CirculationRepo) Availability(ctx context.Context, bookID uint64) (domain.Availability, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var a domain.Availability
+	if _, ok := r.s.books[bookID]; !ok {
+		return a, domain.ErrNotFound
+	}
+	for _, c := range r.s.copies {
+		if c.BookID() == bookID {
+			a.AddCopies(c.Kind(), c.Status(), 1)
+		}
+	}
+	for _, h := range r.s.holds {
+		if h.BookID() == bookID && h.Status() == domain.HoldWaiting {
+			a.Waiting++
+		}
+	}
+	return a, nil
+}
+
+// -------------------- Helpers del Store (requieren el candado de escritura) --------------------
+
+// setCopyStatus reemplaza el ejemplar con el nuevo estado.
+func (s *Store) setCopyStatus(c *domain.Copy, st domain.CopyStatus) {
+	s.copies[c.ID()] = domain.HydrateCopy(c.ID(), c.BookID(), c.Kind(), c.Barcode(), st, c.Location(), c.CreatedAt(), s.now())
+}
+
+// serveHolds aparta los ejemplares disponibles del libro para las reservas
+// en espera más antiguas (FIFO por id), como txConn.serveHolds.
+func (s *Store) serveHolds(bookID uint64, now time.Time) {
+	for {
+		var c *domain.Copy
+		for _, cand := range s.copies {
+			if cand.BookID() == bookID && cand.Available() && (c == nil || cand.ID() < c.ID()) {
+				c = cand
+			}
+		}
+		var h *domain.Hold
+		for _, cand := range s.holds {
+			if cand.BookID() == bookID && cand.Status() == domain.HoldWaiting && (h == nil || cand.ID() < h.ID()) {
+				h = cand
+			}
+		}
+		if c == nil || h == nil {
+			return
+		}
+
+		ready := cloneHold(h)
+		_ = ready.MarkReady(c.ID(), now) // h está en espera: no falla
+		s.holds[h.ID()] = ready
+		s.setCopyStatus(c, domain.CopyOnHold)
+	}
+}
+
+// releaseCopy devuelve a la estantería un ejemplar apartado y atiende la cola.
+func (s *Store) releaseCopy(copyID, bookID uint64, now time.Time) {
+	if c, ok := s.copies[copyID]; ok && c.Status() == domain.CopyOnHold {
+		s.setCopyStatus(c, domain.CopyAvailable)
+	}
+	s.serveHolds(bookID, now)
+}
+
+// setHoldStatus cierra la reserva con el estado st.
+func (s *Store) setHoldStatus(h *domain.Hold, st domain.HoldStatus, at time.Time) {
+	s.holds[h.ID()] = domain.HydrateHold(h.ID(), h.BookID(), h.UserID(), h.CopyID(), st, h.CreatedAt(), h.ReadyAt(), h.ExpiresAt(), at.UTC())
+}
+
+// deleteCopies borra los ejemplares que cumplen match y sus préstamos
+// (cascada de books -> copies -> loans); las reservas que los apartaban
+// quedan sin ejemplar (ON DELETE SET NULL).
+func (s *Store) deleteCopies(match func(c *domain.Copy) bool) {
+	for id, c := range s.copies {
+		if !match(c) {
+			continue
+		}
+		for lid, l := range s.loans {
+			if l.CopyID() == id {
+				delete(s.loans, lid)
+			}
+		}
+		for _, h := range s.holds {
+			if h.CopyID() == id {
+				s.holds[h.ID()] = domain.HydrateHold(h.ID(), h.BookID(), h.UserID(), 0, h.Status(), h.CreatedAt(), h.ReadyAt(), h.ExpiresAt(), h.ClosedAt())
+			}
+		}
+		delete(s.copyByBarcode, key(c.Barcode()))
+		delete(s.copies, id)
+	}
+}
+
+func cloneCopy(c *domain.Copy) *domain.Copy {
+	return domain.HydrateCopy(c.ID(), c.BookID(), c.Kind(), c.Barcode(), c.Status(), c.Location(), c.CreatedAt(), c.UpdatedAt())
+}
+
+func cloneLoan(l *domain.Loan) *domain.Loan {
+	return domain.HydrateLoan(l.ID(), l.CopyID(), l.BookID(), l.UserID(), l.LoanedAt(), l.DueAt(), l.ReturnedAt(), l.Renewals())
+}
+
+func cloneHold(h *domain.Hold) *domain.Hold {
+	return domain.HydrateHold(h.ID(), h.BookID(), h.UserID(), h.CopyID(), h.Status(), h.CreatedAt(), h.ReadyAt(), h.ExpiresAt(), h.ClosedAt())
+}
@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"context" // Firma del contrato
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain" // Entidades + errores
+)
+
+// CoverRepo implementa usecase.CoverRepo sobre un Store.
+type CoverRepo struct{ s *Store }
+
+// NewCoverRepo construye el repositorio de portadas.
+func NewCoverRepo(s *Store) *CoverRepo { return &CoverRepo{s: s} }
+
+// Get retorna una copia de la portada o domain.ErrNotFound.
+func (r *CoverRepo) Get(ctx context.Context, bookID uint64) (*domain.Cover, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	c, ok := r.s.covers[bookID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneCover(c), nil
+}
+
+// ByBooks retorna copias de las portadas de los libros dados.
+func (r *CoverRepo) ByBooks(ctx context.Context, bookIDs []uint64) (map[uint64]*domain.Cover, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	out := map[uint64]*domain.Cover{}
+	for _, id := range bookIDs {
+		if c, ok := r.s.covers[id]; ok {
+			out[id] = cloneCover(c)
+		}
+	}
+	return out, nil
+}
+
+// Put crea o reemplaza la portada (ErrNotFound si falta el libro).
+func (r *CoverRepo) Put(ctx context.Context, c *domain.Cover) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.books[c.BookID()]; !ok {
+		return domain.ErrNotFound // clave foránea
+	}
+	r.s.covers[c.BookID()] = domain.HydrateCover(c.BookID(), c.SHA256(), c.ContentType(), c.Width(), c.Height(), c.Size(), r.s.now())
+	return nil
+}
+
+// CountBySHA cuenta las portadas con esa imagen.
+func (r *CoverRepo) CountBySHA(ctx context.Context, sha256 string) (int, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	n := 0
+	for _, c := range r.s.covers {
+		if c.SHA256() == sha256 {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Delete quita la portada del libro.
+func (r *CoverRepo) Delete(ctx context.Context, bookID uint64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.covers[bookID]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.s.covers, bookID)
+	return nil
+}
+
+func cloneCover(c *domain.Cover) *domain.Cover {
+	return domain.HydrateCover(c.BookID(), c.SHA256(), c.ContentType(), c.Width(), c.Height(), c.Size(), c.UpdatedAt())
+}
@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"cmp"     // Orden por tipo y nombre
+	"context" // Firma del contrato
+	"sort"    // Orden de adjuntos
+	"strings" // Nombre sin distinguir mayúsculas
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain" // Entidades + errores
+)
+
+// FileRepo implementa usecase.FileRepo sobre un Store.
+type FileRepo struct{ s *Store }
+
+// NewFileRepo construye el repositorio de adjuntos.
+func NewFileRepo(s *Store) *FileRepo { return &FileRepo{s: s} }
+
+// Create guarda el adjunto (ErrNotFound si falta el libro, ErrDuplicate si
+// el libro ya tiene ese contenido).
+func (r *FileRepo) Create(ctx context.Context, f *domain.BookFile) (uint64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.books[f.BookID()]; !ok {
+		return 0, domain.ErrNotFound // clave foránea
+	}
+	for _, e := range r.s.files {
+		if e.BookID() == f.BookID() && e.SHA256() == f.SHA256() {
+			return 0, domain.ErrDuplicate
+		}
+	}
+	uploadedBy := f.UploadedBy()
+	if _, ok := r.s.users[uploadedBy]; !ok {
+		uploadedBy = 0
+	}
+
+	r.s.nextFile++
+	id := r.s.nextFile
+	r.s.files[id] = domain.HydrateBookFile(id, f.BookID(), f.Name(), f.ContentType(), f.Kind(), f.Size(), f.SHA256(), uploadedBy, r.s.now())
+	return id, nil
+}
+
+// GetByID retorna una copia del adjunto o domain.ErrNotFound.
+func (r *FileRepo) GetByID(ctx context.Context, id uint64) (*domain.BookFile, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	f, ok := r.s.files[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneFile(f), nil
+}
+
+// ByBook retorna los adjuntos del libro por tipo, nombre e id.
+func (r *FileRepo) ByBook(ctx context.Context, bookID uint64) ([]*domain.BookFile, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	out := []*domain.BookFile{}
+	for _, f := range r.s.files {
+		if f.BookID() == bookID {
+			out = append(out, cloneFile(f))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		return cmp.Or(
+			cmp.Compare(a.Kind(), b.Kind()),
+			cmp.Compare(strings.ToLower(a.Name()), strings.ToLower(b.Name())),
+			cmp.Compare(a.ID(), b.ID()),
+		) < 0
+	})
+	return out, nil
+}
+
+// CountBySHA cuenta los adjuntos con ese contenido.
+func (r *FileRepo) CountBySHA(ctx context.Context, sha256 string) (int, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	n := 0
+	for _, f := range r.s.files {
+		if f.SHA256() == sha256 {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Delete elimina el adjunto.
+func (r *FileRepo) Delete(ctx context.Context, id uint64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.files[id]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.s.files, id)
+	return nil
+}
+
+func cloneFile(f *domain.BookFile) *domain.BookFile {
+	return domain.HydrateBookFile(f.ID(), f.BookID(), f.Name(), f.ContentType(), f.Kind(), f.Size(), f.SHA256(), f.UploadedBy(), f.CreatedAt())
+}
@@ -0,0 +1,30 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/infrastructure/memory"
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/infrastructure/repotest"
+)
+
+func TestConformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repos {
+		s := memory.NewStore()
+		return repotest.Repos{
+			Users:       memory.NewUserRepo(s),
+			Books:       memory.NewBookRepo(s),
+			Access:      memory.NewAccessRepo(s),
+			Sessions:    memory.NewSessionRepo(s),
+			Tokens:      memory.NewAPITokenRepo(s),
+			Authors:     memory.NewAuthorRepo(s),
+			Taxonomy:    memory.NewTaxonomyRepo(s),
+			Circulation: memory.NewCirculationRepo(s),
+			Relations:   memory.NewRelationRepo(s),
+			Files:       memory.NewFileRepo(s),
+			Covers:      memory.NewCoverRepo(s),
+			Progress:    memory.NewProgressRepo(s),
+			Annotations: memory.NewAnnotationRepo(s),
+			Reviews:     memory.NewReviewRepo(s),
+		}
+	})
+}
@@ -0,0 +1,165 @@
+package memory
+
+import (
+	"cmp"     // Comparación de campos de orden
+	"context" // Firma del contrato
+	"strings" // Orden por título sin distinguir mayúsculas
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain" // Entidades + errores
+)
+
+// ProgressRepo implementa usecase.ProgressRepo sobre un Store.
+type ProgressRepo struct{ s *Store }
+
+// NewProgressRepo construye el repositorio de progreso de lectura.
+func NewProgressRepo(s *Store) *ProgressRepo { return &ProgressRepo{s: s} }
+
+// Save actualiza el progreso del usuario en el libro (conservando el
+// inicio) o lo crea. ErrNotFound si falta el usuario o el libro.
+func (r *ProgressRepo) Save(ctx context.Context, p *domain.ReadingProgress) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.users[p.UserID()]; !ok {
+		return domain.ErrNotFound // clave foránea
+	}
+	if _, ok := r.s.books[p.BookID()]; !ok {
+		return domain.ErrNotFound
+	}
+	if prev := r.find(p.UserID(), p.BookID()); prev != nil {
+		r.s.progress[prev.ID()] = domain.HydrateReadingProgress(prev.ID(), p.UserID(), p.BookID(), p.Position(),
+			prev.StartedAt(), p.LastReadAt().UTC(), p.FinishedAt().UTC())
+		return nil
+	}
+	r.s.nextProgress++
+	id := r.s.nextProgress
+	r.s.progress[id] = domain.HydrateReadingProgress(id, p.UserID(), p.BookID(), p.Position(),
+		p.StartedAt().UTC(), p.LastReadAt().UTC(), p.FinishedAt().UTC())
+	return nil
+}
+
+// Get retorna una copia del progreso o domain.ErrNotFound.
+func (r *ProgressRepo) Get(ctx context.Context, userID, bookID uint64) (*domain.ReadingProgress, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	p := r.find(userID, bookID)
+	if p == nil {
+		return nil, domain.ErrNotFound
+	}
+	return cloneProgress(p), nil
+}
+
+// Delete borra el progreso del usuario en el libro.
+func (r *ProgressRepo) Delete(ctx context.Context, userID, bookID uint64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	p := r.find(userID, bookID)
+	if p == nil {
+		return domain.ErrNotFound
+	}
+	delete(r.s.progress, p.ID())
+	return nil
+}
+
+// List filtra, ordena y pagina como el repositorio SQL.
+func (r *ProgressRepo) List(ctx context.Context, f domain.ProgressFilter) (domain.Page[domain.ProgressDetail], error) {
+	p, err := f.Paging()
+	if err != nil {
+		return domain.Page[domain.ProgressDetail]{}, err
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	out := []domain.ProgressDetail{}
+	for _, pr := range r.s.progress {
+		switch {
+		case f.UserID != 0 && pr.UserID() != f.UserID,
+			f.BookID != 0 && pr.BookID() != f.BookID,
+			f.Status == domain.ProgressStatusReading && pr.Finished(),
+			f.Status == domain.ProgressStatusFinished && !pr.Finished():
+			continue
+		}
+		d := domain.ProgressDetail{Progress: cloneProgress(pr)}
+		if b, ok := r.s.books[pr.BookID()]; ok {
+			d.BookTitle, d.BookAuthor = b.Title(), b.Author()
+		}
+		if u, ok := r.s.users[pr.UserID()]; ok {
+			d.UserName = u.Name()
+		}
+		out = append(out, d)
+	}
+	sortPaged(out, p, progressSortFields[p.Sort], func(d domain.ProgressDetail) uint64 { return d.Progress.ID() })
+	return paginate(out, p), nil
+}
+
+// progressSortFields replica progressSortColumns del repositorio SQL.
+var progressSortFields = map[string]func(a, b domain.ProgressDetail) int{
+	domain.SortCreatedAt: nil, // orden de alta (id)
+	"last_read_at":       func(a, b domain.ProgressDetail) int { return a.Progress.LastReadAt().Compare(b.Progress.LastReadAt()) },
+	"percent":            func(a, b domain.ProgressDetail) int { return cmp.Compare(a.Progress.Percent(), b.Progress.Percent()) },
+}
+
+// Completion agrupa los progresos por libro.
+func (r *ProgressRepo) Completion(ctx context.Context, f domain.CompletionFilter) (domain.Page[domain.BookCompletion], error) {
+	p, err := f.Paging()
+	if err != nil {
+		return domain.Page[domain.BookCompletion]{}, err
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	byBook := map[uint64]*domain.BookCompletion{}
+	sums := map[uint64]float64{}
+	for _, pr := range r.s.progress {
+		if f.BookID != 0 && pr.BookID() != f.BookID {
+			continue
+		}
+		c, ok := byBook[pr.BookID()]
+		if !ok {
+			c = &domain.BookCompletion{BookID: pr.BookID()}
+			if b, ok := r.s.books[pr.BookID()]; ok {
+				c.Title, c.Author = b.Title(), b.Author()
+			}
+			byBook[pr.BookID()] = c
+		}
+		c.Readers++
+		if pr.Finished() {
+			c.Finished++
+		}
+		sums[pr.BookID()] += pr.Percent()
+	}
+
+	out := make([]domain.BookCompletion, 0, len(byBook))
+	for id, c := range byBook {
+		c.AvgPercent = sums[id] / float64(c.Readers)
+		out = append(out, *c)
+	}
+	sortPaged(out, p, completionSortFields[p.Sort], func(c domain.BookCompletion) uint64 { return c.BookID })
+	return paginate(out, p), nil
+}
+
+// completionSortFields replica completionSortColumns del repositorio SQL.
+var completionSortFields = map[string]func(a, b domain.BookCompletion) int{
+	"readers":     func(a, b domain.BookCompletion) int { return cmp.Compare(a.Readers, b.Readers) },
+	"rate":        func(a, b domain.BookCompletion) int { return cmp.Compare(a.Finished*b.Readers, b.Finished*a.Readers) },
+	"avg_percent": func(a, b domain.BookCompletion) int { return cmp.Compare(a.AvgPercent, b.AvgPercent) },
+	"title":       func(a, b domain.BookCompletion) int { return strings.Compare(key(a.Title), key(b.Title)) },
+}
+
+// find busca el progreso de un par usuario-libro (requiere el candado).
+func (r *ProgressRepo) find(userID, bookID uint64) *domain.ReadingProgress {
+	for _, p := range r.s.progress {
+		if p.UserID() == userID && p.BookID() == bookID {
+			return p
+		}
+	}
+	return nil
+}
+
+func cloneProgress(p *domain.ReadingProgress) *domain.ReadingProgress {
+	return domain.HydrateReadingProgress(p.ID(), p.UserID(), p.BookID(), p.Position(), p.StartedAt(), p.LastReadAt(), p.FinishedAt())
+}
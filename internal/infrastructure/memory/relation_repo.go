@@ -0,0 +1,118 @@
+package memory
+
+import (
+	"cmp"     // Orden por tipo y título
+	"context" // Firma del contrato
+	"sort"    // Orden de relaciones
+	"strings" // Título sin distinguir mayúsculas
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain" // Entidades + errores
+)
+
+// RelationRepo implementa usecase.RelationRepo sobre un Store.
+type RelationRepo struct{ s *Store }
+
+// NewRelationRepo construye el repositorio de relaciones entre libros.
+func NewRelationRepo(s *Store) *RelationRepo { return &RelationRepo{s: s} }
+
+// Create guarda la relación (ErrNotFound si falta un libro, ErrDuplicate si
+// ya existe con el mismo origen, destino y tipo).
+func (r *RelationRepo) Create(ctx context.Context, rel *domain.BookRelation) (uint64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, bookID := range []uint64{rel.FromID(), rel.ToID()} {
+		if _, ok := r.s.books[bookID]; !ok {
+			return 0, domain.ErrNotFound // claves foráneas
+		}
+	}
+	for _, e := range r.s.relations {
+		if e.FromID() == rel.FromID() && e.ToID() == rel.ToID() && e.Type() == rel.Type() {
+			return 0, domain.ErrDuplicate
+		}
+	}
+
+	r.s.nextRelation++
+	id := r.s.nextRelation
+	r.s.relations[id] = domain.HydrateBookRelation(id, rel.FromID(), rel.ToID(), rel.Type(), rel.Bidirectional(), r.s.now())
+	return id, nil
+}
+
+// GetByID retorna una copia de la relación o domain.ErrNotFound.
+func (r *RelationRepo) GetByID(ctx context.Context, id uint64) (*domain.BookRelation, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	rel, ok := r.s.relations[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneRelation(rel), nil
+}
+
+// Delete elimina la relación.
+func (r *RelationRepo) Delete(ctx context.Context, id uint64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.relations[id]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.s.relations, id)
+	return nil
+}
+
+// ByType retorna las relaciones del tipo ordenadas por id.
+func (r *RelationRepo) ByType(ctx context.Context, t domain.RelationType) ([]*domain.BookRelation, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	out := []*domain.BookRelation{}
+	for _, rel := range r.s.relations {
+		if rel.Type() == t {
+			out = append(out, cloneRelation(rel))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID() < out[j].ID() })
+	return out, nil
+}
+
+// ByBook replica la consulta SQL: salientes y bidireccionales entrantes,
+// por tipo, título e id.
+func (r *RelationRepo) ByBook(ctx context.Context, bookID uint64) ([]domain.RelatedBook, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	out := []domain.RelatedBook{}
+	for _, rel := range r.s.relations {
+		other := rel.ToID()
+		switch {
+		case rel.FromID() == bookID:
+		case rel.ToID() == bookID && rel.Bidirectional():
+			other = rel.FromID()
+		default:
+			continue
+		}
+		b, ok := r.s.books[other]
+		if !ok {
+			continue
+		}
+		out = append(out, domain.RelatedBook{
+			Relation: cloneRelation(rel), Inverse: other == rel.FromID(),
+			BookID: b.ID(), Title: b.Title(), Author: b.Author(), Year: b.Year(),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		return cmp.Or(
+			cmp.Compare(a.Relation.Type(), b.Relation.Type()),
+			cmp.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title)),
+			cmp.Compare(a.Relation.ID(), b.Relation.ID()),
+		) < 0
+	})
+	return out, nil
+}
+
+func cloneRelation(rel *domain.BookRelation) *domain.BookRelation {
+	return domain.HydrateBookRelation(rel.ID(), rel.FromID(), rel.ToID(), rel.Type(), rel.Bidirectional(), rel.CreatedAt())
+}
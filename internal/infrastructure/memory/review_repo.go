@@ -0,0 +1,183 @@
+package memory
+
+import (
+	"cmp"     // Orden por estrellas
+	"context" // Firma del contrato
+	"slices"  // Filtro por estado
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain" // Entidades + errores
+)
+
+// ReviewRepo implementa usecase.ReviewRepo sobre un Store.
+type ReviewRepo struct{ s *Store }
+
+// NewReviewRepo construye el repositorio de reseñas.
+func NewReviewRepo(s *Store) *ReviewRepo { return &ReviewRepo{s: s} }
+
+// Create guarda la reseña. ErrNotFound si falta el usuario o el libro,
+// ErrDuplicate si el usuario ya reseñó el libro.
+func (r *ReviewRepo) Create(ctx context.Context, rv *domain.Review) (uint64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.users[rv.UserID()]; !ok {
+		return 0, domain.ErrNotFound // clave foránea
+	}
+	if _, ok := r.s.books[rv.BookID()]; !ok {
+		return 0, domain.ErrNotFound
+	}
+	for _, other := range r.s.reviews {
+		if other.UserID() == rv.UserID() && other.BookID() == rv.BookID() {
+			return 0, domain.ErrDuplicate // clave única (user_id, book_id)
+		}
+	}
+	r.s.nextReview++
+	id := r.s.nextReview
+	r.s.reviews[id] = domain.HydrateReview(id, rv.UserID(), rv.BookID(), rv.Rating(), rv.Text(), rv.Status(),
+		rv.CreatedAt().UTC(), rv.UpdatedAt().UTC(), rv.ModeratedAt().UTC())
+	return id, nil
+}
+
+// GetByID retorna una copia de la reseña o domain.ErrNotFound.
+func (r *ReviewRepo) GetByID(ctx context.Context, id uint64) (*domain.Review, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	rv, ok := r.s.reviews[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneReview(rv), nil
+}
+
+// GetByUserBook busca la reseña del usuario sobre el libro.
+func (r *ReviewRepo) GetByUserBook(ctx context.Context, userID, bookID uint64) (*domain.Review, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, rv := range r.s.reviews {
+		if rv.UserID() == userID && rv.BookID() == bookID {
+			return cloneReview(rv), nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+// Update guarda estrellas, texto y estado; prev (si no es nil) pasa al historial.
+func (r *ReviewRepo) Update(ctx context.Context, rv *domain.Review, prev *domain.ReviewRevision) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	old, ok := r.s.reviews[rv.ID()]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	r.s.reviews[rv.ID()] = domain.HydrateReview(rv.ID(), old.UserID(), old.BookID(), rv.Rating(), rv.Text(), rv.Status(),
+		old.CreatedAt(), rv.UpdatedAt().UTC(), rv.ModeratedAt().UTC())
+	if prev != nil {
+		rev := *prev
+		rev.WrittenAt = rev.WrittenAt.UTC()
+		r.s.reviewHistory[rv.ID()] = append(r.s.reviewHistory[rv.ID()], rev)
+	}
+	return nil
+}
+
+// Delete elimina la reseña y su historial.
+func (r *ReviewRepo) Delete(ctx context.Context, id uint64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.reviews[id]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.s.reviews, id)
+	delete(r.s.reviewHistory, id)
+	return nil
+}
+
+// List filtra, ordena y pagina como el repositorio SQL.
+func (r *ReviewRepo) List(ctx context.Context, f domain.ReviewFilter) (domain.Page[domain.ReviewDetail], error) {
+	p, err := f.Paging()
+	if err != nil {
+		return domain.Page[domain.ReviewDetail]{}, err
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	out := []domain.ReviewDetail{}
+	for _, rv := range r.s.reviews {
+		switch {
+		case f.UserID != 0 && rv.UserID() != f.UserID,
+			f.BookID != 0 && rv.BookID() != f.BookID,
+			len(f.Statuses) > 0 && !slices.Contains(f.Statuses, rv.Status()),
+			f.Rating != 0 && rv.Rating() != f.Rating:
+			continue
+		}
+		d := domain.ReviewDetail{Review: cloneReview(rv)}
+		if b, ok := r.s.books[rv.BookID()]; ok {
+			d.BookTitle = b.Title()
+		}
+		if u, ok := r.s.users[rv.UserID()]; ok {
+			d.UserName = u.Name()
+		}
+		out = append(out, d)
+	}
+	sortPaged(out, p, reviewSortFields[p.Sort], func(d domain.ReviewDetail) uint64 { return d.Review.ID() })
+	return paginate(out, p), nil
+}
+
+// reviewSortFields replica reviewSortColumns del repositorio SQL.
+var reviewSortFields = map[string]func(a, b domain.ReviewDetail) int{
+	domain.SortCreatedAt: nil, // orden de alta (id)
+	"updated_at": func(a, b domain.ReviewDetail) int {
+		return a.Review.UpdatedAt().Compare(b.Review.UpdatedAt())
+	},
+	domain.SortRating: func(a, b domain.ReviewDetail) int {
+		return cmp.Compare(a.Review.Rating(), b.Review.Rating())
+	},
+}
+
+// History retorna las versiones anteriores de la reseña en orden de edición.
+func (r *ReviewRepo) History(ctx context.Context, reviewID uint64) ([]domain.ReviewRevision, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	return append([]domain.ReviewRevision{}, r.s.reviewHistory[reviewID]...), nil
+}
+
+// Summaries cuenta las reseñas aprobadas de los libros pedidos.
+func (r *ReviewRepo) Summaries(ctx context.Context, bookIDs []uint64) (map[uint64]domain.RatingSummary, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	all := r.s.ratingSummaries()
+	out := map[uint64]domain.RatingSummary{}
+	for _, id := range bookIDs {
+		if s, ok := all[id]; ok {
+			out[id] = s
+		}
+	}
+	return out, nil
+}
+
+// ratingSummaries resume las reseñas aprobadas de todos los libros.
+// Requiere s.mu (lectura).
+func (s *Store) ratingSummaries() map[uint64]domain.RatingSummary {
+	out := map[uint64]domain.RatingSummary{}
+	for _, rv := range s.reviews {
+		if !rv.Approved() {
+			continue
+		}
+		sum := out[rv.BookID()]
+		sum.BookID = rv.BookID()
+		sum.Add(rv.Rating(), 1)
+		out[rv.BookID()] = sum
+	}
+	return out
+}
+
+func cloneReview(rv *domain.Review) *domain.Review {
+	return domain.HydrateReview(rv.ID(), rv.UserID(), rv.BookID(), rv.Rating(), rv.Text(), rv.Status(),
+		rv.CreatedAt(), rv.UpdatedAt(), rv.ModeratedAt())
+}
@@ -0,0 +1,63 @@
+package memory
+
+import (
+	"context" // Firma del contrato
+	"time"    // Expiración
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain" // Entidad + errores
+)
+
+// SessionRepo implementa usecase.SessionRepo sobre un Store.
+type SessionRepo struct{ s *Store }
+
+// NewSessionRepo construye el repositorio de sesiones.
+func NewSessionRepo(s *Store) *SessionRepo { return &SessionRepo{s: s} }
+
+// Create guarda la sesión (clave: hash del token).
+func (r *SessionRepo) Create(ctx context.Context, s *domain.Session) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.sessions[s.TokenHash()]; ok {
+		return domain.ErrDuplicate
+	}
+	r.s.sessions[s.TokenHash()] = domain.HydrateSession(s.TokenHash(), s.UserID(), s.ExpiresAt(), r.s.now())
+	return nil
+}
+
+// GetByTokenHash busca la sesión o retorna domain.ErrNotFound.
+func (r *SessionRepo) GetByTokenHash(ctx context.Context, tokenHash string) (*domain.Session, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	s, ok := r.s.sessions[tokenHash]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return domain.HydrateSession(s.TokenHash(), s.UserID(), s.ExpiresAt(), s.CreatedAt()), nil
+}
+
+// Delete elimina una sesión (logout).
+func (r *SessionRepo) Delete(ctx context.Context, tokenHash string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.sessions[tokenHash]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.s.sessions, tokenHash)
+	return nil
+}
+
+// DeleteExpired purga sesiones vencidas.
+func (r *SessionRepo) DeleteExpired(ctx context.Context, now time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for h, s := range r.s.sessions {
+		if s.Expired(now) {
+			delete(r.s.sessions, h)
+		}
+	}
+	return nil
+}
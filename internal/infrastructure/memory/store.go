@@ -0,0 +1,144 @@
+// Package memory implementa los repositorios en memoria del proceso.
+//
+// Sirve para demos, pruebas y despliegues efímeros (DB_DRIVER=memory):
+// respeta el mismo contrato que los repositorios SQL (ver repotest), pero
+// los datos se pierden al reiniciar.
+package memory
+
+import (
+	"cmp"     // Comparación de campos de orden
+	"sort"    // Orden estable de resultados
+	"strings" // Normalización de claves únicas
+	"sync"    // Acceso concurrente seguro
+	"time"    // Timestamps
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain" // Entidades
+)
+
+// Store es el "motor" en memoria: un único candado protege todas las tablas
+// para poder replicar borrados en cascada (como ON DELETE CASCADE).
+type Store struct {
+	mu sync.RWMutex
+
+	// Contadores autoincrementales por tabla
+	nextUser, nextBook, nextAccess, nextToken, nextAuthor, nextTag, nextCategory, nextCopy, nextLoan, nextHold, nextRelation, nextFile, nextProgress, nextAnnotation, nextReview uint64
+
+	users    map[uint64]*domain.User
+	books    map[uint64]*domain.Book
+	access   map[uint64]*domain.AccessEvent
+	sessions map[string]*domain.Session
+	tokens   map[uint64]*domain.APIToken
+	authors  map[uint64]*domain.Author
+
+	// Taxonomía: los libros guardan el nombre canónico de su categoría y
+	// etiquetas; la relación se resuelve por slug (único en cada tabla)
+	tags       map[uint64]*domain.Tag
+	categories map[uint64]*domain.Category
+
+	// Circulación: ejemplares físicos, préstamos y reservas
+	copies map[uint64]*domain.Copy
+	loans  map[uint64]*domain.Loan
+	holds  map[uint64]*domain.Hold
+
+	// Relaciones tipadas entre libros (tabla book_relations)
+	relations map[uint64]*domain.BookRelation
+
+	// Metadatos de adjuntos (tabla book_files; el contenido está en el BlobStore)
+	files map[uint64]*domain.BookFile
+
+	// Portadas por libro (tabla book_covers; la imagen está en el BlobStore)
+	covers map[uint64]*domain.Cover
+
+	// Progreso de lectura (tabla reading_progress; único por usuario y libro)
+	progress map[uint64]*domain.ReadingProgress
+
+	// Subrayados, marcadores y notas (tabla annotations)
+	annotations map[uint64]*domain.Annotation
+
+	// Reseñas (tabla reviews; única por usuario y libro) y sus versiones
+	// anteriores (tabla review_revisions, por reseña en orden de edición)
+	reviews       map[uint64]*domain.Review
+	reviewHistory map[uint64][]domain.ReviewRevision
+
+	// credits: libro -> créditos en orden (tabla book_authors)
+	credits map[uint64][]domain.BookCredit
+
+	// Índices únicos (clave normalizada -> ID)
+	userByEmail    map[string]uint64
+	bookByISBN     map[string]uint64
+	tagBySlug      map[string]uint64
+	categoryBySlug map[string]uint64
+	copyByBarcode  map[string]uint64
+
+	// now permite fijar el reloj en pruebas
+	now func() time.Time
+}
+
+// NewStore crea un almacén vacío.
+func NewStore() *Store {
+	return &Store{
+		users:          map[uint64]*domain.User{},
+		books:          map[uint64]*domain.Book{},
+		access:         map[uint64]*domain.AccessEvent{},
+		sessions:       map[string]*domain.Session{},
+		tokens:         map[uint64]*domain.APIToken{},
+		authors:        map[uint64]*domain.Author{},
+		credits:        map[uint64][]domain.BookCredit{},
+		tags:           map[uint64]*domain.Tag{},
+		categories:     map[uint64]*domain.Category{},
+		copies:         map[uint64]*domain.Copy{},
+		loans:          map[uint64]*domain.Loan{},
+		holds:          map[uint64]*domain.Hold{},
+		relations:      map[uint64]*domain.BookRelation{},
+		files:          map[uint64]*domain.BookFile{},
+		covers:         map[uint64]*domain.Cover{},
+		progress:       map[uint64]*domain.ReadingProgress{},
+		annotations:    map[uint64]*domain.Annotation{},
+		reviews:        map[uint64]*domain.Review{},
+		reviewHistory:  map[uint64][]domain.ReviewRevision{},
+		userByEmail:    map[string]uint64{},
+		bookByISBN:     map[string]uint64{},
+		tagBySlug:      map[string]uint64{},
+		categoryBySlug: map[string]uint64{},
+		copyByBarcode:  map[string]uint64{},
+		// Precisión de segundos, como las columnas TIMESTAMP
+		now: func() time.Time { return time.Now().UTC().Truncate(time.Second) },
+	}
+}
+
+// key normaliza claves únicas de texto (equivale a la collation *_ci).
+func key(s string) string { return strings.ToLower(strings.TrimSpace(s)) }
+
+// containsFold indica si s contiene sub sin distinguir mayúsculas.
+// sub debe venir en minúsculas.
+func containsFold(s, sub string) bool {
+	return strings.Contains(strings.ToLower(s), sub)
+}
+
+// sortPaged ordena list según la página normalizada p, como el ORDER BY SQL:
+// byField compara por el campo pedido (nil => solo id, caso created_at)
+// y el id desempata en la misma dirección.
+func sortPaged[T any](list []T, p domain.PageRequest, byField func(a, b T) int, id func(T) uint64) {
+	sort.SliceStable(list, func(i, j int) bool {
+		c := 0
+		if byField != nil {
+			c = byField(list[i], list[j])
+		}
+		if c == 0 {
+			c = cmp.Compare(id(list[i]), id(list[j]))
+		}
+		if p.Dir == domain.SortDesc {
+			return c > 0
+		}
+		return c < 0
+	})
+}
+
+// paginate recorta la página pedida (LIMIT/OFFSET) y adjunta el total.
+func paginate[T any](list []T, p domain.PageRequest) domain.Page[T] {
+	page := domain.Page[T]{Items: []T{}, Total: len(list), Page: p.Page, PageSize: p.PageSize}
+	if from := p.Offset(); from >= 0 && from < len(list) {
+		page.Items = list[from:min(from+p.PageSize, len(list))]
+	}
+	return page
+}
@@ -0,0 +1,351 @@
+package memory
+
+import (
+	"cmp"     // Comparación de campos de orden
+	"context" // Firma del contrato
+	"sort"    // Orden de IDs afectados
+	"strings" // Filtro por nombre
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain" // Entidades + errores
+)
+
+// TaxonomyRepo implementa usecase.TaxonomyRepo sobre un Store.
+type TaxonomyRepo struct{ s *Store }
+
+// NewTaxonomyRepo construye el repositorio de etiquetas y categorías.
+func NewTaxonomyRepo(s *Store) *TaxonomyRepo { return &TaxonomyRepo{s: s} }
+
+// -------------------- Etiquetas --------------------
+
+// ListTags replica el filtro SQL (nombre o slug "contiene") con la cantidad
+// de libros de cada etiqueta.
+func (r *TaxonomyRepo) ListTags(ctx context.Context, f domain.TagFilter) (domain.Page[domain.TagUsage], error) {
+	p, err := f.Paging()
+	if err != nil {
+		return domain.Page[domain.TagUsage]{}, err
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	q := key(f.Q)
+	slug := domain.Slugify(f.Q)
+	counts := map[string]int{}
+	for _, b := range r.s.books {
+		for _, t := range b.Tags() {
+			counts[domain.Slugify(t)]++
+		}
+	}
+
+	out := []domain.TagUsage{}
+	for _, t := range r.s.tags {
+		if q != "" && !containsFold(t.Name(), q) && (slug == "" || !strings.Contains(t.Slug(), slug)) {
+			continue
+		}
+		out = append(out, domain.TagUsage{Tag: cloneTag(t), Books: counts[t.Slug()]})
+	}
+	sortPaged(out, p, tagSortFields[p.Sort], func(u domain.TagUsage) uint64 { return u.Tag.ID() })
+	return paginate(out, p), nil
+}
+
+// tagSortFields replica tagSortColumns del repositorio SQL.
+var tagSortFields = map[string]func(a, b domain.TagUsage) int{
+	domain.SortCreatedAt: nil, // orden de inserción (id)
+	"name":               func(a, b domain.TagUsage) int { return cmp.Compare(a.Tag.Name(), b.Tag.Name()) },
+	"books":              func(a, b domain.TagUsage) int { return cmp.Compare(a.Books, b.Books) },
+}
+
+// GetTag retorna una copia de la etiqueta o domain.ErrNotFound.
+func (r *TaxonomyRepo) GetTag(ctx context.Context, id uint64) (*domain.Tag, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	t, ok := r.s.tags[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneTag(t), nil
+}
+
+// RenameTag guarda el nuevo nombre/slug y lo aplica a los libros que la usan.
+func (r *TaxonomyRepo) RenameTag(ctx context.Context, t *domain.Tag) ([]uint64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	old, ok := r.s.tags[t.ID()]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	if id, ok := r.s.tagBySlug[t.Slug()]; ok && id != t.ID() {
+		return nil, domain.ErrDuplicate
+	}
+
+	affected := r.s.retagBooks(old.Slug(), t.Name())
+	delete(r.s.tagBySlug, old.Slug())
+	r.s.tagBySlug[t.Slug()] = t.ID()
+	r.s.tags[t.ID()] = domain.HydrateTag(t.ID(), t.Name(), t.Slug(), old.CreatedAt())
+	return affected, nil
+}
+
+// MergeTags pasa los libros de dupID a keepID y elimina dupID.
+func (r *TaxonomyRepo) MergeTags(ctx context.Context, keepID, dupID uint64) ([]uint64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	keep, ok := r.s.tags[keepID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	dup, ok := r.s.tags[dupID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+
+	affected := r.s.retagBooks(dup.Slug(), keep.Name())
+	delete(r.s.tagBySlug, dup.Slug())
+	delete(r.s.tags, dupID)
+	return affected, nil
+}
+
+// DeleteTag elimina la etiqueta y la quita de todos los libros.
+func (r *TaxonomyRepo) DeleteTag(ctx context.Context, id uint64) ([]uint64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	t, ok := r.s.tags[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+
+	affected := r.s.retagBooks(t.Slug(), "")
+	delete(r.s.tagBySlug, t.Slug())
+	delete(r.s.tags, id)
+	return affected, nil
+}
+
+// -------------------- Categorías --------------------
+
+// Categories retorna todas las categorías (por ID) con sus libros directos.
+func (r *TaxonomyRepo) Categories(ctx context.Context) ([]domain.CategoryUsage, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	counts := map[string]int{}
+	for _, b := range r.s.books {
+		counts[domain.Slugify(b.Category())]++
+	}
+	out := make([]domain.CategoryUsage, 0, len(r.s.categories))
+	for _, c := range r.s.categories {
+		out = append(out, domain.CategoryUsage{Category: cloneCategory(c), Books: counts[c.Slug()]})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Category.ID() < out[j].Category.ID() })
+	return out, nil
+}
+
+// GetCategory retorna una copia de la categoría o domain.ErrNotFound.
+func (r *TaxonomyRepo) GetCategory(ctx context.Context, id uint64) (*domain.Category, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	c, ok := r.s.categories[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneCategory(c), nil
+}
+
+// CreateCategory guarda la categoría (ErrDuplicate si el slug ya existe).
+func (r *TaxonomyRepo) CreateCategory(ctx context.Context, c *domain.Category) (uint64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.categoryBySlug[c.Slug()]; ok {
+		return 0, domain.ErrDuplicate
+	}
+	if _, ok := r.s.categories[c.ParentID()]; c.ParentID() != 0 && !ok {
+		return 0, domain.ErrNotFound // clave foránea parent_id
+	}
+	return r.s.addCategory(c.Name(), c.ParentID()).ID(), nil
+}
+
+// UpdateCategory guarda nombre y padre y actualiza el nombre en sus libros.
+func (r *TaxonomyRepo) UpdateCategory(ctx context.Context, c *domain.Category) ([]uint64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	old, ok := r.s.categories[c.ID()]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	if id, ok := r.s.categoryBySlug[c.Slug()]; ok && id != c.ID() {
+		return nil, domain.ErrDuplicate
+	}
+
+	affected := r.s.recategorizeBooks(old.Slug(), c.Name())
+	delete(r.s.categoryBySlug, old.Slug())
+	r.s.categoryBySlug[c.Slug()] = c.ID()
+	r.s.categories[c.ID()] = domain.HydrateCategory(c.ID(), c.Name(), c.Slug(), c.ParentID(), old.CreatedAt(), r.s.now())
+	return affected, nil
+}
+
+// MergeCategories pasa libros y subcategorías de dupID a keepID y elimina
+// dupID (si keepID estaba dentro de dupID, antes sube al lugar de dupID).
+func (r *TaxonomyRepo) MergeCategories(ctx context.Context, keepID, dupID uint64) ([]uint64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	keep, ok := r.s.categories[keepID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	dup, ok := r.s.categories[dupID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+
+	parent := map[uint64]uint64{}
+	for id, c := range r.s.categories {
+		parent[id] = c.ParentID()
+	}
+	for p, steps := keep.ParentID(), 0; p != 0 && steps <= len(parent); p, steps = parent[p], steps+1 {
+		if p == dupID {
+			keep = r.s.reparent(keep, dup.ParentID())
+			break
+		}
+	}
+	for _, c := range r.s.categories {
+		if c.ParentID() == dupID {
+			r.s.reparent(c, keepID)
+		}
+	}
+
+	affected := r.s.recategorizeBooks(dup.Slug(), keep.Name())
+	delete(r.s.categoryBySlug, dup.Slug())
+	delete(r.s.categories, dupID)
+	return affected, nil
+}
+
+// DeleteCategory elimina la categoría (el servicio verifica antes que no
+// tenga libros ni subcategorías).
+func (r *TaxonomyRepo) DeleteCategory(ctx context.Context, id uint64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	c, ok := r.s.categories[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.s.categoryBySlug, c.Slug())
+	delete(r.s.categories, id)
+	return nil
+}
+
+// -------------------- Helpers del Store (requieren el candado de escritura) --------------------
+
+// ensureCategory devuelve la categoría con el slug de name o la crea en la raíz.
+func (s *Store) ensureCategory(name string) *domain.Category {
+	if id, ok := s.categoryBySlug[domain.Slugify(name)]; ok {
+		return s.categories[id]
+	}
+	return s.addCategory(name, 0)
+}
+
+// addCategory inserta una categoría nueva.
+func (s *Store) addCategory(name string, parentID uint64) *domain.Category {
+	s.nextCategory++
+	now := s.now()
+	c := domain.HydrateCategory(s.nextCategory, name, domain.Slugify(name), parentID, now, now)
+	s.categories[c.ID()] = c
+	s.categoryBySlug[c.Slug()] = c.ID()
+	return c
+}
+
+// reparent guarda c bajo parentID y retorna la versión guardada.
+func (s *Store) reparent(c *domain.Category, parentID uint64) *domain.Category {
+	moved := domain.HydrateCategory(c.ID(), c.Name(), c.Slug(), parentID, c.CreatedAt(), s.now())
+	s.categories[c.ID()] = moved
+	return moved
+}
+
+// ensureTags devuelve los nombres canónicos de las etiquetas (por slug),
+// creando las que no existan.
+func (s *Store) ensureTags(names []string) []string {
+	out := make([]string, 0, len(names))
+	for _, name := range names {
+		slug := domain.Slugify(name)
+		id, ok := s.tagBySlug[slug]
+		if !ok {
+			s.nextTag++
+			id = s.nextTag
+			s.tags[id] = domain.HydrateTag(id, name, slug, s.now())
+			s.tagBySlug[slug] = id
+		}
+		out = append(out, s.tags[id].Name())
+	}
+	return out
+}
+
+// retagBooks reemplaza la etiqueta con slug por name en cada libro que la
+// tiene (name vacío la quita; si el libro ya tenía name, no se repite).
+// Retorna los libros afectados, por ID.
+func (s *Store) retagBooks(slug, name string) []uint64 {
+	var affected []uint64
+	for id, b := range s.books {
+		tags := b.Tags()
+		i := -1
+		for j, t := range tags {
+			if domain.Slugify(t) == slug {
+				i = j
+			}
+		}
+		if i < 0 {
+			continue
+		}
+		affected = append(affected, id)
+
+		next := make([]string, 0, len(tags))
+		for j, t := range tags {
+			switch {
+			case j != i:
+				next = append(next, t)
+			case name != "":
+				next = append(next, name)
+			}
+		}
+		// SetTags descarta la repetida si el libro ya tenía la etiqueta destino
+		s.books[id] = withBook(b, b.Category(), next)
+	}
+	sort.Slice(affected, func(i, j int) bool { return affected[i] < affected[j] })
+	return affected
+}
+
+// recategorizeBooks cambia la categoría con slug por name en sus libros.
+func (s *Store) recategorizeBooks(slug, name string) []uint64 {
+	var affected []uint64
+	for id, b := range s.books {
+		if domain.Slugify(b.Category()) == slug {
+			affected = append(affected, id)
+			s.books[id] = withBook(b, name, b.Tags())
+		}
+	}
+	sort.Slice(affected, func(i, j int) bool { return affected[i] < affected[j] })
+	return affected
+}
+
+// withBook es una copia de b con otra categoría y etiquetas (sin tocar
+// updated_at: solo se sincroniza el nombre desnormalizado).
+func withBook(b *domain.Book, category string, tags []string) *domain.Book {
+	c, _ := domain.HydrateBook(b.ID(), b.Title(), b.Author(), b.Year(), b.ISBN(), category,
+		tags, b.Description(), b.Active(), b.CreatedAt(), b.UpdatedAt())
+	return c
+}
+
+// cloneTag evita compartir el puntero guardado.
+func cloneTag(t *domain.Tag) *domain.Tag {
+	return domain.HydrateTag(t.ID(), t.Name(), t.Slug(), t.CreatedAt())
+}
+
+// cloneCategory evita compartir el puntero guardado.
+func cloneCategory(c *domain.Category) *domain.Category {
+	return domain.HydrateCategory(c.ID(), c.Name(), c.Slug(), c.ParentID(), c.CreatedAt(), c.UpdatedAt())
+}
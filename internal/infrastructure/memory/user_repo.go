@@ -0,0 +1,206 @@
+package memory
+
+import (
+	"cmp"     // Comparación de campos de orden
+	"context" // Firma del contrato (no se usa: no hay E/S)
+	"sort"    // Orden por ID descendente
+	"strings" // Filtros
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain" // Entidad + errores
+)
+
+// UserRepo implementa usecase.UserRepo sobre un Store.
+type UserRepo struct{ s *Store }
+
+// NewUserRepo construye el repositorio de usuarios.
+func NewUserRepo(s *Store) *UserRepo { return &UserRepo{s: s} }
+
+// Create guarda una copia del usuario y retorna el ID asignado.
+func (r *UserRepo) Create(ctx context.Context, u *domain.User) (uint64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.userByEmail[key(u.Email())]; ok {
+		return 0, domain.ErrDuplicate
+	}
+
+	r.s.nextUser++
+	id := r.s.nextUser
+	now := r.s.now()
+	stored, err := domain.HydrateUser(id, u.Name(), u.Email(), u.Role(), u.Active(), u.PasswordHash(), now, now)
+	if err != nil {
+		return 0, err
+	}
+
+	r.s.users[id] = stored
+	r.s.userByEmail[key(u.Email())] = id
+	return id, nil
+}
+
+// GetByID retorna una copia del usuario o domain.ErrNotFound.
+func (r *UserRepo) GetByID(ctx context.Context, id uint64) (*domain.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	u, ok := r.s.users[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneUser(u), nil
+}
+
+// GetByEmail busca por email normalizado (mayúsculas/espacios).
+func (r *UserRepo) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	id, ok := r.s.userByEmail[key(email)]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneUser(r.s.users[id]), nil
+}
+
+// List retorna todos los usuarios ordenados por ID descendente (sin paginar).
+func (r *UserRepo) List(ctx context.Context) ([]*domain.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	out := make([]*domain.User, 0, len(r.s.users))
+	for _, u := range r.s.users {
+		out = append(out, cloneUser(u))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID() > out[j].ID() })
+	return out, nil
+}
+
+// Search aplica el filtro con la misma semántica que SQL y retorna
+// la página pedida con el total de coincidencias.
+func (r *UserRepo) Search(ctx context.Context, f domain.UserFilter) (domain.Page[*domain.User], error) {
+	p, err := f.Paging()
+	if err != nil {
+		return domain.Page[*domain.User]{}, err
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	role := domain.Role(strings.ToUpper(string(f.Role)))
+	email := key(f.Email)
+
+	out := []*domain.User{}
+	for _, u := range r.s.users {
+		if role != "" && u.Role() != role {
+			continue
+		}
+		if f.Active != nil && u.Active() != *f.Active {
+			continue
+		}
+		if email != "" && !containsFold(u.Email(), email) {
+			continue
+		}
+		out = append(out, cloneUser(u))
+	}
+	sortPaged(out, p, userSortFields[p.Sort], (*domain.User).ID)
+	return paginate(out, p), nil
+}
+
+// userSortFields replica userSortColumns del repositorio SQL.
+var userSortFields = map[string]func(a, b *domain.User) int{
+	domain.SortCreatedAt: nil, // orden de inserción (id)
+	"name":               func(a, b *domain.User) int { return cmp.Compare(strings.ToLower(a.Name()), strings.ToLower(b.Name())) },
+	"email":              func(a, b *domain.User) int { return cmp.Compare(a.Email(), b.Email()) },
+	"role":               func(a, b *domain.User) int { return cmp.Compare(a.Role(), b.Role()) },
+}
+
+// Update reemplaza los datos del usuario manteniendo la unicidad del email.
+func (r *UserRepo) Update(ctx context.Context, u *domain.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	old, ok := r.s.users[u.ID()]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if id, ok := r.s.userByEmail[key(u.Email())]; ok && id != u.ID() {
+		return domain.ErrDuplicate
+	}
+
+	stored, err := domain.HydrateUser(u.ID(), u.Name(), u.Email(), u.Role(), u.Active(), u.PasswordHash(), old.CreatedAt(), r.s.now())
+	if err != nil {
+		return err
+	}
+
+	delete(r.s.userByEmail, key(old.Email()))
+	r.s.userByEmail[key(u.Email())] = u.ID()
+	r.s.users[u.ID()] = stored
+	return nil
+}
+
+// Delete elimina el usuario y, en cascada, sus sesiones, tokens y accesos.
+func (r *UserRepo) Delete(ctx context.Context, id uint64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	u, ok := r.s.users[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.s.userByEmail, key(u.Email()))
+	delete(r.s.users, id)
+
+	for h, s := range r.s.sessions {
+		if s.UserID() == id {
+			delete(r.s.sessions, h)
+		}
+	}
+	for tid, t := range r.s.tokens {
+		if t.UserID() == id {
+			delete(r.s.tokens, tid)
+		}
+	}
+	for eid, e := range r.s.access {
+		if e.UserID() == id {
+			delete(r.s.access, eid)
+		}
+	}
+	for lid, l := range r.s.loans {
+		if l.UserID() == id {
+			delete(r.s.loans, lid)
+		}
+	}
+	for hid, h := range r.s.holds {
+		if h.UserID() == id {
+			delete(r.s.holds, hid)
+		}
+	}
+	for pid, p := range r.s.progress {
+		if p.UserID() == id {
+			delete(r.s.progress, pid)
+		}
+	}
+	for aid, a := range r.s.annotations {
+		if a.UserID() == id {
+			delete(r.s.annotations, aid)
+		}
+	}
+	for rid, rv := range r.s.reviews {
+		if rv.UserID() == id {
+			delete(r.s.reviews, rid)
+			delete(r.s.reviewHistory, rid)
+		}
+	}
+	// Los adjuntos quedan sin autor (ON DELETE SET NULL)
+	for fid, f := range r.s.files {
+		if f.UploadedBy() == id {
+			r.s.files[fid] = domain.HydrateBookFile(f.ID(), f.BookID(), f.Name(), f.ContentType(), f.Kind(), f.Size(), f.SHA256(), 0, f.CreatedAt())
+		}
+	}
+	return nil
+}
+
+// cloneUser evita que el llamador modifique el estado guardado sin Update.
+func cloneUser(u *domain.User) *domain.User {
+	c, _ := domain.HydrateUser(u.ID(), u.Name(), u.Email(), u.Role(), u.Active(), u.PasswordHash(), u.CreatedAt(), u.UpdatedAt())
+	return c
+}
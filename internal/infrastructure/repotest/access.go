@@ -0,0 +1,110 @@
+package repotest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+// RunAccessRepo verifica el contrato de usecase.AccessRepo.
+func RunAccessRepo(t *testing.T, newRepos Factory) {
+	t.Run("StatsAlwaysHaveAllTypes", func(t *testing.T) {
+		r := newRepos(t)
+		book := mustBook(t, r.Books, "Uno", "Autor", validISBNs[0], "General")
+
+		stats, err := r.Access.StatsByBook(ctx(), book)
+		wantNoErr(t, err, "stats")
+		for _, at := range []domain.AccessType{domain.AccessApertura, domain.AccessLectura, domain.AccessDescarga} {
+			if v, ok := stats[at]; !ok || v != 0 {
+				t.Fatalf("expected %s=0 present, got %v", at, stats)
+			}
+		}
+	})
+
+	t.Run("StatsCountPerBookAndType", func(t *testing.T) {
+		r := newRepos(t)
+		user := mustUser(t, r.Users, "Ana", "ana@example.com", domain.RoleReader)
+		b1 := mustBook(t, r.Books, "Uno", "Autor", validISBNs[0], "General")
+		b2 := mustBook(t, r.Books, "Dos", "Autor", validISBNs[1], "General")
+
+		record := func(book uint64, at domain.AccessType) uint64 {
+			t.Helper()
+			e, err := domain.NewAccessEvent(user, book, at)
+			wantNoErr(t, err, "new event")
+			id, err := r.Access.Create(ctx(), e)
+			wantNoErr(t, err, "create event")
+			return id
+		}
+
+		first := record(b1, domain.AccessApertura)
+		record(b1, domain.AccessApertura)
+		record(b1, domain.AccessLectura)
+		last := record(b2, domain.AccessDescarga)
+		if first == 0 || last <= first {
+			t.Fatalf("event ids must be positive and increasing: %d, %d", first, last)
+		}
+
+		stats, err := r.Access.StatsByBook(ctx(), b1)
+		wantNoErr(t, err, "stats")
+		if stats[domain.AccessApertura] != 2 || stats[domain.AccessLectura] != 1 || stats[domain.AccessDescarga] != 0 {
+			t.Fatalf("unexpected stats for book 1: %v", stats)
+		}
+		stats, _ = r.Access.StatsByBook(ctx(), b2)
+		if stats[domain.AccessDescarga] != 1 || stats[domain.AccessApertura] != 0 {
+			t.Fatalf("unexpected stats for book 2: %v", stats)
+		}
+	})
+
+	t.Run("ReadingTimeSumsSessions", func(t *testing.T) {
+		r := newRepos(t)
+		user := mustUser(t, r.Users, "Ana", "ana@example.com", domain.RoleReader)
+		b1 := mustBook(t, r.Books, "Uno", "Autor", validISBNs[0], "General")
+		b2 := mustBook(t, r.Books, "Dos", "Autor", validISBNs[1], "General")
+
+		rt, err := r.Access.ReadingTime(ctx(), b1)
+		wantNoErr(t, err, "reading time without sessions")
+		if rt != (domain.ReadingTime{}) {
+			t.Fatalf("expected zero reading time, got %+v", rt)
+		}
+
+		for _, d := range []time.Duration{90 * time.Second, 20*time.Minute + 400*time.Millisecond} {
+			e, err := domain.NewReadingSession(user, b1, d)
+			wantNoErr(t, err, "new session")
+			_, err = r.Access.Create(ctx(), e)
+			wantNoErr(t, err, "create session")
+		}
+		// Los eventos sin duración también cuentan como sesión; los de otro tipo o libro no
+		for _, e := range []struct {
+			book uint64
+			at   domain.AccessType
+		}{{b1, domain.AccessLectura}, {b1, domain.AccessDescarga}, {b2, domain.AccessLectura}} {
+			ev, _ := domain.NewAccessEvent(user, e.book, e.at)
+			_, err := r.Access.Create(ctx(), ev)
+			wantNoErr(t, err, "create event")
+		}
+
+		rt, err = r.Access.ReadingTime(ctx(), b1)
+		wantNoErr(t, err, "reading time")
+		if rt.Sessions != 3 || rt.Total != 21*time.Minute+30*time.Second {
+			t.Fatalf("unexpected reading time %+v", rt)
+		}
+	})
+
+	t.Run("DeletingBookRemovesEvents", func(t *testing.T) {
+		r := newRepos(t)
+		user := mustUser(t, r.Users, "Ana", "ana@example.com", domain.RoleReader)
+		book := mustBook(t, r.Books, "Uno", "Autor", validISBNs[0], "General")
+
+		e, _ := domain.NewAccessEvent(user, book, domain.AccessLectura)
+		_, err := r.Access.Create(ctx(), e)
+		wantNoErr(t, err, "create event")
+		wantNoErr(t, r.Books.Delete(ctx(), book), "delete book")
+
+		stats, err := r.Access.StatsByBook(ctx(), book)
+		wantNoErr(t, err, "stats")
+		if stats[domain.AccessLectura] != 0 {
+			t.Fatalf("events must cascade on book delete: %v", stats)
+		}
+	})
+}
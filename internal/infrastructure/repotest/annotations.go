@@ -0,0 +1,145 @@
+package repotest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+// RunAnnotationRepo verifica el contrato de usecase.AnnotationRepo.
+func RunAnnotationRepo(t *testing.T, newRepos Factory) {
+	if newRepos(t).Annotations == nil {
+		t.Skip("backend without AnnotationRepo")
+	}
+
+	t.Run("CreateUpdateAndDelete", func(t *testing.T) {
+		repos := newRepos(t)
+		r := repos.Annotations
+		ana := mustUser(t, repos.Users, "Ana", "ana@example.com", domain.RoleReader)
+		uno := mustBook(t, repos.Books, "Uno", "Ana", validISBNs[0], "Novela")
+
+		_, err := r.Create(ctx(), newAnnotation(t, 999999, uno, domain.AnnotationContent{Location: "p. 1"}))
+		wantErr(t, err, domain.ErrNotFound, "annotation of missing user")
+		_, err = r.Create(ctx(), newAnnotation(t, ana, 999999, domain.AnnotationContent{Location: "p. 1"}))
+		wantErr(t, err, domain.ErrNotFound, "annotation of missing book")
+
+		a := newAnnotation(t, ana, uno, domain.AnnotationContent{
+			Location: "epubcfi(/6/4!/4/2/1:0)", Quote: "En un lugar de la Mancha", Note: "Comienzo", Color: domain.ColorGreen,
+		})
+		id, err := r.Create(ctx(), a)
+		wantNoErr(t, err, "create")
+		got, err := r.GetByID(ctx(), id)
+		wantNoErr(t, err, "get")
+		if got.ID() != id || got.UserID() != ana || got.BookID() != uno || got.Content() != a.Content() ||
+			got.Visibility() != domain.AnnotationPrivate || !sameInstant(got.CreatedAt(), a.CreatedAt()) {
+			t.Fatalf("unexpected annotation %+v", got)
+		}
+
+		// Editar conserva el alta y guarda la fecha de edición
+		later := time.Now().Add(time.Hour)
+		edited := domain.HydrateAnnotation(id, ana, uno, domain.AnnotationContent{
+			Location: "epubcfi(/6/4!/4/2/1:0)", Quote: "En un lugar de la Mancha", Color: domain.ColorBlue, Visibility: domain.AnnotationPublic,
+		}, later, later)
+		wantNoErr(t, r.Update(ctx(), edited), "update")
+		upd, err := r.GetByID(ctx(), id)
+		wantNoErr(t, err, "get updated")
+		if upd.Note() != "" || upd.Color() != domain.ColorBlue || upd.Visibility() != domain.AnnotationPublic ||
+			!sameInstant(upd.CreatedAt(), a.CreatedAt()) || !sameInstant(upd.UpdatedAt(), later) {
+			t.Fatalf("unexpected updated annotation %+v", upd)
+		}
+
+		wantNoErr(t, r.Delete(ctx(), id), "delete")
+		_, err = r.GetByID(ctx(), id)
+		wantErr(t, err, domain.ErrNotFound, "get deleted")
+		wantErr(t, r.Delete(ctx(), id), domain.ErrNotFound, "delete missing")
+		wantErr(t, r.Update(ctx(), edited), domain.ErrNotFound, "update missing")
+	})
+
+	t.Run("ListFilters", func(t *testing.T) {
+		repos := newRepos(t)
+		r := repos.Annotations
+		ana := mustUser(t, repos.Users, "Ana", "ana@example.com", domain.RoleReader)
+		beto := mustUser(t, repos.Users, "Beto", "beto@example.com", domain.RoleReader)
+		uno := mustBook(t, repos.Books, "Uno", "Autora", validISBNs[0], "Novela")
+		dos := mustBook(t, repos.Books, "Dos", "Autor", validISBNs[1], "Novela")
+
+		add := func(user, book uint64, location string, v domain.AnnotationVisibility, c domain.AnnotationColor) uint64 {
+			t.Helper()
+			id, err := r.Create(ctx(), newAnnotation(t, user, book, domain.AnnotationContent{Location: location, Color: c, Visibility: v}))
+			wantNoErr(t, err, "create")
+			return id
+		}
+		a1 := add(ana, uno, "p. 30", domain.AnnotationPrivate, domain.ColorYellow)
+		a2 := add(ana, uno, "p. 10", domain.AnnotationClass, domain.ColorGreen)
+		a3 := add(ana, dos, "p. 20", domain.AnnotationPublic, domain.ColorYellow)
+		b1 := add(beto, uno, "p. 40", domain.AnnotationPublic, domain.ColorYellow)
+
+		// Por defecto lo más reciente primero
+		mine, err := r.List(ctx(), domain.AnnotationFilter{UserID: ana})
+		wantNoErr(t, err, "list mine")
+		if got := detailIDs(mine.Items); mine.Total != 3 || !reflect.DeepEqual(got, []uint64{a3, a2, a1}) {
+			t.Fatalf("unexpected list %v", got)
+		}
+		if d := mine.Items[0]; d.BookTitle != "Dos" || d.UserName != "Ana" {
+			t.Fatalf("unexpected detail %+v", d)
+		}
+
+		shared, err := r.List(ctx(), domain.AnnotationFilter{BookID: uno,
+			Visibilities: []domain.AnnotationVisibility{domain.AnnotationClass, domain.AnnotationPublic},
+			PageRequest:  domain.PageRequest{Sort: "location"}})
+		wantNoErr(t, err, "list shared")
+		if got := detailIDs(shared.Items); !reflect.DeepEqual(got, []uint64{a2, b1}) {
+			t.Fatalf("unexpected shared list %v", got)
+		}
+		yellow, err := r.List(ctx(), domain.AnnotationFilter{UserID: ana, Color: domain.ColorYellow})
+		wantNoErr(t, err, "list by color")
+		if got := detailIDs(yellow.Items); !reflect.DeepEqual(got, []uint64{a3, a1}) {
+			t.Fatalf("unexpected yellow list %v", got)
+		}
+
+		_, err = r.List(ctx(), domain.AnnotationFilter{Visibilities: []domain.AnnotationVisibility{"secret"}})
+		wantErr(t, err, domain.ErrValidation, "invalid visibility")
+		_, err = r.List(ctx(), domain.AnnotationFilter{Color: "black"})
+		wantErr(t, err, domain.ErrValidation, "invalid color")
+	})
+
+	t.Run("CascadeOnDelete", func(t *testing.T) {
+		repos := newRepos(t)
+		r := repos.Annotations
+		ana := mustUser(t, repos.Users, "Ana", "ana@example.com", domain.RoleReader)
+		beto := mustUser(t, repos.Users, "Beto", "beto@example.com", domain.RoleReader)
+		uno := mustBook(t, repos.Books, "Uno", "Ana", validISBNs[0], "Novela")
+		dos := mustBook(t, repos.Books, "Dos", "Ana", validISBNs[1], "Novela")
+		for _, pair := range [][2]uint64{{ana, uno}, {ana, dos}, {beto, dos}} {
+			_, err := r.Create(ctx(), newAnnotation(t, pair[0], pair[1], domain.AnnotationContent{Location: "p. 1"}))
+			wantNoErr(t, err, "create")
+		}
+
+		wantNoErr(t, repos.Books.Delete(ctx(), uno), "delete book")
+		wantNoErr(t, repos.Users.Delete(ctx(), beto), "delete user")
+		page, err := r.List(ctx(), domain.AnnotationFilter{})
+		wantNoErr(t, err, "list")
+		if page.Total != 1 || page.Items[0].Annotation.UserID() != ana || page.Items[0].Annotation.BookID() != dos {
+			t.Fatalf("expected only ana's annotation in dos, got %+v", page)
+		}
+	})
+}
+
+func newAnnotation(t *testing.T, userID, bookID uint64, c domain.AnnotationContent) *domain.Annotation {
+	t.Helper()
+	a, err := domain.NewAnnotation(userID, bookID, c)
+	if err != nil {
+		t.Fatalf("new annotation: %v", err)
+	}
+	return a
+}
+
+func detailIDs(items []domain.AnnotationDetail) []uint64 {
+	out := make([]uint64, 0, len(items))
+	for _, d := range items {
+		out = append(out, d.Annotation.ID())
+	}
+	return out
+}
@@ -0,0 +1,129 @@
+package repotest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+// RunSessionRepo verifica el contrato de usecase.SessionRepo.
+func RunSessionRepo(t *testing.T, newRepos Factory) {
+	if newRepos(t).Sessions == nil {
+		t.Skip("backend without SessionRepo")
+	}
+
+	t.Run("Lifecycle", func(t *testing.T) {
+		r := newRepos(t)
+		user := mustUser(t, r.Users, "Ana", "ana@example.com", domain.RoleReader)
+
+		now := time.Now()
+		s, _ := domain.NewSession("hash-1", user, now.Add(time.Hour))
+		wantNoErr(t, r.Sessions.Create(ctx(), s), "create")
+		wantErr(t, r.Sessions.Create(ctx(), s), domain.ErrDuplicate, "create duplicate")
+
+		got, err := r.Sessions.GetByTokenHash(ctx(), "hash-1")
+		wantNoErr(t, err, "get")
+		if got.UserID() != user || !sameInstant(got.ExpiresAt(), s.ExpiresAt()) {
+			t.Fatalf("unexpected session %+v", got)
+		}
+
+		wantNoErr(t, r.Sessions.Delete(ctx(), "hash-1"), "delete")
+		_, err = r.Sessions.GetByTokenHash(ctx(), "hash-1")
+		wantErr(t, err, domain.ErrNotFound, "get deleted")
+		wantErr(t, r.Sessions.Delete(ctx(), "hash-1"), domain.ErrNotFound, "delete twice")
+	})
+
+	t.Run("DeleteExpired", func(t *testing.T) {
+		r := newRepos(t)
+		user := mustUser(t, r.Users, "Ana", "ana@example.com", domain.RoleReader)
+
+		now := time.Now()
+		short, _ := domain.NewSession("short", user, now.Add(time.Minute))
+		long, _ := domain.NewSession("long", user, now.Add(time.Hour))
+		wantNoErr(t, r.Sessions.Create(ctx(), short), "create short")
+		wantNoErr(t, r.Sessions.Create(ctx(), long), "create long")
+
+		wantNoErr(t, r.Sessions.DeleteExpired(ctx(), now.Add(2*time.Minute)), "delete expired")
+		_, err := r.Sessions.GetByTokenHash(ctx(), "short")
+		wantErr(t, err, domain.ErrNotFound, "expired session")
+		_, err = r.Sessions.GetByTokenHash(ctx(), "long")
+		wantNoErr(t, err, "live session")
+	})
+
+	t.Run("DeletingUserRemovesSessions", func(t *testing.T) {
+		r := newRepos(t)
+		user := mustUser(t, r.Users, "Ana", "ana@example.com", domain.RoleReader)
+		s, _ := domain.NewSession("hash-1", user, time.Now().Add(time.Hour))
+		wantNoErr(t, r.Sessions.Create(ctx(), s), "create")
+
+		wantNoErr(t, r.Users.Delete(ctx(), user), "delete user")
+		_, err := r.Sessions.GetByTokenHash(ctx(), "hash-1")
+		wantErr(t, err, domain.ErrNotFound, "session of deleted user")
+	})
+}
+
+// RunAPITokenRepo verifica el contrato de usecase.APITokenRepo.
+func RunAPITokenRepo(t *testing.T, newRepos Factory) {
+	if newRepos(t).Tokens == nil {
+		t.Skip("backend without APITokenRepo")
+	}
+
+	newToken := func(t *testing.T, user uint64, hash string, expires time.Time) *domain.APIToken {
+		t.Helper()
+		tok, err := domain.NewAPIToken(user, "script "+hash, hash, "slk_abc", []domain.Permission{domain.PermBooksRead}, expires)
+		wantNoErr(t, err, "new token")
+		return tok
+	}
+
+	t.Run("Lifecycle", func(t *testing.T) {
+		r := newRepos(t)
+		user := mustUser(t, r.Users, "Ana", "ana@example.com", domain.RoleReader)
+
+		expires := time.Now().Add(24 * time.Hour)
+		id, err := r.Tokens.Create(ctx(), newToken(t, user, "hash-1", expires))
+		wantNoErr(t, err, "create")
+		_, err = r.Tokens.Create(ctx(), newToken(t, user, "hash-1", time.Time{}))
+		wantErr(t, err, domain.ErrDuplicate, "create duplicate")
+
+		got, err := r.Tokens.GetByHash(ctx(), "hash-1")
+		wantNoErr(t, err, "get by hash")
+		if got.ID() != id || got.UserID() != user || !got.HasScope(domain.PermBooksRead) ||
+			!sameInstant(got.ExpiresAt(), expires) || got.Revoked() || !got.LastUsedAt().IsZero() {
+			t.Fatalf("unexpected token %+v", got)
+		}
+
+		used := time.Now()
+		wantNoErr(t, r.Tokens.TouchLastUsed(ctx(), id, used), "touch")
+		got, _ = r.Tokens.GetByID(ctx(), id)
+		if !sameInstant(got.LastUsedAt(), used) {
+			t.Fatalf("last used not persisted: %v", got.LastUsedAt())
+		}
+
+		wantNoErr(t, r.Tokens.Revoke(ctx(), id, time.Now()), "revoke")
+		got, _ = r.Tokens.GetByID(ctx(), id)
+		if !got.Revoked() {
+			t.Fatalf("token must be revoked")
+		}
+		wantErr(t, r.Tokens.Revoke(ctx(), id, time.Now()), domain.ErrNotFound, "revoke twice")
+
+		_, err = r.Tokens.GetByID(ctx(), 999999)
+		wantErr(t, err, domain.ErrNotFound, "get missing")
+	})
+
+	t.Run("ListByUserNewestFirst", func(t *testing.T) {
+		r := newRepos(t)
+		ana := mustUser(t, r.Users, "Ana", "ana@example.com", domain.RoleReader)
+		beto := mustUser(t, r.Users, "Beto", "beto@example.com", domain.RoleReader)
+
+		first, _ := r.Tokens.Create(ctx(), newToken(t, ana, "h1", time.Time{}))
+		_, _ = r.Tokens.Create(ctx(), newToken(t, beto, "h2", time.Time{}))
+		second, _ := r.Tokens.Create(ctx(), newToken(t, ana, "h3", time.Time{}))
+
+		list, err := r.Tokens.ListByUser(ctx(), ana)
+		wantNoErr(t, err, "list")
+		if len(list) != 2 || list[0].ID() != second || list[1].ID() != first {
+			t.Fatalf("expected [%d %d], got %d tokens", second, first, len(list))
+		}
+	})
+}
@@ -0,0 +1,180 @@
+package repotest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/usecase"
+)
+
+// RunAuthorRepo verifica el contrato de usecase.AuthorRepo.
+func RunAuthorRepo(t *testing.T, newRepos Factory) {
+	if newRepos(t).Authors == nil {
+		t.Skip("backend without AuthorRepo")
+	}
+
+	t.Run("CreateGetUpdate", func(t *testing.T) {
+		r := newRepos(t).Authors
+		id := mustAuthor(t, r, "Gabriel García Márquez")
+
+		got, err := r.GetByID(ctx(), id)
+		wantNoErr(t, err, "get")
+		if got.ID() != id || got.Name() != "Gabriel García Márquez" || got.CreatedAt().IsZero() {
+			t.Fatalf("unexpected author %+v", got)
+		}
+
+		byName, err := r.GetByName(ctx(), "  gabriel  garcía márquez ")
+		wantNoErr(t, err, "get by name")
+		if byName.ID() != id {
+			t.Fatalf("get by name: expected %d, got %d", id, byName.ID())
+		}
+
+		wantNoErr(t, got.SetName("Gabo"), "set name")
+		got.SetBio("Nobel 1982")
+		wantNoErr(t, r.Update(ctx(), got), "update")
+		got, _ = r.GetByID(ctx(), id)
+		if got.Name() != "Gabo" || got.Bio() != "Nobel 1982" {
+			t.Fatalf("update not persisted: %+v", got)
+		}
+
+		_, err = r.GetByID(ctx(), 999999)
+		wantErr(t, err, domain.ErrNotFound, "get missing")
+		_, err = r.GetByName(ctx(), "Nadie")
+		wantErr(t, err, domain.ErrNotFound, "get by missing name")
+		wantErr(t, r.Update(ctx(), domain.HydrateAuthor(999999, "Nadie", "", got.CreatedAt(), got.UpdatedAt())), domain.ErrNotFound, "update missing")
+		wantErr(t, r.Delete(ctx(), 999999), domain.ErrNotFound, "delete missing")
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		r := newRepos(t).Authors
+		borges := mustAuthor(t, r, "Jorge Luis Borges")
+		cortazar := mustAuthor(t, r, "Julio Cortázar")
+		bioy := mustAuthor(t, r, "Adolfo Bioy Casares")
+
+		page, err := r.Search(ctx(), domain.AuthorFilter{PageRequest: domain.PageRequest{Sort: "name"}})
+		wantNoErr(t, err, "search all")
+		if want := []uint64{bioy, borges, cortazar}; page.Total != 3 || !reflect.DeepEqual(ids(page.Items), want) {
+			t.Fatalf("by name: expected %v, got %v (total %d)", want, ids(page.Items), page.Total)
+		}
+
+		page, err = r.Search(ctx(), domain.AuthorFilter{Q: "OR", PageRequest: domain.PageRequest{PageSize: 1}})
+		wantNoErr(t, err, "search q")
+		if page.Total != 2 || len(page.Items) != 1 || page.Items[0].ID() != cortazar {
+			t.Fatalf("q=OR: expected newest of 2 matches, got %v (total %d)", ids(page.Items), page.Total)
+		}
+
+		_, err = r.Search(ctx(), domain.AuthorFilter{PageRequest: domain.PageRequest{Sort: "year"}})
+		wantErr(t, err, domain.ErrValidation, "invalid sort")
+	})
+
+	t.Run("Credits", func(t *testing.T) {
+		repos := newRepos(t)
+		r := repos.Authors
+		ana := mustAuthor(t, r, "Ana Autora")
+		eva := mustAuthor(t, r, "Eva Editora")
+		old := mustBook(t, repos.Books, "Antología", "Eva Editora (ed.)", validISBNs[0], "General")
+		book := mustBook(t, repos.Books, "Cuentos", "Ana Autora", validISBNs[1], "General")
+
+		credits := []domain.BookCredit{
+			{AuthorID: eva, Role: domain.CreditEditor},
+			{AuthorID: ana, Role: domain.CreditAuthor},
+			{AuthorID: ana, Role: domain.CreditTranslator},
+		}
+		wantNoErr(t, r.SetBookCredits(ctx(), book, credits), "set credits")
+		wantNoErr(t, r.SetBookCredits(ctx(), old, credits[:1]), "set credits old")
+
+		got, err := r.BookCredits(ctx(), book)
+		wantNoErr(t, err, "credits")
+		want := []domain.BookCredit{
+			{AuthorID: eva, Name: "Eva Editora", Role: domain.CreditEditor, Position: 0},
+			{AuthorID: ana, Name: "Ana Autora", Role: domain.CreditAuthor, Position: 1},
+			{AuthorID: ana, Name: "Ana Autora", Role: domain.CreditTranslator, Position: 2},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("credits: expected %+v, got %+v", want, got)
+		}
+
+		// Reemplazo completo y reordenado
+		wantNoErr(t, r.SetBookCredits(ctx(), book, []domain.BookCredit{{AuthorID: ana, Role: domain.CreditAuthor}, {AuthorID: eva, Role: domain.CreditEditor}}), "replace credits")
+		got, _ = r.BookCredits(ctx(), book)
+		if len(got) != 2 || got[0].AuthorID != ana || got[1].AuthorID != eva || got[1].Position != 1 {
+			t.Fatalf("replaced credits: got %+v", got)
+		}
+
+		// Libros de Eva: por año y título, con su rol
+		works, err := r.AuthorBooks(ctx(), eva)
+		wantNoErr(t, err, "author books")
+		if len(works) != 2 || works[0].Book.ID() != old || works[1].Book.ID() != book || works[0].Role != domain.CreditEditor {
+			t.Fatalf("author books: got %+v", works)
+		}
+
+		// Borrar el libro borra sus créditos
+		wantNoErr(t, repos.Books.Delete(ctx(), book), "delete book")
+		works, _ = r.AuthorBooks(ctx(), ana)
+		if len(works) != 0 {
+			t.Fatalf("credits of deleted book remain: %+v", works)
+		}
+
+		// Borrar el autor borra sus créditos
+		wantNoErr(t, r.Delete(ctx(), eva), "delete author")
+		got, _ = r.BookCredits(ctx(), old)
+		if len(got) != 0 {
+			t.Fatalf("credits of deleted author remain: %+v", got)
+		}
+	})
+
+	t.Run("Merge", func(t *testing.T) {
+		repos := newRepos(t)
+		r := repos.Authors
+		keep := mustAuthor(t, r, "Jorge Luis Borges")
+		dup := mustAuthor(t, r, "J. L. Borges")
+		bioy := mustAuthor(t, r, "Adolfo Bioy Casares")
+
+		shared := mustBook(t, repos.Books, "Seis problemas", "Borges, Bioy", validISBNs[0], "Policial")
+		onlyDup := mustBook(t, repos.Books, "Ficciones", "J. L. Borges", validISBNs[1], "Cuento")
+		wantNoErr(t, r.SetBookCredits(ctx(), shared, []domain.BookCredit{
+			{AuthorID: dup, Role: domain.CreditAuthor},
+			{AuthorID: bioy, Role: domain.CreditAuthor},
+			{AuthorID: keep, Role: domain.CreditAuthor},
+		}), "credits shared")
+		wantNoErr(t, r.SetBookCredits(ctx(), onlyDup, []domain.BookCredit{{AuthorID: dup, Role: domain.CreditAuthor}}), "credits onlyDup")
+
+		affected, err := r.Merge(ctx(), keep, dup)
+		wantNoErr(t, err, "merge")
+		if want := []uint64{shared, onlyDup}; !reflect.DeepEqual(affected, want) {
+			t.Fatalf("affected: expected %v, got %v", want, affected)
+		}
+
+		_, err = r.GetByID(ctx(), dup)
+		wantErr(t, err, domain.ErrNotFound, "merged author")
+
+		got, _ := r.BookCredits(ctx(), shared)
+		if len(got) != 2 || got[0].AuthorID != bioy || got[1].AuthorID != keep {
+			t.Fatalf("shared credits: expected bioy, keep; got %+v", got)
+		}
+		got, _ = r.BookCredits(ctx(), onlyDup)
+		if len(got) != 1 || got[0].AuthorID != keep || got[0].Name != "Jorge Luis Borges" {
+			t.Fatalf("moved credit: got %+v", got)
+		}
+
+		_, err = r.Merge(ctx(), keep, 999999)
+		wantErr(t, err, domain.ErrNotFound, "merge missing")
+	})
+}
+
+func mustAuthor(t *testing.T, r usecase.AuthorRepo, name string) uint64 {
+	t.Helper()
+	a, err := domain.NewAuthor(name, "")
+	if err != nil {
+		t.Fatalf("new author: %v", err)
+	}
+	id, err := r.Create(ctx(), a)
+	if err != nil {
+		t.Fatalf("create author %s: %v", name, err)
+	}
+	if id == 0 {
+		t.Fatalf("create author %s: expected id > 0", name)
+	}
+	return id
+}
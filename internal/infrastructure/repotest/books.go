@@ -0,0 +1,320 @@
+package repotest
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+// RunBookRepo verifica el contrato de usecase.BookRepo.
+func RunBookRepo(t *testing.T, newRepos Factory) {
+	t.Run("CreateAndGet", func(t *testing.T) {
+		r := newRepos(t).Books
+		b, _ := domain.NewBook("Cien años de soledad", "Gabriel García Márquez", 1967,
+			validISBNs[0], "Literatura", []string{"novela", "realismo mágico"}, "Macondo")
+		id, err := r.Create(ctx(), b)
+		wantNoErr(t, err, "create")
+
+		got, err := r.GetByID(ctx(), id)
+		wantNoErr(t, err, "get")
+		if got.ID() != id || got.Title() != "Cien años de soledad" || got.Author() != "Gabriel García Márquez" ||
+			got.Year() != 1967 || got.ISBN() != validISBNs[0] || got.Category() != "Literatura" ||
+			got.Description() != "Macondo" || !got.Active() {
+			t.Fatalf("unexpected book %+v", got)
+		}
+		if !reflect.DeepEqual(got.Tags(), b.Tags()) {
+			t.Fatalf("tags: expected %v, got %v", b.Tags(), got.Tags())
+		}
+		if got.CreatedAt().IsZero() || got.UpdatedAt().IsZero() {
+			t.Fatalf("timestamps not set")
+		}
+
+		byISBN, err := r.GetByISBN(ctx(), validISBNs[0])
+		wantNoErr(t, err, "get by isbn")
+		if byISBN.ID() != id {
+			t.Fatalf("get by isbn: expected %d, got %d", id, byISBN.ID())
+		}
+	})
+
+	t.Run("DuplicateISBN", func(t *testing.T) {
+		r := newRepos(t).Books
+		mustBook(t, r, "Uno", "Autor", validISBNs[0], "General")
+
+		dup, _ := domain.NewBook("Dos", "Otro", 2001, validISBNs[0], "General", nil, "")
+		_, err := r.Create(ctx(), dup)
+		wantErr(t, err, domain.ErrDuplicate, "create duplicate")
+
+		// Otro formato del mismo ISBN: el dominio lo normaliza antes de guardar
+		dup, err = domain.NewBook("Tres", "Otro", 2001, domain.ISBN10(validISBNs[0]), "General", nil, "")
+		wantNoErr(t, err, "new book isbn-10")
+		_, err = r.Create(ctx(), dup)
+		wantErr(t, err, domain.ErrDuplicate, "create duplicate isbn-10")
+	})
+
+	t.Run("GetByISBNAnyFormat", func(t *testing.T) {
+		r := newRepos(t).Books
+		id := mustBook(t, r, "Uno", "Autor", validISBNs[0], "General")
+
+		for _, isbn := range []string{validISBNs[0], domain.FormatISBN(validISBNs[0]), domain.ISBN10(validISBNs[0]), " ISBN " + validISBNs[0]} {
+			b, err := r.GetByISBN(ctx(), isbn)
+			wantNoErr(t, err, "get by isbn "+isbn)
+			if b.ID() != id || b.ISBN() != validISBNs[0] {
+				t.Fatalf("get by isbn %q: expected book %d with canonical isbn, got %d %q", isbn, id, b.ID(), b.ISBN())
+			}
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		r := newRepos(t).Books
+		_, err := r.GetByID(ctx(), 999999)
+		wantErr(t, err, domain.ErrNotFound, "get by id")
+		_, err = r.GetByISBN(ctx(), validISBNs[7])
+		wantErr(t, err, domain.ErrNotFound, "get by isbn")
+		wantErr(t, r.Delete(ctx(), 999999), domain.ErrNotFound, "delete")
+	})
+
+	t.Run("ListNewestFirst", func(t *testing.T) {
+		r := newRepos(t).Books
+		ids := []uint64{
+			mustBook(t, r, "Libro A", "Autor", validISBNs[0], "General"),
+			mustBook(t, r, "Libro B", "Autor", validISBNs[1], "General"),
+			mustBook(t, r, "Libro C", "Autor", validISBNs[2], "General"),
+		}
+		list, err := r.List(ctx())
+		wantNoErr(t, err, "list")
+		if len(list) != 3 {
+			t.Fatalf("expected 3 books, got %d", len(list))
+		}
+		for i, b := range list {
+			if b.ID() != ids[len(ids)-1-i] {
+				t.Fatalf("list must be ordered by id desc, got %d at %d", b.ID(), i)
+			}
+		}
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		r := newRepos(t).Books
+		cienBook, _ := domain.NewBook("Cien años de soledad", "Gabriel Garcia Marquez", 2000, validISBNs[0],
+			"Literatura", []string{"novela"}, "La familia Buendia en Macondo")
+		cien, err := r.Create(ctx(), cienBook)
+		wantNoErr(t, err, "create")
+		amor := mustBook(t, r, "El amor en los tiempos del colera", "Gabriel Garcia Marquez", validISBNs[1], "Literatura", "romance")
+		gopl := mustBook(t, r, "The Go Programming Language", "Donovan", validISBNs[2], "Programacion", "golang", "novela-grafica")
+
+		check := func(name string, f domain.BookFilter, want ...uint64) {
+			t.Helper()
+			page, err := r.Search(ctx(), f)
+			wantNoErr(t, err, name)
+			got := page.Items
+			if len(got) != len(want) || page.Total != len(want) {
+				t.Fatalf("%s: expected %d books, got %d (total %d)", name, len(want), len(got), page.Total)
+			}
+			for i := range want {
+				if got[i].ID() != want[i] {
+					t.Fatalf("%s: expected id %d at %d, got %d", name, want[i], i, got[i].ID())
+				}
+			}
+		}
+
+		check("all", domain.BookFilter{}, gopl, amor, cien)
+		check("q title", domain.BookFilter{Q: "SOLEDAD"}, cien)
+		check("q author", domain.BookFilter{Q: "marquez"}, amor, cien)
+		check("q tags", domain.BookFilter{Q: "Novela"}, gopl, cien)
+		check("q description", domain.BookFilter{Q: "MACONDO"}, cien)
+		check("author", domain.BookFilter{Author: "DONOVAN"}, gopl)
+		check("category", domain.BookFilter{Category: "literat"}, amor, cien)
+		check("combined", domain.BookFilter{Q: "novela", Category: "literatura"}, cien)
+		check("tag exact", domain.BookFilter{Tag: "NOVELA"}, cien) // no "novela-grafica"
+		check("tag middle", domain.BookFilter{Tag: "novela-grafica"}, gopl)
+		check("tag first", domain.BookFilter{Tag: "golang"}, gopl)
+		check("none", domain.BookFilter{Q: "inexistente"})
+	})
+
+	t.Run("SearchPagination", func(t *testing.T) {
+		r := newRepos(t).Books
+		var all []uint64
+		for i, isbn := range validISBNs[:5] {
+			all = append(all, mustBook(t, r, fmt.Sprintf("Tomo %d", i+1), "Autor", isbn, "Saga"))
+		}
+		mustBook(t, r, "Otro", "Autor", validISBNs[5], "Suelto")
+
+		check := func(name string, f domain.BookFilter, total, page, size int, want ...uint64) {
+			t.Helper()
+			got, err := r.Search(ctx(), f)
+			wantNoErr(t, err, name)
+			if got.Total != total || got.Page != page || got.PageSize != size {
+				t.Fatalf("%s: expected total=%d page=%d size=%d, got %d/%d/%d",
+					name, total, page, size, got.Total, got.Page, got.PageSize)
+			}
+			if !reflect.DeepEqual(ids(got.Items), append([]uint64{}, want...)) {
+				t.Fatalf("%s: expected %v, got %v", name, want, ids(got.Items))
+			}
+		}
+
+		saga := func(page, size int) domain.BookFilter {
+			return domain.BookFilter{Category: "saga", PageRequest: domain.PageRequest{Page: page, PageSize: size}}
+		}
+		check("page 1", saga(1, 2), 5, 1, 2, all[4], all[3])
+		check("page 2", saga(2, 2), 5, 2, 2, all[2], all[1])
+		check("last page", saga(3, 2), 5, 3, 2, all[0])
+		check("beyond end", saga(4, 2), 5, 4, 2)
+		check("defaults", saga(0, 0), 5, 1, domain.DefaultPageSize, all[4], all[3], all[2], all[1], all[0])
+		check("size clamp", saga(1, domain.MaxPageSize+1), 5, 1, domain.MaxPageSize, all[4], all[3], all[2], all[1], all[0])
+
+		// Una página cuyo offset desbordaría un int se rechaza (no panic ni OFFSET negativo)
+		_, err := r.Search(ctx(), saga(math.MaxInt, domain.MaxPageSize))
+		wantErr(t, err, domain.ErrValidation, "huge page")
+	})
+
+	t.Run("SearchSort", func(t *testing.T) {
+		r := newRepos(t).Books
+		create := func(title, author string, year int, isbn string) uint64 {
+			t.Helper()
+			b, err := domain.NewBook(title, author, year, isbn, "General", nil, "")
+			wantNoErr(t, err, "new book")
+			id, err := r.Create(ctx(), b)
+			wantNoErr(t, err, "create")
+			return id
+		}
+		beta := create("beta", "Zapata", 1990, validISBNs[0])
+		alfa := create("Alfa", "Mora", 2010, validISBNs[1])
+		gama := create("Gama", "mora", 1990, validISBNs[2])
+
+		check := func(sort string, dir domain.SortDir, want ...uint64) {
+			t.Helper()
+			got, err := r.Search(ctx(), domain.BookFilter{PageRequest: domain.PageRequest{Sort: sort, Dir: dir}})
+			wantNoErr(t, err, sort)
+			if !reflect.DeepEqual(ids(got.Items), want) {
+				t.Fatalf("sort %s %s: expected %v, got %v", sort, dir, want, ids(got.Items))
+			}
+		}
+
+		check("", "", gama, alfa, beta) // created_at desc por defecto
+		check("created_at", domain.SortAsc, beta, alfa, gama)
+		check("title", "", alfa, beta, gama) // sin distinguir mayúsculas
+		check("title", domain.SortDesc, gama, beta, alfa)
+		check("author", domain.SortAsc, alfa, gama, beta) // empate "Mora"/"mora": desempata id
+		check("year", domain.SortAsc, beta, gama, alfa)
+		check("year", domain.SortDesc, alfa, gama, beta)
+
+		years := func(from, to int) []uint64 {
+			t.Helper()
+			got, err := r.Search(ctx(), domain.BookFilter{YearFrom: from, YearTo: to})
+			wantNoErr(t, err, "years")
+			return ids(got.Items)
+		}
+		if got := years(2000, 0); !reflect.DeepEqual(got, []uint64{alfa}) {
+			t.Fatalf("year_from: expected [%d], got %v", alfa, got)
+		}
+		if got := years(0, 1990); !reflect.DeepEqual(got, []uint64{gama, beta}) {
+			t.Fatalf("year_to: expected [%d %d], got %v", gama, beta, got)
+		}
+		if got := years(1991, 2009); len(got) != 0 {
+			t.Fatalf("year range: expected none, got %v", got)
+		}
+
+		_, err := r.Search(ctx(), domain.BookFilter{PageRequest: domain.PageRequest{Sort: "isbn"}})
+		wantErr(t, err, domain.ErrValidation, "unknown sort")
+		_, err = r.Search(ctx(), domain.BookFilter{PageRequest: domain.PageRequest{Dir: "up"}})
+		wantErr(t, err, domain.ErrValidation, "unknown dir")
+	})
+
+	t.Run("SearchQuery", func(t *testing.T) {
+		r := newRepos(t).Books
+		create := func(title, author string, year int, isbn, category string, tags ...string) uint64 {
+			t.Helper()
+			b, err := domain.NewBook(title, author, year, isbn, category, tags, "")
+			wantNoErr(t, err, "new book")
+			id, err := r.Create(ctx(), b)
+			wantNoErr(t, err, "create")
+			return id
+		}
+		cien := create("Cien años de soledad", "Gabriel García Márquez", 1967, validISBNs[0], "Literatura", "novela", "realismo")
+		pez := create("El cuento del pez", "Gabriel García Márquez", 1975, validISBNs[1], "Literatura", "novela", "infantil")
+		coronel := create("El coronel no tiene quien le escriba", "Gabriel García Márquez", 1990, validISBNs[2], "Literatura", "novela")
+		rayuela := create("Rayuela", "Julio Cortázar", 1963, validISBNs[3], "Literatura", "novela")
+		gopl := create("The Go Programming Language", "Donovan", 2015, validISBNs[4], "Programacion", "golang")
+
+		check := func(q string, want ...uint64) {
+			t.Helper()
+			got, err := r.Search(ctx(), domain.BookFilter{Q: q})
+			wantNoErr(t, err, q)
+			if !reflect.DeepEqual(ids(got.Items), append([]uint64{}, want...)) || got.Total != len(want) {
+				t.Fatalf("q %q: expected %v, got %v (total %d)", q, want, ids(got.Items), got.Total)
+			}
+		}
+
+		check(`author:"García Márquez" year:1960..1980 tag:novela -tag:infantil category:literatura`, cien)
+		check("author:cortázar", rayuela)
+		check("title:COronel", coronel)
+		check("tag:NOVELA year:1990", coronel)
+		check("year:..1965", rayuela)
+		check("year:1980..", gopl, coronel)
+		check("isbn:"+strings.ToLower(validISBNs[4]), gopl)
+		check("rayuela OR golang", gopl, rayuela)
+		check("(tag:golang OR author:cortázar) -year:2015", rayuela)
+		check("NOT tag:novela", gopl)
+		check(`"no tiene quien"`, coronel)
+		check("sol* márquez", cien)
+		check("pez coronel") // AND implícito
+		check("", gopl, rayuela, coronel, pez, cien)
+
+		// Combinado con los filtros estructurados
+		got, err := r.Search(ctx(), domain.BookFilter{Q: "-tag:infantil", Author: "garcía", YearTo: 1980})
+		wantNoErr(t, err, "combined")
+		if !reflect.DeepEqual(ids(got.Items), []uint64{cien}) {
+			t.Fatalf("combined: expected [%d], got %v", cien, ids(got.Items))
+		}
+
+		for _, bad := range []string{`author:"sin cerrar`, "author:", "year:abc", "year:1980..1960", "(novela", "novela OR", "NOT"} {
+			_, err := r.Search(ctx(), domain.BookFilter{Q: bad})
+			wantErr(t, err, domain.ErrValidation, bad)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		r := newRepos(t).Books
+		id := mustBook(t, r, "Uno", "Autor", validISBNs[0], "General")
+		mustBook(t, r, "Dos", "Autor", validISBNs[1], "General")
+
+		b, _ := r.GetByID(ctx(), id)
+		_ = b.SetTitle("Uno (2a ed.)")
+		_ = b.SetYear(2010)
+		_ = b.SetISBN(validISBNs[2])
+		b.SetTags([]string{"revisada"})
+		b.SetDescription("nueva")
+		b.Deactivate()
+		wantNoErr(t, r.Update(ctx(), b), "update")
+
+		got, err := r.GetByISBN(ctx(), validISBNs[2])
+		wantNoErr(t, err, "get updated")
+		if got.ID() != id || got.Title() != "Uno (2a ed.)" || got.Year() != 2010 ||
+			got.Description() != "nueva" || got.Active() || !reflect.DeepEqual(got.Tags(), []string{"revisada"}) {
+			t.Fatalf("update not persisted: %+v", got)
+		}
+		if got.UpdatedAt().Before(got.CreatedAt()) {
+			t.Fatalf("updated_at before created_at")
+		}
+		_, err = r.GetByISBN(ctx(), validISBNs[0])
+		wantErr(t, err, domain.ErrNotFound, "old isbn")
+
+		_ = got.SetISBN(validISBNs[1])
+		wantErr(t, r.Update(ctx(), got), domain.ErrDuplicate, "update duplicate")
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		r := newRepos(t).Books
+		id := mustBook(t, r, "Uno", "Autor", validISBNs[0], "General")
+
+		wantNoErr(t, r.Delete(ctx(), id), "delete")
+		_, err := r.GetByID(ctx(), id)
+		wantErr(t, err, domain.ErrNotFound, "get deleted")
+
+		// El ISBN queda libre
+		mustBook(t, r, "Uno", "Autor", validISBNs[0], "General")
+	})
+}
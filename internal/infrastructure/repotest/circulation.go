@@ -0,0 +1,451 @@
+package repotest
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/usecase"
+)
+
+// RunCirculationRepo verifica el contrato de usecase.CirculationRepo.
+func RunCirculationRepo(t *testing.T, newRepos Factory) {
+	if newRepos(t).Circulation == nil {
+		t.Skip("backend without CirculationRepo")
+	}
+
+	t.Run("Copies", func(t *testing.T) {
+		repos := newRepos(t)
+		r := repos.Circulation
+		book := mustBook(t, repos.Books, "Uno", "Ana", validISBNs[0], "Novela")
+		b := mustCopy(t, r, book, "b-002")
+		a := mustCopy(t, r, book, "A-001")
+
+		c, err := domain.NewCopy(book, "b-002", "")
+		wantNoErr(t, err, "new copy")
+		_, err = r.CreateCopy(ctx(), c)
+		wantErr(t, err, domain.ErrDuplicate, "duplicate barcode")
+
+		got, err := r.GetCopyByBarcode(ctx(), "B-002")
+		wantNoErr(t, err, "get by barcode")
+		if got.ID() != b || got.BookID() != book || got.Status() != domain.CopyAvailable || got.CreatedAt().IsZero() {
+			t.Fatalf("unexpected copy %+v", got)
+		}
+
+		list, err := r.CopiesByBook(ctx(), book)
+		wantNoErr(t, err, "copies by book")
+		if want := []uint64{a, b}; !reflect.DeepEqual(ids(list), want) {
+			t.Fatalf("by barcode: expected %v, got %v", want, ids(list))
+		}
+
+		wantNoErr(t, got.SetBarcode("B-003"), "set barcode")
+		wantNoErr(t, got.SetLocation("Sala 2"), "set location")
+		wantNoErr(t, got.SetStatus(domain.CopyMaintenance), "set status")
+		wantNoErr(t, r.UpdateCopy(ctx(), got, domain.CopyAvailable), "update")
+		got, _ = r.GetCopy(ctx(), b)
+		if got.Barcode() != "B-003" || got.Location() != "Sala 2" || got.Status() != domain.CopyMaintenance {
+			t.Fatalf("update not persisted: %+v", got)
+		}
+		wantNoErr(t, got.SetBarcode("A-001"), "set barcode")
+		wantErr(t, r.UpdateCopy(ctx(), got, domain.CopyMaintenance), domain.ErrDuplicate, "update to existing barcode")
+
+		wantNoErr(t, r.DeleteCopy(ctx(), a), "delete")
+		_, err = r.GetCopy(ctx(), a)
+		wantErr(t, err, domain.ErrNotFound, "get deleted")
+		_, err = r.GetCopyByBarcode(ctx(), "NADA")
+		wantErr(t, err, domain.ErrNotFound, "get missing barcode")
+		wantErr(t, r.DeleteCopy(ctx(), 999999), domain.ErrNotFound, "delete missing")
+	})
+
+	t.Run("CheckoutRenewReturn", func(t *testing.T) {
+		repos := newRepos(t)
+		r := repos.Circulation
+		book := mustBook(t, repos.Books, "Uno", "Ana", validISBNs[0], "Novela")
+		uid := mustUser(t, repos.Users, "Ana", "ana@x.com", domain.RoleReader)
+		u, _ := repos.Users.GetByID(ctx(), uid)
+		cid := mustCopy(t, r, book, "C-1")
+		c, _ := r.GetCopy(ctx(), cid)
+
+		now := time.Now().UTC().Truncate(time.Second)
+		l, err := domain.NewLoan(c, u, now)
+		wantNoErr(t, err, "new loan")
+		id, err := r.Checkout(ctx(), l, 3)
+		wantNoErr(t, err, "checkout")
+
+		// El ejemplar queda prestado: un segundo préstamo o un cambio de estado fallan
+		_, err = r.Checkout(ctx(), l, 3)
+		wantErr(t, err, domain.ErrUnavailable, "checkout twice")
+		c, _ = r.GetCopy(ctx(), cid)
+		if c.Status() != domain.CopyOnLoan {
+			t.Fatalf("expected copy on loan, got %s", c.Status())
+		}
+		stale := domain.HydrateCopy(cid, book, domain.CopyPhysical, "C-1", domain.CopyMaintenance, "", c.CreatedAt(), c.UpdatedAt())
+		wantErr(t, r.UpdateCopy(ctx(), stale, domain.CopyAvailable), domain.ErrUnavailable, "update copy on loan")
+
+		active, err := r.ActiveLoan(ctx(), cid)
+		wantNoErr(t, err, "active loan")
+		if active.ID() != id || active.UserID() != uid || active.BookID() != book || !sameInstant(active.DueAt(), now.AddDate(0, 0, 14)) {
+			t.Fatalf("unexpected loan %+v", active)
+		}
+
+		wantNoErr(t, active.Renew(u.Role().LoanPolicy(), now), "renew")
+		wantNoErr(t, r.RenewLoan(ctx(), active), "save renew")
+		got, _ := r.GetLoan(ctx(), id)
+		if got.Renewals() != 1 || !sameInstant(got.DueAt(), now.AddDate(0, 0, 28)) {
+			t.Fatalf("renew not persisted: %+v", got)
+		}
+
+		wantNoErr(t, got.Return(now.Add(time.Hour)), "return")
+		wantNoErr(t, r.ReturnLoan(ctx(), got), "save return")
+		got, _ = r.GetLoan(ctx(), id)
+		if got.Active() || !sameInstant(got.ReturnedAt(), now.Add(time.Hour)) {
+			t.Fatalf("return not persisted: %+v", got)
+		}
+		c, _ = r.GetCopy(ctx(), cid)
+		if !c.Available() {
+			t.Fatalf("expected copy available after return, got %s", c.Status())
+		}
+
+		// Una edición leída con el préstamo en curso no pisa la devolución
+		stale = domain.HydrateCopy(cid, book, domain.CopyPhysical, "C-1", domain.CopyOnLoan, "Sala 9", c.CreatedAt(), c.UpdatedAt())
+		wantErr(t, r.UpdateCopy(ctx(), stale, domain.CopyOnLoan), domain.ErrUnavailable, "stale update after return")
+		if c, _ = r.GetCopy(ctx(), cid); !c.Available() || c.Location() != "" {
+			t.Fatalf("stale update overwrote the return: %+v", c)
+		}
+		_, err = r.ActiveLoan(ctx(), cid)
+		wantErr(t, err, domain.ErrNotFound, "no active loan")
+		wantErr(t, r.ReturnLoan(ctx(), got), domain.ErrValidation, "return twice")
+		wantErr(t, r.RenewLoan(ctx(), got), domain.ErrValidation, "renew returned")
+		_, err = r.GetLoan(ctx(), 999999)
+		wantErr(t, err, domain.ErrNotFound, "get missing loan")
+	})
+
+	t.Run("CheckoutLimit", func(t *testing.T) {
+		repos := newRepos(t)
+		r := repos.Circulation
+		book := mustBook(t, repos.Books, "Uno", "Ana", validISBNs[0], "Novela")
+		uid := mustUser(t, repos.Users, "Ana", "ana@x.com", domain.RoleReader)
+		u, _ := repos.Users.GetByID(ctx(), uid)
+
+		for i, code := range []string{"L-1", "L-2", "L-3"} {
+			c, _ := r.GetCopy(ctx(), mustCopy(t, r, book, code))
+			l, err := domain.NewLoan(c, u, time.Now())
+			wantNoErr(t, err, "new loan")
+			_, err = r.Checkout(ctx(), l, 2)
+			if i < 2 {
+				wantNoErr(t, err, "checkout "+code)
+			} else {
+				wantErr(t, err, domain.ErrLimitReached, "checkout over limit")
+			}
+		}
+		// El intento rechazado no deja el ejemplar prestado
+		c, _ := r.GetCopyByBarcode(ctx(), "L-3")
+		if !c.Available() {
+			t.Fatalf("expected rejected copy to stay available, got %s", c.Status())
+		}
+	})
+
+	t.Run("ListLoans", func(t *testing.T) {
+		repos := newRepos(t)
+		r := repos.Circulation
+		b1 := mustBook(t, repos.Books, "Uno", "Ana", validISBNs[0], "Novela")
+		b2 := mustBook(t, repos.Books, "Dos", "Eva", validISBNs[1], "Novela")
+		ana := mustUser(t, repos.Users, "Ana", "ana@x.com", domain.RoleReader)
+		leo := mustUser(t, repos.Users, "Leo", "leo@x.com", domain.RoleReader)
+
+		now := time.Now().UTC().Truncate(time.Second)
+		old := mustLoan(t, repos, mustCopy(t, r, b1, "X-1"), ana, now.AddDate(0, 0, -20)) // vencido
+		cur := mustLoan(t, repos, mustCopy(t, r, b2, "X-2"), ana, now)
+		ret := mustLoan(t, repos, mustCopy(t, r, b1, "X-3"), leo, now)
+		l, _ := r.GetLoan(ctx(), ret)
+		wantNoErr(t, l.Return(now), "return")
+		wantNoErr(t, r.ReturnLoan(ctx(), l), "save return")
+
+		cases := []struct {
+			name string
+			f    domain.LoanFilter
+			want []uint64
+		}{
+			{"all", domain.LoanFilter{}, []uint64{ret, cur, old}},
+			{"user", domain.LoanFilter{UserID: ana}, []uint64{cur, old}},
+			{"book", domain.LoanFilter{BookID: b1}, []uint64{ret, old}},
+			{"active", domain.LoanFilter{Status: domain.LoanStatusActive}, []uint64{cur, old}},
+			{"overdue", domain.LoanFilter{Status: domain.LoanStatusOverdue, Now: now}, []uint64{old}},
+			{"returned", domain.LoanFilter{Status: domain.LoanStatusReturned}, []uint64{ret}},
+			{"due", domain.LoanFilter{PageRequest: domain.PageRequest{Sort: "due_at"}}, []uint64{old, cur, ret}},
+		}
+		for _, tc := range cases {
+			page, err := r.ListLoans(ctx(), tc.f)
+			wantNoErr(t, err, tc.name)
+			got := make([]uint64, 0, len(page.Items))
+			for _, d := range page.Items {
+				got = append(got, d.Loan.ID())
+			}
+			if page.Total != len(tc.want) || !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("%s: expected %v, got %v (total %d)", tc.name, tc.want, got, page.Total)
+			}
+		}
+
+		page, _ := r.ListLoans(ctx(), domain.LoanFilter{UserID: leo})
+		if d := page.Items[0]; d.Barcode != "X-3" || d.BookTitle != "Uno" || d.UserName != "Leo" {
+			t.Fatalf("unexpected detail %+v", d)
+		}
+		_, err := r.ListLoans(ctx(), domain.LoanFilter{Status: "perdido"})
+		wantErr(t, err, domain.ErrValidation, "invalid status")
+	})
+
+	t.Run("Holds", func(t *testing.T) {
+		repos := newRepos(t)
+		r := repos.Circulation
+		book := mustBook(t, repos.Books, "Uno", "Ana", validISBNs[0], "Novela")
+		ana := mustUser(t, repos.Users, "Ana", "ana@x.com", domain.RoleReader)
+		leo := mustUser(t, repos.Users, "Leo", "leo@x.com", domain.RoleReader)
+		eva := mustUser(t, repos.Users, "Eva", "eva@x.com", domain.RoleReader)
+		cid := mustCopy(t, r, book, "H-1")
+		loan := mustLoan(t, repos, cid, ana, time.Now())
+
+		now := time.Now().UTC().Truncate(time.Second)
+		first := mustHold(t, repos, book, leo, now)
+		second := mustHold(t, repos, book, eva, now)
+		_, err := r.PlaceHold(ctx(), holdFor(t, repos, book, leo, now), 3)
+		wantErr(t, err, domain.ErrDuplicate, "second hold on the same book")
+
+		// Con la cola esperando el préstamo no se renueva
+		renew, _ := r.GetLoan(ctx(), loan)
+		borrower, _ := repos.Users.GetByID(ctx(), ana)
+		wantNoErr(t, renew.Renew(borrower.Role().LoanPolicy(), now), "renew")
+		wantErr(t, r.RenewLoan(ctx(), renew), domain.ErrLimitReached, "renew with waiting holds")
+		if got, _ := r.GetLoan(ctx(), loan); got.Renewals() != 0 {
+			t.Fatalf("renew with waiting holds was persisted: %+v", got)
+		}
+
+		a, err := r.Availability(ctx(), book)
+		wantNoErr(t, err, "availability")
+		if a != (domain.Availability{Copies: 1, OnLoan: 1, Waiting: 2}) {
+			t.Fatalf("unexpected availability %+v", a)
+		}
+		page, err := r.ListHolds(ctx(), domain.HoldFilter{BookID: book, PageRequest: domain.PageRequest{Dir: domain.SortAsc}})
+		wantNoErr(t, err, "list holds")
+		if page.Total != 2 || page.Items[0].Position != 1 || page.Items[1].Position != 2 || page.Items[1].UserName != "Eva" {
+			t.Fatalf("unexpected queue %+v", page.Items)
+		}
+
+		// La devolución aparta el ejemplar para la primera de la cola (FIFO)
+		l, _ := r.GetLoan(ctx(), loan)
+		wantNoErr(t, l.Return(now), "return")
+		wantNoErr(t, r.ReturnLoan(ctx(), l), "save return")
+		c, _ := r.GetCopy(ctx(), cid)
+		h, _ := r.GetHold(ctx(), first)
+		if c.Status() != domain.CopyOnHold || h.Status() != domain.HoldReady || h.CopyID() != cid || !sameInstant(h.ExpiresAt(), now.AddDate(0, 0, domain.HoldPickupDays)) {
+			t.Fatalf("expected copy on hold for first hold, got %s / %+v", c.Status(), h)
+		}
+		ready, err := r.ReadyHold(ctx(), cid)
+		wantNoErr(t, err, "ready hold")
+		if ready.ID() != first {
+			t.Fatalf("expected ready hold %d, got %d", first, ready.ID())
+		}
+		stale := domain.HydrateCopy(cid, book, domain.CopyPhysical, "H-1", domain.CopyMaintenance, "", c.CreatedAt(), c.UpdatedAt())
+		wantErr(t, r.UpdateCopy(ctx(), stale, domain.CopyAvailable), domain.ErrUnavailable, "update copy on hold")
+
+		// Otro usuario no puede llevarse el ejemplar apartado
+		u, _ := repos.Users.GetByID(ctx(), eva)
+		other := domain.HydrateLoan(0, cid, book, eva, now, now.AddDate(0, 0, 14), time.Time{}, 0)
+		_, err = r.Checkout(ctx(), other, 3)
+		wantErr(t, err, domain.ErrUnavailable, "checkout copy held for another user")
+
+		// Si no se retira a tiempo vence y pasa a la siguiente
+		n, err := r.ExpireHolds(ctx(), now.AddDate(0, 0, domain.HoldPickupDays).Add(time.Minute))
+		wantNoErr(t, err, "expire")
+		if n != 1 {
+			t.Fatalf("expected 1 expired hold, got %d", n)
+		}
+		h, _ = r.GetHold(ctx(), first)
+		if h.Status() != domain.HoldExpired || h.ClosedAt().IsZero() {
+			t.Fatalf("expected expired hold, got %+v", h)
+		}
+		h, _ = r.GetHold(ctx(), second)
+		if h.Status() != domain.HoldReady || h.CopyID() != cid {
+			t.Fatalf("expected second hold ready, got %+v", h)
+		}
+
+		// Retirarlo lo presta y cumple la reserva
+		c, _ = r.GetCopy(ctx(), cid)
+		l, err = domain.NewHoldLoan(c, h, u, now)
+		wantNoErr(t, err, "new hold loan")
+		_, err = r.Checkout(ctx(), l, 3)
+		wantNoErr(t, err, "checkout held copy")
+		h, _ = r.GetHold(ctx(), second)
+		c, _ = r.GetCopy(ctx(), cid)
+		if h.Status() != domain.HoldFulfilled || c.Status() != domain.CopyOnLoan {
+			t.Fatalf("expected fulfilled hold and copy on loan, got %s / %s", h.Status(), c.Status())
+		}
+
+		// Cancelar una reserva lista libera el ejemplar
+		third := mustHold(t, repos, book, ana, now)
+		c2 := mustCopy(t, r, book, "H-2")
+		h, _ = r.GetHold(ctx(), third)
+		if h.Status() != domain.HoldReady || h.CopyID() != c2 {
+			t.Fatalf("expected new copy to serve the queue, got %+v", h)
+		}
+		wantNoErr(t, h.Cancel(now), "cancel")
+		wantNoErr(t, r.CancelHold(ctx(), h), "save cancel")
+		wantErr(t, r.CancelHold(ctx(), h), domain.ErrValidation, "cancel twice")
+		c, _ = r.GetCopy(ctx(), c2)
+		if !c.Available() {
+			t.Fatalf("expected copy available after cancel, got %s", c.Status())
+		}
+
+		page, _ = r.ListHolds(ctx(), domain.HoldFilter{Status: domain.HoldStatusActive})
+		if page.Total != 0 {
+			t.Fatalf("expected no active holds, got %d", page.Total)
+		}
+		_, err = r.GetHold(ctx(), 999999)
+		wantErr(t, err, domain.ErrNotFound, "get missing hold")
+		_, err = r.Availability(ctx(), 999999)
+		wantErr(t, err, domain.ErrNotFound, "availability of missing book")
+		_, err = r.ListHolds(ctx(), domain.HoldFilter{Status: "perdida"})
+		wantErr(t, err, domain.ErrValidation, "invalid status")
+	})
+
+	t.Run("HoldLimit", func(t *testing.T) {
+		repos := newRepos(t)
+		ana := mustUser(t, repos.Users, "Ana", "ana@x.com", domain.RoleReader)
+		b1 := mustBook(t, repos.Books, "Uno", "Ana", validISBNs[0], "Novela")
+		b2 := mustBook(t, repos.Books, "Dos", "Eva", validISBNs[1], "Novela")
+
+		_, err := repos.Circulation.PlaceHold(ctx(), holdFor(t, repos, b1, ana, time.Now()), 1)
+		wantNoErr(t, err, "first hold")
+		_, err = repos.Circulation.PlaceHold(ctx(), holdFor(t, repos, b2, ana, time.Now()), 1)
+		wantErr(t, err, domain.ErrLimitReached, "hold over limit")
+	})
+
+	t.Run("DigitalSeats", func(t *testing.T) {
+		repos := newRepos(t)
+		r := repos.Circulation
+		book := mustBook(t, repos.Books, "Uno", "Ana", validISBNs[0], "Novela")
+		ana := mustUser(t, repos.Users, "Ana", "ana@x.com", domain.RoleReader)
+		leo := mustUser(t, repos.Users, "Leo", "leo@x.com", domain.RoleReader)
+		mustCopy(t, r, book, "F-1")
+
+		seat, err := domain.NewSeat(book, 1)
+		wantNoErr(t, err, "new seat")
+		sid, err := r.CreateCopy(ctx(), seat)
+		wantNoErr(t, err, "create seat")
+		got, err := r.GetCopy(ctx(), sid)
+		wantNoErr(t, err, "get seat")
+		if !got.Digital() || got.Barcode() != fmt.Sprintf("SEAT-%d-1", book) || got.Location() != "" {
+			t.Fatalf("unexpected seat %+v", got)
+		}
+		a, err := r.Availability(ctx(), book)
+		wantNoErr(t, err, "availability")
+		if a != (domain.Availability{Copies: 1, Seats: 1, Available: 2}) {
+			t.Fatalf("unexpected availability %+v", a)
+		}
+
+		// Con el puesto prestado, la reserva de Leo se aparta en el ejemplar
+		// físico; la de Eva espera y la atiende el puesto al devolverse
+		now := time.Now().UTC().Truncate(time.Second)
+		loan := mustLoan(t, repos, sid, ana, now)
+		first := mustHold(t, repos, book, leo, now)
+		h, _ := r.GetHold(ctx(), first)
+		if h.Status() != domain.HoldReady || h.CopyID() == sid {
+			t.Fatalf("expected hold served by the physical copy, got %+v", h)
+		}
+		page, err := r.ListLoans(ctx(), domain.LoanFilter{Digital: true})
+		wantNoErr(t, err, "list digital loans")
+		if page.Total != 1 || page.Items[0].Loan.ID() != loan || !page.Items[0].Digital {
+			t.Fatalf("unexpected digital loans %+v", page.Items)
+		}
+
+		eva := mustUser(t, repos.Users, "Eva", "eva@x.com", domain.RoleReader)
+		second := mustHold(t, repos, book, eva, now)
+		l, _ := r.GetLoan(ctx(), loan)
+		wantNoErr(t, l.Return(now), "return seat")
+		wantNoErr(t, r.ReturnLoan(ctx(), l), "save seat return")
+		holds, err := r.ListHolds(ctx(), domain.HoldFilter{BookID: book, Status: string(domain.HoldReady), PageRequest: domain.PageRequest{Dir: domain.SortAsc}})
+		wantNoErr(t, err, "list ready holds")
+		if holds.Total != 2 || holds.Items[1].Hold.ID() != second || holds.Items[1].Hold.CopyID() != sid || !holds.Items[1].Digital || holds.Items[0].Digital {
+			t.Fatalf("expected the freed seat held for the second hold, got %+v", holds.Items)
+		}
+	})
+
+	t.Run("CascadeOnDelete", func(t *testing.T) {
+		repos := newRepos(t)
+		r := repos.Circulation
+		book := mustBook(t, repos.Books, "Uno", "Ana", validISBNs[0], "Novela")
+		uid := mustUser(t, repos.Users, "Ana", "ana@x.com", domain.RoleReader)
+		cid := mustCopy(t, r, book, "D-1")
+		id := mustLoan(t, repos, cid, uid, time.Now())
+		hid := mustHold(t, repos, book, mustUser(t, repos.Users, "Leo", "leo@x.com", domain.RoleReader), time.Now())
+
+		wantNoErr(t, repos.Books.Delete(ctx(), book), "delete book")
+		_, err := r.GetCopy(ctx(), cid)
+		wantErr(t, err, domain.ErrNotFound, "copy after book delete")
+		_, err = r.GetLoan(ctx(), id)
+		wantErr(t, err, domain.ErrNotFound, "loan after book delete")
+		_, err = r.GetHold(ctx(), hid)
+		wantErr(t, err, domain.ErrNotFound, "hold after book delete")
+	})
+}
+
+func mustCopy(t *testing.T, r usecase.CirculationRepo, bookID uint64, barcode string) uint64 {
+	t.Helper()
+	c, err := domain.NewCopy(bookID, barcode, "")
+	if err != nil {
+		t.Fatalf("new copy: %v", err)
+	}
+	id, err := r.CreateCopy(ctx(), c)
+	if err != nil {
+		t.Fatalf("create copy %s: %v", barcode, err)
+	}
+	return id
+}
+
+func mustLoan(t *testing.T, repos Repos, copyID, userID uint64, at time.Time) uint64 {
+	t.Helper()
+	c, err := repos.Circulation.GetCopy(ctx(), copyID)
+	if err != nil {
+		t.Fatalf("get copy %d: %v", copyID, err)
+	}
+	u, err := repos.Users.GetByID(ctx(), userID)
+	if err != nil {
+		t.Fatalf("get user %d: %v", userID, err)
+	}
+	l, err := domain.NewLoan(c, u, at)
+	if err != nil {
+		t.Fatalf("new loan: %v", err)
+	}
+	id, err := repos.Circulation.Checkout(ctx(), l, 10)
+	if err != nil {
+		t.Fatalf("checkout copy %d: %v", copyID, err)
+	}
+	return id
+}
+
+func holdFor(t *testing.T, repos Repos, bookID, userID uint64, at time.Time) *domain.Hold {
+	t.Helper()
+	b, err := repos.Books.GetByID(ctx(), bookID)
+	if err != nil {
+		t.Fatalf("get book %d: %v", bookID, err)
+	}
+	u, err := repos.Users.GetByID(ctx(), userID)
+	if err != nil {
+		t.Fatalf("get user %d: %v", userID, err)
+	}
+	h, err := domain.NewHold(b, u, at)
+	if err != nil {
+		t.Fatalf("new hold: %v", err)
+	}
+	return h
+}
+
+func mustHold(t *testing.T, repos Repos, bookID, userID uint64, at time.Time) uint64 {
+	t.Helper()
+	id, err := repos.Circulation.PlaceHold(ctx(), holdFor(t, repos, bookID, userID, at), 10)
+	if err != nil {
+		t.Fatalf("place hold: %v", err)
+	}
+	return id
+}
@@ -0,0 +1,83 @@
+package repotest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+// RunCoverRepo verifica el contrato de usecase.CoverRepo.
+func RunCoverRepo(t *testing.T, newRepos Factory) {
+	if newRepos(t).Covers == nil {
+		t.Skip("backend without CoverRepo")
+	}
+
+	t.Run("PutReplaceAndDelete", func(t *testing.T) {
+		repos := newRepos(t)
+		r := repos.Covers
+		uno := mustBook(t, repos.Books, "Uno", "Ana", validISBNs[0], "Novela")
+		dos := mustBook(t, repos.Books, "Dos", "Ana", validISBNs[1], "Novela")
+		tres := mustBook(t, repos.Books, "Tres", "Ana", validISBNs[2], "Novela")
+
+		shaA, shaB := strings.Repeat("a", 64), strings.Repeat("b", 64)
+		_, err := r.Get(ctx(), uno)
+		wantErr(t, err, domain.ErrNotFound, "get without cover")
+		wantErr(t, r.Put(ctx(), newCover(t, 999999, shaA)), domain.ErrNotFound, "cover of missing book")
+
+		wantNoErr(t, r.Put(ctx(), newCover(t, uno, shaA)), "put")
+		wantNoErr(t, r.Put(ctx(), newCover(t, dos, shaA)), "put same image in another book")
+		got, err := r.Get(ctx(), uno)
+		wantNoErr(t, err, "get")
+		if got.SHA256() != shaA || got.ContentType() != "image/png" || got.Width() != 600 ||
+			got.Height() != 900 || got.Size() != 4321 || got.UpdatedAt().IsZero() {
+			t.Fatalf("unexpected cover %+v", got)
+		}
+
+		// Reemplazar no deja dos portadas
+		wantNoErr(t, r.Put(ctx(), newCover(t, uno, shaB)), "replace")
+		if got, _ := r.Get(ctx(), uno); got.SHA256() != shaB {
+			t.Fatalf("expected replaced cover, got %s", got.SHA256())
+		}
+		if n, _ := r.CountBySHA(ctx(), shaA); n != 1 {
+			t.Fatalf("expected 1 cover with the old image, got %d", n)
+		}
+
+		all, err := r.ByBooks(ctx(), []uint64{uno, dos, tres, 999999})
+		wantNoErr(t, err, "by books")
+		if len(all) != 2 || all[uno].SHA256() != shaB || all[dos].SHA256() != shaA {
+			t.Fatalf("unexpected covers %v", all)
+		}
+		if none, err := r.ByBooks(ctx(), nil); err != nil || len(none) != 0 {
+			t.Fatalf("expected no covers for no books, got %v, %v", none, err)
+		}
+
+		wantNoErr(t, r.Delete(ctx(), uno), "delete")
+		_, err = r.Get(ctx(), uno)
+		wantErr(t, err, domain.ErrNotFound, "get deleted")
+		wantErr(t, r.Delete(ctx(), uno), domain.ErrNotFound, "delete missing")
+	})
+
+	t.Run("CascadeOnDelete", func(t *testing.T) {
+		repos := newRepos(t)
+		uno := mustBook(t, repos.Books, "Uno", "Ana", validISBNs[0], "Novela")
+		sha := strings.Repeat("c", 64)
+		wantNoErr(t, repos.Covers.Put(ctx(), newCover(t, uno, sha)), "put")
+
+		wantNoErr(t, repos.Books.Delete(ctx(), uno), "delete book")
+		_, err := repos.Covers.Get(ctx(), uno)
+		wantErr(t, err, domain.ErrNotFound, "cover after book delete")
+		if n, _ := repos.Covers.CountBySHA(ctx(), sha); n != 0 {
+			t.Fatalf("expected no covers after book delete, got %d", n)
+		}
+	})
+}
+
+func newCover(t *testing.T, bookID uint64, sha string) *domain.Cover {
+	t.Helper()
+	c, err := domain.NewCover(bookID, sha, "image/png", 600, 900, 4321)
+	if err != nil {
+		t.Fatalf("new cover: %v", err)
+	}
+	return c
+}
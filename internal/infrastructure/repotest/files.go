@@ -0,0 +1,104 @@
+package repotest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/usecase"
+)
+
+// RunFileRepo verifica el contrato de usecase.FileRepo.
+func RunFileRepo(t *testing.T, newRepos Factory) {
+	if newRepos(t).Files == nil {
+		t.Skip("backend without FileRepo")
+	}
+
+	t.Run("CreateAndList", func(t *testing.T) {
+		repos := newRepos(t)
+		r := repos.Files
+		ana := mustUser(t, repos.Users, "Ana", "ana@x.com", domain.RoleAdmin)
+		uno := mustBook(t, repos.Books, "Uno", "Ana", validISBNs[0], "Novela")
+		dos := mustBook(t, repos.Books, "Dos", "Ana", validISBNs[1], "Novela")
+
+		shaA, shaB := strings.Repeat("a", 64), strings.Repeat("b", 64)
+		audio := mustFile(t, r, uno, "capitulo1.mp3", domain.FileKindAudio, shaB, ana)
+		pdf := mustFile(t, r, uno, "Uno.pdf", domain.FileKindPDF, shaA, ana)
+		mustFile(t, r, dos, "copia.pdf", domain.FileKindPDF, shaA, 0) // mismo contenido en otro libro
+
+		_, err := r.Create(ctx(), newFile(t, uno, "otra.pdf", domain.FileKindPDF, shaA, ana))
+		wantErr(t, err, domain.ErrDuplicate, "same content twice in a book")
+		_, err = r.Create(ctx(), newFile(t, 999999, "x.pdf", domain.FileKindPDF, shaA, ana))
+		wantErr(t, err, domain.ErrNotFound, "file of missing book")
+
+		got, err := r.GetByID(ctx(), pdf)
+		wantNoErr(t, err, "get")
+		if got.BookID() != uno || got.Name() != "Uno.pdf" || got.ContentType() != "application/pdf" ||
+			got.Size() != 1234 || got.SHA256() != shaA || got.UploadedBy() != ana || got.CreatedAt().IsZero() {
+			t.Fatalf("unexpected file %+v", got)
+		}
+
+		// Por tipo (audio < pdf) y nombre
+		list, err := r.ByBook(ctx(), uno)
+		wantNoErr(t, err, "by book")
+		if got := ids(list); len(got) != 2 || got[0] != audio || got[1] != pdf {
+			t.Fatalf("unexpected order %v", got)
+		}
+
+		n, err := r.CountBySHA(ctx(), shaA)
+		wantNoErr(t, err, "count by sha")
+		if n != 2 {
+			t.Fatalf("expected 2 files with the same content, got %d", n)
+		}
+
+		wantNoErr(t, r.Delete(ctx(), pdf), "delete")
+		_, err = r.GetByID(ctx(), pdf)
+		wantErr(t, err, domain.ErrNotFound, "get deleted")
+		wantErr(t, r.Delete(ctx(), pdf), domain.ErrNotFound, "delete missing")
+		if n, _ := r.CountBySHA(ctx(), shaA); n != 1 {
+			t.Fatalf("expected 1 file after delete, got %d", n)
+		}
+	})
+
+	t.Run("CascadeOnDelete", func(t *testing.T) {
+		repos := newRepos(t)
+		ana := mustUser(t, repos.Users, "Ana", "ana@x.com", domain.RoleAdmin)
+		uno := mustBook(t, repos.Books, "Uno", "Ana", validISBNs[0], "Novela")
+		dos := mustBook(t, repos.Books, "Dos", "Ana", validISBNs[1], "Novela")
+		keep := mustFile(t, repos.Files, dos, "dos.epub", domain.FileKindEPUB, strings.Repeat("c", 64), ana)
+		gone := mustFile(t, repos.Files, uno, "uno.epub", domain.FileKindEPUB, strings.Repeat("d", 64), ana)
+
+		// Sin el usuario el adjunto queda sin autor
+		wantNoErr(t, repos.Users.Delete(ctx(), ana), "delete user")
+		got, err := repos.Files.GetByID(ctx(), keep)
+		wantNoErr(t, err, "get after user delete")
+		if got.UploadedBy() != 0 {
+			t.Fatalf("expected no uploader, got %d", got.UploadedBy())
+		}
+
+		wantNoErr(t, repos.Books.Delete(ctx(), uno), "delete book")
+		_, err = repos.Files.GetByID(ctx(), gone)
+		wantErr(t, err, domain.ErrNotFound, "file after book delete")
+	})
+}
+
+func newFile(t *testing.T, bookID uint64, name string, kind domain.FileKind, sha string, uploadedBy uint64) *domain.BookFile {
+	t.Helper()
+	types := map[domain.FileKind]string{
+		domain.FileKindPDF: "application/pdf", domain.FileKindEPUB: "application/epub+zip", domain.FileKindAudio: "audio/mpeg",
+	}
+	f, err := domain.NewBookFile(bookID, name, types[kind], kind, 1234, sha, uploadedBy)
+	if err != nil {
+		t.Fatalf("new file: %v", err)
+	}
+	return f
+}
+
+func mustFile(t *testing.T, r usecase.FileRepo, bookID uint64, name string, kind domain.FileKind, sha string, uploadedBy uint64) uint64 {
+	t.Helper()
+	id, err := r.Create(ctx(), newFile(t, bookID, name, kind, sha, uploadedBy))
+	if err != nil {
+		t.Fatalf("create file %s: %v", name, err)
+	}
+	return id
+}
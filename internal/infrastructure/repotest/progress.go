@@ -0,0 +1,155 @@
+package repotest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+// RunProgressRepo verifica el contrato de usecase.ProgressRepo.
+func RunProgressRepo(t *testing.T, newRepos Factory) {
+	if newRepos(t).Progress == nil {
+		t.Skip("backend without ProgressRepo")
+	}
+
+	t.Run("SaveUpdateAndDelete", func(t *testing.T) {
+		repos := newRepos(t)
+		r := repos.Progress
+		ana := mustUser(t, repos.Users, "Ana", "ana@example.com", domain.RoleReader)
+		uno := mustBook(t, repos.Books, "Uno", "Ana", validISBNs[0], "Novela")
+
+		_, err := r.Get(ctx(), ana, uno)
+		wantErr(t, err, domain.ErrNotFound, "get without progress")
+		wantErr(t, r.Save(ctx(), newProgress(t, 999999, uno, 10, 100)), domain.ErrNotFound, "progress of missing user")
+		wantErr(t, r.Save(ctx(), newProgress(t, ana, 999999, 10, 100)), domain.ErrNotFound, "progress of missing book")
+
+		first := newProgress(t, ana, uno, 10, 200)
+		wantNoErr(t, r.Save(ctx(), first), "save")
+		got, err := r.Get(ctx(), ana, uno)
+		wantNoErr(t, err, "get")
+		if got.ID() == 0 || got.Page() != 10 || got.TotalPages() != 200 || got.Percent() != 5 ||
+			got.Finished() || !sameInstant(got.StartedAt(), first.StartedAt()) {
+			t.Fatalf("unexpected progress %+v", got)
+		}
+
+		// Guardar otra vez actualiza la misma fila y conserva el inicio
+		later := time.Now().Add(time.Hour)
+		next := domain.HydrateReadingProgress(0, ana, uno,
+			domain.ReadingPosition{Location: "epubcfi(/6/4!/4/2:0)", Percent: 100}, later, later, later)
+		wantNoErr(t, r.Save(ctx(), next), "update")
+		upd, err := r.Get(ctx(), ana, uno)
+		wantNoErr(t, err, "get updated")
+		if upd.ID() != got.ID() || upd.Location() != "epubcfi(/6/4!/4/2:0)" || upd.Percent() != 100 || !upd.Finished() ||
+			!sameInstant(upd.StartedAt(), first.StartedAt()) || !sameInstant(upd.LastReadAt(), later) {
+			t.Fatalf("unexpected updated progress %+v", upd)
+		}
+
+		wantNoErr(t, r.Delete(ctx(), ana, uno), "delete")
+		_, err = r.Get(ctx(), ana, uno)
+		wantErr(t, err, domain.ErrNotFound, "get deleted")
+		wantErr(t, r.Delete(ctx(), ana, uno), domain.ErrNotFound, "delete missing")
+	})
+
+	t.Run("ListAndCompletion", func(t *testing.T) {
+		repos := newRepos(t)
+		r := repos.Progress
+		ana := mustUser(t, repos.Users, "Ana", "ana@example.com", domain.RoleReader)
+		beto := mustUser(t, repos.Users, "Beto", "beto@example.com", domain.RoleReader)
+		uno := mustBook(t, repos.Books, "Uno", "Autora", validISBNs[0], "Novela")
+		dos := mustBook(t, repos.Books, "Dos", "Autor", validISBNs[1], "Novela")
+
+		base := time.Now().Add(-time.Hour)
+		save := func(user, book uint64, percent float64, minutes int, finished bool) {
+			t.Helper()
+			at := base.Add(time.Duration(minutes) * time.Minute)
+			var done time.Time
+			if finished {
+				done = at
+			}
+			p := domain.HydrateReadingProgress(0, user, book, domain.ReadingPosition{Percent: percent}, at, at, done)
+			wantNoErr(t, r.Save(ctx(), p), "save")
+		}
+		save(ana, uno, 100, 1, true)
+		save(ana, dos, 40, 3, false)
+		save(beto, uno, 20, 2, false)
+
+		// Por defecto lo leído más reciente primero
+		mine, err := r.List(ctx(), domain.ProgressFilter{UserID: ana})
+		wantNoErr(t, err, "list mine")
+		if mine.Total != 2 || mine.Items[0].Progress.BookID() != dos || mine.Items[1].Progress.BookID() != uno {
+			t.Fatalf("unexpected list %+v", mine)
+		}
+		if d := mine.Items[0]; d.BookTitle != "Dos" || d.BookAuthor != "Autor" || d.UserName != "Ana" {
+			t.Fatalf("unexpected detail %+v", d)
+		}
+
+		reading, err := r.List(ctx(), domain.ProgressFilter{UserID: ana, Status: domain.ProgressStatusReading})
+		wantNoErr(t, err, "list reading")
+		if reading.Total != 1 || reading.Items[0].Progress.BookID() != dos {
+			t.Fatalf("unexpected reading list %+v", reading)
+		}
+		finished, err := r.List(ctx(), domain.ProgressFilter{Status: domain.ProgressStatusFinished})
+		wantNoErr(t, err, "list finished")
+		if finished.Total != 1 || finished.Items[0].Progress.UserID() != ana {
+			t.Fatalf("unexpected finished list %+v", finished)
+		}
+		readers, err := r.List(ctx(), domain.ProgressFilter{BookID: uno,
+			PageRequest: domain.PageRequest{Sort: "percent", Dir: domain.SortAsc}})
+		wantNoErr(t, err, "list readers")
+		if readers.Total != 2 || readers.Items[0].UserName != "Beto" || readers.Items[1].UserName != "Ana" {
+			t.Fatalf("unexpected readers %+v", readers)
+		}
+		_, err = r.List(ctx(), domain.ProgressFilter{Status: "paused"})
+		wantErr(t, err, domain.ErrValidation, "invalid status")
+
+		all, err := r.Completion(ctx(), domain.CompletionFilter{})
+		wantNoErr(t, err, "completion")
+		if all.Total != 2 || len(all.Items) != 2 {
+			t.Fatalf("unexpected completion %+v", all)
+		}
+		c := all.Items[0] // más lectores primero
+		if c.BookID != uno || c.Title != "Uno" || c.Author != "Autora" || c.Readers != 2 || c.Finished != 1 ||
+			c.AvgPercent != 60 || c.Rate() != 50 {
+			t.Fatalf("unexpected completion for book %+v", c)
+		}
+		byRate, err := r.Completion(ctx(), domain.CompletionFilter{PageRequest: domain.PageRequest{Sort: "rate", Dir: domain.SortAsc}})
+		wantNoErr(t, err, "completion by rate")
+		if byRate.Items[0].BookID != dos || byRate.Items[0].Rate() != 0 {
+			t.Fatalf("unexpected completion order %+v", byRate.Items)
+		}
+		one, err := r.Completion(ctx(), domain.CompletionFilter{BookID: dos})
+		wantNoErr(t, err, "completion of a book")
+		if one.Total != 1 || one.Items[0].Readers != 1 || one.Items[0].AvgPercent != 40 {
+			t.Fatalf("unexpected completion of a book %+v", one)
+		}
+	})
+
+	t.Run("CascadeOnDelete", func(t *testing.T) {
+		repos := newRepos(t)
+		ana := mustUser(t, repos.Users, "Ana", "ana@example.com", domain.RoleReader)
+		beto := mustUser(t, repos.Users, "Beto", "beto@example.com", domain.RoleReader)
+		uno := mustBook(t, repos.Books, "Uno", "Ana", validISBNs[0], "Novela")
+		dos := mustBook(t, repos.Books, "Dos", "Ana", validISBNs[1], "Novela")
+		wantNoErr(t, repos.Progress.Save(ctx(), newProgress(t, ana, uno, 1, 10)), "save")
+		wantNoErr(t, repos.Progress.Save(ctx(), newProgress(t, ana, dos, 1, 10)), "save")
+		wantNoErr(t, repos.Progress.Save(ctx(), newProgress(t, beto, dos, 1, 10)), "save")
+
+		wantNoErr(t, repos.Books.Delete(ctx(), uno), "delete book")
+		wantNoErr(t, repos.Users.Delete(ctx(), beto), "delete user")
+		page, err := repos.Progress.List(ctx(), domain.ProgressFilter{})
+		wantNoErr(t, err, "list")
+		if page.Total != 1 || page.Items[0].Progress.UserID() != ana || page.Items[0].Progress.BookID() != dos {
+			t.Fatalf("expected only ana's progress in dos, got %+v", page)
+		}
+	})
+}
+
+func newProgress(t *testing.T, userID, bookID uint64, page, total int) *domain.ReadingProgress {
+	t.Helper()
+	p, err := domain.NewReadingProgress(userID, bookID, domain.ReadingPosition{Page: page, TotalPages: total})
+	if err != nil {
+		t.Fatalf("new progress: %v", err)
+	}
+	return p
+}
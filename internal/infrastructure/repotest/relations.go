@@ -0,0 +1,92 @@
+package repotest
+
+import (
+	"testing"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/usecase"
+)
+
+// RunRelationRepo verifica el contrato de usecase.RelationRepo.
+func RunRelationRepo(t *testing.T, newRepos Factory) {
+	if newRepos(t).Relations == nil {
+		t.Skip("backend without RelationRepo")
+	}
+
+	t.Run("CreateAndList", func(t *testing.T) {
+		repos := newRepos(t)
+		r := repos.Relations
+		uno := mustBook(t, repos.Books, "Uno", "Ana", validISBNs[0], "Novela")
+		dos := mustBook(t, repos.Books, "Dos", "Ana", validISBNs[1], "Novela")
+		guia := mustBook(t, repos.Books, "Guía", "Eva", validISBNs[2], "Novela")
+
+		sequel := mustRelation(t, r, dos, uno, domain.RelationSequel, true)
+		guide := mustRelation(t, r, guia, uno, domain.RelationTeacherGuide, false)
+
+		_, err := r.Create(ctx(), newRelation(t, dos, uno, domain.RelationSequel, false))
+		wantErr(t, err, domain.ErrDuplicate, "duplicate relation")
+		_, err = r.Create(ctx(), newRelation(t, dos, 999999, domain.RelationSequel, false))
+		wantErr(t, err, domain.ErrNotFound, "relation to missing book")
+
+		got, err := r.GetByID(ctx(), sequel)
+		wantNoErr(t, err, "get")
+		if got.FromID() != dos || got.ToID() != uno || got.Type() != domain.RelationSequel || !got.Bidirectional() || got.CreatedAt().IsZero() {
+			t.Fatalf("unexpected relation %+v", got)
+		}
+
+		// Desde "Uno" se ve la continuación (bidireccional) pero no la guía
+		list, err := r.ByBook(ctx(), uno)
+		wantNoErr(t, err, "by book")
+		if len(list) != 1 || list[0].Relation.ID() != sequel || !list[0].Inverse || list[0].BookID != dos || list[0].Title != "Dos" {
+			t.Fatalf("unexpected relations of target %+v", list)
+		}
+		list, _ = r.ByBook(ctx(), guia)
+		if len(list) != 1 || list[0].Relation.ID() != guide || list[0].Inverse || list[0].BookID != uno || list[0].Author != "Ana" {
+			t.Fatalf("unexpected relations of source %+v", list)
+		}
+
+		byType, err := r.ByType(ctx(), domain.RelationSequel)
+		wantNoErr(t, err, "by type")
+		if len(byType) != 1 || byType[0].ID() != sequel {
+			t.Fatalf("unexpected relations by type %+v", byType)
+		}
+
+		wantNoErr(t, r.Delete(ctx(), sequel), "delete")
+		_, err = r.GetByID(ctx(), sequel)
+		wantErr(t, err, domain.ErrNotFound, "get deleted")
+		wantErr(t, r.Delete(ctx(), sequel), domain.ErrNotFound, "delete missing")
+	})
+
+	t.Run("CascadeOnDelete", func(t *testing.T) {
+		repos := newRepos(t)
+		uno := mustBook(t, repos.Books, "Uno", "Ana", validISBNs[0], "Novela")
+		dos := mustBook(t, repos.Books, "Dos", "Ana", validISBNs[1], "Novela")
+		id := mustRelation(t, repos.Relations, dos, uno, domain.RelationAdaptation, true)
+
+		wantNoErr(t, repos.Books.Delete(ctx(), uno), "delete book")
+		_, err := repos.Relations.GetByID(ctx(), id)
+		wantErr(t, err, domain.ErrNotFound, "relation after book delete")
+		list, _ := repos.Relations.ByBook(ctx(), dos)
+		if len(list) != 0 {
+			t.Fatalf("expected no relations, got %+v", list)
+		}
+	})
+}
+
+func newRelation(t *testing.T, fromID, toID uint64, typ domain.RelationType, bidirectional bool) *domain.BookRelation {
+	t.Helper()
+	rel, err := domain.NewBookRelation(fromID, toID, typ, bidirectional)
+	if err != nil {
+		t.Fatalf("new relation: %v", err)
+	}
+	return rel
+}
+
+func mustRelation(t *testing.T, r usecase.RelationRepo, fromID, toID uint64, typ domain.RelationType, bidirectional bool) uint64 {
+	t.Helper()
+	id, err := r.Create(ctx(), newRelation(t, fromID, toID, typ, bidirectional))
+	if err != nil {
+		t.Fatalf("create relation: %v", err)
+	}
+	return id
+}
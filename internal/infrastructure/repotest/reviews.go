@@ -0,0 +1,238 @@
+package repotest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/usecase"
+)
+
+// RunReviewRepo verifica el contrato de usecase.ReviewRepo y la búsqueda
+// de libros por valoración.
+func RunReviewRepo(t *testing.T, newRepos Factory) {
+	if newRepos(t).Reviews == nil {
+		t.Skip("backend without ReviewRepo")
+	}
+
+	t.Run("CreateUpdateAndHistory", func(t *testing.T) {
+		repos := newRepos(t)
+		r := repos.Reviews
+		ana := mustUser(t, repos.Users, "Ana", "ana@example.com", domain.RoleReader)
+		uno := mustBook(t, repos.Books, "Uno", "Ana", validISBNs[0], "Novela")
+
+		_, err := r.Create(ctx(), newReview(t, 999999, uno, 4, ""))
+		wantErr(t, err, domain.ErrNotFound, "review of missing user")
+		_, err = r.Create(ctx(), newReview(t, ana, 999999, 4, ""))
+		wantErr(t, err, domain.ErrNotFound, "review of missing book")
+
+		rv := newReview(t, ana, uno, 4, "Muy bueno")
+		id, err := r.Create(ctx(), rv)
+		wantNoErr(t, err, "create")
+		_, err = r.Create(ctx(), newReview(t, ana, uno, 5, ""))
+		wantErr(t, err, domain.ErrDuplicate, "second review of the same book")
+
+		got, err := r.GetByID(ctx(), id)
+		wantNoErr(t, err, "get")
+		if got.ID() != id || got.UserID() != ana || got.BookID() != uno || got.Rating() != 4 || got.Text() != "Muy bueno" ||
+			got.Status() != domain.ReviewPending || !got.ModeratedAt().IsZero() || !sameInstant(got.CreatedAt(), rv.CreatedAt()) {
+			t.Fatalf("unexpected review %+v", got)
+		}
+		mine, err := r.GetByUserBook(ctx(), ana, uno)
+		wantNoErr(t, err, "get by user and book")
+		if mine.ID() != id {
+			t.Fatalf("unexpected review %+v", mine)
+		}
+
+		// Moderar no agrega historial; editar guarda la versión anterior
+		wantNoErr(t, got.Moderate(domain.ReviewApproved), "approve")
+		wantNoErr(t, r.Update(ctx(), got, nil), "update moderation")
+		approved, err := r.GetByID(ctx(), id)
+		wantNoErr(t, err, "get approved")
+		if !approved.Approved() || !sameInstant(approved.ModeratedAt(), got.ModeratedAt()) {
+			t.Fatalf("unexpected approved review %+v", approved)
+		}
+		prev, err := approved.Edit(5, "Mejor de lo que pensaba")
+		wantNoErr(t, err, "edit")
+		wantNoErr(t, r.Update(ctx(), approved, prev), "update edit")
+		edited, err := r.GetByID(ctx(), id)
+		wantNoErr(t, err, "get edited")
+		if edited.Rating() != 5 || edited.Status() != domain.ReviewPending || edited.ModeratedAt().IsZero() ||
+			!sameInstant(edited.CreatedAt(), rv.CreatedAt()) {
+			t.Fatalf("unexpected edited review %+v", edited)
+		}
+		hist, err := r.History(ctx(), id)
+		wantNoErr(t, err, "history")
+		if len(hist) != 1 || hist[0].Rating != 4 || hist[0].Text != "Muy bueno" || !sameInstant(hist[0].WrittenAt, rv.UpdatedAt()) {
+			t.Fatalf("unexpected history %+v", hist)
+		}
+
+		wantNoErr(t, r.Delete(ctx(), id), "delete")
+		_, err = r.GetByID(ctx(), id)
+		wantErr(t, err, domain.ErrNotFound, "get deleted")
+		_, err = r.GetByUserBook(ctx(), ana, uno)
+		wantErr(t, err, domain.ErrNotFound, "get deleted by user and book")
+		wantErr(t, r.Delete(ctx(), id), domain.ErrNotFound, "delete missing")
+		wantErr(t, r.Update(ctx(), edited, nil), domain.ErrNotFound, "update missing")
+		if hist, err := r.History(ctx(), id); err != nil || len(hist) != 0 {
+			t.Fatalf("expected history deleted, got %+v, %v", hist, err)
+		}
+	})
+
+	t.Run("ListFiltersAndSummaries", func(t *testing.T) {
+		repos := newRepos(t)
+		r := repos.Reviews
+		ana := mustUser(t, repos.Users, "Ana", "ana@example.com", domain.RoleReader)
+		beto := mustUser(t, repos.Users, "Beto", "beto@example.com", domain.RoleReader)
+		caro := mustUser(t, repos.Users, "Caro", "caro@example.com", domain.RoleReader)
+		uno := mustBook(t, repos.Books, "Uno", "Autora", validISBNs[0], "Novela")
+		dos := mustBook(t, repos.Books, "Dos", "Autor", validISBNs[1], "Novela")
+
+		base := time.Now().Add(-time.Hour)
+		add := func(user, book uint64, rating int, status domain.ReviewStatus) uint64 {
+			t.Helper()
+			base = base.Add(time.Second) // fechas distintas aun con precisión de segundos
+			return addReview(t, r, user, book, rating, status, base)
+		}
+		a1 := add(ana, uno, 4, domain.ReviewApproved)
+		b1 := add(beto, uno, 2, domain.ReviewApproved)
+		c1 := add(caro, uno, 5, domain.ReviewPending)
+		a2 := add(ana, dos, 3, domain.ReviewHidden)
+
+		// Por defecto lo más reciente primero
+		all, err := r.List(ctx(), domain.ReviewFilter{})
+		wantNoErr(t, err, "list")
+		if got := reviewIDs(all.Items); all.Total != 4 || !reflect.DeepEqual(got, []uint64{a2, c1, b1, a1}) {
+			t.Fatalf("unexpected list %v", got)
+		}
+		if d := all.Items[0]; d.BookTitle != "Dos" || d.UserName != "Ana" {
+			t.Fatalf("unexpected detail %+v", d)
+		}
+
+		approved, err := r.List(ctx(), domain.ReviewFilter{BookID: uno, Statuses: []domain.ReviewStatus{domain.ReviewApproved},
+			PageRequest: domain.PageRequest{Sort: domain.SortRating}})
+		wantNoErr(t, err, "list approved by rating")
+		if got := reviewIDs(approved.Items); !reflect.DeepEqual(got, []uint64{a1, b1}) {
+			t.Fatalf("unexpected approved list %v", got)
+		}
+		mine, err := r.List(ctx(), domain.ReviewFilter{UserID: ana, Rating: 3})
+		wantNoErr(t, err, "list by user and rating")
+		if got := reviewIDs(mine.Items); !reflect.DeepEqual(got, []uint64{a2}) {
+			t.Fatalf("unexpected list %v", got)
+		}
+		queue, err := r.List(ctx(), domain.ReviewFilter{Statuses: []domain.ReviewStatus{domain.ReviewPending, domain.ReviewHidden},
+			PageRequest: domain.PageRequest{Sort: "updated_at", Dir: domain.SortAsc}})
+		wantNoErr(t, err, "list queue")
+		if got := reviewIDs(queue.Items); !reflect.DeepEqual(got, []uint64{c1, a2}) {
+			t.Fatalf("unexpected queue %v", got)
+		}
+
+		_, err = r.List(ctx(), domain.ReviewFilter{Statuses: []domain.ReviewStatus{"deleted"}})
+		wantErr(t, err, domain.ErrValidation, "invalid status")
+		_, err = r.List(ctx(), domain.ReviewFilter{Rating: 6})
+		wantErr(t, err, domain.ErrValidation, "invalid rating")
+
+		// Solo cuentan las aprobadas; los libros sin ellas no aparecen
+		sums, err := r.Summaries(ctx(), []uint64{uno, dos, 999999})
+		wantNoErr(t, err, "summaries")
+		if len(sums) != 1 || sums[uno].BookID != uno || sums[uno].Count != 2 || sums[uno].Average() != 3 ||
+			sums[uno].Distribution != [5]int{0, 1, 0, 1, 0} {
+			t.Fatalf("unexpected summaries %+v", sums)
+		}
+		if sums, err := r.Summaries(ctx(), nil); err != nil || len(sums) != 0 {
+			t.Fatalf("expected no summaries, got %+v, %v", sums, err)
+		}
+	})
+
+	t.Run("BookSearchByRating", func(t *testing.T) {
+		repos := newRepos(t)
+		ana := mustUser(t, repos.Users, "Ana", "ana@example.com", domain.RoleReader)
+		beto := mustUser(t, repos.Users, "Beto", "beto@example.com", domain.RoleReader)
+		uno := mustBook(t, repos.Books, "Uno", "Ana", validISBNs[0], "Novela")
+		dos := mustBook(t, repos.Books, "Dos", "Ana", validISBNs[1], "Novela")
+		tres := mustBook(t, repos.Books, "Tres", "Ana", validISBNs[2], "Novela")
+		now := time.Now()
+		addReview(t, repos.Reviews, ana, uno, 3, domain.ReviewApproved, now)
+		addReview(t, repos.Reviews, beto, uno, 4, domain.ReviewApproved, now)
+		addReview(t, repos.Reviews, ana, dos, 5, domain.ReviewApproved, now)
+		addReview(t, repos.Reviews, beto, tres, 5, domain.ReviewPending, now)
+
+		// Sin reseñas aprobadas el promedio es 0: último al ordenar, fuera con min_rating
+		byRating, err := repos.Books.Search(ctx(), domain.BookFilter{PageRequest: domain.PageRequest{Sort: domain.SortRating}})
+		wantNoErr(t, err, "sort by rating")
+		if got := ids(byRating.Items); !reflect.DeepEqual(got, []uint64{dos, uno, tres}) {
+			t.Fatalf("unexpected order %v", got)
+		}
+		asc, err := repos.Books.Search(ctx(), domain.BookFilter{PageRequest: domain.PageRequest{Sort: domain.SortRating, Dir: domain.SortAsc}})
+		wantNoErr(t, err, "sort by rating asc")
+		if got := ids(asc.Items); !reflect.DeepEqual(got, []uint64{tres, uno, dos}) {
+			t.Fatalf("unexpected order %v", got)
+		}
+		good, err := repos.Books.Search(ctx(), domain.BookFilter{MinRating: 3.5,
+			PageRequest: domain.PageRequest{Sort: "title"}})
+		wantNoErr(t, err, "min rating")
+		if got := ids(good.Items); good.Total != 2 || !reflect.DeepEqual(got, []uint64{dos, uno}) {
+			t.Fatalf("unexpected filtered books %v", got)
+		}
+		best, err := repos.Books.Search(ctx(), domain.BookFilter{MinRating: 4.5})
+		wantNoErr(t, err, "min rating 4.5")
+		if got := ids(best.Items); !reflect.DeepEqual(got, []uint64{dos}) {
+			t.Fatalf("unexpected filtered books %v", got)
+		}
+	})
+
+	t.Run("CascadeOnDelete", func(t *testing.T) {
+		repos := newRepos(t)
+		r := repos.Reviews
+		ana := mustUser(t, repos.Users, "Ana", "ana@example.com", domain.RoleReader)
+		beto := mustUser(t, repos.Users, "Beto", "beto@example.com", domain.RoleReader)
+		uno := mustBook(t, repos.Books, "Uno", "Ana", validISBNs[0], "Novela")
+		dos := mustBook(t, repos.Books, "Dos", "Ana", validISBNs[1], "Novela")
+		now := time.Now()
+		addReview(t, r, ana, uno, 4, domain.ReviewApproved, now)
+		keep := addReview(t, r, ana, dos, 4, domain.ReviewApproved, now)
+		gone := addReview(t, r, beto, dos, 2, domain.ReviewApproved, now)
+		rv, err := r.GetByID(ctx(), gone)
+		wantNoErr(t, err, "get")
+		prev, err := rv.Edit(1, "Peor")
+		wantNoErr(t, err, "edit")
+		wantNoErr(t, r.Update(ctx(), rv, prev), "update")
+
+		wantNoErr(t, repos.Books.Delete(ctx(), uno), "delete book")
+		wantNoErr(t, repos.Users.Delete(ctx(), beto), "delete user")
+		page, err := r.List(ctx(), domain.ReviewFilter{})
+		wantNoErr(t, err, "list")
+		if got := reviewIDs(page.Items); !reflect.DeepEqual(got, []uint64{keep}) {
+			t.Fatalf("expected only ana's review of dos, got %v", got)
+		}
+		if hist, err := r.History(ctx(), gone); err != nil || len(hist) != 0 {
+			t.Fatalf("expected history deleted, got %+v, %v", hist, err)
+		}
+	})
+}
+
+func newReview(t *testing.T, userID, bookID uint64, rating int, text string) *domain.Review {
+	t.Helper()
+	rv, err := domain.NewReview(userID, bookID, rating, text)
+	if err != nil {
+		t.Fatalf("new review: %v", err)
+	}
+	return rv
+}
+
+// addReview crea una reseña ya en el estado pedido, escrita en at.
+func addReview(t *testing.T, r usecase.ReviewRepo, userID, bookID uint64, rating int, status domain.ReviewStatus, at time.Time) uint64 {
+	t.Helper()
+	id, err := r.Create(ctx(), domain.HydrateReview(0, userID, bookID, rating, "", status, at, at, time.Time{}))
+	wantNoErr(t, err, "create review")
+	return id
+}
+
+func reviewIDs(items []domain.ReviewDetail) []uint64 {
+	out := make([]uint64, 0, len(items))
+	for _, d := range items {
+		out = append(out, d.Review.ID())
+	}
+	return out
+}
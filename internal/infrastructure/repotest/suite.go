@@ -0,0 +1,140 @@
+// Package repotest es la suite de conformidad de los repositorios.
+//
+// Cualquier implementación de usecase.UserRepo, usecase.BookRepo y
+// usecase.AccessRepo (y de los demás repositorios que ofrezca) debe pasarla:
+// así MySQL, SQLite, PostgreSQL y la versión en memoria se comportan igual
+// (errores del dominio, normalización, orden y estadísticas).
+//
+// Uso desde el _test.go de un backend:
+//
+//	func TestConformance(t *testing.T) {
+//		repotest.Run(t, func(t *testing.T) repotest.Repos {
+//			// devolver repositorios sobre un almacenamiento VACÍO
+//		})
+//	}
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/usecase"
+)
+
+// Repos agrupa los repositorios de un backend. Los campos distintos de
+// Users/Books/Access son opcionales: si son nil, sus pruebas se omiten.
+type Repos struct {
+	Users       usecase.UserRepo
+	Books       usecase.BookRepo
+	Access      usecase.AccessRepo
+	Sessions    usecase.SessionRepo
+	Tokens      usecase.APITokenRepo
+	Authors     usecase.AuthorRepo
+	Taxonomy    usecase.TaxonomyRepo
+	Circulation usecase.CirculationRepo
+	Relations   usecase.RelationRepo
+	Files       usecase.FileRepo
+	Covers      usecase.CoverRepo
+	Progress    usecase.ProgressRepo
+	Annotations usecase.AnnotationRepo
+	Reviews     usecase.ReviewRepo
+}
+
+// Factory construye repositorios sobre un almacenamiento vacío.
+// Se invoca una vez por subprueba, así cada caso parte de cero.
+type Factory func(t *testing.T) Repos
+
+// Run ejecuta la suite completa.
+func Run(t *testing.T, newRepos Factory) {
+	t.Run("Users", func(t *testing.T) { RunUserRepo(t, newRepos) })
+	t.Run("Books", func(t *testing.T) { RunBookRepo(t, newRepos) })
+	t.Run("Access", func(t *testing.T) { RunAccessRepo(t, newRepos) })
+	t.Run("Sessions", func(t *testing.T) { RunSessionRepo(t, newRepos) })
+	t.Run("Tokens", func(t *testing.T) { RunAPITokenRepo(t, newRepos) })
+	t.Run("Authors", func(t *testing.T) { RunAuthorRepo(t, newRepos) })
+	t.Run("Taxonomy", func(t *testing.T) { RunTaxonomyRepo(t, newRepos) })
+	t.Run("Circulation", func(t *testing.T) { RunCirculationRepo(t, newRepos) })
+	t.Run("Relations", func(t *testing.T) { RunRelationRepo(t, newRepos) })
+	t.Run("Files", func(t *testing.T) { RunFileRepo(t, newRepos) })
+	t.Run("Covers", func(t *testing.T) { RunCoverRepo(t, newRepos) })
+	t.Run("Progress", func(t *testing.T) { RunProgressRepo(t, newRepos) })
+	t.Run("Annotations", func(t *testing.T) { RunAnnotationRepo(t, newRepos) })
+	t.Run("Reviews", func(t *testing.T) { RunReviewRepo(t, newRepos) })
+}
+
+// validISBNs son ISBN-13 válidos para crear libros distintos en las pruebas.
+var validISBNs = []string{
+	"9780306400070", "9780306400148", "9780306400216", "9780306400285",
+	"9780306400353", "9780306400421", "9780306400490", "9780306400568",
+}
+
+// -------------------- helpers --------------------
+
+func ctx() context.Context { return context.Background() }
+
+func mustUser(t *testing.T, r usecase.UserRepo, name, email string, role domain.Role) uint64 {
+	t.Helper()
+	u, err := domain.NewUser(name, email, role)
+	if err != nil {
+		t.Fatalf("new user: %v", err)
+	}
+	id, err := r.Create(ctx(), u)
+	if err != nil {
+		t.Fatalf("create user %s: %v", email, err)
+	}
+	if id == 0 {
+		t.Fatalf("create user %s: expected id > 0", email)
+	}
+	return id
+}
+
+func mustBook(t *testing.T, r usecase.BookRepo, title, author, isbn, category string, tags ...string) uint64 {
+	t.Helper()
+	b, err := domain.NewBook(title, author, 2000, isbn, category, tags, "")
+	if err != nil {
+		t.Fatalf("new book: %v", err)
+	}
+	id, err := r.Create(ctx(), b)
+	if err != nil {
+		t.Fatalf("create book %s: %v", isbn, err)
+	}
+	if id == 0 {
+		t.Fatalf("create book %s: expected id > 0", isbn)
+	}
+	return id
+}
+
+func wantErr(t *testing.T, got, want error, what string) {
+	t.Helper()
+	if !errors.Is(got, want) {
+		t.Fatalf("%s: expected %v, got %v", what, want, got)
+	}
+}
+
+func wantNoErr(t *testing.T, err error, what string) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("%s: %v", what, err)
+	}
+}
+
+// sameInstant compara fechas tolerando la precisión de segundos de algunos motores.
+func sameInstant(a, b time.Time) bool {
+	d := a.Sub(b)
+	if d < 0 {
+		d = -d
+	}
+	return d <= time.Second
+}
+
+// ids extrae los IDs en orden para comparar listados.
+func ids[T interface{ ID() uint64 }](items []T) []uint64 {
+	out := make([]uint64, 0, len(items))
+	for _, it := range items {
+		out = append(out, it.ID())
+	}
+	return out
+}
@@ -0,0 +1,213 @@
+package repotest
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/usecase"
+)
+
+// RunTaxonomyRepo verifica el contrato de usecase.TaxonomyRepo.
+func RunTaxonomyRepo(t *testing.T, newRepos Factory) {
+	if newRepos(t).Taxonomy == nil {
+		t.Skip("backend without TaxonomyRepo")
+	}
+
+	t.Run("BooksShareTags", func(t *testing.T) {
+		repos := newRepos(t)
+		r := repos.Taxonomy
+		a := mustBook(t, repos.Books, "Uno", "Ana", validISBNs[0], "Novela", "Ciencia  Ficción", "clásico")
+		mustBook(t, repos.Books, "Dos", "Eva", validISBNs[1], "novela", "ciencia ficción", "Clásico", "CLÁSICO")
+
+		b, err := repos.Books.GetByID(ctx(), a)
+		wantNoErr(t, err, "get book")
+		if want := []string{"ciencia ficción", "clásico"}; !reflect.DeepEqual(b.Tags(), want) || b.Category() != "Novela" {
+			t.Fatalf("expected tags %v in category Novela, got %v / %q", want, b.Tags(), b.Category())
+		}
+
+		page, err := r.ListTags(ctx(), domain.TagFilter{PageRequest: domain.PageRequest{Sort: "name"}})
+		wantNoErr(t, err, "list tags")
+		if page.Total != 2 || page.Items[0].Tag.Slug() != "ciencia-ficcion" || page.Items[0].Books != 2 || page.Items[1].Books != 2 {
+			t.Fatalf("expected 2 shared tags used by 2 books, got %+v", page)
+		}
+
+		page, err = r.ListTags(ctx(), domain.TagFilter{Q: "FICCION"})
+		wantNoErr(t, err, "list tags q")
+		if page.Total != 1 || page.Items[0].Tag.Name() != "ciencia ficción" {
+			t.Fatalf("q=FICCION: expected 1 tag, got %+v", page)
+		}
+		_, err = r.ListTags(ctx(), domain.TagFilter{PageRequest: domain.PageRequest{Sort: "year"}})
+		wantErr(t, err, domain.ErrValidation, "invalid sort")
+
+		cats, err := r.Categories(ctx())
+		wantNoErr(t, err, "categories")
+		if len(cats) != 1 || cats[0].Category.Name() != "Novela" || cats[0].Books != 2 {
+			t.Fatalf("expected one shared category with 2 books, got %+v", cats)
+		}
+	})
+
+	t.Run("RenameMergeDeleteTags", func(t *testing.T) {
+		repos := newRepos(t)
+		r := repos.Taxonomy
+		a := mustBook(t, repos.Books, "Uno", "Ana", validISBNs[0], "Novela", "scifi", "clásico")
+		b := mustBook(t, repos.Books, "Dos", "Eva", validISBNs[1], "Novela", "ciencia ficción")
+		c := mustBook(t, repos.Books, "Tres", "Leo", validISBNs[2], "Novela", "scifi", "ciencia ficción")
+
+		scifi := mustTag(t, r, "scifi")
+		cf := mustTag(t, r, "ciencia ficción")
+		clasico := mustTag(t, r, "clásico")
+
+		// Renombrar a un slug ocupado es un duplicado: hay que fusionar
+		wantNoErr(t, scifi.SetName("Ciencia-Ficción"), "set name")
+		_, err := r.RenameTag(ctx(), scifi)
+		wantErr(t, err, domain.ErrDuplicate, "rename to existing slug")
+
+		wantNoErr(t, clasico.SetName("Clásicos"), "set name")
+		affected, err := r.RenameTag(ctx(), clasico)
+		wantNoErr(t, err, "rename")
+		if !reflect.DeepEqual(affected, []uint64{a}) {
+			t.Fatalf("rename: expected affected [%d], got %v", a, affected)
+		}
+		got, _ := repos.Books.GetByID(ctx(), a)
+		if !slices.Contains(got.Tags(), "clásicos") {
+			t.Fatalf("rename not applied to book: %v", got.Tags())
+		}
+
+		affected, err = r.MergeTags(ctx(), cf.ID(), scifi.ID())
+		wantNoErr(t, err, "merge")
+		slices.Sort(affected)
+		if !reflect.DeepEqual(affected, []uint64{a, c}) {
+			t.Fatalf("merge: expected affected %v, got %v", []uint64{a, c}, affected)
+		}
+		_, err = r.GetTag(ctx(), scifi.ID())
+		wantErr(t, err, domain.ErrNotFound, "get merged tag")
+		for _, id := range []uint64{a, b, c} {
+			got, _ := repos.Books.GetByID(ctx(), id)
+			if n := len(slices.DeleteFunc(slices.Clone(got.Tags()), func(s string) bool { return s != "ciencia ficción" })); n != 1 {
+				t.Fatalf("merge: book %d expected one 'ciencia ficción', got %v", id, got.Tags())
+			}
+		}
+
+		affected, err = r.DeleteTag(ctx(), cf.ID())
+		wantNoErr(t, err, "delete")
+		if len(affected) != 3 {
+			t.Fatalf("delete: expected 3 affected books, got %v", affected)
+		}
+		got, _ = repos.Books.GetByID(ctx(), b)
+		if len(got.Tags()) != 0 {
+			t.Fatalf("delete: expected no tags, got %v", got.Tags())
+		}
+
+		_, err = r.MergeTags(ctx(), clasico.ID(), 999999)
+		wantErr(t, err, domain.ErrNotFound, "merge missing")
+		_, err = r.DeleteTag(ctx(), 999999)
+		wantErr(t, err, domain.ErrNotFound, "delete missing")
+	})
+
+	t.Run("CategoryTree", func(t *testing.T) {
+		repos := newRepos(t)
+		r := repos.Taxonomy
+		fic := mustCategory(t, r, "Ficción", 0)
+		sf := mustCategory(t, r, "Ciencia ficción", fic)
+		book := mustBook(t, repos.Books, "Uno", "Ana", validISBNs[0], "ciencia  ficcion")
+		mustBook(t, repos.Books, "Dos", "Eva", validISBNs[1], "Ensayo")
+
+		got, _ := repos.Books.GetByID(ctx(), book)
+		if got.Category() != "Ciencia ficción" {
+			t.Fatalf("expected canonical category name, got %q", got.Category())
+		}
+
+		dup, err := domain.NewCategory("FICCION", 0)
+		wantNoErr(t, err, "new category")
+		_, err = r.CreateCategory(ctx(), dup)
+		wantErr(t, err, domain.ErrDuplicate, "create duplicate slug")
+
+		cats, err := r.Categories(ctx())
+		wantNoErr(t, err, "categories")
+		tree := domain.BuildCategoryTree(cats)
+		if len(tree) != 2 || tree[0].Category.Name() != "Ensayo" || tree[1].TotalBooks != 1 || tree[1].Books != 0 ||
+			len(tree[1].Children) != 1 || tree[1].Children[0].Category.ID() != sf {
+			t.Fatalf("unexpected tree %+v", tree)
+		}
+
+		// Renombrar y mover a la raíz se refleja en los libros
+		c, err := r.GetCategory(ctx(), sf)
+		wantNoErr(t, err, "get")
+		wantNoErr(t, c.SetName("Sci-Fi"), "set name")
+		wantNoErr(t, c.SetParent(0), "set parent")
+		affected, err := r.UpdateCategory(ctx(), c)
+		wantNoErr(t, err, "update")
+		if !reflect.DeepEqual(affected, []uint64{book}) {
+			t.Fatalf("update: expected affected [%d], got %v", book, affected)
+		}
+		c, _ = r.GetCategory(ctx(), sf)
+		got, _ = repos.Books.GetByID(ctx(), book)
+		if c.Name() != "Sci-Fi" || c.Slug() != "sci-fi" || c.ParentID() != 0 || got.Category() != "Sci-Fi" {
+			t.Fatalf("update not persisted: %+v / book %q", c, got.Category())
+		}
+
+		wantNoErr(t, c.SetName("Ensayo"), "set name")
+		_, err = r.UpdateCategory(ctx(), c)
+		wantErr(t, err, domain.ErrDuplicate, "rename to existing slug")
+		_, err = r.GetCategory(ctx(), 999999)
+		wantErr(t, err, domain.ErrNotFound, "get missing")
+		wantErr(t, r.DeleteCategory(ctx(), 999999), domain.ErrNotFound, "delete missing")
+	})
+
+	t.Run("MergeCategories", func(t *testing.T) {
+		repos := newRepos(t)
+		r := repos.Taxonomy
+		dup := mustCategory(t, r, "Narrativa", 0)
+		keep := mustCategory(t, r, "Novela", dup)
+		child := mustCategory(t, r, "Policial", dup)
+		moved := mustBook(t, repos.Books, "Uno", "Ana", validISBNs[0], "Narrativa")
+		mustBook(t, repos.Books, "Dos", "Eva", validISBNs[1], "Novela")
+
+		// keep estaba dentro de dup: sube a su lugar y hereda sus hijos y libros
+		affected, err := r.MergeCategories(ctx(), keep, dup)
+		wantNoErr(t, err, "merge")
+		if !reflect.DeepEqual(affected, []uint64{moved}) {
+			t.Fatalf("merge: expected affected [%d], got %v", moved, affected)
+		}
+		_, err = r.GetCategory(ctx(), dup)
+		wantErr(t, err, domain.ErrNotFound, "get merged")
+		k, _ := r.GetCategory(ctx(), keep)
+		c, _ := r.GetCategory(ctx(), child)
+		got, _ := repos.Books.GetByID(ctx(), moved)
+		if k.ParentID() != 0 || c.ParentID() != keep || got.Category() != "Novela" {
+			t.Fatalf("merge not applied: keep parent %d, child parent %d, book %q", k.ParentID(), c.ParentID(), got.Category())
+		}
+
+		wantNoErr(t, r.DeleteCategory(ctx(), child), "delete empty")
+		_, err = r.MergeCategories(ctx(), keep, 999999)
+		wantErr(t, err, domain.ErrNotFound, "merge missing")
+	})
+}
+
+// mustTag busca una etiqueta existente por nombre exacto.
+func mustTag(t *testing.T, r usecase.TaxonomyRepo, name string) *domain.Tag {
+	t.Helper()
+	page, err := r.ListTags(ctx(), domain.TagFilter{Q: name, PageRequest: domain.PageRequest{PageSize: 100}})
+	wantNoErr(t, err, "list tags")
+	for _, u := range page.Items {
+		if u.Tag.Name() == name {
+			return u.Tag
+		}
+	}
+	t.Fatalf("tag %q not found in %+v", name, page.Items)
+	return nil
+}
+
+func mustCategory(t *testing.T, r usecase.TaxonomyRepo, name string, parentID uint64) uint64 {
+	t.Helper()
+	c, err := domain.NewCategory(name, parentID)
+	wantNoErr(t, err, "new category")
+	id, err := r.CreateCategory(ctx(), c)
+	wantNoErr(t, err, "create category "+name)
+	if id == 0 {
+		t.Fatalf("create category %s: expected id > 0", name)
+	}
+	return id
+}
@@ -0,0 +1,207 @@
+package repotest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+// RunUserRepo verifica el contrato de usecase.UserRepo.
+func RunUserRepo(t *testing.T, newRepos Factory) {
+	t.Run("CreateAndGet", func(t *testing.T) {
+		r := newRepos(t).Users
+		u, _ := domain.NewUser("Ana Pérez", "ana@example.com", domain.RoleReader)
+		_ = u.SetPassword("secreto123")
+		id, err := r.Create(ctx(), u)
+		wantNoErr(t, err, "create")
+
+		got, err := r.GetByID(ctx(), id)
+		wantNoErr(t, err, "get")
+		if got.ID() != id || got.Name() != "Ana Pérez" || got.Email() != "ana@example.com" ||
+			got.Role() != domain.RoleReader || !got.Active() {
+			t.Fatalf("unexpected user %+v", got)
+		}
+		if !got.CheckPassword("secreto123") {
+			t.Fatalf("password hash not persisted")
+		}
+		if got.CreatedAt().IsZero() || got.UpdatedAt().IsZero() {
+			t.Fatalf("timestamps not set")
+		}
+	})
+
+	t.Run("IDsIncrease", func(t *testing.T) {
+		r := newRepos(t).Users
+		a := mustUser(t, r, "Ana", "a@example.com", domain.RoleReader)
+		b := mustUser(t, r, "Beto", "b@example.com", domain.RoleReader)
+		if b <= a {
+			t.Fatalf("ids must increase: %d then %d", a, b)
+		}
+	})
+
+	t.Run("DuplicateEmail", func(t *testing.T) {
+		r := newRepos(t).Users
+		mustUser(t, r, "Ana", "ana@example.com", domain.RoleReader)
+
+		// El dominio normaliza a minúsculas; el repo debe rechazar el duplicado
+		dup, _ := domain.NewUser("Otra", "ANA@Example.com", domain.RoleAdmin)
+		_, err := r.Create(ctx(), dup)
+		wantErr(t, err, domain.ErrDuplicate, "create duplicate")
+	})
+
+	t.Run("GetByEmailNormalizes", func(t *testing.T) {
+		r := newRepos(t).Users
+		id := mustUser(t, r, "Ana", "ana@example.com", domain.RoleReader)
+
+		got, err := r.GetByEmail(ctx(), "  ANA@example.COM ")
+		wantNoErr(t, err, "get by email")
+		if got.ID() != id {
+			t.Fatalf("expected id %d, got %d", id, got.ID())
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		r := newRepos(t).Users
+		_, err := r.GetByID(ctx(), 999999)
+		wantErr(t, err, domain.ErrNotFound, "get by id")
+		_, err = r.GetByEmail(ctx(), "nadie@example.com")
+		wantErr(t, err, domain.ErrNotFound, "get by email")
+		wantErr(t, r.Delete(ctx(), 999999), domain.ErrNotFound, "delete")
+	})
+
+	t.Run("ListNewestFirst", func(t *testing.T) {
+		r := newRepos(t).Users
+		ids := []uint64{
+			mustUser(t, r, "Ana", "a@example.com", domain.RoleReader),
+			mustUser(t, r, "Beto", "b@example.com", domain.RoleAdmin),
+			mustUser(t, r, "Caro", "c@example.com", domain.RoleConsultor),
+		}
+		list, err := r.List(ctx())
+		wantNoErr(t, err, "list")
+		if len(list) != 3 {
+			t.Fatalf("expected 3 users, got %d", len(list))
+		}
+		for i, u := range list {
+			if u.ID() != ids[len(ids)-1-i] {
+				t.Fatalf("list must be ordered by id desc, got %d at %d", u.ID(), i)
+			}
+		}
+	})
+
+	t.Run("SearchFilters", func(t *testing.T) {
+		r := newRepos(t).Users
+		ana := mustUser(t, r, "Ana", "ana@colegio.edu", domain.RoleReader)
+		beto := mustUser(t, r, "Beto", "beto@colegio.edu", domain.RoleReader)
+		mustUser(t, r, "Caro", "caro@otro.org", domain.RoleAdmin)
+
+		u, _ := r.GetByID(ctx(), beto)
+		u.Deactivate()
+		wantNoErr(t, r.Update(ctx(), u), "deactivate")
+
+		check := func(name string, f domain.UserFilter, want ...uint64) {
+			t.Helper()
+			page, err := r.Search(ctx(), f)
+			wantNoErr(t, err, name)
+			got := page.Items
+			if len(got) != len(want) || page.Total != len(want) {
+				t.Fatalf("%s: expected %d users, got %d (total %d)", name, len(want), len(got), page.Total)
+			}
+			for i := range want {
+				if got[i].ID() != want[i] {
+					t.Fatalf("%s: expected id %d at %d, got %d", name, want[i], i, got[i].ID())
+				}
+			}
+		}
+
+		active, inactive := true, false
+		check("role", domain.UserFilter{Role: domain.RoleReader}, beto, ana)
+		check("active", domain.UserFilter{Role: domain.RoleReader, Active: &active}, ana)
+		check("inactive", domain.UserFilter{Active: &inactive}, beto)
+		check("email", domain.UserFilter{Email: "COLEGIO"}, beto, ana)
+		check("none", domain.UserFilter{Email: "inexistente"})
+	})
+
+	t.Run("SearchPageAndSort", func(t *testing.T) {
+		r := newRepos(t).Users
+		caro := mustUser(t, r, "caro", "caro@example.com", domain.RoleReader)
+		ana := mustUser(t, r, "Ana", "zeta@example.com", domain.RoleAdmin)
+		beto := mustUser(t, r, "Beto", "beto@example.com", domain.RoleReader)
+
+		check := func(name string, p domain.PageRequest, total int, want ...uint64) {
+			t.Helper()
+			got, err := r.Search(ctx(), domain.UserFilter{PageRequest: p})
+			wantNoErr(t, err, name)
+			if got.Total != total || !reflect.DeepEqual(ids(got.Items), want) {
+				t.Fatalf("%s: expected %v (total %d), got %v (total %d)", name, want, total, ids(got.Items), got.Total)
+			}
+		}
+
+		check("default", domain.PageRequest{}, 3, beto, ana, caro)
+		check("name", domain.PageRequest{Sort: "name"}, 3, ana, beto, caro)
+		check("email desc", domain.PageRequest{Sort: "email", Dir: domain.SortDesc}, 3, ana, caro, beto)
+		check("role", domain.PageRequest{Sort: "role"}, 3, ana, caro, beto)
+		check("page 2", domain.PageRequest{Sort: "name", Page: 2, PageSize: 2}, 3, caro)
+
+		_, err := r.Search(ctx(), domain.UserFilter{PageRequest: domain.PageRequest{Sort: "password_hash"}})
+		wantErr(t, err, domain.ErrValidation, "unknown sort")
+
+		// List no pagina
+		list, err := r.List(ctx())
+		wantNoErr(t, err, "list")
+		if len(list) != 3 {
+			t.Fatalf("list: expected 3 users, got %d", len(list))
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		r := newRepos(t).Users
+		id := mustUser(t, r, "Ana", "ana@example.com", domain.RoleReader)
+		mustUser(t, r, "Beto", "beto@example.com", domain.RoleReader)
+
+		u, _ := r.GetByID(ctx(), id)
+		_ = u.SetName("Ana María")
+		_ = u.SetEmail("ana.maria@example.com")
+		_ = u.SetRole(domain.RoleConsultor)
+		wantNoErr(t, r.Update(ctx(), u), "update")
+
+		got, err := r.GetByEmail(ctx(), "ana.maria@example.com")
+		wantNoErr(t, err, "get updated")
+		if got.ID() != id || got.Name() != "Ana María" || got.Role() != domain.RoleConsultor {
+			t.Fatalf("update not persisted: %+v", got)
+		}
+		if got.UpdatedAt().Before(got.CreatedAt()) {
+			t.Fatalf("updated_at before created_at")
+		}
+		_, err = r.GetByEmail(ctx(), "ana@example.com")
+		wantErr(t, err, domain.ErrNotFound, "old email")
+
+		// Tomar el email de otro usuario
+		_ = got.SetEmail("beto@example.com")
+		wantErr(t, r.Update(ctx(), got), domain.ErrDuplicate, "update duplicate")
+	})
+
+	t.Run("ReturnedCopiesAreDetached", func(t *testing.T) {
+		r := newRepos(t).Users
+		id := mustUser(t, r, "Ana", "ana@example.com", domain.RoleReader)
+
+		u, _ := r.GetByID(ctx(), id)
+		_ = u.SetName("Cambiado sin Update")
+
+		got, _ := r.GetByID(ctx(), id)
+		if got.Name() != "Ana" {
+			t.Fatalf("changes must not persist without Update")
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		r := newRepos(t).Users
+		id := mustUser(t, r, "Ana", "ana@example.com", domain.RoleReader)
+
+		wantNoErr(t, r.Delete(ctx(), id), "delete")
+		_, err := r.GetByID(ctx(), id)
+		wantErr(t, err, domain.ErrNotFound, "get deleted")
+
+		// El email queda libre
+		mustUser(t, r, "Ana", "ana@example.com", domain.RoleReader)
+	})
+}
@@ -0,0 +1,96 @@
+package search
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+// counter agrupa valores sin distinguir mayúsculas ni acentos. Se muestra la
+// forma escrita más frecuente (empate: la menor alfabéticamente).
+type counter struct {
+	forms  map[string]map[string]int // clave normalizada -> forma original -> veces
+	counts map[string]int
+}
+
+func newCounter() *counter {
+	return &counter{forms: map[string]map[string]int{}, counts: map[string]int{}}
+}
+
+func (c *counter) add(value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+	k := Fold(value)
+	if c.forms[k] == nil {
+		c.forms[k] = map[string]int{}
+	}
+	c.forms[k][value]++
+	c.counts[k]++
+}
+
+func (c *counter) list() []domain.FacetCount {
+	out := make([]domain.FacetCount, 0, len(c.counts))
+	for k, n := range c.counts {
+		display, best := "", 0
+		for form, m := range c.forms[k] {
+			if m > best || (m == best && form < display) {
+				display, best = form, m
+			}
+		}
+		out = append(out, domain.FacetCount{Value: display, Count: n})
+	}
+	return out
+}
+
+// facets cuenta las coincidencias por dimensión (todas, no solo la página).
+func facets(hits []domain.BookHit, withYears bool) *domain.BookFacets {
+	categories, authors, tags := newCounter(), newCounter(), newCounter()
+	decades, years := map[int]int{}, map[int]int{}
+
+	for _, h := range hits {
+		b := h.Book
+		categories.add(b.Category())
+		authors.add(b.Author())
+		seen := map[string]bool{} // una etiqueta repetida cuenta una vez por libro
+		for _, t := range b.Tags() {
+			if k := Fold(t); !seen[k] {
+				seen[k] = true
+				tags.add(t)
+			}
+		}
+		if b.Year() > 0 {
+			decades[domain.Decade(b.Year())]++
+			years[b.Year()]++
+		}
+	}
+
+	out := &domain.BookFacets{
+		Categories: domain.TopFacets(categories.list()),
+		Authors:    domain.TopFacets(authors.list()),
+		Tags:       domain.TopFacets(tags.list()),
+		Decades:    byYearDesc(decades),
+	}
+	if withYears {
+		out.Years = byYearDesc(years)
+	}
+	return out
+}
+
+// byYearDesc lista décadas/años del más reciente al más antiguo.
+func byYearDesc(counts map[int]int) []domain.FacetCount {
+	keys := make([]int, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(keys)))
+
+	out := make([]domain.FacetCount, 0, len(keys))
+	for _, k := range keys {
+		out = append(out, domain.FacetCount{Value: strconv.Itoa(k), Count: counts[k]})
+	}
+	return out
+}
@@ -0,0 +1,69 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+func TestFacets(t *testing.T) {
+	x := NewIndex()
+	mk := func(id uint64, author, category string, year int, tags string) *domain.Book {
+		b, err := domain.HydrateBook(id, "Libro", author, year, fmt.Sprintf("isbn-%d", id), category, strings.Split(tags, ","), "", true, time.Now(), time.Now())
+		if err != nil {
+			t.Fatalf("hydrate: %v", err)
+		}
+		return b
+	}
+	x.Rebuild([]*domain.Book{
+		mk(1, "Julio Cortázar", "Novela", 1963, "clásico,Argentina"),
+		mk(2, "julio cortazar", "Cuento", 1951, "argentina"),
+		mk(3, "Jorge Luis Borges", "Cuento", 1944, "argentina,clasico"),
+		mk(4, "Isabel Allende", "Novela", 1982, "chile"),
+	})
+
+	get := func(f domain.BookFilter) *domain.BookFacets {
+		t.Helper()
+		f.WithFacets = true
+		res, err := x.Search(context.Background(), f)
+		if err != nil {
+			t.Fatalf("search: %v", err)
+		}
+		if res.Facets == nil {
+			t.Fatalf("facets requested but missing")
+		}
+		return res.Facets
+	}
+
+	all := get(domain.BookFilter{})
+	want := []domain.FacetCount{{Value: "Julio Cortázar", Count: 2}, {Value: "Isabel Allende", Count: 1}, {Value: "Jorge Luis Borges", Count: 1}}
+	if !reflect.DeepEqual(all.Authors, want) {
+		t.Fatalf("authors (accent/case folded): %+v", all.Authors)
+	}
+	if !reflect.DeepEqual(all.Tags[:2], []domain.FacetCount{{Value: "argentina", Count: 3}, {Value: "clasico", Count: 2}}) {
+		t.Fatalf("tags: %+v", all.Tags)
+	}
+	if !reflect.DeepEqual(all.Decades, []domain.FacetCount{{Value: "1980", Count: 1}, {Value: "1960", Count: 1}, {Value: "1950", Count: 1}, {Value: "1940", Count: 1}}) {
+		t.Fatalf("decades: %+v", all.Decades)
+	}
+	if all.Years != nil {
+		t.Fatalf("years must be omitted without a narrow year range")
+	}
+
+	// Refinar: las facetas cuentan solo el resultado filtrado
+	cuentos := get(domain.BookFilter{Category: "cuento", Tag: "ARGENTINA", YearFrom: 1950, YearTo: 1959})
+	if !reflect.DeepEqual(cuentos.Categories, []domain.FacetCount{{Value: "Cuento", Count: 1}}) ||
+		!reflect.DeepEqual(cuentos.Years, []domain.FacetCount{{Value: "1951", Count: 1}}) {
+		t.Fatalf("refined facets: %+v", cuentos)
+	}
+
+	res, _ := x.Search(context.Background(), domain.BookFilter{})
+	if res.Facets != nil {
+		t.Fatalf("facets must be nil unless requested")
+	}
+}
@@ -0,0 +1,152 @@
+package search
+
+import "sort"
+
+// Búsqueda tolerante a errores de tipeo: cada término del vocabulario se
+// indexa también por sus trigramas; los candidatos que comparten trigramas con
+// el término buscado se verifican con la distancia de edición (con
+// transposiciones: "cervantez" -> "cervantes", "mrquez" -> "marquez").
+
+// fuzzyPenalty multiplica la frecuencia de un término aproximado por cada
+// edición, para que las coincidencias exactas pesen más.
+const fuzzyPenalty = 0.5
+
+// maxEdits es la cantidad de errores tolerados según el largo del término:
+// las palabras cortas deben escribirse bien ("sol" no debe encontrar "son").
+func maxEdits(term string) int {
+	switch n := len([]rune(term)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// trigrams devuelve los trigramas de t con bordes ("$$t$"), sin repetir.
+func trigrams(t string) []string {
+	r := append([]rune("$$"), []rune(t)...)
+	r = append(r, '$')
+	seen := map[string]struct{}{}
+	var out []string
+	for i := 0; i+3 <= len(r); i++ {
+		g := string(r[i : i+3])
+		if _, ok := seen[g]; !ok {
+			seen[g] = struct{}{}
+			out = append(out, g)
+		}
+	}
+	return out
+}
+
+// editDistance calcula la distancia de Damerau-Levenshtein restringida
+// (inserción, borrado, sustitución y transposición de vecinos). Devuelve
+// max+1 en cuanto sabe que la distancia supera max.
+func editDistance(a, b string, max int) int {
+	ra, rb := []rune(a), []rune(b)
+	if d := len(ra) - len(rb); d > max || -d > max {
+		return max + 1
+	}
+
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+			rowMin = min(rowMin, cur[j])
+		}
+		if rowMin > max {
+			return max + 1
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return min(prev[len(rb)], max+1)
+}
+
+// expansion es un término del vocabulario que satisface una cláusula, con su peso.
+type expansion struct {
+	term   string
+	weight float64
+}
+
+// fuzzyExpand lista los términos del vocabulario a distancia <= maxEdits(t),
+// el exacto primero y luego por cercanía. Requiere x.mu.
+func (x *Index) fuzzyExpand(t string) []expansion {
+	edits := maxEdits(t)
+	if edits == 0 {
+		return []expansion{{term: t, weight: 1}}
+	}
+
+	// Candidatos: términos que comparten algún trigrama
+	candidates := map[string]struct{}{}
+	for _, g := range trigrams(t) {
+		for term := range x.grams[g] {
+			candidates[term] = struct{}{}
+		}
+	}
+
+	type scored struct {
+		term string
+		dist int
+	}
+	var found []scored
+	for c := range candidates {
+		if d := editDistance(t, c, edits); d <= edits {
+			found = append(found, scored{c, d})
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].dist != found[j].dist {
+			return found[i].dist < found[j].dist
+		}
+		return found[i].term < found[j].term
+	})
+	if len(found) > maxExpansions {
+		found = found[:maxExpansions]
+	}
+
+	out := make([]expansion, 0, len(found))
+	for _, f := range found {
+		w := 1.0
+		for range f.dist {
+			w *= fuzzyPenalty
+		}
+		out = append(out, expansion{term: f.term, weight: w})
+	}
+	return out
+}
+
+// addGrams / removeGrams mantienen el índice de trigramas del vocabulario.
+func (x *Index) addGrams(term string) {
+	for _, g := range trigrams(term) {
+		set, ok := x.grams[g]
+		if !ok {
+			set = map[string]struct{}{}
+			x.grams[g] = set
+		}
+		set[term] = struct{}{}
+	}
+}
+
+func (x *Index) removeGrams(term string) {
+	for _, g := range trigrams(term) {
+		delete(x.grams[g], term)
+		if len(x.grams[g]) == 0 {
+			delete(x.grams, g)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package search
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+func TestEditDistance(t *testing.T) {
+	cases := []struct {
+		a, b string
+		max  int
+		want int
+	}{
+		{"cervantes", "cervantez", 2, 1},
+		{"marquez", "mrquez", 1, 1},    // borrado
+		{"garcia", "gracia", 1, 1},     // transposición
+		{"soledad", "soledad", 1, 0},   // igual
+		{"macondo", "mandolina", 1, 2}, // supera el máximo: max+1
+		{"año", "ano", 1, 1},           // runas, no bytes
+	}
+	for _, c := range cases {
+		if got := editDistance(c.a, c.b, c.max); got != c.want {
+			t.Errorf("editDistance(%q, %q, %d) = %d, want %d", c.a, c.b, c.max, got, c.want)
+		}
+	}
+}
+
+func TestFuzzySearch(t *testing.T) {
+	x := fixture(t)
+	fuzzy := func(q string) []uint64 {
+		t.Helper()
+		return hitIDs(search(t, x, domain.BookFilter{Q: q, Fuzzy: true, PageRequest: domain.PageRequest{Sort: domain.SortRelevance}}))
+	}
+
+	if got := hitIDs(search(t, x, domain.BookFilter{Q: "cortazr"})); len(got) != 0 {
+		t.Fatalf("exact mode must not tolerate typos, got %v", got)
+	}
+	if got := fuzzy("cortazr"); !reflect.DeepEqual(got, []uint64{3}) {
+		t.Fatalf("cortazr: expected [3], got %v", got)
+	}
+	if got := fuzzy("marqez macondo"); !reflect.DeepEqual(got, []uint64{1}) {
+		t.Fatalf("typo + exact term: expected [1], got %v", got)
+	}
+	if got := fuzzy("gao"); len(got) != 0 {
+		t.Fatalf("short words must match exactly, got %v", got)
+	}
+
+	// La coincidencia exacta pesa más que la aproximada y se resalta igual
+	hits := search(t, x, domain.BookFilter{Q: "amor", Fuzzy: true, PageRequest: domain.PageRequest{Sort: domain.SortRelevance}})
+	if len(hits.Items) == 0 || hits.Items[0].Book.ID() != 2 {
+		t.Fatalf("expected exact match first, got %v", hitIDs(hits))
+	}
+	hit := search(t, x, domain.BookFilter{Q: "cortazr", Fuzzy: true}).Items[0]
+	if hit.Title == nil || hit.Snippet == nil {
+		t.Fatalf("expected fragments for fuzzy hit")
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	x := fixture(t)
+	suggest := func(prefix string) []domain.Suggestion {
+		t.Helper()
+		got, err := x.Suggest(context.Background(), prefix, 5)
+		if err != nil {
+			t.Fatalf("suggest %q: %v", prefix, err)
+		}
+		return got
+	}
+
+	got := suggest("gab")
+	if len(got) != 1 || got[0].Text != "Gabriel García Márquez" || got[0].Kind != domain.SuggestAuthor || got[0].Count != 2 {
+		t.Fatalf("gab: expected the author with 2 books, got %+v", got)
+	}
+
+	got = suggest("el a")
+	if len(got) != 1 || got[0].Kind != domain.SuggestTitle || got[0].BookID != 2 {
+		t.Fatalf("el a: expected title of book 2, got %+v", got)
+	}
+
+	// Inicio del texto antes que inicio de palabra
+	got = suggest("r")
+	if len(got) != 1 || got[0].Text != "Rayuela" {
+		t.Fatalf("r: expected Rayuela, got %+v", got)
+	}
+	got = suggest("so")
+	if len(got) != 1 || got[0].Text != "Cien años de soledad" {
+		t.Fatalf("so: expected word match, got %+v", got)
+	}
+
+	// Errores de tipeo
+	got = suggest("cortas")
+	if len(got) != 1 || got[0].Text != "Julio Cortázar" {
+		t.Fatalf("cortas: expected approximate author, got %+v", got)
+	}
+
+	if got := suggest("zzz"); len(got) != 0 {
+		t.Fatalf("zzz: expected none, got %+v", got)
+	}
+}
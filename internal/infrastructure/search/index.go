@@ -0,0 +1,502 @@
+package search
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+// field identifica un campo indexado del libro.
+type field int
+
+const (
+	fieldTitle field = iota
+	fieldAuthor
+	fieldTags
+	fieldCategory
+	fieldDescription
+	fieldISBN
+	numFields
+)
+
+// fieldWeights: una coincidencia en el título pesa más que en la descripción.
+var fieldWeights = [numFields]float64{
+	fieldTitle:       3,
+	fieldAuthor:      2,
+	fieldTags:        1.5,
+	fieldCategory:    1,
+	fieldDescription: 1,
+	fieldISBN:        1,
+}
+
+// Parámetros BM25 habituales.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	// maxExpansions limita cuántos términos del vocabulario abarca un prefijo.
+	maxExpansions = 64
+)
+
+// document es un libro indexado.
+type document struct {
+	book   *domain.Book
+	fields [numFields][]string // términos normalizados en orden (para frases)
+	length float64             // longitud ponderada (normalización BM25)
+}
+
+// Index es un índice invertido en memoria, seguro para uso concurrente.
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[uint64]*document
+	postings map[string]map[uint64]struct{} // término -> libros que lo contienen
+	totalLen float64
+
+	vocab      []string // términos ordenados (búsqueda por prefijo)
+	vocabDirty bool
+
+	grams map[string]map[string]struct{} // trigrama -> términos (búsqueda aproximada)
+
+	ratings map[uint64]float64 // libro -> promedio de estrellas (ver SetRating)
+}
+
+// NewIndex crea un índice vacío.
+func NewIndex() *Index {
+	return &Index{
+		docs:     map[uint64]*document{},
+		postings: map[string]map[uint64]struct{}{},
+		grams:    map[string]map[string]struct{}{},
+		ratings:  map[uint64]float64{},
+	}
+}
+
+// Rebuild reemplaza el contenido del índice por los libros dados
+// (se usa al arrancar, con BookRepo.List). Las valoraciones se cargan
+// aparte con SetRating.
+func (x *Index) Rebuild(books []*domain.Book) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.docs = map[uint64]*document{}
+	x.postings = map[string]map[uint64]struct{}{}
+	x.grams = map[string]map[string]struct{}{}
+	x.totalLen = 0
+	x.vocabDirty = true
+	for _, b := range books {
+		x.add(b)
+	}
+}
+
+// Index agrega o reemplaza un libro.
+func (x *Index) Index(b *domain.Book) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.remove(b.ID())
+	x.add(b)
+}
+
+// Remove quita un libro (no hace nada si no estaba).
+func (x *Index) Remove(id uint64) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.remove(id)
+	delete(x.ratings, id)
+}
+
+// SetRating guarda el promedio de estrellas del libro para filtrar y ordenar
+// por valoración (0 = sin reseñas aprobadas).
+func (x *Index) SetRating(id uint64, avg float64) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if avg <= 0 {
+		delete(x.ratings, id)
+		return
+	}
+	x.ratings[id] = avg
+}
+
+func (x *Index) add(b *domain.Book) {
+	d := &document{book: cloneBook(b)}
+	d.fields[fieldTitle] = terms(b.Title())
+	d.fields[fieldAuthor] = terms(b.Author())
+	d.fields[fieldTags] = terms(strings.Join(b.Tags(), " "))
+	d.fields[fieldCategory] = terms(b.Category())
+	d.fields[fieldDescription] = terms(b.Description())
+	d.fields[fieldISBN] = terms(b.ISBN())
+
+	for f, ts := range d.fields {
+		d.length += fieldWeights[f] * float64(len(ts))
+		for _, t := range ts {
+			ids, ok := x.postings[t]
+			if !ok {
+				ids = map[uint64]struct{}{}
+				x.postings[t] = ids
+				x.vocabDirty = true
+				x.addGrams(t)
+			}
+			ids[b.ID()] = struct{}{}
+		}
+	}
+	x.docs[b.ID()] = d
+	x.totalLen += d.length
+}
+
+func (x *Index) remove(id uint64) {
+	d, ok := x.docs[id]
+	if !ok {
+		return
+	}
+	for _, ts := range d.fields {
+		for _, t := range ts {
+			delete(x.postings[t], id)
+			if len(x.postings[t]) == 0 {
+				delete(x.postings, t)
+				x.vocabDirty = true
+				x.removeGrams(t)
+			}
+		}
+	}
+	x.totalLen -= d.length
+	delete(x.docs, id)
+}
+
+// Search aplica el filtro: Q se interpreta con domain.ParseQuery (texto libre,
+// campos, negación, OR), autor/categoría como "contiene", etiqueta/años
+// exactos y el promedio mínimo de estrellas, igual que los repositorios. Solo el texto libre no negado aporta
+// relevancia. Con f.Fuzzy las palabras toleran errores de tipeo (ver fuzzy.go).
+// Ordena por relevancia salvo que se pida otro campo.
+// Con f.WithFacets agrega las facetas de todas las coincidencias.
+func (x *Index) Search(ctx context.Context, f domain.BookFilter) (domain.BookResults, error) {
+	p, err := f.Paging()
+	if err != nil {
+		return domain.BookResults{}, err
+	}
+	expr, err := f.Query()
+	if err != nil {
+		return domain.BookResults{}, err
+	}
+
+	x.mu.Lock() // el vocabulario ordenado se reconstruye de forma perezosa
+	x.sortVocab()
+	x.mu.Unlock()
+
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	q := x.compile(expr, f.Fuzzy)
+	match := newFilter(f, x.ratings)
+
+	hits := []domain.BookHit{}
+	for id, d := range x.docs {
+		if !match(d.book) || !domain.EvalQuery(expr, func(leaf domain.QueryExpr) bool { return q.leaf(id, d, leaf) }) {
+			continue
+		}
+		hits = append(hits, domain.BookHit{Book: d.book, Score: q.score(id, d)})
+	}
+	sortHits(hits, p, x.ratings)
+
+	var res domain.BookResults
+	if f.WithFacets {
+		res.Facets = facets(hits, f.NarrowYears())
+	}
+
+	page := domain.Page[domain.BookHit]{Items: []domain.BookHit{}, Total: len(hits), Page: p.Page, PageSize: p.PageSize}
+	if from := p.Offset(); from >= 0 && from < len(hits) {
+		page.Items = hits[from:min(from+p.PageSize, len(hits))]
+	}
+
+	// Fragmentos solo para la página visible
+	for i := range page.Items {
+		b := page.Items[i].Book
+		page.Items[i].Book = cloneBook(b)
+		page.Items[i].Title = highlight(b.Title(), q.positive, 0)
+		page.Items[i].Snippet = highlight(b.Description(), q.positive, snippetWords)
+	}
+	res.Hits = page
+	return res, nil
+}
+
+// newFilter arma el predicado de los filtros estructurados (sin Q).
+func newFilter(f domain.BookFilter, ratings map[uint64]float64) func(b *domain.Book) bool {
+	author := Fold(strings.TrimSpace(f.Author))
+	category := Fold(strings.TrimSpace(f.Category))
+	tag := Fold(strings.TrimSpace(f.Tag))
+
+	return func(b *domain.Book) bool {
+		if author != "" && !strings.Contains(Fold(b.Author()), author) {
+			return false
+		}
+		if category != "" && !strings.Contains(Fold(b.Category()), category) {
+			return false
+		}
+		if (f.YearFrom > 0 && b.Year() < f.YearFrom) || (f.YearTo > 0 && b.Year() > f.YearTo) {
+			return false
+		}
+		if f.MinRating > 0 && ratings[b.ID()] < f.MinRating {
+			return false
+		}
+		return tag == "" || hasTag(b, tag)
+	}
+}
+
+// hasTag compara etiquetas por slug (sin distinguir mayúsculas, acentos ni
+// separadores: "ciencia ficcion" = "Ciencia-Ficción"), como los repositorios.
+func hasTag(b *domain.Book, tag string) bool {
+	slug := domain.Slugify(tag)
+	for _, t := range b.Tags() {
+		if domain.Slugify(t) == slug {
+			return true
+		}
+	}
+	return false
+}
+
+// compiled es la consulta lista para evaluar: frecuencias de cada término de
+// texto (positivo o negado) y las cláusulas que puntúan y se resaltan.
+type compiled struct {
+	tfs      map[domain.TextTerm]map[uint64]float64 // nil = término sin palabras (no filtra)
+	positive []clause
+	idf      []float64 // idf de cada cláusula positiva
+	posTerms []domain.TextTerm
+	avgLen   float64
+}
+
+// compile calcula una vez por búsqueda lo que necesitan leaf y score. Requiere x.mu.
+func (x *Index) compile(expr domain.QueryExpr, fuzzy bool) *compiled {
+	q := &compiled{tfs: map[domain.TextTerm]map[uint64]float64{}, avgLen: 1}
+	n := float64(len(x.docs))
+	if n > 0 && x.totalLen > 0 {
+		q.avgLen = x.totalLen / n
+	}
+
+	var collect func(e domain.QueryExpr)
+	collect = func(e domain.QueryExpr) {
+		switch t := e.(type) {
+		case domain.AndExpr:
+			for _, c := range t.Terms {
+				collect(c)
+			}
+		case domain.OrExpr:
+			for _, c := range t.Terms {
+				collect(c)
+			}
+		case domain.NotExpr:
+			collect(t.Term)
+		case domain.TextTerm:
+			if _, done := q.tfs[t]; done {
+				return
+			}
+			if c, ok := textClause(t, fuzzy); ok {
+				q.tfs[t] = x.clauseTF(c)
+			} else {
+				q.tfs[t] = nil
+			}
+		}
+	}
+	collect(expr)
+
+	seen := map[domain.TextTerm]bool{}
+	for _, t := range domain.PositiveTextTerms(expr) {
+		c, ok := textClause(t, fuzzy)
+		if !ok || seen[t] {
+			continue
+		}
+		seen[t] = true
+		df := float64(len(q.tfs[t]))
+		q.positive = append(q.positive, c)
+		q.posTerms = append(q.posTerms, t)
+		q.idf = append(q.idf, math.Log(1+(n-df+0.5)/(df+0.5)))
+	}
+	return q
+}
+
+// leaf evalúa una hoja del AST para el libro id.
+func (q *compiled) leaf(id uint64, d *document, e domain.QueryExpr) bool {
+	b := d.book
+	switch t := e.(type) {
+	case domain.TextTerm:
+		tfs := q.tfs[t]
+		if tfs == nil {
+			return true
+		}
+		_, ok := tfs[id]
+		return ok
+	case domain.FieldTerm:
+		v := Fold(t.Value)
+		switch t.Field {
+		case domain.FieldAuthor:
+			return strings.Contains(Fold(b.Author()), v)
+		case domain.FieldTitle:
+			return strings.Contains(Fold(b.Title()), v)
+		case domain.FieldCategory:
+			return strings.Contains(Fold(b.Category()), v)
+		case domain.FieldTag:
+			return hasTag(b, v)
+		case domain.FieldISBN:
+			return Fold(b.ISBN()) == v
+		}
+	case domain.YearRange:
+		return (t.From == 0 || b.Year() >= t.From) && (t.To == 0 || b.Year() <= t.To)
+	}
+	return false
+}
+
+// score suma el BM25 de las cláusulas positivas que el libro contiene
+// (0 si la consulta no tiene texto libre).
+func (q *compiled) score(id uint64, d *document) float64 {
+	s := 0.0
+	for i, t := range q.posTerms {
+		tf, ok := q.tfs[t][id]
+		if !ok {
+			continue
+		}
+		norm := 1 - bm25B + bm25B*d.length/q.avgLen
+		s += q.idf[i] * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+	}
+	return s
+}
+
+// clauseTF devuelve la frecuencia ponderada por campo de la cláusula en cada
+// libro que la contiene.
+func (x *Index) clauseTF(c clause) map[uint64]float64 {
+	out := map[uint64]float64{}
+
+	// Términos candidatos: el exacto, la expansión del prefijo o los
+	// términos aproximados (con menos peso cuanto más lejanos)
+	last := c.terms[len(c.terms)-1]
+	expansions := []expansion{{term: last, weight: 1}}
+	switch {
+	case c.prefix:
+		expansions = expansions[:0]
+		for _, t := range x.expand(last) {
+			expansions = append(expansions, expansion{term: t, weight: 1})
+		}
+	case c.fuzzy:
+		expansions = x.fuzzyExpand(last)
+	}
+
+	if !c.phrase() {
+		for _, e := range expansions {
+			for id := range x.postings[e.term] {
+				out[id] += e.weight * termTF(x.docs[id], e.term)
+			}
+		}
+		return out
+	}
+
+	// Frase: candidatos = libros con todas sus palabras; luego se verifica el orden
+	first := x.postings[c.terms[0]]
+	for id := range first {
+		if d := x.docs[id]; d != nil {
+			if tf := phraseTF(d, c); tf > 0 {
+				out[id] = tf
+			}
+		}
+	}
+	return out
+}
+
+// expand lista los términos del vocabulario que empiezan por prefix.
+func (x *Index) expand(prefix string) []string {
+	i := sort.SearchStrings(x.vocab, prefix)
+	var out []string
+	for ; i < len(x.vocab) && len(out) < maxExpansions && strings.HasPrefix(x.vocab[i], prefix); i++ {
+		out = append(out, x.vocab[i])
+	}
+	return out
+}
+
+// sortVocab reconstruye el vocabulario ordenado si cambió. Requiere x.mu.
+func (x *Index) sortVocab() {
+	if !x.vocabDirty {
+		return
+	}
+	x.vocab = x.vocab[:0]
+	for t := range x.postings {
+		x.vocab = append(x.vocab, t)
+	}
+	sort.Strings(x.vocab)
+	x.vocabDirty = false
+}
+
+// termTF cuenta las apariciones de t ponderadas por campo.
+func termTF(d *document, t string) float64 {
+	tf := 0.0
+	for f, ts := range d.fields {
+		for _, w := range ts {
+			if w == t {
+				tf += fieldWeights[f]
+			}
+		}
+	}
+	return tf
+}
+
+// phraseTF cuenta las apariciones consecutivas de la frase ponderadas por campo.
+func phraseTF(d *document, c clause) float64 {
+	tf := 0.0
+	for f, ts := range d.fields {
+		for i := 0; i+len(c.terms) <= len(ts); i++ {
+			ok := true
+			for j, t := range c.terms {
+				if ts[i+j] != t {
+					ok = false
+					break
+				}
+			}
+			if ok {
+				tf += fieldWeights[f]
+			}
+		}
+	}
+	return tf
+}
+
+// hitSortFields replica el orden de los repositorios (sin distinguir
+// mayúsculas; el id desempata en la misma dirección).
+var hitSortFields = map[string]func(a, b domain.BookHit) int{
+	domain.SortRelevance: func(a, b domain.BookHit) int { return cmp.Compare(a.Score, b.Score) },
+	"title": func(a, b domain.BookHit) int {
+		return cmp.Compare(strings.ToLower(a.Book.Title()), strings.ToLower(b.Book.Title()))
+	},
+	"author": func(a, b domain.BookHit) int {
+		return cmp.Compare(strings.ToLower(a.Book.Author()), strings.ToLower(b.Book.Author()))
+	},
+	"year": func(a, b domain.BookHit) int { return cmp.Compare(a.Book.Year(), b.Book.Year()) },
+}
+
+// sortHits ordena por el campo pedido; rating usa los promedios del índice.
+func sortHits(hits []domain.BookHit, p domain.PageRequest, ratings map[uint64]float64) {
+	byField := hitSortFields[p.Sort]
+	if p.Sort == domain.SortRating {
+		byField = func(a, b domain.BookHit) int { return cmp.Compare(ratings[a.Book.ID()], ratings[b.Book.ID()]) }
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		c := 0
+		if byField != nil {
+			c = byField(hits[i], hits[j])
+		}
+		if c == 0 {
+			c = cmp.Compare(hits[i].Book.ID(), hits[j].Book.ID())
+		}
+		if p.Dir == domain.SortDesc {
+			return c > 0
+		}
+		return c < 0
+	})
+}
+
+// cloneBook evita que el llamador modifique el libro indexado.
+func cloneBook(b *domain.Book) *domain.Book {
+	c, _ := domain.HydrateBook(b.ID(), b.Title(), b.Author(), b.Year(), b.ISBN(), b.Category(),
+		b.Tags(), b.Description(), b.Active(), b.CreatedAt(), b.UpdatedAt())
+	return c
+}
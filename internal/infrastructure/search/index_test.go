@@ -0,0 +1,183 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+func book(t *testing.T, id uint64, title, author, category, tags, desc string) *domain.Book {
+	t.Helper()
+	now := time.Now()
+	b, err := domain.HydrateBook(id, title, author, 2000, "isbn-"+title, category, strings.Split(tags, ","), desc, true, now, now)
+	if err != nil {
+		t.Fatalf("hydrate: %v", err)
+	}
+	return b
+}
+
+func fixture(t *testing.T) *Index {
+	x := NewIndex()
+	x.Rebuild([]*domain.Book{
+		book(t, 1, "Cien años de soledad", "Gabriel García Márquez", "Novela", "realismo mágico",
+			"La historia de la familia Buendía en el pueblo de Macondo a lo largo de siete generaciones."),
+		book(t, 2, "El amor en los tiempos del cólera", "Gabriel García Márquez", "Novela", "romance",
+			"Florentino Ariza espera más de cincuenta años el amor de Fermina Daza."),
+		book(t, 3, "Rayuela", "Julio Cortázar", "Novela", "experimental",
+			"Una novela que puede leerse en varios órdenes; la soledad de Oliveira en París."),
+		book(t, 4, "The Go Programming Language", "Alan Donovan", "Programación", "golang",
+			"Guía completa del lenguaje Go."),
+	})
+	return x
+}
+
+func search(t *testing.T, x *Index, f domain.BookFilter) domain.Page[domain.BookHit] {
+	t.Helper()
+	res, err := x.Search(context.Background(), f)
+	if err != nil {
+		t.Fatalf("search %+v: %v", f, err)
+	}
+	return res.Hits
+}
+
+func hitIDs(page domain.Page[domain.BookHit]) []uint64 {
+	out := []uint64{}
+	for _, h := range page.Items {
+		out = append(out, h.Book.ID())
+	}
+	return out
+}
+
+func TestSearchMatching(t *testing.T) {
+	x := fixture(t)
+	cases := []struct {
+		q    string
+		want []uint64
+	}{
+		{"SOLEDAD", []uint64{1, 3}},           // título pesa más que descripción
+		{"colera", []uint64{2}},               // sin acentos en la consulta
+		{"Buendia macondo", []uint64{1}},      // AND de términos, descripción incluida
+		{"cortázar soledad", []uint64{3}},     // autor + descripción
+		{`"tiempos del colera"`, []uint64{2}}, // frase
+		{`"colera del tiempos"`, []uint64{}},  // frase fuera de orden
+		{"program*", []uint64{4}},             // prefijo
+		{"gabr* amor", []uint64{2}},
+		{"inexistente", []uint64{}},
+	}
+	for _, c := range cases {
+		if got := hitIDs(search(t, x, domain.BookFilter{Q: c.q, PageRequest: domain.PageRequest{Sort: domain.SortRelevance}})); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("q=%q: expected %v, got %v", c.q, c.want, got)
+		}
+	}
+}
+
+func TestSearchQueryLanguage(t *testing.T) {
+	x := fixture(t)
+	cases := []struct {
+		q    string
+		want []uint64
+	}{
+		{"author:marquez", []uint64{2, 1}},              // campo sin acentos
+		{"author:marquez -tag:romance", []uint64{1}},    // negación de campo
+		{"soledad -cortazar", []uint64{1}},              // negación de texto
+		{"tag:golang OR rayuela", []uint64{4, 3}},       // OR
+		{"category:novela year:2000 amor", []uint64{2}}, // campos + texto
+		{"year:2001..", []uint64{}},
+	}
+	for _, c := range cases {
+		if got := hitIDs(search(t, x, domain.BookFilter{Q: c.q})); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("q=%q: expected %v, got %v", c.q, c.want, got)
+		}
+	}
+
+	// Lo negado no puntúa ni se resalta
+	hit := search(t, x, domain.BookFilter{Q: "soledad -buendia"})
+	if hit.Total != 1 || hit.Items[0].Book.ID() != 3 {
+		t.Fatalf("expected only Rayuela, got %v", hitIDs(hit))
+	}
+	only := search(t, x, domain.BookFilter{Q: "soledad"}) // id desc: Rayuela primero
+	if hit.Items[0].Score != only.Items[0].Score {
+		t.Fatalf("negated terms must not change the score: %v vs %v", hit.Items[0].Score, only.Items[0].Score)
+	}
+
+	if _, err := x.Search(context.Background(), domain.BookFilter{Q: "year:sesenta"}); err == nil {
+		t.Fatalf("expected validation error for an invalid year")
+	}
+}
+
+func TestSearchFiltersSortAndPaging(t *testing.T) {
+	x := fixture(t)
+
+	got := search(t, x, domain.BookFilter{Q: "novela", Author: "MARQUEZ"})
+	if got.Total != 2 || got.Items[0].Score <= 0 {
+		t.Fatalf("expected 2 scored hits, got %+v", got)
+	}
+
+	got = search(t, x, domain.BookFilter{Category: "novela", PageRequest: domain.PageRequest{Sort: "title", PageSize: 2, Page: 2}})
+	if got.Total != 3 || !reflect.DeepEqual(hitIDs(got), []uint64{3}) {
+		t.Fatalf("expected page 2 = [3] of 3, got %v of %d", hitIDs(got), got.Total)
+	}
+
+	if _, err := x.Search(context.Background(), domain.BookFilter{PageRequest: domain.PageRequest{Sort: "isbn"}}); err == nil {
+		t.Fatalf("expected validation error for unknown sort")
+	}
+	huge := domain.PageRequest{Page: math.MaxInt, PageSize: domain.MaxPageSize}
+	if _, err := x.Search(context.Background(), domain.BookFilter{Q: "novela", PageRequest: huge}); !errors.Is(err, domain.ErrValidation) {
+		t.Fatalf("expected validation error for a huge page, got %v", err)
+	}
+}
+
+func TestIndexUpdateAndRemove(t *testing.T) {
+	x := fixture(t)
+
+	x.Index(book(t, 4, "Concurrencia en Go", "Katherine Cox-Buday", "Programación", "golang", ""))
+	if got := hitIDs(search(t, x, domain.BookFilter{Q: "donovan"})); len(got) != 0 {
+		t.Fatalf("old terms must be gone after reindex, got %v", got)
+	}
+	if got := hitIDs(search(t, x, domain.BookFilter{Q: "concurr*"})); !reflect.DeepEqual(got, []uint64{4}) {
+		t.Fatalf("new terms must be indexed, got %v", got)
+	}
+
+	x.Remove(4)
+	if got := search(t, x, domain.BookFilter{}); got.Total != 3 {
+		t.Fatalf("expected 3 books after remove, got %d", got.Total)
+	}
+}
+
+func TestSnippetHighlights(t *testing.T) {
+	x := fixture(t)
+	hit := search(t, x, domain.BookFilter{Q: "buendia"}).Items[0]
+
+	var marked []string
+	var text strings.Builder
+	for _, f := range hit.Snippet {
+		text.WriteString(f.Text)
+		if f.Match {
+			marked = append(marked, f.Text)
+		}
+	}
+	if !reflect.DeepEqual(marked, []string{"Buendía"}) {
+		t.Fatalf("expected original-cased match, got %v", marked)
+	}
+	if !strings.HasPrefix(text.String(), "La historia") || !strings.HasSuffix(text.String(), "generaciones.") {
+		t.Fatalf("short descriptions must be kept whole, got %q", text.String())
+	}
+
+	long := strings.Repeat("palabra ", 50) + "objetivo " + strings.Repeat("relleno ", 50)
+	frags := highlight(long, []clause{{terms: []string{"objetivo"}}}, snippetWords)
+	if frags[0].Text[:len("…")] != "…" || !strings.HasSuffix(frags[len(frags)-1].Text, "…") {
+		t.Fatalf("long snippets must be cut with ellipsis: %+v", frags)
+	}
+}
+
+func TestFold(t *testing.T) {
+	if got := Fold("Canción ÑANDÚ Über"); got != "cancion nandu uber" {
+		t.Fatalf("fold: got %q", got)
+	}
+}
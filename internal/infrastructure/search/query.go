@@ -0,0 +1,56 @@
+package search
+
+import (
+	"strings"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+// clause es un término de texto libre de la consulta ya normalizado
+// (la sintaxis la resuelve domain.ParseQuery):
+//
+//	cien          término
+//	sol*          prefijo (soledad, solar, ...)
+//	"cien años"   frase: términos consecutivos en el mismo campo
+type clause struct {
+	terms  []string // términos normalizados (más de uno solo en frases)
+	prefix bool     // el último término es un prefijo
+	fuzzy  bool     // término suelto que tolera errores de tipeo
+}
+
+// textClause convierte un TextTerm del AST en cláusula. Una palabra que se
+// parte en varios términos ("pre-venta") se trata como frase (salvo un ISBN
+// válido, que se busca en su forma canónica). Devuelve false
+// si no queda ningún término (signos sueltos): ese término no filtra.
+// fuzzy solo aplica a palabras sueltas (no a frases ni prefijos).
+func textClause(t domain.TextTerm, fuzzy bool) (clause, bool) {
+	// Un ISBN escrito con guiones o en su forma ISBN-10 busca la canónica
+	if isbn, err := domain.ParseISBN(t.Text); err == nil && !t.Prefix {
+		return clause{terms: []string{isbn}}, true
+	}
+	ts := terms(t.Text)
+	if len(ts) == 0 {
+		return clause{}, false
+	}
+	c := clause{terms: ts, prefix: t.Prefix}
+	c.fuzzy = fuzzy && !c.prefix && !c.phrase()
+	return c, true
+}
+
+// phrase indica si la cláusula exige términos consecutivos.
+func (c clause) phrase() bool { return len(c.terms) > 1 }
+
+// matches indica si un término del documento satisface esta cláusula
+// (se usa para resaltar: en frases basta con ser una de sus palabras).
+func (c clause) matches(term string) bool {
+	for i, t := range c.terms {
+		if term == t || (c.prefix && i == len(c.terms)-1 && strings.HasPrefix(term, t)) {
+			return true
+		}
+	}
+	if c.fuzzy {
+		edits := maxEdits(c.terms[0])
+		return editDistance(term, c.terms[0], edits) <= edits
+	}
+	return false
+}
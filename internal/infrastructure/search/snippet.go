@@ -0,0 +1,76 @@
+package search
+
+import "github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+
+const (
+	// snippetWords es la cantidad de palabras del extracto de la descripción.
+	snippetWords = 30
+	// snippetLead son las palabras de contexto antes de la primera coincidencia.
+	snippetLead = 8
+)
+
+// highlight parte text en fragmentos marcando las palabras que coinciden con
+// alguna cláusula. Con maxWords > 0 recorta una ventana alrededor de la
+// primera coincidencia (o el inicio del texto) y agrega "…" en los cortes.
+func highlight(text string, clauses []clause, maxWords int) []domain.Fragment {
+	toks := tokenize(text)
+	if len(toks) == 0 {
+		return nil
+	}
+
+	match := make([]bool, len(toks))
+	first := -1
+	for i, t := range toks {
+		for _, c := range clauses {
+			if c.matches(t.term) {
+				match[i] = true
+				break
+			}
+		}
+		if match[i] && first < 0 {
+			first = i
+		}
+	}
+
+	from, to := 0, len(toks)
+	if maxWords > 0 && len(toks) > maxWords {
+		from = max(0, first-snippetLead)
+		to = min(len(toks), from+maxWords)
+		from = max(0, to-maxWords) // ventana completa aunque la coincidencia esté al final
+	}
+
+	var out []domain.Fragment
+	push := func(s string, m bool) {
+		if s == "" {
+			return
+		}
+		if n := len(out); n > 0 && out[n-1].Match == m && !m {
+			out[n-1].Text += s
+			return
+		}
+		out = append(out, domain.Fragment{Text: s, Match: m})
+	}
+
+	start := toks[from].start
+	end := toks[to-1].end
+	if to == len(toks) {
+		end = len(text)
+	}
+	if from > 0 {
+		push("… ", false)
+	} else {
+		start = 0
+	}
+
+	pos := start
+	for i := from; i < to; i++ {
+		push(text[pos:toks[i].start], false)
+		push(text[toks[i].start:toks[i].end], match[i])
+		pos = toks[i].end
+	}
+	push(text[pos:end], false)
+	if to < len(toks) {
+		push(" …", false)
+	}
+	return out
+}
@@ -0,0 +1,124 @@
+package search
+
+import (
+	"cmp"
+	"context"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+// Rango de una sugerencia (menor = mejor).
+const (
+	rankStart  = iota // el texto empieza por el prefijo
+	rankWord          // alguna palabra del texto empieza por el prefijo
+	rankApprox        // alguna palabra se parece al prefijo (errores de tipeo)
+	rankNone
+)
+
+// Suggest completa títulos y autores que empiezan por prefix (o tienen una
+// palabra que empieza por él), sin distinguir mayúsculas ni acentos. Si el
+// prefijo tiene errores de tipeo ("cervantez") también propone los parecidos,
+// después de los exactos. Ordena por rango, cantidad de libros y largo.
+func (x *Index) Suggest(ctx context.Context, prefix string, limit int) ([]domain.Suggestion, error) {
+	p := Fold(strings.TrimSpace(prefix))
+	out := []domain.Suggestion{}
+	if p == "" || limit <= 0 {
+		return out, nil
+	}
+
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	// Títulos y autores agrupados sin distinguir mayúsculas ni acentos
+	titles, authors := newCounter(), newCounter()
+	newest := map[string]uint64{} // título plegado -> libro más reciente
+	for id, d := range x.docs {
+		titles.add(d.book.Title())
+		authors.add(d.book.Author())
+		if k := Fold(strings.TrimSpace(d.book.Title())); id > newest[k] {
+			newest[k] = id
+		}
+	}
+
+	type candidate struct {
+		domain.Suggestion
+		rank int
+	}
+	var list []candidate
+	collect := func(kind domain.SuggestionKind, c *counter) {
+		for _, fc := range c.list() {
+			k := Fold(fc.Value)
+			rank := suggestRank(k, p)
+			if rank == rankNone {
+				continue
+			}
+			s := domain.Suggestion{Text: fc.Value, Kind: kind, Count: fc.Count}
+			if kind == domain.SuggestTitle {
+				s.BookID = newest[k]
+			}
+			list = append(list, candidate{s, rank})
+		}
+	}
+	collect(domain.SuggestTitle, titles)
+	collect(domain.SuggestAuthor, authors)
+
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if c := cmp.Compare(a.rank, b.rank); c != 0 {
+			return c < 0
+		}
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c < 0
+		}
+		if c := cmp.Compare(utf8.RuneCountInString(a.Text), utf8.RuneCountInString(b.Text)); c != 0 {
+			return c < 0
+		}
+		if c := cmp.Compare(strings.ToLower(a.Text), strings.ToLower(b.Text)); c != 0 {
+			return c < 0
+		}
+		return a.Kind < b.Kind
+	})
+	for _, c := range list[:min(limit, len(list))] {
+		out = append(out, c.Suggestion)
+	}
+	return out, nil
+}
+
+// suggestRank clasifica text (plegado) frente al prefijo p (plegado).
+func suggestRank(text, p string) int {
+	if strings.HasPrefix(text, p) {
+		return rankStart
+	}
+
+	// Inicio de cada palabra: "soledad" completa "Cien años de soledad"
+	var starts []int
+	for i := range text {
+		if i > 0 && text[i-1] == ' ' && text[i] != ' ' {
+			starts = append(starts, i)
+		}
+	}
+	for _, i := range starts {
+		if strings.HasPrefix(text[i:], p) {
+			return rankWord
+		}
+	}
+
+	// Aproximado: lo escrito se compara con el mismo largo de cada palabra
+	edits := maxEdits(p)
+	if edits == 0 {
+		return rankNone
+	}
+	n := utf8.RuneCountInString(p)
+	for _, i := range append([]int{0}, starts...) {
+		r := []rune(text[i:])
+		for _, l := range []int{n - 1, n, n + 1} { // el error puede agregar o quitar letras
+			if l > 0 && l <= len(r) && editDistance(string(r[:l]), p, edits) <= edits {
+				return rankApprox
+			}
+		}
+	}
+	return rankNone
+}
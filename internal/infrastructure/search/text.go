@@ -0,0 +1,63 @@
+// Package search implementa un índice invertido de libros en memoria
+// (usecase.BookSearcher): ranking BM25 por campos, coincidencia sin acentos
+// ni mayúsculas, frases entre comillas, prefijos (term*) y fragmentos resaltados.
+//
+// El índice vive en el proceso: se reconstruye al arrancar desde el repositorio
+// y BookService lo mantiene al día en cada alta, edición o baja.
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// token es una palabra del texto original con su posición en bytes.
+type token struct {
+	term       string // forma normalizada (Fold)
+	start, end int    // rango en el texto original
+}
+
+// Fold normaliza un término para comparar: minúsculas y sin diacríticos
+// ("Canción" -> "cancion", "Ñandú" -> "nandu").
+func Fold(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	out, _, err := transform.String(t, s)
+	if err != nil {
+		out = s
+	}
+	return strings.ToLower(out)
+}
+
+// tokenize separa s en palabras (letras y dígitos) conservando sus posiciones.
+func tokenize(s string) []token {
+	var out []token
+	start := -1
+	for i, r := range s {
+		word := unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r)
+		switch {
+		case word && start < 0:
+			start = i
+		case !word && start >= 0:
+			out = append(out, token{term: Fold(s[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		out = append(out, token{term: Fold(s[start:]), start: start, end: len(s)})
+	}
+	return out
+}
+
+// terms devuelve solo los términos normalizados de s.
+func terms(s string) []string {
+	toks := tokenize(s)
+	out := make([]string, len(toks))
+	for i, t := range toks {
+		out[i] = t.term
+	}
+	return out
+}
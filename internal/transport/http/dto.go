@@ -2,9 +2,17 @@ package http // Paquete de transporte HTTP (DTOs para API)
 
 // Importaciones
 import (
-	"time" // time.Time para fechas de creación/actualización
+	"cmp"           // Títulos por defecto
+	"fmt"           // Duraciones legibles
+	"html"          // Escapado de los fragmentos resaltados
+	"html/template" // Capítulos del lector ya saneados
+	"math"          // Redondeo de promedios
+	"strconv"       // URLs de descarga y tamaños legibles
+	"strings"       // Armado de los fragmentos resaltados
+	"time"          // time.Time para fechas de creación/actualización
 
-	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain" // Entidades del dominio (User, Book, Role)
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"  // Entidades del dominio (User, Book, Role)
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/usecase" // Páginas del lector
 )
 
 // -------------------- USERS DTO --------------------
@@ -52,17 +60,22 @@ func usersToDTO(list []*domain.User) []UserDTO {
 // BookDTO es el objeto que se envía/recibe por la API (JSON) para libros.
 // Igual que UserDTO: separa dominio de transporte.
 type BookDTO struct {
-	ID          uint64    `json:"id"`          // ID del libro
-	Title       string    `json:"title"`       // Título
-	Author      string    `json:"author"`      // Autor
-	Year        int       `json:"year"`        // Año de publicación
-	ISBN        string    `json:"isbn"`        // Código ISBN
-	Category    string    `json:"category"`    // Categoría
-	Tags        []string  `json:"tags"`        // Etiquetas (slice)
-	Description string    `json:"description"` // Descripción
-	Active      bool      `json:"active"`      // Estado lógico
-	CreatedAt   time.Time `json:"created_at"`  // Fecha de creación
-	UpdatedAt   time.Time `json:"updated_at"`  // Fecha de actualización
+	ID          uint64    `json:"id"`           // ID del libro
+	Title       string    `json:"title"`        // Título
+	Author      string    `json:"author"`       // Autor
+	Year        int       `json:"year"`         // Año de publicación
+	ISBN        string    `json:"isbn"`         // ISBN-13 canónico (sin guiones)
+	ISBNDisplay string    `json:"isbn_display"` // ISBN con guiones para mostrar
+	Category    string    `json:"category"`     // Categoría
+	Tags        []string  `json:"tags"`         // Etiquetas (slice)
+	Description string    `json:"description"`  // Descripción
+	Active      bool      `json:"active"`       // Estado lógico
+	CreatedAt   time.Time `json:"created_at"`   // Fecha de creación
+	UpdatedAt   time.Time `json:"updated_at"`   // Fecha de actualización
+
+	Authors []CreditDTO       `json:"authors,omitempty"` // Créditos (solo en el detalle)
+	Cover   *CoverDTO         `json:"cover,omitempty"`   // Portada (nil si no tiene)
+	Rating  *RatingSummaryDTO `json:"rating,omitempty"`  // Valoración (nil sin reseñas aprobadas)
 }
 
 // bookToDTO convierte la entidad domain.Book a BookDTO.
@@ -74,6 +87,7 @@ func bookToDTO(b *domain.Book) BookDTO {
 		Author:      b.Author(),      // Getter
 		Year:        b.Year(),        // Getter
 		ISBN:        b.ISBN(),        // Getter
+		ISBNDisplay: b.ISBNDisplay(), // Formato con guiones
 		Category:    b.Category(),    // Getter
 		Tags:        b.Tags(),        // Getter (slice)
 		Description: b.Description(), // Getter
@@ -93,6 +107,857 @@ func booksToDTO(list []*domain.Book) []BookDTO {
 	return out // devuelve DTOs listos para writeJSON
 }
 
+// -------------------- AUTHORS DTO --------------------
+
+// AuthorDTO expone un autor por la API.
+type AuthorDTO struct {
+	ID        uint64    `json:"id"`
+	Name      string    `json:"name"`
+	Bio       string    `json:"bio"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// authorToDTO convierte la entidad a DTO.
+func authorToDTO(a *domain.Author) AuthorDTO {
+	return AuthorDTO{
+		ID:        a.ID(),
+		Name:      a.Name(),
+		Bio:       a.Bio(),
+		CreatedAt: a.CreatedAt(),
+		UpdatedAt: a.UpdatedAt(),
+	}
+}
+
+// authorsToDTO convierte un slice de autores.
+func authorsToDTO(list []*domain.Author) []AuthorDTO {
+	out := make([]AuthorDTO, 0, len(list))
+	for _, a := range list {
+		out = append(out, authorToDTO(a))
+	}
+	return out
+}
+
+// CreditDTO es la participación de un autor en un libro.
+type CreditDTO struct {
+	AuthorID uint64            `json:"author_id"`
+	Name     string            `json:"name"`
+	Role     domain.CreditRole `json:"role"`
+	Position int               `json:"position"`
+}
+
+// creditsToDTO convierte los créditos de un libro (en orden).
+func creditsToDTO(list []domain.BookCredit) []CreditDTO {
+	out := make([]CreditDTO, 0, len(list))
+	for _, c := range list {
+		out = append(out, CreditDTO{AuthorID: c.AuthorID, Name: c.Name, Role: c.Role, Position: c.Position})
+	}
+	return out
+}
+
+// RelationDTO es una relación entre libros. En el listado de un libro, book_*
+// describe el otro libro e inverse indica que el libro actual es el destino.
+type RelationDTO struct {
+	ID            uint64              `json:"id"`
+	Type          domain.RelationType `json:"type"`
+	FromBookID    uint64              `json:"from_book_id"`
+	ToBookID      uint64              `json:"to_book_id"`
+	Bidirectional bool                `json:"bidirectional"`
+	Inverse       bool                `json:"inverse"`
+	BookID        uint64              `json:"book_id,omitempty"`
+	Title         string              `json:"title,omitempty"`
+	Author        string              `json:"author,omitempty"`
+	Year          int                 `json:"year,omitempty"`
+}
+
+// relationToDTO convierte una relación (sin datos del otro libro).
+func relationToDTO(r *domain.BookRelation) RelationDTO {
+	return RelationDTO{ID: r.ID(), Type: r.Type(), FromBookID: r.FromID(), ToBookID: r.ToID(), Bidirectional: r.Bidirectional()}
+}
+
+// relatedToDTO convierte las relaciones vistas desde un libro.
+func relatedToDTO(list []domain.RelatedBook) []RelationDTO {
+	out := make([]RelationDTO, 0, len(list))
+	for _, rb := range list {
+		dto := relationToDTO(rb.Relation)
+		dto.Inverse, dto.BookID, dto.Title, dto.Author, dto.Year = rb.Inverse, rb.BookID, rb.Title, rb.Author, rb.Year
+		out = append(out, dto)
+	}
+	return out
+}
+
+// CoverDTO describe la portada de un libro con las URLs de sus miniaturas.
+// Las URLs llevan ?v=<versión>: cambian al cambiar la imagen, así el
+// navegador puede guardarlas sin volver a preguntar.
+type CoverDTO struct {
+	ContentType string    `json:"content_type"`
+	Width       int       `json:"width"`
+	Height      int       `json:"height"`
+	Size        int64     `json:"size"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Small       string    `json:"small_url"`
+	Medium      string    `json:"medium_url"`
+	Large       string    `json:"large_url"`
+}
+
+func coverToDTO(c *domain.Cover) *CoverDTO {
+	url := func(size domain.CoverSize) string {
+		return "/covers/" + strconv.FormatUint(c.BookID(), 10) + "/" + string(size) + "?v=" + c.Version()
+	}
+	return &CoverDTO{
+		ContentType: c.ContentType(),
+		Width:       c.Width(),
+		Height:      c.Height(),
+		Size:        c.Size(),
+		UpdatedAt:   c.UpdatedAt(),
+		Small:       url(domain.CoverSmall),
+		Medium:      url(domain.CoverMedium),
+		Large:       url(domain.CoverLarge),
+	}
+}
+
+// FileDTO expone los metadatos de un adjunto; download_url sirve el
+// contenido (admite Range).
+type FileDTO struct {
+	ID          uint64          `json:"id"`
+	BookID      uint64          `json:"book_id"`
+	Name        string          `json:"name"`
+	ContentType string          `json:"content_type"`
+	Kind        domain.FileKind `json:"kind"`
+	Size        int64           `json:"size"`
+	SHA256      string          `json:"sha256"`
+	UploadedBy  uint64          `json:"uploaded_by,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	DownloadURL string          `json:"download_url"`
+
+	SizeText string `json:"-"` // tamaño legible para los templates
+}
+
+func fileToDTO(f *domain.BookFile) FileDTO {
+	return FileDTO{
+		ID:          f.ID(),
+		BookID:      f.BookID(),
+		Name:        f.Name(),
+		ContentType: f.ContentType(),
+		Kind:        f.Kind(),
+		Size:        f.Size(),
+		SHA256:      f.SHA256(),
+		UploadedBy:  f.UploadedBy(),
+		CreatedAt:   f.CreatedAt(),
+		DownloadURL: "/api/files/" + strconv.FormatUint(f.ID(), 10) + "/download",
+		SizeText:    sizeText(f.Size()),
+	}
+}
+
+func filesToDTO(list []*domain.BookFile) []FileDTO {
+	out := make([]FileDTO, 0, len(list))
+	for _, f := range list {
+		out = append(out, fileToDTO(f))
+	}
+	return out
+}
+
+// sizeText formatea bytes como "532 KB" o "12.4 MB".
+func sizeText(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return strconv.FormatFloat(float64(n)/(1<<20), 'f', 1, 64) + " MB"
+	case n >= 1<<10:
+		return strconv.FormatInt(n>>10, 10) + " KB"
+	}
+	return strconv.FormatInt(n, 10) + " B"
+}
+
+// AuthorBookDTO es un libro de un autor con su rol en él.
+type AuthorBookDTO struct {
+	BookDTO
+	Role domain.CreditRole `json:"role"`
+}
+
+// authorBooksToDTO convierte los libros de un autor.
+func authorBooksToDTO(list []domain.AuthorBook) []AuthorBookDTO {
+	out := make([]AuthorBookDTO, 0, len(list))
+	for _, ab := range list {
+		out = append(out, AuthorBookDTO{BookDTO: bookToDTO(ab.Book), Role: ab.Role})
+	}
+	return out
+}
+
+// -------------------- TAXONOMÍA DTO --------------------
+
+// TagDTO expone una etiqueta con la cantidad de libros que la usan.
+type TagDTO struct {
+	ID        uint64    `json:"id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	Books     int       `json:"books"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// tagsToDTO convierte un listado de etiquetas con uso.
+func tagsToDTO(list []domain.TagUsage) []TagDTO {
+	out := make([]TagDTO, 0, len(list))
+	for _, u := range list {
+		out = append(out, TagDTO{ID: u.Tag.ID(), Name: u.Tag.Name(), Slug: u.Tag.Slug(), Books: u.Books, CreatedAt: u.Tag.CreatedAt()})
+	}
+	return out
+}
+
+// CategoryDTO expone una categoría; en el árbol incluye conteos y subcategorías.
+type CategoryDTO struct {
+	ID         uint64        `json:"id"`
+	Name       string        `json:"name"`
+	Slug       string        `json:"slug"`
+	ParentID   uint64        `json:"parent_id,omitempty"`
+	Books      int           `json:"books"`       // libros asignados directamente
+	TotalBooks int           `json:"total_books"` // incluye subcategorías
+	Children   []CategoryDTO `json:"children"`
+}
+
+// categoryToDTO convierte una categoría suelta (sin conteos).
+func categoryToDTO(c *domain.Category) CategoryDTO {
+	return CategoryDTO{ID: c.ID(), Name: c.Name(), Slug: c.Slug(), ParentID: c.ParentID(), Children: []CategoryDTO{}}
+}
+
+// categoryTreeToDTO convierte el árbol recursivamente.
+func categoryTreeToDTO(nodes []*domain.CategoryNode) []CategoryDTO {
+	out := make([]CategoryDTO, 0, len(nodes))
+	for _, n := range nodes {
+		dto := categoryToDTO(n.Category)
+		dto.Books, dto.TotalBooks = n.Books, n.TotalBooks
+		dto.Children = categoryTreeToDTO(n.Children)
+		out = append(out, dto)
+	}
+	return out
+}
+
+// -------------------- CIRCULACIÓN DTO --------------------
+
+// CopyDTO expone un ejemplar físico o un puesto digital (kind).
+type CopyDTO struct {
+	ID        uint64            `json:"id"`
+	BookID    uint64            `json:"book_id"`
+	Kind      domain.CopyKind   `json:"kind"`
+	Barcode   string            `json:"barcode"`
+	Status    domain.CopyStatus `json:"status"`
+	Location  string            `json:"location"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// copiesToDTO convierte un slice de ejemplares.
+func copiesToDTO(list []*domain.Copy) []CopyDTO {
+	out := make([]CopyDTO, 0, len(list))
+	for _, c := range list {
+		out = append(out, CopyDTO{
+			ID: c.ID(), BookID: c.BookID(), Kind: c.Kind(), Barcode: c.Barcode(), Status: c.Status(),
+			Location: c.Location(), CreatedAt: c.CreatedAt(), UpdatedAt: c.UpdatedAt(),
+		})
+	}
+	return out
+}
+
+// LoanDTO expone un préstamo; en listados incluye código, título y usuario.
+type LoanDTO struct {
+	ID         uint64     `json:"id"`
+	CopyID     uint64     `json:"copy_id"`
+	BookID     uint64     `json:"book_id"`
+	UserID     uint64     `json:"user_id"`
+	Barcode    string     `json:"barcode,omitempty"`
+	Digital    bool       `json:"digital,omitempty"` // préstamo de un puesto digital
+	BookTitle  string     `json:"book_title,omitempty"`
+	UserName   string     `json:"user_name,omitempty"`
+	LoanedAt   time.Time  `json:"loaned_at"`
+	DueAt      time.Time  `json:"due_at"`
+	ReturnedAt *time.Time `json:"returned_at"` // nil => sin devolver
+	Renewals   int        `json:"renewals"`
+	Overdue    bool       `json:"overdue"`
+}
+
+// loanToDTO convierte un préstamo (overdue se calcula contra now).
+func loanToDTO(l *domain.Loan, now time.Time) LoanDTO {
+	return LoanDTO{
+		ID:         l.ID(),
+		CopyID:     l.CopyID(),
+		BookID:     l.BookID(),
+		UserID:     l.UserID(),
+		LoanedAt:   l.LoanedAt(),
+		DueAt:      l.DueAt(),
+		ReturnedAt: timePtr(l.ReturnedAt()),
+		Renewals:   l.Renewals(),
+		Overdue:    l.Overdue(now),
+	}
+}
+
+// loansToDTO convierte un listado de préstamos con detalle.
+func loansToDTO(list []domain.LoanDetail, now time.Time) []LoanDTO {
+	out := make([]LoanDTO, 0, len(list))
+	for _, d := range list {
+		dto := loanToDTO(d.Loan, now)
+		dto.Barcode, dto.Digital, dto.BookTitle, dto.UserName = d.Barcode, d.Digital, d.BookTitle, d.UserName
+		out = append(out, dto)
+	}
+	return out
+}
+
+// HoldDTO expone una reserva; position es el lugar en la cola (0 si no espera).
+type HoldDTO struct {
+	ID        uint64     `json:"id"`
+	BookID    uint64     `json:"book_id"`
+	UserID    uint64     `json:"user_id"`
+	CopyID    uint64     `json:"copy_id,omitempty"`
+	Barcode   string     `json:"barcode,omitempty"`
+	Digital   bool       `json:"digital,omitempty"` // lo apartado es un puesto digital
+	BookTitle string     `json:"book_title,omitempty"`
+	UserName  string     `json:"user_name,omitempty"`
+	Status    string     `json:"status"`
+	Position  int        `json:"position,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	ReadyAt   *time.Time `json:"ready_at"`   // nil => sin ejemplar apartado
+	ExpiresAt *time.Time `json:"expires_at"` // límite para retirarlo
+	ClosedAt  *time.Time `json:"closed_at"`  // nil => activa
+}
+
+// holdToDTO convierte una reserva.
+func holdToDTO(h *domain.Hold) HoldDTO {
+	return HoldDTO{
+		ID:        h.ID(),
+		BookID:    h.BookID(),
+		UserID:    h.UserID(),
+		CopyID:    h.CopyID(),
+		Status:    string(h.Status()),
+		CreatedAt: h.CreatedAt(),
+		ReadyAt:   timePtr(h.ReadyAt()),
+		ExpiresAt: timePtr(h.ExpiresAt()),
+		ClosedAt:  timePtr(h.ClosedAt()),
+	}
+}
+
+// holdsToDTO convierte un listado de reservas con detalle.
+func holdsToDTO(list []domain.HoldDetail) []HoldDTO {
+	out := make([]HoldDTO, 0, len(list))
+	for _, d := range list {
+		dto := holdToDTO(d.Hold)
+		dto.Position, dto.Barcode, dto.Digital, dto.BookTitle, dto.UserName = d.Position, d.Barcode, d.Digital, d.BookTitle, d.UserName
+		out = append(out, dto)
+	}
+	return out
+}
+
+// AvailabilityDTO resume ejemplares, puestos digitales y cola de un libro.
+type AvailabilityDTO struct {
+	BookID    uint64 `json:"book_id"`
+	Copies    int    `json:"copies"`
+	Seats     int    `json:"seats"`
+	Available int    `json:"available"`
+	OnLoan    int    `json:"on_loan"`
+	OnHold    int    `json:"on_hold"`
+	Waiting   int    `json:"waiting"`
+}
+
+func availabilityToDTO(bookID uint64, a domain.Availability) AvailabilityDTO {
+	return AvailabilityDTO{BookID: bookID, Copies: a.Copies, Seats: a.Seats, Available: a.Available, OnLoan: a.OnLoan, OnHold: a.OnHold, Waiting: a.Waiting}
+}
+
+// -------------------- PROGRESO DE LECTURA DTO --------------------
+
+// ProgressDTO expone por dónde va un lector en un libro.
+type ProgressDTO struct {
+	BookID     uint64     `json:"book_id"`
+	UserID     uint64     `json:"user_id"`
+	BookTitle  string     `json:"book_title,omitempty"`
+	BookAuthor string     `json:"book_author,omitempty"`
+	UserName   string     `json:"user_name,omitempty"`
+	Page       int        `json:"page"`
+	TotalPages int        `json:"total_pages"`
+	Percent    float64    `json:"percent"`
+	Location   string     `json:"location,omitempty"` // EPUB CFI u otra ancla
+	Finished   bool       `json:"finished"`
+	StartedAt  time.Time  `json:"started_at"`
+	LastReadAt time.Time  `json:"last_read_at"`
+	FinishedAt *time.Time `json:"finished_at"` // nil => sin terminar
+}
+
+func progressToDTO(p *domain.ReadingProgress) ProgressDTO {
+	return ProgressDTO{
+		BookID:     p.BookID(),
+		UserID:     p.UserID(),
+		Page:       p.Page(),
+		TotalPages: p.TotalPages(),
+		Percent:    p.Percent(),
+		Location:   p.Location(),
+		Finished:   p.Finished(),
+		StartedAt:  p.StartedAt(),
+		LastReadAt: p.LastReadAt(),
+		FinishedAt: timePtr(p.FinishedAt()),
+	}
+}
+
+// progressListToDTO convierte un listado de progresos con detalle.
+func progressListToDTO(list []domain.ProgressDetail) []ProgressDTO {
+	out := make([]ProgressDTO, 0, len(list))
+	for _, d := range list {
+		dto := progressToDTO(d.Progress)
+		dto.BookTitle, dto.BookAuthor, dto.UserName = d.BookTitle, d.BookAuthor, d.UserName
+		out = append(out, dto)
+	}
+	return out
+}
+
+// BookCompletionDTO resume lectores y finalización de un libro.
+type BookCompletionDTO struct {
+	BookID     uint64  `json:"book_id"`
+	Title      string  `json:"title"`
+	Author     string  `json:"author"`
+	Readers    int     `json:"readers"`
+	Finished   int     `json:"finished"`
+	Rate       float64 `json:"completion_rate"` // % de lectores que lo terminaron
+	AvgPercent float64 `json:"avg_percent"`
+}
+
+func completionToDTO(list []domain.BookCompletion) []BookCompletionDTO {
+	out := make([]BookCompletionDTO, 0, len(list))
+	for _, c := range list {
+		out = append(out, BookCompletionDTO{
+			BookID: c.BookID, Title: c.Title, Author: c.Author, Readers: c.Readers, Finished: c.Finished,
+			Rate: c.Rate(), AvgPercent: math.Round(c.AvgPercent*10) / 10,
+		})
+	}
+	return out
+}
+
+// -------------------- LECTOR DTO --------------------
+
+// ReaderDTO es una página del lector integrado (solo para templates).
+type ReaderDTO struct {
+	BookID   uint64
+	Title    string
+	Kind     domain.FileKind // epub o pdf
+	At       int             // capítulo o página actual, desde 1
+	Total    int             // capítulos o páginas (0 si no se conocen)
+	Prev     int             // capítulo anterior (0 si no hay)
+	Next     int             // capítulo siguiente (0 si no hay)
+	Chapters []ReaderChapterDTO
+	Content  template.HTML // el EbookReader ya lo saneó: se incrusta tal cual
+	Location string        // ancla de lo que se muestra, para anotar
+	Progress *ProgressDTO
+}
+
+// ReaderChapterDTO es una entrada del índice de capítulos.
+type ReaderChapterDTO struct {
+	Number int
+	Title  string
+}
+
+func readerToDTO(p *usecase.ReaderPage) ReaderDTO {
+	dto := ReaderDTO{
+		BookID:  p.Book.ID(),
+		Title:   p.Book.Title(),
+		Kind:    p.File.Kind(),
+		At:      p.At,
+		Total:   p.Total,
+		Content: template.HTML(p.HTML),
+	}
+	for i, ch := range p.Outline.Chapters {
+		dto.Chapters = append(dto.Chapters, ReaderChapterDTO{Number: i + 1, Title: cmp.Or(ch.Title, fmt.Sprintf("Capítulo %d", i+1))})
+	}
+	dto.Location = fmt.Sprintf("página %d", p.At)
+	if len(dto.Chapters) > 0 {
+		dto.Location = p.Outline.ChapterCFI(p.At - 1)
+		if p.At > 1 {
+			dto.Prev = p.At - 1
+		}
+		if p.At < len(dto.Chapters) {
+			dto.Next = p.At + 1
+		}
+	}
+	if p.Progress != nil {
+		pr := progressToDTO(p.Progress)
+		dto.Progress = &pr
+	}
+	return dto
+}
+
+// ReadingTimeDTO resume las sesiones del lector integrado en un libro.
+type ReadingTimeDTO struct {
+	Sessions     int    `json:"sessions"`
+	TotalSeconds int64  `json:"total_seconds"`
+	TotalText    string `json:"-"` // "1 h 05 min" para los templates
+}
+
+func readingTimeToDTO(rt domain.ReadingTime) ReadingTimeDTO {
+	total := rt.Total.Round(time.Minute)
+	text := fmt.Sprintf("%d min", int(total.Minutes()))
+	if total >= time.Hour {
+		text = fmt.Sprintf("%d h %02d min", int(total.Hours()), int(total.Minutes())%60)
+	}
+	return ReadingTimeDTO{Sessions: rt.Sessions, TotalSeconds: int64(rt.Total / time.Second), TotalText: text}
+}
+
+// -------------------- ANOTACIONES DTO --------------------
+
+// AnnotationDTO expone un subrayado, marcador o nota.
+type AnnotationDTO struct {
+	ID         uint64    `json:"id"`
+	BookID     uint64    `json:"book_id"`
+	UserID     uint64    `json:"user_id"`
+	BookTitle  string    `json:"book_title,omitempty"`
+	UserName   string    `json:"user_name,omitempty"`
+	Kind       string    `json:"kind"` // bookmark, highlight o note
+	Location   string    `json:"location"`
+	Quote      string    `json:"quote,omitempty"`
+	Note       string    `json:"note,omitempty"`
+	Color      string    `json:"color"`
+	Visibility string    `json:"visibility"` // private, class o public
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+
+	KindText       string `json:"-"` // "Nota", "Subrayado"... para templates y Markdown
+	ColorText      string `json:"-"`
+	VisibilityText string `json:"-"`
+}
+
+// Textos de los tipos y visibilidades de anotación.
+var (
+	annotationKindText = map[string]string{
+		domain.AnnotationBookmark:  "Marcador",
+		domain.AnnotationHighlight: "Subrayado",
+		domain.AnnotationNote:      "Nota",
+	}
+	annotationColorText = map[domain.AnnotationColor]string{
+		domain.ColorYellow: "Amarillo",
+		domain.ColorGreen:  "Verde",
+		domain.ColorBlue:   "Azul",
+		domain.ColorPink:   "Rosa",
+		domain.ColorPurple: "Violeta",
+	}
+	annotationVisibilityText = map[domain.AnnotationVisibility]string{
+		domain.AnnotationPrivate: "Privada",
+		domain.AnnotationClass:   "Clase",
+		domain.AnnotationPublic:  "Pública",
+	}
+)
+
+// OptionDTO es una opción de un <select> (valor y texto a mostrar).
+type OptionDTO struct {
+	Value string
+	Text  string
+}
+
+// annotationColorOptions lista los colores para los formularios.
+func annotationColorOptions() []OptionDTO {
+	out := make([]OptionDTO, 0, len(domain.AnnotationColors))
+	for _, c := range domain.AnnotationColors {
+		out = append(out, OptionDTO{Value: string(c), Text: annotationColorText[c]})
+	}
+	return out
+}
+
+// annotationVisibilityOptions lista las visibilidades para los formularios.
+func annotationVisibilityOptions() []OptionDTO {
+	out := make([]OptionDTO, 0, len(domain.AnnotationVisibilities))
+	for _, v := range domain.AnnotationVisibilities {
+		out = append(out, OptionDTO{Value: string(v), Text: annotationVisibilityText[v]})
+	}
+	return out
+}
+
+func annotationToDTO(a *domain.Annotation) AnnotationDTO {
+	return AnnotationDTO{
+		ID:         a.ID(),
+		BookID:     a.BookID(),
+		UserID:     a.UserID(),
+		Kind:       a.Kind(),
+		Location:   a.Location(),
+		Quote:      a.Quote(),
+		Note:       a.Note(),
+		Color:      string(a.Color()),
+		Visibility: string(a.Visibility()),
+		CreatedAt:  a.CreatedAt(),
+		UpdatedAt:  a.UpdatedAt(),
+
+		KindText:       annotationKindText[a.Kind()],
+		ColorText:      annotationColorText[a.Color()],
+		VisibilityText: annotationVisibilityText[a.Visibility()],
+	}
+}
+
+// annotationListToDTO convierte un listado de anotaciones con detalle.
+func annotationListToDTO(list []domain.AnnotationDetail) []AnnotationDTO {
+	out := make([]AnnotationDTO, 0, len(list))
+	for _, d := range list {
+		dto := annotationToDTO(d.Annotation)
+		dto.BookTitle, dto.UserName = d.BookTitle, d.UserName
+		out = append(out, dto)
+	}
+	return out
+}
+
+// AnnotationExportDTO es la exportación JSON de las anotaciones de un usuario.
+type AnnotationExportDTO struct {
+	User        UserDTO         `json:"user"`
+	Shared      bool            `json:"shared_only"` // true si solo incluye las compartidas
+	ExportedAt  time.Time       `json:"exported_at"`
+	Annotations []AnnotationDTO `json:"annotations"`
+}
+
+// -------------------- RESEÑAS DTO --------------------
+
+// ReviewDTO expone una reseña (estrellas, texto y estado de moderación).
+type ReviewDTO struct {
+	ID          uint64     `json:"id"`
+	BookID      uint64     `json:"book_id"`
+	UserID      uint64     `json:"user_id"`
+	BookTitle   string     `json:"book_title,omitempty"`
+	UserName    string     `json:"user_name,omitempty"`
+	Rating      int        `json:"rating"` // 1 a 5
+	Text        string     `json:"text"`
+	Status      string     `json:"status"` // pending, approved o hidden
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	ModeratedAt *time.Time `json:"moderated_at"` // nil => nunca moderada
+
+	Stars      string `json:"-"` // "★★★★☆" para los templates
+	StatusText string `json:"-"`
+	Edited     bool   `json:"-"` // editada después del alta
+}
+
+// reviewStatusText son los textos de los estados de moderación.
+var reviewStatusText = map[domain.ReviewStatus]string{
+	domain.ReviewPending:  "Pendiente",
+	domain.ReviewApproved: "Aprobada",
+	domain.ReviewHidden:   "Oculta",
+}
+
+// reviewStatusOptions lista los estados para los filtros.
+func reviewStatusOptions() []OptionDTO {
+	out := make([]OptionDTO, 0, len(domain.ReviewStatuses))
+	for _, st := range domain.ReviewStatuses {
+		out = append(out, OptionDTO{Value: string(st), Text: reviewStatusText[st]})
+	}
+	return out
+}
+
+func reviewToDTO(rv *domain.Review) ReviewDTO {
+	return ReviewDTO{
+		ID:          rv.ID(),
+		BookID:      rv.BookID(),
+		UserID:      rv.UserID(),
+		Rating:      rv.Rating(),
+		Text:        rv.Text(),
+		Status:      string(rv.Status()),
+		CreatedAt:   rv.CreatedAt(),
+		UpdatedAt:   rv.UpdatedAt(),
+		ModeratedAt: timePtr(rv.ModeratedAt()),
+
+		Stars:      stars(rv.Rating()),
+		StatusText: reviewStatusText[rv.Status()],
+		Edited:     rv.UpdatedAt().Sub(rv.CreatedAt()) > time.Second,
+	}
+}
+
+// reviewListToDTO convierte un listado de reseñas con detalle.
+func reviewListToDTO(list []domain.ReviewDetail) []ReviewDTO {
+	out := make([]ReviewDTO, 0, len(list))
+	for _, d := range list {
+		dto := reviewToDTO(d.Review)
+		dto.BookTitle, dto.UserName = d.BookTitle, d.UserName
+		out = append(out, dto)
+	}
+	return out
+}
+
+// ReviewRevisionDTO es una versión anterior de una reseña.
+type ReviewRevisionDTO struct {
+	Rating    int       `json:"rating"`
+	Text      string    `json:"text"`
+	WrittenAt time.Time `json:"written_at"`
+
+	Stars string `json:"-"`
+}
+
+func revisionsToDTO(list []domain.ReviewRevision) []ReviewRevisionDTO {
+	out := make([]ReviewRevisionDTO, 0, len(list))
+	for _, rev := range list {
+		out = append(out, ReviewRevisionDTO{Rating: rev.Rating, Text: rev.Text, WrittenAt: rev.WrittenAt, Stars: stars(rev.Rating)})
+	}
+	return out
+}
+
+// RatingSummaryDTO resume la valoración de un libro (solo reseñas aprobadas).
+type RatingSummaryDTO struct {
+	Count        int                         `json:"count"`
+	Average      float64                     `json:"average"`      // redondeado a 2 decimales (0 sin reseñas)
+	Distribution [domain.MaxReviewRating]int `json:"distribution"` // distribution[i] = reseñas de i+1 estrellas
+
+	AverageText string         `json:"-"` // "4,3"
+	Stars       string         `json:"-"` // promedio redondeado en estrellas
+	Bars        []RatingBarDTO `json:"-"` // de 5 a 1 estrellas
+}
+
+// RatingBarDTO es una barra de la distribución (cuántas de N estrellas).
+type RatingBarDTO struct {
+	Stars   int
+	Count   int
+	Percent int
+}
+
+func ratingSummaryToDTO(s domain.RatingSummary) RatingSummaryDTO {
+	avg := s.Average()
+	dto := RatingSummaryDTO{
+		Count:        s.Count,
+		Average:      math.Round(avg*100) / 100,
+		Distribution: s.Distribution,
+		AverageText:  strings.Replace(strconv.FormatFloat(avg, 'f', 1, 64), ".", ",", 1),
+		Stars:        stars(int(math.Round(avg))),
+	}
+	for n := domain.MaxReviewRating; n >= domain.MinReviewRating; n-- {
+		bar := RatingBarDTO{Stars: n, Count: s.Distribution[n-1]}
+		if s.Count > 0 {
+			bar.Percent = bar.Count * 100 / s.Count
+		}
+		dto.Bars = append(dto.Bars, bar)
+	}
+	return dto
+}
+
+// stars dibuja n estrellas llenas de 5 ("★★★☆☆").
+func stars(n int) string {
+	n = max(0, min(n, domain.MaxReviewRating))
+	return strings.Repeat("★", n) + strings.Repeat("☆", domain.MaxReviewRating-n)
+}
+
+// -------------------- BÚSQUEDA DTO --------------------
+
+// BookHitDTO es un resultado de búsqueda: el libro más su relevancia y los
+// resaltados. En JSON, highlight/snippet son HTML con <mark> (texto escapado);
+// los templates usan los fragmentos directamente.
+type BookHitDTO struct {
+	BookDTO
+	Score     float64 `json:"score,omitempty"`     // 0 (omitido) si no hubo texto libre
+	Highlight string  `json:"highlight,omitempty"` // título con coincidencias marcadas
+	Snippet   string  `json:"snippet,omitempty"`   // extracto de la descripción
+
+	TitleFragments   []domain.Fragment `json:"-"`
+	SnippetFragments []domain.Fragment `json:"-"`
+}
+
+// bookHitsToDTO convierte resultados de búsqueda a DTO.
+func bookHitsToDTO(list []domain.BookHit) []BookHitDTO {
+	out := make([]BookHitDTO, 0, len(list))
+	for _, h := range list {
+		dto := BookHitDTO{
+			BookDTO:          bookToDTO(h.Book),
+			Score:            h.Score,
+			TitleFragments:   h.Title,
+			SnippetFragments: h.Snippet,
+		}
+		if hasMatch(h.Title) {
+			dto.Highlight = fragmentsHTML(h.Title)
+		}
+		if len(h.Snippet) > 0 {
+			dto.Snippet = fragmentsHTML(h.Snippet)
+		}
+		out = append(out, dto)
+	}
+	return out
+}
+
+// fragmentsHTML une fragmentos escapando el texto y marcando coincidencias con <mark>.
+func fragmentsHTML(frags []domain.Fragment) string {
+	var b strings.Builder
+	for _, f := range frags {
+		if f.Match {
+			b.WriteString("<mark>" + html.EscapeString(f.Text) + "</mark>")
+		} else {
+			b.WriteString(html.EscapeString(f.Text))
+		}
+	}
+	return b.String()
+}
+
+// hasMatch indica si algún fragmento coincide con la consulta.
+func hasMatch(frags []domain.Fragment) bool {
+	for _, f := range frags {
+		if f.Match {
+			return true
+		}
+	}
+	return false
+}
+
+// -------------------- API TOKENS DTO --------------------
+
+// APITokenDTO expone los metadatos de un token (NUNCA el hash ni el secreto).
+type APITokenDTO struct {
+	ID         uint64              `json:"id"`
+	Name       string              `json:"name"`
+	Prefix     string              `json:"prefix"`
+	Scopes     []domain.Permission `json:"scopes"`
+	ExpiresAt  *time.Time          `json:"expires_at"`   // nil => no expira
+	LastUsedAt *time.Time          `json:"last_used_at"` // nil => nunca usado
+	RevokedAt  *time.Time          `json:"revoked_at"`   // nil => vigente
+	CreatedAt  time.Time           `json:"created_at"`
+}
+
+// apiTokenToDTO convierte la entidad a DTO (fechas cero -> null).
+func apiTokenToDTO(t *domain.APIToken) APITokenDTO {
+	return APITokenDTO{
+		ID:         t.ID(),
+		Name:       t.Name(),
+		Prefix:     t.Prefix(),
+		Scopes:     t.Scopes(),
+		ExpiresAt:  timePtr(t.ExpiresAt()),
+		LastUsedAt: timePtr(t.LastUsedAt()),
+		RevokedAt:  timePtr(t.RevokedAt()),
+		CreatedAt:  t.CreatedAt(),
+	}
+}
+
+// apiTokensToDTO convierte un slice de tokens.
+func apiTokensToDTO(list []*domain.APIToken) []APITokenDTO {
+	out := make([]APITokenDTO, 0, len(list))
+	for _, t := range list {
+		out = append(out, apiTokenToDTO(t))
+	}
+	return out
+}
+
+// timePtr devuelve nil para fechas cero (JSON null / "—" en templates).
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// -------------------- PERMISOS (HTML) --------------------
+
+// CanView resume los permisos del usuario actual para ocultar controles en templates.
+// Es solo cosmético: la autorización real se aplica en los servicios.
+type CanView struct {
+	BooksWrite      bool
+	BooksDownload   bool
+	UsersRead       bool
+	UsersWrite      bool
+	StatsRead       bool
+	LoansWrite      bool
+	ReviewsModerate bool
+}
+
+// canToView calcula CanView a partir del usuario (nil => sin permisos).
+func canToView(u *domain.User) CanView {
+	return CanView{
+		BooksWrite:      u.Can(domain.PermBooksWrite),
+		BooksDownload:   u.Can(domain.PermBooksDownload),
+		UsersRead:       u.Can(domain.PermUsersRead),
+		UsersWrite:      u.Can(domain.PermUsersWrite),
+		StatsRead:       u.Can(domain.PermStatsRead),
+		LoansWrite:      u.Can(domain.PermLoansWrite),
+		ReviewsModerate: u.Can(domain.PermReviewsModerate),
+	}
+}
+
 // -------------------- VIEW (HTML) DTO --------------------
 
 // ViewData es el DTO utilizado EXCLUSIVAMENTE para renderizar templates HTML.
@@ -115,3 +980,19 @@ type ViewData struct {
 	Content string // Nombre del template a renderizar dentro del layout
 	Data    any    // Datos dinámicos que la vista necesita (flexible)
 }
+
+// SuggestionDTO es una compleción del autocompletado (/api/books/suggest).
+type SuggestionDTO struct {
+	Text   string `json:"text"`              // Título o autor
+	Kind   string `json:"kind"`              // title | author
+	BookID uint64 `json:"book_id,omitempty"` // Libro más reciente con ese título
+	Count  int    `json:"count"`             // Libros con ese título / de ese autor
+}
+
+func suggestionsToDTO(list []domain.Suggestion) []SuggestionDTO {
+	out := make([]SuggestionDTO, 0, len(list))
+	for _, s := range list {
+		out = append(out, SuggestionDTO{Text: s.Text, Kind: string(s.Kind), BookID: s.BookID, Count: s.Count})
+	}
+	return out
+}
@@ -0,0 +1,140 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+// -------------------- API (JSON) --------------------
+
+// FacetCountDTO es un valor de faceta con su conteo.
+type FacetCountDTO struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// BookFacetsDTO agrupa las facetas de una búsqueda (?facets=true).
+type BookFacetsDTO struct {
+	Categories []FacetCountDTO `json:"categories"`
+	Authors    []FacetCountDTO `json:"authors"`
+	Tags       []FacetCountDTO `json:"tags"`
+	Decades    []FacetCountDTO `json:"decades"`
+	Years      []FacetCountDTO `json:"years,omitempty"` // solo con un rango de años acotado
+}
+
+// BookSearchDTO es el sobre paginado de libros más las facetas pedidas.
+type BookSearchDTO struct {
+	PageDTO[BookHitDTO]
+	Facets      *BookFacetsDTO `json:"facets,omitempty"`
+	Approximate bool           `json:"approximate,omitempty"` // resultados tolerando errores de tipeo
+}
+
+func facetCountsToDTO(list []domain.FacetCount) []FacetCountDTO {
+	out := make([]FacetCountDTO, 0, len(list))
+	for _, c := range list {
+		out = append(out, FacetCountDTO{Value: c.Value, Count: c.Count})
+	}
+	return out
+}
+
+func bookFacetsToDTO(f *domain.BookFacets) *BookFacetsDTO {
+	if f == nil {
+		return nil
+	}
+	return &BookFacetsDTO{
+		Categories: facetCountsToDTO(f.Categories),
+		Authors:    facetCountsToDTO(f.Authors),
+		Tags:       facetCountsToDTO(f.Tags),
+		Decades:    facetCountsToDTO(f.Decades),
+		Years:      facetCountsToDTO(f.Years),
+	}
+}
+
+// -------------------- UI (HTML) --------------------
+
+// facetView es un bloque de la columna "Refinar" de book_search.html.
+type facetView struct {
+	Title    string
+	Values   []facetValueView
+	ClearURL string // no vacío si esta dimensión ya está filtrada
+}
+
+// facetValueView es un enlace que agrega (o reemplaza) un filtro.
+type facetValueView struct {
+	Label  string
+	Count  int
+	URL    string
+	Active bool
+}
+
+// refineURL devuelve la URL actual con los parámetros cambiados, volviendo a
+// la página 1 (un valor vacío quita el parámetro).
+func refineURL(r *http.Request, set map[string]string) string {
+	q := r.URL.Query()
+	q.Del("page")
+	for k, v := range set {
+		if v == "" {
+			q.Del(k)
+		} else {
+			q.Set(k, v)
+		}
+	}
+	return r.URL.Path + "?" + q.Encode()
+}
+
+// bookFacetViews arma los bloques de facetas con enlaces que refinan el filtro.
+func bookFacetViews(r *http.Request, f domain.BookFilter, facets *domain.BookFacets) []facetView {
+	if facets == nil {
+		return nil
+	}
+
+	// Dimensiones de texto: un parámetro por faceta
+	text := func(title, param, current string, list []domain.FacetCount) facetView {
+		v := facetView{Title: title}
+		if strings.TrimSpace(current) != "" {
+			v.ClearURL = refineURL(r, map[string]string{param: ""})
+		}
+		for _, c := range list {
+			v.Values = append(v.Values, facetValueView{
+				Label:  c.Value,
+				Count:  c.Count,
+				URL:    refineURL(r, map[string]string{param: c.Value}),
+				Active: strings.EqualFold(strings.TrimSpace(current), c.Value),
+			})
+		}
+		return v
+	}
+
+	// Décadas y años: rango year_from..year_to
+	years := func(title string, list []domain.FacetCount, span int, label func(int) string) facetView {
+		v := facetView{Title: title}
+		if f.YearFrom > 0 || f.YearTo > 0 {
+			v.ClearURL = refineURL(r, map[string]string{"year_from": "", "year_to": ""})
+		}
+		for _, c := range list {
+			from, _ := strconv.Atoi(c.Value)
+			to := from + span - 1
+			v.Values = append(v.Values, facetValueView{
+				Label:  label(from),
+				Count:  c.Count,
+				URL:    refineURL(r, map[string]string{"year_from": strconv.Itoa(from), "year_to": strconv.Itoa(to)}),
+				Active: f.YearFrom == from && f.YearTo == to,
+			})
+		}
+		return v
+	}
+
+	out := []facetView{
+		text("Categoría", "category", f.Category, facets.Categories),
+		text("Autor", "author", f.Author, facets.Authors),
+		text("Etiqueta", "tag", f.Tag, facets.Tags),
+		years("Década", facets.Decades, 10, func(y int) string { return strconv.Itoa(y) + "–" + strconv.Itoa(y+9) }),
+	}
+	if len(facets.Years) > 0 {
+		out = append(out, years("Año", facets.Years, 1, strconv.Itoa))
+	}
+	return out
+}
@@ -2,6 +2,8 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
@@ -13,30 +15,97 @@ import (
 	"github.com/jfmg0509/sistema_libros_funcional_go/internal/usecase"
 )
 
+// Services agrupa los casos de uso que consume la capa HTTP.
+// Evita constructores con listas largas de parámetros posicionales.
+type Services struct {
+	Users       *usecase.UserService
+	Books       *usecase.BookService
+	Auth        *usecase.AuthService
+	Tokens      *usecase.TokenService
+	Authors     *usecase.AuthorService
+	Taxonomy    *usecase.TaxonomyService
+	Circulation *usecase.CirculationService
+	Files       *usecase.FileService
+	Covers      *usecase.CoverService
+	Progress    *usecase.ProgressService
+	Reader      *usecase.ReaderService
+	Annotations *usecase.AnnotationService
+	Reviews     *usecase.ReviewService
+}
+
 type Handler struct {
-	users *usecase.UserService
-	books *usecase.BookService
-	r     *Renderer
+	users       *usecase.UserService
+	books       *usecase.BookService
+	auth        *usecase.AuthService
+	tokens      *usecase.TokenService
+	authors     *usecase.AuthorService
+	taxonomy    *usecase.TaxonomyService
+	circ        *usecase.CirculationService
+	files       *usecase.FileService
+	covers      *usecase.CoverService
+	progress    *usecase.ProgressService
+	reader      *usecase.ReaderService
+	annotations *usecase.AnnotationService
+	reviews     *usecase.ReviewService
+	r           *Renderer
+
+	secureCookies bool // cookies de sesión solo por HTTPS
 }
 
-func NewHandler(users *usecase.UserService, books *usecase.BookService, r *Renderer) *Handler {
-	return &Handler{users: users, books: books, r: r}
+func NewHandler(svc Services, r *Renderer, secureCookies bool) *Handler {
+	return &Handler{
+		users:         svc.Users,
+		books:         svc.Books,
+		auth:          svc.Auth,
+		tokens:        svc.Tokens,
+		authors:       svc.Authors,
+		taxonomy:      svc.Taxonomy,
+		circ:          svc.Circulation,
+		files:         svc.Files,
+		covers:        svc.Covers,
+		progress:      svc.Progress,
+		reader:        svc.Reader,
+		annotations:   svc.Annotations,
+		reviews:       svc.Reviews,
+		r:             r,
+		secureCookies: secureCookies,
+	}
 }
 
-func (h *Handler) viewBase(title string, showNav bool) map[string]any {
+func (h *Handler) viewBase(r *http.Request, title string, showNav bool) map[string]any {
 	tomorrow := time.Now().Add(24 * time.Hour).Format("02/01/2006")
-	return map[string]any{
+	data := map[string]any{
 		"Title":       title,
 		"ShowNav":     showNav,
 		"FooterLeft":  "Juan Francisco Morán Gortaire",
 		"FooterRight": "PROGRAMACION ORIENTADA A OBJETOS - " + tomorrow,
 	}
+	u, ok := usecase.UserFromContext(r.Context())
+	if ok {
+		dto := userToDTO(u)
+		data["CurrentUser"] = &dto
+	}
+	data["Can"] = canToView(u)
+	return data
 }
 
-func (h *Handler) uiError(w http.ResponseWriter, err error) {
-	data := h.viewBase("Error", true)
+func (h *Handler) uiError(w http.ResponseWriter, r *http.Request, err error) {
+	data := h.viewBase(r, "Error", true)
 	data["Error"] = err.Error()
-	h.r.Render(w, "error.html", data)
+
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, domain.ErrUnavailable), errors.Is(err, domain.ErrLimitReached):
+		status = http.StatusConflict
+	case errors.Is(err, domain.ErrTooLarge):
+		status = http.StatusRequestEntityTooLarge
+	case errors.Is(err, domain.ErrForbidden):
+		status = http.StatusForbidden
+		data["Error"] = "No tienes permisos para realizar esta acción."
+	}
+	h.r.RenderStatus(w, status, "error.html", data)
 }
 
 //
@@ -48,16 +117,17 @@ func (h *Handler) uiError(w http.ResponseWriter, err error) {
 // POST /api/users
 func (h *Handler) apiCreateUser(w http.ResponseWriter, r *http.Request) {
 	var in struct {
-		Name  string      `json:"name"`
-		Email string      `json:"email"`
-		Role  domain.Role `json:"role"`
+		Name     string      `json:"name"`
+		Email    string      `json:"email"`
+		Role     domain.Role `json:"role"`
+		Password string      `json:"password"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
 		writeErr(w, err) // <- ya existe en responses.go
 		return
 	}
 
-	u, err := h.users.Create(r.Context(), in.Name, in.Email, in.Role)
+	u, err := h.users.Create(r.Context(), in.Name, in.Email, in.Role, in.Password)
 	if err != nil {
 		writeErr(w, err)
 		return
@@ -66,14 +136,90 @@ func (h *Handler) apiCreateUser(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusCreated, userToDTO(u)) // <- ya existe en dto.go
 }
 
-// GET /api/users
+// GET /api/me
+func (h *Handler) apiMe(w http.ResponseWriter, r *http.Request) {
+	u, _ := usecase.UserFromContext(r.Context())
+	writeJSON(w, http.StatusOK, userToDTO(u))
+}
+
+// GET /api/users?role=&active=&email=&page=&page_size=&sort=&dir=
 func (h *Handler) apiListUsers(w http.ResponseWriter, r *http.Request) {
-	list, err := h.users.List(r.Context())
+	f, err := userFilterFromQuery(r)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	page, err := h.users.Search(r.Context(), f)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writePage(w, r, page, usersToDTO(page.Items))
+}
+
+// GET /api/users/{id}
+func (h *Handler) apiGetUser(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	u, err := h.users.Get(r.Context(), id)
 	if err != nil {
 		writeErr(w, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, usersToDTO(list))
+	writeJSON(w, http.StatusOK, userToDTO(u))
+}
+
+// PATCH /api/users/{id}
+func (h *Handler) apiUpdateUser(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+
+	var in struct {
+		Name     string      `json:"name"`
+		Email    string      `json:"email"`
+		Role     domain.Role `json:"role"`
+		Active   *bool       `json:"active"`
+		Password string      `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	u, err := h.users.Update(r.Context(), id, in.Name, in.Email, in.Role, in.Active)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	if in.Password != "" {
+		if err := h.users.ChangePassword(r.Context(), id, in.Password); err != nil {
+			writeErr(w, err)
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, userToDTO(u))
+}
+
+// DELETE /api/users/{id}
+func (h *Handler) apiDeleteUser(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if err := h.users.Delete(r.Context(), id); err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /api/users/{id}/activate y /api/users/{id}/deactivate
+func (h *Handler) apiSetUserActive(active bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := mustUint64(mux.Vars(r)["id"])
+		u, err := h.users.SetActive(r.Context(), id, active)
+		if err != nil {
+			writeErr(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, userToDTO(u))
+	}
 }
 
 // POST /api/books
@@ -101,29 +247,50 @@ func (h *Handler) apiCreateBook(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusCreated, bookToDTO(b))
 }
 
-// GET /api/books
+// GET /api/books?page=&page_size=&sort=&dir=
 func (h *Handler) apiListBooks(w http.ResponseWriter, r *http.Request) {
-	list, err := h.books.List(r.Context())
+	h.apiSearchBooks(w, r)
+}
+
+// GET /api/books/search?q=&author=&category=&tag=&year_from=&year_to=&min_rating=&facets=&page=&page_size=&sort=&dir=
+func (h *Handler) apiSearchBooks(w http.ResponseWriter, r *http.Request) {
+	f, err := bookFilterFromQuery(r)
 	if err != nil {
 		writeErr(w, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, booksToDTO(list))
+	res, err := h.books.Search(r.Context(), f)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	items := bookHitsToDTO(res.Hits.Items)
+	h.setHitCovers(r.Context(), items)
+	h.setHitRatings(r.Context(), items)
+	writeJSON(w, http.StatusOK, BookSearchDTO{
+		PageDTO:     newPageDTO(w, r, res.Hits, items),
+		Facets:      bookFacetsToDTO(res.Facets),
+		Approximate: res.Approximate,
+	})
 }
 
-// GET /api/books/search
-func (h *Handler) apiSearchBooks(w http.ResponseWriter, r *http.Request) {
-	f := domain.BookFilter{
-		Q:        r.URL.Query().Get("q"),
-		Author:   r.URL.Query().Get("author"),
-		Category: r.URL.Query().Get("category"),
+// GET /api/books/suggest?prefix=cerv&limit=10
+func (h *Handler) apiSuggestBooks(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if s := strings.TrimSpace(r.URL.Query().Get("limit")); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 1 {
+			writeErr(w, fmt.Errorf("%w: limit must be a positive integer", domain.ErrValidation))
+			return
+		}
+		limit = v
 	}
-	list, err := h.books.Search(r.Context(), f)
+	list, err := h.books.Suggest(r.Context(), r.URL.Query().Get("prefix"), limit)
 	if err != nil {
 		writeErr(w, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, booksToDTO(list))
+	writeJSON(w, http.StatusOK, map[string]any{"suggestions": suggestionsToDTO(list)})
 }
 
 // GET /api/books/{id}
@@ -134,7 +301,16 @@ func (h *Handler) apiGetBook(w http.ResponseWriter, r *http.Request) {
 		writeErr(w, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, bookToDTO(b))
+	credits, err := h.books.Credits(r.Context(), id)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	dto := bookToDTO(b)
+	dto.Authors = creditsToDTO(credits)
+	h.setBookCover(r.Context(), &dto)
+	h.setBookRating(r.Context(), &dto)
+	writeJSON(w, http.StatusOK, dto)
 }
 
 // PATCH /api/books/{id}
@@ -171,7 +347,10 @@ func (h *Handler) apiUpdateBook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, bookToDTO(out))
+	dto := bookToDTO(out)
+	h.setBookCover(r.Context(), &dto)
+	h.setBookRating(r.Context(), &dto)
+	writeJSON(w, http.StatusOK, dto)
 }
 
 // DELETE /api/books/{id}
@@ -184,6 +363,48 @@ func (h *Handler) apiDeleteBook(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// POST /api/books/{id}/access
+func (h *Handler) apiRecordAccess(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+
+	var in struct {
+		Type domain.AccessType `json:"type"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	u, _ := usecase.UserFromContext(r.Context())
+	if err := h.books.RecordAccess(r.Context(), u.ID(), id, in.Type); err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// GET /api/books/{id}/stats
+func (h *Handler) apiBookStats(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	stats, err := h.books.StatsByBook(r.Context(), id)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// GET /api/books/{id}/reading-time (stats:read)
+// Sesiones del lector integrado y tiempo total leído.
+func (h *Handler) apiBookReadingTime(w http.ResponseWriter, r *http.Request) {
+	rt, err := h.books.ReadingTime(r.Context(), mustUint64(mux.Vars(r)["id"]))
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, readingTimeToDTO(rt))
+}
+
 //
 // ==============================
 // UI (HTML) - /ui/*
@@ -192,21 +413,94 @@ func (h *Handler) apiDeleteBook(w http.ResponseWriter, r *http.Request) {
 
 // GET /
 func (h *Handler) uiHome(w http.ResponseWriter, r *http.Request) {
-	data := h.viewBase("Inicio", false)
+	data := h.viewBase(r, "Inicio", false)
+	// "Continuar leyendo": los últimos libros empezados y sin terminar
+	if _, ok := usecase.UserFromContext(r.Context()); ok {
+		f := domain.ProgressFilter{Status: domain.ProgressStatusReading, PageRequest: domain.PageRequest{PageSize: 5}}
+		if page, err := h.progress.Mine(r.Context(), f); err == nil {
+			data["Reading"] = progressListToDTO(page.Items)
+		}
+	}
 	h.r.Render(w, "home.html", data)
 }
 
-// GET /ui/users
+// GET /ui/login
+func (h *Handler) uiLoginGET(w http.ResponseWriter, r *http.Request) {
+	data := h.viewBase(r, "Iniciar sesión", true)
+	data["Next"] = safeNext(r.URL.Query().Get("next"))
+	h.r.Render(w, "login.html", data)
+}
+
+// POST /ui/login
+func (h *Handler) uiLoginPOST(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+
+	next := safeNext(r.FormValue("next"))
+	token, sess, err := h.auth.Login(r.Context(), r.FormValue("email"), r.FormValue("password"))
+	if err != nil {
+		data := h.viewBase(r, "Iniciar sesión", true)
+		data["Next"] = next
+		data["Email"] = r.FormValue("email")
+		data["Error"] = "Credenciales inválidas o usuario inactivo."
+		h.r.RenderStatus(w, http.StatusUnauthorized, "login.html", data)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    token,
+		Path:     "/",
+		Expires:  sess.ExpiresAt(),
+		HttpOnly: true,
+		Secure:   h.secureCookies,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, next, http.StatusSeeOther)
+}
+
+// POST /ui/logout
+func (h *Handler) uiLogoutPOST(w http.ResponseWriter, r *http.Request) {
+	if c, err := r.Cookie(sessionCookieName); err == nil {
+		_ = h.auth.Logout(r.Context(), c.Value)
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   h.secureCookies,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, "/ui/login", http.StatusSeeOther)
+}
+
+// GET /ui/users?role=&active=&email=&page=&sort=&dir=
 func (h *Handler) uiUsersGET(w http.ResponseWriter, r *http.Request) {
-	list, err := h.users.List(r.Context())
+	f, err := userFilterFromQuery(r)
+	if err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	page, err := h.users.Search(r.Context(), f)
 	if err != nil {
-		h.uiError(w, err)
+		h.uiError(w, r, err)
 		return
 	}
 
-	data := h.viewBase("Usuarios", true)
-	data["Users"] = usersToDTO(list)
+	data := h.viewBase(r, "Usuarios", true)
+	data["Users"] = usersToDTO(page.Items)
+	data["Pager"] = newPagerView(r, page)
 	data["Roles"] = domain.AllowedRoles
+	data["FilterRole"] = string(f.Role)
+	data["FilterActive"] = r.URL.Query().Get("active")
+	data["FilterEmail"] = f.Email
+	data["Sort"] = f.Sort
+	data["Dir"] = string(f.Dir)
 
 	h.r.Render(w, "users.html", data)
 }
@@ -214,7 +508,7 @@ func (h *Handler) uiUsersGET(w http.ResponseWriter, r *http.Request) {
 // POST /ui/users
 func (h *Handler) uiUsersPOST(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		h.uiError(w, err)
+		h.uiError(w, r, err)
 		return
 	}
 
@@ -223,25 +517,106 @@ func (h *Handler) uiUsersPOST(w http.ResponseWriter, r *http.Request) {
 		r.FormValue("name"),
 		r.FormValue("email"),
 		domain.Role(r.FormValue("role")),
+		r.FormValue("password"),
 	)
 	if err != nil {
-		h.uiError(w, err)
+		h.uiError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/ui/users", http.StatusSeeOther)
+}
+
+// GET /ui/users/{id}
+func (h *Handler) uiUserDetailGET(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+
+	u, err := h.users.Get(r.Context(), id)
+	if err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+
+	data := h.viewBase(r, "Editar usuario", true)
+	data["User"] = userToDTO(u)
+	data["Roles"] = domain.AllowedRoles
+	h.r.Render(w, "user_detail.html", data)
+}
+
+// POST /ui/users/{id}
+func (h *Handler) uiUserUpdatePOST(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if err := r.ParseForm(); err != nil {
+		h.uiError(w, r, err)
 		return
 	}
 
+	active := r.FormValue("active") == "true"
+	if _, err := h.users.Update(
+		r.Context(),
+		id,
+		r.FormValue("name"),
+		r.FormValue("email"),
+		domain.Role(r.FormValue("role")),
+		&active,
+	); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+
+	if p := r.FormValue("password"); p != "" {
+		if err := h.users.ChangePassword(r.Context(), id, p); err != nil {
+			h.uiError(w, r, err)
+			return
+		}
+	}
+
+	http.Redirect(w, r, "/ui/users/"+strconv.FormatUint(id, 10), http.StatusSeeOther)
+}
+
+// POST /ui/users/{id}/activate y /ui/users/{id}/deactivate
+func (h *Handler) uiUserSetActivePOST(active bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := mustUint64(mux.Vars(r)["id"])
+		if _, err := h.users.SetActive(r.Context(), id, active); err != nil {
+			h.uiError(w, r, err)
+			return
+		}
+		http.Redirect(w, r, "/ui/users/"+strconv.FormatUint(id, 10), http.StatusSeeOther)
+	}
+}
+
+// POST /ui/users/{id}/delete
+func (h *Handler) uiUserDeletePOST(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if err := h.users.Delete(r.Context(), id); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
 	http.Redirect(w, r, "/ui/users", http.StatusSeeOther)
 }
 
-// GET /ui/books
+// GET /ui/books?page=&sort=&dir=
 func (h *Handler) uiBooksGET(w http.ResponseWriter, r *http.Request) {
-	list, err := h.books.List(r.Context())
+	p, err := pageRequestFromQuery(r.URL.Query())
+	if err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	res, err := h.books.Search(r.Context(), domain.BookFilter{PageRequest: p})
 	if err != nil {
-		h.uiError(w, err)
+		h.uiError(w, r, err)
 		return
 	}
 
-	data := h.viewBase("Libros", true)
-	data["Books"] = booksToDTO(list)
+	data := h.viewBase(r, "Libros", true)
+	books := bookHitsToDTO(res.Hits.Items)
+	h.setHitCovers(r.Context(), books)
+	h.setHitRatings(r.Context(), books)
+	data["Books"] = books
+	data["Pager"] = newPagerView(r, res.Hits)
+	data["Sort"] = p.Sort
+	data["Dir"] = string(p.Dir)
 
 	h.r.Render(w, "books.html", data)
 }
@@ -249,7 +624,7 @@ func (h *Handler) uiBooksGET(w http.ResponseWriter, r *http.Request) {
 // POST /ui/books
 func (h *Handler) uiBooksPOST(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		h.uiError(w, err)
+		h.uiError(w, r, err)
 		return
 	}
 
@@ -267,36 +642,57 @@ func (h *Handler) uiBooksPOST(w http.ResponseWriter, r *http.Request) {
 		r.FormValue("description"),
 	)
 	if err != nil {
-		h.uiError(w, err)
+		h.uiError(w, r, err)
 		return
 	}
 
 	http.Redirect(w, r, "/ui/books", http.StatusSeeOther)
 }
 
-// GET /ui/books/search
+// GET /ui/books/search?q=&author=&category=&tag=&year_from=&year_to=&min_rating=&page=&sort=&dir=
 func (h *Handler) uiBookSearchGET(w http.ResponseWriter, r *http.Request) {
-	q := r.URL.Query().Get("q")
-	author := r.URL.Query().Get("author")
-	category := r.URL.Query().Get("category")
-
-	list, err := h.books.Search(r.Context(), domain.BookFilter{
-		Q:        q,
-		Author:   author,
-		Category: category,
-	})
+	f, err := bookFilterFromQuery(r)
 	if err != nil {
-		h.uiError(w, err)
+		h.uiError(w, r, err)
+		return
+	}
+	f.WithFacets = true // la UI siempre muestra la columna "Refinar"
+
+	data := h.viewBase(r, "Buscar", true)
+	status := http.StatusOK
+
+	res, err := h.books.Search(r.Context(), f)
+	switch {
+	case errors.Is(err, domain.ErrValidation):
+		// Consulta mal escrita: se muestra junto al formulario para corregirla
+		data["Error"] = err.Error()
+		status = http.StatusBadRequest
+		res.Hits = domain.Page[domain.BookHit]{Page: 1, PageSize: domain.DefaultPageSize}
+	case err != nil:
+		h.uiError(w, r, err)
 		return
 	}
 
-	data := h.viewBase("Buscar", true)
-	data["Books"] = booksToDTO(list)
-	data["Q"] = q
-	data["Author"] = author
-	data["Category"] = category
-
-	h.r.Render(w, "book_search.html", data)
+	books := bookHitsToDTO(res.Hits.Items)
+	h.setHitCovers(r.Context(), books)
+	h.setHitRatings(r.Context(), books)
+	data["Books"] = books
+	data["Pager"] = newPagerView(r, res.Hits)
+	data["Facets"] = bookFacetViews(r, f, res.Facets)
+	data["Q"] = f.Q
+	data["Author"] = f.Author
+	data["Category"] = f.Category
+	data["Tag"] = f.Tag
+	data["Fuzzy"] = f.Fuzzy
+	data["Approximate"] = res.Approximate
+	data["YearFrom"] = r.URL.Query().Get("year_from")
+	data["YearTo"] = r.URL.Query().Get("year_to")
+	data["MinRating"] = r.URL.Query().Get("min_rating")
+	data["MinRatings"] = minRatingOptions()
+	data["Sort"] = f.Sort
+	data["Dir"] = string(f.Dir)
+
+	h.r.RenderStatus(w, status, "book_search.html", data)
 }
 
 // GET /ui/books/{id}
@@ -305,14 +701,108 @@ func (h *Handler) uiBookDetailGET(w http.ResponseWriter, r *http.Request) {
 
 	b, err := h.books.Get(r.Context(), id)
 	if err != nil {
-		h.uiError(w, err)
+		h.uiError(w, r, err)
 		return
 	}
 
-	data := h.viewBase("Detalle del libro", true)
-	data["Book"] = bookToDTO(b)
+	credits, err := h.books.Credits(r.Context(), id)
+	if err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+
+	data := h.viewBase(r, "Detalle del libro", true)
+	dto := bookToDTO(b)
+	dto.Authors = creditsToDTO(credits)
+	h.setBookCover(r.Context(), &dto)
+	data["Book"] = dto
+	data["CreditRoles"] = domain.CreditRoles
+	data["MaxCoverMB"] = domain.MaxCoverBytes >> 20
+	data["MaxCoverSide"] = domain.MaxCoverSide
+	if related, err := h.books.Relations(r.Context(), id); err == nil {
+		data["Related"] = relatedToDTO(related)
+		data["RelationTypes"] = domain.RelationTypes
+	}
+
+	if files, err := h.files.Files(r.Context(), id); err == nil {
+		data["Files"] = filesToDTO(files)
+		data["MaxUploadMB"] = h.files.MaxSize() >> 20
+		// El lector integrado abre el primer EPUB o, si no hay, el primer PDF
+		// (que solo puede leer quien puede descargarlo)
+		canDownload := data["Can"].(CanView).BooksDownload
+		for _, f := range files {
+			if f.Kind() == domain.FileKindEPUB || (f.Kind() == domain.FileKindPDF && canDownload) {
+				data["ReadURL"] = readerURL(id)
+				break
+			}
+		}
+	}
+
+	if copies, err := h.circ.Copies(r.Context(), id); err == nil {
+		data["Copies"] = copiesToDTO(copies)
+		data["CopyStatuses"] = domain.AllowedCopyStatuses
+	}
+	data["MaxSeats"] = domain.MaxSeats
+	if a, err := h.circ.Availability(r.Context(), id); err == nil && a.InCirculation() {
+		data["Availability"] = availabilityToDTO(id, a)
+		// Reserva activa del usuario actual (con su lugar en la cola)
+		if u, ok := usecase.UserFromContext(r.Context()); ok {
+			mine, err := h.circ.Holds(r.Context(), domain.HoldFilter{UserID: u.ID(), BookID: id, Status: domain.HoldStatusActive})
+			if err == nil && len(mine.Items) > 0 {
+				data["MyHold"] = holdsToDTO(mine.Items)[0]
+			}
+			// Con puestos digitales se lee con un préstamo vigente (como
+			// exigen el lector y la descarga); el mostrador no lo necesita
+			if a.Seats > 0 {
+				reading := data["Can"].(CanView).LoansWrite
+				f := domain.LoanFilter{UserID: u.ID(), BookID: id, Status: domain.LoanStatusActive}
+				if loans, err := h.circ.Loans(r.Context(), f); err == nil {
+					for _, l := range loansToDTO(loans.Items, time.Now()) {
+						if l.Overdue {
+							continue
+						}
+						reading = true
+						if l.Digital {
+							data["SeatLoan"] = l
+						}
+					}
+				}
+				if !reading {
+					delete(data, "ReadURL")
+					data["SeatRequired"] = true
+				}
+			}
+		}
+	}
+
+	if p, err := h.progress.Get(r.Context(), id); err == nil {
+		data["Progress"] = progressToDTO(p)
+	}
+
+	// Anotaciones propias en orden de ubicación
+	f := domain.AnnotationFilter{BookID: id, PageRequest: domain.PageRequest{Sort: "location", PageSize: domain.MaxPageSize}}
+	if notes, err := h.annotations.Mine(r.Context(), f); err == nil {
+		data["Annotations"] = annotationListToDTO(notes.Items)
+		data["AnnotationsMore"] = notes.Total > len(notes.Items)
+		data["AnnotationColors"] = annotationColorOptions()
+		data["AnnotationVisibilities"] = annotationVisibilityOptions()
+	}
+
+	h.setBookReviews(r, data, id)
+
+	// Estadísticas solo para quien tiene stats:read (ADMIN / CONSULTOR)
+	if u, _ := usecase.UserFromContext(r.Context()); u.Can(domain.PermStatsRead) {
+		if stats, err := h.books.StatsByBook(r.Context(), id); err == nil {
+			data["Stats"] = stats
+		}
+		if rt, err := h.books.ReadingTime(r.Context(), id); err == nil {
+			data["ReadingTime"] = readingTimeToDTO(rt)
+		}
+		if c, err := h.progress.Completion(r.Context(), domain.CompletionFilter{BookID: id}); err == nil && len(c.Items) > 0 {
+			data["Completion"] = completionToDTO(c.Items)[0]
+		}
+	}
 
-	// SIN ESTADÍSTICAS
 	h.r.Render(w, "book_detail.html", data)
 }
 
@@ -322,11 +812,85 @@ func (h *Handler) uiBookDetailGET(w http.ResponseWriter, r *http.Request) {
 // ==============================
 //
 
+// bookFilterFromQuery arma domain.BookFilter desde
+// ?q=&author=&category=&tag=&year_from=&year_to=&min_rating=&facets= más la paginación.
+func bookFilterFromQuery(r *http.Request) (domain.BookFilter, error) {
+	q := r.URL.Query()
+	p, err := pageRequestFromQuery(q)
+	f := domain.BookFilter{
+		Q:           q.Get("q"),
+		Author:      q.Get("author"),
+		Category:    q.Get("category"),
+		Tag:         q.Get("tag"),
+		PageRequest: p,
+	}
+	if err != nil {
+		return f, err
+	}
+
+	for name, dst := range map[string]*int{"year_from": &f.YearFrom, "year_to": &f.YearTo} {
+		if s := strings.TrimSpace(q.Get(name)); s != "" {
+			v, err := strconv.Atoi(s)
+			if err != nil {
+				return f, fmt.Errorf("%w: %s must be a year", domain.ErrValidation, name)
+			}
+			*dst = v
+		}
+	}
+	if s := strings.TrimSpace(q.Get("min_rating")); s != "" {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return f, fmt.Errorf("%w: min_rating must be a number", domain.ErrValidation)
+		}
+		f.MinRating = v
+	}
+	for name, dst := range map[string]*bool{"facets": &f.WithFacets, "fuzzy": &f.Fuzzy} {
+		if s := strings.TrimSpace(q.Get(name)); s != "" {
+			v, err := strconv.ParseBool(s)
+			if err != nil {
+				return f, fmt.Errorf("%w: %s must be true or false", domain.ErrValidation, name)
+			}
+			*dst = v
+		}
+	}
+	return f, nil
+}
+
+// userFilterFromQuery arma domain.UserFilter desde ?role=&active=&email= más la paginación.
+func userFilterFromQuery(r *http.Request) (domain.UserFilter, error) {
+	q := r.URL.Query()
+	p, err := pageRequestFromQuery(q)
+	f := domain.UserFilter{
+		Role:        domain.Role(strings.ToUpper(strings.TrimSpace(q.Get("role")))),
+		Email:       strings.TrimSpace(q.Get("email")),
+		PageRequest: p,
+	}
+	if err != nil {
+		return f, err
+	}
+	if a := strings.TrimSpace(q.Get("active")); a != "" {
+		v, err := strconv.ParseBool(a)
+		if err != nil {
+			return f, fmt.Errorf("%w: active must be true or false", domain.ErrValidation)
+		}
+		f.Active = &v
+	}
+	return f, nil
+}
+
 func mustUint64(s string) uint64 {
 	v, _ := strconv.ParseUint(s, 10, 64)
 	return v
 }
 
+// safeNext solo acepta rutas locales para evitar redirecciones abiertas.
+func safeNext(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/"
+	}
+	return next
+}
+
 func splitCSV(s string) []string {
 	s = strings.TrimSpace(s)
 	if s == "" {
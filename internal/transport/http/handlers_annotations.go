@@ -0,0 +1,374 @@
+package http
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/usecase"
+)
+
+//
+// ==============================
+// ANOTACIONES - /api/annotations, /api/books/{id}/annotations y /ui/annotations
+// ==============================
+//
+
+// annotationInput es el cuerpo JSON para crear o editar una anotación
+// (al editar, los campos ausentes no cambian).
+type annotationInput struct {
+	Location   *string `json:"location"`
+	Quote      *string `json:"quote"`
+	Note       *string `json:"note"`
+	Color      *string `json:"color"`
+	Visibility *string `json:"visibility"`
+}
+
+// GET /api/books/{id}/annotations?color=&page=&page_size=&sort=created_at|updated_at|location&dir=
+// Las anotaciones públicas del libro (las propias: /api/me/annotations?book_id=).
+func (h *Handler) apiBookAnnotations(w http.ResponseWriter, r *http.Request) {
+	f, err := annotationFilterFromQuery(r)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	page, err := h.annotations.Public(r.Context(), mustUint64(mux.Vars(r)["id"]), f)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writePage(w, r, page, annotationListToDTO(page.Items))
+}
+
+// GET /api/books/{id}/annotations/shared?user_id=&visibility=class|public&color=&page=&sort=&dir= (stats:read)
+// Vista del docente: lo que los lectores compartieron con la clase o hicieron público.
+func (h *Handler) apiBookSharedAnnotations(w http.ResponseWriter, r *http.Request) {
+	f, err := annotationFilterFromQuery(r)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	f.UserID = mustUint64(strings.TrimSpace(r.URL.Query().Get("user_id")))
+	page, err := h.annotations.Shared(r.Context(), mustUint64(mux.Vars(r)["id"]), f)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writePage(w, r, page, annotationListToDTO(page.Items))
+}
+
+// POST /api/books/{id}/annotations {"location": "epubcfi(...)", "quote": "...", "note": "...", "color": "yellow", "visibility": "private"}
+// Solo location es obligatoria (sin quote ni note es un marcador).
+func (h *Handler) apiCreateAnnotation(w http.ResponseWriter, r *http.Request) {
+	var in annotationInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeErr(w, err)
+		return
+	}
+	a, err := h.annotations.Create(r.Context(), mustUint64(mux.Vars(r)["id"]), in.content())
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, annotationToDTO(a))
+}
+
+// GET /api/annotations/{id}
+func (h *Handler) apiGetAnnotation(w http.ResponseWriter, r *http.Request) {
+	a, err := h.annotations.Get(r.Context(), mustUint64(mux.Vars(r)["id"]))
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, annotationToDTO(a))
+}
+
+// PATCH /api/annotations/{id} (mismos campos que al crear, todos opcionales; solo el autor)
+func (h *Handler) apiUpdateAnnotation(w http.ResponseWriter, r *http.Request) {
+	var in annotationInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeErr(w, err)
+		return
+	}
+	a, err := h.annotations.Update(r.Context(), mustUint64(mux.Vars(r)["id"]), usecase.UpdateAnnotationInput(in))
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, annotationToDTO(a))
+}
+
+// DELETE /api/annotations/{id} (el autor, o users:write para moderar)
+func (h *Handler) apiDeleteAnnotation(w http.ResponseWriter, r *http.Request) {
+	if err := h.annotations.Delete(r.Context(), mustUint64(mux.Vars(r)["id"])); err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /api/me/annotations?book_id=&visibility=&color=&page=&page_size=&sort=&dir=
+func (h *Handler) apiMyAnnotations(w http.ResponseWriter, r *http.Request) {
+	f, err := annotationFilterFromQuery(r)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	page, err := h.annotations.Mine(r.Context(), f)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writePage(w, r, page, annotationListToDTO(page.Items))
+}
+
+// GET /api/me/annotations/export?format=json|markdown
+func (h *Handler) apiExportMyAnnotations(w http.ResponseWriter, r *http.Request) {
+	u, ok := usecase.UserFromContext(r.Context())
+	if !ok {
+		writeErr(w, domain.ErrUnauthorized)
+		return
+	}
+	h.exportAnnotations(w, r, u.ID())
+}
+
+// GET /api/users/{id}/annotations/export?format=json|markdown
+// El propio usuario exporta todas; un docente (stats:read), solo las compartidas.
+func (h *Handler) apiExportUserAnnotations(w http.ResponseWriter, r *http.Request) {
+	h.exportAnnotations(w, r, mustUint64(mux.Vars(r)["id"]))
+}
+
+// exportAnnotations descarga las anotaciones del usuario como JSON (por
+// defecto) o Markdown, agrupadas por libro.
+func (h *Handler) exportAnnotations(w http.ResponseWriter, r *http.Request, userID uint64) {
+	format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+	switch format {
+	case "", "json", "markdown", "md":
+	default:
+		writeErr(w, fmt.Errorf("%w: format must be json or markdown", domain.ErrValidation))
+		return
+	}
+	exp, err := h.annotations.Export(r.Context(), userID)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	now := time.Now()
+	name := "anotaciones-" + cmp.Or(domain.Slugify(exp.User.Name()), strconv.FormatUint(userID, 10))
+	var body bytes.Buffer
+	if format == "markdown" || format == "md" {
+		writeAnnotationsMarkdown(&body, exp, now)
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		name += ".md"
+	} else {
+		_ = json.NewEncoder(&body).Encode(AnnotationExportDTO{
+			User: userToDTO(exp.User), Shared: exp.Shared, ExportedAt: now, Annotations: annotationListToDTO(exp.Items),
+		})
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		name += ".json"
+	}
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	_, _ = w.Write(body.Bytes())
+}
+
+// writeAnnotationsMarkdown escribe la exportación: un título por libro y,
+// en cada uno, las anotaciones en orden de ubicación (el texto subrayado
+// como cita y la nota debajo).
+func writeAnnotationsMarkdown(b *bytes.Buffer, exp *usecase.AnnotationExport, now time.Time) {
+	fmt.Fprintf(b, "# Anotaciones de %s\n\n", oneLine(exp.User.Name()))
+	fmt.Fprintf(b, "Exportado el %s · %d anotación(es)", now.Format("02/01/2006 15:04"), len(exp.Items))
+	if exp.Shared {
+		b.WriteString(" · solo las compartidas")
+	}
+	b.WriteString("\n")
+
+	var book uint64
+	for _, a := range annotationListToDTO(exp.Items) {
+		if a.BookID != book {
+			book = a.BookID
+			fmt.Fprintf(b, "\n## %s\n", oneLine(a.BookTitle))
+		}
+		fmt.Fprintf(b, "\n### %s · %s\n\n", a.KindText, oneLine(a.Location))
+		if a.Quote != "" {
+			for _, line := range strings.Split(a.Quote, "\n") {
+				b.WriteString(strings.TrimRight("> "+line, " ") + "\n")
+			}
+			b.WriteString("\n")
+		}
+		if a.Note != "" {
+			b.WriteString(a.Note + "\n\n")
+		}
+		fmt.Fprintf(b, "_%s · %s · %s_\n", a.VisibilityText, strings.ToLower(a.ColorText), a.UpdatedAt.Format("02/01/2006"))
+	}
+}
+
+// POST /ui/books/{id}/annotations (location, quote, note, color, visibility, next)
+func (h *Handler) uiAnnotationsPOST(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if err := r.ParseForm(); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	c := domain.AnnotationContent{
+		Location:   r.FormValue("location"),
+		Quote:      r.FormValue("quote"),
+		Note:       r.FormValue("note"),
+		Color:      domain.AnnotationColor(r.FormValue("color")),
+		Visibility: domain.AnnotationVisibility(r.FormValue("visibility")),
+	}
+	if _, err := h.annotations.Create(r.Context(), id, c); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	next := "/ui/books/" + strconv.FormatUint(id, 10) + "#anotaciones"
+	if r.FormValue("next") != "" {
+		next = safeNext(r.FormValue("next"))
+	}
+	http.Redirect(w, r, next, http.StatusSeeOther)
+}
+
+// POST /ui/annotations/{id} (note, color, visibility)
+func (h *Handler) uiAnnotationUpdatePOST(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if err := r.ParseForm(); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	// Solo cambian los campos que trae el formulario
+	field := func(name string) *string {
+		if _, ok := r.PostForm[name]; !ok {
+			return nil
+		}
+		v := r.PostForm.Get(name)
+		return &v
+	}
+	in := usecase.UpdateAnnotationInput{Note: field("note"), Color: field("color"), Visibility: field("visibility")}
+	if _, err := h.annotations.Update(r.Context(), id, in); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, safeNext(r.FormValue("next")), http.StatusSeeOther)
+}
+
+// POST /ui/annotations/{id}/delete (next)
+func (h *Handler) uiAnnotationDeletePOST(w http.ResponseWriter, r *http.Request) {
+	if err := h.annotations.Delete(r.Context(), mustUint64(mux.Vars(r)["id"])); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, safeNext(r.FormValue("next")), http.StatusSeeOther)
+}
+
+// GET /ui/annotations?book_id=&visibility=&color=&page=
+// Las anotaciones del usuario actual, con enlaces de exportación.
+func (h *Handler) uiAnnotationsGET(w http.ResponseWriter, r *http.Request) {
+	f, err := annotationFilterFromQuery(r)
+	if err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	page, err := h.annotations.Mine(r.Context(), f)
+	if err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+
+	data := h.viewBase(r, "Mis anotaciones", true)
+	data["Items"] = annotationListToDTO(page.Items)
+	data["Pager"] = newPagerView(r, page)
+	data["Color"] = string(f.Color)
+	data["Visibility"] = r.URL.Query().Get("visibility")
+	data["Colors"] = annotationColorOptions()
+	data["Visibilities"] = annotationVisibilityOptions()
+	data["Next"] = r.URL.RequestURI()
+
+	h.r.Render(w, "annotations.html", data)
+}
+
+// GET /ui/books/{id}/annotations?user_id=&page=
+// Anotaciones compartidas del libro: el docente (stats:read) ve las de
+// clase y las públicas; el resto de los lectores, solo las públicas.
+func (h *Handler) uiBookAnnotationsGET(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	f, err := annotationFilterFromQuery(r)
+	if err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	f.Sort = cmp.Or(f.Sort, "location")
+
+	b, err := h.books.Get(r.Context(), id)
+	if err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	var page domain.Page[domain.AnnotationDetail]
+	u, _ := usecase.UserFromContext(r.Context())
+	teacher := u.Can(domain.PermStatsRead)
+	if teacher {
+		f.UserID = mustUint64(strings.TrimSpace(r.URL.Query().Get("user_id")))
+		page, err = h.annotations.Shared(r.Context(), id, f)
+	} else {
+		page, err = h.annotations.Public(r.Context(), id, f)
+	}
+	if err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+
+	data := h.viewBase(r, "Anotaciones de "+b.Title(), true)
+	data["Book"] = bookToDTO(b)
+	data["Teacher"] = teacher
+	data["UserID"] = f.UserID
+	data["Items"] = annotationListToDTO(page.Items)
+	data["Pager"] = newPagerView(r, page)
+	data["Next"] = r.URL.RequestURI()
+
+	h.r.Render(w, "book_annotations.html", data)
+}
+
+// annotationFilterFromQuery arma domain.AnnotationFilter desde
+// ?book_id=&visibility=&color= más la paginación.
+func annotationFilterFromQuery(r *http.Request) (domain.AnnotationFilter, error) {
+	q := r.URL.Query()
+	p, err := pageRequestFromQuery(q)
+	f := domain.AnnotationFilter{
+		BookID:      mustUint64(strings.TrimSpace(q.Get("book_id"))),
+		Color:       domain.AnnotationColor(strings.ToLower(strings.TrimSpace(q.Get("color")))),
+		PageRequest: p,
+	}
+	for _, v := range splitCSV(strings.ToLower(q.Get("visibility"))) {
+		f.Visibilities = append(f.Visibilities, domain.AnnotationVisibility(v))
+	}
+	return f, err
+}
+
+// content convierte el cuerpo en el contenido de una anotación nueva.
+func (in annotationInput) content() domain.AnnotationContent {
+	deref := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	return domain.AnnotationContent{
+		Location:   deref(in.Location),
+		Quote:      deref(in.Quote),
+		Note:       deref(in.Note),
+		Color:      domain.AnnotationColor(deref(in.Color)),
+		Visibility: domain.AnnotationVisibility(deref(in.Visibility)),
+	}
+}
+
+// oneLine deja el texto en una línea (títulos de Markdown).
+func oneLine(s string) string { return strings.Join(strings.Fields(s), " ") }
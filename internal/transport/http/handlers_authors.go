@@ -0,0 +1,325 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/usecase"
+)
+
+//
+// ==============================
+// AUTORES - /api/authors, /ui/authors y créditos de libros
+// ==============================
+//
+
+// POST /api/authors
+func (h *Handler) apiCreateAuthor(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		Name string `json:"name"`
+		Bio  string `json:"bio"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	a, err := h.authors.Create(r.Context(), in.Name, in.Bio)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, authorToDTO(a))
+}
+
+// GET /api/authors?q=&page=&page_size=&sort=&dir=
+func (h *Handler) apiListAuthors(w http.ResponseWriter, r *http.Request) {
+	f, err := authorFilterFromQuery(r)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	page, err := h.authors.Search(r.Context(), f)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writePage(w, r, page, authorsToDTO(page.Items))
+}
+
+// GET /api/authors/{id} (incluye sus libros con el rol en cada uno)
+func (h *Handler) apiGetAuthor(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	a, err := h.authors.Get(r.Context(), id)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	works, err := h.authors.Books(r.Context(), id)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"author": authorToDTO(a),
+		"books":  authorBooksToDTO(works),
+	})
+}
+
+// PATCH /api/authors/{id}
+func (h *Handler) apiUpdateAuthor(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+
+	var in struct {
+		Name *string `json:"name"`
+		Bio  *string `json:"bio"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	a, err := h.authors.Update(r.Context(), id, usecase.UpdateAuthorInput{Name: in.Name, Bio: in.Bio})
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, authorToDTO(a))
+}
+
+// DELETE /api/authors/{id} (solo autores sin libros)
+func (h *Handler) apiDeleteAuthor(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if err := h.authors.Delete(r.Context(), id); err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /api/authors/{id}/merge {"duplicate_id": 7}
+// El autor {id} se conserva; duplicate_id se fusiona en él y desaparece.
+func (h *Handler) apiMergeAuthor(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+
+	var in struct {
+		DuplicateID uint64 `json:"duplicate_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	a, err := h.authors.Merge(r.Context(), id, in.DuplicateID)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, authorToDTO(a))
+}
+
+// GET /api/books/{id}/authors
+func (h *Handler) apiBookCredits(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	credits, err := h.books.Credits(r.Context(), id)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"authors": creditsToDTO(credits)})
+}
+
+// PUT /api/books/{id}/authors
+// {"authors":[{"author_id":1},{"name":"Andrew Hurley","role":"translator"}]}
+func (h *Handler) apiSetBookCredits(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+
+	var in struct {
+		Authors []struct {
+			AuthorID uint64 `json:"author_id"`
+			Name     string `json:"name"`
+			Role     string `json:"role"`
+		} `json:"authors"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	credits := make([]usecase.CreditInput, 0, len(in.Authors))
+	for _, c := range in.Authors {
+		credits = append(credits, usecase.CreditInput{AuthorID: c.AuthorID, Name: c.Name, Role: c.Role})
+	}
+	b, saved, err := h.books.SetCredits(r.Context(), id, credits)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	dto := bookToDTO(b)
+	dto.Authors = creditsToDTO(saved)
+	writeJSON(w, http.StatusOK, dto)
+}
+
+// GET /ui/authors?q=&page=&sort=&dir=
+func (h *Handler) uiAuthorsGET(w http.ResponseWriter, r *http.Request) {
+	f, err := authorFilterFromQuery(r)
+	if err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	page, err := h.authors.Search(r.Context(), f)
+	if err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+
+	data := h.viewBase(r, "Autores", true)
+	data["Authors"] = authorsToDTO(page.Items)
+	data["Pager"] = newPagerView(r, page)
+	data["Q"] = f.Q
+	data["Sort"] = f.Sort
+	data["Dir"] = string(f.Dir)
+
+	h.r.Render(w, "authors.html", data)
+}
+
+// POST /ui/authors
+func (h *Handler) uiAuthorsPOST(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	a, err := h.authors.Create(r.Context(), r.FormValue("name"), r.FormValue("bio"))
+	if err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, "/ui/authors/"+strconv.FormatUint(a.ID(), 10), http.StatusSeeOther)
+}
+
+// GET /ui/authors/{id}
+func (h *Handler) uiAuthorDetailGET(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+
+	a, err := h.authors.Get(r.Context(), id)
+	if err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	works, err := h.authors.Books(r.Context(), id)
+	if err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+
+	data := h.viewBase(r, a.Name(), true)
+	data["Author"] = authorToDTO(a)
+	data["Books"] = authorBooksToDTO(works)
+	h.r.Render(w, "author_detail.html", data)
+}
+
+// POST /ui/authors/{id}
+func (h *Handler) uiAuthorUpdatePOST(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if err := r.ParseForm(); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+
+	name, bio := r.FormValue("name"), r.FormValue("bio")
+	if _, err := h.authors.Update(r.Context(), id, usecase.UpdateAuthorInput{Name: &name, Bio: &bio}); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, "/ui/authors/"+strconv.FormatUint(id, 10), http.StatusSeeOther)
+}
+
+// POST /ui/authors/{id}/merge (duplicate_id se fusiona en {id})
+func (h *Handler) uiAuthorMergePOST(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if err := r.ParseForm(); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+
+	if _, err := h.authors.Merge(r.Context(), id, mustUint64(strings.TrimSpace(r.FormValue("duplicate_id")))); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, "/ui/authors/"+strconv.FormatUint(id, 10), http.StatusSeeOther)
+}
+
+// POST /ui/authors/{id}/delete
+func (h *Handler) uiAuthorDeletePOST(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if err := h.authors.Delete(r.Context(), id); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, "/ui/authors", http.StatusSeeOther)
+}
+
+// POST /ui/books/{id}/authors
+// Un crédito por línea: "Nombre" (autor) o "rol: Nombre" (autor, editor,
+// traductor, ilustrador, en español o inglés).
+func (h *Handler) uiBookCreditsPOST(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if err := r.ParseForm(); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+
+	if _, _, err := h.books.SetCredits(r.Context(), id, parseCreditLines(r.FormValue("credits"))); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, "/ui/books/"+strconv.FormatUint(id, 10), http.StatusSeeOther)
+}
+
+// creditRoleLabels traduce las etiquetas del formulario a roles del dominio.
+var creditRoleLabels = map[string]domain.CreditRole{
+	"autor":       domain.CreditAuthor,
+	"autora":      domain.CreditAuthor,
+	"editor":      domain.CreditEditor,
+	"editora":     domain.CreditEditor,
+	"traductor":   domain.CreditTranslator,
+	"traductora":  domain.CreditTranslator,
+	"ilustrador":  domain.CreditIllustrator,
+	"ilustradora": domain.CreditIllustrator,
+}
+
+// parseCreditLines lee el textarea de créditos. Si lo que precede a ":" no es
+// un rol conocido, la línea completa es el nombre de un autor.
+func parseCreditLines(text string) []usecase.CreditInput {
+	var out []usecase.CreditInput
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		c := usecase.CreditInput{Name: line}
+		if label, name, ok := strings.Cut(line, ":"); ok {
+			label = strings.ToLower(strings.TrimSpace(label))
+			if role, ok := creditRoleLabels[label]; ok {
+				c = usecase.CreditInput{Name: strings.TrimSpace(name), Role: string(role)}
+			} else if role, err := domain.ParseCreditRole(label); err == nil {
+				c = usecase.CreditInput{Name: strings.TrimSpace(name), Role: string(role)}
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// authorFilterFromQuery arma domain.AuthorFilter desde ?q= más la paginación.
+func authorFilterFromQuery(r *http.Request) (domain.AuthorFilter, error) {
+	q := r.URL.Query()
+	p, err := pageRequestFromQuery(q)
+	return domain.AuthorFilter{Q: strings.TrimSpace(q.Get("q")), PageRequest: p}, err
+}
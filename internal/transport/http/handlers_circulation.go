@@ -0,0 +1,483 @@
+package http
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/usecase"
+)
+
+//
+// ==============================
+// CIRCULACIÓN - ejemplares, puestos digitales, préstamos, reservas, /ui/circulation y /ui/holds
+// ==============================
+//
+
+// GET /api/books/{id}/copies
+func (h *Handler) apiBookCopies(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	copies, err := h.circ.Copies(r.Context(), id)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"copies": copiesToDTO(copies)})
+}
+
+// POST /api/books/{id}/copies {"barcode": "BIB-0001", "location": "Estante A3"}
+func (h *Handler) apiAddCopy(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+
+	var in struct {
+		Barcode  string `json:"barcode"`
+		Location string `json:"location"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	c, err := h.circ.AddCopy(r.Context(), id, in.Barcode, in.Location)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, copiesToDTO([]*domain.Copy{c})[0])
+}
+
+// PATCH /api/copies/{id} {"barcode": "...", "location": "...", "status": "maintenance"}
+func (h *Handler) apiUpdateCopy(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+
+	var in struct {
+		Barcode  *string `json:"barcode"`
+		Location *string `json:"location"`
+		Status   *string `json:"status"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	c, err := h.circ.UpdateCopy(r.Context(), id, usecase.UpdateCopyInput{Barcode: in.Barcode, Location: in.Location, Status: in.Status})
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, copiesToDTO([]*domain.Copy{c})[0])
+}
+
+// DELETE /api/copies/{id} (solo ejemplares sin historial de préstamos)
+func (h *Handler) apiDeleteCopy(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if err := h.circ.DeleteCopy(r.Context(), id); err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PUT /api/books/{id}/seats {"seats": 3} (puestos de la licencia digital)
+func (h *Handler) apiSetSeats(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+
+	var in struct {
+		Seats *int `json:"seats"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeErr(w, err)
+		return
+	}
+	if in.Seats == nil {
+		writeErr(w, fmt.Errorf("%w: seats is required", domain.ErrValidation))
+		return
+	}
+
+	a, err := h.circ.SetSeats(r.Context(), id, *in.Seats)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, availabilityToDTO(id, a))
+}
+
+// POST /api/books/{id}/borrow (préstamo digital para el usuario actual)
+func (h *Handler) apiBorrow(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	l, err := h.circ.Borrow(r.Context(), id)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, loanToDTO(l, time.Now()))
+}
+
+// POST /api/loans/{id}/return (devolución de un préstamo digital: el propio usuario o el mostrador)
+func (h *Handler) apiReturnSeat(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	l, err := h.circ.ReturnSeat(r.Context(), id)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, loanToDTO(l, time.Now()))
+}
+
+// GET /api/loans?user_id=&book_id=&status=active|overdue|returned&page=&page_size=&sort=created_at|due_at&dir=
+// Sin loans:write solo se listan los préstamos propios.
+func (h *Handler) apiListLoans(w http.ResponseWriter, r *http.Request) {
+	f, err := loanFilterFromQuery(r)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	page, err := h.circ.Loans(r.Context(), f)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writePage(w, r, page, loansToDTO(page.Items, time.Now()))
+}
+
+// POST /api/loans {"barcode": "BIB-0001", "user_id": 7} (préstamo en mostrador)
+func (h *Handler) apiCheckout(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		Barcode string `json:"barcode"`
+		UserID  uint64 `json:"user_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	l, err := h.circ.Checkout(r.Context(), in.Barcode, in.UserID)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, loanToDTO(l, time.Now()))
+}
+
+// POST /api/loans/return {"barcode": "BIB-0001"} (devolución en mostrador)
+func (h *Handler) apiReturn(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		Barcode string `json:"barcode"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	l, err := h.circ.Return(r.Context(), in.Barcode)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, loanToDTO(l, time.Now()))
+}
+
+// POST /api/loans/{id}/renew (el propio usuario o el mostrador)
+func (h *Handler) apiRenewLoan(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	l, err := h.circ.Renew(r.Context(), id)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, loanToDTO(l, time.Now()))
+}
+
+// GET /ui/circulation?user_id=&book_id=&status=&page=
+// El mostrador ve todos los préstamos; el resto, solo los propios.
+// Sin ?status= se muestran los activos; status=all los muestra todos.
+func (h *Handler) uiCirculationGET(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	switch q.Get("status") {
+	case "":
+		q.Set("status", domain.LoanStatusActive)
+	case "all":
+		q.Del("status")
+	}
+	r.URL.RawQuery = q.Encode()
+
+	f, err := loanFilterFromQuery(r)
+	if err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	if f.Sort == "" {
+		f.Sort, f.Dir = "due_at", domain.SortAsc
+	}
+	page, err := h.circ.Loans(r.Context(), f)
+	if err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+
+	data := h.viewBase(r, "Préstamos", true)
+	data["Loans"] = loansToDTO(page.Items, time.Now())
+	data["Pager"] = newPagerView(r, page)
+	data["Status"] = cmp.Or(f.Status, "all")
+	data["Statuses"] = []string{domain.LoanStatusActive, domain.LoanStatusOverdue, domain.LoanStatusReturned}
+	data["UserID"] = q.Get("user_id")
+
+	h.r.Render(w, "circulation.html", data)
+}
+
+// POST /ui/circulation/checkout (barcode + user_id)
+func (h *Handler) uiCheckoutPOST(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	userID := mustUint64(strings.TrimSpace(r.FormValue("user_id")))
+	if _, err := h.circ.Checkout(r.Context(), r.FormValue("barcode"), userID); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, "/ui/circulation", http.StatusSeeOther)
+}
+
+// POST /ui/circulation/return (barcode)
+func (h *Handler) uiReturnPOST(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	if _, err := h.circ.Return(r.Context(), r.FormValue("barcode")); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, "/ui/circulation", http.StatusSeeOther)
+}
+
+// POST /ui/loans/{id}/renew
+func (h *Handler) uiRenewLoanPOST(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if _, err := h.circ.Renew(r.Context(), id); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, "/ui/circulation", http.StatusSeeOther)
+}
+
+// POST /ui/books/{id}/copies (alta de ejemplar desde el detalle del libro)
+func (h *Handler) uiAddCopyPOST(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if err := r.ParseForm(); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	if _, err := h.circ.AddCopy(r.Context(), id, r.FormValue("barcode"), r.FormValue("location")); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/ui/books/%d", id), http.StatusSeeOther)
+}
+
+// POST /ui/books/{id}/seats (puestos de la licencia digital)
+func (h *Handler) uiSeatsPOST(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if err := r.ParseForm(); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	seats, err := strconv.Atoi(strings.TrimSpace(r.FormValue("seats")))
+	if err != nil {
+		h.uiError(w, r, fmt.Errorf("%w: seats must be a number", domain.ErrValidation))
+		return
+	}
+	if _, err := h.circ.SetSeats(r.Context(), id, seats); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/ui/books/%d", id), http.StatusSeeOther)
+}
+
+// POST /ui/books/{id}/borrow (préstamo digital para el usuario actual)
+func (h *Handler) uiBorrowPOST(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if _, err := h.circ.Borrow(r.Context(), id); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/ui/books/%d#lectura", id), http.StatusSeeOther)
+}
+
+// POST /ui/loans/{id}/return (devolución de un préstamo digital)
+func (h *Handler) uiReturnSeatPOST(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	l, err := h.circ.ReturnSeat(r.Context(), id)
+	if err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/ui/books/%d", l.BookID()), http.StatusSeeOther)
+}
+
+// POST /ui/copies/{id} (ubicación y estado)
+func (h *Handler) uiCopyUpdatePOST(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if err := r.ParseForm(); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+
+	location, status := r.FormValue("location"), r.FormValue("status")
+	c, err := h.circ.UpdateCopy(r.Context(), id, usecase.UpdateCopyInput{Location: &location, Status: &status})
+	if err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/ui/books/%d", c.BookID()), http.StatusSeeOther)
+}
+
+// GET /api/books/{id}/availability
+func (h *Handler) apiBookAvailability(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	a, err := h.circ.Availability(r.Context(), id)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, availabilityToDTO(id, a))
+}
+
+// POST /api/books/{id}/holds {"user_id": 7} (sin user_id: el usuario actual)
+func (h *Handler) apiPlaceHold(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+
+	var in struct {
+		UserID uint64 `json:"user_id"`
+	}
+	// Cuerpo opcional: sin JSON se reserva para el usuario actual
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil && !errors.Is(err, io.EOF) {
+		writeErr(w, err)
+		return
+	}
+
+	hold, err := h.circ.PlaceHold(r.Context(), id, in.UserID)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, holdToDTO(hold))
+}
+
+// GET /api/holds?user_id=&book_id=&status=active|waiting|ready|fulfilled|cancelled|expired&page=&page_size=&dir=
+// Sin loans:write solo se listan las reservas propias.
+func (h *Handler) apiListHolds(w http.ResponseWriter, r *http.Request) {
+	f, err := holdFilterFromQuery(r)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	page, err := h.circ.Holds(r.Context(), f)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writePage(w, r, page, holdsToDTO(page.Items))
+}
+
+// POST /api/holds/{id}/cancel (el propio usuario o el mostrador)
+func (h *Handler) apiCancelHold(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	hold, err := h.circ.CancelHold(r.Context(), id)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, holdToDTO(hold))
+}
+
+// GET /ui/holds?user_id=&book_id=&status=&page=
+// Sin ?status= se muestran las activas (en la cola o listas para retirar).
+func (h *Handler) uiHoldsGET(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	switch q.Get("status") {
+	case "":
+		q.Set("status", domain.HoldStatusActive)
+	case "all":
+		q.Del("status")
+	}
+	r.URL.RawQuery = q.Encode()
+
+	f, err := holdFilterFromQuery(r)
+	if err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	page, err := h.circ.Holds(r.Context(), f)
+	if err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+
+	data := h.viewBase(r, "Reservas", true)
+	data["Holds"] = holdsToDTO(page.Items)
+	data["Pager"] = newPagerView(r, page)
+	data["Status"] = cmp.Or(f.Status, "all")
+	data["Statuses"] = []string{
+		domain.HoldStatusActive, string(domain.HoldWaiting), string(domain.HoldReady),
+		string(domain.HoldFulfilled), string(domain.HoldCancelled), string(domain.HoldExpired),
+	}
+	data["UserID"] = q.Get("user_id")
+	data["PickupDays"] = domain.HoldPickupDays
+
+	h.r.Render(w, "holds.html", data)
+}
+
+// POST /ui/books/{id}/holds (el usuario actual se pone en la cola)
+func (h *Handler) uiPlaceHoldPOST(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if _, err := h.circ.PlaceHold(r.Context(), id, 0); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/ui/books/%d", id), http.StatusSeeOther)
+}
+
+// POST /ui/holds/{id}/cancel
+func (h *Handler) uiCancelHoldPOST(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if _, err := h.circ.CancelHold(r.Context(), id); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, "/ui/holds", http.StatusSeeOther)
+}
+
+// loanFilterFromQuery arma domain.LoanFilter desde ?user_id=&book_id=&status= más la paginación.
+func loanFilterFromQuery(r *http.Request) (domain.LoanFilter, error) {
+	q := r.URL.Query()
+	p, err := pageRequestFromQuery(q)
+	return domain.LoanFilter{
+		UserID:      mustUint64(strings.TrimSpace(q.Get("user_id"))),
+		BookID:      mustUint64(strings.TrimSpace(q.Get("book_id"))),
+		Status:      strings.TrimSpace(q.Get("status")),
+		PageRequest: p,
+	}, err
+}
+
+// holdFilterFromQuery arma domain.HoldFilter desde ?user_id=&book_id=&status= más la paginación.
+func holdFilterFromQuery(r *http.Request) (domain.HoldFilter, error) {
+	q := r.URL.Query()
+	p, err := pageRequestFromQuery(q)
+	return domain.HoldFilter{
+		UserID:      mustUint64(strings.TrimSpace(q.Get("user_id"))),
+		BookID:      mustUint64(strings.TrimSpace(q.Get("book_id"))),
+		Status:      strings.TrimSpace(q.Get("status")),
+		PageRequest: p,
+	}, err
+}
@@ -0,0 +1,140 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+//
+// ==============================
+// PORTADAS - /api/books/{id}/cover, /covers/{id}/{size} y sección "Portada"
+// ==============================
+//
+
+// PUT /api/books/{id}/cover (multipart/form-data, campo "cover")
+func (h *Handler) apiPutCover(w http.ResponseWriter, r *http.Request) {
+	c, err := h.uploadCover(r, mustUint64(mux.Vars(r)["id"]))
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, coverToDTO(c))
+}
+
+// DELETE /api/books/{id}/cover
+func (h *Handler) apiDeleteCover(w http.ResponseWriter, r *http.Request) {
+	if err := h.covers.Delete(r.Context(), mustUint64(mux.Vars(r)["id"])); err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /ui/books/{id}/cover (multipart/form-data, campo "cover")
+func (h *Handler) uiCoverPOST(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if _, err := h.uploadCover(r, id); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, "/ui/books/"+strconv.FormatUint(id, 10), http.StatusSeeOther)
+}
+
+// POST /ui/books/{id}/cover/delete
+func (h *Handler) uiCoverDeletePOST(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if err := h.covers.Delete(r.Context(), id); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, "/ui/books/"+strconv.FormatUint(id, 10), http.StatusSeeOther)
+}
+
+// GET /covers/{id}/{size}[?v=versión]
+//
+// Sirve la miniatura JPEG con ETag (responde 304 a If-None-Match). Si la
+// URL trae la versión vigente el contenido de esa URL no cambia nunca y se
+// puede guardar un año; sin versión el navegador debe revalidar cada vez.
+func (h *Handler) coverGET(w http.ResponseWriter, r *http.Request) {
+	size, err := domain.ParseCoverSize(mux.Vars(r)["size"])
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	data, c, err := h.covers.Thumbnail(r.Context(), mustUint64(mux.Vars(r)["id"]), size)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/jpeg")
+	w.Header().Set("ETag", `"`+c.SHA256()+"-"+string(size)+`"`)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if r.URL.Query().Get("v") == c.Version() {
+		w.Header().Set("Cache-Control", "private, max-age=31536000, immutable")
+	} else {
+		w.Header().Set("Cache-Control", "private, no-cache")
+	}
+	http.ServeContent(w, r, "", c.UpdatedAt(), bytes.NewReader(data))
+}
+
+// uploadCover busca el campo "cover" del multipart y lo pasa al servicio
+// sin guardarlo antes (el servicio corta al superar el máximo).
+func (h *Handler) uploadCover(r *http.Request, bookID uint64) (*domain.Cover, error) {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, fmt.Errorf("%w: expected multipart/form-data with a \"cover\" field", domain.ErrValidation)
+	}
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: no image received", domain.ErrValidation)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() != "cover" || part.FileName() == "" {
+			part.Close()
+			continue
+		}
+		defer part.Close()
+		return h.covers.Upload(r.Context(), bookID, part)
+	}
+}
+
+// setBookCover completa la portada del libro (si tiene).
+func (h *Handler) setBookCover(ctx context.Context, dto *BookDTO) {
+	if c, err := h.covers.Get(ctx, dto.ID); err == nil {
+		dto.Cover = coverToDTO(c)
+	}
+}
+
+// setHitCovers completa las portadas de un listado con una sola consulta.
+// Un error solo deja el listado sin imágenes.
+func (h *Handler) setHitCovers(ctx context.Context, hits []BookHitDTO) {
+	if len(hits) == 0 {
+		return
+	}
+	ids := make([]uint64, len(hits))
+	for i := range hits {
+		ids[i] = hits[i].ID
+	}
+	covers, err := h.covers.ByBooks(ctx, ids)
+	if err != nil {
+		return
+	}
+	for i := range hits {
+		if c, ok := covers[hits[i].ID]; ok {
+			hits[i].Cover = coverToDTO(c)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/usecase"
+)
+
+//
+// ==============================
+// ADJUNTOS - /api/books/{id}/files, /api/files/{id} y sección "Archivos"
+// ==============================
+//
+
+// GET /api/books/{id}/files
+func (h *Handler) apiBookFiles(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	list, err := h.files.Files(r.Context(), id)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"files": filesToDTO(list)})
+}
+
+// POST /api/books/{id}/files (multipart/form-data, uno o más campos "file")
+func (h *Handler) apiUploadFiles(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	list, err := h.uploadParts(w, r, id)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{"files": filesToDTO(list)})
+}
+
+// GET /api/files/{id}
+func (h *Handler) apiGetFile(w http.ResponseWriter, r *http.Request) {
+	f, err := h.files.Get(r.Context(), mustUint64(mux.Vars(r)["id"]))
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, fileToDTO(f))
+}
+
+// GET /api/files/{id}/download[?inline=1] (admite Range)
+func (h *Handler) apiDownloadFile(w http.ResponseWriter, r *http.Request) {
+	if err := h.serveFile(w, r); err != nil {
+		writeErr(w, err)
+	}
+}
+
+// DELETE /api/files/{id}
+func (h *Handler) apiDeleteFile(w http.ResponseWriter, r *http.Request) {
+	if err := h.files.Delete(r.Context(), mustUint64(mux.Vars(r)["id"])); err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /ui/books/{id}/files (multipart/form-data, campo "file" múltiple)
+func (h *Handler) uiUploadFilesPOST(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if _, err := h.uploadParts(w, r, id); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, "/ui/books/"+strconv.FormatUint(id, 10)+"#archivos", http.StatusSeeOther)
+}
+
+// GET /ui/files/{id}/download[?inline=1]
+func (h *Handler) uiDownloadFileGET(w http.ResponseWriter, r *http.Request) {
+	if err := h.serveFile(w, r); err != nil {
+		h.uiError(w, r, err)
+	}
+}
+
+// POST /ui/files/{id}/delete
+func (h *Handler) uiDeleteFilePOST(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	f, err := h.files.Get(r.Context(), id)
+	if err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	if err := h.files.Delete(r.Context(), id); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, "/ui/books/"+strconv.FormatUint(f.BookID(), 10)+"#archivos", http.StatusSeeOther)
+}
+
+// uploadParts recorre el multipart sin cargarlo en memoria ni en disco
+// (r.ParseMultipartForm lo haría) y sube cada campo "file" a medida que llega.
+// Si un archivo falla, los anteriores quedan guardados.
+func (h *Handler) uploadParts(w http.ResponseWriter, r *http.Request, bookID uint64) ([]*domain.BookFile, error) {
+	// Un archivo grande tarda más que el ReadTimeout del servidor
+	_ = http.NewResponseController(w).SetReadDeadline(time.Time{})
+
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, fmt.Errorf("%w: expected multipart/form-data with a \"file\" field", domain.ErrValidation)
+	}
+	out := []*domain.BookFile{}
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return out, err
+		}
+		if part.FormName() != "file" || part.FileName() == "" {
+			part.Close()
+			continue
+		}
+		f, err := h.files.Upload(r.Context(), bookID, part.FileName(), part)
+		part.Close()
+		if err != nil {
+			return out, err
+		}
+		out = append(out, f)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("%w: no file received", domain.ErrValidation)
+	}
+	return out, nil
+}
+
+// serveFile envía el contenido con http.ServeContent, que resuelve Range,
+// If-Range, If-None-Match (ETag = SHA-256) y HEAD. Registra una DESCARGA
+// por pedido completo o primer tramo (un reproductor de audio pide muchos
+// rangos de un mismo archivo). Solo retorna error si aún no escribió nada.
+func (h *Handler) serveFile(w http.ResponseWriter, r *http.Request) error {
+	f, content, err := h.files.Open(r.Context(), mustUint64(mux.Vars(r)["id"]))
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	if rng := r.Header.Get("Range"); r.Method == http.MethodGet && (rng == "" || strings.HasPrefix(rng, "bytes=0-")) {
+		if u, ok := usecase.UserFromContext(r.Context()); ok {
+			_ = h.books.RecordAccess(r.Context(), u.ID(), f.BookID(), domain.AccessDescarga)
+		}
+	}
+
+	disposition := "attachment"
+	if r.URL.Query().Get("inline") != "" {
+		disposition = "inline" // reproducir/ver en el navegador
+	}
+	w.Header().Set("Content-Type", f.ContentType())
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": f.Name()}))
+	w.Header().Set("ETag", `"`+f.SHA256()+`"`)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	// Una descarga grande tarda más que el WriteTimeout del servidor
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	http.ServeContent(w, r, f.Name(), f.CreatedAt(), content)
+	return nil
+}
@@ -0,0 +1,220 @@
+package http
+
+import (
+	"cmp"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+//
+// ==============================
+// PROGRESO DE LECTURA - /api/books/{id}/progress, /api/me/progress y /ui/reading
+// ==============================
+//
+
+// GET /api/books/{id}/progress (el del usuario actual; 404 si no lo empezó)
+func (h *Handler) apiGetProgress(w http.ResponseWriter, r *http.Request) {
+	p, err := h.progress.Get(r.Context(), mustUint64(mux.Vars(r)["id"]))
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, progressToDTO(p))
+}
+
+// PUT /api/books/{id}/progress {"page": 12, "total_pages": 300, "percent": 4, "location": "epubcfi(...)", "finished": false}
+// Todos los campos son opcionales; sin "finished" el libro queda terminado al llegar al 100%.
+func (h *Handler) apiPutProgress(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		Page       int     `json:"page"`
+		TotalPages int     `json:"total_pages"`
+		Percent    float64 `json:"percent"`
+		Location   string  `json:"location"`
+		Finished   *bool   `json:"finished"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	pos := domain.ReadingPosition{Page: in.Page, TotalPages: in.TotalPages, Percent: in.Percent, Location: in.Location}
+	p, err := h.progress.Update(r.Context(), mustUint64(mux.Vars(r)["id"]), pos, in.Finished)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, progressToDTO(p))
+}
+
+// DELETE /api/books/{id}/progress
+func (h *Handler) apiDeleteProgress(w http.ResponseWriter, r *http.Request) {
+	if err := h.progress.Delete(r.Context(), mustUint64(mux.Vars(r)["id"])); err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /api/me/progress?status=reading|finished&page=&page_size=&sort=last_read_at|percent|created_at&dir=
+func (h *Handler) apiMyProgress(w http.ResponseWriter, r *http.Request) {
+	f, err := progressFilterFromQuery(r)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	page, err := h.progress.Mine(r.Context(), f)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writePage(w, r, page, progressListToDTO(page.Items))
+}
+
+// GET /api/books/{id}/readers?status=&page=&page_size=&sort=&dir= (stats:read)
+func (h *Handler) apiBookReaders(w http.ResponseWriter, r *http.Request) {
+	f, err := progressFilterFromQuery(r)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	f.BookID = mustUint64(mux.Vars(r)["id"])
+	page, err := h.progress.Readers(r.Context(), f)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writePage(w, r, page, progressListToDTO(page.Items))
+}
+
+// GET /api/progress/completion?book_id=&page=&page_size=&sort=readers|rate|avg_percent|title&dir= (stats:read)
+func (h *Handler) apiCompletion(w http.ResponseWriter, r *http.Request) {
+	f, err := completionFilterFromQuery(r)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	page, err := h.progress.Completion(r.Context(), f)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writePage(w, r, page, completionToDTO(page.Items))
+}
+
+// POST /ui/books/{id}/progress (page, total_pages, percent, finished)
+//
+// La casilla "terminado" solo se envía marcada; was_finished indica que
+// estaba marcada al mostrar el formulario, para distinguir "la desmarqué"
+// de "dejar que se marque sola al llegar al final".
+func (h *Handler) uiProgressPOST(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if err := r.ParseForm(); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+
+	page, _ := strconv.Atoi(strings.TrimSpace(r.FormValue("page")))
+	total, _ := strconv.Atoi(strings.TrimSpace(r.FormValue("total_pages")))
+	percent, _ := strconv.ParseFloat(strings.TrimSpace(r.FormValue("percent")), 64)
+	var finished *bool
+	if done := r.FormValue("finished") != ""; done || r.FormValue("was_finished") != "" {
+		finished = &done
+	}
+
+	pos := domain.ReadingPosition{Page: page, TotalPages: total, Percent: percent, Location: r.FormValue("location")}
+	if _, err := h.progress.Update(r.Context(), id, pos, finished); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, "/ui/books/"+strconv.FormatUint(id, 10)+"#lectura", http.StatusSeeOther)
+}
+
+// POST /ui/books/{id}/progress/delete
+func (h *Handler) uiProgressDeletePOST(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if err := h.progress.Delete(r.Context(), id); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, "/ui/books/"+strconv.FormatUint(id, 10), http.StatusSeeOther)
+}
+
+// GET /ui/reading?status=&page=
+// Los libros que lee o leyó el usuario actual.
+func (h *Handler) uiReadingGET(w http.ResponseWriter, r *http.Request) {
+	f, err := progressFilterFromQuery(r)
+	if err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	page, err := h.progress.Mine(r.Context(), f)
+	if err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+
+	data := h.viewBase(r, "Mis lecturas", true)
+	data["Items"] = progressListToDTO(page.Items)
+	data["Pager"] = newPagerView(r, page)
+	data["Status"] = cmp.Or(f.Status, "all")
+	data["Statuses"] = []string{domain.ProgressStatusReading, domain.ProgressStatusFinished}
+
+	h.r.Render(w, "reading.html", data)
+}
+
+// GET /ui/reading/completion?page=&sort=&dir=
+// Tasas de finalización por libro (stats:read).
+func (h *Handler) uiCompletionGET(w http.ResponseWriter, r *http.Request) {
+	f, err := completionFilterFromQuery(r)
+	if err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	f.Sort = cmp.Or(f.Sort, "readers")
+	if f.Dir == "" && f.Sort != "title" {
+		f.Dir = domain.SortDesc // los números, de mayor a menor
+	}
+	page, err := h.progress.Completion(r.Context(), f)
+	if err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+
+	data := h.viewBase(r, "Finalización de lecturas", true)
+	data["Items"] = completionToDTO(page.Items)
+	data["Pager"] = newPagerView(r, page)
+	data["Sort"] = f.Sort
+
+	h.r.Render(w, "completion.html", data)
+}
+
+// progressFilterFromQuery arma domain.ProgressFilter desde ?book_id=&status= más la paginación.
+func progressFilterFromQuery(r *http.Request) (domain.ProgressFilter, error) {
+	q := r.URL.Query()
+	p, err := pageRequestFromQuery(q)
+	status := strings.TrimSpace(q.Get("status"))
+	if status == "all" {
+		status = ""
+	}
+	return domain.ProgressFilter{
+		BookID:      mustUint64(strings.TrimSpace(q.Get("book_id"))),
+		Status:      status,
+		PageRequest: p,
+	}, err
+}
+
+// completionFilterFromQuery arma domain.CompletionFilter desde ?book_id= más la paginación.
+func completionFilterFromQuery(r *http.Request) (domain.CompletionFilter, error) {
+	q := r.URL.Query()
+	p, err := pageRequestFromQuery(q)
+	return domain.CompletionFilter{
+		BookID:      mustUint64(strings.TrimSpace(q.Get("book_id"))),
+		PageRequest: p,
+	}, err
+}
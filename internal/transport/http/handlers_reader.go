@@ -0,0 +1,157 @@
+package http
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+//
+// ==============================
+// LECTOR INTEGRADO - /ui/books/{id}/read
+// ==============================
+//
+
+// readerCSP acota lo que puede cargar la página del lector: el capítulo ya
+// viene saneado, pero si algo se escapara no podría traer recursos de
+// afuera, embeber objetos ni ejecutar scripts: solo corre el script de la
+// página, que lleva el nonce de cada respuesta.
+func readerCSP(nonce string) string {
+	return "default-src 'self'; img-src 'self'; style-src 'self' 'unsafe-inline'; " +
+		"script-src 'nonce-" + nonce + "'; object-src 'none'; base-uri 'none'; form-action 'self'"
+}
+
+// newNonce genera el nonce de CSP de una respuesta (16 bytes aleatorios en
+// base64 URL-safe).
+func newNonce() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// GET /ui/books/{id}/read?ch=N
+// EPUB: muestra el capítulo N (sin ch, donde quedó el usuario) y guarda la
+// posición. PDF: el visor del navegador en la página guardada.
+func (h *Handler) uiReaderGET(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	at, _ := strconv.Atoi(strings.TrimSpace(r.URL.Query().Get("ch")))
+	if at < 0 {
+		at = 0
+	}
+
+	page, err := h.reader.Read(r.Context(), id, at, readerLinks(id))
+	if err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+
+	nonce, err := newNonce()
+	if err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+
+	data := h.viewBase(r, page.Book.Title(), true)
+	data["Reader"] = readerToDTO(page)
+	data["AnnotationColors"] = annotationColorOptions()
+	data["AnnotationVisibilities"] = annotationVisibilityOptions()
+	data["ScriptNonce"] = nonce
+	w.Header().Set("Content-Security-Policy", readerCSP(nonce))
+	h.r.Render(w, "reader.html", data)
+}
+
+// POST /ui/books/{id}/read (page, total_pages)
+// Guarda la página por la que va el usuario en un PDF.
+func (h *Handler) uiReaderPagePOST(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if err := r.ParseForm(); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	page, _ := strconv.Atoi(strings.TrimSpace(r.FormValue("page")))
+	total, _ := strconv.Atoi(strings.TrimSpace(r.FormValue("total_pages")))
+	if _, err := h.reader.SavePage(r.Context(), id, page, total); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, readerURL(id), http.StatusSeeOther)
+}
+
+// POST /ui/books/{id}/read/ping
+// Latido de la página del lector mientras está a la vista: mantiene abierta
+// la sesión de lectura (204 sin cuerpo).
+func (h *Handler) uiReaderPingPOST(w http.ResponseWriter, r *http.Request) {
+	if err := h.reader.Ping(r.Context(), mustUint64(mux.Vars(r)["id"])); err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /ui/books/{id}/read/res?path=
+// Imágenes del EPUB que referencia el capítulo (requiere books:download).
+func (h *Handler) uiReaderResourceGET(w http.ResponseWriter, r *http.Request) {
+	data, mediaType, err := h.reader.Resource(r.Context(), mustUint64(mux.Vars(r)["id"]), r.URL.Query().Get("path"))
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", mediaType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+}
+
+// GET|HEAD /ui/books/{id}/read/file
+// El PDF que se lee, en línea para el visor (requiere books:download, pero
+// no cuenta como descarga).
+func (h *Handler) uiReaderFileGET(w http.ResponseWriter, r *http.Request) {
+	f, content, err := h.reader.PDF(r.Context(), mustUint64(mux.Vars(r)["id"]))
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", f.ContentType())
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": f.Name()}))
+	w.Header().Set("ETag", `"`+f.SHA256()+`"`)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	http.ServeContent(w, r, f.Name(), f.CreatedAt(), content)
+}
+
+// readerURL es la página del lector de un libro.
+func readerURL(bookID uint64) string {
+	return "/ui/books/" + strconv.FormatUint(bookID, 10) + "/read"
+}
+
+// readerLinks arma las URLs del lector para los enlaces internos del EPUB.
+func readerLinks(bookID uint64) domain.EbookLinks {
+	base := readerURL(bookID)
+	return domain.EbookLinks{
+		Chapter: func(index int, fragment string) string {
+			u := fmt.Sprintf("%s?ch=%d", base, index+1)
+			if fragment != "" {
+				u += "#" + url.PathEscape(fragment)
+			}
+			return u
+		},
+		Resource: func(path string) string {
+			return base + "/res?path=" + url.QueryEscape(path)
+		},
+	}
+}
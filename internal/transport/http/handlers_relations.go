@@ -0,0 +1,88 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+//
+// ==============================
+// RELACIONES ENTRE LIBROS - /api/books/{id}/relations y sección "Relacionados"
+// ==============================
+//
+
+// GET /api/books/{id}/relations
+func (h *Handler) apiBookRelations(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	list, err := h.books.Relations(r.Context(), id)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"relations": relatedToDTO(list)})
+}
+
+// POST /api/books/{id}/relations {"to_book_id": 3, "type": "sequel", "bidirectional": true}
+// Se lee "el libro {id} es <type> de to_book_id".
+func (h *Handler) apiAddBookRelation(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+
+	var in struct {
+		ToBookID      uint64 `json:"to_book_id"`
+		Type          string `json:"type"`
+		Bidirectional bool   `json:"bidirectional"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	rel, err := h.books.AddRelation(r.Context(), id, in.ToBookID, in.Type, in.Bidirectional)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, relationToDTO(rel))
+}
+
+// DELETE /api/books/{id}/relations/{rel}
+func (h *Handler) apiDeleteBookRelation(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if err := h.books.DeleteRelation(r.Context(), mustUint64(vars["id"]), mustUint64(vars["rel"])); err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /ui/books/{id}/relations (type, to_book_id, bidirectional)
+func (h *Handler) uiAddBookRelationPOST(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if err := r.ParseForm(); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+
+	toID := mustUint64(strings.TrimSpace(r.FormValue("to_book_id")))
+	bidirectional := r.FormValue("bidirectional") != ""
+	if _, err := h.books.AddRelation(r.Context(), id, toID, r.FormValue("type"), bidirectional); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, "/ui/books/"+strconv.FormatUint(id, 10), http.StatusSeeOther)
+}
+
+// POST /ui/books/{id}/relations/{rel}/delete
+func (h *Handler) uiDeleteBookRelationPOST(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := mustUint64(vars["id"])
+	if err := h.books.DeleteRelation(r.Context(), id, mustUint64(vars["rel"])); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, "/ui/books/"+strconv.FormatUint(id, 10), http.StatusSeeOther)
+}
@@ -0,0 +1,350 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/usecase"
+)
+
+//
+// ==============================
+// RESEÑAS - /api/reviews, /api/books/{id}/reviews y /ui/reviews
+// ==============================
+//
+
+// reviewInput es el cuerpo JSON para escribir o editar una reseña
+// (al editar, los campos ausentes no cambian).
+type reviewInput struct {
+	Rating *int    `json:"rating"`
+	Text   *string `json:"text"`
+}
+
+// GET /api/books/{id}/reviews?rating=&page=&page_size=&sort=created_at|updated_at|rating&dir=
+// Las reseñas aprobadas del libro.
+func (h *Handler) apiBookReviews(w http.ResponseWriter, r *http.Request) {
+	f, err := reviewFilterFromQuery(r)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	page, err := h.reviews.ByBook(r.Context(), mustUint64(mux.Vars(r)["id"]), f)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writePage(w, r, page, reviewListToDTO(page.Items))
+}
+
+// GET /api/books/{id}/rating
+// Promedio y distribución de estrellas (solo reseñas aprobadas).
+func (h *Handler) apiBookRating(w http.ResponseWriter, r *http.Request) {
+	sum, err := h.reviews.Summary(r.Context(), mustUint64(mux.Vars(r)["id"]))
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, ratingSummaryToDTO(sum))
+}
+
+// POST /api/books/{id}/reviews {"rating": 4, "text": "..."}
+// Una reseña por usuario y libro; queda pendiente de moderación.
+func (h *Handler) apiCreateReview(w http.ResponseWriter, r *http.Request) {
+	var in reviewInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeErr(w, err)
+		return
+	}
+	if in.Rating == nil {
+		writeErr(w, fmt.Errorf("%w: rating is required", domain.ErrValidation))
+		return
+	}
+	text := ""
+	if in.Text != nil {
+		text = *in.Text
+	}
+	rv, err := h.reviews.Create(r.Context(), mustUint64(mux.Vars(r)["id"]), *in.Rating, text)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, reviewToDTO(rv))
+}
+
+// GET /api/reviews/{id}
+func (h *Handler) apiGetReview(w http.ResponseWriter, r *http.Request) {
+	rv, err := h.reviews.Get(r.Context(), mustUint64(mux.Vars(r)["id"]))
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, reviewToDTO(rv))
+}
+
+// PATCH /api/reviews/{id} {"rating": 5, "text": "..."} (solo el autor; vuelve a moderación)
+func (h *Handler) apiUpdateReview(w http.ResponseWriter, r *http.Request) {
+	var in reviewInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeErr(w, err)
+		return
+	}
+	rv, err := h.reviews.Update(r.Context(), mustUint64(mux.Vars(r)["id"]), usecase.UpdateReviewInput(in))
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, reviewToDTO(rv))
+}
+
+// DELETE /api/reviews/{id} (el autor, o reviews:moderate)
+func (h *Handler) apiDeleteReview(w http.ResponseWriter, r *http.Request) {
+	if err := h.reviews.Delete(r.Context(), mustUint64(mux.Vars(r)["id"])); err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /api/reviews/{id}/history (el autor, o reviews:moderate)
+// Versiones anteriores, de la más antigua a la más reciente.
+func (h *Handler) apiReviewHistory(w http.ResponseWriter, r *http.Request) {
+	list, err := h.reviews.History(r.Context(), mustUint64(mux.Vars(r)["id"]))
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"revisions": revisionsToDTO(list)})
+}
+
+// GET /api/me/reviews?book_id=&status=&rating=&page=&page_size=&sort=&dir=
+func (h *Handler) apiMyReviews(w http.ResponseWriter, r *http.Request) {
+	f, err := reviewFilterFromQuery(r)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	page, err := h.reviews.MyReviews(r.Context(), f)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writePage(w, r, page, reviewListToDTO(page.Items))
+}
+
+// GET /api/reviews/moderation?status=pending,hidden&book_id=&user_id=&rating=&page=&sort=&dir= (reviews:moderate)
+// Cola de moderación: por defecto las pendientes, la más antigua primero.
+func (h *Handler) apiReviewQueue(w http.ResponseWriter, r *http.Request) {
+	f, err := reviewFilterFromQuery(r)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	f.UserID = mustUint64(strings.TrimSpace(r.URL.Query().Get("user_id")))
+	page, err := h.reviews.Queue(r.Context(), f)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writePage(w, r, page, reviewListToDTO(page.Items))
+}
+
+// POST /api/reviews/{id}/moderate {"status": "approved"|"hidden"} (reviews:moderate)
+func (h *Handler) apiModerateReview(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeErr(w, err)
+		return
+	}
+	rv, err := h.reviews.Moderate(r.Context(), mustUint64(mux.Vars(r)["id"]), reviewStatus(in.Status))
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, reviewToDTO(rv))
+}
+
+// POST /ui/books/{id}/reviews (rating, text)
+func (h *Handler) uiReviewsPOST(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if err := r.ParseForm(); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	rating, _ := strconv.Atoi(r.FormValue("rating"))
+	if _, err := h.reviews.Create(r.Context(), id, rating, r.FormValue("text")); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, "/ui/books/"+strconv.FormatUint(id, 10)+"#resenas", http.StatusSeeOther)
+}
+
+// POST /ui/reviews/{id} (rating, text, next)
+func (h *Handler) uiReviewUpdatePOST(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	rating, _ := strconv.Atoi(r.FormValue("rating"))
+	text := r.FormValue("text")
+	in := usecase.UpdateReviewInput{Rating: &rating, Text: &text}
+	if _, err := h.reviews.Update(r.Context(), mustUint64(mux.Vars(r)["id"]), in); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, safeNext(r.FormValue("next")), http.StatusSeeOther)
+}
+
+// POST /ui/reviews/{id}/delete (next)
+func (h *Handler) uiReviewDeletePOST(w http.ResponseWriter, r *http.Request) {
+	if err := h.reviews.Delete(r.Context(), mustUint64(mux.Vars(r)["id"])); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, safeNext(r.FormValue("next")), http.StatusSeeOther)
+}
+
+// POST /ui/reviews/{id}/moderate (status, next)
+func (h *Handler) uiReviewModeratePOST(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	if _, err := h.reviews.Moderate(r.Context(), mustUint64(mux.Vars(r)["id"]), reviewStatus(r.FormValue("status"))); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, safeNext(r.FormValue("next")), http.StatusSeeOther)
+}
+
+// GET /ui/reviews/moderation?status=&rating=&page=
+// Cola de moderación con los botones para aprobar u ocultar.
+func (h *Handler) uiReviewQueueGET(w http.ResponseWriter, r *http.Request) {
+	f, err := reviewFilterFromQuery(r)
+	if err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	page, err := h.reviews.Queue(r.Context(), f)
+	if err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+
+	data := h.viewBase(r, "Moderación de reseñas", true)
+	data["Items"] = reviewListToDTO(page.Items)
+	data["Pager"] = newPagerView(r, page)
+	data["Status"] = r.URL.Query().Get("status")
+	data["Rating"] = f.Rating
+	data["Statuses"] = reviewStatusOptions()
+	data["Ratings"] = ratingOptions()
+	data["Next"] = r.URL.RequestURI()
+
+	h.r.Render(w, "reviews_moderation.html", data)
+}
+
+// setBookReviews completa el detalle del libro: valoración, reseñas
+// aprobadas (las más recientes) y la reseña propia con su historial.
+func (h *Handler) setBookReviews(r *http.Request, data map[string]any, bookID uint64) {
+	if sum, err := h.reviews.Summary(r.Context(), bookID); err == nil {
+		data["Rating"] = ratingSummaryToDTO(sum)
+	}
+	if page, err := h.reviews.ByBook(r.Context(), bookID, domain.ReviewFilter{}); err == nil {
+		data["Reviews"] = reviewListToDTO(page.Items)
+		data["ReviewsMore"] = page.Total > len(page.Items)
+	}
+	if mine, err := h.reviews.Mine(r.Context(), bookID); err == nil {
+		data["MyReview"] = reviewToDTO(mine)
+		if hist, err := h.reviews.History(r.Context(), mine.ID()); err == nil {
+			data["MyReviewHistory"] = revisionsToDTO(hist)
+		}
+	}
+	data["Ratings"] = ratingOptions()
+	data["MaxReviewLen"] = domain.MaxReviewTextLen
+}
+
+// setBookRating completa la valoración del libro (si tiene reseñas aprobadas).
+func (h *Handler) setBookRating(ctx context.Context, dto *BookDTO) {
+	if sum, err := h.reviews.Summary(ctx, dto.ID); err == nil && sum.Count > 0 {
+		s := ratingSummaryToDTO(sum)
+		dto.Rating = &s
+	}
+}
+
+// setHitRatings completa la valoración de un listado con una sola consulta.
+// Un error solo deja el listado sin estrellas.
+func (h *Handler) setHitRatings(ctx context.Context, hits []BookHitDTO) {
+	if len(hits) == 0 {
+		return
+	}
+	ids := make([]uint64, len(hits))
+	for i := range hits {
+		ids[i] = hits[i].ID
+	}
+	sums, err := h.reviews.Summaries(ctx, ids)
+	if err != nil {
+		return
+	}
+	for i := range hits {
+		if sum, ok := sums[hits[i].ID]; ok {
+			s := ratingSummaryToDTO(sum)
+			hits[i].Rating = &s
+		}
+	}
+}
+
+// reviewFilterFromQuery arma domain.ReviewFilter desde
+// ?book_id=&status=&rating= más la paginación.
+func reviewFilterFromQuery(r *http.Request) (domain.ReviewFilter, error) {
+	q := r.URL.Query()
+	p, err := pageRequestFromQuery(q)
+	f := domain.ReviewFilter{
+		BookID:      mustUint64(strings.TrimSpace(q.Get("book_id"))),
+		PageRequest: p,
+	}
+	if err != nil {
+		return f, err
+	}
+	for _, v := range splitCSV(q.Get("status")) {
+		f.Statuses = append(f.Statuses, reviewStatus(v))
+	}
+	if s := strings.TrimSpace(q.Get("rating")); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			return f, fmt.Errorf("%w: rating must be a number of stars", domain.ErrValidation)
+		}
+		f.Rating = v
+	}
+	return f, nil
+}
+
+// reviewStatus normaliza el estado recibido (lo valida el dominio).
+func reviewStatus(s string) domain.ReviewStatus {
+	return domain.ReviewStatus(strings.ToLower(strings.TrimSpace(s)))
+}
+
+// ratingOptions lista las estrellas para los formularios (de 5 a 1).
+func ratingOptions() []OptionDTO {
+	out := make([]OptionDTO, 0, domain.MaxReviewRating)
+	for n := domain.MaxReviewRating; n >= domain.MinReviewRating; n-- {
+		out = append(out, OptionDTO{Value: strconv.Itoa(n), Text: stars(n)})
+	}
+	return out
+}
+
+// minRatingOptions lista los mínimos de valoración para buscar libros.
+func minRatingOptions() []OptionDTO {
+	out := make([]OptionDTO, 0, domain.MaxReviewRating-1)
+	for n := domain.MaxReviewRating - 1; n >= domain.MinReviewRating; n-- {
+		out = append(out, OptionDTO{Value: strconv.Itoa(n), Text: stars(n) + " o más"})
+	}
+	return out
+}
@@ -0,0 +1,314 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/usecase"
+)
+
+//
+// ==============================
+// TAXONOMÍA - /api/tags, /api/categories y /ui/taxonomy
+// ==============================
+//
+
+// GET /api/tags?q=&page=&page_size=&sort=name|books|created_at&dir=
+func (h *Handler) apiListTags(w http.ResponseWriter, r *http.Request) {
+	f, err := tagFilterFromQuery(r)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	page, err := h.taxonomy.Tags(r.Context(), f)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writePage(w, r, page, tagsToDTO(page.Items))
+}
+
+// PATCH /api/tags/{id} {"name": "ciencia ficción"}
+func (h *Handler) apiRenameTag(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+
+	var in struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	t, err := h.taxonomy.RenameTag(r.Context(), id, in.Name)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tagsToDTO([]domain.TagUsage{{Tag: t}})[0])
+}
+
+// DELETE /api/tags/{id} (la quita de todos sus libros)
+func (h *Handler) apiDeleteTag(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if err := h.taxonomy.DeleteTag(r.Context(), id); err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /api/tags/{id}/merge {"duplicate_id": 7}
+// La etiqueta {id} se conserva; duplicate_id se fusiona en ella y desaparece.
+func (h *Handler) apiMergeTag(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+
+	var in struct {
+		DuplicateID uint64 `json:"duplicate_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	t, err := h.taxonomy.MergeTags(r.Context(), id, in.DuplicateID)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tagsToDTO([]domain.TagUsage{{Tag: t}})[0])
+}
+
+// GET /api/categories (árbol completo con libros directos y totales)
+func (h *Handler) apiCategoryTree(w http.ResponseWriter, r *http.Request) {
+	tree, err := h.taxonomy.CategoryTree(r.Context())
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"categories": categoryTreeToDTO(tree)})
+}
+
+// POST /api/categories {"name": "Ciencia ficción", "parent_id": 3}
+func (h *Handler) apiCreateCategory(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		Name     string `json:"name"`
+		ParentID uint64 `json:"parent_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	c, err := h.taxonomy.CreateCategory(r.Context(), in.Name, in.ParentID)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, categoryToDTO(c))
+}
+
+// PATCH /api/categories/{id} {"name": "...", "parent_id": 0}
+// parent_id 0 la mueve a la raíz; omitido no cambia el padre.
+func (h *Handler) apiUpdateCategory(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+
+	var in struct {
+		Name     *string `json:"name"`
+		ParentID *uint64 `json:"parent_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	c, err := h.taxonomy.UpdateCategory(r.Context(), id, usecase.UpdateCategoryInput{Name: in.Name, ParentID: in.ParentID})
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, categoryToDTO(c))
+}
+
+// DELETE /api/categories/{id} (solo categorías sin libros ni subcategorías)
+func (h *Handler) apiDeleteCategory(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if err := h.taxonomy.DeleteCategory(r.Context(), id); err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /api/categories/{id}/merge {"duplicate_id": 7}
+// La categoría {id} se conserva; duplicate_id (libros y subcategorías) se fusiona en ella.
+func (h *Handler) apiMergeCategory(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+
+	var in struct {
+		DuplicateID uint64 `json:"duplicate_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	c, err := h.taxonomy.MergeCategories(r.Context(), id, in.DuplicateID)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, categoryToDTO(c))
+}
+
+// GET /ui/taxonomy?q=&page=&sort=&dir=
+func (h *Handler) uiTaxonomyGET(w http.ResponseWriter, r *http.Request) {
+	f, err := tagFilterFromQuery(r)
+	if err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	if f.Sort == "" {
+		f.Sort, f.Dir = "books", domain.SortDesc
+	}
+	page, err := h.taxonomy.Tags(r.Context(), f)
+	if err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	tree, err := h.taxonomy.CategoryTree(r.Context())
+	if err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+
+	data := h.viewBase(r, "Taxonomía", true)
+	data["Tags"] = tagsToDTO(page.Items)
+	data["Pager"] = newPagerView(r, page)
+	data["Categories"] = flattenCategories(categoryTreeToDTO(tree), 0, nil)
+	data["Q"] = f.Q
+	data["Sort"] = f.Sort
+	data["Dir"] = string(f.Dir)
+
+	h.r.Render(w, "taxonomy.html", data)
+}
+
+// POST /ui/tags/{id} (renombrar)
+func (h *Handler) uiTagRenamePOST(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if err := r.ParseForm(); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	if _, err := h.taxonomy.RenameTag(r.Context(), id, r.FormValue("name")); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, "/ui/taxonomy", http.StatusSeeOther)
+}
+
+// POST /ui/tags/{id}/merge (duplicate_id se fusiona en {id})
+func (h *Handler) uiTagMergePOST(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if err := r.ParseForm(); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	if _, err := h.taxonomy.MergeTags(r.Context(), id, mustUint64(strings.TrimSpace(r.FormValue("duplicate_id")))); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, "/ui/taxonomy", http.StatusSeeOther)
+}
+
+// POST /ui/tags/{id}/delete
+func (h *Handler) uiTagDeletePOST(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if err := h.taxonomy.DeleteTag(r.Context(), id); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, "/ui/taxonomy", http.StatusSeeOther)
+}
+
+// POST /ui/categories
+func (h *Handler) uiCategoriesPOST(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	parentID := mustUint64(strings.TrimSpace(r.FormValue("parent_id")))
+	if _, err := h.taxonomy.CreateCategory(r.Context(), r.FormValue("name"), parentID); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, "/ui/taxonomy", http.StatusSeeOther)
+}
+
+// POST /ui/categories/{id} (renombrar y/o mover)
+func (h *Handler) uiCategoryUpdatePOST(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if err := r.ParseForm(); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+
+	name := r.FormValue("name")
+	parentID := mustUint64(strings.TrimSpace(r.FormValue("parent_id")))
+	if _, err := h.taxonomy.UpdateCategory(r.Context(), id, usecase.UpdateCategoryInput{Name: &name, ParentID: &parentID}); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, "/ui/taxonomy", http.StatusSeeOther)
+}
+
+// POST /ui/categories/{id}/merge (duplicate_id se fusiona en {id})
+func (h *Handler) uiCategoryMergePOST(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if err := r.ParseForm(); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	if _, err := h.taxonomy.MergeCategories(r.Context(), id, mustUint64(strings.TrimSpace(r.FormValue("duplicate_id")))); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, "/ui/taxonomy", http.StatusSeeOther)
+}
+
+// POST /ui/categories/{id}/delete
+func (h *Handler) uiCategoryDeletePOST(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if err := h.taxonomy.DeleteCategory(r.Context(), id); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, "/ui/taxonomy", http.StatusSeeOther)
+}
+
+// CategoryRowView es una categoría del árbol aplanada para la tabla de la UI.
+type CategoryRowView struct {
+	CategoryDTO
+	Depth  int    // Nivel en el árbol (0 = raíz)
+	Indent string // Sangría visual según Depth
+}
+
+// flattenCategories recorre el árbol en preorden (padres antes que hijos).
+func flattenCategories(nodes []CategoryDTO, depth int, out []CategoryRowView) []CategoryRowView {
+	for _, n := range nodes {
+		out = append(out, CategoryRowView{CategoryDTO: n, Depth: depth, Indent: strings.Repeat("— ", depth)})
+		out = flattenCategories(n.Children, depth+1, out)
+	}
+	return out
+}
+
+// tagFilterFromQuery arma domain.TagFilter desde ?q= más la paginación.
+func tagFilterFromQuery(r *http.Request) (domain.TagFilter, error) {
+	q := r.URL.Query()
+	p, err := pageRequestFromQuery(q)
+	return domain.TagFilter{Q: strings.TrimSpace(q.Get("q")), PageRequest: p}, err
+}
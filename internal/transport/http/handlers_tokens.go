@@ -0,0 +1,138 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/usecase"
+)
+
+//
+// ==============================
+// API TOKENS - /api/tokens, /ui/tokens
+// ==============================
+//
+
+// tokenExpiry calcula la expiración a partir de "días" (0 => no expira).
+func tokenExpiry(days int) time.Time {
+	if days <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(time.Duration(days) * 24 * time.Hour)
+}
+
+// POST /api/tokens
+func (h *Handler) apiCreateToken(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		Name          string              `json:"name"`
+		Scopes        []domain.Permission `json:"scopes"`
+		ExpiresInDays int                 `json:"expires_in_days"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	token, t, err := h.tokens.Create(r.Context(), in.Name, in.Scopes, tokenExpiry(in.ExpiresInDays))
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	// El secreto solo se devuelve en esta respuesta
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"token":    token,
+		"metadata": apiTokenToDTO(t),
+	})
+}
+
+// GET /api/tokens
+func (h *Handler) apiListTokens(w http.ResponseWriter, r *http.Request) {
+	list, err := h.tokens.List(r.Context())
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, apiTokensToDTO(list))
+}
+
+// DELETE /api/tokens/{id}
+func (h *Handler) apiRevokeToken(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if err := h.tokens.Revoke(r.Context(), id); err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /ui/tokens
+func (h *Handler) uiTokensGET(w http.ResponseWriter, r *http.Request) {
+	h.renderTokens(w, r, "")
+}
+
+// POST /ui/tokens
+func (h *Handler) uiTokensPOST(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+
+	scopes := make([]domain.Permission, 0, len(r.Form["scopes"]))
+	for _, s := range r.Form["scopes"] {
+		scopes = append(scopes, domain.Permission(s))
+	}
+	days, _ := strconv.Atoi(r.FormValue("expires_in_days"))
+
+	token, _, err := h.tokens.Create(r.Context(), r.FormValue("name"), scopes, tokenExpiry(days))
+	if err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+
+	// Se muestra el secreto una única vez (no se redirige para no perderlo)
+	h.renderTokens(w, r, token)
+}
+
+// POST /ui/tokens/{id}/revoke
+func (h *Handler) uiTokenRevokePOST(w http.ResponseWriter, r *http.Request) {
+	id := mustUint64(mux.Vars(r)["id"])
+	if err := h.tokens.Revoke(r.Context(), id); err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, "/ui/tokens", http.StatusSeeOther)
+}
+
+// renderTokens arma la página de tokens; newToken solo viene recién emitido.
+func (h *Handler) renderTokens(w http.ResponseWriter, r *http.Request, newToken string) {
+	list, err := h.tokens.List(r.Context())
+	if err != nil {
+		h.uiError(w, r, err)
+		return
+	}
+
+	// Solo se ofrecen los scopes que el rol del usuario puede otorgar
+	u, _ := usecase.UserFromContext(r.Context())
+	scopes := make([]domain.Permission, 0, len(domain.AllPermissions))
+	for _, p := range domain.AllPermissions {
+		if u.Can(p) {
+			scopes = append(scopes, p)
+		}
+	}
+
+	if newToken != "" {
+		w.Header().Set("Cache-Control", "no-store")
+	}
+
+	data := h.viewBase(r, "Tokens de API", true)
+	data["Tokens"] = apiTokensToDTO(list)
+	data["Scopes"] = scopes
+	data["NewToken"] = newToken
+	h.r.Render(w, "tokens.html", data)
+}
@@ -4,9 +4,19 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/usecase"
 )
 
+// sessionCookieName es la cookie que transporta el token de sesión.
+const sessionCookieName = "sl_session"
+
 // Middleware simple de RequestID (mínimo)
 func requestIDMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -38,6 +48,73 @@ func methodOverrideMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// sessionMiddleware resuelve la cookie de sesión (si existe) y coloca al
+// usuario en el contexto. No bloquea: las rutas protegidas usan require*.
+func sessionMiddleware(auth *usecase.AuthService) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c, err := r.Cookie(sessionCookieName)
+			if err == nil && c.Value != "" {
+				if u, err := auth.Authenticate(r.Context(), c.Value); err == nil {
+					r = r.WithContext(usecase.WithUser(r.Context(), u))
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// bearerMiddleware autentica con "Authorization: Bearer <token>" (rutas /api/*).
+// Si el header viene y el token no es válido, responde 401 sin caer a la cookie.
+func bearerMiddleware(tokens *usecase.TokenService) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := r.Header.Get("Authorization")
+			if h == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			scheme, token, ok := strings.Cut(h, " ")
+			if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+				writeErr(w, domain.ErrUnauthorized)
+				return
+			}
+
+			u, t, err := tokens.Authenticate(r.Context(), strings.TrimSpace(token))
+			if err != nil {
+				writeErr(w, domain.ErrUnauthorized)
+				return
+			}
+
+			ctx := usecase.WithAPIToken(usecase.WithUser(r.Context(), u), t)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// requireUIUser redirige al login si no hay sesión (rutas /ui/*).
+func requireUIUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := usecase.UserFromContext(r.Context()); !ok {
+			http.Redirect(w, r, "/ui/login?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusSeeOther)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireAPIUser responde 401 JSON si no hay identidad (rutas /api/*).
+func requireAPIUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := usecase.UserFromContext(r.Context()); !ok {
+			writeErr(w, domain.ErrUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 func fmtInt64(v int64) string {
 	// sin strconv.FormatInt para mantenerlo simple, pero ok usar strconv:
 	// return strconv.FormatInt(v, 10)
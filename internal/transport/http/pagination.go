@@ -0,0 +1,100 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+// PageDTO es el sobre JSON de los listados paginados.
+type PageDTO[T any] struct {
+	Items      []T `json:"items"`
+	Page       int `json:"page"`
+	PageSize   int `json:"page_size"`
+	Total      int `json:"total"`
+	TotalPages int `json:"total_pages"`
+}
+
+// pageRequestFromQuery lee ?page=&page_size=&sort=&dir=
+// Los valores vacíos quedan en cero (el dominio aplica los valores por defecto).
+func pageRequestFromQuery(q url.Values) (domain.PageRequest, error) {
+	p := domain.PageRequest{
+		Sort: strings.ToLower(strings.TrimSpace(q.Get("sort"))),
+		Dir:  domain.SortDir(strings.ToLower(strings.TrimSpace(q.Get("dir")))),
+	}
+	for name, dst := range map[string]*int{"page": &p.Page, "page_size": &p.PageSize} {
+		s := strings.TrimSpace(q.Get(name))
+		if s == "" {
+			continue
+		}
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 1 {
+			return p, fmt.Errorf("%w: %s must be a positive integer", domain.ErrValidation, name)
+		}
+		*dst = v
+	}
+	return p, nil
+}
+
+// pageURL devuelve la URL actual (ruta + query) apuntando a otra página.
+// Conserva filtros y orden para que la navegación no los pierda.
+func pageURL(r *http.Request, page int) string {
+	q := r.URL.Query()
+	q.Set("page", strconv.Itoa(page))
+	return r.URL.Path + "?" + q.Encode()
+}
+
+// writePage responde el sobre paginado con cabeceras X-Total-Count y Link.
+func writePage[E, D any](w http.ResponseWriter, r *http.Request, p domain.Page[E], items []D) {
+	writeJSON(w, http.StatusOK, newPageDTO(w, r, p, items))
+}
+
+// newPageDTO arma el sobre paginado y fija las cabeceras X-Total-Count y Link
+// (rel first/prev/next/last, RFC 8288). Sirve para respuestas que agregan
+// campos al sobre (p. ej. facetas).
+func newPageDTO[E, D any](w http.ResponseWriter, r *http.Request, p domain.Page[E], items []D) PageDTO[D] {
+	last := p.TotalPages()
+
+	links := []string{fmt.Sprintf(`<%s>; rel="first"`, pageURL(r, 1))}
+	if p.HasPrev() {
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, pageURL(r, min(p.Page-1, last))))
+	}
+	if p.HasNext() {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pageURL(r, p.Page+1)))
+	}
+	links = append(links, fmt.Sprintf(`<%s>; rel="last"`, pageURL(r, last)))
+
+	w.Header().Set("Link", strings.Join(links, ", "))
+	w.Header().Set("X-Total-Count", strconv.Itoa(p.Total))
+	return PageDTO[D]{
+		Items:      items,
+		Page:       p.Page,
+		PageSize:   p.PageSize,
+		Total:      p.Total,
+		TotalPages: last,
+	}
+}
+
+// pagerView es lo que necesita el template "pager" del layout.
+type pagerView struct {
+	Page       int
+	TotalPages int
+	Total      int
+	PrevURL    string // vacío si no hay página anterior
+	NextURL    string // vacío si no hay página siguiente
+}
+
+func newPagerView[E any](r *http.Request, p domain.Page[E]) pagerView {
+	v := pagerView{Page: p.Page, TotalPages: p.TotalPages(), Total: p.Total}
+	if p.HasPrev() {
+		v.PrevURL = pageURL(r, min(p.Page-1, v.TotalPages))
+	}
+	if p.HasNext() {
+		v.NextURL = pageURL(r, p.Page+1)
+	}
+	return v
+}
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"bytes"
 	"fmt"
 	"html/template"
 	"net/http"
@@ -38,6 +39,12 @@ func NewRenderer(templatesDir string) (*Renderer, error) {
 // - Parsea la página (users.html, books.html, etc.)
 // - Ejecuta template "layout"
 func (r *Renderer) Render(w http.ResponseWriter, page string, data any) {
+	r.RenderStatus(w, http.StatusOK, page, data)
+}
+
+// RenderStatus es igual que Render pero con un código HTTP explícito
+// (por ejemplo 401 en login fallido o 403 en la página de error).
+func (r *Renderer) RenderStatus(w http.ResponseWriter, status int, page string, data any) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 
 	tpl, err := r.get(page)
@@ -46,9 +53,15 @@ func (r *Renderer) Render(w http.ResponseWriter, page string, data any) {
 		return
 	}
 
-	if err := tpl.ExecuteTemplate(w, "layout", data); err != nil {
+	// Se ejecuta primero en un buffer para poder responder 500 si falla
+	var buf bytes.Buffer
+	if err := tpl.ExecuteTemplate(&buf, "layout", data); err != nil {
 		http.Error(w, "template execute error: "+err.Error(), http.StatusInternalServerError)
+		return
 	}
+
+	w.WriteHeader(status)
+	_, _ = buf.WriteTo(w)
 }
 
 // get obtiene o construye el template final
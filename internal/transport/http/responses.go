@@ -19,8 +19,18 @@ func writeErr(w http.ResponseWriter, err error) {
 	switch {
 	case errors.Is(err, domain.ErrNotFound):
 		writeJSON(w, http.StatusNotFound, map[string]any{"error": err.Error()})
-	case errors.Is(err, domain.ErrDuplicate):
+	case errors.Is(err, domain.ErrDuplicate), errors.Is(err, domain.ErrUnavailable), errors.Is(err, domain.ErrLimitReached):
+		// Conflicto con el estado actual (clave única, ejemplar prestado, límite del rol)
 		writeJSON(w, http.StatusConflict, map[string]any{"error": err.Error()})
+	case errors.Is(err, domain.ErrUnauthorized):
+		writeJSON(w, http.StatusUnauthorized, map[string]any{"error": err.Error()})
+	case errors.Is(err, domain.ErrForbidden):
+		writeJSON(w, http.StatusForbidden, map[string]any{"error": err.Error()})
+	case errors.Is(err, domain.ErrTooLarge):
+		writeJSON(w, http.StatusRequestEntityTooLarge, map[string]any{"error": err.Error()})
+	case errors.Is(err, domain.ErrValidation):
+		// Incluye consultas q mal formadas (posición del error en el mensaje)
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
 	default:
 		writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
 	}
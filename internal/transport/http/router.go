@@ -13,36 +13,207 @@ func NewRouter(h *Handler) http.Handler {
 	r.Use(requestIDMiddleware)
 	r.Use(loggingMiddleware)
 	r.Use(methodOverrideMiddleware)
+	r.Use(sessionMiddleware(h.auth))
 
-	// UI
+	// Públicas (registradas ANTES de los subrouters protegidos)
 	r.HandleFunc("/", h.uiHome).Methods(http.MethodGet)
+	r.HandleFunc("/ui/login", h.uiLoginGET).Methods(http.MethodGet)
+	r.HandleFunc("/ui/login", h.uiLoginPOST).Methods(http.MethodPost)
+	r.HandleFunc("/api/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	}).Methods(http.MethodGet)
+
+	// UI (requiere sesión)
+	ui := r.PathPrefix("/ui").Subrouter()
+	ui.Use(requireUIUser)
+
+	ui.HandleFunc("/logout", h.uiLogoutPOST).Methods(http.MethodPost)
+
+	ui.HandleFunc("/tokens", h.uiTokensGET).Methods(http.MethodGet)
+	ui.HandleFunc("/tokens", h.uiTokensPOST).Methods(http.MethodPost)
+	ui.HandleFunc("/tokens/{id:[0-9]+}/revoke", h.uiTokenRevokePOST).Methods(http.MethodPost)
 
-	r.HandleFunc("/ui/users", h.uiUsersGET).Methods(http.MethodGet)
-	r.HandleFunc("/ui/users", h.uiUsersPOST).Methods(http.MethodPost)
+	ui.HandleFunc("/users", h.uiUsersGET).Methods(http.MethodGet)
+	ui.HandleFunc("/users", h.uiUsersPOST).Methods(http.MethodPost)
+	ui.HandleFunc("/users/{id:[0-9]+}", h.uiUserDetailGET).Methods(http.MethodGet)
+	ui.HandleFunc("/users/{id:[0-9]+}", h.uiUserUpdatePOST).Methods(http.MethodPost)
+	ui.HandleFunc("/users/{id:[0-9]+}/activate", h.uiUserSetActivePOST(true)).Methods(http.MethodPost)
+	ui.HandleFunc("/users/{id:[0-9]+}/deactivate", h.uiUserSetActivePOST(false)).Methods(http.MethodPost)
+	ui.HandleFunc("/users/{id:[0-9]+}/delete", h.uiUserDeletePOST).Methods(http.MethodPost)
 
-	r.HandleFunc("/ui/books", h.uiBooksGET).Methods(http.MethodGet)
-	r.HandleFunc("/ui/books", h.uiBooksPOST).Methods(http.MethodPost)
+	ui.HandleFunc("/books", h.uiBooksGET).Methods(http.MethodGet)
+	ui.HandleFunc("/books", h.uiBooksPOST).Methods(http.MethodPost)
 
-	r.HandleFunc("/ui/books/search", h.uiBookSearchGET).Methods(http.MethodGet)
-	r.HandleFunc("/ui/books/{id:[0-9]+}", h.uiBookDetailGET).Methods(http.MethodGet)
+	ui.HandleFunc("/books/search", h.uiBookSearchGET).Methods(http.MethodGet)
+	ui.HandleFunc("/books/{id:[0-9]+}", h.uiBookDetailGET).Methods(http.MethodGet)
+	ui.HandleFunc("/books/{id:[0-9]+}/authors", h.uiBookCreditsPOST).Methods(http.MethodPost)
+	ui.HandleFunc("/books/{id:[0-9]+}/relations", h.uiAddBookRelationPOST).Methods(http.MethodPost)
+	ui.HandleFunc("/books/{id:[0-9]+}/relations/{rel:[0-9]+}/delete", h.uiDeleteBookRelationPOST).Methods(http.MethodPost)
+	ui.HandleFunc("/books/{id:[0-9]+}/files", h.uiUploadFilesPOST).Methods(http.MethodPost)
+	ui.HandleFunc("/books/{id:[0-9]+}/cover", h.uiCoverPOST).Methods(http.MethodPost)
+	ui.HandleFunc("/books/{id:[0-9]+}/cover/delete", h.uiCoverDeletePOST).Methods(http.MethodPost)
+	ui.HandleFunc("/files/{id:[0-9]+}/download", h.uiDownloadFileGET).Methods(http.MethodGet, http.MethodHead)
+	ui.HandleFunc("/files/{id:[0-9]+}/delete", h.uiDeleteFilePOST).Methods(http.MethodPost)
+	ui.HandleFunc("/books/{id:[0-9]+}/progress", h.uiProgressPOST).Methods(http.MethodPost)
+	ui.HandleFunc("/books/{id:[0-9]+}/progress/delete", h.uiProgressDeletePOST).Methods(http.MethodPost)
+	ui.HandleFunc("/books/{id:[0-9]+}/read", h.uiReaderGET).Methods(http.MethodGet)
+	ui.HandleFunc("/books/{id:[0-9]+}/read", h.uiReaderPagePOST).Methods(http.MethodPost)
+	ui.HandleFunc("/books/{id:[0-9]+}/read/ping", h.uiReaderPingPOST).Methods(http.MethodPost)
+	ui.HandleFunc("/books/{id:[0-9]+}/read/res", h.uiReaderResourceGET).Methods(http.MethodGet)
+	ui.HandleFunc("/books/{id:[0-9]+}/read/file", h.uiReaderFileGET).Methods(http.MethodGet, http.MethodHead)
+	ui.HandleFunc("/books/{id:[0-9]+}/annotations", h.uiBookAnnotationsGET).Methods(http.MethodGet)
+	ui.HandleFunc("/books/{id:[0-9]+}/annotations", h.uiAnnotationsPOST).Methods(http.MethodPost)
+	ui.HandleFunc("/annotations", h.uiAnnotationsGET).Methods(http.MethodGet)
+	ui.HandleFunc("/annotations/{id:[0-9]+}", h.uiAnnotationUpdatePOST).Methods(http.MethodPost)
+	ui.HandleFunc("/annotations/{id:[0-9]+}/delete", h.uiAnnotationDeletePOST).Methods(http.MethodPost)
+	ui.HandleFunc("/books/{id:[0-9]+}/reviews", h.uiReviewsPOST).Methods(http.MethodPost)
+	ui.HandleFunc("/reviews/moderation", h.uiReviewQueueGET).Methods(http.MethodGet)
+	ui.HandleFunc("/reviews/{id:[0-9]+}", h.uiReviewUpdatePOST).Methods(http.MethodPost)
+	ui.HandleFunc("/reviews/{id:[0-9]+}/delete", h.uiReviewDeletePOST).Methods(http.MethodPost)
+	ui.HandleFunc("/reviews/{id:[0-9]+}/moderate", h.uiReviewModeratePOST).Methods(http.MethodPost)
+	ui.HandleFunc("/books/{id:[0-9]+}/copies", h.uiAddCopyPOST).Methods(http.MethodPost)
+	ui.HandleFunc("/copies/{id:[0-9]+}", h.uiCopyUpdatePOST).Methods(http.MethodPost)
 
-	// API (básico)
+	ui.HandleFunc("/circulation", h.uiCirculationGET).Methods(http.MethodGet)
+	ui.HandleFunc("/circulation/checkout", h.uiCheckoutPOST).Methods(http.MethodPost)
+	ui.HandleFunc("/circulation/return", h.uiReturnPOST).Methods(http.MethodPost)
+	ui.HandleFunc("/loans/{id:[0-9]+}/renew", h.uiRenewLoanPOST).Methods(http.MethodPost)
+	ui.HandleFunc("/loans/{id:[0-9]+}/return", h.uiReturnSeatPOST).Methods(http.MethodPost)
+	ui.HandleFunc("/books/{id:[0-9]+}/seats", h.uiSeatsPOST).Methods(http.MethodPost)
+	ui.HandleFunc("/books/{id:[0-9]+}/borrow", h.uiBorrowPOST).Methods(http.MethodPost)
+	ui.HandleFunc("/books/{id:[0-9]+}/holds", h.uiPlaceHoldPOST).Methods(http.MethodPost)
+	ui.HandleFunc("/holds", h.uiHoldsGET).Methods(http.MethodGet)
+	ui.HandleFunc("/holds/{id:[0-9]+}/cancel", h.uiCancelHoldPOST).Methods(http.MethodPost)
+
+	ui.HandleFunc("/reading", h.uiReadingGET).Methods(http.MethodGet)
+	ui.HandleFunc("/reading/completion", h.uiCompletionGET).Methods(http.MethodGet)
+
+	ui.HandleFunc("/authors", h.uiAuthorsGET).Methods(http.MethodGet)
+	ui.HandleFunc("/authors", h.uiAuthorsPOST).Methods(http.MethodPost)
+	ui.HandleFunc("/authors/{id:[0-9]+}", h.uiAuthorDetailGET).Methods(http.MethodGet)
+	ui.HandleFunc("/authors/{id:[0-9]+}", h.uiAuthorUpdatePOST).Methods(http.MethodPost)
+	ui.HandleFunc("/authors/{id:[0-9]+}/merge", h.uiAuthorMergePOST).Methods(http.MethodPost)
+	ui.HandleFunc("/authors/{id:[0-9]+}/delete", h.uiAuthorDeletePOST).Methods(http.MethodPost)
+
+	ui.HandleFunc("/taxonomy", h.uiTaxonomyGET).Methods(http.MethodGet)
+	ui.HandleFunc("/tags/{id:[0-9]+}", h.uiTagRenamePOST).Methods(http.MethodPost)
+	ui.HandleFunc("/tags/{id:[0-9]+}/merge", h.uiTagMergePOST).Methods(http.MethodPost)
+	ui.HandleFunc("/tags/{id:[0-9]+}/delete", h.uiTagDeletePOST).Methods(http.MethodPost)
+	ui.HandleFunc("/categories", h.uiCategoriesPOST).Methods(http.MethodPost)
+	ui.HandleFunc("/categories/{id:[0-9]+}", h.uiCategoryUpdatePOST).Methods(http.MethodPost)
+	ui.HandleFunc("/categories/{id:[0-9]+}/merge", h.uiCategoryMergePOST).Methods(http.MethodPost)
+	ui.HandleFunc("/categories/{id:[0-9]+}/delete", h.uiCategoryDeletePOST).Methods(http.MethodPost)
+
+	// API (requiere sesión o token Bearer)
 	api := r.PathPrefix("/api").Subrouter()
+	api.Use(bearerMiddleware(h.tokens))
+	api.Use(requireAPIUser)
+
+	api.HandleFunc("/me", h.apiMe).Methods(http.MethodGet)
+	api.HandleFunc("/me/progress", h.apiMyProgress).Methods(http.MethodGet)
+	api.HandleFunc("/me/annotations", h.apiMyAnnotations).Methods(http.MethodGet)
+	api.HandleFunc("/me/annotations/export", h.apiExportMyAnnotations).Methods(http.MethodGet)
+	api.HandleFunc("/me/reviews", h.apiMyReviews).Methods(http.MethodGet)
+
+	api.HandleFunc("/tokens", h.apiCreateToken).Methods(http.MethodPost)
+	api.HandleFunc("/tokens", h.apiListTokens).Methods(http.MethodGet)
+	api.HandleFunc("/tokens/{id:[0-9]+}", h.apiRevokeToken).Methods(http.MethodDelete)
 
 	api.HandleFunc("/users", h.apiCreateUser).Methods(http.MethodPost)
 	api.HandleFunc("/users", h.apiListUsers).Methods(http.MethodGet)
+	api.HandleFunc("/users/{id:[0-9]+}", h.apiGetUser).Methods(http.MethodGet)
+	api.HandleFunc("/users/{id:[0-9]+}", h.apiUpdateUser).Methods(http.MethodPatch)
+	api.HandleFunc("/users/{id:[0-9]+}", h.apiDeleteUser).Methods(http.MethodDelete)
+	api.HandleFunc("/users/{id:[0-9]+}/activate", h.apiSetUserActive(true)).Methods(http.MethodPost)
+	api.HandleFunc("/users/{id:[0-9]+}/deactivate", h.apiSetUserActive(false)).Methods(http.MethodPost)
+	api.HandleFunc("/users/{id:[0-9]+}/annotations/export", h.apiExportUserAnnotations).Methods(http.MethodGet)
 
 	api.HandleFunc("/books", h.apiCreateBook).Methods(http.MethodPost)
 	api.HandleFunc("/books", h.apiListBooks).Methods(http.MethodGet)
 	api.HandleFunc("/books/search", h.apiSearchBooks).Methods(http.MethodGet)
+	api.HandleFunc("/books/suggest", h.apiSuggestBooks).Methods(http.MethodGet)
 	api.HandleFunc("/books/{id:[0-9]+}", h.apiGetBook).Methods(http.MethodGet)
 	api.HandleFunc("/books/{id:[0-9]+}", h.apiUpdateBook).Methods(http.MethodPatch)
 	api.HandleFunc("/books/{id:[0-9]+}", h.apiDeleteBook).Methods(http.MethodDelete)
+	api.HandleFunc("/books/{id:[0-9]+}/access", h.apiRecordAccess).Methods(http.MethodPost)
+	api.HandleFunc("/books/{id:[0-9]+}/stats", h.apiBookStats).Methods(http.MethodGet)
+	api.HandleFunc("/books/{id:[0-9]+}/reading-time", h.apiBookReadingTime).Methods(http.MethodGet)
+	api.HandleFunc("/books/{id:[0-9]+}/authors", h.apiBookCredits).Methods(http.MethodGet)
+	api.HandleFunc("/books/{id:[0-9]+}/authors", h.apiSetBookCredits).Methods(http.MethodPut)
+	api.HandleFunc("/books/{id:[0-9]+}/relations", h.apiBookRelations).Methods(http.MethodGet)
+	api.HandleFunc("/books/{id:[0-9]+}/relations", h.apiAddBookRelation).Methods(http.MethodPost)
+	api.HandleFunc("/books/{id:[0-9]+}/relations/{rel:[0-9]+}", h.apiDeleteBookRelation).Methods(http.MethodDelete)
+	api.HandleFunc("/books/{id:[0-9]+}/files", h.apiBookFiles).Methods(http.MethodGet)
+	api.HandleFunc("/books/{id:[0-9]+}/files", h.apiUploadFiles).Methods(http.MethodPost)
+	api.HandleFunc("/files/{id:[0-9]+}", h.apiGetFile).Methods(http.MethodGet)
+	api.HandleFunc("/files/{id:[0-9]+}", h.apiDeleteFile).Methods(http.MethodDelete)
+	api.HandleFunc("/files/{id:[0-9]+}/download", h.apiDownloadFile).Methods(http.MethodGet, http.MethodHead)
+	api.HandleFunc("/books/{id:[0-9]+}/cover", h.apiPutCover).Methods(http.MethodPut)
+	api.HandleFunc("/books/{id:[0-9]+}/cover", h.apiDeleteCover).Methods(http.MethodDelete)
+	api.HandleFunc("/books/{id:[0-9]+}/progress", h.apiGetProgress).Methods(http.MethodGet)
+	api.HandleFunc("/books/{id:[0-9]+}/progress", h.apiPutProgress).Methods(http.MethodPut)
+	api.HandleFunc("/books/{id:[0-9]+}/progress", h.apiDeleteProgress).Methods(http.MethodDelete)
+	api.HandleFunc("/books/{id:[0-9]+}/readers", h.apiBookReaders).Methods(http.MethodGet)
+	api.HandleFunc("/progress/completion", h.apiCompletion).Methods(http.MethodGet)
+	api.HandleFunc("/books/{id:[0-9]+}/annotations", h.apiBookAnnotations).Methods(http.MethodGet)
+	api.HandleFunc("/books/{id:[0-9]+}/annotations", h.apiCreateAnnotation).Methods(http.MethodPost)
+	api.HandleFunc("/books/{id:[0-9]+}/annotations/shared", h.apiBookSharedAnnotations).Methods(http.MethodGet)
+	api.HandleFunc("/annotations/{id:[0-9]+}", h.apiGetAnnotation).Methods(http.MethodGet)
+	api.HandleFunc("/annotations/{id:[0-9]+}", h.apiUpdateAnnotation).Methods(http.MethodPatch)
+	api.HandleFunc("/annotations/{id:[0-9]+}", h.apiDeleteAnnotation).Methods(http.MethodDelete)
+	api.HandleFunc("/books/{id:[0-9]+}/reviews", h.apiBookReviews).Methods(http.MethodGet)
+	api.HandleFunc("/books/{id:[0-9]+}/reviews", h.apiCreateReview).Methods(http.MethodPost)
+	api.HandleFunc("/books/{id:[0-9]+}/rating", h.apiBookRating).Methods(http.MethodGet)
+	api.HandleFunc("/reviews/moderation", h.apiReviewQueue).Methods(http.MethodGet)
+	api.HandleFunc("/reviews/{id:[0-9]+}", h.apiGetReview).Methods(http.MethodGet)
+	api.HandleFunc("/reviews/{id:[0-9]+}", h.apiUpdateReview).Methods(http.MethodPatch)
+	api.HandleFunc("/reviews/{id:[0-9]+}", h.apiDeleteReview).Methods(http.MethodDelete)
+	api.HandleFunc("/reviews/{id:[0-9]+}/history", h.apiReviewHistory).Methods(http.MethodGet)
+	api.HandleFunc("/reviews/{id:[0-9]+}/moderate", h.apiModerateReview).Methods(http.MethodPost)
+	api.HandleFunc("/books/{id:[0-9]+}/copies", h.apiBookCopies).Methods(http.MethodGet)
+	api.HandleFunc("/books/{id:[0-9]+}/copies", h.apiAddCopy).Methods(http.MethodPost)
 
-	api.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte("ok"))
-	}).Methods(http.MethodGet)
+	api.HandleFunc("/copies/{id:[0-9]+}", h.apiUpdateCopy).Methods(http.MethodPatch)
+	api.HandleFunc("/copies/{id:[0-9]+}", h.apiDeleteCopy).Methods(http.MethodDelete)
+
+	api.HandleFunc("/loans", h.apiListLoans).Methods(http.MethodGet)
+	api.HandleFunc("/loans", h.apiCheckout).Methods(http.MethodPost)
+	api.HandleFunc("/loans/return", h.apiReturn).Methods(http.MethodPost)
+	api.HandleFunc("/loans/{id:[0-9]+}/renew", h.apiRenewLoan).Methods(http.MethodPost)
+	api.HandleFunc("/loans/{id:[0-9]+}/return", h.apiReturnSeat).Methods(http.MethodPost)
+	api.HandleFunc("/books/{id:[0-9]+}/seats", h.apiSetSeats).Methods(http.MethodPut)
+	api.HandleFunc("/books/{id:[0-9]+}/borrow", h.apiBorrow).Methods(http.MethodPost)
+
+	api.HandleFunc("/books/{id:[0-9]+}/availability", h.apiBookAvailability).Methods(http.MethodGet)
+	api.HandleFunc("/books/{id:[0-9]+}/holds", h.apiPlaceHold).Methods(http.MethodPost)
+	api.HandleFunc("/holds", h.apiListHolds).Methods(http.MethodGet)
+	api.HandleFunc("/holds/{id:[0-9]+}/cancel", h.apiCancelHold).Methods(http.MethodPost)
+
+	api.HandleFunc("/authors", h.apiCreateAuthor).Methods(http.MethodPost)
+	api.HandleFunc("/authors", h.apiListAuthors).Methods(http.MethodGet)
+	api.HandleFunc("/authors/{id:[0-9]+}", h.apiGetAuthor).Methods(http.MethodGet)
+	api.HandleFunc("/authors/{id:[0-9]+}", h.apiUpdateAuthor).Methods(http.MethodPatch)
+	api.HandleFunc("/authors/{id:[0-9]+}", h.apiDeleteAuthor).Methods(http.MethodDelete)
+	api.HandleFunc("/authors/{id:[0-9]+}/merge", h.apiMergeAuthor).Methods(http.MethodPost)
+
+	api.HandleFunc("/tags", h.apiListTags).Methods(http.MethodGet)
+	api.HandleFunc("/tags/{id:[0-9]+}", h.apiRenameTag).Methods(http.MethodPatch)
+	api.HandleFunc("/tags/{id:[0-9]+}", h.apiDeleteTag).Methods(http.MethodDelete)
+	api.HandleFunc("/tags/{id:[0-9]+}/merge", h.apiMergeTag).Methods(http.MethodPost)
+
+	api.HandleFunc("/categories", h.apiCategoryTree).Methods(http.MethodGet)
+	api.HandleFunc("/categories", h.apiCreateCategory).Methods(http.MethodPost)
+	api.HandleFunc("/categories/{id:[0-9]+}", h.apiUpdateCategory).Methods(http.MethodPatch)
+	api.HandleFunc("/categories/{id:[0-9]+}", h.apiDeleteCategory).Methods(http.MethodDelete)
+	api.HandleFunc("/categories/{id:[0-9]+}/merge", h.apiMergeCategory).Methods(http.MethodPost)
+
+	// Miniaturas de portada (sesión o token Bearer, como la API)
+	covers := r.PathPrefix("/covers").Subrouter()
+	covers.Use(bearerMiddleware(h.tokens))
+	covers.Use(requireAPIUser)
+
+	covers.HandleFunc("/{id:[0-9]+}/{size}", h.coverGET).Methods(http.MethodGet, http.MethodHead)
 
 	return r
 }
@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+// ctxKey evita colisiones con otras llaves de contexto.
+type ctxKey int
+
+const (
+	userCtxKey ctxKey = iota
+	tokenCtxKey
+)
+
+// WithUser devuelve un contexto que transporta al usuario autenticado.
+// Lo usa el middleware HTTP después de resolver la sesión.
+func WithUser(ctx context.Context, u *domain.User) context.Context {
+	return context.WithValue(ctx, userCtxKey, u)
+}
+
+// UserFromContext recupera el usuario autenticado (si existe).
+func UserFromContext(ctx context.Context) (*domain.User, bool) {
+	u, ok := ctx.Value(userCtxKey).(*domain.User)
+	return u, ok && u != nil
+}
+
+// WithAPIToken marca que la identidad proviene de un token de API.
+// Los permisos quedan limitados a los scopes del token.
+func WithAPIToken(ctx context.Context, t *domain.APIToken) context.Context {
+	return context.WithValue(ctx, tokenCtxKey, t)
+}
+
+// APITokenFromContext recupera el token usado para autenticar (si hubo).
+func APITokenFromContext(ctx context.Context) (*domain.APIToken, bool) {
+	t, ok := ctx.Value(tokenCtxKey).(*domain.APIToken)
+	return t, ok && t != nil
+}
@@ -0,0 +1,246 @@
+package usecase
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+// sharedVisibilities son las anotaciones que ve un docente además del autor.
+var sharedVisibilities = []domain.AnnotationVisibility{domain.AnnotationClass, domain.AnnotationPublic}
+
+// AnnotationService gestiona los subrayados, marcadores y notas de los
+// lectores. Cada uno edita solo lo suyo; lo que marcan como "class" lo ven
+// además los docentes (permiso de estadísticas) y lo "public", cualquier
+// lector del libro.
+type AnnotationService struct {
+	repo  AnnotationRepo
+	books BookRepo
+	users UserRepo
+}
+
+// AnnotationExport son las anotaciones de un usuario listas para exportar,
+// agrupadas por libro y en orden de ubicación.
+type AnnotationExport struct {
+	User   *domain.User
+	Shared bool // solo las compartidas (lo exporta otro usuario, no el autor)
+	Items  []domain.AnnotationDetail
+}
+
+// NewAnnotationService construye el servicio de anotaciones.
+func NewAnnotationService(repo AnnotationRepo, bookRepo BookRepo, userRepo UserRepo) *AnnotationService {
+	return &AnnotationService{repo: repo, books: bookRepo, users: userRepo}
+}
+
+// Create guarda una anotación del usuario actual en el libro.
+func (s *AnnotationService) Create(ctx context.Context, bookID uint64, c domain.AnnotationContent) (*domain.Annotation, error) {
+	u, err := authorize(ctx, domain.PermBooksRead)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.books.GetByID(ctx, bookID); err != nil {
+		return nil, err
+	}
+	a, err := domain.NewAnnotation(u.ID(), bookID, c)
+	if err != nil {
+		return nil, err
+	}
+	id, err := s.repo.Create(ctx, a)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// Get obtiene una anotación que el usuario actual puede ver (las ajenas
+// que no puede ver dan ErrNotFound, como si no existieran).
+func (s *AnnotationService) Get(ctx context.Context, id uint64) (*domain.Annotation, error) {
+	u, err := authorize(ctx, domain.PermBooksRead)
+	if err != nil {
+		return nil, err
+	}
+	a, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !s.visible(ctx, u, a) {
+		return nil, fmt.Errorf("%w: annotation %d", domain.ErrNotFound, id)
+	}
+	return a, nil
+}
+
+// Update edita una anotación propia (nil = sin cambios).
+func (s *AnnotationService) Update(ctx context.Context, id uint64, in UpdateAnnotationInput) (*domain.Annotation, error) {
+	a, err := s.own(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	c := a.Content()
+	if in.Location != nil {
+		c.Location = *in.Location
+	}
+	if in.Quote != nil {
+		c.Quote = *in.Quote
+	}
+	if in.Note != nil {
+		c.Note = *in.Note
+	}
+	if in.Color != nil {
+		c.Color = domain.AnnotationColor(*in.Color)
+	}
+	if in.Visibility != nil {
+		c.Visibility = domain.AnnotationVisibility(*in.Visibility)
+	}
+	if err := a.Edit(c); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, a); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// Delete borra una anotación propia; quien administra usuarios puede
+// borrar también las ajenas que vea (moderación).
+func (s *AnnotationService) Delete(ctx context.Context, id uint64) error {
+	u, err := authorize(ctx, domain.PermBooksRead)
+	if err != nil {
+		return err
+	}
+	a, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if a.UserID() != u.ID() {
+		if !s.visible(ctx, u, a) {
+			return fmt.Errorf("%w: annotation %d", domain.ErrNotFound, id)
+		}
+		if _, err := authorize(ctx, domain.PermUsersWrite); err != nil {
+			return err
+		}
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// Mine lista las anotaciones del usuario actual (f.UserID se ignora:
+// siempre es el propio).
+func (s *AnnotationService) Mine(ctx context.Context, f domain.AnnotationFilter) (domain.Page[domain.AnnotationDetail], error) {
+	u, err := authorize(ctx, domain.PermBooksRead)
+	if err != nil {
+		return domain.Page[domain.AnnotationDetail]{}, err
+	}
+	f.UserID = u.ID()
+	return s.repo.List(ctx, f)
+}
+
+// Public lista las anotaciones públicas del libro (de cualquier lector).
+func (s *AnnotationService) Public(ctx context.Context, bookID uint64, f domain.AnnotationFilter) (domain.Page[domain.AnnotationDetail], error) {
+	if _, err := authorize(ctx, domain.PermBooksRead); err != nil {
+		return domain.Page[domain.AnnotationDetail]{}, err
+	}
+	if _, err := s.books.GetByID(ctx, bookID); err != nil {
+		return domain.Page[domain.AnnotationDetail]{}, err
+	}
+	f.BookID, f.Visibilities = bookID, []domain.AnnotationVisibility{domain.AnnotationPublic}
+	return s.repo.List(ctx, f)
+}
+
+// Shared es la vista del docente: las anotaciones del libro que los
+// lectores compartieron con la clase o hicieron públicas (f.UserID != 0
+// las acota a un lector). Requiere permiso de estadísticas.
+func (s *AnnotationService) Shared(ctx context.Context, bookID uint64, f domain.AnnotationFilter) (domain.Page[domain.AnnotationDetail], error) {
+	if _, err := authorize(ctx, domain.PermStatsRead); err != nil {
+		return domain.Page[domain.AnnotationDetail]{}, err
+	}
+	if _, err := s.books.GetByID(ctx, bookID); err != nil {
+		return domain.Page[domain.AnnotationDetail]{}, err
+	}
+	f.BookID = bookID
+	if len(f.Visibilities) == 0 {
+		f.Visibilities = sharedVisibilities
+	}
+	for _, v := range f.Visibilities {
+		if v == domain.AnnotationPrivate {
+			return domain.Page[domain.AnnotationDetail]{}, fmt.Errorf("%w: private annotations are not shared", domain.ErrForbidden)
+		}
+	}
+	return s.repo.List(ctx, f)
+}
+
+// Export reúne todas las anotaciones del usuario para exportarlas. El
+// propio usuario exporta todas; un docente (permiso de estadísticas), solo
+// las que el usuario compartió.
+func (s *AnnotationService) Export(ctx context.Context, userID uint64) (*AnnotationExport, error) {
+	me, err := authorizeSelfOr(ctx, userID, domain.PermStatsRead)
+	if err != nil {
+		return nil, err
+	}
+	u, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	out := &AnnotationExport{User: u, Shared: me.ID() != userID, Items: []domain.AnnotationDetail{}}
+
+	f := domain.AnnotationFilter{UserID: userID,
+		PageRequest: domain.PageRequest{Sort: "location", Dir: domain.SortAsc, PageSize: domain.MaxPageSize}}
+	if out.Shared {
+		f.Visibilities = sharedVisibilities
+	}
+	for f.Page = 1; ; f.Page++ {
+		page, err := s.repo.List(ctx, f)
+		if err != nil {
+			return nil, err
+		}
+		out.Items = append(out.Items, page.Items...)
+		if len(page.Items) == 0 || len(out.Items) >= page.Total {
+			break
+		}
+	}
+
+	// Agrupa por libro sin perder el orden de ubicación dentro de cada uno
+	slices.SortStableFunc(out.Items, func(a, b domain.AnnotationDetail) int {
+		if c := strings.Compare(strings.ToLower(a.BookTitle), strings.ToLower(b.BookTitle)); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Annotation.BookID(), b.Annotation.BookID())
+	})
+	return out, nil
+}
+
+// own obtiene una anotación del usuario actual: las ajenas que puede ver
+// dan ErrForbidden y las demás ErrNotFound.
+func (s *AnnotationService) own(ctx context.Context, id uint64) (*domain.Annotation, error) {
+	u, err := authorize(ctx, domain.PermBooksRead)
+	if err != nil {
+		return nil, err
+	}
+	a, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case a.UserID() == u.ID():
+		return a, nil
+	case s.visible(ctx, u, a):
+		return nil, fmt.Errorf("%w: only the author can edit an annotation", domain.ErrForbidden)
+	default:
+		return nil, fmt.Errorf("%w: annotation %d", domain.ErrNotFound, id)
+	}
+}
+
+// visible indica si u puede ver la anotación: la propia siempre, la
+// pública cualquiera y la de clase quien tiene permiso de estadísticas.
+func (s *AnnotationService) visible(ctx context.Context, u *domain.User, a *domain.Annotation) bool {
+	switch {
+	case a.UserID() == u.ID(), a.Visibility() == domain.AnnotationPublic:
+		return true
+	case a.Visibility() == domain.AnnotationClass:
+		_, err := authorize(ctx, domain.PermStatsRead)
+		return err == nil
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+    "errors"
+    "testing"
+
+    "github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+    "github.com/jfmg0509/sistema_libros_funcional_go/internal/infrastructure/memory"
+)
+
+func TestAnnotationServiceVisibility(t *testing.T) {
+    s := newMemStore()
+    users, books, annotations := memory.NewUserRepo(s), memory.NewBookRepo(s), memory.NewAnnotationRepo(s)
+    bookSvc := NewBookService(books, users, newMemAccessRepo(), nil)
+    svc := NewAnnotationService(annotations, books, users)
+
+    adminCtx, _ := actorCtx(users, domain.RoleAdmin)
+    readerCtx, reader := actorCtx(users, domain.RoleReader)
+    otherCtx, _ := actorCtx(users, domain.RoleReader)
+    teacherCtx, _ := actorCtx(users, domain.RoleConsultor)
+
+    uno, err := bookSvc.Create(adminCtx, "Uno", "Ana", 2001, "9780306400070", "Novela", nil, "")
+    if err != nil { t.Fatalf("create book: %v", err) }
+
+    if _, err := svc.Create(readerCtx, 999999, domain.AnnotationContent{Location: "p. 1"}); !errors.Is(err, domain.ErrNotFound) {
+        t.Fatalf("expected missing book, got %v", err)
+    }
+    if _, err := svc.Create(readerCtx, uno.ID(), domain.AnnotationContent{Location: "epubcfi(/6/4!"}); !errors.Is(err, domain.ErrValidation) {
+        t.Fatalf("expected malformed CFI, got %v", err)
+    }
+
+    private, err := svc.Create(readerCtx, uno.ID(), domain.AnnotationContent{Location: "p. 3"})
+    if err != nil || private.Kind() != domain.AnnotationBookmark || private.Color() != domain.ColorYellow || private.Visibility() != domain.AnnotationPrivate {
+        t.Fatalf("unexpected bookmark %+v, %v", private, err)
+    }
+    class, err := svc.Create(readerCtx, uno.ID(), domain.AnnotationContent{Location: "p. 5", Quote: "Cita", Note: "Duda", Visibility: domain.AnnotationClass})
+    if err != nil || class.Kind() != domain.AnnotationNote { t.Fatalf("create class note: %+v, %v", class, err) }
+    public, err := svc.Create(otherCtx, uno.ID(), domain.AnnotationContent{Location: "p. 9", Quote: "Otra cita", Visibility: domain.AnnotationPublic})
+    if err != nil || public.Kind() != domain.AnnotationHighlight { t.Fatalf("create public highlight: %+v, %v", public, err) }
+
+    // Lo privado y lo de clase no existen para otro lector; lo de clase sí para el docente
+    if _, err := svc.Get(otherCtx, private.ID()); !errors.Is(err, domain.ErrNotFound) { t.Fatalf("expected private hidden, got %v", err) }
+    if _, err := svc.Get(otherCtx, class.ID()); !errors.Is(err, domain.ErrNotFound) { t.Fatalf("expected class hidden from readers, got %v", err) }
+    if _, err := svc.Get(teacherCtx, class.ID()); err != nil { t.Fatalf("teacher get class: %v", err) }
+    if _, err := svc.Get(readerCtx, public.ID()); err != nil { t.Fatalf("get public: %v", err) }
+
+    // Solo el autor edita; el administrador puede borrar lo que ve
+    note := "Resuelta"
+    if _, err := svc.Update(teacherCtx, class.ID(), UpdateAnnotationInput{Note: &note}); !errors.Is(err, domain.ErrForbidden) {
+        t.Fatalf("expected forbidden edit, got %v", err)
+    }
+    upd, err := svc.Update(readerCtx, class.ID(), UpdateAnnotationInput{Note: &note})
+    if err != nil || upd.Note() != "Resuelta" || upd.Quote() != "Cita" || upd.Visibility() != domain.AnnotationClass {
+        t.Fatalf("unexpected update %+v, %v", upd, err)
+    }
+    bad := "black"
+    if _, err := svc.Update(readerCtx, class.ID(), UpdateAnnotationInput{Color: &bad}); !errors.Is(err, domain.ErrValidation) {
+        t.Fatalf("expected invalid color, got %v", err)
+    }
+    if err := svc.Delete(readerCtx, public.ID()); !errors.Is(err, domain.ErrForbidden) { t.Fatalf("expected forbidden delete, got %v", err) }
+    if err := svc.Delete(otherCtx, private.ID()); !errors.Is(err, domain.ErrNotFound) { t.Fatalf("expected hidden delete, got %v", err) }
+
+    // Vistas por libro
+    pub, err := svc.Public(readerCtx, uno.ID(), domain.AnnotationFilter{})
+    if err != nil || pub.Total != 1 || pub.Items[0].Annotation.ID() != public.ID() { t.Fatalf("unexpected public list %+v, %v", pub, err) }
+    if _, err := svc.Shared(readerCtx, uno.ID(), domain.AnnotationFilter{}); !errors.Is(err, domain.ErrForbidden) {
+        t.Fatalf("expected forbidden shared view, got %v", err)
+    }
+    shared, err := svc.Shared(teacherCtx, uno.ID(), domain.AnnotationFilter{})
+    if err != nil || shared.Total != 2 { t.Fatalf("unexpected shared list %+v, %v", shared, err) }
+    if _, err := svc.Shared(teacherCtx, uno.ID(), domain.AnnotationFilter{Visibilities: []domain.AnnotationVisibility{domain.AnnotationPrivate}}); !errors.Is(err, domain.ErrForbidden) {
+        t.Fatalf("expected private to stay private, got %v", err)
+    }
+    mine, err := svc.Mine(readerCtx, domain.AnnotationFilter{UserID: 999})
+    if err != nil || mine.Total != 2 { t.Fatalf("unexpected own list %+v, %v", mine, err) }
+
+    // Export: el autor todo, el docente solo lo compartido, otro lector nada
+    all, err := svc.Export(readerCtx, reader.ID())
+    if err != nil || all.Shared || len(all.Items) != 2 || all.Items[0].Annotation.ID() != private.ID() {
+        t.Fatalf("unexpected own export %+v, %v", all, err)
+    }
+    sharedExport, err := svc.Export(teacherCtx, reader.ID())
+    if err != nil || !sharedExport.Shared || len(sharedExport.Items) != 1 || sharedExport.Items[0].Annotation.ID() != class.ID() {
+        t.Fatalf("unexpected teacher export %+v, %v", sharedExport, err)
+    }
+    if _, err := svc.Export(otherCtx, reader.ID()); !errors.Is(err, domain.ErrForbidden) { t.Fatalf("expected forbidden export, got %v", err) }
+
+    if err := svc.Delete(adminCtx, public.ID()); err != nil { t.Fatalf("admin delete: %v", err) }
+    if err := svc.Delete(readerCtx, private.ID()); err != nil { t.Fatalf("own delete: %v", err) }
+}
@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+// DefaultSessionTTL es la duración de una sesión si no se configura otra.
+const DefaultSessionTTL = 12 * time.Hour
+
+// dummyUser se compara cuando el email no existe, para que el tiempo de
+// respuesta no revele qué cuentas están registradas.
+var dummyUser = sync.OnceValue(func() *domain.User {
+	u, _ := domain.NewUser("dummy", "dummy@example.com", domain.RoleReader)
+	_ = u.SetPassword("dummy-password")
+	return u
+})
+
+// AuthService maneja login, logout y resolución de sesiones.
+type AuthService struct {
+	users    UserRepo
+	sessions SessionRepo
+	ttl      time.Duration
+}
+
+func NewAuthService(userRepo UserRepo, sessionRepo SessionRepo, ttl time.Duration) *AuthService {
+	if ttl <= 0 {
+		ttl = DefaultSessionTTL
+	}
+	return &AuthService{users: userRepo, sessions: sessionRepo, ttl: ttl}
+}
+
+// Login valida credenciales y abre una sesión nueva.
+// Devuelve el token en claro (solo para la cookie) y la sesión persistida.
+func (s *AuthService) Login(ctx context.Context, email, password string) (string, *domain.Session, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" || password == "" {
+		return "", nil, fmt.Errorf("%w: email and password are required", domain.ErrUnauthorized)
+	}
+
+	u, err := s.users.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			dummyUser().CheckPassword(password)
+			return "", nil, fmt.Errorf("%w: invalid credentials", domain.ErrUnauthorized)
+		}
+		return "", nil, err
+	}
+	if !u.CheckPassword(password) {
+		return "", nil, fmt.Errorf("%w: invalid credentials", domain.ErrUnauthorized)
+	}
+	if !u.Active() {
+		return "", nil, domain.ErrInactiveEntity
+	}
+
+	// limpieza oportunista de sesiones vencidas
+	_ = s.sessions.DeleteExpired(ctx, time.Now())
+
+	token, err := newToken()
+	if err != nil {
+		return "", nil, err
+	}
+	sess, err := domain.NewSession(HashToken(token), u.ID(), time.Now().Add(s.ttl))
+	if err != nil {
+		return "", nil, err
+	}
+	if err := s.sessions.Create(ctx, sess); err != nil {
+		return "", nil, err
+	}
+	return token, sess, nil
+}
+
+// Logout invalida la sesión asociada al token (idempotente).
+func (s *AuthService) Logout(ctx context.Context, token string) error {
+	if token == "" {
+		return nil
+	}
+	if err := s.sessions.Delete(ctx, HashToken(token)); err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// Authenticate resuelve el usuario dueño de un token de sesión.
+// Sesiones vencidas se eliminan y usuarios inactivos no se aceptan.
+func (s *AuthService) Authenticate(ctx context.Context, token string) (*domain.User, error) {
+	if token == "" {
+		return nil, domain.ErrUnauthorized
+	}
+
+	sess, err := s.sessions.GetByTokenHash(ctx, HashToken(token))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.ErrUnauthorized
+		}
+		return nil, err
+	}
+	if sess.Expired(time.Now()) {
+		_ = s.sessions.Delete(ctx, sess.TokenHash())
+		return nil, fmt.Errorf("%w: session expired", domain.ErrUnauthorized)
+	}
+
+	u, err := s.users.GetByID(ctx, sess.UserID())
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.ErrUnauthorized
+		}
+		return nil, err
+	}
+	if !u.Active() {
+		return nil, domain.ErrInactiveEntity
+	}
+	return u, nil
+}
+
+// ===== helpers =====
+
+// newToken genera 32 bytes aleatorios codificados en base64 URL-safe.
+func newToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// HashToken calcula el SHA-256 (hex) de un token; es lo único que se persiste.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
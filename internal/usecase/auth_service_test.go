@@ -0,0 +1,58 @@
+package usecase
+
+import (
+    "context"
+    "errors"
+    "testing"
+
+    "github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+func TestAuthLoginAuthenticateLogout(t *testing.T) {
+    users := newMemUserRepo()
+    sessions := newMemSessionRepo()
+    ctx := context.Background()
+    adminCtx, _ := actorCtx(users, domain.RoleAdmin)
+
+    if _, err := NewUserService(users).Create(adminCtx, "Ana", "ana@example.com", domain.RoleReader, "secreto123"); err != nil {
+        t.Fatalf("create: %v", err)
+    }
+    auth := NewAuthService(users, sessions, 0)
+
+    if _, _, err := auth.Login(ctx, "ana@example.com", "incorrecta"); !errors.Is(err, domain.ErrUnauthorized) {
+        t.Fatalf("expected unauthorized, got %v", err)
+    }
+    if _, _, err := auth.Login(ctx, "nadie@example.com", "secreto123"); !errors.Is(err, domain.ErrUnauthorized) {
+        t.Fatalf("expected unauthorized for unknown email, got %v", err)
+    }
+
+    token, sess, err := auth.Login(ctx, " ANA@example.com ", "secreto123")
+    if err != nil { t.Fatalf("login: %v", err) }
+    if sess.TokenHash() == token { t.Fatalf("token must not be stored in clear") }
+
+    u, err := auth.Authenticate(ctx, token)
+    if err != nil { t.Fatalf("authenticate: %v", err) }
+    if u.Email() != "ana@example.com" { t.Fatalf("unexpected user %s", u.Email()) }
+
+    if err := auth.Logout(ctx, token); err != nil { t.Fatalf("logout: %v", err) }
+    if _, err := auth.Authenticate(ctx, token); !errors.Is(err, domain.ErrUnauthorized) {
+        t.Fatalf("expected unauthorized after logout, got %v", err)
+    }
+}
+
+func TestAuthRejectsInactiveUser(t *testing.T) {
+    users := newMemUserRepo()
+    ctx := context.Background()
+    adminCtx, _ := actorCtx(users, domain.RoleAdmin)
+
+    svc := NewUserService(users)
+    u, err := svc.Create(adminCtx, "Ana", "ana@example.com", domain.RoleReader, "secreto123")
+    if err != nil { t.Fatalf("create: %v", err) }
+    inactive := false
+    if _, err := svc.Update(adminCtx, u.ID(), "", "", "", &inactive); err != nil { t.Fatalf("update: %v", err) }
+
+    auth := NewAuthService(users, newMemSessionRepo(), 0)
+    if _, _, err := auth.Login(ctx, "ana@example.com", "secreto123"); !errors.Is(err, domain.ErrInactiveEntity) {
+        t.Fatalf("expected inactive error, got %v", err)
+    }
+}
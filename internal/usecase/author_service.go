@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+// AuthorService gestiona autores y mantiene al día el byline de sus libros
+// (Book.Author) cuando un autor se renombra o se fusiona con otro.
+type AuthorService struct {
+	authors AuthorRepo
+	books   BookRepo
+	search  BookSearcher // opcional: se reindexan los libros cuyo byline cambia
+}
+
+func NewAuthorService(authorRepo AuthorRepo, bookRepo BookRepo) *AuthorService {
+	return &AuthorService{authors: authorRepo, books: bookRepo}
+}
+
+// SetSearcher comparte el índice de búsqueda de BookService.
+func (s *AuthorService) SetSearcher(idx BookSearcher) { s.search = idx }
+
+func (s *AuthorService) Create(ctx context.Context, name, bio string) (*domain.Author, error) {
+	if _, err := authorize(ctx, domain.PermBooksWrite); err != nil {
+		return nil, err
+	}
+	a, err := domain.NewAuthor(name, bio)
+	if err != nil {
+		return nil, err
+	}
+	id, err := s.authors.Create(ctx, a)
+	if err != nil {
+		return nil, err
+	}
+	return s.authors.GetByID(ctx, id)
+}
+
+func (s *AuthorService) Get(ctx context.Context, id uint64) (*domain.Author, error) {
+	if _, err := authorize(ctx, domain.PermBooksRead); err != nil {
+		return nil, err
+	}
+	return s.authors.GetByID(ctx, id)
+}
+
+// Search devuelve una página de autores (nombre "contiene" f.Q).
+func (s *AuthorService) Search(ctx context.Context, f domain.AuthorFilter) (domain.Page[*domain.Author], error) {
+	if _, err := authorize(ctx, domain.PermBooksRead); err != nil {
+		return domain.Page[*domain.Author]{}, err
+	}
+	return s.authors.Search(ctx, f)
+}
+
+// Books devuelve los libros del autor con su rol en cada uno.
+func (s *AuthorService) Books(ctx context.Context, id uint64) ([]domain.AuthorBook, error) {
+	if _, err := authorize(ctx, domain.PermBooksRead); err != nil {
+		return nil, err
+	}
+	if _, err := s.authors.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.authors.AuthorBooks(ctx, id)
+}
+
+// Update aplica los cambios; si cambia el nombre se recalcula el byline
+// de todos sus libros.
+func (s *AuthorService) Update(ctx context.Context, id uint64, in UpdateAuthorInput) (*domain.Author, error) {
+	if _, err := authorize(ctx, domain.PermBooksWrite); err != nil {
+		return nil, err
+	}
+	a, err := s.authors.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	renamed := false
+	if in.Name != nil {
+		old := a.Name()
+		if err := a.SetName(*in.Name); err != nil {
+			return nil, err
+		}
+		renamed = a.Name() != old
+	}
+	if in.Bio != nil {
+		a.SetBio(*in.Bio)
+	}
+	if err := s.authors.Update(ctx, a); err != nil {
+		return nil, err
+	}
+
+	if renamed {
+		works, err := s.authors.AuthorBooks(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]uint64, 0, len(works))
+		for _, w := range works {
+			ids = append(ids, w.Book.ID())
+		}
+		if err := s.refreshBylines(ctx, ids); err != nil {
+			return nil, err
+		}
+	}
+	return s.authors.GetByID(ctx, id)
+}
+
+// Delete elimina un autor sin libros. Con libros se rechaza: primero hay que
+// fusionarlo con otro (Merge) o cambiar los créditos de esos libros.
+func (s *AuthorService) Delete(ctx context.Context, id uint64) error {
+	if _, err := authorize(ctx, domain.PermBooksWrite); err != nil {
+		return err
+	}
+	works, err := s.authors.AuthorBooks(ctx, id)
+	if err != nil {
+		return err
+	}
+	if len(works) > 0 {
+		return fmt.Errorf("%w: author is credited in %d book(s); merge it or edit the credits first", domain.ErrValidation, len(works))
+	}
+	return s.authors.Delete(ctx, id)
+}
+
+// Merge fusiona el autor dupID en keepID: sus créditos pasan a keepID, dupID
+// se elimina y se recalcula el byline de los libros afectados.
+func (s *AuthorService) Merge(ctx context.Context, keepID, dupID uint64) (*domain.Author, error) {
+	if _, err := authorize(ctx, domain.PermBooksWrite); err != nil {
+		return nil, err
+	}
+	if dupID == 0 || keepID == dupID {
+		return nil, fmt.Errorf("%w: duplicate_id must be another author", domain.ErrValidation)
+	}
+	affected, err := s.authors.Merge(ctx, keepID, dupID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.refreshBylines(ctx, affected); err != nil {
+		return nil, err
+	}
+	return s.authors.GetByID(ctx, keepID)
+}
+
+// refreshBylines recalcula Book.Author de cada libro desde sus créditos.
+func (s *AuthorService) refreshBylines(ctx context.Context, bookIDs []uint64) error {
+	for _, id := range bookIDs {
+		if _, err := syncByline(ctx, s.authors, s.books, s.search, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncByline guarda en Book.Author el byline de los créditos actuales del
+// libro (si cambió) y actualiza el índice de búsqueda.
+func syncByline(ctx context.Context, authors AuthorRepo, books BookRepo, idx BookSearcher, bookID uint64) (*domain.Book, error) {
+	b, err := books.GetByID(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	credits, err := authors.BookCredits(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	byline := domain.Byline(credits)
+	if byline == "" || byline == b.Author() {
+		return b, nil
+	}
+	if err := b.SetAuthor(byline); err != nil {
+		return nil, err
+	}
+	if err := books.Update(ctx, b); err != nil {
+		return nil, err
+	}
+	if b, err = books.GetByID(ctx, bookID); err != nil {
+		return nil, err
+	}
+	if idx != nil {
+		idx.Index(b)
+	}
+	return b, nil
+}
+
+// ensureAuthor devuelve el ID del autor con ese nombre (sin distinguir
+// mayúsculas) y lo crea si no existe.
+func ensureAuthor(ctx context.Context, authors AuthorRepo, name string) (uint64, error) {
+	a, err := authors.GetByName(ctx, name)
+	if err == nil {
+		return a.ID(), nil
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return 0, err
+	}
+	if a, err = domain.NewAuthor(name, ""); err != nil {
+		return 0, err
+	}
+	return authors.Create(ctx, a)
+}
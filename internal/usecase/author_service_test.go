@@ -0,0 +1,119 @@
+package usecase
+
+import (
+    "context"
+    "errors"
+    "testing"
+
+    "github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+    "github.com/jfmg0509/sistema_libros_funcional_go/internal/infrastructure/memory"
+    "github.com/jfmg0509/sistema_libros_funcional_go/internal/infrastructure/search"
+)
+
+func TestBookServiceCreditsAuthors(t *testing.T) {
+    users := newMemUserRepo()
+    s := newMemStore()
+    books, authors := memory.NewBookRepo(s), memory.NewAuthorRepo(s)
+    svc := NewBookService(books, users, newMemAccessRepo(), nil)
+    svc.SetAuthorRepo(authors)
+
+    adminCtx, _ := actorCtx(users, domain.RoleAdmin)
+    b, err := svc.Create(adminCtx, "Ficciones", "Jorge Luis Borges", 1944, "978-0-306-40615-7", "Cuento", nil, "")
+    if err != nil { t.Fatalf("create: %v", err) }
+    other, err := svc.Create(adminCtx, "El Aleph", "jorge luis borges", 1949, "9780306400070", "Cuento", nil, "")
+    if err != nil { t.Fatalf("create other: %v", err) }
+
+    // El mismo nombre (sin distinguir mayúsculas) reutiliza el autor
+    c1, _ := svc.Credits(adminCtx, b.ID())
+    c2, _ := svc.Credits(adminCtx, other.ID())
+    if len(c1) != 1 || len(c2) != 1 || c1[0].AuthorID != c2[0].AuthorID || c1[0].Role != domain.CreditAuthor {
+        t.Fatalf("expected both books credited to the same author, got %+v / %+v", c1, c2)
+    }
+
+    // Créditos por nombre y por ID: el byline resume a los autores
+    b, credits, err := svc.SetCredits(adminCtx, b.ID(), []CreditInput{
+        {AuthorID: c1[0].AuthorID},
+        {Name: "Adolfo Bioy Casares", Role: "Author"},
+        {Name: "Andrew Hurley", Role: "translator"},
+    })
+    if err != nil { t.Fatalf("set credits: %v", err) }
+    if b.Author() != "Jorge Luis Borges, Adolfo Bioy Casares" || len(credits) != 3 || credits[2].Role != domain.CreditTranslator {
+        t.Fatalf("unexpected byline %q / credits %+v", b.Author(), credits)
+    }
+
+    if _, _, err := svc.SetCredits(adminCtx, b.ID(), []CreditInput{{Name: "Alguien", Role: "prologuista"}}); !errors.Is(err, domain.ErrValidation) {
+        t.Fatalf("expected validation error for unknown role, got %v", err)
+    }
+    if _, _, err := svc.SetCredits(adminCtx, b.ID(), nil); !errors.Is(err, domain.ErrValidation) {
+        t.Fatalf("expected validation error for empty credits, got %v", err)
+    }
+
+    // Cambiar el autor por PATCH reemplaza solo los créditos de rol author
+    name := "J. L. Borges"
+    if _, err := svc.Update(adminCtx, b.ID(), UpdateBookInput{Author: &name}); err != nil { t.Fatalf("update: %v", err) }
+    credits, _ = svc.Credits(adminCtx, b.ID())
+    if len(credits) != 2 || credits[0].Name != name || credits[1].Role != domain.CreditTranslator {
+        t.Fatalf("unexpected credits after author change: %+v", credits)
+    }
+}
+
+func TestAuthorServiceMergeRefreshesBylines(t *testing.T) {
+    users := newMemUserRepo()
+    s := newMemStore()
+    books, authors := memory.NewBookRepo(s), memory.NewAuthorRepo(s)
+    idx := search.NewIndex()
+    bookSvc := NewBookService(books, users, newMemAccessRepo(), nil)
+    bookSvc.SetAuthorRepo(authors)
+    bookSvc.SetSearcher(idx)
+    svc := NewAuthorService(authors, books)
+    svc.SetSearcher(idx)
+
+    adminCtx, _ := actorCtx(users, domain.RoleAdmin)
+    readerCtx, _ := actorCtx(users, domain.RoleReader)
+
+    if _, err := bookSvc.Create(adminCtx, "Ficciones", "Jorge Luis Borges", 1944, "978-0-306-40615-7", "Cuento", nil, ""); err != nil { t.Fatalf("create: %v", err) }
+    dupBook, err := bookSvc.Create(adminCtx, "El Aleph", "J. L. Borgues", 1949, "9780306400070", "Cuento", nil, "")
+    if err != nil { t.Fatalf("create dup: %v", err) }
+
+    keep, _ := authors.GetByName(context.Background(), "Jorge Luis Borges")
+    dup, _ := authors.GetByName(context.Background(), "J. L. Borgues")
+
+    if _, err := svc.Merge(readerCtx, keep.ID(), dup.ID()); !errors.Is(err, domain.ErrForbidden) {
+        t.Fatalf("expected forbidden merge for reader, got %v", err)
+    }
+    if _, err := svc.Merge(adminCtx, keep.ID(), keep.ID()); !errors.Is(err, domain.ErrValidation) {
+        t.Fatalf("expected validation error merging an author into itself, got %v", err)
+    }
+    if err := svc.Delete(adminCtx, dup.ID()); !errors.Is(err, domain.ErrValidation) {
+        t.Fatalf("expected delete of credited author to be refused, got %v", err)
+    }
+
+    if _, err := svc.Merge(adminCtx, keep.ID(), dup.ID()); err != nil { t.Fatalf("merge: %v", err) }
+
+    got, _ := bookSvc.Get(adminCtx, dupBook.ID())
+    if got.Author() != "Jorge Luis Borges" {
+        t.Fatalf("expected byline refreshed after merge, got %q", got.Author())
+    }
+    works, _ := svc.Books(adminCtx, keep.ID())
+    if len(works) != 2 {
+        t.Fatalf("expected 2 books for the kept author, got %d", len(works))
+    }
+
+    // El índice ve el nuevo byline (y ya no el viejo)
+    res, _ := idx.Search(context.Background(), domain.BookFilter{Q: "author:borgues"})
+    if res.Hits.Total != 0 {
+        t.Fatalf("index still has the old byline")
+    }
+
+    // Renombrar propaga a todos sus libros
+    name := "Borges"
+    if _, err := svc.Update(adminCtx, keep.ID(), UpdateAuthorInput{Name: &name}); err != nil { t.Fatalf("rename: %v", err) }
+    res, _ = idx.Search(context.Background(), domain.BookFilter{Q: `author:"borges"`})
+    if res.Hits.Total != 2 {
+        t.Fatalf("expected 2 books with the renamed byline, got %d", res.Hits.Total)
+    }
+    got, _ = bookSvc.Get(adminCtx, dupBook.ID())
+    if got.Author() != "Borges" {
+        t.Fatalf("expected renamed byline, got %q", got.Author())
+    }
+}
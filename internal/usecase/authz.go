@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+// authorize exige un usuario autenticado con el permiso indicado.
+// Sin usuario en el contexto -> ErrUnauthorized; sin permiso -> ErrForbidden.
+// Si la identidad viene de un token de API, además debe tener ese scope.
+func authorize(ctx context.Context, p domain.Permission) (*domain.User, error) {
+	u, ok := UserFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrUnauthorized
+	}
+	if !u.Can(p) {
+		return nil, fmt.Errorf("%w: role %s lacks %s", domain.ErrForbidden, u.Role(), p)
+	}
+	if t, ok := APITokenFromContext(ctx); ok && !t.HasScope(p) {
+		return nil, fmt.Errorf("%w: token lacks scope %s", domain.ErrForbidden, p)
+	}
+	return u, nil
+}
+
+// authorizeSelfOr permite la acción si el usuario actúa sobre sí mismo
+// o si tiene el permiso indicado (por ejemplo, un ADMIN).
+// Con token de API no aplica el atajo "sobre sí mismo": solo cuentan los scopes.
+func authorizeSelfOr(ctx context.Context, userID uint64, p domain.Permission) (*domain.User, error) {
+	u, ok := UserFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrUnauthorized
+	}
+	if _, viaToken := APITokenFromContext(ctx); !viaToken && u.ID() == userID && u.Active() {
+		return u, nil
+	}
+	return authorize(ctx, p)
+}
+
+// requireSession exige una sesión interactiva (no un token de API).
+// Se usa para operaciones sensibles como emitir nuevos tokens.
+func requireSession(ctx context.Context) (*domain.User, error) {
+	u, ok := UserFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrUnauthorized
+	}
+	if _, viaToken := APITokenFromContext(ctx); viaToken {
+		return nil, fmt.Errorf("%w: this operation requires an interactive session", domain.ErrForbidden)
+	}
+	return u, nil
+}
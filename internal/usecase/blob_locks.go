@@ -0,0 +1,44 @@
+package usecase
+
+import "sync"
+
+// blobLocks serializa, por clave de blob, el "¿ya está guardado?" de una
+// subida con el "¿alguien lo usa todavía?" de un borrado. Sin esto, una
+// subida que ve el contenido ya guardado (y no lo vuelve a subir) puede
+// cruzarse con el borrado del último adjunto que lo usaba, y el adjunto
+// nuevo queda apuntando a un blob que ya no existe. Es un candado del
+// proceso: alcanza porque la app corre como un único proceso.
+var blobLocks = &keyLocks{held: map[string]*keyLock{}}
+
+// keyLocks es un mutex por clave; las claves sin uso no ocupan memoria.
+type keyLocks struct {
+	mu   sync.Mutex
+	held map[string]*keyLock
+}
+
+type keyLock struct {
+	sync.Mutex
+	refs int // quienes tienen o esperan el candado
+}
+
+// lock toma el candado de key y devuelve la función que lo suelta.
+func (l *keyLocks) lock(key string) (unlock func()) {
+	l.mu.Lock()
+	k, ok := l.held[key]
+	if !ok {
+		k = &keyLock{}
+		l.held[key] = k
+	}
+	k.refs++
+	l.mu.Unlock()
+
+	k.Lock()
+	return func() {
+		k.Unlock()
+		l.mu.Lock()
+		if k.refs--; k.refs == 0 {
+			delete(l.held, key)
+		}
+		l.mu.Unlock()
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
 )
@@ -15,6 +17,15 @@ type BookService struct {
 	users  UserRepo
 	access AccessRepo
 	queue  *AccessQueue
+	search BookSearcher // opcional: sin índice, Q usa el filtro del repositorio
+
+	authors   AuthorRepo   // opcional: créditos libro-autor (Book.Author queda como byline)
+	relations RelationRepo // opcional: relaciones tipadas entre libros
+	files     FileRepo     // opcional: adjuntos (sus blobs se borran con el libro)
+	blobs     BlobStore
+	covers    CoverRepo // opcional: portadas (imagen y miniaturas se borran con el libro)
+	coverBlob BlobStore
+	thumbs    BlobStore
 }
 
 func NewBookService(bookRepo BookRepo, userRepo UserRepo, accessRepo AccessRepo, queue *AccessQueue) *BookService {
@@ -26,7 +37,40 @@ func NewBookService(bookRepo BookRepo, userRepo UserRepo, accessRepo AccessRepo,
 	}
 }
 
+// SetSearcher activa la búsqueda de texto completo. El índice debe venir
+// cargado con los libros existentes (p. ej. con BookRepo.List al arrancar).
+func (s *BookService) SetSearcher(idx BookSearcher) { s.search = idx }
+
+// SetAuthorRepo activa los créditos de autoría: al crear o cambiar el autor
+// de un libro se acredita (y si hace falta se crea) el Author con ese nombre.
+func (s *BookService) SetAuthorRepo(r AuthorRepo) { s.authors = r }
+
+// SetRelationRepo activa las relaciones entre libros (continuación, cuaderno
+// de trabajo, guía docente, adaptación, complementario).
+func (s *BookService) SetRelationRepo(r RelationRepo) { s.relations = r }
+
+// SetFileStore activa la limpieza de adjuntos: al borrar un libro se borran
+// los blobs que solo usaban sus archivos.
+func (s *BookService) SetFileStore(files FileRepo, blobs BlobStore) { s.files, s.blobs = files, blobs }
+
+// SetCoverStore activa la limpieza de portadas: al borrar un libro se borran
+// su imagen y sus miniaturas si ningún otro libro las usa.
+func (s *BookService) SetCoverStore(covers CoverRepo, blobs, thumbs BlobStore) {
+	s.covers, s.coverBlob, s.thumbs = covers, blobs, thumbs
+}
+
+// reindex actualiza el índice tras una escritura (si hay índice).
+func (s *BookService) reindex(b *domain.Book) {
+	if s.search != nil && b != nil {
+		s.search.Index(b)
+	}
+}
+
 func (s *BookService) Create(ctx context.Context, title, author string, year int, isbn, category string, tags []string, description string) (*domain.Book, error) {
+	if _, err := authorize(ctx, domain.PermBooksWrite); err != nil {
+		return nil, err
+	}
+
 	title = strings.TrimSpace(title)
 	author = strings.TrimSpace(author)
 	isbn = strings.TrimSpace(isbn)
@@ -35,42 +79,157 @@ func (s *BookService) Create(ctx context.Context, title, author string, year int
 		return nil, errors.New("title, author e isbn son obligatorios")
 	}
 
-	// evita ISBN duplicado si tu repo lo soporta
-	if existing, _ := s.books.GetByISBN(ctx, isbn); existing != nil {
-		return nil, errors.New("ya existe un libro con ese ISBN")
-	}
-
 	b, err := domain.NewBook(title, author, year, isbn, category, tags, description)
 	if err != nil {
 		return nil, err
 	}
 
+	// evita ISBN duplicado (se compara la forma canónica: con o sin guiones,
+	// ISBN-10 o ISBN-13 son el mismo libro)
+	if existing, _ := s.books.GetByISBN(ctx, b.ISBN()); existing != nil {
+		return nil, fmt.Errorf("%w: ya existe un libro con ese ISBN", domain.ErrDuplicate)
+	}
+
 	id, err := s.books.Create(ctx, b)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.books.GetByID(ctx, id)
+	if s.authors != nil {
+		if err := s.creditAuthor(ctx, id, b.Author()); err != nil {
+			return nil, err
+		}
+	}
+
+	b, err = s.books.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.reindex(b)
+	return b, nil
 }
 
 func (s *BookService) List(ctx context.Context) ([]*domain.Book, error) {
+	if _, err := authorize(ctx, domain.PermBooksRead); err != nil {
+		return nil, err
+	}
 	return s.books.List(ctx)
 }
 
 func (s *BookService) Get(ctx context.Context, id uint64) (*domain.Book, error) {
+	if _, err := authorize(ctx, domain.PermBooksRead); err != nil {
+		return nil, err
+	}
 	return s.books.GetByID(ctx, id)
 }
 
-func (s *BookService) Search(ctx context.Context, f domain.BookFilter) ([]*domain.Book, error) {
-	return s.books.Search(ctx, f)
+// Search devuelve una página de libros que cumplen el filtro y el total.
+// Con texto libre (Q) y un índice configurado, los resultados vienen
+// ordenados por relevancia (salvo otro orden explícito) y con fragmentos.
+// Con f.WithFacets (y un índice) agrega las facetas del resultado completo.
+// Filtros u orden inválidos se rechazan con ErrValidation.
+func (s *BookService) Search(ctx context.Context, f domain.BookFilter) (domain.BookResults, error) {
+	if _, err := authorize(ctx, domain.PermBooksRead); err != nil {
+		return domain.BookResults{}, err
+	}
+	if err := f.Validate(); err != nil {
+		return domain.BookResults{}, err
+	}
+
+	if s.search != nil && (strings.TrimSpace(f.Q) != "" || f.WithFacets) {
+		if f.Sort == "" && strings.TrimSpace(f.Q) != "" {
+			f.Sort = domain.SortRelevance
+		}
+		res, err := s.search.Search(ctx, f)
+		if err != nil || res.Hits.Total > 0 || f.Fuzzy || strings.TrimSpace(f.Q) == "" {
+			return res, err
+		}
+
+		// Sin coincidencias exactas: se reintenta tolerando errores de tipeo
+		// ("Cervantez" encuentra "Cervantes")
+		f.Fuzzy = true
+		approx, err := s.search.Search(ctx, f)
+		if err != nil || approx.Hits.Total == 0 {
+			return res, err
+		}
+		approx.Approximate = true
+		return approx, nil
+	}
+
+	page, err := s.books.Search(ctx, f)
+	if err != nil {
+		return domain.BookResults{}, err
+	}
+	return domain.BookResults{Hits: domain.MapPage(page, domain.BookHits)}, nil
+}
+
+// Suggest devuelve compleciones de título y autor para el prefijo
+// (autocompletado del buscador). Requiere el índice de búsqueda: sin él no
+// hay sugerencias. limit <= 0 usa DefaultSuggestions; se recorta a MaxSuggestions.
+func (s *BookService) Suggest(ctx context.Context, prefix string, limit int) ([]domain.Suggestion, error) {
+	if _, err := authorize(ctx, domain.PermBooksRead); err != nil {
+		return nil, err
+	}
+	prefix = strings.TrimSpace(prefix)
+	if prefix == "" {
+		return nil, fmt.Errorf("%w: prefix is required", domain.ErrValidation)
+	}
+	if utf8.RuneCountInString(prefix) > domain.MaxSuggestPrefix {
+		return nil, fmt.Errorf("%w: prefix too long (max %d)", domain.ErrValidation, domain.MaxSuggestPrefix)
+	}
+	if limit <= 0 {
+		limit = domain.DefaultSuggestions
+	}
+	limit = min(limit, domain.MaxSuggestions)
+
+	if s.search == nil {
+		return []domain.Suggestion{}, nil
+	}
+	return s.search.Suggest(ctx, prefix, limit)
 }
 
 func (s *BookService) Delete(ctx context.Context, id uint64) error {
-	return s.books.Delete(ctx, id)
+	if _, err := authorize(ctx, domain.PermBooksWrite); err != nil {
+		return err
+	}
+	var files []*domain.BookFile
+	if s.files != nil {
+		var err error
+		if files, err = s.files.ByBook(ctx, id); err != nil {
+			return err
+		}
+	}
+	var cover *domain.Cover
+	if s.covers != nil {
+		var err error
+		if cover, err = s.covers.Get(ctx, id); err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return err
+		}
+	}
+	if err := s.books.Delete(ctx, id); err != nil {
+		return err
+	}
+	if s.search != nil {
+		s.search.Remove(id)
+	}
+	// Los adjuntos y la portada se borraron en cascada; sus blobs se limpian
+	// aparte. El libro ya no existe: un blob que no se pudo borrar solo
+	// ocupa espacio.
+	for _, f := range files {
+		_ = releaseBlob(ctx, s.files, s.blobs, f.SHA256())
+	}
+	if cover != nil {
+		_ = releaseCover(ctx, s.covers, s.coverBlob, s.thumbs, cover.SHA256())
+	}
+	return nil
 }
 
 // Update aplica cambios usando setters reales del dominio.
 func (s *BookService) Update(ctx context.Context, id uint64, in UpdateBookInput) (*domain.Book, error) {
+	if _, err := authorize(ctx, domain.PermBooksWrite); err != nil {
+		return nil, err
+	}
+
 	b, err := s.books.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -93,13 +252,13 @@ func (s *BookService) Update(ctx context.Context, id uint64, in UpdateBookInput)
 		}
 	}
 	if in.ISBN != nil {
-		// opcional: validar duplicado si cambias ISBN
-		if existing, _ := s.books.GetByISBN(ctx, *in.ISBN); existing != nil && existing.ID() != id {
-			return nil, errors.New("ya existe un libro con ese ISBN")
-		}
 		if err := b.SetISBN(*in.ISBN); err != nil {
 			return nil, err
 		}
+		// validar duplicado con la forma canónica
+		if existing, _ := s.books.GetByISBN(ctx, b.ISBN()); existing != nil && existing.ID() != id {
+			return nil, fmt.Errorf("%w: ya existe un libro con ese ISBN", domain.ErrDuplicate)
+		}
 	}
 	if in.Category != nil {
 		if err := b.SetCategory(*in.Category); err != nil {
@@ -124,15 +283,201 @@ func (s *BookService) Update(ctx context.Context, id uint64, in UpdateBookInput)
 	if err := s.books.Update(ctx, b); err != nil {
 		return nil, err
 	}
+	if in.Author != nil && s.authors != nil {
+		if err := s.creditAuthor(ctx, id, b.Author()); err != nil {
+			return nil, err
+		}
+	}
 
-	return s.books.GetByID(ctx, id)
+	b, err = s.books.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.reindex(b)
+	return b, nil
+}
+
+// creditAuthor acredita a name como único autor del libro (rol author),
+// conservando los demás créditos (editores, traductores, ilustradores).
+func (s *BookService) creditAuthor(ctx context.Context, bookID uint64, name string) error {
+	authorID, err := ensureAuthor(ctx, s.authors, name)
+	if err != nil {
+		return err
+	}
+	current, err := s.authors.BookCredits(ctx, bookID)
+	if err != nil {
+		return err
+	}
+	credits := []domain.BookCredit{{AuthorID: authorID, Role: domain.CreditAuthor}}
+	for _, c := range current {
+		if c.Role != domain.CreditAuthor {
+			credits = append(credits, c)
+		}
+	}
+	return s.authors.SetBookCredits(ctx, bookID, credits)
+}
+
+// Credits devuelve los créditos del libro en orden (vacío sin AuthorRepo).
+func (s *BookService) Credits(ctx context.Context, bookID uint64) ([]domain.BookCredit, error) {
+	if _, err := authorize(ctx, domain.PermBooksRead); err != nil {
+		return nil, err
+	}
+	if _, err := s.books.GetByID(ctx, bookID); err != nil {
+		return nil, err
+	}
+	if s.authors == nil {
+		return []domain.BookCredit{}, nil
+	}
+	return s.authors.BookCredits(ctx, bookID)
+}
+
+// SetCredits reemplaza los créditos del libro en el orden recibido. Cada
+// crédito indica un autor existente (AuthorID) o un nombre (se reutiliza el
+// autor con ese nombre o se crea). Book.Author pasa a ser el byline resultante.
+func (s *BookService) SetCredits(ctx context.Context, bookID uint64, in []CreditInput) (*domain.Book, []domain.BookCredit, error) {
+	if _, err := authorize(ctx, domain.PermBooksWrite); err != nil {
+		return nil, nil, err
+	}
+	if s.authors == nil {
+		return nil, nil, fmt.Errorf("%w: author credits are not enabled", domain.ErrValidation)
+	}
+	if _, err := s.books.GetByID(ctx, bookID); err != nil {
+		return nil, nil, err
+	}
+
+	credits := make([]domain.BookCredit, 0, len(in))
+	for _, c := range in {
+		role, err := domain.ParseCreditRole(c.Role)
+		if err != nil {
+			return nil, nil, err
+		}
+		authorID := c.AuthorID
+		switch {
+		case authorID != 0:
+			if _, err := s.authors.GetByID(ctx, authorID); err != nil {
+				if errors.Is(err, domain.ErrNotFound) {
+					return nil, nil, fmt.Errorf("%w: author %d does not exist", domain.ErrValidation, authorID)
+				}
+				return nil, nil, err
+			}
+		case strings.TrimSpace(c.Name) != "":
+			if authorID, err = ensureAuthor(ctx, s.authors, c.Name); err != nil {
+				return nil, nil, err
+			}
+		default:
+			return nil, nil, fmt.Errorf("%w: each credit needs author_id or name", domain.ErrValidation)
+		}
+		credits = append(credits, domain.BookCredit{AuthorID: authorID, Role: role})
+	}
+	if err := domain.ValidateCredits(credits); err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.authors.SetBookCredits(ctx, bookID, credits); err != nil {
+		return nil, nil, err
+	}
+	b, err := syncByline(ctx, s.authors, s.books, s.search, bookID)
+	if err != nil {
+		return nil, nil, err
+	}
+	saved, err := s.authors.BookCredits(ctx, bookID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return b, saved, nil
+}
+
+// Relations devuelve las relaciones que se ven desde el libro (vacío sin
+// RelationRepo).
+func (s *BookService) Relations(ctx context.Context, bookID uint64) ([]domain.RelatedBook, error) {
+	if _, err := authorize(ctx, domain.PermBooksRead); err != nil {
+		return nil, err
+	}
+	if _, err := s.books.GetByID(ctx, bookID); err != nil {
+		return nil, err
+	}
+	if s.relations == nil {
+		return []domain.RelatedBook{}, nil
+	}
+	return s.relations.ByBook(ctx, bookID)
+}
+
+// AddRelation registra "fromID es <typ> de toID". En los tipos jerárquicos
+// (todos salvo companion) se rechaza la relación que cerraría un ciclo.
+func (s *BookService) AddRelation(ctx context.Context, fromID, toID uint64, typ string, bidirectional bool) (*domain.BookRelation, error) {
+	if _, err := authorize(ctx, domain.PermBooksWrite); err != nil {
+		return nil, err
+	}
+	if s.relations == nil {
+		return nil, fmt.Errorf("%w: book relations are not enabled", domain.ErrValidation)
+	}
+	for _, id := range []uint64{fromID, toID} {
+		if _, err := s.books.GetByID(ctx, id); err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				return nil, fmt.Errorf("%w: book %d does not exist", domain.ErrValidation, id)
+			}
+			return nil, err
+		}
+	}
+
+	rel, err := domain.NewBookRelation(fromID, toID, domain.RelationType(typ), bidirectional)
+	if err != nil {
+		return nil, err
+	}
+	same, err := s.relations.ByType(ctx, rel.Type())
+	if err != nil {
+		return nil, err
+	}
+	if err := domain.CheckBookRelation(same, rel); err != nil {
+		return nil, err
+	}
+
+	id, err := s.relations.Create(ctx, rel)
+	if err != nil {
+		return nil, err
+	}
+	return s.relations.GetByID(ctx, id)
 }
 
+// DeleteRelation quita una relación. Si bookID no es 0, la relación debe
+// pertenecer a ese libro (origen o destino).
+func (s *BookService) DeleteRelation(ctx context.Context, bookID, id uint64) error {
+	if _, err := authorize(ctx, domain.PermBooksWrite); err != nil {
+		return err
+	}
+	if s.relations == nil {
+		return domain.ErrNotFound
+	}
+	rel, err := s.relations.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if bookID != 0 && rel.FromID() != bookID && rel.ToID() != bookID {
+		return domain.ErrNotFound
+	}
+	return s.relations.Delete(ctx, id)
+}
+
+// RecordAccess registra un acceso del usuario a un libro.
+// Abrir/leer requiere books:read y descargar requiere books:download.
+// Un usuario solo registra sus propios accesos, salvo quien gestiona usuarios.
 func (s *BookService) RecordAccess(ctx context.Context, userID, bookID uint64, t domain.AccessType) error {
 	if userID == 0 || bookID == 0 {
 		return errors.New("user_id y book_id son obligatorios")
 	}
 
+	perm := domain.PermBooksRead
+	if domain.AccessType(strings.ToUpper(string(t))) == domain.AccessDescarga {
+		perm = domain.PermBooksDownload
+	}
+	actor, err := authorize(ctx, perm)
+	if err != nil {
+		return err
+	}
+	if actor.ID() != userID && !actor.Can(domain.PermUsersWrite) {
+		return fmt.Errorf("%w: cannot record access for another user", domain.ErrForbidden)
+	}
+
 	// valida existencia
 	if _, err := s.users.GetByID(ctx, userID); err != nil {
 		return err
@@ -159,11 +504,24 @@ func (s *BookService) RecordAccess(ctx context.Context, userID, bookID uint64, t
 	return err
 }
 
+// StatsByBook requiere stats:read (ADMIN y CONSULTOR).
 func (s *BookService) StatsByBook(ctx context.Context, bookID uint64) (map[domain.AccessType]int, error) {
+	if _, err := authorize(ctx, domain.PermStatsRead); err != nil {
+		return nil, err
+	}
 	// ✅ Siempre consulta al MISMO repo de BD
 	return s.access.StatsByBook(ctx, bookID)
 }
 
+// ReadingTime resume las sesiones del lector integrado en el libro
+// (requiere stats:read).
+func (s *BookService) ReadingTime(ctx context.Context, bookID uint64) (domain.ReadingTime, error) {
+	if _, err := authorize(ctx, domain.PermStatsRead); err != nil {
+		return domain.ReadingTime{}, err
+	}
+	return s.access.ReadingTime(ctx, bookID)
+}
+
 // ===== helpers opcionales =====
 
 func mustJSON(v any) string {
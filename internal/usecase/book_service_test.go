@@ -2,10 +2,15 @@ package usecase
 
 import (
     "context"
+    "errors"
+    "fmt"
+    "strings"
     "testing"
     "time"
 
     "github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+    "github.com/jfmg0509/sistema_libros_funcional_go/internal/infrastructure/memory"
+    "github.com/jfmg0509/sistema_libros_funcional_go/internal/infrastructure/search"
 )
 
 func TestBookServiceRecordAccessQueued(t *testing.T) {
@@ -16,7 +21,7 @@ func TestBookServiceRecordAccessQueued(t *testing.T) {
     // seed
     u, _ := domain.NewUser("Juan", "juan@example.com", domain.RoleReader)
     uid, _ := users.Create(context.Background(), u)
-    b, _ := domain.NewBook("Go POO", "Autor", 2024, "ISBN-1", "Programación", []string{"go","poo"}, "")
+    b, _ := domain.NewBook("Go POO", "Autor", 2024, "978-0-306-40615-7", "Programación", []string{"go","poo"}, "")
     bid, _ := books.Create(context.Background(), b)
 
     q := NewAccessQueue(access, 10, 1)
@@ -24,15 +29,185 @@ func TestBookServiceRecordAccessQueued(t *testing.T) {
 
     svc := NewBookService(books, users, access, q)
 
-    if err := svc.RecordAccess(context.Background(), uid, bid, domain.AccessLectura); err != nil {
+    reader, _ := users.GetByID(context.Background(), uid)
+    readerCtx := WithUser(context.Background(), reader)
+    if err := svc.RecordAccess(readerCtx, uid, bid, domain.AccessLectura); err != nil {
         t.Fatalf("record: %v", err)
     }
 
     // esperar a que el worker procese
     time.Sleep(60 * time.Millisecond)
 
-    stats, _ := svc.StatsByBook(context.Background(), bid)
+    consultorCtx, _ := actorCtx(users, domain.RoleConsultor)
+    stats, _ := svc.StatsByBook(consultorCtx, bid)
     if stats[domain.AccessLectura] != 1 {
         t.Fatalf("expected 1 lectura, got %d", stats[domain.AccessLectura])
     }
 }
+
+func TestBookServicePermissions(t *testing.T) {
+    users := newMemUserRepo()
+    books := newMemBookRepo()
+    svc := NewBookService(books, users, newMemAccessRepo(), nil)
+
+    readerCtx, reader := actorCtx(users, domain.RoleReader)
+    if _, err := svc.Create(readerCtx, "Go POO", "Autor", 2024, "978-0-306-40615-7", "Programación", nil, ""); !errors.Is(err, domain.ErrForbidden) {
+        t.Fatalf("expected forbidden create for reader, got %v", err)
+    }
+
+    adminCtx, _ := actorCtx(users, domain.RoleAdmin)
+    b, err := svc.Create(adminCtx, "Go POO", "Autor", 2024, "978-0-306-40615-7", "Programación", nil, "")
+    if err != nil { t.Fatalf("admin create: %v", err) }
+
+    if _, err := svc.Get(readerCtx, b.ID()); err != nil { t.Fatalf("reader get: %v", err) }
+    if err := svc.Delete(readerCtx, b.ID()); !errors.Is(err, domain.ErrForbidden) {
+        t.Fatalf("expected forbidden delete for reader, got %v", err)
+    }
+    if _, err := svc.StatsByBook(readerCtx, b.ID()); !errors.Is(err, domain.ErrForbidden) {
+        t.Fatalf("expected forbidden stats for reader, got %v", err)
+    }
+    if err := svc.RecordAccess(readerCtx, reader.ID(), b.ID(), domain.AccessDescarga); err != nil {
+        t.Fatalf("reader download: %v", err)
+    }
+
+    consultorCtx, consultor := actorCtx(users, domain.RoleConsultor)
+    if err := svc.RecordAccess(consultorCtx, consultor.ID(), b.ID(), domain.AccessDescarga); !errors.Is(err, domain.ErrForbidden) {
+        t.Fatalf("expected forbidden download for consultor, got %v", err)
+    }
+    if err := svc.RecordAccess(consultorCtx, reader.ID(), b.ID(), domain.AccessLectura); !errors.Is(err, domain.ErrForbidden) {
+        t.Fatalf("expected forbidden access on behalf of another user, got %v", err)
+    }
+}
+
+func TestBookServiceKeepsSearchIndexInSync(t *testing.T) {
+    users := newMemUserRepo()
+    svc := NewBookService(newMemBookRepo(), users, newMemAccessRepo(), nil)
+    svc.SetSearcher(search.NewIndex())
+    ctx, _ := actorCtx(users, domain.RoleAdmin)
+
+    ids := func(q string) []uint64 {
+        t.Helper()
+        page, err := svc.Search(ctx, domain.BookFilter{Q: q})
+        if err != nil { t.Fatalf("search %q: %v", q, err) }
+        out := []uint64{}
+        for _, h := range page.Hits.Items { out = append(out, h.Book.ID()) }
+        return out
+    }
+
+    b, err := svc.Create(ctx, "Canción de hielo", "George Martin", 1996, "978-0-306-40615-7", "Fantasía", nil, "Invierno en Poniente")
+    if err != nil { t.Fatalf("create: %v", err) }
+    if got := ids("cancion poniente"); len(got) != 1 || got[0] != b.ID() { t.Fatalf("created book not indexed: %v", got) }
+
+    title := "Juego de tronos"
+    if _, err := svc.Update(ctx, b.ID(), UpdateBookInput{Title: &title}); err != nil { t.Fatalf("update: %v", err) }
+    if got := ids("cancion"); len(got) != 0 { t.Fatalf("stale title still indexed: %v", got) }
+    if got := ids("tron*"); len(got) != 1 { t.Fatalf("updated title not indexed: %v", got) }
+
+    if err := svc.Delete(ctx, b.ID()); err != nil { t.Fatalf("delete: %v", err) }
+    if got := ids("tronos"); len(got) != 0 { t.Fatalf("deleted book still indexed: %v", got) }
+}
+
+func TestBookServiceRejectsMalformedQuery(t *testing.T) {
+    users := newMemUserRepo()
+    svc := NewBookService(newMemBookRepo(), users, newMemAccessRepo(), nil)
+    ctx, _ := actorCtx(users, domain.RoleAdmin)
+
+    // Con y sin índice el error es el mismo (se valida antes de elegir backend)
+    for _, idx := range []BookSearcher{nil, search.NewIndex()} {
+        if idx != nil { svc.SetSearcher(idx) }
+        _, err := svc.Search(ctx, domain.BookFilter{Q: `author:"García Márquez`})
+        if !errors.Is(err, domain.ErrValidation) { t.Fatalf("expected validation error, got %v", err) }
+        if want := "unterminated quote at position 8"; !strings.Contains(err.Error(), want) { t.Fatalf("expected %q in %q", want, err.Error()) }
+    }
+}
+
+func TestBookServiceFallsBackToFuzzySearch(t *testing.T) {
+    users := newMemUserRepo()
+    svc := NewBookService(newMemBookRepo(), users, newMemAccessRepo(), nil)
+    svc.SetSearcher(search.NewIndex())
+    ctx, _ := actorCtx(users, domain.RoleAdmin)
+
+    b, err := svc.Create(ctx, "Don Quijote de la Mancha", "Miguel de Cervantes", 1605, "978-0-306-40615-7", "Novela", nil, "")
+    if err != nil { t.Fatalf("create: %v", err) }
+
+    res, err := svc.Search(ctx, domain.BookFilter{Q: "cervantes"})
+    if err != nil || res.Approximate || res.Hits.Total != 1 { t.Fatalf("exact search: %+v %v", res, err) }
+
+    res, err = svc.Search(ctx, domain.BookFilter{Q: "Cervantez"})
+    if err != nil { t.Fatalf("search: %v", err) }
+    if !res.Approximate || res.Hits.Total != 1 || res.Hits.Items[0].Book.ID() != b.ID() { t.Fatalf("expected approximate hit, got %+v", res) }
+
+    list, err := svc.Suggest(ctx, "cerv", 0)
+    if err != nil || len(list) != 1 || list[0].Kind != domain.SuggestAuthor { t.Fatalf("suggest: %+v %v", list, err) }
+    if _, err := svc.Suggest(ctx, "  ", 0); !errors.Is(err, domain.ErrValidation) { t.Fatalf("expected validation error for empty prefix, got %v", err) }
+}
+
+func TestBookServiceNormalizesISBN(t *testing.T) {
+    users := newMemUserRepo()
+    svc := NewBookService(newMemBookRepo(), users, newMemAccessRepo(), nil)
+    ctx, _ := actorCtx(users, domain.RoleAdmin)
+
+    b, err := svc.Create(ctx, "La tregua", "Mario Benedetti", 1960, "84-376-0494-X", "Novela", nil, "")
+    if err != nil { t.Fatalf("create: %v", err) }
+    if b.ISBN() != "9788437604947" || b.ISBNDisplay() != "978-84-376-0494-7" { t.Fatalf("expected canonical isbn, got %q / %q", b.ISBN(), b.ISBNDisplay()) }
+
+    // El mismo ISBN con otro formato es un duplicado
+    if _, err := svc.Create(ctx, "Otra", "Otro", 2000, "978 84 376 0494 7", "Novela", nil, ""); !errors.Is(err, domain.ErrDuplicate) { t.Fatalf("expected duplicate, got %v", err) }
+    if _, err := svc.Create(ctx, "Otra", "Otro", 2000, "978-84-376-0494-8", "Novela", nil, ""); !errors.Is(err, domain.ErrValidation) { t.Fatalf("expected checksum error, got %v", err) }
+
+    other, err := svc.Create(ctx, "Otra", "Otro", 2000, "9780306406157", "Novela", nil, "")
+    if err != nil { t.Fatalf("create other: %v", err) }
+    isbn := "978-84-376-0494-7"
+    if _, err := svc.Update(ctx, other.ID(), UpdateBookInput{ISBN: &isbn}); !errors.Is(err, domain.ErrDuplicate) { t.Fatalf("expected duplicate on update, got %v", err) }
+}
+
+func TestBookServiceRelations(t *testing.T) {
+    users := newMemUserRepo()
+    s := newMemStore()
+    books, relations := memory.NewBookRepo(s), memory.NewRelationRepo(s)
+    svc := NewBookService(books, users, newMemAccessRepo(), nil)
+    svc.SetRelationRepo(relations)
+    adminCtx, _ := actorCtx(users, domain.RoleAdmin)
+    readerCtx, _ := actorCtx(users, domain.RoleReader)
+
+    var ids []uint64
+    for i, isbn := range []string{"9780306406157", "9788437604947", "9780262033848"} {
+        b, err := svc.Create(adminCtx, fmt.Sprintf("Tomo %d", i+1), "Ana", 2000+i, isbn, "Novela", nil, "")
+        if err != nil { t.Fatalf("create book: %v", err) }
+        ids = append(ids, b.ID())
+    }
+
+    // Tomo 2 continúa el 1 y el 3 continúa el 2
+    if _, err := svc.AddRelation(readerCtx, ids[1], ids[0], "sequel", true); !errors.Is(err, domain.ErrForbidden) {
+        t.Fatalf("expected forbidden for reader, got %v", err)
+    }
+    if _, err := svc.AddRelation(adminCtx, ids[1], ids[0], "Sequel", true); err != nil { t.Fatalf("add relation: %v", err) }
+    rel, err := svc.AddRelation(adminCtx, ids[2], ids[1], "sequel", false)
+    if err != nil { t.Fatalf("add relation: %v", err) }
+
+    // Cerrar la cadena sería un ciclo; complementarios no tienen dirección
+    if _, err := svc.AddRelation(adminCtx, ids[0], ids[2], "sequel", true); !errors.Is(err, domain.ErrValidation) {
+        t.Fatalf("expected cycle to be rejected, got %v", err)
+    }
+    if _, err := svc.AddRelation(adminCtx, ids[0], ids[0], "companion", true); !errors.Is(err, domain.ErrValidation) {
+        t.Fatalf("expected self relation to be rejected, got %v", err)
+    }
+    if _, err := svc.AddRelation(adminCtx, ids[0], ids[2], "companion", true); err != nil { t.Fatalf("add companion: %v", err) }
+    if _, err := svc.AddRelation(adminCtx, ids[2], ids[0], "companion", true); !errors.Is(err, domain.ErrDuplicate) {
+        t.Fatalf("expected reversed companion to be a duplicate, got %v", err)
+    }
+    if _, err := svc.AddRelation(adminCtx, ids[0], 999999, "sequel", true); !errors.Is(err, domain.ErrValidation) {
+        t.Fatalf("expected validation error for missing book, got %v", err)
+    }
+
+    // Desde el tomo 2 se ve de cuál es continuación; el tomo 3 (no bidireccional) no aparece
+    list, err := svc.Relations(readerCtx, ids[1])
+    if err != nil || len(list) != 1 || list[0].BookID != ids[0] || list[0].Inverse { t.Fatalf("unexpected relations %+v (%v)", list, err) }
+    list, _ = svc.Relations(readerCtx, ids[0])
+    if len(list) != 2 { t.Fatalf("expected companion and sequel on first book, got %+v", list) }
+
+    if err := svc.DeleteRelation(adminCtx, ids[0], rel.ID()); !errors.Is(err, domain.ErrNotFound) {
+        t.Fatalf("expected not found deleting a relation of another book, got %v", err)
+    }
+    if err := svc.DeleteRelation(adminCtx, ids[1], rel.ID()); err != nil { t.Fatalf("delete relation: %v", err) }
+}
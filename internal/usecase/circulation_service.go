@@ -0,0 +1,621 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+// CirculationService es el mostrador de la biblioteca: ejemplares físicos,
+// puestos digitales, préstamos, renovaciones, devoluciones y la cola de
+// reservas. Las reglas de cada rol (préstamos simultáneos, días y
+// renovaciones) vienen de domain.LoanPolicy.
+type CirculationService struct {
+	repo  CirculationRepo
+	books BookRepo
+	users UserRepo
+}
+
+func NewCirculationService(repo CirculationRepo, bookRepo BookRepo, userRepo UserRepo) *CirculationService {
+	return &CirculationService{repo: repo, books: bookRepo, users: userRepo}
+}
+
+// -------------------- Ejemplares --------------------
+
+// AddCopy registra un ejemplar disponible del libro.
+func (s *CirculationService) AddCopy(ctx context.Context, bookID uint64, barcode, location string) (*domain.Copy, error) {
+	if _, err := authorize(ctx, domain.PermLoansWrite); err != nil {
+		return nil, err
+	}
+	if _, err := s.books.GetByID(ctx, bookID); err != nil {
+		return nil, err
+	}
+	c, err := domain.NewCopy(bookID, barcode, location)
+	if err != nil {
+		return nil, err
+	}
+	id, err := s.repo.CreateCopy(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetCopy(ctx, id)
+}
+
+// Copies lista los ejemplares de un libro (con su estado).
+func (s *CirculationService) Copies(ctx context.Context, bookID uint64) ([]*domain.Copy, error) {
+	if _, err := authorize(ctx, domain.PermBooksRead); err != nil {
+		return nil, err
+	}
+	return s.repo.CopiesByBook(ctx, bookID)
+}
+
+// UpdateCopy cambia código, ubicación o estado (mantenimiento, extraviado,
+// baja). Los estados "on_loan" y "on_hold" solo los manejan préstamos,
+// devoluciones y reservas.
+func (s *CirculationService) UpdateCopy(ctx context.Context, id uint64, in UpdateCopyInput) (*domain.Copy, error) {
+	if _, err := authorize(ctx, domain.PermLoansWrite); err != nil {
+		return nil, err
+	}
+	c, err := s.repo.GetCopy(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if c.Digital() {
+		return nil, errSeatCopy
+	}
+	from := c.Status() // concurrencia optimista: se guarda solo si no cambió
+
+	if in.Barcode != nil {
+		if err := c.SetBarcode(*in.Barcode); err != nil {
+			return nil, err
+		}
+	}
+	if in.Location != nil {
+		if err := c.SetLocation(*in.Location); err != nil {
+			return nil, err
+		}
+	}
+	if in.Status != nil {
+		st, err := domain.ParseCopyStatus(*in.Status)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.SetStatus(st); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.UpdateCopy(ctx, c, from); err != nil {
+		return nil, err
+	}
+	return s.repo.GetCopy(ctx, id)
+}
+
+// DeleteCopy elimina un ejemplar cargado por error. Uno que ya se prestó
+// conserva su historial: se marca "withdrawn" en lugar de borrarlo.
+func (s *CirculationService) DeleteCopy(ctx context.Context, id uint64) error {
+	if _, err := authorize(ctx, domain.PermLoansWrite); err != nil {
+		return err
+	}
+	c, err := s.repo.GetCopy(ctx, id)
+	if err != nil {
+		return err
+	}
+	if c.Digital() {
+		return errSeatCopy
+	}
+	if c.Status() == domain.CopyOnHold {
+		return fmt.Errorf("%w: copy %s is reserved for a hold", domain.ErrUnavailable, c.Barcode())
+	}
+	history, err := s.repo.ListLoans(ctx, domain.LoanFilter{CopyID: id, PageRequest: domain.PageRequest{PageSize: 1}})
+	if err != nil {
+		return err
+	}
+	if history.Total > 0 {
+		return fmt.Errorf("%w: copy has loan history; mark it as withdrawn instead", domain.ErrValidation)
+	}
+	return s.repo.DeleteCopy(ctx, id)
+}
+
+// -------------------- Puestos digitales --------------------
+
+// errSeatCopy: los puestos digitales no se editan ni borran como ejemplares.
+var errSeatCopy = fmt.Errorf("%w: digital seats are managed through the book's seat count", domain.ErrValidation)
+
+// SetSeats fija cuántos préstamos digitales simultáneos permite la licencia
+// del libro. Los puestos que se agregan (primero se reactivan los dados de
+// baja) atienden enseguida la cola de reservas. Al reducir se dan de baja
+// puestos libres, nunca uno prestado o apartado (ErrUnavailable si no
+// alcanzan). Con 0 puestos el libro vuelve a leerse sin préstamo.
+func (s *CirculationService) SetSeats(ctx context.Context, bookID uint64, n int) (domain.Availability, error) {
+	if _, err := authorize(ctx, domain.PermLoansWrite); err != nil {
+		return domain.Availability{}, err
+	}
+	if n < 0 || n > domain.MaxSeats {
+		return domain.Availability{}, fmt.Errorf("%w: seats must be between 0 and %d", domain.ErrValidation, domain.MaxSeats)
+	}
+	if _, err := s.books.GetByID(ctx, bookID); err != nil {
+		return domain.Availability{}, err
+	}
+	if err := s.expire(ctx, time.Now()); err != nil {
+		return domain.Availability{}, err
+	}
+	copies, err := s.repo.CopiesByBook(ctx, bookID)
+	if err != nil {
+		return domain.Availability{}, err
+	}
+
+	var total, active int
+	var free, withdrawn []*domain.Copy
+	for _, c := range copies {
+		if !c.Digital() {
+			continue
+		}
+		total++
+		switch c.Status() {
+		case domain.CopyWithdrawn:
+			withdrawn = append(withdrawn, c)
+		case domain.CopyAvailable:
+			free = append(free, c)
+			active++
+		default:
+			active++
+		}
+	}
+	if active-n > len(free) {
+		return domain.Availability{}, fmt.Errorf("%w: %d of %d seat(s) are on loan or held", domain.ErrUnavailable, active-len(free), active)
+	}
+
+	for ; active < n && len(withdrawn) > 0; active++ {
+		if err := s.setSeatStatus(ctx, withdrawn[0], domain.CopyAvailable); err != nil {
+			return domain.Availability{}, err
+		}
+		withdrawn = withdrawn[1:]
+	}
+	for ; active < n; active++ {
+		total++
+		c, err := domain.NewSeat(bookID, total)
+		if err != nil {
+			return domain.Availability{}, err
+		}
+		if _, err := s.repo.CreateCopy(ctx, c); err != nil {
+			return domain.Availability{}, err
+		}
+	}
+	for ; active > n; active-- {
+		if err := s.setSeatStatus(ctx, free[len(free)-1], domain.CopyWithdrawn); err != nil {
+			return domain.Availability{}, err
+		}
+		free = free[:len(free)-1]
+	}
+	return s.repo.Availability(ctx, bookID)
+}
+
+// Borrow presta al usuario actual un puesto digital del libro: el que tiene
+// apartado por una reserva lista o, si no, uno libre. Sin puestos libres hay
+// que reservar (ErrUnavailable). Rigen las mismas reglas que en Checkout.
+func (s *CirculationService) Borrow(ctx context.Context, bookID uint64) (*domain.Loan, error) {
+	actor, err := authorize(ctx, domain.PermBooksRead)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.books.GetByID(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	u, err := s.users.GetByID(ctx, actor.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.expire(ctx, now); err != nil {
+		return nil, err
+	}
+	ready, err := s.repo.ListHolds(ctx, domain.HoldFilter{UserID: u.ID(), BookID: bookID, Status: string(domain.HoldReady)})
+	if err != nil {
+		return nil, err
+	}
+	copies, err := s.repo.CopiesByBook(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	var seat *domain.Copy
+	for _, c := range copies {
+		if !c.Digital() {
+			continue
+		}
+		if len(ready.Items) > 0 && ready.Items[0].Hold.CopyID() == c.ID() {
+			seat = c
+			break
+		}
+		if seat == nil && c.Available() {
+			seat = c
+		}
+	}
+	if seat == nil {
+		return nil, fmt.Errorf("%w: book %d has no free digital seat; place a hold", domain.ErrUnavailable, bookID)
+	}
+	return s.lend(ctx, b, seat, u, now)
+}
+
+// ReturnSeat devuelve un préstamo digital. Como no hay nada que entregar,
+// puede hacerlo el propio usuario (o el mostrador); el puesto atiende
+// enseguida la cola. Los ejemplares físicos se devuelven con Return.
+func (s *CirculationService) ReturnSeat(ctx context.Context, loanID uint64) (*domain.Loan, error) {
+	if _, ok := UserFromContext(ctx); !ok {
+		return nil, domain.ErrUnauthorized
+	}
+	l, err := s.repo.GetLoan(ctx, loanID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := authorizeSelfOr(ctx, l.UserID(), domain.PermLoansWrite); err != nil {
+		return nil, err
+	}
+	c, err := s.repo.GetCopy(ctx, l.CopyID())
+	if err != nil {
+		return nil, err
+	}
+	if !c.Digital() {
+		return nil, fmt.Errorf("%w: copy %s is a physical copy; return it at the desk", domain.ErrValidation, c.Barcode())
+	}
+
+	if err := l.Return(time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReturnLoan(ctx, l); err != nil {
+		return nil, err
+	}
+	return s.repo.GetLoan(ctx, loanID)
+}
+
+// setSeatStatus da de baja o reactiva un puesto (si nadie lo tomó entretanto).
+func (s *CirculationService) setSeatStatus(ctx context.Context, c *domain.Copy, st domain.CopyStatus) error {
+	from := c.Status()
+	if err := c.SetStatus(st); err != nil {
+		return err
+	}
+	return s.repo.UpdateCopy(ctx, c, from)
+}
+
+// -------------------- Préstamos --------------------
+
+// Checkout presta el ejemplar (por código de barras) al usuario. Se rechaza
+// si el libro o el usuario están inactivos, si el usuario tiene préstamos
+// vencidos o si ya alcanzó el máximo de su rol. Un ejemplar apartado solo se
+// presta al titular de la reserva, que queda cumplida.
+func (s *CirculationService) Checkout(ctx context.Context, barcode string, userID uint64) (*domain.Loan, error) {
+	if _, err := authorize(ctx, domain.PermLoansWrite); err != nil {
+		return nil, err
+	}
+	c, err := s.copyByBarcode(ctx, barcode)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.books.GetByID(ctx, c.BookID())
+	if err != nil {
+		return nil, err
+	}
+	u, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.expire(ctx, now); err != nil {
+		return nil, err
+	}
+	if c, err = s.repo.GetCopy(ctx, c.ID()); err != nil { // el vencimiento pudo liberar el ejemplar
+		return nil, err
+	}
+	return s.lend(ctx, b, c, u, now)
+}
+
+// lend presta el ejemplar c del libro b al usuario u (Checkout y Borrow):
+// libro activo, sin préstamos vencidos y dentro del máximo del rol.
+func (s *CirculationService) lend(ctx context.Context, b *domain.Book, c *domain.Copy, u *domain.User, now time.Time) (*domain.Loan, error) {
+	if !b.Active() {
+		return nil, fmt.Errorf("%w: book %d", domain.ErrInactiveEntity, b.ID())
+	}
+	overdue, err := s.repo.ListLoans(ctx, domain.LoanFilter{
+		UserID: u.ID(), Status: domain.LoanStatusOverdue, Now: now,
+		PageRequest: domain.PageRequest{PageSize: 1},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if overdue.Total > 0 {
+		return nil, fmt.Errorf("%w: user has %d overdue loan(s)", domain.ErrLimitReached, overdue.Total)
+	}
+
+	l, err := domain.NewLoan(c, u, now)
+	if c.Status() == domain.CopyOnHold {
+		l, err = s.holdLoan(ctx, c, u, now)
+	}
+	if err != nil {
+		return nil, err
+	}
+	id, err := s.repo.Checkout(ctx, l, u.Role().LoanPolicy().MaxLoans)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetLoan(ctx, id)
+}
+
+// Renew extiende un préstamo activo según la política del rol del usuario.
+// Puede hacerlo el propio usuario o el mostrador; no si otros lectores
+// esperan el libro (ErrLimitReached).
+func (s *CirculationService) Renew(ctx context.Context, loanID uint64) (*domain.Loan, error) {
+	if _, ok := UserFromContext(ctx); !ok {
+		return nil, domain.ErrUnauthorized
+	}
+	l, err := s.repo.GetLoan(ctx, loanID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := authorizeSelfOr(ctx, l.UserID(), domain.PermLoansWrite); err != nil {
+		return nil, err
+	}
+	u, err := s.users.GetByID(ctx, l.UserID())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := l.Renew(u.Role().LoanPolicy(), time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.repo.RenewLoan(ctx, l); err != nil {
+		return nil, err
+	}
+	return s.repo.GetLoan(ctx, loanID)
+}
+
+// Return registra la devolución del ejemplar (por código de barras) y lo deja
+// disponible.
+func (s *CirculationService) Return(ctx context.Context, barcode string) (*domain.Loan, error) {
+	if _, err := authorize(ctx, domain.PermLoansWrite); err != nil {
+		return nil, err
+	}
+	c, err := s.copyByBarcode(ctx, barcode)
+	if err != nil {
+		return nil, err
+	}
+	l, err := s.repo.ActiveLoan(ctx, c.ID())
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, fmt.Errorf("%w: copy %s is not on loan", domain.ErrValidation, c.Barcode())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := l.Return(time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReturnLoan(ctx, l); err != nil {
+		return nil, err
+	}
+	return s.repo.GetLoan(ctx, l.ID())
+}
+
+// Loans lista préstamos. Sin el permiso del mostrador solo se ven los propios
+// (f.UserID se fija al usuario actual).
+func (s *CirculationService) Loans(ctx context.Context, f domain.LoanFilter) (domain.Page[domain.LoanDetail], error) {
+	u, ok := UserFromContext(ctx)
+	if !ok {
+		return domain.Page[domain.LoanDetail]{}, domain.ErrUnauthorized
+	}
+	if f.UserID == 0 {
+		if _, err := authorize(ctx, domain.PermLoansWrite); err != nil {
+			f.UserID = u.ID()
+		}
+	}
+	if _, err := authorizeSelfOr(ctx, f.UserID, domain.PermLoansWrite); err != nil {
+		return domain.Page[domain.LoanDetail]{}, err
+	}
+	f.Now = time.Now()
+	if err := s.expire(ctx, f.Now); err != nil {
+		return domain.Page[domain.LoanDetail]{}, err
+	}
+	return s.repo.ListLoans(ctx, f)
+}
+
+// -------------------- Reservas --------------------
+
+// PlaceHold pone al usuario en la cola del libro (userID 0 = el usuario
+// actual; reservar para otro requiere el permiso del mostrador). Si hay un
+// ejemplar o puesto digital disponible queda apartado enseguida.
+func (s *CirculationService) PlaceHold(ctx context.Context, bookID, userID uint64) (*domain.Hold, error) {
+	actor, ok := UserFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrUnauthorized
+	}
+	if userID == 0 {
+		userID = actor.ID()
+	}
+	if _, err := authorizeSelfOr(ctx, userID, domain.PermLoansWrite); err != nil {
+		return nil, err
+	}
+	b, err := s.books.GetByID(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	u, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.expire(ctx, now); err != nil {
+		return nil, err
+	}
+	a, err := s.repo.Availability(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	if !a.InCirculation() {
+		return nil, fmt.Errorf("%w: book %d has no copies or digital seats in circulation", domain.ErrUnavailable, bookID)
+	}
+	loans, err := s.repo.ListLoans(ctx, domain.LoanFilter{
+		UserID: userID, BookID: bookID, Status: domain.LoanStatusActive,
+		PageRequest: domain.PageRequest{PageSize: 1},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if loans.Total > 0 {
+		return nil, fmt.Errorf("%w: user already has this book on loan", domain.ErrDuplicate)
+	}
+
+	h, err := domain.NewHold(b, u, now)
+	if err != nil {
+		return nil, err
+	}
+	id, err := s.repo.PlaceHold(ctx, h, u.Role().LoanPolicy().MaxHolds)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetHold(ctx, id)
+}
+
+// CancelHold retira la reserva de la cola; si tenía un ejemplar apartado,
+// pasa a la siguiente. Puede hacerlo el propio usuario o el mostrador.
+func (s *CirculationService) CancelHold(ctx context.Context, id uint64) (*domain.Hold, error) {
+	if _, ok := UserFromContext(ctx); !ok {
+		return nil, domain.ErrUnauthorized
+	}
+	h, err := s.repo.GetHold(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := authorizeSelfOr(ctx, h.UserID(), domain.PermLoansWrite); err != nil {
+		return nil, err
+	}
+
+	if err := h.Cancel(time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CancelHold(ctx, h); err != nil {
+		return nil, err
+	}
+	return s.repo.GetHold(ctx, id)
+}
+
+// Holds lista reservas con su lugar en la cola. Sin el permiso del
+// mostrador solo se ven las propias (como Loans).
+func (s *CirculationService) Holds(ctx context.Context, f domain.HoldFilter) (domain.Page[domain.HoldDetail], error) {
+	u, ok := UserFromContext(ctx)
+	if !ok {
+		return domain.Page[domain.HoldDetail]{}, domain.ErrUnauthorized
+	}
+	if f.UserID == 0 {
+		if _, err := authorize(ctx, domain.PermLoansWrite); err != nil {
+			f.UserID = u.ID()
+		}
+	}
+	if _, err := authorizeSelfOr(ctx, f.UserID, domain.PermLoansWrite); err != nil {
+		return domain.Page[domain.HoldDetail]{}, err
+	}
+	if err := s.expire(ctx, time.Now()); err != nil {
+		return domain.Page[domain.HoldDetail]{}, err
+	}
+	return s.repo.ListHolds(ctx, f)
+}
+
+// Availability resume ejemplares, puestos digitales y cola del libro. Las
+// reservas listas cuyo plazo de retiro pasó y los préstamos digitales
+// vencidos se cierran antes de contar (no hay tarea programada).
+func (s *CirculationService) Availability(ctx context.Context, bookID uint64) (domain.Availability, error) {
+	if _, err := authorize(ctx, domain.PermBooksRead); err != nil {
+		return domain.Availability{}, err
+	}
+	if err := s.expire(ctx, time.Now()); err != nil {
+		return domain.Availability{}, err
+	}
+	return s.repo.Availability(ctx, bookID)
+}
+
+// expire cierra lo que venció: primero devuelve los préstamos digitales
+// vencidos (el puesto vuelve a la licencia y atiende la cola) y después
+// vence las reservas listas que no se retiraron.
+func (s *CirculationService) expire(ctx context.Context, now time.Time) error {
+	for {
+		page, err := s.repo.ListLoans(ctx, domain.LoanFilter{
+			Digital: true, Status: domain.LoanStatusOverdue, Now: now,
+			PageRequest: domain.PageRequest{PageSize: domain.MaxPageSize},
+		})
+		if err != nil {
+			return err
+		}
+		for _, d := range page.Items {
+			l := d.Loan
+			if err := l.Return(now); err != nil {
+				return err
+			}
+			// ErrValidation: otro pedido ya lo devolvió
+			if err := s.repo.ReturnLoan(ctx, l); err != nil && !errors.Is(err, domain.ErrValidation) {
+				return err
+			}
+		}
+		if page.Total <= len(page.Items) {
+			break
+		}
+	}
+	_, err := s.repo.ExpireHolds(ctx, now)
+	return err
+}
+
+// requireSeat exige, en un libro con puestos digitales, un préstamo vigente
+// del libro al usuario actual para leer o descargar el archivo (el mostrador
+// no lo necesita). Sin circ (repo no configurado) no se exige.
+func requireSeat(ctx context.Context, circ CirculationRepo, bookID uint64) error {
+	if circ == nil {
+		return nil
+	}
+	u, ok := UserFromContext(ctx)
+	if !ok {
+		return domain.ErrUnauthorized
+	}
+	if u.Can(domain.PermLoansWrite) {
+		return nil
+	}
+	a, err := circ.Availability(ctx, bookID)
+	if err != nil || a.Seats == 0 {
+		return err
+	}
+	now := time.Now()
+	loans, err := circ.ListLoans(ctx, domain.LoanFilter{UserID: u.ID(), BookID: bookID, Status: domain.LoanStatusActive, Now: now})
+	if err != nil {
+		return err
+	}
+	for _, d := range loans.Items {
+		if !d.Loan.Overdue(now) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: book %d is lent through digital seats; borrow one to read it", domain.ErrUnavailable, bookID)
+}
+
+// holdLoan arma el préstamo de un ejemplar apartado a partir de su reserva lista.
+func (s *CirculationService) holdLoan(ctx context.Context, c *domain.Copy, u *domain.User, now time.Time) (*domain.Loan, error) {
+	h, err := s.repo.ReadyHold(ctx, c.ID())
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, fmt.Errorf("%w: copy %s is not available", domain.ErrUnavailable, c.Barcode())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewHoldLoan(c, h, u, now)
+}
+
+// copyByBarcode normaliza el código antes de buscarlo.
+func (s *CirculationService) copyByBarcode(ctx context.Context, barcode string) (*domain.Copy, error) {
+	code, err := domain.NormalizeBarcode(barcode)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetCopyByBarcode(ctx, code)
+}
@@ -0,0 +1,206 @@
+package usecase
+
+import (
+    "errors"
+    "testing"
+    "time"
+
+    "github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+    "github.com/jfmg0509/sistema_libros_funcional_go/internal/infrastructure/memory"
+)
+
+func TestCirculationServiceCheckoutLimitsAndReturn(t *testing.T) {
+    s := newMemStore()
+    users, books, circ := memory.NewUserRepo(s), memory.NewBookRepo(s), memory.NewCirculationRepo(s)
+    bookSvc := NewBookService(books, users, newMemAccessRepo(), nil)
+    svc := NewCirculationService(circ, books, users)
+
+    adminCtx, _ := actorCtx(users, domain.RoleAdmin)
+    readerCtx, reader := actorCtx(users, domain.RoleReader)
+    _, other := actorCtx(users, domain.RoleReader)
+
+    b, err := bookSvc.Create(adminCtx, "Uno", "Ana", 2001, "9780306400070", "Novela", nil, "")
+    if err != nil { t.Fatalf("create book: %v", err) }
+    if _, err := svc.AddCopy(readerCtx, b.ID(), "C-1", ""); !errors.Is(err, domain.ErrForbidden) {
+        t.Fatalf("expected forbidden add copy for reader, got %v", err)
+    }
+    for _, code := range []string{"c-1", "c-2", "c-3", "c-4"} {
+        if _, err := svc.AddCopy(adminCtx, b.ID(), code, "Sala 1"); err != nil { t.Fatalf("add copy %s: %v", code, err) }
+    }
+
+    // Un lector tiene un máximo de 3 préstamos simultáneos
+    for _, code := range []string{"C-1", "C-2", "C-3"} {
+        if _, err := svc.Checkout(adminCtx, code, reader.ID()); err != nil { t.Fatalf("checkout %s: %v", code, err) }
+    }
+    if _, err := svc.Checkout(adminCtx, "C-4", reader.ID()); !errors.Is(err, domain.ErrLimitReached) {
+        t.Fatalf("expected limit reached on 4th loan, got %v", err)
+    }
+    if _, err := svc.Checkout(adminCtx, "C-1", other.ID()); !errors.Is(err, domain.ErrUnavailable) {
+        t.Fatalf("expected copy on loan to be unavailable, got %v", err)
+    }
+
+    // El lector ve solo sus préstamos y puede renovarlos
+    page, err := svc.Loans(readerCtx, domain.LoanFilter{})
+    if err != nil || page.Total != 3 { t.Fatalf("expected 3 own loans, got %+v (%v)", page, err) }
+    if _, err := svc.Loans(readerCtx, domain.LoanFilter{UserID: other.ID()}); !errors.Is(err, domain.ErrForbidden) {
+        t.Fatalf("expected forbidden listing another user's loans, got %v", err)
+    }
+    l, err := svc.Renew(readerCtx, page.Items[0].Loan.ID())
+    if err != nil || l.Renewals() != 1 { t.Fatalf("renew: %+v (%v)", l, err) }
+
+    // Con préstamos activos el usuario no se puede eliminar ni el ejemplar borrar
+    userSvc := NewUserService(users)
+    userSvc.SetCirculationRepo(circ)
+    if err := userSvc.Delete(adminCtx, reader.ID()); !errors.Is(err, domain.ErrValidation) {
+        t.Fatalf("expected validation error deleting user with active loans, got %v", err)
+    }
+
+    l, err = svc.Return(adminCtx, "c-1")
+    if err != nil || l.Active() { t.Fatalf("return: %+v (%v)", l, err) }
+    if _, err := svc.Return(adminCtx, "C-1"); !errors.Is(err, domain.ErrValidation) {
+        t.Fatalf("expected validation error returning a copy not on loan, got %v", err)
+    }
+    c, _ := circ.GetCopyByBarcode(adminCtx, "C-1")
+    if err := svc.DeleteCopy(adminCtx, c.ID()); !errors.Is(err, domain.ErrValidation) {
+        t.Fatalf("expected validation error deleting a copy with history, got %v", err)
+    }
+    if _, err := svc.Checkout(adminCtx, "C-4", reader.ID()); err != nil { t.Fatalf("checkout after return: %v", err) }
+}
+
+func TestCirculationServiceHoldQueue(t *testing.T) {
+    s := newMemStore()
+    users, books, circ := memory.NewUserRepo(s), memory.NewBookRepo(s), memory.NewCirculationRepo(s)
+    bookSvc := NewBookService(books, users, newMemAccessRepo(), nil)
+    svc := NewCirculationService(circ, books, users)
+
+    adminCtx, _ := actorCtx(users, domain.RoleAdmin)
+    anaCtx, ana := actorCtx(users, domain.RoleReader)
+    leoCtx, leo := actorCtx(users, domain.RoleReader)
+
+    b, err := bookSvc.Create(adminCtx, "Uno", "Ana", 2001, "9780306400070", "Novela", nil, "")
+    if err != nil { t.Fatalf("create book: %v", err) }
+    if _, err := svc.PlaceHold(anaCtx, b.ID(), 0); !errors.Is(err, domain.ErrUnavailable) {
+        t.Fatalf("expected unavailable hold on a book without copies, got %v", err)
+    }
+    if _, err := svc.AddCopy(adminCtx, b.ID(), "R-1", ""); err != nil { t.Fatalf("add copy: %v", err) }
+    if _, err := svc.Checkout(adminCtx, "R-1", ana.ID()); err != nil { t.Fatalf("checkout: %v", err) }
+
+    // Quien ya tiene el libro no lo reserva; un lector no reserva para otro
+    if _, err := svc.PlaceHold(anaCtx, b.ID(), 0); !errors.Is(err, domain.ErrDuplicate) {
+        t.Fatalf("expected duplicate hold for current borrower, got %v", err)
+    }
+    if _, err := svc.PlaceHold(anaCtx, b.ID(), leo.ID()); !errors.Is(err, domain.ErrForbidden) {
+        t.Fatalf("expected forbidden hold for another user, got %v", err)
+    }
+    h, err := svc.PlaceHold(leoCtx, b.ID(), 0)
+    if err != nil || h.Status() != domain.HoldWaiting { t.Fatalf("place hold: %+v (%v)", h, err) }
+
+    // Con Leo en la cola, Ana no renueva
+    loans, _ := svc.Loans(anaCtx, domain.LoanFilter{})
+    if _, err := svc.Renew(anaCtx, loans.Items[0].Loan.ID()); !errors.Is(err, domain.ErrLimitReached) {
+        t.Fatalf("expected limit reached renewing with waiting holds, got %v", err)
+    }
+
+    a, err := svc.Availability(leoCtx, b.ID())
+    if err != nil || a.Available != 0 || a.Waiting != 1 { t.Fatalf("unexpected availability %+v (%v)", a, err) }
+    page, err := svc.Holds(leoCtx, domain.HoldFilter{})
+    if err != nil || page.Total != 1 || page.Items[0].Position != 1 { t.Fatalf("expected own hold first in queue, got %+v (%v)", page, err) }
+
+    // Al devolverse queda apartado para Leo: Ana no puede volver a llevarlo
+    if _, err := svc.Return(adminCtx, "R-1"); err != nil { t.Fatalf("return: %v", err) }
+    if _, err := svc.Checkout(adminCtx, "R-1", ana.ID()); !errors.Is(err, domain.ErrUnavailable) {
+        t.Fatalf("expected copy held for another user, got %v", err)
+    }
+    c, _ := circ.GetCopyByBarcode(adminCtx, "R-1")
+    if err := svc.DeleteCopy(adminCtx, c.ID()); !errors.Is(err, domain.ErrUnavailable) {
+        t.Fatalf("expected unavailable deleting a held copy, got %v", err)
+    }
+    l, err := svc.Checkout(adminCtx, "R-1", leo.ID())
+    if err != nil || l.UserID() != leo.ID() { t.Fatalf("checkout held copy: %+v (%v)", l, err) }
+    h, _ = circ.GetHold(adminCtx, h.ID())
+    if h.Status() != domain.HoldFulfilled { t.Fatalf("expected fulfilled hold, got %s", h.Status()) }
+
+    // Cancelar: solo el titular o el mostrador
+    h, err = svc.PlaceHold(anaCtx, b.ID(), 0)
+    if err != nil { t.Fatalf("place second hold: %v", err) }
+    if _, err := svc.CancelHold(leoCtx, h.ID()); !errors.Is(err, domain.ErrForbidden) {
+        t.Fatalf("expected forbidden cancel of another user's hold, got %v", err)
+    }
+    h, err = svc.CancelHold(anaCtx, h.ID())
+    if err != nil || h.Status() != domain.HoldCancelled { t.Fatalf("cancel: %+v (%v)", h, err) }
+}
+
+func TestCirculationServiceDigitalSeats(t *testing.T) {
+    s := newMemStore()
+    users, books, circ := memory.NewUserRepo(s), memory.NewBookRepo(s), memory.NewCirculationRepo(s)
+    bookSvc := NewBookService(books, users, newMemAccessRepo(), nil)
+    svc := NewCirculationService(circ, books, users)
+
+    adminCtx, _ := actorCtx(users, domain.RoleAdmin)
+    anaCtx, ana := actorCtx(users, domain.RoleReader)
+    leoCtx, leo := actorCtx(users, domain.RoleReader)
+
+    // Un título solo digital: sin puestos no hay reserva ni préstamo; sin
+    // licencia se lee libremente
+    b, err := bookSvc.Create(adminCtx, "Uno", "Ana", 2001, "9780306400070", "Novela", nil, "")
+    if err != nil { t.Fatalf("create book: %v", err) }
+    if _, err := svc.PlaceHold(anaCtx, b.ID(), 0); !errors.Is(err, domain.ErrUnavailable) {
+        t.Fatalf("expected unavailable hold without seats, got %v", err)
+    }
+    if err := requireSeat(anaCtx, circ, b.ID()); err != nil { t.Fatalf("expected open access without seats, got %v", err) }
+    if _, err := svc.SetSeats(anaCtx, b.ID(), 1); !errors.Is(err, domain.ErrForbidden) {
+        t.Fatalf("expected forbidden setting seats as reader, got %v", err)
+    }
+    if _, err := svc.SetSeats(adminCtx, b.ID(), domain.MaxSeats+1); !errors.Is(err, domain.ErrValidation) {
+        t.Fatalf("expected validation error over max seats, got %v", err)
+    }
+    a, err := svc.SetSeats(adminCtx, b.ID(), 1)
+    if err != nil || a.Seats != 1 || a.Copies != 0 || a.Available != 1 { t.Fatalf("set seats: %+v (%v)", a, err) }
+
+    // Con licencia se lee con préstamo; el puesto se toma sin mostrador
+    if err := requireSeat(anaCtx, circ, b.ID()); !errors.Is(err, domain.ErrUnavailable) {
+        t.Fatalf("expected a seat required to read, got %v", err)
+    }
+    if err := requireSeat(adminCtx, circ, b.ID()); err != nil { t.Fatalf("expected desk access without a seat, got %v", err) }
+    l, err := svc.Borrow(anaCtx, b.ID())
+    if err != nil || l.UserID() != ana.ID() { t.Fatalf("borrow: %+v (%v)", l, err) }
+    if err := requireSeat(anaCtx, circ, b.ID()); err != nil { t.Fatalf("expected access with a seat, got %v", err) }
+    seats, _ := svc.Copies(adminCtx, b.ID())
+    if _, err := svc.UpdateCopy(adminCtx, seats[0].ID(), UpdateCopyInput{}); !errors.Is(err, domain.ErrValidation) {
+        t.Fatalf("expected validation error editing a seat as a copy, got %v", err)
+    }
+
+    // Sin puestos libres se reserva; al devolver, el puesto queda apartado
+    if _, err := svc.Borrow(leoCtx, b.ID()); !errors.Is(err, domain.ErrUnavailable) {
+        t.Fatalf("expected unavailable borrow without free seats, got %v", err)
+    }
+    h, err := svc.PlaceHold(leoCtx, b.ID(), 0)
+    if err != nil || h.Status() != domain.HoldWaiting { t.Fatalf("place hold on seats: %+v (%v)", h, err) }
+    if _, err := svc.SetSeats(adminCtx, b.ID(), 0); !errors.Is(err, domain.ErrUnavailable) {
+        t.Fatalf("expected unavailable removing a seat on loan, got %v", err)
+    }
+    if _, err := svc.ReturnSeat(leoCtx, l.ID()); !errors.Is(err, domain.ErrForbidden) {
+        t.Fatalf("expected forbidden returning another user's seat, got %v", err)
+    }
+    if l, err = svc.ReturnSeat(anaCtx, l.ID()); err != nil || l.Active() { t.Fatalf("return seat: %+v (%v)", l, err) }
+    if _, err := svc.Borrow(anaCtx, b.ID()); !errors.Is(err, domain.ErrUnavailable) {
+        t.Fatalf("expected seat held for the queue, got %v", err)
+    }
+    l, err = svc.Borrow(leoCtx, b.ID())
+    if err != nil || l.UserID() != leo.ID() { t.Fatalf("borrow held seat: %+v (%v)", l, err) }
+    h, _ = circ.GetHold(adminCtx, h.ID())
+    if h.Status() != domain.HoldFulfilled { t.Fatalf("expected fulfilled hold, got %s", h.Status()) }
+
+    // Un préstamo digital vencido se devuelve solo
+    past := time.Now().AddDate(0, 0, -30)
+    overdue := domain.HydrateLoan(l.ID(), l.CopyID(), l.BookID(), l.UserID(), past, past.AddDate(0, 0, 1), time.Time{}, 0)
+    if err := circ.RenewLoan(adminCtx, overdue); err != nil { t.Fatalf("backdate loan: %v", err) }
+    if a, err = svc.Availability(anaCtx, b.ID()); err != nil || a.Available != 1 || a.OnLoan != 0 {
+        t.Fatalf("expected overdue seat returned, got %+v (%v)", a, err)
+    }
+
+    // Reducir da de baja puestos libres; volver a subir los reactiva
+    if a, err = svc.SetSeats(adminCtx, b.ID(), 0); err != nil || a.Seats != 0 { t.Fatalf("remove seats: %+v (%v)", a, err) }
+    if a, err = svc.SetSeats(adminCtx, b.ID(), 2); err != nil || a.Seats != 2 { t.Fatalf("add seats: %+v (%v)", a, err) }
+    if seats, _ = svc.Copies(adminCtx, b.ID()); len(seats) != 2 { t.Fatalf("expected the withdrawn seat reused, got %d seats", len(seats)) }
+}
@@ -2,6 +2,8 @@ package usecase
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
 )
@@ -13,6 +15,7 @@ type UserRepo interface {
 	GetByID(ctx context.Context, id uint64) (*domain.User, error)
 	GetByEmail(ctx context.Context, email string) (*domain.User, error)
 	List(ctx context.Context) ([]*domain.User, error)
+	Search(ctx context.Context, f domain.UserFilter) (domain.Page[*domain.User], error)
 	Update(ctx context.Context, u *domain.User) error
 	Delete(ctx context.Context, id uint64) error
 }
@@ -22,7 +25,7 @@ type BookRepo interface {
 	GetByID(ctx context.Context, id uint64) (*domain.Book, error)
 	GetByISBN(ctx context.Context, isbn string) (*domain.Book, error)
 	List(ctx context.Context) ([]*domain.Book, error)
-	Search(ctx context.Context, f domain.BookFilter) ([]*domain.Book, error)
+	Search(ctx context.Context, f domain.BookFilter) (domain.Page[*domain.Book], error)
 	Update(ctx context.Context, b *domain.Book) error
 	Delete(ctx context.Context, id uint64) error
 }
@@ -30,6 +33,181 @@ type BookRepo interface {
 type AccessRepo interface {
 	Create(ctx context.Context, e *domain.AccessEvent) (uint64, error)
 	StatsByBook(ctx context.Context, bookID uint64) (map[domain.AccessType]int, error)
+	ReadingTime(ctx context.Context, bookID uint64) (domain.ReadingTime, error)
+}
+
+type AuthorRepo interface {
+	Create(ctx context.Context, a *domain.Author) (uint64, error)
+	GetByID(ctx context.Context, id uint64) (*domain.Author, error)
+	GetByName(ctx context.Context, name string) (*domain.Author, error)
+	Search(ctx context.Context, f domain.AuthorFilter) (domain.Page[*domain.Author], error)
+	Update(ctx context.Context, a *domain.Author) error
+	Delete(ctx context.Context, id uint64) error
+	SetBookCredits(ctx context.Context, bookID uint64, credits []domain.BookCredit) error
+	BookCredits(ctx context.Context, bookID uint64) ([]domain.BookCredit, error)
+	AuthorBooks(ctx context.Context, authorID uint64) ([]domain.AuthorBook, error)
+	Merge(ctx context.Context, keepID, dupID uint64) ([]uint64, error)
+}
+
+// TaxonomyRepo persiste etiquetas y el árbol de categorías. Las operaciones
+// que cambian el nombre que ven los libros retornan los IDs afectados.
+type TaxonomyRepo interface {
+	ListTags(ctx context.Context, f domain.TagFilter) (domain.Page[domain.TagUsage], error)
+	GetTag(ctx context.Context, id uint64) (*domain.Tag, error)
+	RenameTag(ctx context.Context, t *domain.Tag) ([]uint64, error)
+	MergeTags(ctx context.Context, keepID, dupID uint64) ([]uint64, error)
+	DeleteTag(ctx context.Context, id uint64) ([]uint64, error)
+
+	Categories(ctx context.Context) ([]domain.CategoryUsage, error)
+	GetCategory(ctx context.Context, id uint64) (*domain.Category, error)
+	CreateCategory(ctx context.Context, c *domain.Category) (uint64, error)
+	UpdateCategory(ctx context.Context, c *domain.Category) ([]uint64, error)
+	MergeCategories(ctx context.Context, keepID, dupID uint64) ([]uint64, error)
+	DeleteCategory(ctx context.Context, id uint64) error
+}
+
+// CirculationRepo persiste ejemplares y préstamos. Checkout y ReturnLoan
+// cambian el estado del ejemplar en la misma transacción que el préstamo.
+type CirculationRepo interface {
+	CreateCopy(ctx context.Context, c *domain.Copy) (uint64, error)
+	GetCopy(ctx context.Context, id uint64) (*domain.Copy, error)
+	GetCopyByBarcode(ctx context.Context, barcode string) (*domain.Copy, error)
+	CopiesByBook(ctx context.Context, bookID uint64) ([]*domain.Copy, error)
+	UpdateCopy(ctx context.Context, c *domain.Copy, from domain.CopyStatus) error
+	DeleteCopy(ctx context.Context, id uint64) error
+
+	Checkout(ctx context.Context, l *domain.Loan, maxActive int) (uint64, error)
+	GetLoan(ctx context.Context, id uint64) (*domain.Loan, error)
+	ActiveLoan(ctx context.Context, copyID uint64) (*domain.Loan, error)
+	RenewLoan(ctx context.Context, l *domain.Loan) error
+	ReturnLoan(ctx context.Context, l *domain.Loan) error
+	ListLoans(ctx context.Context, f domain.LoanFilter) (domain.Page[domain.LoanDetail], error)
+
+	PlaceHold(ctx context.Context, h *domain.Hold, maxActive int) (uint64, error)
+	GetHold(ctx context.Context, id uint64) (*domain.Hold, error)
+	ReadyHold(ctx context.Context, copyID uint64) (*domain.Hold, error)
+	CancelHold(ctx context.Context, h *domain.Hold) error
+	ExpireHolds(ctx context.Context, now time.Time) (int, error)
+	ListHolds(ctx context.Context, f domain.HoldFilter) (domain.Page[domain.HoldDetail], error)
+	Availability(ctx context.Context, bookID uint64) (domain.Availability, error)
+}
+
+// RelationRepo persiste las relaciones tipadas entre libros.
+type RelationRepo interface {
+	Create(ctx context.Context, r *domain.BookRelation) (uint64, error)
+	GetByID(ctx context.Context, id uint64) (*domain.BookRelation, error)
+	Delete(ctx context.Context, id uint64) error
+	ByType(ctx context.Context, t domain.RelationType) ([]*domain.BookRelation, error)
+	ByBook(ctx context.Context, bookID uint64) ([]domain.RelatedBook, error)
+}
+
+// FileRepo persiste los metadatos de los adjuntos de un libro.
+type FileRepo interface {
+	Create(ctx context.Context, f *domain.BookFile) (uint64, error)
+	GetByID(ctx context.Context, id uint64) (*domain.BookFile, error)
+	ByBook(ctx context.Context, bookID uint64) ([]*domain.BookFile, error)
+	CountBySHA(ctx context.Context, sha256 string) (int, error)
+	Delete(ctx context.Context, id uint64) error
+}
+
+// ProgressRepo persiste el progreso de lectura.
+type ProgressRepo interface {
+	Save(ctx context.Context, p *domain.ReadingProgress) error
+	Get(ctx context.Context, userID, bookID uint64) (*domain.ReadingProgress, error)
+	Delete(ctx context.Context, userID, bookID uint64) error
+	List(ctx context.Context, f domain.ProgressFilter) (domain.Page[domain.ProgressDetail], error)
+	Completion(ctx context.Context, f domain.CompletionFilter) (domain.Page[domain.BookCompletion], error)
+}
+
+// AnnotationRepo persiste las anotaciones de los usuarios.
+type AnnotationRepo interface {
+	Create(ctx context.Context, a *domain.Annotation) (uint64, error)
+	GetByID(ctx context.Context, id uint64) (*domain.Annotation, error)
+	Update(ctx context.Context, a *domain.Annotation) error
+	Delete(ctx context.Context, id uint64) error
+	List(ctx context.Context, f domain.AnnotationFilter) (domain.Page[domain.AnnotationDetail], error)
+}
+
+// ReviewRepo persiste las reseñas, su historial y los promedios por libro.
+type ReviewRepo interface {
+	Create(ctx context.Context, r *domain.Review) (uint64, error)
+	GetByID(ctx context.Context, id uint64) (*domain.Review, error)
+	GetByUserBook(ctx context.Context, userID, bookID uint64) (*domain.Review, error)
+	Update(ctx context.Context, r *domain.Review, prev *domain.ReviewRevision) error
+	Delete(ctx context.Context, id uint64) error
+	List(ctx context.Context, f domain.ReviewFilter) (domain.Page[domain.ReviewDetail], error)
+	History(ctx context.Context, reviewID uint64) ([]domain.ReviewRevision, error)
+	Summaries(ctx context.Context, bookIDs []uint64) (map[uint64]domain.RatingSummary, error)
+}
+
+// RatingIndex recibe el promedio de estrellas de cada libro para que el
+// índice de búsqueda filtre y ordene por valoración. ReviewService lo
+// mantiene al día cuando cambian las reseñas aprobadas.
+type RatingIndex interface {
+	SetRating(bookID uint64, avg float64)
+}
+
+// CoverRepo persiste las portadas de los libros.
+type CoverRepo interface {
+	Get(ctx context.Context, bookID uint64) (*domain.Cover, error)
+	ByBooks(ctx context.Context, bookIDs []uint64) (map[uint64]*domain.Cover, error)
+	Put(ctx context.Context, c *domain.Cover) error
+	CountBySHA(ctx context.Context, sha256 string) (int, error)
+	Delete(ctx context.Context, bookID uint64) error
+}
+
+// ImageResizer inspecciona imágenes y genera miniaturas. Inspect solo lee
+// la cabecera (no decodifica los píxeles), para rechazar dimensiones
+// excesivas antes de reservar memoria; Thumbnail devuelve un JPEG del ancho
+// pedido (sin agrandar imágenes más chicas).
+type ImageResizer interface {
+	Inspect(r io.Reader) (domain.ImageInfo, error)
+	Thumbnail(r io.Reader, width int) ([]byte, error)
+}
+
+// EbookReader lee los EPUB adjuntos: la estructura (capítulos en orden de
+// lectura), cada capítulo como HTML saneado listo para incrustar (con los
+// enlaces internos reescritos con links) y las imágenes que usan. index
+// cuenta desde 0; ErrNotFound si el capítulo o el recurso no existen.
+type EbookReader interface {
+	Outline(r io.ReaderAt, size int64) (domain.EbookOutline, error)
+	Chapter(r io.ReaderAt, size int64, index int, links domain.EbookLinks) (string, error)
+	Resource(r io.ReaderAt, size int64, path string) ([]byte, string, error)
+}
+
+// BlobStore guarda el contenido de los adjuntos por clave (ver
+// domain.BlobKey). Get abre la lectura desde offset para servir rangos
+// HTTP sin leer el archivo completo; ErrNotFound si la clave no existe.
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+	Get(ctx context.Context, key string, offset int64) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// BookSearcher es el índice de texto completo de libros (ranking, frases,
+// prefijos, búsqueda aproximada, fragmentos resaltados, facetas y
+// autocompletado). BookService lo mantiene sincronizado.
+type BookSearcher interface {
+	Index(b *domain.Book)
+	Remove(id uint64)
+	Search(ctx context.Context, f domain.BookFilter) (domain.BookResults, error)
+	Suggest(ctx context.Context, prefix string, limit int) ([]domain.Suggestion, error)
+}
+
+type SessionRepo interface {
+	Create(ctx context.Context, s *domain.Session) error
+	GetByTokenHash(ctx context.Context, tokenHash string) (*domain.Session, error)
+	Delete(ctx context.Context, tokenHash string) error
+	DeleteExpired(ctx context.Context, now time.Time) error
+}
+
+type APITokenRepo interface {
+	Create(ctx context.Context, t *domain.APIToken) (uint64, error)
+	GetByID(ctx context.Context, id uint64) (*domain.APIToken, error)
+	GetByHash(ctx context.Context, tokenHash string) (*domain.APIToken, error)
+	ListByUser(ctx context.Context, userID uint64) ([]*domain.APIToken, error)
+	Revoke(ctx context.Context, id uint64, at time.Time) error
+	TouchLastUsed(ctx context.Context, id uint64, at time.Time) error
 }
 
 // ====== DTO for Update ======
@@ -43,3 +221,49 @@ type UpdateBookInput struct {
 	Description *string
 	Active      *bool
 }
+
+// ====== DTO for Author Update ======
+type UpdateAuthorInput struct {
+	Name *string
+	Bio  *string
+}
+
+// CreditInput es un crédito pedido por el cliente: un autor existente
+// (AuthorID) o uno por nombre (se reutiliza si ya existe, si no se crea).
+type CreditInput struct {
+	AuthorID uint64
+	Name     string
+	Role     string
+}
+
+// ====== DTO for Category Update ======
+// ParentID: nil = sin cambio, 0 = mover a la raíz.
+type UpdateCategoryInput struct {
+	Name     *string
+	ParentID *uint64
+}
+
+// UpdateCopyInput representa una actualización parcial de un ejemplar
+// (nil = sin cambios).
+type UpdateCopyInput struct {
+	Barcode  *string
+	Location *string
+	Status   *string
+}
+
+// UpdateAnnotationInput representa una edición parcial de una anotación
+// (nil = sin cambios).
+type UpdateAnnotationInput struct {
+	Location   *string
+	Quote      *string
+	Note       *string
+	Color      *string
+	Visibility *string
+}
+
+// UpdateReviewInput representa una edición parcial de una reseña
+// (nil = sin cambios).
+type UpdateReviewInput struct {
+	Rating *int
+	Text   *string
+}
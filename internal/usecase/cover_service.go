@@ -0,0 +1,212 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+// CoverService administra las portadas. La imagen original va al BlobStore
+// bajo su SHA-256 (compartida si dos libros usan la misma) y las
+// miniaturas se generan al pedirlas por primera vez y quedan en la caché.
+type CoverService struct {
+	repo   CoverRepo
+	books  BookRepo
+	blobs  BlobStore    // imágenes originales
+	cache  BlobStore    // miniaturas generadas (se pueden borrar sin perder nada)
+	images ImageResizer // inspección y escalado
+}
+
+// NewCoverService construye el servicio; cache guarda las miniaturas.
+func NewCoverService(repo CoverRepo, bookRepo BookRepo, blobs, cache BlobStore, images ImageResizer) *CoverService {
+	return &CoverService{repo: repo, books: bookRepo, blobs: blobs, cache: cache, images: images}
+}
+
+// Get obtiene la portada de un libro (ErrNotFound si no tiene).
+func (s *CoverService) Get(ctx context.Context, bookID uint64) (*domain.Cover, error) {
+	if _, err := authorize(ctx, domain.PermBooksRead); err != nil {
+		return nil, err
+	}
+	return s.repo.Get(ctx, bookID)
+}
+
+// ByBooks retorna las portadas de los libros que tengan una (para listados).
+func (s *CoverService) ByBooks(ctx context.Context, bookIDs []uint64) (map[uint64]*domain.Cover, error) {
+	if _, err := authorize(ctx, domain.PermBooksRead); err != nil {
+		return nil, err
+	}
+	return s.repo.ByBooks(ctx, bookIDs)
+}
+
+// Upload valida la imagen (tipo por firma, tamaño en bytes y dimensiones
+// leídas de la cabecera, antes de decodificar los píxeles) y reemplaza la
+// portada del libro. La imagen anterior se borra si nadie más la usa.
+func (s *CoverService) Upload(ctx context.Context, bookID uint64, r io.Reader) (*domain.Cover, error) {
+	if _, err := authorize(ctx, domain.PermBooksWrite); err != nil {
+		return nil, err
+	}
+	if _, err := s.books.GetByID(ctx, bookID); err != nil {
+		return nil, err
+	}
+
+	// Como máximo 5 MB: cabe en memoria y evita un temporal
+	data, err := io.ReadAll(io.LimitReader(r, domain.MaxCoverBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: image is empty", domain.ErrValidation)
+	}
+	contentType, err := domain.SniffCoverType(data[:min(len(data), domain.SniffLen)])
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > domain.MaxCoverBytes {
+		return nil, fmt.Errorf("%w: image exceeds the %d MB limit", domain.ErrTooLarge, domain.MaxCoverBytes>>20)
+	}
+	info, err := s.images.Inspect(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if "image/"+info.Format != contentType {
+		return nil, fmt.Errorf("%w: unreadable image", domain.ErrValidation)
+	}
+
+	sum := sha256.Sum256(data)
+	c, err := domain.NewCover(bookID, hex.EncodeToString(sum[:]), contentType, info.Width, info.Height, int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	// La miniatura se genera ahora: confirma que la imagen decodifica
+	// entera (no solo la cabecera) y deja lista la de los listados
+	if _, err := s.generate(ctx, c.SHA256(), domain.CoverSmall, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+
+	prev, err := s.repo.Get(ctx, bookID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+	if err := s.store(ctx, c, data); err != nil {
+		return nil, err
+	}
+	if prev != nil && prev.SHA256() != c.SHA256() {
+		_ = releaseCover(ctx, s.repo, s.blobs, s.cache, prev.SHA256())
+	}
+	return s.repo.Get(ctx, bookID)
+}
+
+// store guarda la imagen (si ninguna portada la tenía) y la asigna al libro,
+// con el candado del blob tomado (ver blobLocks).
+func (s *CoverService) store(ctx context.Context, c *domain.Cover, data []byte) error {
+	unlock := blobLocks.lock(c.BlobKey())
+	defer unlock()
+
+	stored, err := s.repo.CountBySHA(ctx, c.SHA256())
+	if err != nil {
+		return err
+	}
+	if stored == 0 {
+		if err := s.blobs.Put(ctx, c.BlobKey(), bytes.NewReader(data), c.Size()); err != nil {
+			return err
+		}
+	}
+	if err := s.repo.Put(ctx, c); err != nil {
+		if stored == 0 {
+			_ = deleteCover(ctx, s.blobs, s.cache, c.SHA256())
+		}
+		return err
+	}
+	return nil
+}
+
+// Delete quita la portada del libro y borra la imagen si nadie más la usa.
+func (s *CoverService) Delete(ctx context.Context, bookID uint64) error {
+	if _, err := authorize(ctx, domain.PermBooksWrite); err != nil {
+		return err
+	}
+	c, err := s.repo.Get(ctx, bookID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, bookID); err != nil {
+		return err
+	}
+	return releaseCover(ctx, s.repo, s.blobs, s.cache, c.SHA256())
+}
+
+// Thumbnail devuelve la miniatura JPEG del tamaño pedido junto con la
+// portada (para el ETag). Si no está en la caché la genera y la guarda.
+func (s *CoverService) Thumbnail(ctx context.Context, bookID uint64, size domain.CoverSize) ([]byte, *domain.Cover, error) {
+	if _, err := authorize(ctx, domain.PermBooksRead); err != nil {
+		return nil, nil, err
+	}
+	c, err := s.repo.Get(ctx, bookID)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := s.thumbnail(ctx, c, size)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, c, nil
+}
+
+// thumbnail lee la miniatura de la caché o la genera desde la original.
+func (s *CoverService) thumbnail(ctx context.Context, c *domain.Cover, size domain.CoverSize) ([]byte, error) {
+	if rc, err := s.cache.Get(ctx, domain.ThumbnailKey(c.SHA256(), size), 0); err == nil {
+		defer rc.Close()
+		return io.ReadAll(rc)
+	} else if !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+
+	rc, err := s.blobs.Get(ctx, c.BlobKey(), 0)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return s.generate(ctx, c.SHA256(), size, rc)
+}
+
+// generate escala src y guarda el resultado en la caché. Dos pedidos
+// simultáneos pueden generar la misma miniatura: el almacén reemplaza el
+// archivo de forma atómica y ambos resultados son iguales.
+func (s *CoverService) generate(ctx context.Context, sha string, size domain.CoverSize, src io.Reader) ([]byte, error) {
+	data, err := s.images.Thumbnail(src, size.Width())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.cache.Put(ctx, domain.ThumbnailKey(sha, size), bytes.NewReader(data), int64(len(data))); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// releaseCover borra la imagen original y sus miniaturas si ya ninguna
+// portada la usa.
+func releaseCover(ctx context.Context, covers CoverRepo, blobs, cache BlobStore, sha string) error {
+	unlock := blobLocks.lock(domain.CoverBlobKey(sha))
+	defer unlock()
+	n, err := covers.CountBySHA(ctx, sha)
+	if err != nil || n > 0 {
+		return err
+	}
+	return deleteCover(ctx, blobs, cache, sha)
+}
+
+// deleteCover borra la imagen original y sus miniaturas (con el candado del
+// blob tomado).
+func deleteCover(ctx context.Context, blobs, cache BlobStore, sha string) error {
+	for _, size := range domain.CoverSizes {
+		_ = cache.Delete(ctx, domain.ThumbnailKey(sha, size))
+	}
+	return blobs.Delete(ctx, domain.CoverBlobKey(sha))
+}
@@ -0,0 +1,101 @@
+package usecase
+
+import (
+    "bytes"
+    "errors"
+    "image"
+    "image/color"
+    "image/jpeg"
+    "image/png"
+    "strings"
+    "testing"
+
+    "github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+    "github.com/jfmg0509/sistema_libros_funcional_go/internal/infrastructure/memory"
+    "github.com/jfmg0509/sistema_libros_funcional_go/internal/infrastructure/blob"
+    "github.com/jfmg0509/sistema_libros_funcional_go/internal/infrastructure/imaging"
+)
+
+// pngImage genera un PNG de w x h (semitransparente, para probar el aplanado).
+func pngImage(t *testing.T, w, h int) []byte {
+    t.Helper()
+    img := image.NewNRGBA(image.Rect(0, 0, w, h))
+    for y := 0; y < h; y++ {
+        for x := 0; x < w; x++ { img.Set(x, y, color.NRGBA{R: 200, G: uint8(x), B: uint8(y), A: 128}) }
+    }
+    var buf bytes.Buffer
+    if err := png.Encode(&buf, img); err != nil { t.Fatal(err) }
+    return buf.Bytes()
+}
+
+func TestCoverServiceUploadAndThumbnails(t *testing.T) {
+    s := newMemStore()
+    users, books, covers := memory.NewUserRepo(s), memory.NewBookRepo(s), memory.NewCoverRepo(s)
+    blobDir, cacheDir := t.TempDir(), t.TempDir()
+    blobs, err := blob.NewLocalStore(blobDir)
+    if err != nil { t.Fatal(err) }
+    cache, err := blob.NewLocalStore(cacheDir)
+    if err != nil { t.Fatal(err) }
+    bookSvc := NewBookService(books, users, newMemAccessRepo(), nil)
+    bookSvc.SetCoverStore(covers, blobs, cache)
+    svc := NewCoverService(covers, books, blobs, cache, imaging.NewResizer())
+
+    adminCtx, _ := actorCtx(users, domain.RoleAdmin)
+    readerCtx, _ := actorCtx(users, domain.RoleReader)
+
+    uno, err := bookSvc.Create(adminCtx, "Uno", "Ana", 2001, "9780306400070", "Novela", nil, "")
+    if err != nil { t.Fatalf("create book: %v", err) }
+
+    portada := pngImage(t, 600, 900)
+    if _, err := svc.Upload(readerCtx, uno.ID(), bytes.NewReader(portada)); !errors.Is(err, domain.ErrForbidden) {
+        t.Fatalf("expected forbidden for reader, got %v", err)
+    }
+    if _, err := svc.Upload(adminCtx, uno.ID(), strings.NewReader("GIF89a....")); !errors.Is(err, domain.ErrValidation) {
+        t.Fatalf("expected unsupported type, got %v", err)
+    }
+    // Firma PNG válida pero contenido corrupto
+    if _, err := svc.Upload(adminCtx, uno.ID(), bytes.NewReader(portada[:200])); !errors.Is(err, domain.ErrValidation) {
+        t.Fatalf("expected unreadable image, got %v", err)
+    }
+    if _, err := svc.Upload(adminCtx, uno.ID(), bytes.NewReader(pngImage(t, domain.MaxCoverSide+1, 10))); !errors.Is(err, domain.ErrValidation) {
+        t.Fatalf("expected dimension limit, got %v", err)
+    }
+
+    c, err := svc.Upload(adminCtx, uno.ID(), bytes.NewReader(portada))
+    if err != nil { t.Fatalf("upload: %v", err) }
+    if c.ContentType() != "image/png" || c.Width() != 600 || c.Height() != 900 || c.Size() != int64(len(portada)) {
+        t.Fatalf("unexpected cover %+v", c)
+    }
+    if countBlobs(t, cacheDir) != 1 { t.Fatal("expected the small thumbnail to be generated on upload") }
+
+    // Miniaturas JPEG con la proporción original, sin agrandar
+    for size, want := range map[domain.CoverSize]image.Point{
+        domain.CoverSmall: {96, 144}, domain.CoverMedium: {240, 360}, domain.CoverLarge: {480, 720},
+    } {
+        data, got, err := svc.Thumbnail(readerCtx, uno.ID(), size)
+        if err != nil { t.Fatalf("thumbnail %s: %v", size, err) }
+        cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+        if err != nil || cfg.Width != want.X || cfg.Height != want.Y || got.SHA256() != c.SHA256() {
+            t.Fatalf("thumbnail %s: %dx%d, %v", size, cfg.Width, cfg.Height, err)
+        }
+    }
+    if countBlobs(t, cacheDir) != 3 { t.Fatalf("expected 3 cached thumbnails, got %d", countBlobs(t, cacheDir)) }
+
+    chica := pngImage(t, 50, 80)
+    if _, err := svc.Upload(adminCtx, uno.ID(), bytes.NewReader(chica)); err != nil { t.Fatalf("replace: %v", err) }
+    data, _, err := svc.Thumbnail(readerCtx, uno.ID(), domain.CoverLarge)
+    if err != nil { t.Fatalf("thumbnail: %v", err) }
+    if cfg, _ := jpeg.DecodeConfig(bytes.NewReader(data)); cfg.Width != 50 || cfg.Height != 80 {
+        t.Fatalf("small image was resized to %dx%d", cfg.Width, cfg.Height)
+    }
+    // Reemplazar borra la imagen anterior y sus miniaturas
+    if countBlobs(t, blobDir) != 1 || countBlobs(t, cacheDir) != 2 {
+        t.Fatalf("old cover not released: %d blobs, %d thumbnails", countBlobs(t, blobDir), countBlobs(t, cacheDir))
+    }
+
+    if err := bookSvc.Delete(adminCtx, uno.ID()); err != nil { t.Fatalf("delete book: %v", err) }
+    if _, _, err := svc.Thumbnail(readerCtx, uno.ID(), domain.CoverSmall); !errors.Is(err, domain.ErrNotFound) {
+        t.Fatalf("expected cover gone with its book, got %v", err)
+    }
+    if countBlobs(t, blobDir)+countBlobs(t, cacheDir) != 0 { t.Fatal("cover files left after deleting the book") }
+}
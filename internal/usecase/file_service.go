@@ -0,0 +1,245 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+// FileService administra los adjuntos de los libros (PDF, EPUB, audio).
+// El contenido va al BlobStore bajo su SHA-256: subir dos veces el mismo
+// archivo (aunque sea para libros distintos) lo guarda una sola vez, y el
+// blob se borra cuando ya ningún adjunto lo usa.
+type FileService struct {
+	repo    FileRepo
+	books   BookRepo
+	blobs   BlobStore
+	maxSize int64           // tamaño máximo de un adjunto en bytes
+	loans   CirculationRepo // opcional: los libros con puestos digitales se descargan con préstamo
+}
+
+// NewFileService construye el servicio; maxSize <= 0 usa domain.DefaultMaxFileSize.
+func NewFileService(repo FileRepo, bookRepo BookRepo, blobs BlobStore, maxSize int64) *FileService {
+	if maxSize <= 0 {
+		maxSize = domain.DefaultMaxFileSize
+	}
+	return &FileService{repo: repo, books: bookRepo, blobs: blobs, maxSize: maxSize}
+}
+
+// SetCirculationRepo activa la regla de los puestos digitales: el archivo de
+// un libro con licencia de préstamo solo se descarga con un préstamo vigente.
+func (s *FileService) SetCirculationRepo(r CirculationRepo) { s.loans = r }
+
+// MaxSize devuelve el tamaño máximo aceptado (para avisarlo en la UI).
+func (s *FileService) MaxSize() int64 { return s.maxSize }
+
+// Files lista los adjuntos de un libro.
+func (s *FileService) Files(ctx context.Context, bookID uint64) ([]*domain.BookFile, error) {
+	if _, err := authorize(ctx, domain.PermBooksRead); err != nil {
+		return nil, err
+	}
+	return s.repo.ByBook(ctx, bookID)
+}
+
+// Get obtiene los metadatos de un adjunto.
+func (s *FileService) Get(ctx context.Context, id uint64) (*domain.BookFile, error) {
+	if _, err := authorize(ctx, domain.PermBooksRead); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// Upload lee r una sola vez: detecta el tipo por los primeros bytes (antes
+// de recibir el resto), copia el contenido a un temporal calculando el
+// SHA-256 y cortando al superar el máximo (ErrTooLarge), y recién entonces
+// lo sube al almacén si ese contenido no estaba ya guardado.
+func (s *FileService) Upload(ctx context.Context, bookID uint64, name string, r io.Reader) (*domain.BookFile, error) {
+	actor, err := authorize(ctx, domain.PermBooksWrite)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.books.GetByID(ctx, bookID); err != nil {
+		return nil, err
+	}
+
+	head := make([]byte, domain.SniffLen)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("%w: file is empty", domain.ErrValidation)
+	}
+	head = head[:n]
+	contentType, kind, err := domain.SniffFileType(head)
+	if err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp("", "upload-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), io.LimitReader(io.MultiReader(bytes.NewReader(head), r), s.maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if size > s.maxSize {
+		return nil, fmt.Errorf("%w: file exceeds the %d MB limit", domain.ErrTooLarge, s.maxSize>>20)
+	}
+
+	f, err := domain.NewBookFile(bookID, name, contentType, kind, size, hex.EncodeToString(hash.Sum(nil)), actor.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := s.store(ctx, f, tmp)
+	if err != nil {
+		if errors.Is(err, domain.ErrDuplicate) {
+			return nil, fmt.Errorf("%w: the book already has a file with the same content", domain.ErrDuplicate)
+		}
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// store guarda el contenido (si ningún adjunto lo tenía) y crea el adjunto,
+// con el candado del blob tomado para que un borrado simultáneo del último
+// adjunto con ese contenido no se lleve el blob (ver blobLocks).
+func (s *FileService) store(ctx context.Context, f *domain.BookFile, content io.ReadSeeker) (uint64, error) {
+	unlock := blobLocks.lock(f.BlobKey())
+	defer unlock()
+
+	// Deduplicación: si otro adjunto ya tiene este contenido, el blob existe
+	stored, err := s.repo.CountBySHA(ctx, f.SHA256())
+	if err != nil {
+		return 0, err
+	}
+	if stored == 0 {
+		if _, err := content.Seek(0, io.SeekStart); err != nil {
+			return 0, err
+		}
+		if err := s.blobs.Put(ctx, f.BlobKey(), content, f.Size()); err != nil {
+			return 0, err
+		}
+	}
+	id, err := s.repo.Create(ctx, f)
+	if err != nil && stored == 0 {
+		_ = s.blobs.Delete(ctx, f.BlobKey())
+	}
+	return id, err
+}
+
+// Delete elimina el adjunto y su blob si ningún otro adjunto lo usa.
+func (s *FileService) Delete(ctx context.Context, id uint64) error {
+	if _, err := authorize(ctx, domain.PermBooksWrite); err != nil {
+		return err
+	}
+	f, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	return releaseBlob(ctx, s.repo, s.blobs, f.SHA256())
+}
+
+// Open abre el contenido para descargarlo (requiere books:download y, si el
+// libro tiene puestos digitales, un préstamo vigente). El
+// lector admite Seek: http.ServeContent lo usa para servir rangos, y cada
+// salto vuelve a pedir el blob desde el nuevo offset en vez de leer lo
+// que hay antes.
+func (s *FileService) Open(ctx context.Context, id uint64) (*domain.BookFile, io.ReadSeekCloser, error) {
+	if _, err := authorize(ctx, domain.PermBooksDownload); err != nil {
+		return nil, nil, err
+	}
+	f, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := requireSeat(ctx, s.loans, f.BookID()); err != nil {
+		return nil, nil, err
+	}
+	// Se abre enseguida para fallar antes de escribir la respuesta si el blob falta
+	rc, err := s.blobs.Get(ctx, f.BlobKey(), 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, &blobReader{ctx: ctx, blobs: s.blobs, key: f.BlobKey(), size: f.Size(), rc: rc}, nil
+}
+
+// releaseBlob borra el blob del contenido si ya ningún adjunto lo usa.
+func releaseBlob(ctx context.Context, files FileRepo, blobs BlobStore, sha string) error {
+	unlock := blobLocks.lock(domain.BlobKey(sha))
+	defer unlock()
+	n, err := files.CountBySHA(ctx, sha)
+	if err != nil || n > 0 {
+		return err
+	}
+	return blobs.Delete(ctx, domain.BlobKey(sha))
+}
+
+// blobReader es un io.ReadSeekCloser sobre un BlobStore: Seek solo mueve el
+// offset y la próxima lectura abre el blob desde ahí.
+type blobReader struct {
+	ctx    context.Context
+	blobs  BlobStore
+	key    string
+	size   int64
+	offset int64
+	rc     io.ReadCloser // lectura abierta en offset (nil tras un Seek)
+}
+
+func (b *blobReader) Read(p []byte) (int, error) {
+	if b.offset >= b.size {
+		return 0, io.EOF
+	}
+	if b.rc == nil {
+		rc, err := b.blobs.Get(b.ctx, b.key, b.offset)
+		if err != nil {
+			return 0, err
+		}
+		b.rc = rc
+	}
+	n, err := b.rc.Read(p)
+	b.offset += int64(n)
+	return n, err
+}
+
+func (b *blobReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += b.offset
+	case io.SeekEnd:
+		offset += b.size
+	}
+	if offset < 0 {
+		return 0, errors.New("blob: negative offset")
+	}
+	if offset != b.offset {
+		b.Close()
+		b.offset = offset
+	}
+	return offset, nil
+}
+
+func (b *blobReader) Close() error {
+	if b.rc == nil {
+		return nil
+	}
+	err := b.rc.Close()
+	b.rc = nil
+	return err
+}
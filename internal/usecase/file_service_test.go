@@ -0,0 +1,136 @@
+package usecase
+
+import (
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "strings"
+    "sync"
+    "testing"
+
+    "github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+    "github.com/jfmg0509/sistema_libros_funcional_go/internal/infrastructure/memory"
+    "github.com/jfmg0509/sistema_libros_funcional_go/internal/infrastructure/blob"
+)
+
+// countBlobs cuenta los archivos guardados bajo dir (sin temporales).
+func countBlobs(t *testing.T, dir string) int {
+    t.Helper()
+    n := 0
+    filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+        if err == nil && !d.IsDir() && !strings.HasPrefix(d.Name(), ".") { n++ }
+        return nil
+    })
+    return n
+}
+
+func TestFileServiceUploadDedupAndDownload(t *testing.T) {
+    s := newMemStore()
+    users, books, files := memory.NewUserRepo(s), memory.NewBookRepo(s), memory.NewFileRepo(s)
+    dir := t.TempDir()
+    blobs, err := blob.NewLocalStore(dir)
+    if err != nil { t.Fatal(err) }
+    bookSvc := NewBookService(books, users, newMemAccessRepo(), nil)
+    bookSvc.SetFileStore(files, blobs)
+    svc := NewFileService(files, books, blobs, 1024)
+
+    adminCtx, admin := actorCtx(users, domain.RoleAdmin)
+    readerCtx, _ := actorCtx(users, domain.RoleReader)
+    consultorCtx, _ := actorCtx(users, domain.RoleConsultor)
+
+    uno, err := bookSvc.Create(adminCtx, "Uno", "Ana", 2001, "9780306400070", "Novela", nil, "")
+    if err != nil { t.Fatalf("create book: %v", err) }
+    dos, err := bookSvc.Create(adminCtx, "Dos", "Ana", 2002, "9780306406157", "Novela", nil, "")
+    if err != nil { t.Fatalf("create book: %v", err) }
+
+    pdf := "%PDF-1.4 contenido de prueba"
+    if _, err := svc.Upload(readerCtx, uno.ID(), "a.pdf", strings.NewReader(pdf)); !errors.Is(err, domain.ErrForbidden) {
+        t.Fatalf("expected forbidden for reader, got %v", err)
+    }
+    if _, err := svc.Upload(adminCtx, uno.ID(), "notas.pdf", strings.NewReader("solo texto")); !errors.Is(err, domain.ErrValidation) {
+        t.Fatalf("expected unsupported type, got %v", err)
+    }
+    if _, err := svc.Upload(adminCtx, uno.ID(), "grande.pdf", strings.NewReader("%PDF-"+strings.Repeat("x", 2048))); !errors.Is(err, domain.ErrTooLarge) {
+        t.Fatalf("expected too large, got %v", err)
+    }
+
+    // El nombre pierde la ruta del cliente y el tipo sale del contenido
+    f1, err := svc.Upload(adminCtx, uno.ID(), `C:\docs\Uno.pdf`, strings.NewReader(pdf))
+    if err != nil { t.Fatalf("upload: %v", err) }
+    if f1.Name() != "Uno.pdf" || f1.ContentType() != "application/pdf" || f1.Kind() != domain.FileKindPDF ||
+        f1.Size() != int64(len(pdf)) || f1.UploadedBy() != admin.ID() {
+        t.Fatalf("unexpected file %+v", f1)
+    }
+
+    // Mismo contenido: duplicado en el libro, compartido (un solo blob) en otro
+    if _, err := svc.Upload(adminCtx, uno.ID(), "copia.pdf", strings.NewReader(pdf)); !errors.Is(err, domain.ErrDuplicate) {
+        t.Fatalf("expected duplicate in the same book, got %v", err)
+    }
+    f2, err := svc.Upload(adminCtx, dos.ID(), "dos.pdf", strings.NewReader(pdf))
+    if err != nil { t.Fatalf("upload to second book: %v", err) }
+    if f2.SHA256() != f1.SHA256() || countBlobs(t, dir) != 1 {
+        t.Fatalf("expected a single shared blob, got %d", countBlobs(t, dir))
+    }
+
+    // Descarga: requiere books:download y admite saltos (rangos HTTP)
+    if _, _, err := svc.Open(consultorCtx, f1.ID()); !errors.Is(err, domain.ErrForbidden) {
+        t.Fatalf("expected forbidden download for consultor, got %v", err)
+    }
+    _, content, err := svc.Open(readerCtx, f1.ID())
+    if err != nil { t.Fatalf("open: %v", err) }
+    if end, _ := content.Seek(0, io.SeekEnd); end != int64(len(pdf)) { t.Fatalf("unexpected size %d", end) }
+    content.Seek(9, io.SeekStart)
+    got, _ := io.ReadAll(content)
+    content.Close()
+    if string(got) != pdf[9:] { t.Fatalf("read from offset = %q", got) }
+
+    // El blob se borra cuando ya nadie lo usa (al borrar el adjunto o el libro)
+    if err := svc.Delete(adminCtx, f1.ID()); err != nil { t.Fatalf("delete: %v", err) }
+    if countBlobs(t, dir) != 1 { t.Fatal("blob removed while still in use") }
+    if err := bookSvc.Delete(adminCtx, dos.ID()); err != nil { t.Fatalf("delete book: %v", err) }
+    if n := countBlobs(t, dir); n != 0 { t.Fatalf("expected no blobs after deleting the book, got %d", n) }
+    if _, err := svc.Get(readerCtx, f2.ID()); !errors.Is(err, domain.ErrNotFound) {
+        t.Fatalf("expected file gone with its book, got %v", err)
+    }
+}
+
+func TestFileServiceConcurrentUploadAndDelete(t *testing.T) {
+    s := newMemStore()
+    users, books, files := memory.NewUserRepo(s), memory.NewBookRepo(s), memory.NewFileRepo(s)
+    blobs, err := blob.NewLocalStore(t.TempDir())
+    if err != nil { t.Fatal(err) }
+    bookSvc := NewBookService(books, users, newMemAccessRepo(), nil)
+    svc := NewFileService(files, books, blobs, 1024)
+    adminCtx, _ := actorCtx(users, domain.RoleAdmin)
+
+    uno, err := bookSvc.Create(adminCtx, "Uno", "Ana", 2001, "9780306400070", "Novela", nil, "")
+    if err != nil { t.Fatalf("create book: %v", err) }
+    dos, err := bookSvc.Create(adminCtx, "Dos", "Ana", 2002, "9780306406157", "Novela", nil, "")
+    if err != nil { t.Fatalf("create book: %v", err) }
+
+    // Dos libros suben y borran el mismo contenido a la vez: el borrado del
+    // último adjunto de uno no puede llevarse el blob que el otro acaba de
+    // reutilizar
+    pdf := "%PDF-1.4 contenido compartido"
+    var wg sync.WaitGroup
+    errs := make(chan error, 2)
+    for _, id := range []uint64{uno.ID(), dos.ID()} {
+        wg.Add(1)
+        go func(bookID uint64) {
+            defer wg.Done()
+            for i := 0; i < 200; i++ {
+                f, err := svc.Upload(adminCtx, bookID, "x.pdf", strings.NewReader(pdf))
+                if err != nil { errs <- err; return }
+                _, rc, err := svc.Open(adminCtx, f.ID())
+                if err != nil { errs <- fmt.Errorf("open after upload: %w", err); return }
+                rc.Close()
+                if err := svc.Delete(adminCtx, f.ID()); err != nil { errs <- err; return }
+            }
+        }(id)
+    }
+    wg.Wait()
+    close(errs)
+    for err := range errs { t.Fatal(err) }
+}
@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+// ProgressService guarda por dónde va cada lector en cada libro y resume
+// las tasas de finalización para quien ve estadísticas (docentes).
+type ProgressService struct {
+	repo  ProgressRepo
+	books BookRepo
+}
+
+// NewProgressService construye el servicio de progreso de lectura.
+func NewProgressService(repo ProgressRepo, bookRepo BookRepo) *ProgressService {
+	return &ProgressService{repo: repo, books: bookRepo}
+}
+
+// maxVisitedPercent es el avance máximo que deja abrir una parte del libro
+// (ver visit): abrir el último capítulo no es haberlo leído.
+const maxVisitedPercent = 99.9
+
+// Update guarda la posición del usuario actual en el libro. finished fija
+// explícitamente si lo terminó; si es nil, el libro queda terminado al
+// llegar al 100% y sigue terminado aunque después lo relea.
+func (s *ProgressService) Update(ctx context.Context, bookID uint64, pos domain.ReadingPosition, finished *bool) (*domain.ReadingProgress, error) {
+	return s.save(ctx, bookID, pos, finished, false)
+}
+
+// visit guarda la parte del libro que el usuario actual acaba de abrir en el
+// lector (un GET, que también puede venir de un enlace precargado). No lo da
+// por terminado ni lo desmarca: el avance queda por debajo del 100% y
+// terminarlo es siempre explícito.
+func (s *ProgressService) visit(ctx context.Context, bookID uint64, pos domain.ReadingPosition) (*domain.ReadingProgress, error) {
+	return s.save(ctx, bookID, pos, nil, true)
+}
+
+func (s *ProgressService) save(ctx context.Context, bookID uint64, pos domain.ReadingPosition, finished *bool, visited bool) (*domain.ReadingProgress, error) {
+	u, err := authorize(ctx, domain.PermBooksRead)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.books.GetByID(ctx, bookID); err != nil {
+		return nil, err
+	}
+	if finished != nil && *finished {
+		pos.Percent = 100
+	}
+	if visited {
+		if pos, err = pos.Normalize(); err != nil {
+			return nil, err
+		}
+		pos.Percent = min(pos.Percent, maxVisitedPercent)
+	}
+	p, err := domain.NewReadingProgress(u.ID(), bookID, pos)
+	if err != nil {
+		return nil, err
+	}
+	prev, err := s.repo.Get(ctx, u.ID(), bookID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+
+	done := p.Percent() >= 100 || (prev != nil && prev.Finished())
+	if finished != nil {
+		done = *finished
+	}
+	if done {
+		at := p.LastReadAt()
+		if prev != nil && prev.Finished() {
+			at = prev.FinishedAt() // se conserva la primera vez que lo terminó
+		}
+		p.MarkFinished(at)
+	}
+	if err := s.repo.Save(ctx, p); err != nil {
+		return nil, err
+	}
+	return s.repo.Get(ctx, u.ID(), bookID)
+}
+
+// Get obtiene el progreso del usuario actual en el libro (ErrNotFound si
+// todavía no lo empezó).
+func (s *ProgressService) Get(ctx context.Context, bookID uint64) (*domain.ReadingProgress, error) {
+	u, err := authorize(ctx, domain.PermBooksRead)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.Get(ctx, u.ID(), bookID)
+}
+
+// Delete olvida el progreso del usuario actual en el libro.
+func (s *ProgressService) Delete(ctx context.Context, bookID uint64) error {
+	u, err := authorize(ctx, domain.PermBooksRead)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, u.ID(), bookID)
+}
+
+// Mine lista los libros que lee o leyó el usuario actual (f.UserID se
+// ignora: siempre es el propio).
+func (s *ProgressService) Mine(ctx context.Context, f domain.ProgressFilter) (domain.Page[domain.ProgressDetail], error) {
+	u, err := authorize(ctx, domain.PermBooksRead)
+	if err != nil {
+		return domain.Page[domain.ProgressDetail]{}, err
+	}
+	f.UserID = u.ID()
+	return s.repo.List(ctx, f)
+}
+
+// Readers lista el progreso de todos los lectores (de un libro si
+// f.BookID != 0). Requiere permiso de estadísticas.
+func (s *ProgressService) Readers(ctx context.Context, f domain.ProgressFilter) (domain.Page[domain.ProgressDetail], error) {
+	if _, err := authorize(ctx, domain.PermStatsRead); err != nil {
+		return domain.Page[domain.ProgressDetail]{}, err
+	}
+	return s.repo.List(ctx, f)
+}
+
+// Completion resume por libro cuántos lectores lo empezaron y terminaron.
+// Requiere permiso de estadísticas.
+func (s *ProgressService) Completion(ctx context.Context, f domain.CompletionFilter) (domain.Page[domain.BookCompletion], error) {
+	if _, err := authorize(ctx, domain.PermStatsRead); err != nil {
+		return domain.Page[domain.BookCompletion]{}, err
+	}
+	return s.repo.Completion(ctx, f)
+}
@@ -0,0 +1,76 @@
+package usecase
+
+import (
+    "errors"
+    "testing"
+
+    "github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+    "github.com/jfmg0509/sistema_libros_funcional_go/internal/infrastructure/memory"
+)
+
+func TestProgressServiceUpdateAndCompletion(t *testing.T) {
+    s := newMemStore()
+    users, books, progress := memory.NewUserRepo(s), memory.NewBookRepo(s), memory.NewProgressRepo(s)
+    bookSvc := NewBookService(books, users, newMemAccessRepo(), nil)
+    svc := NewProgressService(progress, books)
+
+    adminCtx, _ := actorCtx(users, domain.RoleAdmin)
+    readerCtx, reader := actorCtx(users, domain.RoleReader)
+    otherCtx, _ := actorCtx(users, domain.RoleReader)
+    teacherCtx, _ := actorCtx(users, domain.RoleConsultor)
+
+    uno, err := bookSvc.Create(adminCtx, "Uno", "Ana", 2001, "9780306400070", "Novela", nil, "")
+    if err != nil { t.Fatalf("create book: %v", err) }
+
+    if _, err := svc.Update(readerCtx, 999999, domain.ReadingPosition{Page: 1}, nil); !errors.Is(err, domain.ErrNotFound) {
+        t.Fatalf("expected missing book, got %v", err)
+    }
+    if _, err := svc.Update(readerCtx, uno.ID(), domain.ReadingPosition{Page: 9, TotalPages: 5}, nil); !errors.Is(err, domain.ErrValidation) {
+        t.Fatalf("expected invalid position, got %v", err)
+    }
+
+    p, err := svc.Update(readerCtx, uno.ID(), domain.ReadingPosition{Page: 50, TotalPages: 200}, nil)
+    if err != nil { t.Fatalf("update: %v", err) }
+    if p.UserID() != reader.ID() || p.Percent() != 25 || p.Finished() {
+        t.Fatalf("unexpected progress %+v", p)
+    }
+
+    // Llegar al 100% lo marca terminado; releerlo no lo "desmarca"
+    done, err := svc.Update(readerCtx, uno.ID(), domain.ReadingPosition{Page: 200, TotalPages: 200}, nil)
+    if err != nil || !done.Finished() { t.Fatalf("expected finished at the last page, got %+v, %v", done, err) }
+    again, err := svc.Update(readerCtx, uno.ID(), domain.ReadingPosition{Page: 3, TotalPages: 200}, nil)
+    if err != nil || !again.Finished() || again.Page() != 3 || !again.FinishedAt().Equal(done.FinishedAt()) {
+        t.Fatalf("expected to stay finished while rereading, got %+v, %v", again, err)
+    }
+    no := false
+    if p, err := svc.Update(readerCtx, uno.ID(), domain.ReadingPosition{Page: 3, TotalPages: 200}, &no); err != nil || p.Finished() {
+        t.Fatalf("expected explicit unfinish, got %+v, %v", p, err)
+    }
+    yes := true
+    if p, err := svc.Update(otherCtx, uno.ID(), domain.ReadingPosition{Location: "epubcfi(/6/2!/4:0)"}, &yes); err != nil || !p.Finished() || p.Percent() != 100 {
+        t.Fatalf("expected explicit finish at 100%%, got %+v, %v", p, err)
+    }
+
+    // Cada lector ve solo lo suyo
+    mine, err := svc.Mine(readerCtx, domain.ProgressFilter{UserID: 999})
+    if err != nil || mine.Total != 1 || mine.Items[0].Progress.UserID() != reader.ID() {
+        t.Fatalf("unexpected own list %+v, %v", mine, err)
+    }
+
+    // Las tasas solo las ve quien tiene permiso de estadísticas
+    if _, err := svc.Completion(readerCtx, domain.CompletionFilter{}); !errors.Is(err, domain.ErrForbidden) {
+        t.Fatalf("expected forbidden for reader, got %v", err)
+    }
+    if _, err := svc.Readers(readerCtx, domain.ProgressFilter{BookID: uno.ID()}); !errors.Is(err, domain.ErrForbidden) {
+        t.Fatalf("expected forbidden readers list, got %v", err)
+    }
+    c, err := svc.Completion(teacherCtx, domain.CompletionFilter{})
+    if err != nil || c.Total != 1 || c.Items[0].Readers != 2 || c.Items[0].Finished != 1 || c.Items[0].Rate() != 50 {
+        t.Fatalf("unexpected completion %+v, %v", c, err)
+    }
+
+    if err := svc.Delete(readerCtx, uno.ID()); err != nil { t.Fatalf("delete: %v", err) }
+    if _, err := svc.Get(readerCtx, uno.ID()); !errors.Is(err, domain.ErrNotFound) {
+        t.Fatalf("expected progress gone, got %v", err)
+    }
+}
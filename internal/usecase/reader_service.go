@@ -0,0 +1,320 @@
+package usecase
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+// DefaultReaderCacheSize es la memoria que usa por defecto la caché de EPUB
+// del lector (y, por lo tanto, el tamaño máximo de un EPUB legible).
+const DefaultReaderCacheSize = 64 << 20
+
+// ReaderService es el lector integrado: abre el EPUB (o, si no hay, el PDF)
+// adjunto a un libro, pagina los EPUB por capítulo del lado del servidor,
+// guarda la posición del usuario en su progreso de lectura y mide las
+// sesiones de lectura (ver ReadingSessions).
+type ReaderService struct {
+	files    FileRepo
+	books    BookRepo
+	blobs    BlobStore
+	ebooks   EbookReader
+	progress *ProgressService
+	sessions *ReadingSessions
+	cache    *ebookCache     // EPUB ya descargados del almacén, por SHA-256
+	loans    CirculationRepo // opcional: los libros con puestos digitales se leen con préstamo
+}
+
+// ReaderPage es lo que muestra el lector en cada pedido.
+type ReaderPage struct {
+	Book     *domain.Book
+	File     *domain.BookFile    // el adjunto que se lee
+	Outline  domain.EbookOutline // solo EPUB
+	At       int                 // capítulo (EPUB) o página (PDF) actual, desde 1
+	Total    int                 // capítulos o páginas (0 si no se conocen)
+	HTML     string              // capítulo saneado (solo EPUB)
+	Progress *domain.ReadingProgress
+}
+
+// NewReaderService construye el lector; cacheSize <= 0 usa
+// DefaultReaderCacheSize.
+func NewReaderService(files FileRepo, bookRepo BookRepo, blobs BlobStore, ebooks EbookReader,
+	progress *ProgressService, sessions *ReadingSessions, cacheSize int64) *ReaderService {
+	if cacheSize <= 0 {
+		cacheSize = DefaultReaderCacheSize
+	}
+	return &ReaderService{
+		files:    files,
+		books:    bookRepo,
+		blobs:    blobs,
+		ebooks:   ebooks,
+		progress: progress,
+		sessions: sessions,
+		cache:    newEbookCache(cacheSize),
+	}
+}
+
+// SetCirculationRepo activa la regla de los puestos digitales: un libro con
+// licencia de préstamo solo se lee con un préstamo vigente (ver
+// CirculationService.Borrow).
+func (s *ReaderService) SetCirculationRepo(r CirculationRepo) { s.loans = r }
+
+// Read abre el libro en el lector. En un EPUB muestra el capítulo at (desde
+// 1; 0 retoma donde quedó el usuario) y lo guarda como su posición, sin
+// darlo por terminado aunque sea el último (ver ProgressService.visit); sin permiso de
+// descarga el capítulo se muestra sin imágenes (ver Resource). En un PDF
+// solo retoma la página guardada: el visor del navegador no avisa los
+// cambios de página, que se guardan con SavePage. Como el visor recibe el
+// archivo original, leer un PDF exige books:download.
+func (s *ReaderService) Read(ctx context.Context, bookID uint64, at int, links domain.EbookLinks) (*ReaderPage, error) {
+	u, b, f, err := s.open(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	_, noDownload := authorize(ctx, domain.PermBooksDownload)
+	if f.Kind() == domain.FileKindPDF && noDownload != nil {
+		return nil, noDownload
+	}
+	if noDownload != nil {
+		links.Resource = nil
+	}
+	prev, err := s.progress.Get(ctx, bookID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+	page := &ReaderPage{Book: b, File: f, Progress: prev}
+
+	if f.Kind() == domain.FileKindPDF {
+		page.At = 1
+		if prev != nil {
+			page.At, page.Total = max(prev.Page(), 1), prev.TotalPages()
+		}
+		s.sessions.Touch(u.ID(), bookID)
+		return page, nil
+	}
+
+	e, err := s.ebook(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	n := len(e.outline.Chapters)
+	if at == 0 {
+		at = resumeChapter(e.outline, prev)
+	}
+	if at < 1 || at > n {
+		return nil, fmt.Errorf("%w: chapter %d (the book has %d)", domain.ErrNotFound, at, n)
+	}
+	html, err := s.ebooks.Chapter(bytes.NewReader(e.data), int64(len(e.data)), at-1, links)
+	if err != nil {
+		return nil, err
+	}
+
+	pos := domain.ReadingPosition{Page: at, TotalPages: n, Location: e.outline.ChapterCFI(at - 1)}
+	if page.Progress, err = s.progress.visit(ctx, bookID, pos); err != nil {
+		return nil, err
+	}
+	s.sessions.Touch(u.ID(), bookID)
+
+	page.Outline, page.At, page.Total, page.HTML = e.outline, at, n, html
+	return page, nil
+}
+
+// SavePage guarda la página por la que va el usuario en un PDF (total es
+// la cantidad de páginas, 0 si no la sabe).
+func (s *ReaderService) SavePage(ctx context.Context, bookID uint64, page, total int) (*domain.ReadingProgress, error) {
+	u, _, _, err := s.open(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	p, err := s.progress.Update(ctx, bookID, domain.ReadingPosition{Page: page, TotalPages: total}, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.sessions.Touch(u.ID(), bookID)
+	return p, nil
+}
+
+// Ping extiende la sesión de lectura del usuario actual (el latido que
+// envía la página del lector mientras está a la vista).
+func (s *ReaderService) Ping(ctx context.Context, bookID uint64) error {
+	u, err := authorize(ctx, domain.PermBooksRead)
+	if err != nil {
+		return err
+	}
+	if _, err := s.books.GetByID(ctx, bookID); err != nil {
+		return err
+	}
+	s.sessions.Touch(u.ID(), bookID)
+	return nil
+}
+
+// Resource devuelve una imagen del EPUB que se lee y su tipo MIME. Son
+// partes del archivo tal cual, así que exige books:download.
+func (s *ReaderService) Resource(ctx context.Context, bookID uint64, path string) ([]byte, string, error) {
+	if _, err := authorize(ctx, domain.PermBooksDownload); err != nil {
+		return nil, "", err
+	}
+	_, _, f, err := s.open(ctx, bookID)
+	if err != nil {
+		return nil, "", err
+	}
+	if f.Kind() != domain.FileKindEPUB {
+		return nil, "", fmt.Errorf("%w: resource %s", domain.ErrNotFound, path)
+	}
+	e, err := s.ebook(ctx, f)
+	if err != nil {
+		return nil, "", err
+	}
+	return s.ebooks.Resource(bytes.NewReader(e.data), int64(len(e.data)), path)
+}
+
+// PDF abre el PDF que se lee para mostrarlo en el visor. El navegador
+// recibe el archivo original, así que exige books:download como
+// FileService.Open, aunque no cuenta como descarga.
+func (s *ReaderService) PDF(ctx context.Context, bookID uint64) (*domain.BookFile, io.ReadSeekCloser, error) {
+	if _, err := authorize(ctx, domain.PermBooksDownload); err != nil {
+		return nil, nil, err
+	}
+	_, _, f, err := s.open(ctx, bookID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if f.Kind() != domain.FileKindPDF {
+		return nil, nil, fmt.Errorf("%w: book is read as EPUB", domain.ErrNotFound)
+	}
+	rc, err := s.blobs.Get(ctx, f.BlobKey(), 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, &blobReader{ctx: ctx, blobs: s.blobs, key: f.BlobKey(), size: f.Size(), rc: rc}, nil
+}
+
+// open valida el permiso (y el préstamo si el libro tiene puestos
+// digitales) y elige el adjunto que se lee: el primer EPUB o, si no hay, el
+// primer PDF (ErrNotFound si el libro no tiene ninguno).
+func (s *ReaderService) open(ctx context.Context, bookID uint64) (*domain.User, *domain.Book, *domain.BookFile, error) {
+	u, err := authorize(ctx, domain.PermBooksRead)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	b, err := s.books.GetByID(ctx, bookID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if err := requireSeat(ctx, s.loans, bookID); err != nil {
+		return nil, nil, nil, err
+	}
+	files, err := s.files.ByBook(ctx, bookID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var pdf *domain.BookFile
+	for _, f := range files {
+		switch {
+		case f.Kind() == domain.FileKindEPUB:
+			return u, b, f, nil
+		case f.Kind() == domain.FileKindPDF && pdf == nil:
+			pdf = f
+		}
+	}
+	if pdf == nil {
+		return nil, nil, nil, fmt.Errorf("%w: book has no EPUB or PDF to read", domain.ErrNotFound)
+	}
+	return u, b, pdf, nil
+}
+
+// ebook trae el EPUB del almacén (o de la caché) con su estructura.
+func (s *ReaderService) ebook(ctx context.Context, f *domain.BookFile) (*cachedEbook, error) {
+	if e := s.cache.get(f.SHA256()); e != nil {
+		return e, nil
+	}
+	if f.Size() > s.cache.max {
+		return nil, fmt.Errorf("%w: EPUB exceeds the %d MB reader limit", domain.ErrTooLarge, s.cache.max>>20)
+	}
+	rc, err := s.blobs.Get(ctx, f.BlobKey(), 0)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, f.Size()))
+	if err != nil {
+		return nil, err
+	}
+	outline, err := s.ebooks.Outline(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	e := &cachedEbook{sha: f.SHA256(), data: data, outline: outline}
+	s.cache.put(e)
+	return e, nil
+}
+
+// resumeChapter elige el capítulo (desde 1) donde retomar: el del CFI
+// guardado o, si no se reconoce, la página guardada cuando el total
+// coincide con el libro.
+func resumeChapter(o domain.EbookOutline, p *domain.ReadingProgress) int {
+	if p == nil {
+		return 1
+	}
+	if i := o.ChapterAt(p.Location()); i >= 0 {
+		return i + 1
+	}
+	if p.TotalPages() == len(o.Chapters) && p.Page() >= 1 {
+		return p.Page()
+	}
+	return 1
+}
+
+// -------------------- Caché de EPUB --------------------
+
+type cachedEbook struct {
+	sha     string
+	data    []byte
+	outline domain.EbookOutline
+}
+
+// ebookCache guarda los últimos EPUB leídos hasta max bytes en total; al
+// pasarse descarta los menos usados.
+type ebookCache struct {
+	mu    sync.Mutex
+	max   int64
+	size  int64
+	order *list.List // *cachedEbook, el más reciente adelante
+	items map[string]*list.Element
+}
+
+func newEbookCache(max int64) *ebookCache {
+	return &ebookCache{max: max, order: list.New(), items: map[string]*list.Element{}}
+}
+
+func (c *ebookCache) get(sha string) *cachedEbook {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[sha]
+	if !ok {
+		return nil
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*cachedEbook)
+}
+
+func (c *ebookCache) put(e *cachedEbook) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.items[e.sha]; ok {
+		return
+	}
+	c.items[e.sha] = c.order.PushFront(e)
+	c.size += int64(len(e.data))
+	for c.size > c.max && c.order.Len() > 1 {
+		old := c.order.Remove(c.order.Back()).(*cachedEbook)
+		delete(c.items, old.sha)
+		c.size -= int64(len(old.data))
+	}
+}
@@ -0,0 +1,194 @@
+package usecase
+
+import (
+    "archive/zip"
+    "bytes"
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+    "github.com/jfmg0509/sistema_libros_funcional_go/internal/infrastructure/memory"
+    "github.com/jfmg0509/sistema_libros_funcional_go/internal/infrastructure/blob"
+    "github.com/jfmg0509/sistema_libros_funcional_go/internal/infrastructure/epub"
+)
+
+// testEPUB arma un EPUB mínimo con los capítulos dados (uno por XHTML).
+func testEPUB(t *testing.T, chapters ...string) []byte {
+    t.Helper()
+    var buf bytes.Buffer
+    w := zip.NewWriter(&buf)
+    add := func(name, content string, method uint16) {
+        f, err := w.CreateHeader(&zip.FileHeader{Name: name, Method: method})
+        if err != nil { t.Fatal(err) }
+        io.WriteString(f, content)
+    }
+    add("mimetype", "application/epub+zip", zip.Store) // primero y sin comprimir
+    add("META-INF/container.xml", `<container><rootfiles><rootfile full-path="content.opf"/></rootfiles></container>`, zip.Deflate)
+    var manifest, spine strings.Builder
+    for i, body := range chapters {
+        fmt.Fprintf(&manifest, `<item id="c%d" href="c%d.xhtml" media-type="application/xhtml+xml"/>`, i, i)
+        fmt.Fprintf(&spine, `<itemref idref="c%d"/>`, i)
+        add(fmt.Sprintf("c%d.xhtml", i), "<html><body>"+body+"</body></html>", zip.Deflate)
+    }
+    add("content.opf", `<package><metadata><title>Prueba</title></metadata><manifest>`+manifest.String()+
+        `<item id="img" href="pic.png" media-type="image/png"/></manifest><spine>`+spine.String()+`</spine></package>`, zip.Deflate)
+    add("pic.png", "png", zip.Deflate)
+    if err := w.Close(); err != nil { t.Fatal(err) }
+    return buf.Bytes()
+}
+
+var testLinks = domain.EbookLinks{
+    Chapter:  func(i int, frag string) string { return fmt.Sprintf("?ch=%d", i+1) },
+    Resource: func(p string) string { return "res?path=" + p },
+}
+
+func TestReaderServiceEPUB(t *testing.T) {
+    s := newMemStore()
+    users, books, files, progress, access := memory.NewUserRepo(s), memory.NewBookRepo(s), memory.NewFileRepo(s), memory.NewProgressRepo(s), memory.NewAccessRepo(s)
+    blobs, err := blob.NewLocalStore(t.TempDir())
+    if err != nil { t.Fatal(err) }
+    bookSvc := NewBookService(books, users, access, nil)
+    fileSvc := NewFileService(files, books, blobs, 0)
+    sessions := NewReadingSessions(access, time.Hour)
+    svc := NewReaderService(files, books, blobs, epub.NewReader(), NewProgressService(progress, books), sessions, 0)
+
+    adminCtx, _ := actorCtx(users, domain.RoleAdmin)
+    readerCtx, _ := actorCtx(users, domain.RoleReader)
+
+    uno, err := bookSvc.Create(adminCtx, "Uno", "Ana", 2001, "9780306400070", "Novela", nil, "")
+    if err != nil { t.Fatalf("create book: %v", err) }
+    if _, err := svc.Read(readerCtx, uno.ID(), 0, testLinks); !errors.Is(err, domain.ErrNotFound) {
+        t.Fatalf("expected ErrNotFound without a readable file, got %v", err)
+    }
+    data := testEPUB(t, `<p onclick="x()">Primero <a href="c1.xhtml">sigue</a></p>`, `<p>Segundo</p><img src="pic.png"/>`, `<p>Fin</p>`)
+    if _, err := fileSvc.Upload(adminCtx, uno.ID(), "uno.epub", bytes.NewReader(data)); err != nil { t.Fatalf("upload: %v", err) }
+
+    if _, err := svc.Read(context.Background(), uno.ID(), 0, testLinks); !errors.Is(err, domain.ErrUnauthorized) {
+        t.Fatalf("expected unauthorized, got %v", err)
+    }
+
+    // Sin progreso empieza por el primer capítulo y guarda la posición
+    page, err := svc.Read(readerCtx, uno.ID(), 0, testLinks)
+    if err != nil { t.Fatalf("read: %v", err) }
+    if page.At != 1 || page.Total != 3 || page.Outline.Title != "Prueba" || page.HTML != `<p>Primero <a href="?ch=2">sigue</a></p>` {
+        t.Fatalf("unexpected page %+v", page)
+    }
+    if p := page.Progress; p.Page() != 1 || p.TotalPages() != 3 || p.Location() != "epubcfi(/6/2!)" || p.Finished() {
+        t.Fatalf("unexpected progress %+v", p)
+    }
+
+    page, err = svc.Read(readerCtx, uno.ID(), 2, testLinks)
+    if err != nil || !strings.Contains(page.HTML, `<img src="res?path=pic.png" />`) { t.Fatalf("chapter 2: %+v, %v", page, err) }
+    if _, err := svc.Read(readerCtx, uno.ID(), 4, testLinks); !errors.Is(err, domain.ErrNotFound) {
+        t.Fatalf("expected missing chapter, got %v", err)
+    }
+
+    // Retoma donde quedó; abrir el último capítulo no lo da por terminado
+    page, err = svc.Read(readerCtx, uno.ID(), 0, testLinks)
+    if err != nil || page.At != 2 { t.Fatalf("expected to resume at chapter 2, got %+v, %v", page, err) }
+    page, err = svc.Read(readerCtx, uno.ID(), 3, testLinks)
+    if err != nil || page.Progress.Finished() || page.Progress.Percent() >= 100 {
+        t.Fatalf("expected the last chapter opened but not finished, got %+v, %v", page.Progress, err)
+    }
+
+    // Terminarlo es explícito, y releer un capítulo no lo desmarca
+    done := true
+    if p, err := svc.progress.Update(readerCtx, uno.ID(), page.Progress.Position(), &done); err != nil || !p.Finished() {
+        t.Fatalf("mark finished: %+v, %v", p, err)
+    }
+    page, err = svc.Read(readerCtx, uno.ID(), 1, testLinks)
+    if err != nil || !page.Progress.Finished() { t.Fatalf("expected to stay finished when rereading, got %+v, %v", page.Progress, err) }
+
+    img, mediaType, err := svc.Resource(readerCtx, uno.ID(), "pic.png")
+    if err != nil || string(img) != "png" || mediaType != "image/png" { t.Fatalf("resource: %q %q %v", img, mediaType, err) }
+
+    // Sin permiso de descarga se lee el texto, pero no las imágenes del archivo
+    teacherCtx, _ := actorCtx(users, domain.RoleConsultor)
+    page, err = svc.Read(teacherCtx, uno.ID(), 2, testLinks)
+    if err != nil || !strings.Contains(page.HTML, "Segundo") || strings.Contains(page.HTML, "res?path") { t.Fatalf("teacher chapter 2: %+v, %v", page, err) }
+    if _, _, err := svc.Resource(teacherCtx, uno.ID(), "pic.png"); !errors.Is(err, domain.ErrForbidden) {
+        t.Fatalf("expected forbidden resource, got %v", err)
+    }
+    if _, _, err := svc.PDF(readerCtx, uno.ID()); !errors.Is(err, domain.ErrNotFound) {
+        t.Fatalf("expected no PDF for an EPUB book, got %v", err)
+    }
+
+    // La sesión se registra al cerrarse, con una sola entrada por lector
+    if err := svc.Ping(readerCtx, uno.ID()); err != nil { t.Fatalf("ping: %v", err) }
+    sessions.Close()
+    rt, err := access.ReadingTime(context.Background(), uno.ID())
+    if err != nil || rt.Sessions != 2 { t.Fatalf("expected one reading session per reader, got %+v, %v", rt, err) }
+}
+
+func TestReaderServicePDF(t *testing.T) {
+    s := newMemStore()
+    users, books, files, progress, access := memory.NewUserRepo(s), memory.NewBookRepo(s), memory.NewFileRepo(s), memory.NewProgressRepo(s), memory.NewAccessRepo(s)
+    blobs, err := blob.NewLocalStore(t.TempDir())
+    if err != nil { t.Fatal(err) }
+    bookSvc := NewBookService(books, users, access, nil)
+    fileSvc := NewFileService(files, books, blobs, 0)
+    sessions := NewReadingSessions(access, time.Hour)
+    defer sessions.Close()
+    svc := NewReaderService(files, books, blobs, epub.NewReader(), NewProgressService(progress, books), sessions, 0)
+
+    adminCtx, _ := actorCtx(users, domain.RoleAdmin)
+    readerCtx, _ := actorCtx(users, domain.RoleReader)
+    teacherCtx, _ := actorCtx(users, domain.RoleConsultor) // sin permiso de descarga
+
+    uno, err := bookSvc.Create(adminCtx, "Uno", "Ana", 2001, "9780306400070", "Novela", nil, "")
+    if err != nil { t.Fatalf("create book: %v", err) }
+    pdf := "%PDF-1.4 contenido"
+    if _, err := fileSvc.Upload(adminCtx, uno.ID(), "uno.pdf", strings.NewReader(pdf)); err != nil { t.Fatalf("upload: %v", err) }
+
+    // El visor recibe el archivo original: sin permiso de descarga no se abre
+    if _, err := svc.Read(teacherCtx, uno.ID(), 0, testLinks); !errors.Is(err, domain.ErrForbidden) {
+        t.Fatalf("expected forbidden read, got %v", err)
+    }
+    if _, _, err := svc.PDF(teacherCtx, uno.ID()); !errors.Is(err, domain.ErrForbidden) {
+        t.Fatalf("expected forbidden pdf, got %v", err)
+    }
+
+    page, err := svc.Read(readerCtx, uno.ID(), 0, testLinks)
+    if err != nil || page.File.Kind() != domain.FileKindPDF || page.At != 1 || page.HTML != "" { t.Fatalf("read: %+v, %v", page, err) }
+    if _, err := svc.SavePage(readerCtx, uno.ID(), 12, 40); err != nil { t.Fatalf("save page: %v", err) }
+    page, err = svc.Read(readerCtx, uno.ID(), 0, testLinks)
+    if err != nil || page.At != 12 || page.Total != 40 { t.Fatalf("expected to resume at page 12 of 40, got %+v, %v", page, err) }
+
+    _, rc, err := svc.PDF(readerCtx, uno.ID())
+    if err != nil { t.Fatalf("open pdf: %v", err) }
+    got, _ := io.ReadAll(rc)
+    rc.Close()
+    if string(got) != pdf { t.Fatalf("unexpected pdf %q", got) }
+}
+
+func TestReadingSessionsDuration(t *testing.T) {
+    access := newMemAccessRepo()
+    s := NewReadingSessions(access, 10*time.Minute)
+    clock := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+    s.now = func() time.Time { return clock }
+    at := func(minutes int) { clock = time.Date(2026, 1, 1, 10, minutes, 0, 0, time.UTC) }
+
+    s.Touch(1, 7)
+    at(4)
+    s.Touch(1, 7)
+    s.Touch(2, 7) // otro usuario, otra sesión
+
+    // Vencida la inactividad la próxima actividad abre otra sesión
+    at(30)
+    s.Touch(1, 7)
+    at(33)
+    s.Touch(1, 7)
+
+    // El barrido cierra las inactivas; Close, el resto
+    s.flush(clock.Add(-10 * time.Minute))
+    rt, _ := access.ReadingTime(context.Background(), 7)
+    if rt.Sessions != 2 || rt.Total != 4*time.Minute { t.Fatalf("expected 2 sessions (4m + 0m), got %+v", rt) }
+    s.Close()
+    rt, _ = access.ReadingTime(context.Background(), 7)
+    if rt.Sessions != 3 || rt.Total != 7*time.Minute { t.Fatalf("expected 3 sessions totalling 7m, got %+v", rt) }
+}
@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+// DefaultReadingIdle es cuánto tiempo sin actividad cierra una sesión de
+// lectura si no se configura otro.
+const DefaultReadingIdle = 5 * time.Minute
+
+// ReadingSessions mide cuánto lee cada usuario en el lector integrado.
+// Cada capítulo que se abre, y el latido que envía la página mientras está
+// a la vista, extiende la sesión del usuario en ese libro; cuando pasa el
+// tiempo de inactividad sin actividad la sesión se cierra y se registra un
+// evento LECTURA con su duración. Así ningún cliente tiene que llamar a
+// RecordAccess para que la lectura cuente.
+type ReadingSessions struct {
+	repo AccessRepo
+	idle time.Duration
+	now  func() time.Time
+
+	mu   sync.Mutex
+	open map[readingKey]*readingSession
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+type readingKey struct{ userID, bookID uint64 }
+
+type readingSession struct{ start, last time.Time }
+
+// NewReadingSessions arranca el barrido que cierra las sesiones inactivas;
+// idle <= 0 usa DefaultReadingIdle. Close registra las que sigan abiertas.
+func NewReadingSessions(repo AccessRepo, idle time.Duration) *ReadingSessions {
+	if idle <= 0 {
+		idle = DefaultReadingIdle
+	}
+	s := &ReadingSessions{
+		repo: repo,
+		idle: idle,
+		now:  time.Now,
+		open: map[readingKey]*readingSession{},
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go s.sweep()
+	return s
+}
+
+// Touch registra actividad del usuario en el libro: abre una sesión o
+// extiende la actual. Si la anterior ya había vencido (y el barrido aún no
+// la cerró) se registra y empieza otra.
+func (s *ReadingSessions) Touch(userID, bookID uint64) {
+	now := s.now()
+	k := readingKey{userID, bookID}
+
+	s.mu.Lock()
+	var ended *readingSession
+	cur := s.open[k]
+	if cur != nil && now.Sub(cur.last) > s.idle {
+		ended, cur = cur, nil
+	}
+	if cur == nil {
+		s.open[k] = &readingSession{start: now, last: now}
+	} else if now.After(cur.last) {
+		cur.last = now
+	}
+	s.mu.Unlock()
+
+	if ended != nil {
+		s.record(k, ended)
+	}
+}
+
+// Close detiene el barrido y registra las sesiones abiertas (al apagar).
+func (s *ReadingSessions) Close() {
+	s.closeOnce.Do(func() {
+		close(s.stop)
+		<-s.done
+		s.flush(time.Time{})
+	})
+}
+
+func (s *ReadingSessions) sweep() {
+	defer close(s.done)
+	t := time.NewTicker(max(s.idle/2, time.Second))
+	defer t.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-t.C:
+			s.flush(s.now().Add(-s.idle))
+		}
+	}
+}
+
+// flush cierra y registra las sesiones sin actividad desde antes de
+// cutoff; con cutoff cero, todas.
+func (s *ReadingSessions) flush(cutoff time.Time) {
+	s.mu.Lock()
+	ended := map[readingKey]*readingSession{}
+	for k, rs := range s.open {
+		if cutoff.IsZero() || rs.last.Before(cutoff) {
+			ended[k] = rs
+			delete(s.open, k)
+		}
+	}
+	s.mu.Unlock()
+
+	for k, rs := range ended {
+		s.record(k, rs)
+	}
+}
+
+// record guarda el evento de la sesión. Es best-effort, como AccessQueue:
+// si el libro o el usuario se borraron mientras tanto, se pierde.
+func (s *ReadingSessions) record(k readingKey, rs *readingSession) {
+	e, err := domain.NewReadingSession(k.userID, k.bookID, min(rs.last.Sub(rs.start), domain.MaxReadingSession))
+	if err != nil {
+		return
+	}
+	_, _ = s.repo.Create(context.Background(), e)
+}
@@ -0,0 +1,278 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+// ReviewService gestiona las reseñas: cada lector escribe a lo sumo una por
+// libro y la edita cuando quiere (queda el historial). Toda reseña nueva o
+// editada pasa por la cola de moderación; solo las aprobadas se muestran y
+// cuentan en el promedio del libro.
+type ReviewService struct {
+	repo  ReviewRepo
+	books BookRepo
+	index RatingIndex // opcional: promedios para buscar y ordenar por valoración
+}
+
+// NewReviewService construye el servicio de reseñas.
+func NewReviewService(repo ReviewRepo, bookRepo BookRepo) *ReviewService {
+	return &ReviewService{repo: repo, books: bookRepo}
+}
+
+// SetIndex mantiene al día el promedio de cada libro en el índice de
+// búsqueda. El índice debe venir cargado con los promedios actuales
+// (ReviewRepo.Summaries al arrancar).
+func (s *ReviewService) SetIndex(idx RatingIndex) { s.index = idx }
+
+// Create guarda la reseña del usuario actual sobre el libro (pendiente de
+// moderación). Si ya reseñó el libro retorna ErrDuplicate: se edita la existente.
+func (s *ReviewService) Create(ctx context.Context, bookID uint64, rating int, text string) (*domain.Review, error) {
+	u, err := authorize(ctx, domain.PermBooksRead)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.books.GetByID(ctx, bookID); err != nil {
+		return nil, err
+	}
+	rv, err := domain.NewReview(u.ID(), bookID, rating, text)
+	if err != nil {
+		return nil, err
+	}
+	id, err := s.repo.Create(ctx, rv)
+	if errors.Is(err, domain.ErrDuplicate) {
+		return nil, fmt.Errorf("%w: you already reviewed this book", domain.ErrDuplicate)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// Mine obtiene la reseña del usuario actual sobre el libro (ErrNotFound si
+// todavía no escribió una), en cualquier estado.
+func (s *ReviewService) Mine(ctx context.Context, bookID uint64) (*domain.Review, error) {
+	u, err := authorize(ctx, domain.PermBooksRead)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetByUserBook(ctx, u.ID(), bookID)
+}
+
+// MyReviews lista las reseñas del usuario actual (f.UserID se ignora).
+func (s *ReviewService) MyReviews(ctx context.Context, f domain.ReviewFilter) (domain.Page[domain.ReviewDetail], error) {
+	u, err := authorize(ctx, domain.PermBooksRead)
+	if err != nil {
+		return domain.Page[domain.ReviewDetail]{}, err
+	}
+	f.UserID = u.ID()
+	return s.repo.List(ctx, f)
+}
+
+// Get obtiene una reseña visible para el usuario actual: las aprobadas, las
+// propias y, para quien modera, todas (las demás dan ErrNotFound).
+func (s *ReviewService) Get(ctx context.Context, id uint64) (*domain.Review, error) {
+	u, err := authorize(ctx, domain.PermBooksRead)
+	if err != nil {
+		return nil, err
+	}
+	rv, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !s.visible(ctx, u, rv) {
+		return nil, fmt.Errorf("%w: review %d", domain.ErrNotFound, id)
+	}
+	return rv, nil
+}
+
+// Update edita la reseña propia (nil = sin cambios). La versión anterior
+// queda en el historial y la reseña vuelve a la cola de moderación.
+func (s *ReviewService) Update(ctx context.Context, id uint64, in UpdateReviewInput) (*domain.Review, error) {
+	u, err := authorize(ctx, domain.PermBooksRead)
+	if err != nil {
+		return nil, err
+	}
+	rv, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case rv.UserID() == u.ID():
+	case s.visible(ctx, u, rv):
+		return nil, fmt.Errorf("%w: only the author can edit a review", domain.ErrForbidden)
+	default:
+		return nil, fmt.Errorf("%w: review %d", domain.ErrNotFound, id)
+	}
+
+	rating, text := rv.Rating(), rv.Text()
+	if in.Rating != nil {
+		rating = *in.Rating
+	}
+	if in.Text != nil {
+		text = *in.Text
+	}
+	wasApproved := rv.Approved()
+	prev, err := rv.Edit(rating, text)
+	if err != nil || prev == nil {
+		return rv, err
+	}
+	if err := s.repo.Update(ctx, rv, prev); err != nil {
+		return nil, err
+	}
+	if wasApproved {
+		s.refreshRating(ctx, rv.BookID())
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// Delete borra la reseña propia; quien modera puede borrar cualquiera.
+func (s *ReviewService) Delete(ctx context.Context, id uint64) error {
+	u, err := authorize(ctx, domain.PermBooksRead)
+	if err != nil {
+		return err
+	}
+	rv, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if rv.UserID() != u.ID() && !s.moderator(ctx) {
+		if !s.visible(ctx, u, rv) {
+			return fmt.Errorf("%w: review %d", domain.ErrNotFound, id)
+		}
+		return fmt.Errorf("%w: only the author or a moderator can delete a review", domain.ErrForbidden)
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	if rv.Approved() {
+		s.refreshRating(ctx, rv.BookID())
+	}
+	return nil
+}
+
+// History devuelve las versiones anteriores de la reseña (al autor y a
+// quien modera), de la más antigua a la más reciente.
+func (s *ReviewService) History(ctx context.Context, id uint64) ([]domain.ReviewRevision, error) {
+	u, err := authorize(ctx, domain.PermBooksRead)
+	if err != nil {
+		return nil, err
+	}
+	rv, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if rv.UserID() != u.ID() && !s.moderator(ctx) {
+		if !s.visible(ctx, u, rv) {
+			return nil, fmt.Errorf("%w: review %d", domain.ErrNotFound, id)
+		}
+		return nil, fmt.Errorf("%w: only the author or a moderator can see the edit history", domain.ErrForbidden)
+	}
+	return s.repo.History(ctx, id)
+}
+
+// ByBook lista las reseñas aprobadas del libro (f.Rating filtra por estrellas).
+func (s *ReviewService) ByBook(ctx context.Context, bookID uint64, f domain.ReviewFilter) (domain.Page[domain.ReviewDetail], error) {
+	if _, err := authorize(ctx, domain.PermBooksRead); err != nil {
+		return domain.Page[domain.ReviewDetail]{}, err
+	}
+	if _, err := s.books.GetByID(ctx, bookID); err != nil {
+		return domain.Page[domain.ReviewDetail]{}, err
+	}
+	f.BookID, f.Statuses = bookID, []domain.ReviewStatus{domain.ReviewApproved}
+	return s.repo.List(ctx, f)
+}
+
+// Queue es la cola de moderación: por defecto las reseñas pendientes, la
+// que espera hace más tiempo primero (f.Statuses permite revisar las
+// aprobadas u ocultas). Requiere permiso de moderación.
+func (s *ReviewService) Queue(ctx context.Context, f domain.ReviewFilter) (domain.Page[domain.ReviewDetail], error) {
+	if _, err := authorize(ctx, domain.PermReviewsModerate); err != nil {
+		return domain.Page[domain.ReviewDetail]{}, err
+	}
+	if len(f.Statuses) == 0 {
+		f.Statuses = []domain.ReviewStatus{domain.ReviewPending}
+	}
+	if f.Sort == "" {
+		f.Sort, f.Dir = "updated_at", domain.SortAsc
+	}
+	return s.repo.List(ctx, f)
+}
+
+// Moderate aprueba u oculta una reseña y actualiza el promedio del libro.
+func (s *ReviewService) Moderate(ctx context.Context, id uint64, status domain.ReviewStatus) (*domain.Review, error) {
+	if _, err := authorize(ctx, domain.PermReviewsModerate); err != nil {
+		return nil, err
+	}
+	rv, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	wasApproved := rv.Approved()
+	if err := rv.Moderate(status); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, rv, nil); err != nil {
+		return nil, err
+	}
+	if wasApproved != rv.Approved() {
+		s.refreshRating(ctx, rv.BookID())
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// Summary resume las reseñas aprobadas del libro (promedio y cuántas hay
+// de cada cantidad de estrellas).
+func (s *ReviewService) Summary(ctx context.Context, bookID uint64) (domain.RatingSummary, error) {
+	if _, err := authorize(ctx, domain.PermBooksRead); err != nil {
+		return domain.RatingSummary{}, err
+	}
+	if _, err := s.books.GetByID(ctx, bookID); err != nil {
+		return domain.RatingSummary{}, err
+	}
+	sums, err := s.repo.Summaries(ctx, []uint64{bookID})
+	if err != nil {
+		return domain.RatingSummary{}, err
+	}
+	sum := sums[bookID]
+	sum.BookID = bookID
+	return sum, nil
+}
+
+// Summaries resume las reseñas aprobadas de varios libros con una sola
+// consulta (listados). Los libros sin reseñas aprobadas no aparecen.
+func (s *ReviewService) Summaries(ctx context.Context, bookIDs []uint64) (map[uint64]domain.RatingSummary, error) {
+	if _, err := authorize(ctx, domain.PermBooksRead); err != nil {
+		return nil, err
+	}
+	if len(bookIDs) == 0 {
+		return map[uint64]domain.RatingSummary{}, nil
+	}
+	return s.repo.Summaries(ctx, bookIDs)
+}
+
+// refreshRating recalcula el promedio del libro en el índice (si hay).
+// Si falla, el índice queda con el promedio anterior hasta el próximo cambio.
+func (s *ReviewService) refreshRating(ctx context.Context, bookID uint64) {
+	if s.index == nil {
+		return
+	}
+	if sums, err := s.repo.Summaries(ctx, []uint64{bookID}); err == nil {
+		s.index.SetRating(bookID, sums[bookID].Average())
+	}
+}
+
+// moderator indica si el usuario actual puede moderar reseñas.
+func (s *ReviewService) moderator(ctx context.Context) bool {
+	_, err := authorize(ctx, domain.PermReviewsModerate)
+	return err == nil
+}
+
+// visible indica si u puede ver la reseña: las aprobadas cualquiera, la
+// propia siempre y las demás solo quien modera.
+func (s *ReviewService) visible(ctx context.Context, u *domain.User, rv *domain.Review) bool {
+	return rv.Approved() || rv.UserID() == u.ID() || s.moderator(ctx)
+}
@@ -0,0 +1,126 @@
+package usecase
+
+import (
+    "errors"
+    "testing"
+
+    "github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+    "github.com/jfmg0509/sistema_libros_funcional_go/internal/infrastructure/memory"
+    "github.com/jfmg0509/sistema_libros_funcional_go/internal/infrastructure/search"
+)
+
+func TestReviewServiceModeration(t *testing.T) {
+    s := newMemStore()
+    users, books, reviews := memory.NewUserRepo(s), memory.NewBookRepo(s), memory.NewReviewRepo(s)
+    bookSvc := NewBookService(books, users, newMemAccessRepo(), nil)
+    svc := NewReviewService(reviews, books)
+
+    adminCtx, _ := actorCtx(users, domain.RoleAdmin)
+    readerCtx, _ := actorCtx(users, domain.RoleReader)
+    otherCtx, _ := actorCtx(users, domain.RoleReader)
+
+    uno, err := bookSvc.Create(adminCtx, "Uno", "Ana", 2001, "9780306400070", "Novela", nil, "")
+    if err != nil { t.Fatalf("create book: %v", err) }
+
+    if _, err := svc.Create(readerCtx, uno.ID(), 6, ""); !errors.Is(err, domain.ErrValidation) { t.Fatalf("expected invalid rating, got %v", err) }
+    rv, err := svc.Create(readerCtx, uno.ID(), 4, "  Muy bueno ")
+    if err != nil || rv.Status() != domain.ReviewPending || rv.Text() != "Muy bueno" { t.Fatalf("unexpected review %+v, %v", rv, err) }
+    if _, err := svc.Create(readerCtx, uno.ID(), 5, ""); !errors.Is(err, domain.ErrDuplicate) { t.Fatalf("expected one review per book, got %v", err) }
+
+    // Pendiente: la ve su autor y quien modera, no los demás ni el promedio
+    if _, err := svc.Get(otherCtx, rv.ID()); !errors.Is(err, domain.ErrNotFound) { t.Fatalf("expected pending hidden, got %v", err) }
+    if _, err := svc.Queue(readerCtx, domain.ReviewFilter{}); !errors.Is(err, domain.ErrForbidden) { t.Fatalf("expected forbidden queue, got %v", err) }
+    if _, err := svc.Moderate(readerCtx, rv.ID(), domain.ReviewApproved); !errors.Is(err, domain.ErrForbidden) { t.Fatalf("expected forbidden moderation, got %v", err) }
+    queue, err := svc.Queue(adminCtx, domain.ReviewFilter{})
+    if err != nil || queue.Total != 1 || queue.Items[0].Review.ID() != rv.ID() { t.Fatalf("unexpected queue %+v, %v", queue, err) }
+    if sum, _ := svc.Summary(readerCtx, uno.ID()); sum.Count != 0 { t.Fatalf("pending review counted: %+v", sum) }
+
+    if _, err := svc.Moderate(adminCtx, rv.ID(), domain.ReviewPending); !errors.Is(err, domain.ErrValidation) { t.Fatalf("expected invalid decision, got %v", err) }
+    approved, err := svc.Moderate(adminCtx, rv.ID(), domain.ReviewApproved)
+    if err != nil || !approved.Approved() || approved.ModeratedAt().IsZero() { t.Fatalf("unexpected approval %+v, %v", approved, err) }
+    other, err := svc.Create(otherCtx, uno.ID(), 2, "")
+    if err != nil { t.Fatalf("create other: %v", err) }
+    if _, err := svc.Moderate(adminCtx, other.ID(), domain.ReviewApproved); err != nil { t.Fatalf("approve other: %v", err) }
+
+    sum, err := svc.Summary(otherCtx, uno.ID())
+    if err != nil || sum.Count != 2 || sum.Average() != 3 || sum.Distribution != [5]int{0, 1, 0, 1, 0} { t.Fatalf("unexpected summary %+v, %v", sum, err) }
+    list, err := svc.ByBook(otherCtx, uno.ID(), domain.ReviewFilter{Rating: 4})
+    if err != nil || list.Total != 1 || list.Items[0].UserName != "Actor" { t.Fatalf("unexpected book reviews %+v, %v", list, err) }
+
+    // Solo el autor edita: la versión anterior queda en el historial y vuelve a moderación
+    text := "Mejor de lo que pensaba"
+    if _, err := svc.Update(otherCtx, rv.ID(), UpdateReviewInput{Text: &text}); !errors.Is(err, domain.ErrForbidden) { t.Fatalf("expected forbidden edit, got %v", err) }
+    five := 5
+    upd, err := svc.Update(readerCtx, rv.ID(), UpdateReviewInput{Rating: &five, Text: &text})
+    if err != nil || upd.Rating() != 5 || upd.Status() != domain.ReviewPending { t.Fatalf("unexpected edit %+v, %v", upd, err) }
+    hist, err := svc.History(readerCtx, rv.ID())
+    if err != nil || len(hist) != 1 || hist[0].Rating != 4 || hist[0].Text != "Muy bueno" { t.Fatalf("unexpected history %+v, %v", hist, err) }
+    if _, err := svc.History(otherCtx, rv.ID()); !errors.Is(err, domain.ErrNotFound) { t.Fatalf("expected pending history hidden, got %v", err) }
+    if same, err := svc.Update(readerCtx, rv.ID(), UpdateReviewInput{Text: &text}); err != nil || same.Status() != domain.ReviewPending { t.Fatalf("no-op edit: %+v, %v", same, err) }
+    if hist, _ := svc.History(adminCtx, rv.ID()); len(hist) != 1 { t.Fatalf("no-op edit added history: %+v", hist) }
+    if sum, _ := svc.Summary(readerCtx, uno.ID()); sum.Count != 1 { t.Fatalf("edited review still counted: %+v", sum) }
+
+    // Borrar: el autor o quien modera
+    if err := svc.Delete(readerCtx, other.ID()); !errors.Is(err, domain.ErrForbidden) { t.Fatalf("expected forbidden delete, got %v", err) }
+    if err := svc.Delete(adminCtx, other.ID()); err != nil { t.Fatalf("moderator delete: %v", err) }
+    if err := svc.Delete(readerCtx, rv.ID()); err != nil { t.Fatalf("author delete: %v", err) }
+}
+
+func TestBookSearchByRating(t *testing.T) {
+    s := newMemStore()
+    users, books, reviews := memory.NewUserRepo(s), memory.NewBookRepo(s), memory.NewReviewRepo(s)
+    bookSvc := NewBookService(books, users, newMemAccessRepo(), nil)
+    svc := NewReviewService(reviews, books)
+    adminCtx, _ := actorCtx(users, domain.RoleAdmin)
+
+    rate := func(bookID uint64, ratings ...int) {
+        t.Helper()
+        for _, n := range ratings {
+            ctx, _ := actorCtx(users, domain.RoleReader)
+            rv, err := svc.Create(ctx, bookID, n, "")
+            if err != nil { t.Fatalf("review: %v", err) }
+            if _, err := svc.Moderate(adminCtx, rv.ID(), domain.ReviewApproved); err != nil { t.Fatalf("approve: %v", err) }
+        }
+    }
+    uno, _ := bookSvc.Create(adminCtx, "Novela uno", "Ana", 2001, "9780306400070", "Novela", nil, "")
+    dos, _ := bookSvc.Create(adminCtx, "Novela dos", "Ana", 2002, "9780306400148", "Novela", nil, "")
+    tres, _ := bookSvc.Create(adminCtx, "Novela tres", "Ana", 2003, "9780306400216", "Novela", nil, "")
+    rate(uno.ID(), 3, 4)
+    rate(dos.ID(), 5)
+    pending, _ := actorCtx(users, domain.RoleReader)
+    if _, err := svc.Create(pending, tres.ID(), 5, ""); err != nil { t.Fatalf("pending review: %v", err) }
+
+    check := func(name string, f domain.BookFilter, want ...uint64) {
+        t.Helper()
+        res, err := bookSvc.Search(adminCtx, f)
+        if err != nil { t.Fatalf("%s: %v", name, err) }
+        got := []uint64{}
+        for _, h := range res.Hits.Items { got = append(got, h.Book.ID()) }
+        if len(got) != len(want) { t.Fatalf("%s: got %v, want %v", name, got, want) }
+        for i := range want {
+            if got[i] != want[i] { t.Fatalf("%s: got %v, want %v", name, got, want) }
+        }
+    }
+
+    // Sin índice (repositorio) y con índice (texto libre): mismo resultado
+    check("repo sort", domain.BookFilter{PageRequest: domain.PageRequest{Sort: domain.SortRating}}, dos.ID(), uno.ID(), tres.ID())
+    check("repo filter", domain.BookFilter{MinRating: 3.5}, dos.ID(), uno.ID())
+
+    idx := search.NewIndex()
+    all, _ := books.List(adminCtx)
+    idx.Rebuild(all)
+    sums, _ := reviews.Summaries(adminCtx, []uint64{uno.ID(), dos.ID(), tres.ID()})
+    for id, s := range sums { idx.SetRating(id, s.Average()) }
+    bookSvc.SetSearcher(idx)
+    svc.SetIndex(idx)
+    check("index sort", domain.BookFilter{Q: "novela", PageRequest: domain.PageRequest{Sort: domain.SortRating}}, dos.ID(), uno.ID(), tres.ID())
+    check("index filter", domain.BookFilter{Q: "novela", MinRating: 4}, dos.ID())
+
+    // Aprobar una reseña actualiza el índice
+    rate(uno.ID(), 5, 5)
+    check("index after approval", domain.BookFilter{Q: "novela", MinRating: 4}, dos.ID(), uno.ID())
+
+    if _, err := bookSvc.Search(adminCtx, domain.BookFilter{MinRating: 6}); !errors.Is(err, domain.ErrValidation) {
+        t.Fatalf("expected invalid min_rating, got %v", err)
+    }
+}
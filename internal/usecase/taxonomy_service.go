@@ -0,0 +1,235 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+// TaxonomyService administra etiquetas y el árbol de categorías. Renombrar,
+// fusionar o borrar cambia lo que ven los libros, así que los afectados se
+// reindexan en el buscador.
+type TaxonomyService struct {
+	repo   TaxonomyRepo
+	books  BookRepo
+	search BookSearcher // opcional: se reindexan los libros afectados
+}
+
+func NewTaxonomyService(repo TaxonomyRepo, bookRepo BookRepo) *TaxonomyService {
+	return &TaxonomyService{repo: repo, books: bookRepo}
+}
+
+// SetSearcher comparte el índice de búsqueda de BookService.
+func (s *TaxonomyService) SetSearcher(idx BookSearcher) { s.search = idx }
+
+// -------------------- Etiquetas --------------------
+
+// Tags devuelve una página de etiquetas con la cantidad de libros de cada una.
+func (s *TaxonomyService) Tags(ctx context.Context, f domain.TagFilter) (domain.Page[domain.TagUsage], error) {
+	if _, err := authorize(ctx, domain.PermBooksRead); err != nil {
+		return domain.Page[domain.TagUsage]{}, err
+	}
+	return s.repo.ListTags(ctx, f)
+}
+
+// RenameTag cambia el nombre de la etiqueta en todos sus libros. Si el nuevo
+// nombre ya es otra etiqueta (mismo slug) responde ErrDuplicate: hay que fusionarlas.
+func (s *TaxonomyService) RenameTag(ctx context.Context, id uint64, name string) (*domain.Tag, error) {
+	if _, err := authorize(ctx, domain.PermBooksWrite); err != nil {
+		return nil, err
+	}
+	t, err := s.repo.GetTag(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.SetName(name); err != nil {
+		return nil, err
+	}
+	affected, err := s.repo.RenameTag(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.reindex(ctx, affected); err != nil {
+		return nil, err
+	}
+	return s.repo.GetTag(ctx, id)
+}
+
+// MergeTags fusiona la etiqueta dupID en keepID: sus libros pasan a keepID y
+// dupID se elimina.
+func (s *TaxonomyService) MergeTags(ctx context.Context, keepID, dupID uint64) (*domain.Tag, error) {
+	if _, err := authorize(ctx, domain.PermBooksWrite); err != nil {
+		return nil, err
+	}
+	if dupID == 0 || keepID == dupID {
+		return nil, fmt.Errorf("%w: duplicate_id must be another tag", domain.ErrValidation)
+	}
+	affected, err := s.repo.MergeTags(ctx, keepID, dupID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.reindex(ctx, affected); err != nil {
+		return nil, err
+	}
+	return s.repo.GetTag(ctx, keepID)
+}
+
+// DeleteTag elimina la etiqueta y la quita de todos sus libros.
+func (s *TaxonomyService) DeleteTag(ctx context.Context, id uint64) error {
+	if _, err := authorize(ctx, domain.PermBooksWrite); err != nil {
+		return err
+	}
+	affected, err := s.repo.DeleteTag(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.reindex(ctx, affected)
+}
+
+// -------------------- Categorías --------------------
+
+// CategoryTree devuelve el árbol de categorías con libros propios y totales
+// (incluidas las subcategorías).
+func (s *TaxonomyService) CategoryTree(ctx context.Context) ([]*domain.CategoryNode, error) {
+	if _, err := authorize(ctx, domain.PermBooksRead); err != nil {
+		return nil, err
+	}
+	list, err := s.repo.Categories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return domain.BuildCategoryTree(list), nil
+}
+
+// Category devuelve una categoría por ID.
+func (s *TaxonomyService) Category(ctx context.Context, id uint64) (*domain.Category, error) {
+	if _, err := authorize(ctx, domain.PermBooksRead); err != nil {
+		return nil, err
+	}
+	return s.repo.GetCategory(ctx, id)
+}
+
+// CreateCategory crea una categoría bajo parentID (0 = raíz).
+func (s *TaxonomyService) CreateCategory(ctx context.Context, name string, parentID uint64) (*domain.Category, error) {
+	if _, err := authorize(ctx, domain.PermBooksWrite); err != nil {
+		return nil, err
+	}
+	c, err := domain.NewCategory(name, parentID)
+	if err != nil {
+		return nil, err
+	}
+	if parentID != 0 {
+		if _, err := s.repo.GetCategory(ctx, parentID); err != nil {
+			return nil, fmt.Errorf("parent category %d: %w", parentID, err)
+		}
+	}
+	id, err := s.repo.CreateCategory(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetCategory(ctx, id)
+}
+
+// UpdateCategory renombra y/o mueve la categoría (sin crear ciclos). El nuevo
+// nombre se aplica a sus libros.
+func (s *TaxonomyService) UpdateCategory(ctx context.Context, id uint64, in UpdateCategoryInput) (*domain.Category, error) {
+	if _, err := authorize(ctx, domain.PermBooksWrite); err != nil {
+		return nil, err
+	}
+	c, err := s.repo.GetCategory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Name != nil {
+		if err := c.SetName(*in.Name); err != nil {
+			return nil, err
+		}
+	}
+	if in.ParentID != nil {
+		list, err := s.repo.Categories(ctx)
+		if err != nil {
+			return nil, err
+		}
+		all := make([]*domain.Category, 0, len(list))
+		for _, u := range list {
+			all = append(all, u.Category)
+		}
+		if err := domain.CheckCategoryParent(all, id, *in.ParentID); err != nil {
+			return nil, err
+		}
+		if err := c.SetParent(*in.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	affected, err := s.repo.UpdateCategory(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.reindex(ctx, affected); err != nil {
+		return nil, err
+	}
+	return s.repo.GetCategory(ctx, id)
+}
+
+// MergeCategories fusiona dupID en keepID: sus libros y subcategorías pasan a
+// keepID y dupID se elimina.
+func (s *TaxonomyService) MergeCategories(ctx context.Context, keepID, dupID uint64) (*domain.Category, error) {
+	if _, err := authorize(ctx, domain.PermBooksWrite); err != nil {
+		return nil, err
+	}
+	if dupID == 0 || keepID == dupID {
+		return nil, fmt.Errorf("%w: duplicate_id must be another category", domain.ErrValidation)
+	}
+	affected, err := s.repo.MergeCategories(ctx, keepID, dupID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.reindex(ctx, affected); err != nil {
+		return nil, err
+	}
+	return s.repo.GetCategory(ctx, keepID)
+}
+
+// DeleteCategory elimina una categoría vacía. Con libros o subcategorías se
+// rechaza: primero hay que fusionarla con otra (MergeCategories) o vaciarla.
+func (s *TaxonomyService) DeleteCategory(ctx context.Context, id uint64) error {
+	if _, err := authorize(ctx, domain.PermBooksWrite); err != nil {
+		return err
+	}
+	list, err := s.repo.Categories(ctx)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, u := range list {
+		switch {
+		case u.Category.ID() == id && u.Books > 0:
+			return fmt.Errorf("%w: category has %d book(s); merge it into another category first", domain.ErrValidation, u.Books)
+		case u.Category.ParentID() == id:
+			return fmt.Errorf("%w: category has subcategories; move or merge them first", domain.ErrValidation)
+		}
+		found = found || u.Category.ID() == id
+	}
+	if !found {
+		return domain.ErrNotFound
+	}
+	return s.repo.DeleteCategory(ctx, id)
+}
+
+// reindex actualiza en el buscador los libros cuya categoría o etiquetas cambiaron.
+func (s *TaxonomyService) reindex(ctx context.Context, bookIDs []uint64) error {
+	if s.search == nil {
+		return nil
+	}
+	for _, id := range bookIDs {
+		b, err := s.books.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		s.search.Index(b)
+	}
+	return nil
+}
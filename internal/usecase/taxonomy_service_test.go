@@ -0,0 +1,87 @@
+package usecase
+
+import (
+    "context"
+    "errors"
+    "testing"
+
+    "github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+    "github.com/jfmg0509/sistema_libros_funcional_go/internal/infrastructure/memory"
+    "github.com/jfmg0509/sistema_libros_funcional_go/internal/infrastructure/search"
+)
+
+func TestTaxonomyServiceMergeTagsReindexes(t *testing.T) {
+    users := newMemUserRepo()
+    s := newMemStore()
+    books, taxonomy := memory.NewBookRepo(s), memory.NewTaxonomyRepo(s)
+    idx := search.NewIndex()
+    bookSvc := NewBookService(books, users, newMemAccessRepo(), nil)
+    bookSvc.SetSearcher(idx)
+    svc := NewTaxonomyService(taxonomy, books)
+    svc.SetSearcher(idx)
+
+    adminCtx, _ := actorCtx(users, domain.RoleAdmin)
+    readerCtx, _ := actorCtx(users, domain.RoleReader)
+
+    if _, err := bookSvc.Create(adminCtx, "Uno", "Ana", 2001, "9780306400070", "Novela", []string{"SciFi"}, ""); err != nil { t.Fatalf("create: %v", err) }
+    if _, err := bookSvc.Create(adminCtx, "Dos", "Eva", 2002, "9780306400148", "Novela", []string{"Ciencia Ficción"}, ""); err != nil { t.Fatalf("create: %v", err) }
+
+    page, err := svc.Tags(readerCtx, domain.TagFilter{PageRequest: domain.PageRequest{Sort: "name"}})
+    if err != nil || page.Total != 2 { t.Fatalf("expected 2 tags, got %+v (%v)", page, err) }
+    keep, dup := page.Items[0].Tag, page.Items[1].Tag
+
+    if _, err := svc.MergeTags(readerCtx, keep.ID(), dup.ID()); !errors.Is(err, domain.ErrForbidden) {
+        t.Fatalf("expected forbidden merge for reader, got %v", err)
+    }
+    if _, err := svc.MergeTags(adminCtx, keep.ID(), keep.ID()); !errors.Is(err, domain.ErrValidation) {
+        t.Fatalf("expected validation error merging a tag into itself, got %v", err)
+    }
+    if _, err := svc.RenameTag(adminCtx, dup.ID(), "ciencia ficcion"); !errors.Is(err, domain.ErrDuplicate) {
+        t.Fatalf("expected duplicate renaming onto another tag's slug, got %v", err)
+    }
+
+    if _, err := svc.MergeTags(adminCtx, keep.ID(), dup.ID()); err != nil { t.Fatalf("merge: %v", err) }
+
+    // El índice ve la etiqueta fusionada en ambos libros
+    res, _ := idx.Search(context.Background(), domain.BookFilter{Tag: "ciencia-ficcion"})
+    if res.Hits.Total != 2 {
+        t.Fatalf("expected 2 books tagged after merge, got %d", res.Hits.Total)
+    }
+}
+
+func TestTaxonomyServiceCategoryTree(t *testing.T) {
+    users := newMemUserRepo()
+    s := newMemStore()
+    books, taxonomy := memory.NewBookRepo(s), memory.NewTaxonomyRepo(s)
+    bookSvc := NewBookService(books, users, newMemAccessRepo(), nil)
+    svc := NewTaxonomyService(taxonomy, books)
+    adminCtx, _ := actorCtx(users, domain.RoleAdmin)
+
+    fic, err := svc.CreateCategory(adminCtx, "Ficción", 0)
+    if err != nil { t.Fatalf("create root: %v", err) }
+    sf, err := svc.CreateCategory(adminCtx, "Ciencia ficción", fic.ID())
+    if err != nil { t.Fatalf("create child: %v", err) }
+    if _, err := svc.CreateCategory(adminCtx, "Huérfana", 999); !errors.Is(err, domain.ErrNotFound) {
+        t.Fatalf("expected missing parent to be rejected, got %v", err)
+    }
+    if _, err := bookSvc.Create(adminCtx, "Uno", "Ana", 2001, "9780306400070", "ciencia ficcion", nil, ""); err != nil { t.Fatalf("create book: %v", err) }
+
+    // Un padre no puede quedar debajo de su hija
+    under := sf.ID()
+    if _, err := svc.UpdateCategory(adminCtx, fic.ID(), UpdateCategoryInput{ParentID: &under}); !errors.Is(err, domain.ErrValidation) {
+        t.Fatalf("expected cycle to be rejected, got %v", err)
+    }
+    // Con libros o subcategorías no se borra
+    if err := svc.DeleteCategory(adminCtx, sf.ID()); !errors.Is(err, domain.ErrValidation) {
+        t.Fatalf("expected delete of category with books to be refused, got %v", err)
+    }
+    if err := svc.DeleteCategory(adminCtx, fic.ID()); !errors.Is(err, domain.ErrValidation) {
+        t.Fatalf("expected delete of category with children to be refused, got %v", err)
+    }
+
+    tree, err := svc.CategoryTree(adminCtx)
+    if err != nil { t.Fatalf("tree: %v", err) }
+    if len(tree) != 1 || tree[0].TotalBooks != 1 || len(tree[0].Children) != 1 || tree[0].Children[0].Books != 1 {
+        t.Fatalf("unexpected tree %+v", tree)
+    }
+}
@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+// apiTokenPrefix identifica visualmente los tokens del sistema ("slk_...").
+const apiTokenPrefix = "slk_"
+
+// TokenService permite a cada usuario emitir, listar y revocar tokens de API.
+type TokenService struct {
+	tokens APITokenRepo
+	users  UserRepo
+}
+
+func NewTokenService(tokenRepo APITokenRepo, userRepo UserRepo) *TokenService {
+	return &TokenService{tokens: tokenRepo, users: userRepo}
+}
+
+// Create emite un token para el usuario de la sesión actual.
+// Los scopes no pueden exceder los permisos del rol del usuario.
+// Devuelve el token en claro: es la única vez que se puede ver.
+func (s *TokenService) Create(ctx context.Context, name string, scopes []domain.Permission, expiresAt time.Time) (string, *domain.APIToken, error) {
+	u, err := requireSession(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, p := range scopes {
+		p = domain.Permission(strings.ToLower(strings.TrimSpace(string(p))))
+		if p.IsValid() && !u.Can(p) {
+			return "", nil, fmt.Errorf("%w: role %s cannot grant scope %s", domain.ErrForbidden, u.Role(), p)
+		}
+	}
+
+	raw, err := newToken()
+	if err != nil {
+		return "", nil, err
+	}
+	token := apiTokenPrefix + raw
+
+	t, err := domain.NewAPIToken(u.ID(), name, HashToken(token), token[:len(apiTokenPrefix)+6], scopes, expiresAt)
+	if err != nil {
+		return "", nil, err
+	}
+
+	id, err := s.tokens.Create(ctx, t)
+	if err != nil {
+		return "", nil, err
+	}
+	saved, err := s.tokens.GetByID(ctx, id)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, saved, nil
+}
+
+// List devuelve los tokens del usuario de la sesión actual.
+func (s *TokenService) List(ctx context.Context) ([]*domain.APIToken, error) {
+	u, err := requireSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.tokens.ListByUser(ctx, u.ID())
+}
+
+// Revoke invalida un token propio (o de cualquiera, con users:write).
+func (s *TokenService) Revoke(ctx context.Context, id uint64) error {
+	u, err := requireSession(ctx)
+	if err != nil {
+		return err
+	}
+
+	t, err := s.tokens.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if t.UserID() != u.ID() && !u.Can(domain.PermUsersWrite) {
+		// no se revela la existencia de tokens ajenos
+		return domain.ErrNotFound
+	}
+	if t.Revoked() {
+		return nil
+	}
+	return s.tokens.Revoke(ctx, id, time.Now())
+}
+
+// Authenticate resuelve el dueño de un token "Bearer".
+// Registra la fecha de último uso del token.
+func (s *TokenService) Authenticate(ctx context.Context, token string) (*domain.User, *domain.APIToken, error) {
+	if !strings.HasPrefix(token, apiTokenPrefix) {
+		return nil, nil, fmt.Errorf("%w: malformed token", domain.ErrUnauthorized)
+	}
+
+	t, err := s.tokens.GetByHash(ctx, HashToken(token))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, nil, fmt.Errorf("%w: unknown token", domain.ErrUnauthorized)
+		}
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	if !t.Usable(now) {
+		return nil, nil, fmt.Errorf("%w: token revoked or expired", domain.ErrUnauthorized)
+	}
+
+	u, err := s.users.GetByID(ctx, t.UserID())
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, nil, domain.ErrUnauthorized
+		}
+		return nil, nil, err
+	}
+	if !u.Active() {
+		return nil, nil, domain.ErrInactiveEntity
+	}
+
+	// best-effort: un fallo al registrar el uso no bloquea la petición
+	_ = s.tokens.TouchLastUsed(ctx, t.ID(), now)
+
+	return u, t, nil
+}
@@ -0,0 +1,63 @@
+package usecase
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+
+    "github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
+)
+
+func TestTokenLifecycle(t *testing.T) {
+    users := newMemUserRepo()
+    tokens := newMemAPITokenRepo()
+    svc := NewTokenService(tokens, users)
+
+    adminCtx, admin := actorCtx(users, domain.RoleAdmin)
+
+    secret, tok, err := svc.Create(adminCtx, "carga catálogo", []domain.Permission{domain.PermBooksWrite}, time.Time{})
+    if err != nil { t.Fatalf("create: %v", err) }
+    if tok.TokenHash() == secret || tok.TokenHash() != HashToken(secret) { t.Fatalf("token must be stored hashed") }
+
+    u, got, err := svc.Authenticate(context.Background(), secret)
+    if err != nil { t.Fatalf("authenticate: %v", err) }
+    if u.ID() != admin.ID() { t.Fatalf("unexpected owner") }
+    if stored, _ := tokens.GetByID(context.Background(), got.ID()); stored.LastUsedAt().IsZero() {
+        t.Fatalf("expected last used timestamp")
+    }
+
+    // el token limita los permisos del ADMIN a sus scopes
+    tokenCtx := WithAPIToken(WithUser(context.Background(), u), got)
+    books := NewBookService(newMemBookRepo(), users, newMemAccessRepo(), nil)
+    if _, err := books.Create(tokenCtx, "Go POO", "Autor", 2024, "978-0-306-40615-7", "Programación", nil, ""); err != nil {
+        t.Fatalf("create with books:write scope: %v", err)
+    }
+    if _, err := NewUserService(users).List(tokenCtx); !errors.Is(err, domain.ErrForbidden) {
+        t.Fatalf("expected forbidden outside scopes, got %v", err)
+    }
+    if _, _, err := svc.Create(tokenCtx, "otro", []domain.Permission{domain.PermBooksRead}, time.Time{}); !errors.Is(err, domain.ErrForbidden) {
+        t.Fatalf("tokens must not mint tokens, got %v", err)
+    }
+
+    if err := svc.Revoke(adminCtx, got.ID()); err != nil { t.Fatalf("revoke: %v", err) }
+    if _, _, err := svc.Authenticate(context.Background(), secret); !errors.Is(err, domain.ErrUnauthorized) {
+        t.Fatalf("expected unauthorized after revoke, got %v", err)
+    }
+}
+
+func TestTokenScopesLimitedByRole(t *testing.T) {
+    users := newMemUserRepo()
+    svc := NewTokenService(newMemAPITokenRepo(), users)
+
+    readerCtx, _ := actorCtx(users, domain.RoleReader)
+    if _, _, err := svc.Create(readerCtx, "script", []domain.Permission{domain.PermBooksWrite}, time.Time{}); !errors.Is(err, domain.ErrForbidden) {
+        t.Fatalf("expected forbidden scope for reader, got %v", err)
+    }
+    if _, _, err := svc.Create(readerCtx, "script", []domain.Permission{"books:fly"}, time.Time{}); !errors.Is(err, domain.ErrValidation) {
+        t.Fatalf("expected validation error for unknown scope, got %v", err)
+    }
+    if _, _, err := svc.Create(readerCtx, "script", []domain.Permission{domain.PermBooksRead}, time.Now().Add(-time.Hour)); !errors.Is(err, domain.ErrValidation) {
+        t.Fatalf("expected validation error for past expiry, got %v", err)
+    }
+}
@@ -2,7 +2,9 @@ package usecase // Capa de casos de uso (lógica de negocio)
 
 import (
 	"context" // Permite cancelación y timeouts desde handlers
+	"errors"  // Para distinguir ErrNotFound de otros fallos
 	"fmt"     // Para envolver errores con contexto
+	"strings" // Para normalizar el rol recibido
 	"time"    // Para simular trabajo / mostrar timeouts
 
 	"github.com/jfmg0509/sistema_libros_funcional_go/internal/domain" // Dominio: User, errores, interfaces
@@ -11,7 +13,8 @@ import (
 // UserService contiene la lógica de negocio relacionada con usuarios.
 // No depende de MySQL directo: depende de la interfaz domain.UserRepository (POO + SOLID).
 type UserService struct {
-	repo domain.UserRepository // Repositorio (interfaz) para persistencia de usuarios
+	repo  domain.UserRepository // Repositorio (interfaz) para persistencia de usuarios
+	loans CirculationRepo       // opcional: impide borrar usuarios con préstamos activos
 }
 
 // NewUserService es el "constructor" del servicio.
@@ -20,11 +23,26 @@ func NewUserService(repo domain.UserRepository) *UserService {
 	return &UserService{repo: repo} // Retorna servicio listo para usar
 }
 
+// SetCirculationRepo activa la regla de circulación: un usuario con
+// ejemplares sin devolver no se puede eliminar.
+func (s *UserService) SetCirculationRepo(r CirculationRepo) { s.loans = r }
+
 // Create crea un usuario aplicando reglas de negocio:
 // - Validación mediante constructor del dominio
+// - Contraseña obligatoria (se guarda solo su hash)
 // - Regla: email único
 // - Persistencia mediante repo
-func (s *UserService) Create(ctx context.Context, name, email string, role domain.Role) (*domain.User, error) {
+// Requiere permiso users:write (solo ADMIN).
+func (s *UserService) Create(ctx context.Context, name, email string, role domain.Role, password string) (*domain.User, error) {
+	if _, err := authorize(ctx, domain.PermUsersWrite); err != nil {
+		return nil, err
+	}
+	return s.create(ctx, name, email, role, password)
+}
+
+// create contiene la lógica de alta sin verificar permisos.
+// Solo se usa desde Create (ya autorizado) y EnsureAdmin (arranque).
+func (s *UserService) create(ctx context.Context, name, email string, role domain.Role, password string) (*domain.User, error) {
 
 	// Crea entidad User usando un constructor del dominio (valida campos).
 	u, err := domain.NewUser(name, email, role)
@@ -32,6 +50,11 @@ func (s *UserService) Create(ctx context.Context, name, email string, role domai
 		return nil, err // Si falla validación, se retorna error del dominio
 	}
 
+	// La contraseña se valida y se transforma en hash dentro del dominio.
+	if err := u.SetPassword(password); err != nil {
+		return nil, err
+	}
+
 	// Regla del negocio: el email debe ser único.
 	// Si encontramos un usuario con el mismo email, retornamos ErrDuplicate.
 	if _, err := s.repo.GetByEmail(ctx, u.Email()); err == nil {
@@ -49,14 +72,34 @@ func (s *UserService) Create(ctx context.Context, name, email string, role domai
 }
 
 // List devuelve todos los usuarios.
-// No hay reglas extra aquí: delega al repositorio.
+// Requiere permiso users:read.
 func (s *UserService) List(ctx context.Context) ([]*domain.User, error) {
+	if _, err := authorize(ctx, domain.PermUsersRead); err != nil {
+		return nil, err
+	}
 	return s.repo.List(ctx)
 }
 
+// Search devuelve una página de usuarios que cumplen el filtro (rol, estado, email)
+// y el total de coincidencias. Requiere permiso users:read.
+func (s *UserService) Search(ctx context.Context, f domain.UserFilter) (domain.Page[*domain.User], error) {
+	if _, err := authorize(ctx, domain.PermUsersRead); err != nil {
+		return domain.Page[*domain.User]{}, err
+	}
+
+	// Un rol inválido en el filtro es un error del cliente, no "cero resultados".
+	if f.Role != "" && !f.Role.IsValid() {
+		return domain.Page[*domain.User]{}, fmt.Errorf("%w: %s", domain.ErrInvalidRole, f.Role)
+	}
+	return s.repo.Search(ctx, f)
+}
+
 // Get devuelve un usuario por ID.
-// Delegación directa al repositorio.
+// Cada usuario puede verse a sí mismo; ver a otros requiere users:read.
 func (s *UserService) Get(ctx context.Context, id uint64) (*domain.User, error) {
+	if _, err := authorizeSelfOr(ctx, id, domain.PermUsersRead); err != nil {
+		return nil, err
+	}
 	return s.repo.GetByID(ctx, id)
 }
 
@@ -70,6 +113,23 @@ func (s *UserService) Update(
 	active *bool, // puntero para permitir "opcional": nil => no cambiar
 ) (*domain.User, error) {
 
+	// Solo ADMIN puede editar usuarios (incluye rol y estado).
+	actor, err := authorize(ctx, domain.PermUsersWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	// Regla: un administrador no puede quitarse a sí mismo el acceso
+	// (cambiando su rol o desactivándose) para evitar quedar sin ADMIN.
+	if actor.ID() == id {
+		if role != "" && domain.Role(strings.ToUpper(string(role))) != actor.Role() {
+			return nil, fmt.Errorf("%w: cannot change your own role", domain.ErrForbidden)
+		}
+		if active != nil && !*active {
+			return nil, fmt.Errorf("%w: cannot deactivate yourself", domain.ErrForbidden)
+		}
+	}
+
 	// Obtiene el usuario actual desde BD.
 	u, err := s.repo.GetByID(ctx, id)
 	if err != nil {
@@ -123,9 +183,92 @@ func (s *UserService) Update(
 	return s.repo.GetByID(ctx, id)
 }
 
-// Delete elimina un usuario por ID.
+// SetActive activa o desactiva un usuario (requiere users:write).
+// Es un atajo sobre Update para los endpoints activate/deactivate.
+func (s *UserService) SetActive(ctx context.Context, id uint64, active bool) (*domain.User, error) {
+	return s.Update(ctx, id, "", "", "", &active)
+}
+
+// ChangePassword reemplaza la contraseña de un usuario.
+// Cada usuario puede cambiar la suya; cambiar la de otros requiere users:write.
+func (s *UserService) ChangePassword(ctx context.Context, id uint64, password string) error {
+	if _, err := authorizeSelfOr(ctx, id, domain.PermUsersWrite); err != nil {
+		return err
+	}
+
+	// Obtiene el usuario actual desde BD.
+	u, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// El dominio valida longitud y genera el hash.
+	if err := u.SetPassword(password); err != nil {
+		return err
+	}
+
+	return s.repo.Update(ctx, u)
+}
+
+// EnsureAdmin crea un administrador inicial si el email aún no existe.
+// Se usa al arrancar para que siempre haya alguien capaz de iniciar sesión.
+// Retorna created=true solo si el usuario fue creado en esta llamada.
+func (s *UserService) EnsureAdmin(ctx context.Context, name, email, password string) (bool, error) {
+
+	// Si ya existe (con cualquier rol), no se toca.
+	_, err := s.repo.GetByEmail(ctx, email)
+	if err == nil {
+		return false, nil
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return false, err
+	}
+
+	// Arranque del sistema: todavía no hay nadie autenticado.
+	if _, err := s.create(ctx, name, email, domain.RoleAdmin, password); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Delete elimina un usuario por ID (requiere users:write).
 // Envuelve el error con contexto (mejor trazabilidad).
 func (s *UserService) Delete(ctx context.Context, id uint64) error {
+	actor, err := authorize(ctx, domain.PermUsersWrite)
+	if err != nil {
+		return err
+	}
+	if actor.ID() == id {
+		return fmt.Errorf("%w: cannot delete yourself", domain.ErrForbidden)
+	}
+	if s.loans != nil {
+		active, err := s.loans.ListLoans(ctx, domain.LoanFilter{
+			UserID: id, Status: domain.LoanStatusActive, PageRequest: domain.PageRequest{PageSize: 1},
+		})
+		if err != nil {
+			return err
+		}
+		if active.Total > 0 {
+			return fmt.Errorf("%w: user has %d active loan(s); check the copies in first", domain.ErrValidation, active.Total)
+		}
+
+		// Las reservas activas se cancelan antes para que sus ejemplares
+		// apartados pasen a la siguiente de la cola (el máximo por rol cabe en una página).
+		holds, err := s.loans.ListHolds(ctx, domain.HoldFilter{
+			UserID: id, Status: domain.HoldStatusActive, PageRequest: domain.PageRequest{PageSize: domain.MaxPageSize},
+		})
+		if err != nil {
+			return err
+		}
+		for _, d := range holds.Items {
+			if err := d.Hold.Cancel(time.Now()); err != nil {
+				return err
+			}
+			if err := s.loans.CancelHold(ctx, d.Hold); err != nil {
+				return err
+			}
+		}
+	}
 	if err := s.repo.Delete(ctx, id); err != nil {
 		// fmt.Errorf con %w permite "wrap" del error (errors.Is seguirá funcionando).
 		return fmt.Errorf("delete user: %w", err)
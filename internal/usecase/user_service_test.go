@@ -2,6 +2,7 @@ package usecase
 
 import (
     "context"
+    "errors"
     "testing"
 
     "github.com/jfmg0509/sistema_libros_funcional_go/internal/domain"
@@ -10,12 +11,13 @@ import (
 func TestUserServiceCreateAndGet(t *testing.T) {
     repo := newMemUserRepo()
     svc := NewUserService(repo)
+    ctx, _ := actorCtx(repo, domain.RoleAdmin)
 
-    u, err := svc.Create(context.Background(), "Ana", "ana@example.com", domain.RoleAdmin)
+    u, err := svc.Create(ctx, "Ana", "ana@example.com", domain.RoleAdmin, "secreto123")
     if err != nil { t.Fatalf("create: %v", err) }
     if u.ID() == 0 { t.Fatalf("expected id") }
 
-    got, err := svc.Get(context.Background(), u.ID())
+    got, err := svc.Get(ctx, u.ID())
     if err != nil { t.Fatalf("get: %v", err) }
     if got.Email() != "ana@example.com" { t.Fatalf("email mismatch") }
 }
@@ -23,8 +25,86 @@ func TestUserServiceCreateAndGet(t *testing.T) {
 func TestUserValidation(t *testing.T) {
     repo := newMemUserRepo()
     svc := NewUserService(repo)
+    ctx, _ := actorCtx(repo, domain.RoleAdmin)
 
-    if _, err := svc.Create(context.Background(), "A", "bad", domain.RoleAdmin); err == nil {
+    if _, err := svc.Create(ctx, "A", "bad", domain.RoleAdmin, "secreto123"); err == nil {
         t.Fatalf("expected validation error")
     }
 }
+
+func TestUserPasswordIsHashed(t *testing.T) {
+    repo := newMemUserRepo()
+    svc := NewUserService(repo)
+    ctx, _ := actorCtx(repo, domain.RoleAdmin)
+
+    if _, err := svc.Create(ctx, "Ana", "ana@example.com", domain.RoleAdmin, "corta"); err == nil {
+        t.Fatalf("expected short password to fail")
+    }
+
+    u, err := svc.Create(ctx, "Ana", "ana@example.com", domain.RoleAdmin, "secreto123")
+    if err != nil { t.Fatalf("create: %v", err) }
+    if u.PasswordHash() == "" || u.PasswordHash() == "secreto123" { t.Fatalf("password must be stored hashed") }
+    if !u.CheckPassword("secreto123") || u.CheckPassword("otra-clave") { t.Fatalf("password check mismatch") }
+}
+
+func TestUserServiceRequiresAdmin(t *testing.T) {
+    repo := newMemUserRepo()
+    svc := NewUserService(repo)
+
+    if _, err := svc.List(context.Background()); !errors.Is(err, domain.ErrUnauthorized) {
+        t.Fatalf("expected unauthorized without actor, got %v", err)
+    }
+
+    readerCtx, reader := actorCtx(repo, domain.RoleReader)
+    if _, err := svc.Create(readerCtx, "Eva", "eva@example.com", domain.RoleReader, "secreto123"); !errors.Is(err, domain.ErrForbidden) {
+        t.Fatalf("expected forbidden for reader, got %v", err)
+    }
+    if _, err := svc.List(readerCtx); !errors.Is(err, domain.ErrForbidden) {
+        t.Fatalf("expected forbidden list for reader, got %v", err)
+    }
+    // un usuario siempre puede verse a sí mismo
+    if _, err := svc.Get(readerCtx, reader.ID()); err != nil {
+        t.Fatalf("self get: %v", err)
+    }
+    if err := svc.ChangePassword(readerCtx, reader.ID(), "nueva-clave-1"); err != nil {
+        t.Fatalf("self change password: %v", err)
+    }
+}
+
+func TestUserServiceSearchAndActivation(t *testing.T) {
+    repo := newMemUserRepo()
+    svc := NewUserService(repo)
+    ctx, admin := actorCtx(repo, domain.RoleAdmin)
+
+    eva, err := svc.Create(ctx, "Eva", "eva@colegio.edu", domain.RoleReader, "secreto123")
+    if err != nil { t.Fatalf("create: %v", err) }
+    if _, err := svc.Create(ctx, "Leo", "leo@example.com", domain.RoleConsultor, "secreto123"); err != nil { t.Fatalf("create: %v", err) }
+
+    list, err := svc.Search(ctx, domain.UserFilter{Role: domain.RoleReader, Email: "COLEGIO"})
+    if err != nil { t.Fatalf("search: %v", err) }
+    if list.Total != 1 || len(list.Items) != 1 || list.Items[0].ID() != eva.ID() { t.Fatalf("expected only eva, got %d users", list.Total) }
+
+    if _, err := svc.Search(ctx, domain.UserFilter{Role: "JEFE"}); !errors.Is(err, domain.ErrInvalidRole) {
+        t.Fatalf("expected invalid role, got %v", err)
+    }
+    bad := domain.UserFilter{PageRequest: domain.PageRequest{Sort: "password_hash"}}
+    if _, err := svc.Search(ctx, bad); !errors.Is(err, domain.ErrValidation) {
+        t.Fatalf("expected validation error for unknown sort, got %v", err)
+    }
+
+    u, err := svc.SetActive(ctx, eva.ID(), false)
+    if err != nil { t.Fatalf("deactivate: %v", err) }
+    if u.Active() { t.Fatalf("expected inactive") }
+
+    inactive := false
+    list, _ = svc.Search(ctx, domain.UserFilter{Active: &inactive})
+    if list.Total != 1 { t.Fatalf("expected 1 inactive user, got %d", list.Total) }
+
+    // el administrador no puede bloquearse a sí mismo
+    if _, err := svc.SetActive(ctx, admin.ID(), false); !errors.Is(err, domain.ErrForbidden) {
+        t.Fatalf("expected forbidden self-deactivation, got %v", err)
+    }
+    if err := svc.Delete(ctx, admin.ID()); !errors.Is(err, domain.ErrForbidden) {
+        t.Fatalf("expected forbidden self-delete, got %v", err)
+    }
+}